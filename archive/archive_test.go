@@ -0,0 +1,129 @@
+package archive_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/archive"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestRoundTrip(t *testing.T) {
+	ctx := t.Context()
+
+	src := storagemem.New()
+	require.NoError(t, src.CreateBucket(ctx, "src"))
+
+	type object struct {
+		Key      string
+		Body     string
+		Metadata fs.ObjectMetadata
+		Tags     []fs.Tag
+		ACL      fs.ACL
+	}
+
+	objects := []object{
+		{
+			Key: "plain.txt", Body: "hello",
+		},
+		{
+			Key: "dir/with meta.json", Body: `{"a":1}`,
+			Metadata: fs.ObjectMetadata{
+				ContentType:        "application/json",
+				CacheControl:       "max-age=60",
+				ContentDisposition: `attachment; filename="x.json"`,
+				ContentEncoding:    "identity",
+				UserMetadata:       map[string]string{"color": "blue", "owner": "ops team"},
+			},
+			Tags: []fs.Tag{{Key: "z=last", Value: "a&b"}, {Key: "env", Value: "prod"}},
+			ACL:  fs.ACLPublicRead,
+		},
+		{
+			Key: "empty", Body: "",
+		},
+	}
+
+	// A historical timestamp, so a restore stamped with the import time fails.
+	modified := time.Date(2015, time.March, 14, 9, 26, 53, 0, time.UTC)
+
+	for _, o := range objects {
+		_, err := src.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "src", Key: o.Key, Reader: strings.NewReader(o.Body), Size: int64(len(o.Body)),
+			Metadata: o.Metadata, Tags: o.Tags, ACL: o.ACL, LastModified: modified,
+		})
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.ExportBucket(ctx, src, "src", &buf))
+
+	dst := storagemem.New()
+	require.NoError(t, dst.CreateBucket(ctx, "dst"))
+
+	report, err := archive.ImportBucket(ctx, dst, "dst", &buf)
+	require.NoError(t, err)
+	require.Equal(t, len(objects), report.Imported)
+	require.Empty(t, report.ETagChanged)
+
+	for _, o := range objects {
+		want, err := src.GetObject(ctx, "src", o.Key)
+		require.NoError(t, err)
+
+		got, err := dst.GetObject(ctx, "dst", o.Key)
+		require.NoError(t, err)
+
+		require.Equal(t, want.ETag, got.ETag, o.Key)
+		require.True(t, modified.Equal(got.LastModified), "%s: got %v", o.Key, got.LastModified)
+		require.Equal(t, want.Metadata, got.Metadata, o.Key)
+		require.Equal(t, []byte(o.Body), readAll(t, got.Reader), o.Key)
+
+		wantTags, err := src.GetObjectTagging(ctx, "src", o.Key)
+		require.NoError(t, err)
+
+		gotTags, err := dst.GetObjectTagging(ctx, "dst", o.Key)
+		require.NoError(t, err)
+		require.Equal(t, wantTags, gotTags, o.Key)
+
+		acl, err := dst.ObjectACL(ctx, "dst", o.Key)
+		require.NoError(t, err)
+		require.Equal(t, fs.ParseACL(string(o.ACL)), acl, o.Key)
+	}
+}
+
+func TestImportRejectsEscapingEntry(t *testing.T) {
+	ctx := t.Context()
+
+	src := storagemem.New()
+	require.NoError(t, src.CreateBucket(ctx, "src"))
+
+	_, err := src.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "src", Key: "../escape", Reader: strings.NewReader("x"), Size: 1,
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, archive.ExportBucket(ctx, src, "src", &buf))
+
+	dst := storagemem.New()
+	require.NoError(t, dst.CreateBucket(ctx, "dst"))
+
+	_, err = archive.ImportBucket(ctx, dst, "dst", &buf)
+	require.Error(t, err)
+}
+
+func readAll(t *testing.T, r io.ReadCloser) []byte {
+	t.Helper()
+
+	defer func() { _ = r.Close() }()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return data
+}
@@ -24,8 +24,8 @@ const (
 	Read Permission = iota
 	// Write allows Read plus PUT/POST/DELETE (including bucket create/delete).
 	Write
-	// Admin allows everything Write does, and is the level future admin-only
-	// operations will require.
+	// Admin allows everything Write does; on "*" it also allows server
+	// administration (ActionAdmin).
 	Admin
 )
 
@@ -74,6 +74,9 @@ const (
 	ActionRead Action = iota
 	// ActionWrite is required by PUT/POST/DELETE.
 	ActionWrite
+	// ActionAdmin is required by server administration requests (such as
+	// toggling maintenance mode); it needs an Admin grant on "*".
+	ActionAdmin
 )
 
 // Grant authorizes an access key for buckets matching Pattern (a glob using
@@ -194,6 +197,18 @@ func (s *Store) Allow(accessKey, bucket string, action Action) bool {
 		return false
 	}
 
+	if action == ActionAdmin {
+		// Administration is server-wide: an Admin grant on some buckets is
+		// not enough.
+		for _, g := range k.Grants {
+			if g.Permission >= Admin && g.Pattern == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
 	if bucket == "" {
 		// Service-level operations (ListBuckets) need only a valid identity.
 		return true
@@ -0,0 +1,60 @@
+package auth
+
+// Operation names the S3 operation a request performs, as the handler routes
+// it: the S3 API action name, or a descriptive one for this server's
+// extensions. The values are stable, so an authorization policy or a trace
+// query can match them as strings.
+type Operation string
+
+// Service and administration operations.
+const (
+	OperationListBuckets      Operation = "ListBuckets"
+	OperationPreflightRequest Operation = "PreflightRequest"
+	OperationMaintenance      Operation = "Maintenance"
+	OperationReset            Operation = "Reset"
+	OperationListAllObjects   Operation = "ListAllObjects"
+	// OperationUnknown is a request that matches no operation; it is
+	// answered with an error.
+	OperationUnknown Operation = "Unknown"
+)
+
+// Bucket operations.
+const (
+	OperationCreateBucket           Operation = "CreateBucket"
+	OperationHeadBucket             Operation = "HeadBucket"
+	OperationDeleteBucket           Operation = "DeleteBucket"
+	OperationDeleteBucketForce      Operation = "DeleteBucketForce"
+	OperationGetBucketLocation      Operation = "GetBucketLocation"
+	OperationListObjects            Operation = "ListObjects"
+	OperationListObjectsV2          Operation = "ListObjectsV2"
+	OperationListObjectVersions     Operation = "ListObjectVersions"
+	OperationListMultipartUploads   Operation = "ListMultipartUploads"
+	OperationGetBucketManifest      Operation = "GetBucketManifest"
+	OperationCountObjects           Operation = "CountObjects"
+	OperationDiffObjects            Operation = "DiffObjects"
+	OperationDeleteObjects          Operation = "DeleteObjects"
+	OperationDeleteObjectsByPattern Operation = "DeleteObjectsByPattern"
+	OperationPostObject             Operation = "PostObject"
+)
+
+// Object operations.
+const (
+	OperationGetObject               Operation = "GetObject"
+	OperationHeadObject              Operation = "HeadObject"
+	OperationGetObjectMeta           Operation = "GetObjectMeta"
+	OperationPutObject               Operation = "PutObject"
+	OperationPutObjectRange          Operation = "PutObjectRange"
+	OperationCopyObject              Operation = "CopyObject"
+	OperationDeleteObject            Operation = "DeleteObject"
+	OperationGetObjectTagging        Operation = "GetObjectTagging"
+	OperationPutObjectTagging        Operation = "PutObjectTagging"
+	OperationDeleteObjectTagging     Operation = "DeleteObjectTagging"
+	OperationGetObjectLegalHold      Operation = "GetObjectLegalHold"
+	OperationPutObjectLegalHold      Operation = "PutObjectLegalHold"
+	OperationCreateMultipartUpload   Operation = "CreateMultipartUpload"
+	OperationUploadPart              Operation = "UploadPart"
+	OperationUploadPartCopy          Operation = "UploadPartCopy"
+	OperationCompleteMultipartUpload Operation = "CompleteMultipartUpload"
+	OperationAbortMultipartUpload    Operation = "AbortMultipartUpload"
+	OperationListParts               Operation = "ListParts"
+)
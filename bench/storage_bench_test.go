@@ -1,9 +1,15 @@
 package bench
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagefs"
 )
 
 // sizeName renders a byte size for benchmark sub-names.
@@ -37,6 +43,32 @@ func BenchmarkPutObject(b *testing.B) {
 	}
 }
 
+// BenchmarkPutObjectSync contrasts PUT throughput with and without fsync
+// (storagefs.WithSyncWrites). Small objects show the per-write fsync latency,
+// the mid size how much of it streaming amortizes. Run it on the disk the
+// server will use: tmpfs makes fsync free.
+func BenchmarkPutObjectSync(b *testing.B) {
+	for _, sync := range []bool{false, true} {
+		for _, size := range []int64{sizeSmall, sizeMid} {
+			b.Run(fmt.Sprintf("sync=%t/%s", sync, sizeName(size)), func(b *testing.B) {
+				s, err := storagefs.New(b.TempDir(), storagefs.WithSyncWrites(sync))
+				require.NoError(b, err)
+				require.NoError(b, s.CreateBucket(context.Background(), "bench"))
+
+				body := newBody(size)
+
+				b.SetBytes(size)
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; b.Loop(); i++ {
+					putObject(b, s, fmt.Sprintf("put-%d", i), size, body)
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkGetObject measures read throughput across object sizes.
 func BenchmarkGetObject(b *testing.B) {
 	for _, size := range []int64{sizeSmall, sizeMid, sizeLarge} {
@@ -58,6 +90,97 @@ func BenchmarkGetObject(b *testing.B) {
 	}
 }
 
+// BenchmarkGetObjectReadAhead contrasts GET throughput with and without
+// storagefs.WithReadAhead. The large object shows the hint's effect (drop the
+// page cache between runs to measure cold reads from disk); the small one
+// checks it costs nothing per request.
+func BenchmarkGetObjectReadAhead(b *testing.B) {
+	for _, readAhead := range []bool{false, true} {
+		for _, size := range []int64{sizeSmall, sizeLarge} {
+			b.Run(fmt.Sprintf("readahead=%t/%s", readAhead, sizeName(size)), func(b *testing.B) {
+				var opts []storagefs.Option
+				if readAhead {
+					opts = append(opts, storagefs.WithReadAhead())
+				}
+
+				s, err := storagefs.New(b.TempDir(), opts...)
+				require.NoError(b, err)
+				require.NoError(b, s.CreateBucket(context.Background(), "bench"))
+
+				const key = "get"
+
+				putObject(b, s, key, size, newBody(size))
+
+				b.SetBytes(size)
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for b.Loop() {
+					getObjectDiscard(b, s, key)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGetObjectSmallMany reads a working set of tiny objects round-robin,
+// so per-request syscalls and path resolutions — not bytes — set the cost.
+func BenchmarkGetObjectSmallMany(b *testing.B) {
+	const (
+		size = 256
+		keys = 1024
+	)
+
+	s, _ := benchStore(b)
+	body := newBody(size)
+
+	for i := range keys {
+		putObject(b, s, fmt.Sprintf("small-%d", i), size, body)
+	}
+
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; b.Loop(); i++ {
+		getObjectDiscard(b, s, fmt.Sprintf("small-%d", i%keys))
+	}
+}
+
+// BenchmarkGetObjectMissing looks up keys that do not exist, as a
+// cache-miss-heavy workload does, with and without
+// storagefs.WithExistenceFilter. Without it every miss resolves the key's
+// path on disk; with it a definite miss never leaves memory.
+func BenchmarkGetObjectMissing(b *testing.B) {
+	const keys = 1024
+
+	for _, filter := range []bool{false, true} {
+		b.Run(fmt.Sprintf("filter=%t", filter), func(b *testing.B) {
+			opts := []storagefs.Option{storagefs.WithSyncPolicy(storagefs.SyncNone)}
+			if filter {
+				opts = append(opts, storagefs.WithExistenceFilter(keys))
+			}
+
+			s, err := storagefs.New(b.TempDir(), opts...)
+			require.NoError(b, err)
+			require.NoError(b, s.CreateBucket(context.Background(), "bench"))
+
+			body := newBody(sizeSmall)
+			for i := range keys {
+				putObject(b, s, fmt.Sprintf("dir/present-%d", i), sizeSmall, body)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; b.Loop(); i++ {
+				_, err := s.GetObject(context.Background(), "bench", fmt.Sprintf("dir/missing-%d", i%keys))
+				require.ErrorIs(b, err, fs.ErrObjectNotFound)
+			}
+		})
+	}
+}
+
 // BenchmarkPutGetRoundTrip measures a write-then-read cycle for small objects
 // — the metadata-bound path where per-request overhead dominates.
 func BenchmarkPutGetRoundTrip(b *testing.B) {
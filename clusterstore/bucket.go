@@ -54,6 +54,8 @@ type BucketInfo struct {
 	Name    string    `json:"name"`
 	ACL     fs.ACL    `json:"acl,omitempty"`
 	Created time.Time `json:"created"`
+	// Region is the bucket's LocationConstraint; empty when none was given.
+	Region string `json:"region,omitempty"`
 	// Scheme overrides the cluster default replication scheme for this
 	// bucket's objects ("rf2.5", "rf3", "ec:k,m"); empty applies the default.
 	// Changing it affects new writes immediately; existing objects follow
@@ -213,6 +215,20 @@ func (c *Coordinator) SetBucketACL(ctx context.Context, bucket string, acl fs.AC
 	return c.writeBucket(ctx, topo, info)
 }
 
+// SetBucketRegion rewrites the bucket record with a new region.
+func (c *Coordinator) SetBucketRegion(ctx context.Context, bucket, region string) error {
+	topo := c.topo.Topology()
+
+	info, err := c.fetchBucket(ctx, topo, bucket)
+	if err != nil {
+		return err
+	}
+
+	info.Region = region
+
+	return c.writeBucket(ctx, topo, info)
+}
+
 // SetBucketScheme rewrites the bucket record with a new object scheme
 // override; empty restores the cluster default. The scheme must parse and the
 // current topology must be able to host it (a bucket must never be switched
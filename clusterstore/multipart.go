@@ -219,8 +219,8 @@ func (s *Storage) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteM
 		uploaded[partNumber(sc)] = sc
 	}
 
-	// Requested parts in ascending number order; missing numbers are skipped,
-	// matching the single-node backends.
+	// Requested parts in ascending number order; each must exist with the
+	// ETag the client listed.
 	parts := make([]fs.CompletedPart, len(req.Parts))
 	copy(parts, req.Parts)
 	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
@@ -228,18 +228,20 @@ func (s *Storage) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteM
 	var (
 		totalSize int64
 		partKeys  []string
+		partSizes []int64
 		etagHash  = md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
 	)
 
 	for _, part := range parts {
 		sc, ok := uploaded[part.PartNumber]
-		if !ok {
-			continue
+		if !ok || sc.ETag != strings.Trim(part.ETag, `"`) {
+			return nil, errors.Wrapf(fs.ErrInvalidPart, "part %d", part.PartNumber)
 		}
 
 		totalSize += sc.Size
 
 		partKeys = append(partKeys, sc.Key)
+		partSizes = append(partSizes, sc.Size)
 
 		if sum, err := hex.DecodeString(sc.Checksum); err == nil {
 			_, _ = etagHash.Write(sum)
@@ -248,9 +250,34 @@ func (s *Storage) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteM
 
 	etag := fmt.Sprintf("%x-%d", etagHash.Sum(nil), len(parts))
 
+	if req.StoreAs != "" {
+		key = req.StoreAs
+	}
+
 	l := s.locks.of(req.Bucket, key)
 	l.Lock()
 
+	cur, err := s.committed(ctx, req.Bucket, key)
+	if err == nil && (req.IfNoneMatch != "" || req.IfMatch != "") {
+		var currentETag string
+		if cur != nil {
+			currentETag = cur.ETag
+		}
+
+		if req.PreconditionFailed(cur != nil, currentETag) {
+			err = fs.ErrPreconditionFailed
+		}
+	}
+
+	if err == nil && cur != nil && cur.LegalHold {
+		err = legalHoldError(req.Bucket, key)
+	}
+
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+
 	sc, err := s.coord.Put(ctx, &PutRequest{
 		Bucket: req.Bucket,
 		Key:    key,
@@ -265,6 +292,7 @@ func (s *Storage) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteM
 		Tags:     append([]fs.Tag(nil), rec.Tags...),
 		ACL:      rec.ACL,
 		ETag:     etag,
+		Parts:    partSizes,
 	})
 
 	l.Unlock()
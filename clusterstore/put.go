@@ -37,6 +37,12 @@ type PutRequest struct {
 	// ETag overrides the stored ETag (multipart composite ETags); empty means
 	// the content MD5.
 	ETag string
+	// Parts records the part sizes of a multipart object.
+	Parts []int64
+	// Modified overrides the recorded write time (imports); zero means now.
+	// Seq still orders writes, so a historical time never makes this write
+	// lose to an older one.
+	Modified time.Time
 }
 
 // Put writes an object at its bucket's scheme, acknowledging only once the
@@ -111,6 +117,11 @@ func (c *Coordinator) Put(ctx context.Context, req *PutRequest) (*Sidecar, error
 		seq = oldSC.Seq + 1
 	}
 
+	modified := req.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+
 	sc := &Sidecar{
 		Version:            sidecarVersion,
 		Bucket:             req.Bucket,
@@ -119,7 +130,7 @@ func (c *Coordinator) Put(ctx context.Context, req *PutRequest) (*Sidecar, error
 		Size:               req.Size,
 		Generation:         gen,
 		Seq:                seq,
-		Modified:           time.Now().UTC(),
+		Modified:           modified.UTC(),
 		ETag:               etag,
 		Checksum:           checksum,
 		ContentType:        req.Metadata.ContentType,
@@ -129,6 +140,7 @@ func (c *Coordinator) Put(ctx context.Context, req *PutRequest) (*Sidecar, error
 		UserMetadata:       req.Metadata.UserMetadata,
 		Tags:               req.Tags,
 		ACL:                req.ACL,
+		Parts:              req.Parts,
 	}
 
 	// Commit: replace the sidecar on every quorum target. This is what makes
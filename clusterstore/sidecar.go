@@ -61,6 +61,12 @@ type Sidecar struct {
 	UserMetadata       map[string]string `json:"user_metadata,omitempty"`
 	Tags               []fs.Tag          `json:"tags,omitempty"`
 	ACL                fs.ACL            `json:"acl,omitempty"`
+	// LegalHold refuses overwrites and deletes of the object while set.
+	LegalHold bool `json:"legal_hold,omitempty"`
+
+	// Parts are the sizes of the parts a multipart object was completed
+	// from, in order; empty for a single PUT.
+	Parts []int64 `json:"parts,omitempty"`
 }
 
 // ObjectMetadata converts the sidecar's header fields to the domain type.
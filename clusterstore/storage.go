@@ -128,10 +128,14 @@ func (s *Storage) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.
 	return objects, nil
 }
 
-// PutObject implements fs.Storage. The conditional check and the write happen
-// under the object's key lock, so concurrent conditional PUTs on this node
-// resolve to a single winner.
+// PutObject implements fs.Storage. The conditional and legal-hold checks and
+// the write happen under the object's key lock, so concurrent conditional
+// PUTs on this node resolve to a single winner.
 func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "customer-provided encryption keys")
+	}
+
 	if err := s.mustBucket(ctx, req.Bucket); err != nil {
 		return nil, err
 	}
@@ -140,24 +144,26 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 	l.Lock()
 	defer l.Unlock()
 
+	cur, err := s.committed(ctx, req.Bucket, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.IfNoneMatch != "" || req.IfMatch != "" {
-		var (
-			exists      bool
-			currentETag string
-		)
-
-		switch cur, err := s.coord.Stat(ctx, req.Bucket, req.Key); {
-		case err == nil:
-			exists, currentETag = true, cur.ETag
-		case !errors.Is(err, ErrNotFound):
-			return nil, err
+		var currentETag string
+		if cur != nil {
+			currentETag = cur.ETag
 		}
 
-		if req.PreconditionFailed(exists, currentETag) {
+		if req.PreconditionFailed(cur != nil, currentETag) {
 			return nil, fs.ErrPreconditionFailed
 		}
 	}
 
+	if cur != nil && cur.LegalHold {
+		return nil, legalHoldError(req.Bucket, req.Key)
+	}
+
 	sc, err := s.coord.Put(ctx, &PutRequest{
 		Bucket:   req.Bucket,
 		Key:      req.Key,
@@ -166,6 +172,7 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 		Metadata: req.Metadata,
 		Tags:     append([]fs.Tag(nil), req.Tags...),
 		ACL:      req.ACL,
+		Modified: req.LastModified,
 	})
 	if err != nil {
 		return nil, err
@@ -176,6 +183,10 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 
 // GetObject implements fs.Storage.
 func (s *Storage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "customer-provided encryption keys")
+	}
+
 	if err := s.mustBucket(ctx, bucket); err != nil {
 		return nil, err
 	}
@@ -191,15 +202,41 @@ func (s *Storage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObj
 		LastModified: sc.Modified,
 		ETag:         sc.ETag,
 		Metadata:     sc.ObjectMetadata(),
+		PartSizes:    sc.Parts,
 	}, nil
 }
 
-// DeleteObject implements fs.Storage.
+// DeleteObject implements fs.Storage. A conditional delete is checked and
+// applied under the object's key lock, as a conditional PUT is.
 func (s *Storage) DeleteObject(ctx context.Context, bucket, key string) error {
 	if err := s.mustBucket(ctx, bucket); err != nil {
 		return err
 	}
 
+	l := s.locks.of(bucket, key)
+	l.Lock()
+	defer l.Unlock()
+
+	cur, err := s.committed(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if cond := fs.DeleteConditionFromContext(ctx); cond != nil {
+		var currentETag string
+		if cur != nil {
+			currentETag = cur.ETag
+		}
+
+		if cond.PreconditionFailed(cur != nil, currentETag) {
+			return fs.ErrPreconditionFailed
+		}
+	}
+
+	if cur != nil && cur.LegalHold {
+		return legalHoldError(bucket, key)
+	}
+
 	return mapObjectErr(s.coord.Delete(ctx, bucket, key), key)
 }
 
@@ -227,6 +264,28 @@ func (s *Storage) DeleteObjectTagging(ctx context.Context, bucket, key string) e
 	})
 }
 
+// PutObjectLegalHold implements fs.Storage.
+func (s *Storage) PutObjectLegalHold(ctx context.Context, bucket, key string, on bool) error {
+	return s.updateObject(ctx, bucket, key, func(sc *Sidecar) {
+		sc.LegalHold = on
+	})
+}
+
+// GetObjectLegalHold implements fs.Storage.
+func (s *Storage) GetObjectLegalHold(ctx context.Context, bucket, key string) (bool, error) {
+	sc, err := s.statObject(ctx, bucket, key)
+	if err != nil {
+		return false, err
+	}
+
+	return sc.LegalHold, nil
+}
+
+// legalHoldError refuses a change to an object under legal hold.
+func legalHoldError(bucket, key string) error {
+	return errors.Wrapf(fs.ErrAccessDenied, "%q in bucket %q is under legal hold", key, bucket)
+}
+
 // SetBucketACL implements fs.Storage.
 func (s *Storage) SetBucketACL(ctx context.Context, bucket string, acl fs.ACL) error {
 	return s.coord.SetBucketACL(ctx, bucket, acl)
@@ -242,6 +301,21 @@ func (s *Storage) BucketACL(ctx context.Context, bucket string) (fs.ACL, error)
 	return normalizeACL(info.ACL), nil
 }
 
+// SetBucketRegion implements fs.Storage.
+func (s *Storage) SetBucketRegion(ctx context.Context, bucket, region string) error {
+	return s.coord.SetBucketRegion(ctx, bucket, region)
+}
+
+// BucketRegion implements fs.Storage.
+func (s *Storage) BucketRegion(ctx context.Context, bucket string) (string, error) {
+	info, err := s.coord.Bucket(ctx, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	return info.Region, nil
+}
+
 // ObjectACL implements fs.Storage.
 func (s *Storage) ObjectACL(ctx context.Context, bucket, key string) (fs.ACL, error) {
 	sc, err := s.statObject(ctx, bucket, key)
@@ -266,6 +340,21 @@ func (s *Storage) statObject(ctx context.Context, bucket, key string) (*Sidecar,
 	return sc, nil
 }
 
+// committed returns the object's committed sidecar, or nil when there is
+// none. Callers hold the key lock.
+func (s *Storage) committed(ctx context.Context, bucket, key string) (*Sidecar, error) {
+	sc, err := s.coord.Stat(ctx, bucket, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
 // updateObject rewrites an object's sidecar under its key lock.
 func (s *Storage) updateObject(ctx context.Context, bucket, key string, mutate func(*Sidecar)) error {
 	if err := s.mustBucket(ctx, bucket); err != nil {
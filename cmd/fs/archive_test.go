@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagefs"
+)
+
+func TestExportImportCommands(t *testing.T) {
+	ctx := t.Context()
+
+	src, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, src.CreateBucket(ctx, "photos"))
+
+	_, err = src.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "photos", Key: "2024/cat.jpg", Reader: strings.NewReader("meow"), Size: 4,
+		Metadata: fs.ObjectMetadata{ContentType: "image/jpeg"},
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, runExport(ctx, src, "photos", "-", &archive))
+
+	dstRoot := t.TempDir()
+
+	dst, err := storagefs.New(dstRoot)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, runImport(ctx, dst, "restored", "-", &archive, &out))
+	require.Contains(t, out.String(), "imported 1 object(s) into restored")
+
+	got, err := dst.GetObject(ctx, "restored", "2024/cat.jpg")
+	require.NoError(t, err)
+	require.NoError(t, got.Reader.Close())
+	require.Equal(t, "image/jpeg", got.Metadata.ContentType)
+}
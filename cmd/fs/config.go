@@ -1,16 +1,31 @@
 package main
 
 import (
+	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"net/netip"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-faster/errors"
+	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v3"
 
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/defaults"
+	"github.com/go-faster/fs/fallback"
+	"github.com/go-faster/fs/ingest"
 	"github.com/go-faster/fs/internal/cluster/scheme"
 	"github.com/go-faster/fs/internal/validate"
+	"github.com/go-faster/fs/policy"
+	"github.com/go-faster/fs/replicate"
 	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
 )
 
 // StorageTypeFilesystem is the single-node filesystem storage backend.
@@ -62,6 +77,15 @@ type IntegrityConfig struct {
 	// it (costs a full extra read per GET). Off by default.
 	VerifyOnRead bool `yaml:"verify_on_read,omitempty"`
 
+	// VerifyWhileStreaming checks the checksum as the object is sent instead,
+	// at no extra read; a mismatch aborts the response before it completes.
+	// Filesystem storage only; exclusive with VerifyOnRead.
+	VerifyWhileStreaming bool `yaml:"verify_while_streaming,omitempty"`
+
+	// QuarantineOnRead moves objects VerifyWhileStreaming finds corrupt aside
+	// (into <root>/.quarantine) so they are not served again.
+	QuarantineOnRead bool `yaml:"quarantine_on_read,omitempty"`
+
 	// ScrubInterval, if positive, runs a background scrubber that walks all
 	// objects on this cadence and reports bit-rot. Zero disables it.
 	ScrubInterval time.Duration `yaml:"scrub_interval,omitempty"`
@@ -69,6 +93,42 @@ type IntegrityConfig struct {
 	// ScrubQuarantine moves corrupt objects aside (into <root>/.quarantine)
 	// instead of only reporting them.
 	ScrubQuarantine bool `yaml:"scrub_quarantine,omitempty"`
+
+	// ScrubRate caps the scrubber's read rate in bytes per second, so a pass
+	// over cold data does not starve serving. Zero is unthrottled; filesystem
+	// storage only.
+	ScrubRate int64 `yaml:"scrub_rate,omitempty"`
+}
+
+func (c IntegrityConfig) validate() error {
+	if c.VerifyOnRead && c.VerifyWhileStreaming {
+		return errors.New("integrity.verify_on_read and integrity.verify_while_streaming are exclusive")
+	}
+
+	if c.QuarantineOnRead && !c.VerifyWhileStreaming {
+		return errors.New("integrity.quarantine_on_read requires integrity.verify_while_streaming")
+	}
+
+	if c.ScrubRate < 0 {
+		return errors.New("integrity.scrub_rate must not be negative")
+	}
+
+	return nil
+}
+
+// storageOptions converts the read-verification settings to storagefs options.
+func (c IntegrityConfig) storageOptions() []storagefs.Option {
+	opts := []storagefs.Option{storagefs.WithVerifyReads(c.VerifyOnRead)}
+
+	if c.VerifyWhileStreaming {
+		opts = append(opts, storagefs.WithVerifyOnRead())
+	}
+
+	if c.QuarantineOnRead {
+		opts = append(opts, storagefs.WithReadQuarantine())
+	}
+
+	return opts
 }
 
 // Auth source values for AuthConfig.Source.
@@ -175,11 +235,502 @@ type ServerConfig struct {
 	// IdleTimeout is the maximum amount of time to wait for the next request
 	IdleTimeout time.Duration `yaml:"idle_timeout"`
 
+	// MaxHeaderBytes limits the size of request headers. Zero means Go's
+	// default of 1 MB.
+	MaxHeaderBytes int `yaml:"max_header_bytes,omitempty"`
+
+	// DisableKeepAlives closes each client connection after one request.
+	DisableKeepAlives bool `yaml:"disable_keep_alives,omitempty"`
+
 	// HealthPath is the path for health check endpoint
 	HealthPath string `yaml:"health_path"`
 
 	// TLS, if both files are set, serves HTTPS with hot-reloadable certificates.
 	TLS TLSConfig `yaml:"tls,omitempty"`
+
+	// RateLimit optionally throttles requests per client IP.
+	RateLimit RateLimitConfig `yaml:"rate_limit,omitempty"`
+
+	// MaxKeyLength is the key length limit in bytes for new objects. Zero
+	// means the S3 default of 1024.
+	MaxKeyLength int `yaml:"max_key_length,omitempty"`
+
+	// MaxConcurrentUploads caps object uploads in flight; the excess gets
+	// 503 SlowDown. Zero means no limit.
+	MaxConcurrentUploads int `yaml:"max_concurrent_uploads,omitempty"`
+
+	// MaxConnections caps open client connections; the excess waits in the
+	// listen backlog. Zero means no limit.
+	MaxConnections int `yaml:"max_connections,omitempty"`
+
+	// MaxInFlight caps requests being served at once, MaxInFlightReads and
+	// MaxInFlightWrites reads (GET, HEAD, OPTIONS) and all other requests
+	// alone; the excess gets 503 SlowDown. Zero means no limit.
+	MaxInFlight       int `yaml:"max_inflight,omitempty"`
+	MaxInFlightReads  int `yaml:"max_inflight_reads,omitempty"`
+	MaxInFlightWrites int `yaml:"max_inflight_writes,omitempty"`
+
+	// Notifications optionally sends S3 event notifications for object
+	// changes.
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+
+	// Replication optionally copies object writes to peer S3 endpoints.
+	Replication ReplicationConfig `yaml:"replication,omitempty"`
+
+	// PrefixPolicies restrict writes under key prefixes of a bucket.
+	PrefixPolicies []PrefixPolicyConfig `yaml:"prefix_policies,omitempty"`
+
+	// NotFoundKeys serve a bucket object in place of missing keys.
+	NotFoundKeys []NotFoundKeyConfig `yaml:"not_found_keys,omitempty"`
+
+	// BucketDefaults add default tags and metadata to uploaded objects.
+	BucketDefaults []BucketDefaultsConfig `yaml:"bucket_defaults,omitempty"`
+
+	// GzipStatic serves key.gz in place of key to clients accepting gzip.
+	GzipStatic bool `yaml:"gzip_static,omitempty"`
+
+	// CompressListings gzip-compresses listing responses for clients
+	// accepting gzip.
+	CompressListings bool `yaml:"compress_listings,omitempty"`
+
+	// NoOverwriteRename stores writes to existing keys under derived keys
+	// instead of overwriting them.
+	NoOverwriteRename bool `yaml:"no_overwrite_rename,omitempty"`
+
+	// ErrorVerbosity is "public" (default: S3 code and generic message only)
+	// or "debug" (the internal error appended, file paths included).
+	ErrorVerbosity string `yaml:"error_verbosity,omitempty"`
+
+	// DefaultContentType is the Content-Type served for objects uploaded
+	// without one (default application/octet-stream), e.g. text/plain.
+	DefaultContentType string `yaml:"default_content_type,omitempty"`
+
+	// Region is the region GetBucketLocation reports for buckets created
+	// without a LocationConstraint (default us-east-1). With StrictRegion,
+	// creating a bucket with any other constraint is refused.
+	Region       string `yaml:"region,omitempty"`
+	StrictRegion bool   `yaml:"strict_region,omitempty"`
+
+	// ResumableDownloadsTTL, when positive, enables ?download-id GETs that a
+	// client can resume after an interruption, forgetting a download not
+	// resumed for that long.
+	ResumableDownloadsTTL time.Duration `yaml:"resumable_downloads_ttl,omitempty"`
+
+	// URLIngest enables PUTs that have the server fetch the object from a URL.
+	URLIngest URLIngestConfig `yaml:"url_ingest,omitempty"`
+
+	// AllowReset enables the admin-only DELETE /?all endpoint that deletes
+	// every bucket. For test and development servers only.
+	AllowReset bool `yaml:"allow_reset,omitempty"`
+}
+
+// BucketDefaultsConfig gives every object uploaded to Bucket the Tags and
+// Metadata (x-amz-meta-* names without the prefix) the upload does not set
+// itself.
+type BucketDefaultsConfig struct {
+	Bucket   string            `yaml:"bucket"`
+	Tags     map[string]string `yaml:"tags,omitempty"`
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+}
+
+// validate checks that the defaults name a bucket.
+func (c BucketDefaultsConfig) validate() error {
+	if c.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+
+	return nil
+}
+
+// URLIngestConfig configures PUTs with an x-fs-source-url header, which store
+// the object the server fetches from that URL.
+type URLIngestConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// AllowedHosts, when set, are the only hosts fetched from: exact names or
+	// IPs (which may be private) or "*.example.com" wildcards. Otherwise any
+	// public host is.
+	AllowedHosts []string `yaml:"allowed_hosts,omitempty"`
+
+	// DeniedHosts are never fetched from, even when allowed.
+	DeniedHosts []string `yaml:"denied_hosts,omitempty"`
+
+	// MaxSize bounds a fetched object in bytes (default 5 GiB).
+	MaxSize int64 `yaml:"max_size,omitempty"`
+
+	// Timeout bounds a whole fetch (default none).
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// options returns the ingest options the config sets.
+func (c URLIngestConfig) options() []ingest.Option {
+	var opts []ingest.Option
+
+	if len(c.AllowedHosts) > 0 {
+		opts = append(opts, ingest.WithAllowedHosts(c.AllowedHosts...))
+	}
+
+	if len(c.DeniedHosts) > 0 {
+		opts = append(opts, ingest.WithDeniedHosts(c.DeniedHosts...))
+	}
+
+	if c.MaxSize > 0 {
+		opts = append(opts, ingest.WithMaxSize(c.MaxSize))
+	}
+
+	if c.Timeout > 0 {
+		opts = append(opts, ingest.WithTimeout(c.Timeout))
+	}
+
+	return opts
+}
+
+// NotFoundKeyConfig makes a GET of a missing key in Bucket serve the object
+// at Key with Status: 200 for a single-page app's entry point, 404 (the
+// default) for an error page.
+type NotFoundKeyConfig struct {
+	Bucket string `yaml:"bucket"`
+	Key    string `yaml:"key"`
+	Status int    `yaml:"status,omitempty"`
+}
+
+// validate checks that the fallback names a bucket, a key and a sane status.
+func (c NotFoundKeyConfig) validate() error {
+	if c.Bucket == "" || c.Key == "" {
+		return errors.New("bucket and key are required")
+	}
+
+	if c.Status != 0 && (c.Status < 200 || c.Status > 599) {
+		return errors.Errorf("invalid status %d", c.Status)
+	}
+
+	return nil
+}
+
+// PrefixPolicyConfig is a policy for the keys of Bucket starting with Prefix
+// (the whole bucket when empty); the longest matching prefix governs a key.
+type PrefixPolicyConfig struct {
+	Bucket string `yaml:"bucket"`
+	Prefix string `yaml:"prefix,omitempty"`
+
+	// ReadOnly refuses uploads, deletes and tagging changes.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// AppendOnly refuses overwrites and deletes; on the whole bucket it also
+	// refuses deleting the bucket.
+	AppendOnly bool `yaml:"append_only,omitempty"`
+
+	// MaxBytes caps the total size of the objects under the prefix. Zero
+	// means no quota.
+	MaxBytes int64 `yaml:"max_bytes,omitempty"`
+
+	// RequiredMetadata lists x-amz-meta-* names new objects must carry.
+	RequiredMetadata []string `yaml:"required_metadata,omitempty"`
+}
+
+// validate checks that the policy names a bucket and a sane quota.
+func (c PrefixPolicyConfig) validate() error {
+	if c.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+
+	if c.MaxBytes < 0 {
+		return errors.New("max_bytes must not be negative")
+	}
+
+	return nil
+}
+
+// NotificationsConfig configures S3 event notifications, delivered
+// asynchronously with retries.
+type NotificationsConfig struct {
+	// WebhookURL receives each event as a JSON POST. Empty disables
+	// notifications.
+	WebhookURL string `yaml:"webhook_url,omitempty"`
+
+	// Buffer is the number of undelivered events held in memory; events
+	// beyond it are dropped. Zero means DefaultNotificationBuffer.
+	Buffer int `yaml:"buffer,omitempty"`
+
+	// Timeout bounds each webhook POST; one that runs out is retried. Zero
+	// means notify.DefaultWebhookTimeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// DefaultNotificationBuffer is the default notifications.buffer.
+const DefaultNotificationBuffer = 1024
+
+// validate checks the webhook URL, buffer size and timeout.
+func (c NotificationsConfig) validate() error {
+	if c.Buffer < 0 {
+		return errors.New("server.notifications.buffer must not be negative")
+	}
+
+	if c.Timeout < 0 {
+		return errors.New("server.notifications.timeout must not be negative")
+	}
+
+	if c.WebhookURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("server.notifications.webhook_url: %q is not an http(s) URL", c.WebhookURL)
+	}
+
+	return nil
+}
+
+// ReplicationConfig configures asynchronous replication of object writes to
+// peer S3 endpoints (see package replicate).
+type ReplicationConfig struct {
+	// Targets are the peers. Empty disables replication.
+	Targets []ReplicationTargetConfig `yaml:"targets,omitempty"`
+
+	// DeadLetter is a file operations given up on are appended to, one JSON
+	// line each. Empty only counts them.
+	DeadLetter string `yaml:"dead_letter,omitempty"`
+
+	// Buffer is the number of operations queued per peer; operations beyond
+	// it are given up on. Zero means replicate.DefaultBuffer.
+	Buffer int `yaml:"buffer,omitempty"`
+
+	// MaxAttempts is how many times an operation is tried. Zero means
+	// replicate.DefaultMaxAttempts.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+}
+
+// ReplicationTargetConfig is one replication peer.
+type ReplicationTargetConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	AccessKey string `yaml:"access_key,omitempty"`
+	SecretKey string `yaml:"secret_key,omitempty"`
+}
+
+// validate checks the peer endpoints and limits.
+func (c ReplicationConfig) validate() error {
+	if c.Buffer < 0 {
+		return errors.New("server.replication.buffer must not be negative")
+	}
+
+	if c.MaxAttempts < 0 {
+		return errors.New("server.replication.max_attempts must not be negative")
+	}
+
+	for i, t := range c.Targets {
+		if t.Endpoint == "" {
+			return errors.Errorf("server.replication.targets[%d].endpoint is required", i)
+		}
+
+		if (t.AccessKey == "") != (t.SecretKey == "") {
+			return errors.Errorf("server.replication.targets[%d]: access_key and secret_key go together", i)
+		}
+	}
+
+	return nil
+}
+
+// replicator starts replicating the writes of storage to the configured
+// targets, or returns nil when there are none.
+func (c ReplicationConfig) replicator(storage fs.Storage) (*replicate.Replicator, io.Closer, error) {
+	if len(c.Targets) == 0 {
+		return nil, nil, nil
+	}
+
+	targets := make([]replicate.Target, len(c.Targets))
+	for i, t := range c.Targets {
+		targets[i] = replicate.Target{Endpoint: t.Endpoint, AccessKey: t.AccessKey, SecretKey: t.SecretKey}
+	}
+
+	var opts []replicate.Option
+	if c.Buffer > 0 {
+		opts = append(opts, replicate.WithBuffer(c.Buffer))
+	}
+
+	if c.MaxAttempts > 0 {
+		opts = append(opts, replicate.WithMaxAttempts(c.MaxAttempts))
+	}
+
+	var deadLetter io.Closer
+
+	if c.DeadLetter != "" {
+		f, err := os.OpenFile(c.DeadLetter, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "open replication dead-letter log")
+		}
+
+		opts = append(opts, replicate.WithDeadLetter(f))
+		deadLetter = f
+	}
+
+	r, err := replicate.New(storage, targets, opts...)
+	if err != nil {
+		if deadLetter != nil {
+			_ = deadLetter.Close()
+		}
+
+		return nil, nil, errors.Wrap(err, "replication")
+	}
+
+	return r, deadLetter, nil
+}
+
+// RateLimitConfig configures per-client-IP request throttling. Excess requests
+// get 503 SlowDown with a Retry-After header.
+type RateLimitConfig struct {
+	// PerIP is the sustained requests per second allowed per client IP. Zero
+	// disables rate limiting.
+	PerIP float64 `yaml:"per_ip,omitempty"`
+
+	// Burst is the number of requests a client may make at once before being
+	// throttled to PerIP. Must be positive when PerIP is set.
+	Burst int `yaml:"burst,omitempty"`
+
+	// TrustedProxies are CIDRs of reverse proxies whose X-Forwarded-For header
+	// identifies the client (e.g. "10.0.0.0/8").
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+}
+
+// handlerOptions converts the server section to S3 handler options.
+// Notifications and replication are wired separately: their queues need
+// shutting down.
+func (c ServerConfig) handlerOptions() ([]server.HandlerOption, error) {
+	opts, err := c.RateLimit.handlerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MaxKeyLength > 0 {
+		opts = append(opts, server.WithMaxKeyLength(c.MaxKeyLength))
+	}
+
+	if c.MaxConcurrentUploads > 0 {
+		opts = append(opts, server.WithMaxConcurrentUploads(c.MaxConcurrentUploads))
+	}
+
+	if c.MaxInFlight > 0 || c.MaxInFlightReads > 0 || c.MaxInFlightWrites > 0 {
+		opts = append(opts, server.WithMaxInFlight(c.MaxInFlight, c.MaxInFlightReads, c.MaxInFlightWrites))
+	}
+
+	if len(c.PrefixPolicies) > 0 {
+		registry := policy.NewRegistry()
+		for _, p := range c.PrefixPolicies {
+			registry.SetPrefixPolicy(p.Bucket, p.Prefix, policy.PrefixPolicy{
+				ReadOnly:         p.ReadOnly,
+				AppendOnly:       p.AppendOnly,
+				MaxBytes:         p.MaxBytes,
+				RequiredMetadata: p.RequiredMetadata,
+			})
+		}
+
+		opts = append(opts, server.WithPrefixPolicies(registry))
+	}
+
+	if len(c.NotFoundKeys) > 0 {
+		registry := fallback.NewRegistry()
+		for _, f := range c.NotFoundKeys {
+			registry.SetBucketNotFoundKey(f.Bucket, f.Key, f.Status)
+		}
+
+		opts = append(opts, server.WithNotFoundKeys(registry))
+	}
+
+	if len(c.BucketDefaults) > 0 {
+		registry := defaults.NewRegistry()
+		for _, d := range c.BucketDefaults {
+			registry.SetBucketDefaults(d.Bucket, d.Tags, d.Metadata)
+		}
+
+		opts = append(opts, server.WithBucketDefaults(registry))
+	}
+
+	if c.GzipStatic {
+		opts = append(opts, server.WithGzipStatic())
+	}
+
+	if c.CompressListings {
+		opts = append(opts, server.WithListingCompression())
+	}
+
+	if c.NoOverwriteRename {
+		opts = append(opts, server.WithNoOverwriteRename())
+	}
+
+	verbosity, err := parseErrorVerbosity(c.ErrorVerbosity)
+	if err != nil {
+		return nil, err
+	}
+
+	if verbosity != server.ErrorsPublic {
+		opts = append(opts, server.WithErrorVerbosity(verbosity))
+	}
+
+	if c.ResumableDownloadsTTL > 0 {
+		opts = append(opts, server.WithResumableDownloads(c.ResumableDownloadsTTL))
+	}
+
+	if c.DefaultContentType != "" {
+		opts = append(opts, server.WithDefaultContentType(c.DefaultContentType))
+	}
+
+	if c.Region != "" {
+		opts = append(opts, server.WithRegion(c.Region))
+	}
+
+	if c.StrictRegion {
+		opts = append(opts, server.WithStrictRegion())
+	}
+
+	if c.URLIngest.Enabled {
+		opts = append(opts, server.WithURLIngest(c.URLIngest.options()...))
+	}
+
+	if c.AllowReset {
+		opts = append(opts, server.WithReset())
+	}
+
+	return opts, nil
+}
+
+// parseErrorVerbosity parses server.error_verbosity.
+func parseErrorVerbosity(s string) (server.ErrorVerbosity, error) {
+	switch s {
+	case "", "public":
+		return server.ErrorsPublic, nil
+	case "debug":
+		return server.ErrorsDebug, nil
+	default:
+		return server.ErrorsPublic, errors.Errorf("invalid server.error_verbosity %q (want public or debug)", s)
+	}
+}
+
+// handlerOptions converts the configuration to server handler options; nil
+// when rate limiting is disabled.
+func (c RateLimitConfig) handlerOptions() ([]server.HandlerOption, error) {
+	if c.PerIP == 0 {
+		return nil, nil
+	}
+
+	if c.PerIP < 0 || c.Burst <= 0 {
+		return nil, errors.New("server.rate_limit: per_ip must be positive and burst must be at least 1")
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+
+	for _, raw := range c.TrustedProxies {
+		p, err := netip.ParsePrefix(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "server.rate_limit.trusted_proxies: %q", raw)
+		}
+
+		prefixes = append(prefixes, p)
+	}
+
+	return []server.HandlerOption{
+		server.WithRateLimit(rate.Limit(c.PerIP), c.Burst),
+		server.WithTrustedProxies(prefixes...),
+	}, nil
 }
 
 // StorageConfig contains storage backend configuration.
@@ -194,10 +745,81 @@ type StorageConfig struct {
 	// defaults to "file"; set "none" for dev/CI to trade durability for speed.
 	Fsync string `yaml:"fsync,omitempty"`
 
+	// Dedup stores identical object bodies once (filesystem storage only).
+	Dedup bool `yaml:"dedup,omitempty"`
+
+	// Metadata is where per-object metadata is kept: "sidecar" (default,
+	// JSON files under <root>/.meta) or "xattr" (an extended attribute on
+	// each object file, falling back to sidecars where unsupported).
+	// Filesystem storage only; xattr excludes dedup.
+	Metadata string `yaml:"metadata,omitempty"`
+
+	// KeyEncoding maps object keys to file paths: "passthrough" (default,
+	// the key is the path), "percent" (unsafe characters percent-encoded per
+	// segment, for portable roots) or "hashed" (files named by a hash of the
+	// key, for any key at all; excludes strict_prefixes). Fixed for the life
+	// of a root. Filesystem storage only.
+	KeyEncoding string `yaml:"key_encoding,omitempty"`
+
+	// CaseSensitiveKeys also escapes uppercase and non-ASCII key bytes in
+	// file names, so keys differing only in case stay apart on
+	// case-insensitive filesystems (macOS, Windows); passthrough becomes
+	// percent. Fixed for the life of a root. Filesystem storage only.
+	CaseSensitiveKeys bool `yaml:"case_sensitive_keys,omitempty"`
+
+	// EncryptionKeyFile names a file holding a base64-encoded 32-byte key
+	// (`openssl rand -base64 32`); when set, object bodies are encrypted at
+	// rest with AES-256-GCM. Filesystem storage only; excludes dedup.
+	EncryptionKeyFile string `yaml:"encryption_key_file,omitempty"`
+
+	// TempDir is where uploads are streamed before they are placed in root,
+	// e.g. fast local disk when root is networked storage. On another
+	// filesystem each finished upload is copied once more (logged at
+	// startup). Filesystem storage only; default is <root>/.tmp.
+	TempDir string `yaml:"temp_dir,omitempty"`
+
+	// StrictPrefixes refuses PUTs under a key prefix whose directory does not
+	// exist yet instead of creating it. Filesystem storage only.
+	StrictPrefixes bool `yaml:"strict_prefixes,omitempty"`
+
+	// ReadAhead hints the OS to read ahead on full-object GETs (Linux
+	// fadvise). Filesystem storage only.
+	ReadAhead bool `yaml:"read_ahead,omitempty"`
+
+	// ExistenceFilterKeys, when positive, keeps an in-memory filter of
+	// existing keys sized for that many, so GETs of missing keys skip the
+	// disk. Files added to the root by hand are not seen until a restart.
+	// Filesystem storage only.
+	ExistenceFilterKeys int `yaml:"existence_filter_keys,omitempty"`
+
+	// ConsistentListings makes each listing a point-in-time view by holding
+	// back writes and deletes while it walks. Filesystem storage only.
+	ConsistentListings bool `yaml:"consistent_listings,omitempty"`
+
 	// Buckets to pre-create on startup (optional)
 	Buckets []string `yaml:"buckets,omitempty"`
 }
 
+// encryptionKey reads the at-rest encryption key named by EncryptionKeyFile,
+// or returns nil when none is configured.
+func (c StorageConfig) encryptionKey() ([]byte, error) {
+	if c.EncryptionKeyFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(c.EncryptionKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "read encryption key")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode encryption key")
+	}
+
+	return key, nil
+}
+
 // DefaultClusterAddr is the default cluster (peer replication) listener
 // address.
 const DefaultClusterAddr = ":7080"
@@ -381,6 +1003,11 @@ type ObservabilityConfig struct {
 	// EnableRequestLogging enables HTTP request logging
 	EnableRequestLogging bool `yaml:"enable_request_logging"`
 
+	// SlowRequestThreshold, when positive, logs a WARN "Slow request" record
+	// with bucket, key, sizes and timing for every request slower than it.
+	// Requires EnableRequestLogging.
+	SlowRequestThreshold time.Duration `yaml:"slow_request_threshold,omitempty"`
+
 	// EnableMetrics enables Prometheus metrics
 	EnableMetrics bool `yaml:"enable_metrics"`
 
@@ -440,6 +1067,73 @@ func LoadConfig(path string) (Config, error) {
 	return cfg, nil
 }
 
+// ConfigPathEnv names the config file when --config is not given.
+const ConfigPathEnv = "FS_CONFIG"
+
+// envOverrides are the environment variables that override config file
+// values; command-line flags override them in turn. Secrets and per-instance
+// cluster identity have their own variables (FS_ROOT_*, FS_CLUSTER_*).
+var envOverrides = []struct {
+	name string
+	set  func(c *Config, v string) error
+}{
+	{"FS_ADDR", func(c *Config, v string) error { c.Server.Addr = v; return nil }},
+	{"FS_ROOT", func(c *Config, v string) error { c.Storage.Root = v; return nil }},
+	{"FS_STORAGE_TYPE", func(c *Config, v string) error { c.Storage.Type = v; return nil }},
+	{"FS_FSYNC", func(c *Config, v string) error { c.Storage.Fsync = v; return nil }},
+	{"FS_TLS_CERT", func(c *Config, v string) error { c.Server.TLS.CertFile = v; return nil }},
+	{"FS_TLS_KEY", func(c *Config, v string) error { c.Server.TLS.KeyFile = v; return nil }},
+	{"FS_READ_TIMEOUT", func(c *Config, v string) error { return parseEnvDuration(v, &c.Server.ReadTimeout) }},
+	{"FS_WRITE_TIMEOUT", func(c *Config, v string) error { return parseEnvDuration(v, &c.Server.WriteTimeout) }},
+	{"FS_IDLE_TIMEOUT", func(c *Config, v string) error { return parseEnvDuration(v, &c.Server.IdleTimeout) }},
+	{"FS_MAX_KEY_LENGTH", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Server.MaxKeyLength = n
+
+		return err
+	}},
+	{"FS_RATE_LIMIT_PER_IP", func(c *Config, v string) error {
+		n, err := strconv.ParseFloat(v, 64)
+		c.Server.RateLimit.PerIP = n
+
+		return err
+	}},
+	{"FS_RATE_LIMIT_BURST", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Server.RateLimit.Burst = n
+
+		return err
+	}},
+	{"FS_NOTIFICATIONS_WEBHOOK_URL", func(c *Config, v string) error {
+		c.Server.Notifications.WebhookURL = v
+		return nil
+	}},
+}
+
+func parseEnvDuration(v string, d *time.Duration) error {
+	parsed, err := time.ParseDuration(v)
+	*d = parsed
+
+	return err
+}
+
+// ApplyEnv overrides settings from the environment variables in envOverrides,
+// read through lookup (os.LookupEnv outside tests). Empty values are ignored.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, o := range envOverrides {
+		v, ok := lookup(o.name)
+		if !ok || v == "" {
+			continue
+		}
+
+		if err := o.set(c, v); err != nil {
+			return errors.Wrapf(err, "%s", o.name)
+		}
+	}
+
+	return nil
+}
+
 // Validate validates the configuration.
 func (c *Config) Validate() error {
 	if c.Server.Addr == "" {
@@ -453,6 +1147,54 @@ func (c *Config) Validate() error {
 	switch c.Storage.Type {
 	case StorageTypeFilesystem:
 	case StorageTypeCluster:
+		if c.Storage.Dedup {
+			return errors.New("storage.dedup applies to filesystem storage only")
+		}
+
+		if c.Storage.Metadata != "" {
+			return errors.New("storage.metadata applies to filesystem storage only")
+		}
+
+		if c.Storage.KeyEncoding != "" {
+			return errors.New("storage.key_encoding applies to filesystem storage only")
+		}
+
+		if c.Storage.CaseSensitiveKeys {
+			return errors.New("storage.case_sensitive_keys applies to filesystem storage only")
+		}
+
+		if c.Storage.EncryptionKeyFile != "" {
+			return errors.New("storage.encryption_key_file applies to filesystem storage only")
+		}
+
+		if c.Storage.TempDir != "" {
+			return errors.New("storage.temp_dir applies to filesystem storage only")
+		}
+
+		if c.Storage.StrictPrefixes {
+			return errors.New("storage.strict_prefixes applies to filesystem storage only")
+		}
+
+		if c.Storage.ReadAhead {
+			return errors.New("storage.read_ahead applies to filesystem storage only")
+		}
+
+		if c.Storage.ConsistentListings {
+			return errors.New("storage.consistent_listings applies to filesystem storage only")
+		}
+
+		if c.Storage.ExistenceFilterKeys != 0 {
+			return errors.New("storage.existence_filter_keys applies to filesystem storage only")
+		}
+
+		if c.Integrity.VerifyWhileStreaming {
+			return errors.New("integrity.verify_while_streaming applies to filesystem storage only")
+		}
+
+		if c.Integrity.ScrubRate != 0 {
+			return errors.New("integrity.scrub_rate applies to filesystem storage only")
+		}
+
 		if err := c.validateCluster(); err != nil {
 			return err
 		}
@@ -460,6 +1202,32 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("unsupported storage type: %s (want %q or %q)", c.Storage.Type, StorageTypeFilesystem, StorageTypeCluster)
 	}
 
+	metaStore, err := storagefs.ParseMetadataStore(c.Storage.Metadata)
+	if err != nil {
+		return errors.Wrap(err, "storage.metadata")
+	}
+
+	if metaStore == storagefs.MetadataXattr && c.Storage.Dedup {
+		return errors.New("storage.metadata: xattr cannot be combined with storage.dedup")
+	}
+
+	if c.Storage.EncryptionKeyFile != "" && c.Storage.Dedup {
+		return errors.New("storage.encryption_key_file cannot be combined with storage.dedup")
+	}
+
+	keyEncoding, err := storagefs.ParseKeyEncoding(c.Storage.KeyEncoding)
+	if err != nil {
+		return errors.Wrap(err, "storage.key_encoding")
+	}
+
+	if keyEncoding == storagefs.KeyHashed && c.Storage.StrictPrefixes {
+		return errors.New("storage.key_encoding: hashed cannot be combined with storage.strict_prefixes")
+	}
+
+	if c.Storage.ExistenceFilterKeys < 0 {
+		return errors.New("storage.existence_filter_keys must not be negative")
+	}
+
 	switch c.Auth.Source {
 	case "", AuthSourceFile:
 	case AuthSourceEtcd:
@@ -486,10 +1254,89 @@ func (c *Config) Validate() error {
 		return errors.New("server.idle_timeout must be positive")
 	}
 
+	if _, err := c.Server.RateLimit.handlerOptions(); err != nil {
+		return err
+	}
+
+	if c.Server.MaxHeaderBytes < 0 {
+		return errors.New("server.max_header_bytes must not be negative")
+	}
+
+	if c.Server.MaxKeyLength < 0 {
+		return errors.New("server.max_key_length must not be negative")
+	}
+
+	if c.Server.MaxConcurrentUploads < 0 {
+		return errors.New("server.max_concurrent_uploads must not be negative")
+	}
+
+	for name, v := range map[string]int{
+		"max_connections":     c.Server.MaxConnections,
+		"max_inflight":        c.Server.MaxInFlight,
+		"max_inflight_reads":  c.Server.MaxInFlightReads,
+		"max_inflight_writes": c.Server.MaxInFlightWrites,
+	} {
+		if v < 0 {
+			return errors.Errorf("server.%s must not be negative", name)
+		}
+	}
+
+	if _, err := parseErrorVerbosity(c.Server.ErrorVerbosity); err != nil {
+		return err
+	}
+
+	if c.Server.ResumableDownloadsTTL < 0 {
+		return errors.New("server.resumable_downloads_ttl must not be negative")
+	}
+
+	if t := c.Server.DefaultContentType; t != "" {
+		if _, _, err := mime.ParseMediaType(t); err != nil {
+			return errors.Wrapf(err, "invalid server.default_content_type %q", t)
+		}
+	}
+
+	if err := c.Server.Notifications.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Server.Replication.validate(); err != nil {
+		return err
+	}
+
+	for i, p := range c.Server.PrefixPolicies {
+		if err := p.validate(); err != nil {
+			return errors.Wrapf(err, "server.prefix_policies[%d]", i)
+		}
+	}
+
+	for i, f := range c.Server.NotFoundKeys {
+		if err := f.validate(); err != nil {
+			return errors.Wrapf(err, "server.not_found_keys[%d]", i)
+		}
+	}
+
+	if u := c.Server.URLIngest; u.MaxSize < 0 || u.Timeout < 0 {
+		return errors.New("server.url_ingest: max_size and timeout must not be negative")
+	}
+
+	for i, d := range c.Server.BucketDefaults {
+		if err := d.validate(); err != nil {
+			return errors.Wrapf(err, "server.bucket_defaults[%d]", i)
+		}
+	}
+
+	if err := c.Integrity.validate(); err != nil {
+		return err
+	}
+
 	if c.Observability.ServiceName == "" {
 		return errors.New("observability.service_name is required")
 	}
 
+	if c.Observability.SlowRequestThreshold < 0 {
+		return errors.New("observability.slow_request_threshold must not be negative")
+	}
+
 	// Validate bucket names with the same rules the server enforces at runtime.
 	for _, bucket := range c.Storage.Buckets {
 		if err := validate.BucketName(bucket); err != nil {
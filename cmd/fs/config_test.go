@@ -1,6 +1,9 @@
 package main
 
 import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +11,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -172,6 +178,297 @@ func TestValidate_InvalidStorageType(t *testing.T) {
 	assert.Contains(t, err.Error(), "unsupported storage type")
 }
 
+func TestValidate_DedupFilesystemOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.Dedup = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.dedup")
+}
+
+func TestValidate_TempDirFilesystemOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.TempDir = "/var/tmp/fs"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.temp_dir")
+}
+
+func TestValidate_StrictPrefixesFilesystemOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.StrictPrefixes = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.strict_prefixes")
+}
+
+func TestValidate_ReadAheadFilesystemOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.ReadAhead = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.read_ahead")
+}
+
+func TestValidate_ConsistentListingsFilesystemOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.ConsistentListings = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.consistent_listings")
+}
+
+func TestValidate_ExistenceFilter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.ExistenceFilterKeys = 1_000_000
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.ExistenceFilterKeys = -1
+	require.ErrorContains(t, cfg.Validate(), "storage.existence_filter_keys")
+
+	cfg.Storage.ExistenceFilterKeys = 1_000_000
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.existence_filter_keys")
+}
+
+func TestValidate_MetadataStore(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.Metadata = "xattr"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Dedup = true
+	require.ErrorContains(t, cfg.Validate(), "storage.dedup")
+
+	cfg.Storage.Dedup = false
+	cfg.Storage.Metadata = "inode"
+	require.ErrorContains(t, cfg.Validate(), "storage.metadata")
+
+	cfg.Storage.Metadata = "sidecar"
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.metadata")
+}
+
+func TestValidate_KeyEncoding(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, e := range []string{"passthrough", "percent", "hashed"} {
+		cfg.Storage.KeyEncoding = e
+		require.NoError(t, cfg.Validate())
+	}
+
+	cfg.Storage.StrictPrefixes = true
+	require.ErrorContains(t, cfg.Validate(), "storage.strict_prefixes")
+
+	cfg.Storage.StrictPrefixes = false
+	cfg.Storage.KeyEncoding = "base64"
+	require.ErrorContains(t, cfg.Validate(), "storage.key_encoding")
+
+	cfg.Storage.KeyEncoding = "percent"
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.key_encoding")
+}
+
+func TestValidate_CaseSensitiveKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.CaseSensitiveKeys = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.case_sensitive_keys applies to filesystem storage only")
+}
+
+func TestValidate_EncryptionKeyFile(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.EncryptionKeyFile = "/etc/fs/sse.key"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Storage.Dedup = true
+	require.ErrorContains(t, cfg.Validate(), "storage.dedup")
+
+	cfg.Storage.Dedup = false
+	cfg.Storage.Type = StorageTypeCluster
+	require.ErrorContains(t, cfg.Validate(), "storage.encryption_key_file")
+}
+
+func TestStorageConfig_EncryptionKey(t *testing.T) {
+	key, err := StorageConfig{}.encryptionKey()
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	path := filepath.Join(t.TempDir(), "sse.key")
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(make([]byte, 32))+"\n"), 0o600))
+
+	key, err = StorageConfig{EncryptionKeyFile: path}.encryptionKey()
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	require.NoError(t, os.WriteFile(path, []byte("not base64!"), 0o600))
+
+	_, err = StorageConfig{EncryptionKeyFile: path}.encryptionKey()
+	require.ErrorContains(t, err, "decode encryption key")
+}
+
+func TestValidate_Integrity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Integrity = IntegrityConfig{VerifyWhileStreaming: true, QuarantineOnRead: true}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Integrity.VerifyOnRead = true
+	require.ErrorContains(t, cfg.Validate(), "exclusive")
+
+	cfg.Integrity = IntegrityConfig{QuarantineOnRead: true}
+	require.ErrorContains(t, cfg.Validate(), "quarantine_on_read")
+
+	cfg.Integrity = IntegrityConfig{ScrubInterval: time.Hour, ScrubRate: 1 << 20}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Integrity.ScrubRate = -1
+	require.ErrorContains(t, cfg.Validate(), "integrity.scrub_rate must not be negative")
+}
+
+func TestValidate_MaxKeyLength(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.MaxKeyLength = 512
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.MaxKeyLength = -1
+	require.ErrorContains(t, cfg.Validate(), "max_key_length")
+
+	cfg.Server.MaxKeyLength = 0
+	cfg.Server.MaxConcurrentUploads = -1
+	require.ErrorContains(t, cfg.Validate(), "max_concurrent_uploads")
+
+	cfg.Server.MaxConcurrentUploads = 0
+	cfg.Server.MaxInFlightWrites = -1
+	require.ErrorContains(t, cfg.Validate(), "max_inflight_writes")
+
+	cfg.Server.MaxInFlightWrites = 0
+	cfg.Server.ResumableDownloadsTTL = -time.Second
+	require.ErrorContains(t, cfg.Validate(), "resumable_downloads_ttl")
+}
+
+func TestValidate_ErrorVerbosity(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, v := range []string{"", "public", "debug"} {
+		cfg.Server.ErrorVerbosity = v
+		require.NoError(t, cfg.Validate())
+	}
+
+	cfg.Server.ErrorVerbosity = "verbose"
+	require.ErrorContains(t, cfg.Validate(), "server.error_verbosity")
+}
+
+func TestValidate_DefaultContentType(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.DefaultContentType = "text/plain; charset=utf-8"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.DefaultContentType = "text/plain; charset"
+	require.ErrorContains(t, cfg.Validate(), "server.default_content_type")
+}
+
+func TestValidate_Notifications(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Notifications = NotificationsConfig{WebhookURL: "https://hooks.example.com/s3", Buffer: 16}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.Notifications.WebhookURL = "hooks.example.com"
+	require.ErrorContains(t, cfg.Validate(), "webhook_url")
+
+	cfg.Server.Notifications = NotificationsConfig{Buffer: -1}
+	require.ErrorContains(t, cfg.Validate(), "notifications.buffer")
+
+	cfg.Server.Notifications = NotificationsConfig{Timeout: -time.Second}
+	require.ErrorContains(t, cfg.Validate(), "notifications.timeout")
+}
+
+func TestValidate_Replication(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Replication = ReplicationConfig{
+		Targets: []ReplicationTargetConfig{{Endpoint: "https://dr.example.com", AccessKey: "AK", SecretKey: "SK"}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.Replication.Targets[0].SecretKey = ""
+	require.ErrorContains(t, cfg.Validate(), "access_key and secret_key")
+
+	cfg.Server.Replication.Targets[0] = ReplicationTargetConfig{}
+	require.ErrorContains(t, cfg.Validate(), "targets[0].endpoint")
+
+	cfg.Server.Replication = ReplicationConfig{MaxAttempts: -1}
+	require.ErrorContains(t, cfg.Validate(), "replication.max_attempts")
+}
+
+func TestValidate_PrefixPolicies(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.PrefixPolicies = []PrefixPolicyConfig{
+		{Bucket: "shared", Prefix: "tenant-a/", ReadOnly: true},
+		{Bucket: "shared", Prefix: "tenant-b/", MaxBytes: 1 << 30, RequiredMetadata: []string{"owner"}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	opts, err := cfg.Server.handlerOptions()
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+
+	cfg.Server.PrefixPolicies[1].MaxBytes = -1
+	require.ErrorContains(t, cfg.Validate(), "prefix_policies[1]")
+
+	cfg.Server.PrefixPolicies[1] = PrefixPolicyConfig{Prefix: "tenant-b/"}
+	require.ErrorContains(t, cfg.Validate(), "bucket is required")
+}
+
+func TestValidate_NotFoundKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.NotFoundKeys = []NotFoundKeyConfig{
+		{Bucket: "app", Key: "index.html", Status: 200},
+		{Bucket: "docs", Key: "404.html"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	opts, err := cfg.Server.handlerOptions()
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+
+	cfg.Server.NotFoundKeys[1].Status = 42
+	require.ErrorContains(t, cfg.Validate(), "not_found_keys[1]")
+
+	cfg.Server.NotFoundKeys[1] = NotFoundKeyConfig{Bucket: "docs"}
+	require.ErrorContains(t, cfg.Validate(), "bucket and key are required")
+}
+
+func TestValidate_BucketDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.BucketDefaults = []BucketDefaultsConfig{
+		{Bucket: "logs", Tags: map[string]string{"cost-center": "42"}, Metadata: map[string]string{"environment": "prod"}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	opts, err := cfg.Server.handlerOptions()
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+
+	cfg.Server.BucketDefaults[0].Bucket = ""
+	require.ErrorContains(t, cfg.Validate(), "bucket_defaults[0]")
+}
+
+func TestValidate_RateLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.RateLimit = RateLimitConfig{PerIP: 10, Burst: 20, TrustedProxies: []string{"10.0.0.0/8"}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.RateLimit.Burst = 0
+	require.ErrorContains(t, cfg.Validate(), "burst")
+
+	cfg.Server.RateLimit.Burst = 20
+	cfg.Server.RateLimit.TrustedProxies = []string{"not-a-cidr"}
+	require.ErrorContains(t, cfg.Validate(), "trusted_proxies")
+}
+
 func TestValidate_InvalidTimeouts(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -448,3 +745,105 @@ func TestValidateClusterAcceptsEnvIdentity(t *testing.T) {
 
 	require.NoError(t, cfg.Validate())
 }
+
+func TestResolveConfig_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+
+	// yaml.v3 reads JSON too.
+	require.NoError(t, os.WriteFile(configPath, []byte(`{
+  "server": {"addr": ":9000", "read_timeout": "45s", "max_key_length": 10, "max_header_bytes": 4096,
+             "rate_limit": {"per_ip": 1, "burst": 1}},
+  "storage": {"root": "/from/file"}
+}`), 0o600))
+
+	env := map[string]string{
+		ConfigPathEnv:     configPath,
+		"FS_ADDR":         ":9100",
+		"FS_ROOT":         "/from/env",
+		"FS_IDLE_TIMEOUT": "5m",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cmd := S3()
+	require.NoError(t, cmd.Flags().Set("root", "/from/flag"))
+	require.NoError(t, cmd.Flags().Set("max-header-bytes", "8192"))
+	require.NoError(t, cmd.Flags().Set("disable-keep-alives", "true"))
+	require.NoError(t, cmd.Flags().Set("max-connections", "512"))
+	require.NoError(t, cmd.Flags().Set("max-inflight", "64"))
+
+	cfg, path, err := resolveConfig(cmd.Flags(), lookup)
+	require.NoError(t, err)
+
+	assert.Equal(t, configPath, path)
+	assert.Equal(t, ":9100", cfg.Server.Addr, "env overrides file")
+	assert.Equal(t, "/from/flag", cfg.Storage.Root, "flag overrides env")
+	assert.Equal(t, 45*time.Second, cfg.Server.ReadTimeout, "file overrides default")
+	assert.Equal(t, 5*time.Minute, cfg.Server.IdleTimeout)
+	assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout, "default")
+	assert.Equal(t, 8192, cfg.Server.MaxHeaderBytes, "flag overrides file")
+	assert.True(t, cfg.Server.DisableKeepAlives)
+	assert.Equal(t, 512, cfg.Server.MaxConnections)
+	assert.Equal(t, 64, cfg.Server.MaxInFlight)
+
+	// The resulting handler enforces the file's limits.
+	opts, err := cfg.Server.handlerOptions()
+	require.NoError(t, err)
+
+	h := server.NewHandler(storagemem.New(), opts...)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Content-Length", "0")
+		req.RemoteAddr = "192.0.2.1:1234"
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/bucket-a/0123456789a")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "KeyTooLongError")
+
+	rec = do(http.MethodGet, "/")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "burst of 1 is spent")
+}
+
+func TestResolveConfig_Errors(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+
+	t.Run("InvalidEnv", func(t *testing.T) {
+		lookup := func(name string) (string, bool) {
+			return "soon", name == "FS_READ_TIMEOUT"
+		}
+
+		_, _, err := resolveConfig(S3().Flags(), lookup)
+		require.ErrorContains(t, err, "FS_READ_TIMEOUT")
+	})
+
+	t.Run("ValidatedAfterFlags", func(t *testing.T) {
+		cmd := S3()
+		require.NoError(t, cmd.Flags().Set("addr", ""))
+
+		_, _, err := resolveConfig(cmd.Flags(), noEnv)
+		require.ErrorContains(t, err, "validate")
+	})
+
+	t.Run("FlagPathWinsOverEnv", func(t *testing.T) {
+		cmd := S3()
+		require.NoError(t, cmd.Flags().Set("config", "missing.yaml"))
+
+		lookup := func(name string) (string, bool) {
+			return "other.yaml", name == ConfigPathEnv
+		}
+
+		_, path, err := resolveConfig(cmd.Flags(), lookup)
+		require.NoError(t, err)
+		assert.Equal(t, "missing.yaml", path)
+	})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	"github.com/go-faster/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/go-faster/fs/server"
+)
+
+// registerConnMetrics exports the S3 listener's client connections: the open
+// ones by state, and the totals accepted and closed, so connection churn
+// (many short-lived clients, keep-alives off) shows up next to throughput.
+func registerConnMetrics(provider metric.MeterProvider, srv *server.Server) error {
+	meter := provider.Meter("go-faster/fs/server")
+
+	open, err := meter.Int64ObservableGauge("fs.server.connections",
+		metric.WithDescription("Open client connections by state (new, active, idle)."), metric.WithUnit("{connection}"))
+	if err != nil {
+		return errors.Wrap(err, "instrument fs.server.connections")
+	}
+
+	accepted, err := meter.Int64ObservableCounter("fs.server.connections.accepted",
+		metric.WithDescription("Client connections accepted."), metric.WithUnit("{connection}"))
+	if err != nil {
+		return errors.Wrap(err, "instrument fs.server.connections.accepted")
+	}
+
+	closed, err := meter.Int64ObservableCounter("fs.server.connections.closed",
+		metric.WithDescription("Client connections closed or hijacked."), metric.WithUnit("{connection}"))
+	if err != nil {
+		return errors.Wrap(err, "instrument fs.server.connections.closed")
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		st := srv.ConnStats()
+
+		o.ObserveInt64(open, st.New, metric.WithAttributes(attribute.String("state", "new")))
+		o.ObserveInt64(open, st.Active, metric.WithAttributes(attribute.String("state", "active")))
+		o.ObserveInt64(open, st.Idle, metric.WithAttributes(attribute.String("state", "idle")))
+		o.ObserveInt64(accepted, st.Accepted)
+		o.ObserveInt64(closed, st.Closed)
+
+		return nil
+	}, open, accepted, closed)
+	if err != nil {
+		return errors.Wrap(err, "register connection metrics callback")
+	}
+
+	return nil
+}
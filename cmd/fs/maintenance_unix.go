@@ -0,0 +1,32 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs/server"
+)
+
+// handleMaintenanceSignal flips maintenance mode on each SIGUSR1 until ctx is
+// canceled, so an operator can pause writes around a backup with kill alone.
+func handleMaintenanceSignal(ctx context.Context, lg *zap.Logger, m *server.Maintenance) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1)
+
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			lg.Info("Maintenance mode toggled (SIGUSR1)", zap.Bool("enabled", m.Toggle()))
+		}
+	}
+}
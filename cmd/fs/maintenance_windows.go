@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs/server"
+)
+
+// handleMaintenanceSignal is a no-op on Windows, which has no SIGUSR1; use the
+// ?maintenance admin endpoint instead.
+func handleMaintenanceSignal(context.Context, *zap.Logger, *server.Maintenance) {}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/go-faster/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/go-faster/fs/mirror"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// Credentials for the remote endpoint of `fs s3 mirror` and `fs s3 selftest`,
+// read from the environment only so the secret stays out of the process list.
+const (
+	envRemoteAccessKey = "FS_REMOTE_ACCESS_KEY"
+	envRemoteSecretKey = "FS_REMOTE_SECRET_KEY" //nolint:gosec // Env var name, not a credential.
+)
+
+// S3Mirror is `fs s3 mirror`: serve a read-only, pull-through cache of a
+// remote S3.
+func S3Mirror() *cobra.Command {
+	var (
+		remote       string
+		maxCacheSize string
+		noAuth       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mirror",
+		Short: "Serve a read-only, pull-through mirror of a remote S3",
+		Long: `Serve a read-only mirror of a remote S3 endpoint.
+
+An object not yet in the local root is fetched from the remote on first read,
+streamed to the client and written to the root as it goes; later reads are
+served from disk. Listings come from the remote, or from the local copy while
+the remote is unreachable. Writes are refused.
+
+With --max-cache-size the least recently read objects are deleted once the
+cached objects exceed it (bytes, or with a K, M, G or T suffix).
+
+The remote is addressed path-style and signed with $` + envRemoteAccessKey + ` and
+$` + envRemoteSecretKey + ` when set, anonymous otherwise. The server section of
+--config (address, TLS, auth, limits and the other handler settings) applies
+as for "fs s3"; notifications and replication do not, as nothing is written.`,
+		Example: `  # Cache a public endpoint under /var/cache/s3, at most 50 GiB
+  fs s3 mirror --remote https://s3.example.com --root /var/cache/s3 --max-cache-size 50G --insecure-no-auth
+
+  # Mirror a private endpoint behind the configured credentials
+  FS_REMOTE_ACCESS_KEY=... FS_REMOTE_SECRET_KEY=... fs s3 mirror --config config.yaml --remote s3.example.com`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, _, err := resolveConfig(cmd.Flags(), os.LookupEnv)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+
+			if cfg.Storage.Type == StorageTypeCluster {
+				return errors.New("the mirror caches into filesystem storage")
+			}
+
+			maxSize, err := parseByteSize(maxCacheSize)
+			if err != nil {
+				return errors.Wrap(err, "--max-cache-size")
+			}
+
+			authStore, err := buildAuthStore(cfg, noAuth)
+			if err != nil {
+				return errors.Wrap(err, "configure auth")
+			}
+
+			upstream, err := mirror.NewS3Upstream(remote, os.Getenv(envRemoteAccessKey), os.Getenv(envRemoteSecretKey))
+			if err != nil {
+				return errors.Wrap(err, "--remote")
+			}
+
+			root, err := filepath.Abs(cfg.Storage.Root)
+			if err != nil {
+				return errors.Wrap(err, "resolve root")
+			}
+
+			syncPolicy, err := storagefs.ParseSyncPolicy(cfg.Storage.Fsync)
+			if err != nil {
+				return errors.Wrap(err, "storage fsync policy")
+			}
+
+			local, err := storagefs.New(root, storagefs.WithSyncPolicy(syncPolicy))
+			if err != nil {
+				return errors.Wrap(err, "open storage")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			m, err := mirror.New(ctx, local, upstream, mirror.WithMaxCacheSize(maxSize))
+			if err != nil {
+				return errors.Wrap(err, "open mirror")
+			}
+
+			serverCfg := server.Config{
+				Storage:      m,
+				Addr:         cfg.Server.Addr,
+				ReadTimeout:  cfg.Server.ReadTimeout,
+				WriteTimeout: cfg.Server.WriteTimeout,
+				IdleTimeout:  cfg.Server.IdleTimeout,
+				HealthPath:   cfg.Server.HealthPath,
+				Auth:         authStore,
+
+				MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+				DisableKeepAlives: cfg.Server.DisableKeepAlives,
+				MaxConnections:    cfg.Server.MaxConnections,
+			}
+
+			handlerOpts, err := cfg.Server.handlerOptions()
+			if err != nil {
+				return err
+			}
+
+			serverCfg.HandlerOptions = append(serverCfg.HandlerOptions, handlerOpts...)
+
+			if cfg.Server.TLS.CertFile != "" && cfg.Server.TLS.KeyFile != "" {
+				serverCfg.TLS = &server.TLSConfig{
+					CertFile: cfg.Server.TLS.CertFile,
+					KeyFile:  cfg.Server.TLS.KeyFile,
+				}
+			}
+
+			srv, err := server.New(serverCfg)
+			if err != nil {
+				return errors.Wrap(err, "create server")
+			}
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "mirroring %s into %s (%d bytes cached) on %s\n",
+				remote, root, m.CacheSize(), cfg.Server.Addr)
+
+			return srv.ListenAndServe(ctx)
+		},
+	}
+
+	cmd.Flags().StringP("config", "c", "", "Path to a YAML (or JSON) configuration file (default $"+ConfigPathEnv+")")
+	cmd.Flags().String("addr", server.DefaultAddr, "Address to listen on (overrides config file and environment)")
+	cmd.Flags().String("root", DefaultStorageRoot, "Directory the mirrored objects are cached in (overrides config file and environment)")
+	cmd.Flags().String("tls-cert", "", "Path to the TLS certificate (enables HTTPS with --tls-key)")
+	cmd.Flags().String("tls-key", "", "Path to the TLS private key (enables HTTPS with --tls-cert)")
+	cmd.Flags().StringVar(&remote, "remote", "", "Endpoint of the S3 to mirror, e.g. https://s3.example.com")
+	cmd.Flags().StringVar(&maxCacheSize, "max-cache-size", "0", "Evict least recently read objects beyond this size (0: unlimited)")
+	cmd.Flags().BoolVar(&noAuth, "insecure-no-auth", false, "Disable authentication and serve anonymously (insecure)")
+
+	_ = cmd.MarkFlagRequired("remote")
+
+	return cmd
+}
+
+// parseByteSize parses a size in bytes with an optional binary K, M, G or T
+// suffix ("512M" is 512 MiB).
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	shift := 0
+
+	if s != "" {
+		switch strings.ToUpper(s[len(s)-1:]) {
+		case "K":
+			shift = 10
+		case "M":
+			shift = 20
+		case "G":
+			shift = 30
+		case "T":
+			shift = 40
+		}
+	}
+
+	if shift != 0 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+
+	if n < 0 || n > (1<<63-1)>>shift {
+		return 0, errors.Errorf("size %q out of range", s)
+	}
+
+	return n << shift, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"4K", 4 << 10},
+		{"512m", 512 << 20},
+		{"50G", 50 << 30},
+		{"2T", 2 << 40},
+	} {
+		got, err := parseByteSize(tt.in)
+		require.NoError(t, err, tt.in)
+		require.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"", "G", "-1", "1.5G", "10P", "9999999999T"} {
+		_, err := parseByteSize(in)
+		require.Error(t, err, in)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/go-faster/sdk/app"
 	"github.com/go-faster/sdk/zctx"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -23,19 +25,12 @@ import (
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/auth"
 	"github.com/go-faster/fs/internal/adminhandler"
+	"github.com/go-faster/fs/notify"
 	"github.com/go-faster/fs/server"
 	"github.com/go-faster/fs/storagefs"
 )
 
 func S3() *cobra.Command {
-	var (
-		configPath string
-		addr       string
-		root       string
-		tlsCert    string
-		tlsKey     string
-	)
-
 	cmd := &cobra.Command{
 		Use:   "s3",
 		Short: "Start S3-compatible storage server",
@@ -49,8 +44,9 @@ basic S3 API operations including:
 The server stores data in a local directory and provides an HTTP interface
 compatible with S3 clients.
 
-Configuration can be provided via YAML file (--config) or command-line flags.
-Command-line flags override YAML configuration values.`,
+Configuration can be provided via YAML file (--config, or $FS_CONFIG),
+environment variables (FS_ADDR, FS_ROOT, FS_TLS_CERT, ...) or command-line
+flags. Flags override the environment, which overrides the file.`,
 		Example: `  # Start server with YAML configuration
   fs s3 --config config.yaml
 
@@ -82,36 +78,12 @@ Command-line flags override YAML configuration values.`,
 				return
 			}
 
-			// Load configuration
-			cfg, err := LoadConfig(configPath)
+			cfg, configPath, err := resolveConfig(cmd.Flags(), os.LookupEnv)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 				os.Exit(1)
 			}
 
-			// Override with command-line flags if provided
-			if cmd.Flags().Changed("addr") {
-				cfg.Server.Addr = addr
-			}
-
-			if cmd.Flags().Changed("root") {
-				cfg.Storage.Root = root
-			}
-
-			if cmd.Flags().Changed("tls-cert") {
-				cfg.Server.TLS.CertFile = tlsCert
-			}
-
-			if cmd.Flags().Changed("tls-key") {
-				cfg.Server.TLS.KeyFile = tlsKey
-			}
-
-			// Validate configuration
-			if err := cfg.Validate(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error validating config: %v\n", err)
-				os.Exit(1)
-			}
-
 			insecureNoAuth, _ := cmd.Flags().GetBool("insecure-no-auth")
 
 			startTime := time.Now()
@@ -131,6 +103,10 @@ Command-line flags override YAML configuration values.`,
 					zap.Duration("read_timeout", cfg.Server.ReadTimeout),
 					zap.Duration("write_timeout", cfg.Server.WriteTimeout),
 					zap.Duration("idle_timeout", cfg.Server.IdleTimeout),
+					zap.Int("max_header_bytes", cfg.Server.MaxHeaderBytes),
+					zap.Bool("keep_alives", !cfg.Server.DisableKeepAlives),
+					zap.Int("max_connections", cfg.Server.MaxConnections),
+					zap.Int("max_inflight", cfg.Server.MaxInFlight),
 				)
 
 				// Make root path absolute
@@ -182,24 +158,99 @@ Command-line flags override YAML configuration values.`,
 						return errors.Wrap(err, "storage fsync policy")
 					}
 
-					fsStorage, err := storagefs.New(absRoot,
+					fsOpts := append([]storagefs.Option{
 						storagefs.WithSyncPolicy(syncPolicy),
-						storagefs.WithVerifyReads(cfg.Integrity.VerifyOnRead),
-					)
+					}, cfg.Integrity.storageOptions()...)
+					if cfg.Storage.Dedup {
+						fsOpts = append(fsOpts, storagefs.WithDedup())
+					}
+
+					metaStore, err := storagefs.ParseMetadataStore(cfg.Storage.Metadata)
+					if err != nil {
+						return errors.Wrap(err, "storage metadata store")
+					}
+
+					fsOpts = append(fsOpts, storagefs.WithMetadataStore(metaStore))
+
+					keyEncoding, err := storagefs.ParseKeyEncoding(cfg.Storage.KeyEncoding)
+					if err != nil {
+						return errors.Wrap(err, "storage key encoding")
+					}
+
+					fsOpts = append(fsOpts, storagefs.WithKeyEncoding(keyEncoding))
+
+					if cfg.Storage.CaseSensitiveKeys {
+						fsOpts = append(fsOpts, storagefs.WithCaseSensitiveKeys())
+					}
+
+					encryptionKey, err := cfg.Storage.encryptionKey()
+					if err != nil {
+						return errors.Wrap(err, "storage encryption")
+					}
+
+					if encryptionKey != nil {
+						fsOpts = append(fsOpts, storagefs.WithEncryptionKey(encryptionKey))
+					}
+
+					if cfg.Storage.TempDir != "" {
+						fsOpts = append(fsOpts, storagefs.WithTempDir(cfg.Storage.TempDir))
+					}
+
+					if cfg.Storage.StrictPrefixes {
+						fsOpts = append(fsOpts, storagefs.WithStrictPrefixes())
+					}
+
+					if cfg.Storage.ReadAhead {
+						fsOpts = append(fsOpts, storagefs.WithReadAhead())
+					}
+
+					if cfg.Storage.ConsistentListings {
+						fsOpts = append(fsOpts, storagefs.WithConsistentListings())
+					}
+
+					if cfg.Storage.ExistenceFilterKeys > 0 {
+						fsOpts = append(fsOpts, storagefs.WithExistenceFilter(cfg.Storage.ExistenceFilterKeys))
+					}
+
+					fsStorage, err := storagefs.New(absRoot, fsOpts...)
 					if err != nil {
 						return fmt.Errorf("failed to create storage: %w", err)
 					}
 
 					storage = fsStorage
 
+					if fsStorage.MetadataStore() != metaStore {
+						lg.Warn("Extended attributes unsupported, keeping metadata in sidecars",
+							zap.String("root", absRoot))
+					}
+
+					if fsStorage.KeysFoldCase() {
+						lg.Warn("Filesystem is case-insensitive: keys differing only in case overwrite each other; "+
+							"set storage.case_sensitive_keys for a new root",
+							zap.String("root", absRoot))
+					}
+
+					if fsStorage.CrossDeviceTemp() {
+						lg.Warn("Temp directory is on another filesystem than the root, copying each upload into place",
+							zap.String("temp_dir", cfg.Storage.TempDir), zap.String("root", absRoot))
+					}
+
 					// Background integrity scrubber (no-op unless an interval is
 					// set). Cluster-mode scrub/repair is the Phase 8 repair worker.
-					go runScrubber(ctx, lg, fsStorage, cfg.Integrity)
+					scrubTotals := &fsScrubTotals{}
+					if err := registerScrubMetrics(t.MeterProvider(), scrubTotals); err != nil {
+						return errors.Wrap(err, "register scrub metrics")
+					}
+
+					go runScrubber(ctx, lg, fsStorage, cfg.Integrity, scrubTotals)
 				}
 
 				lg.Info("Durability",
 					zap.String("fsync", cfg.Storage.Fsync),
 					zap.Bool("verify_on_read", cfg.Integrity.VerifyOnRead),
+					zap.Bool("verify_while_streaming", cfg.Integrity.VerifyWhileStreaming),
+					zap.Bool("dedup", cfg.Storage.Dedup),
+					zap.Bool("encryption", cfg.Storage.EncryptionKeyFile != ""),
 					zap.String("storage_type", cfg.Storage.Type),
 				)
 
@@ -249,7 +300,7 @@ Command-line flags override YAML configuration values.`,
 				// logging into the embeddable server's handler.
 				wrap := func(h http.Handler) http.Handler {
 					if cfg.Observability.EnableRequestLogging {
-						h = loggingMiddleware(h)
+						h = loggingMiddleware(h, WithSlowLog(cfg.Observability.SlowRequestThreshold))
 					}
 
 					return otelhttp.NewHandler(h, "Operation",
@@ -269,6 +320,10 @@ Command-line flags override YAML configuration values.`,
 					Buckets:      cfg.Storage.Buckets,
 					Auth:         authStore,
 					WrapHandler:  wrap,
+
+					MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+					DisableKeepAlives: cfg.Server.DisableKeepAlives,
+					MaxConnections:    cfg.Server.MaxConnections,
 					// Readiness probes storage reachability (health is liveness only).
 					Ready: func(ctx context.Context) error {
 						_, err := storage.ListBuckets(ctx)
@@ -276,6 +331,84 @@ Command-line flags override YAML configuration values.`,
 					},
 				}
 
+				handlerOpts, err := cfg.Server.handlerOptions()
+				if err != nil {
+					return err
+				}
+
+				serverCfg.HandlerOptions = append(serverCfg.HandlerOptions, handlerOpts...)
+
+				// Maintenance mode (writes refused, reads served) is toggled at
+				// runtime by SIGUSR1 or an admin key's PUT/DELETE /?maintenance.
+				maintenance := new(server.Maintenance)
+				serverCfg.HandlerOptions = append(serverCfg.HandlerOptions, server.WithMaintenance(maintenance))
+				go handleMaintenanceSignal(ctx, lg, maintenance)
+
+				if n := cfg.Server.Notifications; n.WebhookURL != "" {
+					buffer := n.Buffer
+					if buffer == 0 {
+						buffer = DefaultNotificationBuffer
+					}
+
+					timeout := n.Timeout
+					if timeout == 0 {
+						timeout = notify.DefaultWebhookTimeout
+					}
+
+					client := &http.Client{Timeout: timeout}
+					events := notify.NewQueue(notify.Webhook(n.WebhookURL, client), buffer)
+					serverCfg.HandlerOptions = append(serverCfg.HandlerOptions, server.WithEventSink(events.Send))
+
+					// Runs after the server has drained, so no request can
+					// still be sending; give the webhook a moment to catch up.
+					defer func() {
+						closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+						defer cancel()
+
+						if err := events.Close(closeCtx); err != nil {
+							lg.Warn("Undelivered event notifications abandoned", zap.Error(err))
+						}
+
+						if dropped := events.Dropped(); dropped > 0 {
+							lg.Warn("Event notifications dropped", zap.Int64("count", dropped))
+						}
+					}()
+
+					lg.Info("Event notifications", zap.String("webhook_url", n.WebhookURL), zap.Int("buffer", buffer), zap.Duration("timeout", timeout))
+				}
+
+				replicator, deadLetter, err := cfg.Server.Replication.replicator(storage)
+				if err != nil {
+					return err
+				}
+
+				if replicator != nil {
+					serverCfg.HandlerOptions = append(serverCfg.HandlerOptions, server.WithReplication(replicator))
+
+					// Like notifications: drain after the server has stopped.
+					defer func() {
+						closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+						defer cancel()
+
+						if err := replicator.Close(closeCtx); err != nil {
+							lg.Warn("Pending replication abandoned", zap.Error(err))
+						}
+
+						if deadLetter != nil {
+							_ = deadLetter.Close()
+						}
+
+						stats := replicator.Stats()
+						lg.Info("Replication stopped",
+							zap.Int64("replicated", stats.Replicated),
+							zap.Int64("failed", stats.Failed),
+							zap.Int64("dropped", stats.Dropped),
+						)
+					}()
+
+					lg.Info("Replication", zap.Int("targets", len(cfg.Server.Replication.Targets)))
+				}
+
 				if cfg.Server.TLS.CertFile != "" && cfg.Server.TLS.KeyFile != "" {
 					serverCfg.TLS = &server.TLSConfig{
 						CertFile: cfg.Server.TLS.CertFile,
@@ -299,6 +432,10 @@ Command-line flags override YAML configuration values.`,
 					return t.BaseContext()
 				}
 
+				if err := registerConnMetrics(t.MeterProvider(), srv); err != nil {
+					return errors.Wrap(err, "register connection metrics")
+				}
+
 				// Hot-reload credentials and TLS certificate on SIGHUP or via
 				// the admin reload endpoint — one reloader backs both.
 				rel := newReloader(lg, configPath, insecureNoAuth, authManager, srv)
@@ -360,17 +497,79 @@ Command-line flags override YAML configuration values.`,
 		},
 	}
 
-	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to YAML configuration file")
-	cmd.Flags().StringVar(&addr, "addr", server.DefaultAddr, "Address to listen on (overrides config file)")
-	cmd.Flags().StringVar(&root, "root", DefaultStorageRoot, "Root directory for S3 storage (overrides config file)")
-	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Path to the TLS certificate (enables HTTPS with --tls-key)")
-	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "Path to the TLS private key (enables HTTPS with --tls-cert)")
+	cmd.Flags().StringP("config", "c", "", "Path to a YAML (or JSON) configuration file (default $"+ConfigPathEnv+")")
+	cmd.Flags().String("addr", server.DefaultAddr, "Address to listen on (overrides config file and environment)")
+	cmd.Flags().String("root", DefaultStorageRoot, "Root directory for S3 storage (overrides config file and environment)")
+	cmd.Flags().String("tls-cert", "", "Path to the TLS certificate (enables HTTPS with --tls-key)")
+	cmd.Flags().String("tls-key", "", "Path to the TLS private key (enables HTTPS with --tls-cert)")
+	cmd.Flags().Int("max-header-bytes", 0, "Limit on request header size in bytes (default 1 MB; overrides config file)")
+	cmd.Flags().Bool("disable-keep-alives", false, "Close each client connection after one request (overrides config file)")
+	cmd.Flags().Int("max-connections", 0, "Limit on open client connections (default unlimited; overrides config file)")
+	cmd.Flags().Int("max-inflight", 0, "Limit on requests served at once, the rest get 503 SlowDown (default unlimited; overrides config file)")
 	cmd.Flags().Bool("insecure-no-auth", false, "Disable authentication and serve anonymously (insecure)")
 	cmd.Flags().Bool("generate-config", false, "Generate example configuration file and print to stdout")
 
+	cmd.AddCommand(S3Export())
+	cmd.AddCommand(S3ImportTar())
+	cmd.AddCommand(S3Mirror())
+	cmd.AddCommand(S3Selftest())
+
 	return cmd
 }
 
+// resolveConfig builds the effective configuration: defaults, then the config
+// file (--config, else $FS_CONFIG), then environment overrides, then the flags
+// set on the command line. It returns the validated config and the file path
+// it was read from (empty for none).
+func resolveConfig(flags *pflag.FlagSet, lookupEnv func(string) (string, bool)) (Config, string, error) {
+	path, _ := flags.GetString("config")
+	if path == "" {
+		path, _ = lookupEnv(ConfigPathEnv)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return Config{}, "", err
+	}
+
+	if err := cfg.ApplyEnv(lookupEnv); err != nil {
+		return Config{}, "", errors.Wrap(err, "environment")
+	}
+
+	for name, field := range map[string]*string{
+		"addr":     &cfg.Server.Addr,
+		"root":     &cfg.Storage.Root,
+		"tls-cert": &cfg.Server.TLS.CertFile,
+		"tls-key":  &cfg.Server.TLS.KeyFile,
+	} {
+		if flags.Changed(name) {
+			*field, _ = flags.GetString(name)
+		}
+	}
+
+	if flags.Changed("max-header-bytes") {
+		cfg.Server.MaxHeaderBytes, _ = flags.GetInt("max-header-bytes")
+	}
+
+	if flags.Changed("disable-keep-alives") {
+		cfg.Server.DisableKeepAlives, _ = flags.GetBool("disable-keep-alives")
+	}
+
+	if flags.Changed("max-connections") {
+		cfg.Server.MaxConnections, _ = flags.GetInt("max-connections")
+	}
+
+	if flags.Changed("max-inflight") {
+		cfg.Server.MaxInFlight, _ = flags.GetInt("max-inflight")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, "", errors.Wrap(err, "validate")
+	}
+
+	return cfg, path, nil
+}
+
 // bridgeSIGTERM converts the first SIGTERM into a SIGINT to this process, so
 // the app framework's SIGINT-based graceful shutdown fires for the SIGTERM
 // that systemd/Kubernetes/docker send on stop. Idempotent enough for a single
@@ -388,8 +587,29 @@ func bridgeSIGTERM() {
 	}()
 }
 
+// LoggingOption configures loggingMiddleware.
+type LoggingOption func(*loggingOptions)
+
+type loggingOptions struct {
+	slowThreshold time.Duration
+}
+
+// WithSlowLog makes loggingMiddleware emit a separate WARN "Slow request"
+// record, with the bucket, key, sizes and timing breakdown, for every request
+// taking longer than threshold. Zero disables the slowlog.
+func WithSlowLog(threshold time.Duration) LoggingOption {
+	return func(o *loggingOptions) {
+		o.slowThreshold = threshold
+	}
+}
+
 // loggingMiddleware logs HTTP requests
-func loggingMiddleware(next http.Handler) http.Handler {
+func loggingMiddleware(next http.Handler, opts ...LoggingOption) http.Handler {
+	var o loggingOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -399,24 +619,70 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(ww, r)
 
 		duration := time.Since(start)
+		lg := zctx.From(r.Context())
 
-		zctx.From(r.Context()).Info(r.Method,
+		lg.Info(r.Method,
 			zap.String("path", r.URL.Path),
 			zap.Int("status", ww.statusCode),
 			zap.Duration("duration", duration),
 			zap.String("remote_addr", r.RemoteAddr),
 			zap.String("user_agent", r.UserAgent()),
 		)
+
+		if o.slowThreshold <= 0 || duration <= o.slowThreshold {
+			return
+		}
+
+		// Time to first byte splits server-side processing from the time
+		// spent streaming the body to the client.
+		var firstByte time.Duration
+		if !ww.headerAt.IsZero() {
+			firstByte = ww.headerAt.Sub(start)
+		}
+
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+		lg.Warn("Slow request",
+			zap.String("method", r.Method),
+			zap.String("bucket", bucket),
+			zap.String("key", key),
+			zap.String("query", r.URL.RawQuery),
+			zap.Int("status", ww.statusCode),
+			zap.Int64("request_size", r.ContentLength),
+			zap.Int64("response_size", ww.written),
+			zap.Duration("first_byte", firstByte),
+			zap.Duration("duration", duration),
+			zap.Duration("threshold", o.slowThreshold),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
 	})
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code, the time
+// the response header was written and the number of body bytes.
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	headerAt   time.Time
+	written    int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
+	if rw.headerAt.IsZero() {
+		rw.headerAt = time.Now()
+	}
+
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if rw.headerAt.IsZero() {
+		rw.headerAt = time.Now()
+	}
+
+	n, err := rw.ResponseWriter.Write(p)
+	rw.written += int64(n)
+
+	return n, err
+}
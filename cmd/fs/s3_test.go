@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-faster/sdk/zctx"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggingMiddleware_SlowLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := zctx.Base(context.Background(), zap.New(core))
+
+	h := loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/slow") {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		_, _ = w.Write([]byte("hello"))
+	}), WithSlowLog(20*time.Millisecond))
+
+	serve := func(target string) {
+		req := httptest.NewRequest(http.MethodPut, target, strings.NewReader("body")).WithContext(ctx)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("/bucket-a/fast")
+	require.Empty(t, logs.FilterMessage("Slow request").All())
+	require.Equal(t, 1, logs.FilterMessage(http.MethodPut).Len())
+
+	serve("/bucket-a/dir/slow")
+
+	slow := logs.FilterMessage("Slow request").All()
+	require.Len(t, slow, 1)
+	require.Equal(t, zapcore.WarnLevel, slow[0].Level)
+
+	fields := slow[0].ContextMap()
+	require.Equal(t, "bucket-a", fields["bucket"])
+	require.Equal(t, "dir/slow", fields["key"])
+	require.Equal(t, int64(4), fields["request_size"])
+	require.Equal(t, int64(5), fields["response_size"])
+	require.GreaterOrEqual(t, fields["duration"], 50*time.Millisecond)
+}
@@ -2,26 +2,117 @@ package main
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-faster/errors"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
 	"github.com/go-faster/fs/storagefs"
 )
 
+// fsScrubTotals are cumulative filesystem scrub counters, updated by
+// scrubOnce and read by the metrics callback.
+type fsScrubTotals struct {
+	passes, failed, scanned, bytes atomic.Int64
+	corrupt, quarantined           atomic.Int64
+	unverifiable                   atomic.Int64
+	corruptLastPass                atomic.Int64
+}
+
+// observe folds one scrub pass in.
+func (s *fsScrubTotals) observe(report *storagefs.ScrubReport) {
+	s.passes.Add(1)
+	s.scanned.Add(int64(report.Scanned))
+	s.bytes.Add(report.Bytes)
+	s.corrupt.Add(int64(len(report.Corrupt)))
+	s.quarantined.Add(int64(report.Quarantined))
+	s.unverifiable.Add(int64(report.Unverifiable))
+	s.corruptLastPass.Store(int64(len(report.Corrupt)))
+}
+
+// registerScrubMetrics exports the filesystem scrubber's totals, and the
+// corrupt objects its last pass found as a gauge to alert on.
+func registerScrubMetrics(provider metric.MeterProvider, totals *fsScrubTotals) error {
+	meter := provider.Meter("go-faster/fs/storage")
+
+	var (
+		passes, failed, scanned, bytes metric.Int64ObservableCounter
+		corrupt, quarantined           metric.Int64ObservableCounter
+		unverifiable                   metric.Int64ObservableCounter
+		corruptLast                    metric.Int64ObservableGauge
+	)
+
+	counters := []struct {
+		target *metric.Int64ObservableCounter
+		name   string
+		desc   string
+		unit   string
+	}{
+		{&passes, "fs.scrub.passes", "Completed scrub passes.", "{pass}"},
+		{&failed, "fs.scrub.failed", "Scrub passes that stopped on an error.", "{pass}"},
+		{&scanned, "fs.scrub.objects", "Objects examined by the scrubber.", "{object}"},
+		{&bytes, "fs.scrub.bytes", "Object content read by the scrubber.", "By"},
+		{&corrupt, "fs.scrub.corrupt", "Objects the scrubber found not matching their stored checksum.", "{object}"},
+		{&quarantined, "fs.scrub.quarantined", "Corrupt objects the scrubber moved aside.", "{object}"},
+		{&unverifiable, "fs.scrub.unverifiable", "Objects the scrubber found with no stored checksum.", "{object}"},
+	}
+
+	observables := make([]metric.Observable, 0, len(counters)+1)
+
+	for _, c := range counters {
+		inst, err := meter.Int64ObservableCounter(c.name, metric.WithDescription(c.desc), metric.WithUnit(c.unit))
+		if err != nil {
+			return errors.Wrapf(err, "instrument %s", c.name)
+		}
+
+		*c.target = inst
+		observables = append(observables, inst)
+	}
+
+	corruptLast, err := meter.Int64ObservableGauge("fs.scrub.corrupt_last_pass",
+		metric.WithDescription("Corrupt objects found by the last completed scrub pass."), metric.WithUnit("{object}"))
+	if err != nil {
+		return errors.Wrap(err, "instrument fs.scrub.corrupt_last_pass")
+	}
+
+	observables = append(observables, corruptLast)
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(passes, totals.passes.Load())
+		o.ObserveInt64(failed, totals.failed.Load())
+		o.ObserveInt64(scanned, totals.scanned.Load())
+		o.ObserveInt64(bytes, totals.bytes.Load())
+		o.ObserveInt64(corrupt, totals.corrupt.Load())
+		o.ObserveInt64(quarantined, totals.quarantined.Load())
+		o.ObserveInt64(unverifiable, totals.unverifiable.Load())
+		o.ObserveInt64(corruptLast, totals.corruptLastPass.Load())
+
+		return nil
+	}, observables...)
+	if err != nil {
+		return errors.Wrap(err, "register scrub metrics callback")
+	}
+
+	return nil
+}
+
 // runScrubber runs the background integrity scrubber on the configured cadence
-// until ctx is canceled, logging each pass's result. A pass that finds
-// corruption is logged at error level (a loud, single-node report).
-func runScrubber(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage, cfg IntegrityConfig) {
+// until ctx is canceled, logging each pass's result and folding it into
+// totals. A pass that finds corruption is logged at error level (a loud,
+// single-node report). Canceling ctx (shutdown) stops a pass in progress.
+func runScrubber(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage, cfg IntegrityConfig, totals *fsScrubTotals) {
 	if cfg.ScrubInterval <= 0 {
 		return
 	}
 
-	opts := storagefs.ScrubOptions{Quarantine: cfg.ScrubQuarantine}
+	opts := storagefs.ScrubOptions{Quarantine: cfg.ScrubQuarantine, BytesPerSecond: cfg.ScrubRate}
 
 	lg.Info("Scrubber started",
 		zap.Duration("interval", cfg.ScrubInterval),
 		zap.Bool("quarantine", cfg.ScrubQuarantine),
+		zap.Int64("rate", cfg.ScrubRate),
 	)
 
 	ticker := time.NewTicker(cfg.ScrubInterval)
@@ -32,12 +123,12 @@ func runScrubber(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			scrubOnce(ctx, lg, storage, opts)
+			scrubOnce(ctx, lg, storage, opts, totals)
 		}
 	}
 }
 
-func scrubOnce(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage, opts storagefs.ScrubOptions) {
+func scrubOnce(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage, opts storagefs.ScrubOptions, totals *fsScrubTotals) {
 	start := time.Now()
 
 	report, err := storage.Scrub(ctx, opts)
@@ -46,17 +137,21 @@ func scrubOnce(ctx context.Context, lg *zap.Logger, storage *storagefs.Storage,
 			return // shutting down
 		}
 
+		totals.failed.Add(1)
 		lg.Error("Scrub failed", zap.Error(err))
 
 		return
 	}
 
+	totals.observe(report)
+
 	fields := []zap.Field{
 		zap.Int("scanned", report.Scanned),
 		zap.Int("ok", report.OK),
 		zap.Int("corrupt", len(report.Corrupt)),
 		zap.Int("unverifiable", report.Unverifiable),
 		zap.Int("quarantined", report.Quarantined),
+		zap.Int64("bytes", report.Bytes),
 		zap.Duration("took", time.Since(start)),
 	}
 
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagefs"
+)
+
+func TestScrubOnce_FlagsCorruption(t *testing.T) {
+	ctx := t.Context()
+	root := t.TempDir()
+
+	storage, err := storagefs.New(root)
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateBucket(ctx, "b"))
+
+	for _, key := range []string{"good", "rotted"} {
+		_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "b", Key: key, Reader: bytes.NewReader([]byte("content of " + key)), Size: int64(len("content of " + key)),
+		})
+		require.NoError(t, err)
+	}
+
+	// Bit-rot behind the server's back.
+	path := filepath.Join(root, "b", "rotted")
+	data, err := os.ReadFile(path) //nolint:gosec // test path.
+	require.NoError(t, err)
+
+	data[0] ^= 0xFF
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	reader := sdkmetric.NewManualReader()
+	totals := &fsScrubTotals{}
+	require.NoError(t, registerScrubMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), totals))
+
+	scrubOnce(ctx, zaptest.NewLogger(t), storage, storagefs.ScrubOptions{Quarantine: true}, totals)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	values := make(map[string]int64)
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			case metricdata.Gauge[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			}
+		}
+	}
+
+	require.Equal(t, int64(1), values["fs.scrub.passes"])
+	require.Equal(t, int64(2), values["fs.scrub.objects"])
+	require.Equal(t, int64(1), values["fs.scrub.corrupt"])
+	require.Equal(t, int64(1), values["fs.scrub.quarantined"])
+	require.Equal(t, int64(1), values["fs.scrub.corrupt_last_pass"])
+
+	_, err = storage.GetObject(ctx, "b", "rotted")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
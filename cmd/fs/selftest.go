@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/minio/minio-go/v7"
+	"github.com/spf13/cobra"
+
+	"github.com/go-faster/fs/internal/s3client"
+)
+
+// selftestCleanupTimeout bounds the removal of what a failed self-test left
+// behind.
+const selftestCleanupTimeout = 30 * time.Second
+
+// S3Selftest is `fs s3 selftest`: exercise the basic S3 operations against a
+// running server.
+func S3Selftest() *cobra.Command {
+	var endpoint, bucket string
+
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check a running server with a round of basic S3 operations",
+		Long: `Check a running S3 endpoint end to end.
+
+The self-test creates a bucket, puts an object, gets it back and compares the
+body, finds it in a listing, checks HEAD, then deletes the object and the
+bucket. Each step is printed as it passes; the first one that fails is named
+and the command exits non-zero. Whatever the test created is removed even when
+a step fails.
+
+The bucket is a fresh fs-selftest-* name unless --bucket is given; it must not
+exist yet. Requests are addressed path-style and signed with
+$` + envRemoteAccessKey + ` and $` + envRemoteSecretKey + ` when set, anonymous otherwise.`,
+		Example: `  # Smoke-test a deployment
+  FS_REMOTE_ACCESS_KEY=... FS_REMOTE_SECRET_KEY=... fs s3 selftest --endpoint https://s3.example.com
+
+  # Check a local server started with --insecure-no-auth
+  fs s3 selftest --endpoint http://localhost:8080`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, _, err := s3client.New(endpoint, os.Getenv(envRemoteAccessKey), os.Getenv(envRemoteSecretKey))
+			if err != nil {
+				return errors.Wrap(err, "--endpoint")
+			}
+
+			if bucket == "" {
+				bucket = "fs-selftest-" + strings.ToLower(rand.Text()[:12])
+			}
+
+			if err := runSelftest(cmd.Context(), client, bucket, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "self-test passed")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "Endpoint of the S3 to test, e.g. https://s3.example.com")
+	cmd.Flags().StringVar(&bucket, "bucket", "", "Bucket to create for the test (default a random fs-selftest-* name)")
+	_ = cmd.MarkFlagRequired("endpoint")
+
+	return cmd
+}
+
+// runSelftest runs the self-test steps against bucket in order, printing each
+// one to w as it passes. The returned error names the step that failed. What
+// the test created is removed before it returns, whatever the outcome.
+func runSelftest(ctx context.Context, client *minio.Client, bucket string, w io.Writer) (rErr error) {
+	const key = "selftest/object.txt"
+
+	body := []byte("fs self-test " + rand.Text() + "\n")
+
+	var bucketCreated, objectCreated bool
+
+	defer func() {
+		if !bucketCreated {
+			return
+		}
+
+		// The steps' context may be what failed them.
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), selftestCleanupTimeout)
+		defer cancel()
+
+		if objectCreated {
+			if err := client.RemoveObject(cleanupCtx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+				rErr = errors.Join(rErr, errors.Wrap(err, "cleanup: delete object"))
+			}
+		}
+
+		if err := client.RemoveBucket(cleanupCtx, bucket); err != nil {
+			rErr = errors.Join(rErr, errors.Wrap(err, "cleanup: delete bucket"))
+		}
+	}()
+
+	var putETag string
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"create-bucket", func() error {
+			if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+				return err
+			}
+
+			bucketCreated = true
+
+			return nil
+		}},
+		{"put", func() error {
+			info, err := client.PutObject(ctx, bucket, key, bytes.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+				ContentType: "text/plain",
+			})
+			if err != nil {
+				return err
+			}
+
+			objectCreated = true
+			putETag = info.ETag
+
+			return nil
+		}},
+		{"get", func() error {
+			obj, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+			if err != nil {
+				return err
+			}
+			defer func() { _ = obj.Close() }()
+
+			got, err := io.ReadAll(obj)
+			if err != nil {
+				return err
+			}
+
+			if !bytes.Equal(got, body) {
+				return errors.Errorf("body mismatch: got %d bytes, want %d", len(got), len(body))
+			}
+
+			return nil
+		}},
+		{"list", func() error {
+			for info := range client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true}) {
+				if info.Err != nil {
+					return info.Err
+				}
+
+				if info.Key != key {
+					continue
+				}
+
+				if info.Size != int64(len(body)) {
+					return errors.Errorf("listed size %d, want %d", info.Size, len(body))
+				}
+
+				return nil
+			}
+
+			return errors.Errorf("%q not listed", key)
+		}},
+		{"head", func() error {
+			info, err := client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+			if err != nil {
+				return err
+			}
+
+			if info.Size != int64(len(body)) {
+				return errors.Errorf("size %d, want %d", info.Size, len(body))
+			}
+
+			if info.ETag != putETag {
+				return errors.Errorf("ETag %q, want %q as returned by put", info.ETag, putETag)
+			}
+
+			return nil
+		}},
+		{"delete-object", func() error {
+			if err := client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+				return err
+			}
+
+			objectCreated = false
+
+			_, err := client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+			if code := minio.ToErrorResponse(err).Code; code != "NoSuchKey" {
+				return errors.Errorf("object still readable after delete (HEAD: %v)", err)
+			}
+
+			return nil
+		}},
+		{"delete-bucket", func() error {
+			if err := client.RemoveBucket(ctx, bucket); err != nil {
+				return err
+			}
+
+			bucketCreated = false
+
+			return nil
+		}},
+	}
+
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			_, _ = fmt.Fprintf(w, "FAIL %s: %v\n", step.name, err)
+			return errors.Wrapf(err, "step %s", step.name)
+		}
+
+		_, _ = fmt.Fprintf(w, "ok   %s\n", step.name)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// corruptStorage serves every object body with its first byte flipped.
+type corruptStorage struct {
+	fs.Storage
+}
+
+func (s corruptStorage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	obj, err := s.Storage.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(obj.Reader)
+	_ = obj.Reader.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		data[0] ^= 0xff
+	}
+
+	obj.Reader = io.NopCloser(bytes.NewReader(data))
+
+	return obj, nil
+}
+
+func runSelftestCommand(t *testing.T, store fs.Storage, args ...string) (string, error) {
+	t.Helper()
+
+	srv := httptest.NewServer(server.NewHandler(store))
+	t.Cleanup(srv.Close)
+
+	var out bytes.Buffer
+
+	cmd := S3Selftest()
+	cmd.SetArgs(append([]string{"--endpoint", srv.URL}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+
+	err := cmd.ExecuteContext(t.Context())
+
+	return out.String(), err
+}
+
+func TestSelftest(t *testing.T) {
+	store := storagemem.New()
+
+	out, err := runSelftestCommand(t, store)
+	require.NoError(t, err, out)
+
+	for _, step := range []string{"create-bucket", "put", "get", "list", "head", "delete-object", "delete-bucket"} {
+		require.Contains(t, out, "ok   "+step+"\n")
+	}
+
+	require.Contains(t, out, "self-test passed")
+
+	buckets, err := store.ListBuckets(t.Context())
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+}
+
+func TestSelftestFailureCleansUp(t *testing.T) {
+	store := storagemem.New()
+
+	out, err := runSelftestCommand(t, corruptStorage{Storage: store}, "--bucket", "smoke")
+	require.ErrorContains(t, err, "step get")
+	require.Contains(t, out, "ok   put\n")
+	require.Contains(t, out, "FAIL get: body mismatch")
+	require.NotContains(t, out, "list")
+
+	buckets, err := store.ListBuckets(t.Context())
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+}
+
+func TestSelftestExistingBucket(t *testing.T) {
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(t.Context(), "taken"))
+
+	out, err := runSelftestCommand(t, store, "--bucket", "taken")
+	require.ErrorContains(t, err, "step create-bucket")
+	require.True(t, strings.HasPrefix(out, "FAIL create-bucket"), out)
+
+	// A bucket the test did not create is left alone.
+	_, err = store.ListObjects(t.Context(), "taken", "")
+	require.NoError(t, err)
+}
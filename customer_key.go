@@ -0,0 +1,33 @@
+package fs
+
+import "context"
+
+// CustomerKey is an SSE-C encryption key supplied by the client with a request
+// (x-amz-server-side-encryption-customer-*). Backends encrypt or decrypt the
+// object body with Key and may persist KeyMD5 to recognize the key later, but
+// never Key itself.
+type CustomerKey struct {
+	// Key is the 256-bit AES key.
+	Key []byte
+	// KeyMD5 is the base64 MD5 of Key, as sent in
+	// x-amz-server-side-encryption-customer-key-MD5.
+	KeyMD5 string
+}
+
+type customerKeyContextKey struct{}
+
+// WithCustomerKey returns a context carrying key for the object operations
+// (PutObject, GetObject) made with it. The Storage interface has no per-call
+// options, and the key must not outlive the request, so it travels with the
+// context. Backends without SSE-C support return ErrUnsupportedOperation when
+// one is present.
+func WithCustomerKey(ctx context.Context, key *CustomerKey) context.Context {
+	return context.WithValue(ctx, customerKeyContextKey{}, key)
+}
+
+// CustomerKeyFromContext returns the SSE-C key attached by WithCustomerKey, or
+// nil.
+func CustomerKeyFromContext(ctx context.Context) *CustomerKey {
+	key, _ := ctx.Value(customerKeyContextKey{}).(*CustomerKey)
+	return key
+}
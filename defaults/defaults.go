@@ -0,0 +1,71 @@
+// Package defaults holds per-bucket default tags and metadata for the S3
+// server: values every new object in a bucket receives unless the upload sets
+// them itself, for cost-allocation tags or environment labels that no client
+// has to remember. Defaults are set at runtime on a Registry and take effect
+// on the next upload.
+package defaults
+
+import (
+	"maps"
+	"strings"
+	"sync"
+)
+
+// Registry maps buckets to their default tags and metadata. The zero value is
+// empty and ready to use; it is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	defaults map[string]entry // bucket -> defaults
+}
+
+type entry struct {
+	tags     map[string]string
+	metadata map[string]string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry { return &Registry{} }
+
+// SetBucketDefaults sets the tags and x-amz-meta-* metadata (names without
+// the prefix, case-insensitive) merged into every object uploaded to bucket,
+// replacing any defaults set before. A tag or metadata name the upload sets
+// itself keeps the upload's value. Both maps are copied.
+func (r *Registry) SetBucketDefaults(bucket string, tags, metadata map[string]string) {
+	e := entry{tags: maps.Clone(tags)}
+
+	if len(metadata) > 0 {
+		e.metadata = make(map[string]string, len(metadata))
+		for name, value := range metadata {
+			e.metadata[strings.ToLower(name)] = value
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.defaults == nil {
+		r.defaults = make(map[string]entry)
+	}
+
+	r.defaults[bucket] = e
+}
+
+// RemoveBucketDefaults removes the defaults of bucket.
+func (r *Registry) RemoveBucketDefaults(bucket string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.defaults, bucket)
+}
+
+// BucketDefaults returns the default tags and metadata of bucket, with
+// lowercase metadata names. The maps must not be modified. ok is false when
+// bucket has none.
+func (r *Registry) BucketDefaults(bucket string) (tags, metadata map[string]string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.defaults[bucket]
+
+	return e.tags, e.metadata, ok
+}
@@ -0,0 +1,32 @@
+package defaults_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/defaults"
+)
+
+func TestRegistry(t *testing.T) {
+	var r defaults.Registry
+
+	_, _, ok := r.BucketDefaults("logs")
+	require.False(t, ok)
+
+	tags := map[string]string{"cost-center": "42"}
+	r.SetBucketDefaults("logs", tags, map[string]string{"Environment": "prod"})
+
+	// The registry keeps its own copy.
+	tags["cost-center"] = "changed"
+
+	gotTags, gotMeta, ok := r.BucketDefaults("logs")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"cost-center": "42"}, gotTags)
+	require.Equal(t, map[string]string{"environment": "prod"}, gotMeta, "metadata names are lowercased")
+
+	r.RemoveBucketDefaults("logs")
+
+	_, _, ok = r.BucketDefaults("logs")
+	require.False(t, ok)
+}
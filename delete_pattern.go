@@ -0,0 +1,80 @@
+package fs
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// DeleteObjectsByPattern deletes every object of bucket whose key matches
+// pattern under path.Match semantics, and returns how many it deleted. As in
+// path.Match, "*" and "?" do not match "/": "logs/2023/*" removes the objects
+// directly under logs/2023/, not those in its subdirectories.
+//
+// The matching keys are collected from a single listing before anything is
+// deleted, so keys written or removed during the sweep are neither skipped
+// nor visited twice: an object created meanwhile is left alone, one deleted
+// meanwhile is not counted. A malformed pattern is path.ErrBadPattern, with
+// nothing deleted. On error the count covers the objects already deleted.
+func DeleteObjectsByPattern(ctx context.Context, s Storage, bucket, pattern string) (int, error) {
+	return DeleteObjectsByPatternFunc(ctx, s, bucket, pattern, nil)
+}
+
+// DeleteObjectsByPatternFunc is DeleteObjectsByPattern calling deleted, when
+// not nil, with the key of each object as soon as it is deleted. The listing
+// is over by then, so deleted may write to the bucket.
+func DeleteObjectsByPatternFunc(ctx context.Context, s Storage, bucket, pattern string, deleted func(key string)) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, errors.Wrapf(err, "pattern %q", pattern)
+	}
+
+	// Only keys sharing the pattern's literal lead can match it.
+	objects, err := s.ListObjects(ctx, bucket, patternPrefix(pattern))
+	if err != nil {
+		return 0, errors.Wrap(err, "list objects")
+	}
+
+	var keys []string
+
+	for _, o := range objects {
+		if ok, _ := path.Match(pattern, o.Key); ok {
+			keys = append(keys, o.Key)
+		}
+	}
+
+	var count int
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		if err := s.DeleteObject(ctx, bucket, key); err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+
+			return count, errors.Wrapf(err, "delete %q", key)
+		}
+
+		count++
+
+		if deleted != nil {
+			deleted(key)
+		}
+	}
+
+	return count, nil
+}
+
+// patternPrefix returns the literal part of pattern before its first
+// metacharacter.
+func patternPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+
+	return pattern
+}
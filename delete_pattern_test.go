@@ -0,0 +1,81 @@
+package fs_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestDeleteObjectsByPattern(t *testing.T) {
+	ctx := t.Context()
+	s := storagemem.New()
+	require.NoError(t, s.CreateBucket(ctx, "bucket"))
+
+	for _, key := range []string{
+		"logs/2023/jan.log",
+		"logs/2023/feb.log",
+		"logs/2023/archive/dec.log",
+		"logs/2024/jan.log",
+		"logs/2023.txt",
+		"other",
+	} {
+		_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "bucket", Key: key, Reader: strings.NewReader("x"), Size: 1,
+		})
+		require.NoError(t, err)
+	}
+
+	deleted, err := fs.DeleteObjectsByPattern(ctx, s, "bucket", "logs/2023/*")
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	keys := func() []string {
+		objects, err := s.ListObjects(ctx, "bucket", "")
+		require.NoError(t, err)
+
+		var keys []string
+		for _, o := range objects {
+			keys = append(keys, o.Key)
+		}
+
+		return keys
+	}
+
+	require.ElementsMatch(t, []string{
+		"logs/2023/archive/dec.log",
+		"logs/2024/jan.log",
+		"logs/2023.txt",
+		"other",
+	}, keys())
+
+	deleted, err = fs.DeleteObjectsByPattern(ctx, s, "bucket", "logs/202?/*.log")
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	require.ElementsMatch(t, []string{"logs/2023/archive/dec.log", "logs/2023.txt", "other"}, keys())
+
+	deleted, err = fs.DeleteObjectsByPattern(ctx, s, "bucket", "nothing*")
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	_, err = fs.DeleteObjectsByPattern(ctx, s, "bucket", "logs/[")
+	require.ErrorIs(t, err, path.ErrBadPattern)
+	require.Len(t, keys(), 3)
+
+	_, err = fs.DeleteObjectsByPattern(ctx, s, "missing", "*")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+	var reported []string
+
+	deleted, err = fs.DeleteObjectsByPatternFunc(ctx, s, "bucket", "logs/*.txt", func(key string) {
+		reported = append(reported, key)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	require.Equal(t, []string{"logs/2023.txt"}, reported)
+	require.ElementsMatch(t, []string{"logs/2023/archive/dec.log", "other"}, keys())
+}
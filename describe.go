@@ -0,0 +1,91 @@
+package fs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// ObjectDescription is everything known about an object, in one document:
+// what HEAD spreads over response headers plus its tags.
+type ObjectDescription struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	// ETag is unquoted, as stored.
+	ETag               string    `json:"etag"`
+	LastModified       time.Time `json:"last_modified"`
+	ContentType        string    `json:"content_type,omitempty"`
+	CacheControl       string    `json:"cache_control,omitempty"`
+	ContentDisposition string    `json:"content_disposition,omitempty"`
+	ContentEncoding    string    `json:"content_encoding,omitempty"`
+	// UserMetadata holds the x-amz-meta-* pairs, keyed by lowercase name
+	// without the prefix.
+	UserMetadata map[string]string `json:"user_metadata"`
+	// Tags is the object's tag set as a map; tag keys are unique.
+	Tags map[string]string `json:"tags"`
+	// StorageClass is always STANDARD, the only class served.
+	StorageClass         string `json:"storage_class"`
+	ServerSideEncryption string `json:"server_side_encryption,omitempty"`
+	// PartSizes are the sizes of the parts a multipart object was completed
+	// from, when the backend records them.
+	PartSizes []int64 `json:"part_sizes,omitempty"`
+	// Checksums maps an algorithm to the hex digest of the content. Only
+	// "md5" is known, and only for an object written in one piece: a
+	// multipart ETag is not a digest of the content.
+	Checksums map[string]string `json:"checksums,omitempty"`
+	// MetadataMissing reports that the stored metadata could not be read, so
+	// the fields above hold defaults (see GetObjectResponse).
+	MetadataMissing bool `json:"metadata_missing,omitempty"`
+}
+
+// DescribeObject returns the metadata, tags and checksums of bucket/key
+// without reading its body. It is the library side of the GET ?meta
+// extension.
+func DescribeObject(ctx context.Context, s Storage, bucket, key string) (*ObjectDescription, error) {
+	obj, err := s.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = obj.Reader.Close()
+
+	tags, err := s.GetObjectTagging(ctx, bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get tags")
+	}
+
+	d := &ObjectDescription{
+		Bucket:               bucket,
+		Key:                  key,
+		Size:                 obj.Size,
+		ETag:                 strings.Trim(obj.ETag, `"`),
+		LastModified:         obj.LastModified.UTC(),
+		ContentType:          obj.Metadata.ContentType,
+		CacheControl:         obj.Metadata.CacheControl,
+		ContentDisposition:   obj.Metadata.ContentDisposition,
+		ContentEncoding:      obj.Metadata.ContentEncoding,
+		UserMetadata:         make(map[string]string, len(obj.Metadata.UserMetadata)),
+		Tags:                 make(map[string]string, len(tags)),
+		StorageClass:         "STANDARD",
+		ServerSideEncryption: obj.ServerSideEncryption,
+		PartSizes:            obj.PartSizes,
+		MetadataMissing:      obj.MetadataMissing,
+	}
+
+	for k, v := range obj.Metadata.UserMetadata {
+		d.UserMetadata[k] = v
+	}
+
+	for _, tag := range tags {
+		d.Tags[tag.Key] = tag.Value
+	}
+
+	if d.ETag != "" && !strings.Contains(d.ETag, "-") {
+		d.Checksums = map[string]string{"md5": d.ETag}
+	}
+
+	return d, nil
+}
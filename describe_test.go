@@ -0,0 +1,51 @@
+package fs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestDescribeObject(t *testing.T) {
+	ctx := t.Context()
+	s := storagemem.New()
+	require.NoError(t, s.CreateBucket(ctx, "bucket"))
+
+	_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "bucket", Key: "plain", Reader: strings.NewReader("body"), Size: 4,
+	})
+	require.NoError(t, err)
+
+	d, err := fs.DescribeObject(ctx, s, "bucket", "plain")
+	require.NoError(t, err)
+	require.EqualValues(t, 4, d.Size)
+	require.Empty(t, d.Tags)
+	require.Equal(t, map[string]string{"md5": d.ETag}, d.Checksums)
+
+	upload, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "bucket", Key: "mp"})
+	require.NoError(t, err)
+
+	part, err := s.UploadPart(ctx, &fs.UploadPartRequest{
+		Bucket: "bucket", Key: "mp", UploadID: upload.UploadID, PartNumber: 1,
+		Reader: strings.NewReader("part"), Size: 4,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: "bucket", Key: "mp", UploadID: upload.UploadID,
+		Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+	})
+	require.NoError(t, err)
+
+	d, err = fs.DescribeObject(ctx, s, "bucket", "mp")
+	require.NoError(t, err)
+	require.Contains(t, d.ETag, "-")
+	require.Nil(t, d.Checksums, "a multipart ETag is not a content digest")
+
+	_, err = fs.DescribeObject(ctx, s, "bucket", "missing")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
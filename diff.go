@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// DiffObjects compares bucket with clientState, the key → ETag map of a
+// client's copy, and returns the keys the client lacks (added), holds with a
+// different ETag (changed), and holds but the bucket no longer has
+// (deleted), each sorted. ETags compare with their quotes stripped, so
+// either the quoted form of a response header or a listing's bare one may
+// be given.
+//
+// The bucket side is a single listing, so the diff is consistent with the
+// bucket as it was at one point during the call; a sync tool applying it
+// should still expect the bucket to have moved on.
+func DiffObjects(ctx context.Context, s Storage, bucket string, clientState map[string]string) (added, changed, deleted []string, err error) {
+	objects, err := s.ListObjects(ctx, bucket, "")
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "list objects")
+	}
+
+	seen := make(map[string]struct{}, len(objects))
+
+	for _, o := range objects {
+		seen[o.Key] = struct{}{}
+
+		etag, ok := clientState[o.Key]
+
+		switch {
+		case !ok:
+			added = append(added, o.Key)
+		case strings.Trim(etag, `"`) != strings.Trim(o.ETag, `"`):
+			changed = append(changed, o.Key)
+		}
+	}
+
+	for key := range clientState {
+		if _, ok := seen[key]; !ok {
+			deleted = append(deleted, key)
+		}
+	}
+
+	slices.Sort(added)
+	slices.Sort(changed)
+	slices.Sort(deleted)
+
+	return added, changed, deleted, nil
+}
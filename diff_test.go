@@ -0,0 +1,64 @@
+package fs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestDiffObjects(t *testing.T) {
+	ctx := t.Context()
+	s := storagemem.New()
+	require.NoError(t, s.CreateBucket(ctx, "bucket"))
+
+	put := func(key, content string) string {
+		t.Helper()
+
+		resp, err := s.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "bucket", Key: key, Reader: strings.NewReader(content), Size: int64(len(content)),
+		})
+		require.NoError(t, err)
+
+		return resp.ETag
+	}
+
+	same := put("same.txt", "unchanged")
+	stale := put("edited.txt", "v1")
+	put("edited.txt", "v2")
+	put("new/b.txt", "b")
+	put("new/a.txt", "a")
+
+	// The client's copy is partly stale: one key it holds was edited, two
+	// were removed, and two it has never seen were uploaded since.
+	client := map[string]string{
+		"same.txt":   `"` + same + `"`, // Quoted, as in an ETag header.
+		"edited.txt": stale,
+		"gone.txt":   "d41d8cd98f00b204e9800998ecf8427e",
+		"also-gone":  "0cc175b9c0f1b6a831c399e269772661",
+	}
+
+	added, changed, deleted, err := fs.DiffObjects(ctx, s, "bucket", client)
+	require.NoError(t, err)
+	require.Equal(t, []string{"new/a.txt", "new/b.txt"}, added)
+	require.Equal(t, []string{"edited.txt"}, changed)
+	require.Equal(t, []string{"also-gone", "gone.txt"}, deleted)
+
+	// An up-to-date client has nothing to transfer.
+	added, changed, deleted, err = fs.DiffObjects(ctx, s, "bucket", map[string]string{
+		"same.txt":   same,
+		"edited.txt": put("edited.txt", "v2"),
+		"new/a.txt":  put("new/a.txt", "a"),
+		"new/b.txt":  put("new/b.txt", "b"),
+	})
+	require.NoError(t, err)
+	require.Empty(t, added)
+	require.Empty(t, changed)
+	require.Empty(t, deleted)
+
+	_, _, _, err = fs.DiffObjects(ctx, s, "missing", nil)
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
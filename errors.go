@@ -2,15 +2,35 @@ package fs
 
 import "github.com/go-faster/errors"
 
+// Sentinel errors returned by Storage implementations and the S3 service
+// layer. They are always wrapped with context, so compare with errors.Is, never
+// with == or by message; the S3 handler maps each to its error code.
 var (
-	ErrBucketNotFound       = errors.New("bucket not found")
-	ErrBucketAlreadyExists  = errors.New("bucket already exists")
-	ErrBucketNotEmpty       = errors.New("bucket not empty")
-	ErrObjectNotFound       = errors.New("object not found")
-	ErrUploadNotFound       = errors.New("upload not found")
-	ErrInvalidBucketName    = errors.New("invalid bucket name")
+	// ErrBucketNotFound reports that the named bucket does not exist.
+	ErrBucketNotFound = errors.New("bucket not found")
+	// ErrBucketAlreadyExists reports a CreateBucket for an existing bucket.
+	ErrBucketAlreadyExists = errors.New("bucket already exists")
+	// ErrBucketNotEmpty reports a DeleteBucket on a bucket still holding
+	// objects.
+	ErrBucketNotEmpty = errors.New("bucket not empty")
+	// ErrObjectNotFound reports that the named object does not exist.
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrUploadNotFound reports an unknown (or already completed or aborted)
+	// multipart upload ID.
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrInvalidBucketName reports a bucket name violating the S3 naming rules
+	// (3-63 lowercase letters, digits, dots and hyphens, not IP-shaped).
+	ErrInvalidBucketName = errors.New("invalid bucket name")
+	// ErrInvalidKey reports an object key, or listing prefix, that is empty,
+	// not valid UTF-8, or could escape its bucket on disk ("..", backslashes,
+	// drive letters, control characters). Over-long keys are ErrKeyTooLong.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrUnsupportedOperation reports an operation the backend does not
+	// implement.
 	ErrUnsupportedOperation = errors.New("unsupported operation")
-	ErrPreconditionFailed   = errors.New("precondition failed")
+	// ErrPreconditionFailed reports a conditional request (If-Match etc.) whose
+	// condition did not hold.
+	ErrPreconditionFailed = errors.New("precondition failed")
 
 	// ErrInvalidPart reports that a part referenced by CompleteMultipartUpload
 	// was never uploaded or its ETag does not match.
@@ -23,11 +43,51 @@ var (
 	// ErrEntityTooSmall reports a non-last multipart part smaller than the 5 MiB
 	// minimum.
 	ErrEntityTooSmall = errors.New("entity too small")
+	// ErrKeyTooLong reports an object key over the configured length limit
+	// (1024 bytes by default, as on S3), or with a path segment longer than
+	// the backend can store.
+	ErrKeyTooLong = errors.New("key too long")
+	// ErrMetadataTooLarge reports x-amz-meta-* user metadata over the
+	// configured size limit (2 KB by default, as on S3).
+	ErrMetadataTooLarge = errors.New("metadata too large")
 	// ErrInvalidTag reports an object tag set violating the S3 limits
 	// (at most 10 tags, unique keys, key ≤ 128 chars, value ≤ 256 chars).
 	ErrInvalidTag = errors.New("invalid tag")
 
+	// ErrCustomerKeyMismatch reports an SSE-C key that is not the one the
+	// object was encrypted with.
+	ErrCustomerKeyMismatch = errors.New("customer key does not match")
+	// ErrEncryptionParameters reports SSE-C parameters that do not fit the
+	// object: none for an object encrypted with a customer key, or some for
+	// one that is not.
+	ErrEncryptionParameters = errors.New("encryption parameters do not match the object")
+
+	// ErrAccessDenied reports a request refused by a server-side policy (e.g.
+	// a write under a read-only prefix, or a change to an object under legal
+	// hold), independent of the caller's grants.
+	ErrAccessDenied = errors.New("access denied")
+	// ErrQuotaExceeded reports an upload that would take a prefix past its
+	// size quota.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrMissingMetadata reports a new object lacking x-amz-meta-* metadata a
+	// policy requires.
+	ErrMissingMetadata = errors.New("required metadata missing")
+	// ErrPrefixNotFound reports a write under a key prefix that does not exist
+	// on a backend configured not to create prefixes implicitly.
+	ErrPrefixNotFound = errors.New("key prefix does not exist")
+
+	// ErrInvalidRange reports a byte range that does not fit the object, such
+	// as a PutObjectRange offset past its end.
+	ErrInvalidRange = errors.New("invalid range")
+
 	// ErrIntegrity reports that an object's stored content does not match its
 	// recorded checksum (bit-rot / corruption detected on read).
 	ErrIntegrity = errors.New("object integrity check failed")
 )
+
+// Aliases named after the S3 error codes, for callers who think in those
+// terms. They are the same values, so errors.Is matches either name.
+var (
+	ErrNoSuchBucket = ErrBucketNotFound
+	ErrNoSuchKey    = ErrObjectNotFound
+)
@@ -0,0 +1,67 @@
+// Package fallback holds per-bucket not-found fallback keys for the S3
+// server: an object served in place of a missing one, so a bucket can host a
+// single-page app (every unknown path serves index.html with 200) or answer
+// misses with its own error page (404.html with 404) instead of the XML
+// NoSuchKey error. Fallbacks are set at runtime on a Registry and take effect
+// on the next request.
+package fallback
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Registry maps buckets to their not-found fallback. The zero value is empty
+// and ready to use; it is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	fallback map[string]entry // bucket -> fallback
+}
+
+type entry struct {
+	key    string
+	status int
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry { return &Registry{} }
+
+// SetBucketNotFoundKey makes a GET or HEAD of a missing key in bucket serve
+// the object at key instead, answered with status: http.StatusOK to route
+// every path of a single-page app to its entry point, http.StatusNotFound
+// (also used when status is 0) for an error page. Ranges and conditional
+// requests are honored only with http.StatusOK. While key itself is missing,
+// misses get the usual NoSuchKey.
+func (r *Registry) SetBucketNotFoundKey(bucket, key string, status int) {
+	if status == 0 {
+		status = http.StatusNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fallback == nil {
+		r.fallback = make(map[string]entry)
+	}
+
+	r.fallback[bucket] = entry{key: key, status: status}
+}
+
+// RemoveBucketNotFoundKey removes the fallback of bucket.
+func (r *Registry) RemoveBucketNotFoundKey(bucket string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.fallback, bucket)
+}
+
+// NotFoundKey returns the fallback key of bucket and the status to serve it
+// with. ok is false when bucket has none.
+func (r *Registry) NotFoundKey(bucket string) (key string, status int, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.fallback[bucket]
+
+	return e.key, e.status, ok
+}
@@ -0,0 +1,35 @@
+package fallback_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/fallback"
+)
+
+func TestRegistry(t *testing.T) {
+	var r fallback.Registry
+
+	_, _, ok := r.NotFoundKey("app")
+	require.False(t, ok)
+
+	r.SetBucketNotFoundKey("app", "index.html", http.StatusOK)
+	r.SetBucketNotFoundKey("docs", "404.html", 0)
+
+	key, status, ok := r.NotFoundKey("app")
+	require.True(t, ok)
+	require.Equal(t, "index.html", key)
+	require.Equal(t, http.StatusOK, status)
+
+	key, status, ok = r.NotFoundKey("docs")
+	require.True(t, ok)
+	require.Equal(t, "404.html", key)
+	require.Equal(t, http.StatusNotFound, status, "status 0 defaults to 404")
+
+	r.RemoveBucketNotFoundKey("app")
+
+	_, _, ok = r.NotFoundKey("app")
+	require.False(t, ok)
+}
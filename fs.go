@@ -45,9 +45,12 @@ type Tag struct {
 }
 
 type PutObjectRequest struct {
-	Reader   io.Reader
-	Bucket   string
-	Key      string
+	Reader io.Reader
+	Bucket string
+	Key    string
+	// Size is the body length in bytes, or -1 when the client did not
+	// declare it. A zero Size is an empty object: Reader yields nothing and
+	// the object's ETag is that of empty content.
 	Size     int64
 	Metadata ObjectMetadata
 	Tags     []Tag
@@ -62,11 +65,22 @@ type PutObjectRequest struct {
 	// condition.
 	IfNoneMatch string
 	IfMatch     string
+
+	// LastModified, when non-zero, is recorded as the object's modification
+	// time instead of the write time, so migrated objects keep their
+	// historical timestamps in HEAD, GET and listings.
+	LastModified time.Time
 }
 
 // PutObjectResponse reports the stored object's ETag.
 type PutObjectResponse struct {
 	ETag string
+	// ServerSideEncryption is the x-amz-server-side-encryption value when the
+	// backend encrypted the object at rest ("AES256"), else empty.
+	ServerSideEncryption string
+	// SSECustomerKeyMD5 is the base64 MD5 of the SSE-C key the object was
+	// encrypted with (see WithCustomerKey), else empty.
+	SSECustomerKeyMD5 string
 }
 
 // GetObjectResponse represents the response for GetObject operation.
@@ -76,6 +90,20 @@ type GetObjectResponse struct {
 	LastModified time.Time
 	ETag         string
 	Metadata     ObjectMetadata
+	// ServerSideEncryption and SSECustomerKeyMD5 describe how the object is
+	// encrypted at rest (see PutObjectResponse).
+	ServerSideEncryption string
+	SSECustomerKeyMD5    string
+	// PartSizes are the sizes, in order, of the parts a multipart object was
+	// completed from, so a client can fetch it part by part (GET with
+	// partNumber). Nil for an object written in one piece, or by a backend
+	// that does not record them.
+	PartSizes []int64
+	// MetadataMissing reports that the object's stored metadata record exists
+	// but could not be read (corrupt or unreadable): the body is served with
+	// empty Metadata and an ETag recomputed from the content. The S3 handler
+	// answers with x-amz-missing-meta.
+	MetadataMissing bool
 }
 
 // MultipartUpload represents an in-progress multipart upload.
@@ -126,6 +154,16 @@ type CompleteMultipartUploadRequest struct {
 	Key      string
 	UploadID string
 	Parts    []CompletedPart
+
+	// StoreAs, if set, names the key the assembled object is stored under
+	// instead of Key, the one the upload was started with. The handler sets
+	// it for WithNoOverwriteRename.
+	StoreAs string
+
+	// IfNoneMatch and IfMatch are conditions on the object being replaced,
+	// as in PutObjectRequest.
+	IfNoneMatch string
+	IfMatch     string
 }
 
 // CompleteMultipartUploadResponse represents the response for completing multipart upload.
@@ -134,4 +172,7 @@ type CompleteMultipartUploadResponse struct {
 	Bucket   string
 	Key      string
 	ETag     string
+	// ServerSideEncryption is set when the object is encrypted at rest (see
+	// PutObjectResponse).
+	ServerSideEncryption string
 }
@@ -0,0 +1,125 @@
+// Package fstest starts pre-populated S3 servers for tests of code that talks
+// to go-faster/fs (or any S3 endpoint).
+//
+//	ts, _ := fstest.NewTestServer(t, map[string][]byte{
+//		"photos/cat.jpg": catJPEG,
+//		"empty-bucket/":  nil,
+//	})
+//	client := newS3Client(ts.URL)
+package fstest
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// Option configures NewTestServer.
+type Option func(*options)
+
+type options struct {
+	storage fs.Storage
+	handler []server.HandlerOption
+}
+
+// WithStorage serves store instead of a fresh storagefs backend in a temporary
+// directory, e.g. storagemem.New() for tests that never touch the disk.
+func WithStorage(store fs.Storage) Option {
+	return func(o *options) { o.storage = store }
+}
+
+// WithHandlerOptions configures the S3 handler, e.g. server.WithAuth to test
+// signed requests.
+func WithHandlerOptions(opts ...server.HandlerOption) Option {
+	return func(o *options) { o.handler = append(o.handler, opts...) }
+}
+
+// NewTestServer starts an anonymous S3 server holding objects and stops it when
+// the test ends. Each map key is "bucket/key"; buckets are created as needed,
+// and a key of "bucket/" (or just "bucket") creates an empty bucket. The
+// backend is storagefs under t.TempDir() unless WithStorage is given.
+//
+// The httptest.Server is what clients talk to (its URL is the endpoint, for
+// path-style addressing); the server.Server exposes the composed handler and
+// configuration.
+func NewTestServer(t testing.TB, objects map[string][]byte, opts ...Option) (*httptest.Server, *server.Server) {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.storage == nil {
+		store, err := storagefs.New(t.TempDir())
+		if err != nil {
+			t.Fatalf("fstest: create storage: %v", err)
+		}
+
+		o.storage = store
+	}
+
+	if err := populate(t.Context(), o.storage, objects); err != nil {
+		t.Fatalf("fstest: %v", err)
+	}
+
+	srv, err := server.New(server.Config{
+		Storage:        o.storage,
+		HandlerOptions: o.handler,
+	})
+	if err != nil {
+		t.Fatalf("fstest: create server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	return ts, srv
+}
+
+// populate creates the buckets and objects named by the keys of objects, in
+// sorted order so failures are reproducible.
+func populate(ctx context.Context, store fs.Storage, objects map[string][]byte) error {
+	created := make(map[string]bool)
+
+	for _, name := range slices.Sorted(maps.Keys(objects)) {
+		bucket, key, _ := strings.Cut(name, "/")
+		if bucket == "" {
+			return errors.Errorf("object %q: empty bucket name", name)
+		}
+
+		if !created[bucket] {
+			if err := store.CreateBucket(ctx, bucket); err != nil && !errors.Is(err, fs.ErrBucketAlreadyExists) {
+				return errors.Wrapf(err, "create bucket %q", bucket)
+			}
+
+			created[bucket] = true
+		}
+
+		if key == "" {
+			continue
+		}
+
+		body := objects[name]
+		if _, err := store.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: bucket,
+			Key:    key,
+			Reader: bytes.NewReader(body),
+			Size:   int64(len(body)),
+		}); err != nil {
+			return errors.Wrapf(err, "put %q", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package fstest_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/fstest"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// TestNewTestServer is the intended use: one call for a populated server,
+// then an ordinary S3 client against its URL.
+func TestNewTestServer(t *testing.T) {
+	ts, _ := fstest.NewTestServer(t, map[string][]byte{
+		"photos/2024/cat.jpg": []byte("meow"),
+		"photos/dog.jpg":      []byte("woof"),
+		"empty/":              nil,
+	})
+
+	client, err := minio.New(ts.Listener.Addr().String(), &minio.Options{
+		Creds: credentials.NewStaticV4("test", "test", ""),
+	})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	obj, err := client.GetObject(ctx, "photos", "2024/cat.jpg", minio.GetObjectOptions{})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, "meow", string(data))
+
+	var keys []string
+	for info := range client.ListObjects(ctx, "photos", minio.ListObjectsOptions{Recursive: true}) {
+		require.NoError(t, info.Err)
+		keys = append(keys, info.Key)
+	}
+
+	require.Equal(t, []string{"2024/cat.jpg", "dog.jpg"}, keys)
+
+	ok, err := client.BucketExists(ctx, "empty")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestNewTestServer_WithStorage(t *testing.T) {
+	store := storagemem.New()
+
+	ts, srv := fstest.NewTestServer(t, map[string][]byte{"bucket-a/key": []byte("x")}, fstest.WithStorage(store))
+	require.NotNil(t, srv.Handler())
+
+	resp, err := ts.Client().Get(ts.URL + "/bucket-a/key")
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The objects went into the given backend.
+	got, err := store.GetObject(t.Context(), "bucket-a", "key")
+	require.NoError(t, err)
+	require.NoError(t, got.Reader.Close())
+}
@@ -0,0 +1,314 @@
+// Package ingest stores objects fetched from HTTP(S) URLs, so a client can
+// have the server pull a file instead of uploading the bytes itself.
+//
+// Fetching arbitrary URLs on a client's behalf invites server-side request
+// forgery, so an Ingester only connects where it is told it may: hosts on the
+// deny list never, hosts off a configured allow list never, and by default no
+// loopback, private, link-local or otherwise non-public address at all —
+// checked on the address actually dialed, after DNS resolution and on every
+// redirect. A host named exactly on the allow list may resolve to such an
+// address, for fetching from an internal service on purpose.
+package ingest
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// DefaultMaxSize bounds an ingested object unless WithMaxSize says
+// otherwise: 5 GiB, the S3 limit for a single PUT.
+const DefaultMaxSize = 5 << 30
+
+// maxRedirects bounds the redirects followed for one fetch.
+const maxRedirects = 5
+
+var (
+	// ErrHostNotAllowed reports a URL (or a redirect) to a host the Ingester
+	// may not fetch from. It wraps fs.ErrAccessDenied.
+	ErrHostNotAllowed = errors.Wrap(fs.ErrAccessDenied, "source host not allowed")
+	// ErrTooLarge reports a source larger than the maximum size.
+	ErrTooLarge = errors.New("source too large")
+	// ErrSource reports a source URL that could not be fetched: malformed,
+	// unreachable, or answered with a non-2xx status.
+	ErrSource = errors.New("source fetch failed")
+)
+
+// Option configures an Ingester.
+type Option func(*Ingester)
+
+// WithAllowedHosts restricts fetching to the hosts matching patterns: an
+// exact host name or IP ("files.example.com", "10.0.0.7") or a wildcard over
+// subdomains ("*.example.com"). Hosts named exactly may resolve to private
+// addresses. Without it every public host is allowed.
+func WithAllowedHosts(patterns ...string) Option {
+	return func(i *Ingester) { i.allow = append(i.allow, normalizeHosts(patterns)...) }
+}
+
+// WithDeniedHosts refuses the hosts matching patterns (same forms as
+// WithAllowedHosts), even when they are also allowed.
+func WithDeniedHosts(patterns ...string) Option {
+	return func(i *Ingester) { i.deny = append(i.deny, normalizeHosts(patterns)...) }
+}
+
+// WithMaxSize bounds the size of an ingested object in bytes (default
+// DefaultMaxSize). A source declaring a larger Content-Length is refused
+// before its body is read; one without stops once it passes the limit.
+func WithMaxSize(n int64) Option {
+	return func(i *Ingester) { i.maxSize = n }
+}
+
+// WithTimeout bounds a whole fetch, body included (default none beyond the
+// caller's context).
+func WithTimeout(d time.Duration) Option {
+	return func(i *Ingester) { i.client.Timeout = d }
+}
+
+// Ingester fetches URLs into a storage.
+type Ingester struct {
+	storage fs.Storage
+	allow   []string
+	deny    []string
+	maxSize int64
+	client  *http.Client
+}
+
+// New returns an Ingester storing into s.
+func New(s fs.Storage, opts ...Option) *Ingester {
+	i := &Ingester{storage: s, maxSize: DefaultMaxSize}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	i.client = &http.Client{
+		Transport: &http.Transport{
+			// No proxy: the address checks must apply to the source itself.
+			Proxy:                 nil,
+			DialContext:           i.dialContext(dialer),
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConns:          16,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.Wrapf(ErrSource, "stopped after %d redirects", maxRedirects)
+			}
+
+			return i.checkURL(req.URL)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// IngestFromURL fetches rawURL and stores its body as key in bucket, with the
+// source's Content-Type, Content-Encoding, Content-Disposition and
+// Cache-Control. The ETag is computed by the storage as for any PUT.
+func (i *Ingester) IngestFromURL(ctx context.Context, bucket, key, rawURL string) (*fs.PutObjectResponse, error) {
+	return i.Ingest(ctx, &fs.PutObjectRequest{Bucket: bucket, Key: key}, rawURL)
+}
+
+// Ingest is IngestFromURL for a prepared request: its Bucket, Key and
+// If-Match/If-None-Match conditions are kept, while Reader, Size and Metadata
+// are taken from the fetched source.
+func (i *Ingester) Ingest(ctx context.Context, put *fs.PutObjectRequest, rawURL string) (*fs.PutObjectResponse, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSource, "parse %q: %v", rawURL, err)
+	}
+
+	if err := i.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(ErrSource, "request %q: %v", rawURL, err)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		// Refusals from the dialer or redirect check keep their identity.
+		if errors.Is(err, ErrHostNotAllowed) || errors.Is(err, ErrSource) {
+			return nil, err
+		}
+
+		return nil, errors.Wrapf(ErrSource, "fetch %q: %v", rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Wrapf(ErrSource, "fetch %q: status %s", rawURL, resp.Status)
+	}
+
+	if i.maxSize > 0 && resp.ContentLength > i.maxSize {
+		return nil, errors.Wrapf(ErrTooLarge, "%d bytes exceed the limit of %d", resp.ContentLength, i.maxSize)
+	}
+
+	body := &limitedReader{r: resp.Body, max: i.maxSize}
+
+	obj := *put
+	obj.Reader, obj.Size = body, resp.ContentLength
+	obj.Metadata = fs.ObjectMetadata{
+		ContentType:        resp.Header.Get("Content-Type"),
+		ContentEncoding:    resp.Header.Get("Content-Encoding"),
+		ContentDisposition: resp.Header.Get("Content-Disposition"),
+		CacheControl:       resp.Header.Get("Cache-Control"),
+	}
+
+	stored, err := i.storage.PutObject(ctx, &obj)
+	if body.err != nil {
+		return nil, body.err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// checkURL refuses URLs that are not plain HTTP(S) or whose host the lists
+// rule out.
+func (i *Ingester) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Wrapf(ErrSource, "unsupported scheme %q", u.Scheme)
+	}
+
+	if u.User != nil {
+		return errors.Wrap(ErrSource, "credentials in the source URL are not supported")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return errors.Wrapf(ErrSource, "no host in %q", u.Redacted())
+	}
+
+	if !i.hostAllowed(host) {
+		return errors.Wrapf(ErrHostNotAllowed, "%q", host)
+	}
+
+	return nil
+}
+
+func (i *Ingester) hostAllowed(host string) bool {
+	if matchHost(i.deny, host) {
+		return false
+	}
+
+	return len(i.allow) == 0 || matchHost(i.allow, host)
+}
+
+// dialContext resolves the host itself and dials only addresses that pass
+// the checks, so a name cannot be re-resolved to a different address between
+// the check and the connection.
+func (i *Ingester) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		host = strings.ToLower(host)
+		if !i.hostAllowed(host) {
+			return nil, errors.Wrapf(ErrHostNotAllowed, "%q", host)
+		}
+
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+
+		trusted := i.trusted(host)
+
+		var lastErr error = errors.Wrapf(ErrHostNotAllowed, "%q resolves to no public address", host)
+
+		for _, ip := range ips {
+			ip = ip.Unmap()
+			if !trusted && (!ip.IsGlobalUnicast() || ip.IsPrivate()) {
+				continue
+			}
+
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+
+			lastErr = err
+		}
+
+		return nil, lastErr
+	}
+}
+
+// trusted reports whether host is named exactly on the allow list, and so
+// may resolve to a non-public address.
+func (i *Ingester) trusted(host string) bool {
+	for _, p := range i.allow {
+		if p == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchHost reports whether host matches one of patterns.
+func matchHost(patterns []string, host string) bool {
+	for _, p := range patterns {
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if p == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeHosts(patterns []string) []string {
+	out := make([]string, len(patterns))
+	for i, p := range patterns {
+		out[i] = strings.ToLower(strings.Trim(p, "[]"))
+	}
+
+	return out
+}
+
+// limitedReader fails the upload once the source passes the size limit, so
+// a source without Content-Length cannot fill the disk.
+type limitedReader struct {
+	r   io.Reader
+	n   int64
+	max int64 // <= 0: unlimited
+	err error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+
+	l.n += int64(n)
+	if l.max > 0 && l.n > l.max {
+		l.err = errors.Wrapf(ErrTooLarge, "source exceeds the limit of %d bytes", l.max)
+		return n, l.err
+	}
+
+	return n, err
+}
@@ -0,0 +1,126 @@
+package ingest_test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/ingest"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func newSource(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/report.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = io.WriteString(w, "a,b\n1,2\n")
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the end drops Content-Length (chunked encoding).
+		_, _ = io.WriteString(w, strings.Repeat("x", 64))
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, strings.Repeat("x", 64))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/elsewhere", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://blocked.example.com/report.csv", http.StatusFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	return srv, u.Hostname()
+}
+
+func newStore(t *testing.T) fs.Storage {
+	t.Helper()
+
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(t.Context(), "bucket"))
+
+	return store
+}
+
+func TestIngestFromURL(t *testing.T) {
+	ctx := t.Context()
+	srv, host := newSource(t)
+	store := newStore(t)
+	in := ingest.New(store, ingest.WithAllowedHosts(host), ingest.WithMaxSize(100))
+
+	t.Run("Stores", func(t *testing.T) {
+		resp, err := in.IngestFromURL(ctx, "bucket", "reports/latest.csv", srv.URL+"/report.csv")
+		require.NoError(t, err)
+
+		sum := md5.Sum([]byte("a,b\n1,2\n"))
+		require.Equal(t, hex.EncodeToString(sum[:]), strings.Trim(resp.ETag, `"`))
+
+		obj, err := store.GetObject(ctx, "bucket", "reports/latest.csv")
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(obj.Reader)
+		require.NoError(t, err)
+		require.NoError(t, obj.Reader.Close())
+		require.Equal(t, "a,b\n1,2\n", string(body))
+		require.Equal(t, "text/csv", obj.Metadata.ContentType)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		_, err := in.IngestFromURL(ctx, "bucket", "big", srv.URL+"/stream")
+		require.ErrorIs(t, err, ingest.ErrTooLarge)
+
+		_, err = store.GetObject(ctx, "bucket", "big")
+		require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+		small := ingest.New(store, ingest.WithAllowedHosts(host), ingest.WithMaxSize(4))
+		_, err = small.IngestFromURL(ctx, "bucket", "big", srv.URL+"/report.csv")
+		require.ErrorIs(t, err, ingest.ErrTooLarge)
+	})
+
+	t.Run("SourceError", func(t *testing.T) {
+		_, err := in.IngestFromURL(ctx, "bucket", "k", srv.URL+"/missing")
+		require.ErrorIs(t, err, ingest.ErrSource)
+
+		_, err = in.IngestFromURL(ctx, "bucket", "k", "file:///etc/passwd")
+		require.ErrorIs(t, err, ingest.ErrSource)
+	})
+}
+
+func TestIngestFromURL_HostNotAllowed(t *testing.T) {
+	ctx := t.Context()
+	srv, host := newSource(t)
+	store := newStore(t)
+
+	for _, tt := range []struct {
+		name string
+		in   *ingest.Ingester
+		path string
+	}{
+		// Without an allow list loopback and private addresses are refused.
+		{name: "PrivateAddress", in: ingest.New(store), path: "/report.csv"},
+		{name: "NotOnAllowList", in: ingest.New(store, ingest.WithAllowedHosts("files.example.com")), path: "/report.csv"},
+		{name: "DenyWins", in: ingest.New(store, ingest.WithAllowedHosts(host), ingest.WithDeniedHosts(host)), path: "/report.csv"},
+		{name: "Redirect", in: ingest.New(store, ingest.WithAllowedHosts(host)), path: "/elsewhere"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.in.IngestFromURL(ctx, "bucket", "k", srv.URL+tt.path)
+			require.ErrorIs(t, err, ingest.ErrHostNotAllowed)
+			require.ErrorIs(t, err, fs.ErrAccessDenied)
+		})
+	}
+
+	_, err := store.GetObject(ctx, "bucket", "k")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
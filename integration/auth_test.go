@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -462,3 +464,125 @@ func TestAuth_ObjectLevelPublicRead(t *testing.T) {
 	_ = priv.Body.Close()
 	require.Equal(t, http.StatusForbidden, priv.StatusCode)
 }
+
+// postFormUpload posts a browser form upload of content with fields to u.
+func postFormUpload(t *testing.T, u string, fields map[string]string, content string) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		require.NoError(t, mw.WriteField(k, v))
+	}
+
+	fw, err := mw.CreateFormFile("file", "upload.txt")
+	require.NoError(t, err)
+
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, u, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func TestAuth_PostPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	endpoint := newAuthServer(t, adminConfig())
+
+	client := minioClient(t, endpoint, authAccessKey, authSecretKey)
+	require.NoError(t, client.MakeBucket(ctx, "forms", minio.MakeBucketOptions{}))
+
+	policy := minio.NewPostPolicy()
+	require.NoError(t, policy.SetBucket("forms"))
+	require.NoError(t, policy.SetKeyStartsWith("uploads/"))
+	require.NoError(t, policy.SetExpires(time.Now().Add(time.Hour)))
+	require.NoError(t, policy.SetContentLengthRange(1, 16))
+
+	u, formData, err := client.PresignedPostPolicy(ctx, policy)
+	require.NoError(t, err)
+
+	form := func(key string) map[string]string {
+		fields := make(map[string]string, len(formData)+1)
+		for k, v := range formData {
+			fields[k] = v
+		}
+
+		fields["key"] = key
+
+		return fields
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		resp := postFormUpload(t, u.String(), form("uploads/a.txt"), "hello")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		obj, err := client.GetObject(ctx, "forms", "uploads/a.txt", minio.GetObjectOptions{})
+		require.NoError(t, err)
+
+		defer func() { _ = obj.Close() }()
+
+		got, err := io.ReadAll(obj)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(got))
+	})
+
+	t.Run("KeyOutsidePolicy", func(t *testing.T) {
+		resp := postFormUpload(t, u.String(), form("elsewhere/a.txt"), "hello")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		resp := postFormUpload(t, u.String(), form("uploads/big.txt"), "more than sixteen bytes")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, err := client.StatObject(ctx, "forms", "uploads/big.txt", minio.StatObjectOptions{})
+		require.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+	})
+
+	t.Run("BadSignature", func(t *testing.T) {
+		fields := form("uploads/b.txt")
+		fields["x-amz-signature"] = strings.Repeat("0", 64)
+
+		resp := postFormUpload(t, u.String(), fields, "hello")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		resp := postFormUpload(t, u.String(), map[string]string{"key": "uploads/c.txt"}, "hello")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	// Only a form upload may skip the signature: a multi-object delete sent
+	// as form data is still an unsigned write.
+	t.Run("UnsignedDelete", func(t *testing.T) {
+		_, err := client.PutObject(ctx, "forms", "keep.txt", strings.NewReader("x"), 1, minio.PutObjectOptions{})
+		require.NoError(t, err)
+
+		body := `<Delete><Object><Key>keep.txt</Key></Object></Delete>`
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+endpoint+"/forms?delete", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Body.Close() }()
+
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, err = client.StatObject(ctx, "forms", "keep.txt", minio.StatObjectOptions{})
+		require.NoError(t, err)
+	})
+}
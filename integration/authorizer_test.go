@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// TestAuthorizer_ReadOnlyPrincipal wires an authorizer that lets one access
+// key read but never write, although its grant would allow writes.
+func TestAuthorizer_ReadOnlyPrincipal(t *testing.T) {
+	t.Parallel()
+
+	const (
+		readerKey    = "AKIAREADONLYEXAMPLE1"
+		readerSecret = "readonly/secret/EXAMPLEKEYEXAMPLEKEY1"
+		bucket       = "shared"
+	)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: readerKey,
+		SecretKey: readerSecret,
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	var (
+		mu   sync.Mutex
+		seen []auth.Operation
+	)
+
+	readOnly := map[auth.Operation]bool{
+		auth.OperationListBuckets:       true,
+		auth.OperationHeadBucket:        true,
+		auth.OperationGetBucketLocation: true,
+		auth.OperationListObjects:       true,
+		auth.OperationListObjectsV2:     true,
+		auth.OperationGetObject:         true,
+		auth.OperationHeadObject:        true,
+	}
+
+	authorizer := func(_ context.Context, principal string, op auth.Operation, gotBucket, _ string) error {
+		if principal != readerKey {
+			return nil
+		}
+
+		mu.Lock()
+		seen = append(seen, op)
+		mu.Unlock()
+
+		if gotBucket != "" && gotBucket != bucket {
+			return errors.Errorf("%s: unexpected bucket %q", op, gotBucket)
+		}
+
+		if !readOnly[op] {
+			return errors.Errorf("%s is read-only", principal)
+		}
+
+		return nil
+	}
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store), server.WithAuthorizer(authorizer)))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	admin := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	reader := minioClient(t, u.Host, readerKey, readerSecret)
+
+	// Other principals are untouched.
+	require.NoError(t, admin.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}))
+	_, err = admin.PutObject(ctx, bucket, "doc.txt", bytes.NewReader([]byte("hello")), 5, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	// Reads pass.
+	obj, err := reader.GetObject(ctx, bucket, "doc.txt", minio.GetObjectOptions{})
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.NoError(t, obj.Close())
+	require.Equal(t, "hello", string(got))
+
+	_, err = reader.StatObject(ctx, bucket, "doc.txt", minio.StatObjectOptions{})
+	require.NoError(t, err)
+
+	for o := range reader.ListObjects(ctx, bucket, minio.ListObjectsOptions{}) {
+		require.NoError(t, o.Err)
+	}
+
+	// Writes are denied, and nothing changes.
+	_, err = reader.PutObject(ctx, bucket, "new.txt", bytes.NewReader([]byte("x")), 1, minio.PutObjectOptions{})
+	require.Equal(t, "AccessDenied", minio.ToErrorResponse(err).Code)
+
+	err = reader.RemoveObject(ctx, bucket, "doc.txt", minio.RemoveObjectOptions{})
+	require.Equal(t, "AccessDenied", minio.ToErrorResponse(err).Code)
+
+	_, err = reader.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: "copy.txt"},
+		minio.CopySrcOptions{Bucket: bucket, Object: "doc.txt"})
+	require.Equal(t, "AccessDenied", minio.ToErrorResponse(err).Code)
+
+	_, err = admin.StatObject(ctx, bucket, "doc.txt", minio.StatObjectOptions{})
+	require.NoError(t, err)
+
+	_, err = admin.StatObject(ctx, bucket, "new.txt", minio.StatObjectOptions{})
+	require.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Contains(t, seen, auth.OperationGetObject)
+	require.Contains(t, seen, auth.OperationHeadObject)
+	require.Contains(t, seen, auth.OperationListObjectsV2)
+	require.Contains(t, seen, auth.OperationPutObject)
+	require.Contains(t, seen, auth.OperationDeleteObject)
+	require.Contains(t, seen, auth.OperationCopyObject)
+}
+
+// TestAuthorizer_CopySource wires an authorizer that hides one object from a
+// principal allowed to write: copying it elsewhere must be denied as a read.
+func TestAuthorizer_CopySource(t *testing.T) {
+	t.Parallel()
+
+	const (
+		writerKey    = "AKIACOPIEREXAMPLE001"
+		writerSecret = "copier/secret/EXAMPLEKEYEXAMPLEKEY01"
+		bucket       = "shared"
+	)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: writerKey,
+		SecretKey: writerSecret,
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	authorizer := func(_ context.Context, principal string, op auth.Operation, _, key string) error {
+		if principal == writerKey && op == auth.OperationGetObject && key == "secret.txt" {
+			return errors.New("secret.txt is hidden")
+		}
+
+		return nil
+	}
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store), server.WithAuthorizer(authorizer)))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	admin := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	writer := minioClient(t, u.Host, writerKey, writerSecret)
+
+	require.NoError(t, admin.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}))
+
+	for _, key := range []string{"doc.txt", "secret.txt"} {
+		_, err = admin.PutObject(ctx, bucket, key, bytes.NewReader([]byte("hello")), 5, minio.PutObjectOptions{})
+		require.NoError(t, err)
+	}
+
+	_, err = writer.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: "leak.txt"},
+		minio.CopySrcOptions{Bucket: bucket, Object: "secret.txt"})
+	require.Equal(t, "AccessDenied", minio.ToErrorResponse(err).Code)
+
+	_, err = admin.StatObject(ctx, bucket, "leak.txt", minio.StatObjectOptions{})
+	require.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+
+	_, err = writer.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: "copy.txt"},
+		minio.CopySrcOptions{Bucket: bucket, Object: "doc.txt"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/notify"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// TestDeleteBucketForce_AdminEndpoint deletes a populated bucket through the
+// signed ?force endpoint, after checking that the plain delete still refuses
+// it and that only admins may force it, and checks that each removed object
+// is reported to the event sink.
+func TestDeleteBucketForce_AdminEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	root := t.TempDir()
+	storage, err := storagefs.New(root)
+	require.NoError(t, err)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: "WRITERKEY",
+		SecretKey: "writer-secret",
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		removed []string
+	)
+
+	sink := func(e notify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, rec := range e.Records {
+			if rec.EventName == notify.ObjectRemovedDelete {
+				removed = append(removed, rec.S3.Object.Key)
+			}
+		}
+	}
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store), server.WithEventSink(sink)))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	client := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	require.NoError(t, client.MakeBucket(ctx, "doomed", minio.MakeBucketOptions{}))
+
+	for _, key := range []string{"a.txt", "nested/b.txt", "nested/deeper/c.txt"} {
+		_, err := client.PutObject(ctx, "doomed", key, bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+		require.NoError(t, err)
+	}
+
+	status, _ := signedDelete(ctx, t, srv.URL+"/doomed", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusConflict, status, "plain delete enforces emptiness")
+
+	status, _ = signedDelete(ctx, t, srv.URL+"/doomed?force", "WRITERKEY", "writer-secret")
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, body := signedDelete(ctx, t, srv.URL+"/doomed?force", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+
+	var result struct {
+		Objects int   `xml:"Objects"`
+		Bytes   int64 `xml:"Bytes"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &result))
+	require.Equal(t, 3, result.Objects)
+	require.EqualValues(t, 12, result.Bytes)
+
+	mu.Lock()
+	require.ElementsMatch(t, []string{"a.txt", "nested/b.txt", "nested/deeper/c.txt"}, removed)
+	mu.Unlock()
+
+	exists, err := client.BucketExists(ctx, "doomed")
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.NoDirExists(t, filepath.Join(root, "doomed"))
+
+	status, _ = signedDelete(ctx, t, srv.URL+"/doomed?force", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusNotFound, status)
+}
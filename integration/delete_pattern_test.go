@@ -0,0 +1,131 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/notify"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// signedDelete sends a DELETE to target signed with the given key and
+// returns the status and body.
+func signedDelete(ctx context.Context, t *testing.T, target, access, secret string) (int, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target, http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	req = signer.SignV4(*req, access, secret, "", "us-east-1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, body
+}
+
+// TestDeleteByPattern_AdminEndpoint deletes a glob's matches through the
+// signed ?pattern endpoint, after checking that only admins may call it, and
+// checks that each deletion is reported to the event sink.
+func TestDeleteByPattern_AdminEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: "WRITERKEY",
+		SecretKey: "writer-secret",
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		removed []string
+	)
+
+	sink := func(e notify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, rec := range e.Records {
+			if rec.EventName == notify.ObjectRemovedDelete {
+				removed = append(removed, rec.S3.Object.Key)
+			}
+		}
+	}
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store), server.WithEventSink(sink)))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	deleteByPattern := func(pattern, access, secret string) (int, int) {
+		target := srv.URL + "/logs?" + url.Values{"pattern": {pattern}}.Encode()
+		status, body := signedDelete(ctx, t, target, access, secret)
+
+		var result struct {
+			Deleted int `xml:"Deleted"`
+		}
+		if status == http.StatusOK {
+			require.NoError(t, xml.Unmarshal(body, &result))
+		}
+
+		return status, result.Deleted
+	}
+
+	client := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	require.NoError(t, client.MakeBucket(ctx, "logs", minio.MakeBucketOptions{}))
+
+	for _, key := range []string{"2023/a.log", "2023/b.log", "2023/old/c.log", "2024/a.log"} {
+		_, err := client.PutObject(ctx, "logs", key, bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+		require.NoError(t, err)
+	}
+
+	status, _ := deleteByPattern("2023/*", "WRITERKEY", "writer-secret")
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, deleted := deleteByPattern("2023/*", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 2, deleted)
+
+	var keys []string
+	for obj := range client.ListObjects(ctx, "logs", minio.ListObjectsOptions{Recursive: true}) {
+		require.NoError(t, obj.Err)
+		keys = append(keys, obj.Key)
+	}
+
+	require.Equal(t, []string{"2023/old/c.log", "2024/a.log"}, keys)
+
+	mu.Lock()
+	require.ElementsMatch(t, []string{"2023/a.log", "2023/b.log"}, removed)
+	mu.Unlock()
+
+	status, _ = deleteByPattern("2023/[", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+)
+
+// TestDiffObjects_ReadOnlyKey checks that a key with only read access may
+// diff a bucket: the POST reads, it does not write.
+func TestDiffObjects_ReadOnlyKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	const roKey, roSecret = "AKIAREADONLY00000000", "read-only-secret-value-000000000000000000"
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: roKey, SecretKey: roSecret,
+		Grants: []auth.Grant{{Pattern: "shared", Permission: auth.Read}},
+	})
+	endpoint := newAuthServer(t, cfg)
+
+	admin := minioClient(t, endpoint, authAccessKey, authSecretKey)
+	require.NoError(t, admin.MakeBucket(ctx, "shared", minio.MakeBucketOptions{}))
+
+	_, err := admin.PutObject(ctx, "shared", "a.txt", bytes.NewReader([]byte("a")), 1, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	body := []byte(`{"objects":[{"key":"b.txt","etag":"92eb5ffee6ae2fec3ad71c777531578f"}]}`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+endpoint+"/shared?diff", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	req = signer.SignV4(*req, roKey, roSecret, "", "us-east-1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(got))
+
+	var res struct {
+		Added   []string `json:"added"`
+		Deleted []string `json:"deleted"`
+	}
+	require.NoError(t, json.Unmarshal(got, &res))
+	require.Equal(t, []string{"a.txt"}, res.Added)
+	require.Equal(t, []string{"b.txt"}, res.Deleted)
+}
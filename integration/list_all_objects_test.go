@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// allObjectsResult is the ?objects endpoint's XML answer.
+type allObjectsResult struct {
+	Prefix  string             `xml:"Prefix"`
+	Buckets []allObjectsBucket `xml:"Bucket"`
+}
+
+type allObjectsBucket struct {
+	Name string   `xml:"Name"`
+	Keys []string `xml:"Contents>Key"`
+}
+
+// TestListAllObjects_AdminEndpoint searches objects spread over several
+// buckets through the signed ?objects endpoint, which only admins may call.
+func TestListAllObjects_AdminEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: "READERKEY",
+		SecretKey: "reader-secret",
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Read}},
+	})
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store)))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	list := func(query, access, secret string) (int, allObjectsResult) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/?objects"+query, http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		req = signer.SignV4(*req, access, secret, "", "us-east-1")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var result allObjectsResult
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, xml.Unmarshal(body, &result))
+		}
+
+		return resp.StatusCode, result
+	}
+
+	client := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	for bucket, keys := range map[string][]string{
+		"alpha": {"logs/b.txt", "logs/a.txt", "data/c.txt"},
+		"beta":  {"logs/d.txt"},
+		"gamma": {"data/e.txt"},
+		"delta": nil,
+	} {
+		require.NoError(t, client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}))
+
+		for _, key := range keys {
+			_, err := client.PutObject(ctx, bucket, key, bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+			require.NoError(t, err)
+		}
+	}
+
+	status, _ := list("&prefix=logs/", "READERKEY", "reader-secret")
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, result := list("&prefix=logs/", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "logs/", result.Prefix)
+	require.Equal(t, []allObjectsBucket{
+		{Name: "alpha", Keys: []string{"logs/a.txt", "logs/b.txt"}},
+		{Name: "beta", Keys: []string{"logs/d.txt"}},
+	}, result.Buckets)
+
+	status, result = list("", authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, []allObjectsBucket{
+		{Name: "alpha", Keys: []string{"data/c.txt", "logs/a.txt", "logs/b.txt"}},
+		{Name: "beta", Keys: []string{"logs/d.txt"}},
+		{Name: "gamma", Keys: []string{"data/e.txt"}},
+	}, result.Buckets)
+}
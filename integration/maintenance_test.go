@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	miniocreds "github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// TestMaintenance_AdminEndpoint toggles maintenance mode through the signed
+// ?maintenance endpoint and checks that writes are refused only while it is on.
+func TestMaintenance_AdminEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: "WRITERKEY",
+		SecretKey: "writer-secret",
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	maintenance := new(server.Maintenance)
+	srv := httptest.NewServer(server.NewHandler(storage,
+		server.WithAuth(store),
+		server.WithMaintenance(maintenance),
+	))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	admin := func(method, access, secret string) int {
+		req, err := http.NewRequestWithContext(ctx, method, srv.URL+"/?maintenance", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		req = signer.SignV4(*req, access, secret, "", "us-east-1")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode
+	}
+
+	// One attempt: minio-go retries 503s, which would only slow the test down.
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:      miniocreds.NewStaticV4(authAccessKey, authSecretKey, ""),
+		MaxRetries: 1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.MakeBucket(ctx, "bucket-a", minio.MakeBucketOptions{}))
+
+	put := func(key string) error {
+		_, err := client.PutObject(ctx, "bucket-a", key, bytes.NewReader([]byte("x")), 1, minio.PutObjectOptions{})
+		return err
+	}
+
+	require.Equal(t, http.StatusForbidden, admin(http.MethodPut, "WRITERKEY", "writer-secret"))
+	require.False(t, maintenance.Enabled())
+
+	require.Equal(t, http.StatusOK, admin(http.MethodPut, authAccessKey, authSecretKey))
+	require.True(t, maintenance.Enabled())
+
+	err = put("refused")
+	require.Error(t, err)
+	require.Equal(t, "ServiceUnavailable", minio.ToErrorResponse(err).Code)
+
+	_, err = client.StatObject(ctx, "bucket-a", "refused", minio.StatObjectOptions{})
+	require.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+
+	require.Equal(t, http.StatusOK, admin(http.MethodDelete, authAccessKey, authSecretKey))
+	require.False(t, maintenance.Enabled())
+
+	require.NoError(t, put("accepted"))
+}
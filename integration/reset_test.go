@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// resetResult is the ?all endpoint's XML summary.
+type resetResult struct {
+	DryRun  bool          `xml:"DryRun"`
+	Buckets []resetBucket `xml:"Bucket"`
+}
+
+type resetBucket struct {
+	Name    string `xml:"Name"`
+	Objects int    `xml:"Objects"`
+	Bytes   int64  `xml:"Bytes"`
+	Uploads int    `xml:"Uploads"`
+}
+
+// TestReset_AdminEndpoint wipes a populated store through the signed ?all
+// endpoint, after checking the dry run and that only admins may call it.
+func TestReset_AdminEndpoint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := adminConfig()
+	cfg.Keys = append(cfg.Keys, auth.Key{
+		AccessKey: "WRITERKEY",
+		SecretKey: "writer-secret",
+		Grants:    []auth.Grant{{Pattern: "*", Permission: auth.Write}},
+	})
+	store, err := auth.NewStore(cfg)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(server.NewHandler(storage, server.WithAuth(store), server.WithReset()))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	reset := func(method, access, secret string) (int, resetResult) {
+		req, err := http.NewRequestWithContext(ctx, method, srv.URL+"/?all", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		req = signer.SignV4(*req, access, secret, "", "us-east-1")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var result resetResult
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, xml.Unmarshal(body, &result))
+		}
+
+		return resp.StatusCode, result
+	}
+
+	client := minioClient(t, u.Host, authAccessKey, authSecretKey)
+	for bucket, keys := range map[string][]string{
+		"alpha": {"a.txt", "nested/b.txt"},
+		"beta":  {"c.txt"},
+		"gamma": nil,
+	} {
+		require.NoError(t, client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}))
+
+		for _, key := range keys {
+			_, err := client.PutObject(ctx, bucket, key, bytes.NewReader([]byte("data")), 4, minio.PutObjectOptions{})
+			require.NoError(t, err)
+		}
+	}
+
+	status, _ := reset(http.MethodDelete, "WRITERKEY", "writer-secret")
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, dry := reset(http.MethodGet, authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, dry.DryRun)
+	require.Equal(t, []resetBucket{
+		{Name: "alpha", Objects: 2, Bytes: 8},
+		{Name: "beta", Objects: 1, Bytes: 4},
+		{Name: "gamma"},
+	}, dry.Buckets)
+
+	buckets, err := client.ListBuckets(ctx)
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+
+	status, done := reset(http.MethodDelete, authAccessKey, authSecretKey)
+	require.Equal(t, http.StatusOK, status)
+	require.False(t, done.DryRun)
+	require.Equal(t, dry.Buckets, done.Buckets)
+
+	buckets, err = client.ListBuckets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+}
@@ -25,12 +25,74 @@ type Authenticator interface {
 	PublicRead(bucket string) bool
 }
 
+// Authorizer decides whether principal may perform op on bucket and key (each
+// empty where the operation has none), after authentication and the
+// authenticator's own grants have allowed it. principal is the access key of
+// a signed request and empty for an anonymous one. A non-nil error denies
+// the request with AccessDenied.
+//
+// Operations acting on keys named in the request body (DeleteObjects) are
+// authorized on the bucket alone; a form upload (PostObject) is authorized
+// once its fields have named the key. A copy (CopyObject, UploadPartCopy) is
+// authorized on its destination and, as GetObject, on its source.
+type Authorizer func(ctx context.Context, principal string, op auth.Operation, bucket, key string) error
+
+type principalContextKey struct{}
+
+// withPrincipal records the access key a request was authenticated with.
+func withPrincipal(ctx context.Context, accessKey string) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, accessKey)
+}
+
+// principalFromContext returns the access key recorded by withPrincipal, or
+// "" for an anonymous request.
+func principalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalContextKey{}).(string)
+	return principal
+}
+
+// authorizeMiddleware consults a for every request but form uploads, which
+// PostObject authorizes itself. Copies are also checked as a read of their
+// X-Amz-Copy-Source; a malformed source is left to the handler to reject.
+func authorizeMiddleware(a Authorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := operationName(r)
+		if op == auth.OperationPostObject {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		bucket, key := splitPath(r)
+
+		principal := principalFromContext(ctx)
+
+		if err := a(ctx, principal, op, bucket, key); err != nil {
+			renderAPIError(ctx, w, r, s3err.AccessDenied, err)
+			return
+		}
+
+		if op == auth.OperationCopyObject || op == auth.OperationUploadPartCopy {
+			srcBucket, srcKey, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+			if ok {
+				if err := a(ctx, principal, auth.OperationGetObject, srcBucket, srcKey); err != nil {
+					renderAPIError(ctx, w, r, s3err.AccessDenied, err)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // authMiddleware authenticates and authorizes every request before it reaches
 // the router. Signed requests (SigV4 header or presigned query) are verified
 // and authorized; unsigned requests are allowed only when the target's canned
 // ACL permits anonymous access. For signed streaming uploads the request body
 // is replaced with a chunk-signature-verifying reader so tampered payloads
-// never reach storage.
+// never reach storage. Unsigned form uploads (POST object) carry their
+// credentials in the body and are authenticated by the handler instead.
 func authMiddleware(a Authenticator, store fs.Storage, next http.Handler) http.Handler {
 	verifier := sigv4.NewVerifier(a.Secret)
 
@@ -53,11 +115,16 @@ func authMiddleware(a Authenticator, store fs.Storage, next http.Handler) http.H
 				replaceWithVerifiedBody(r, res)
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), res.AccessKey)))
 
 			return
 		}
 
+		if action == auth.ActionWrite && isFormUpload(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		if anonymousAllowed(r.Context(), store, a, bucket, key, action) {
 			next.ServeHTTP(w, r)
 			return
@@ -71,15 +138,21 @@ func authMiddleware(a Authenticator, store fs.Storage, next http.Handler) http.H
 // from its method and path (path-style addressing). A root request (ListBuckets)
 // has an empty bucket; a bucket-level request has an empty key.
 func requestScope(r *http.Request) (bucket, key string, action auth.Action) {
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ = strings.Cut(path, "/")
+	bucket, key = splitPath(r)
+
+	if isAdminRequest(r) {
+		return "", "", auth.ActionAdmin
+	}
 
-	switch r.Method {
-	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	if isBulkDelete(r) {
+		return bucket, "", auth.ActionAdmin
+	}
+
+	if !isMutating(r) {
 		return bucket, key, auth.ActionRead
-	default:
-		return bucket, key, auth.ActionWrite
 	}
+
+	return bucket, key, auth.ActionWrite
 }
 
 // anonymousAllowed decides whether an unsigned request may proceed, consulting
@@ -88,14 +161,14 @@ func requestScope(r *http.Request) (bucket, key string, action auth.Action) {
 // through so the router produces the natural NoSuchBucket/NoSuchKey (404),
 // matching S3-compatible (RGW) behavior rather than a blanket 403.
 //
-//   - service level (no bucket): denied.
+//   - service level (no bucket) and administration: denied.
 //   - bucket level (no key): reads need a public-read bucket; writes (bucket
 //     create/delete) are never anonymous.
 //   - object level: a missing bucket is let through (→ 404); otherwise writes
 //     need a public-read-write bucket and reads need the bucket or the object
 //     to be public-read.
 func anonymousAllowed(ctx context.Context, store fs.Storage, a Authenticator, bucket, key string, action auth.Action) bool {
-	if bucket == "" {
+	if bucket == "" || action == auth.ActionAdmin {
 		return false
 	}
 
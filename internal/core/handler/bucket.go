@@ -1,9 +1,43 @@
 package handler
 
-import "time"
+import (
+	"encoding/xml"
+	"time"
+)
 
 // BucketInfo is the XML representation of a bucket.
 type BucketInfo struct {
 	Name         string    `xml:"Name"`
-	CreationDate time.Time `xml:"CreationDate"`
+	CreationDate Timestamp `xml:"CreationDate"`
+}
+
+// timestampFormat is the ISO 8601 form S3 writes timestamps in: always UTC,
+// always milliseconds. Strict clients parse exactly this layout, which
+// time.Time's own RFC 3339 encoding (local offsets, trimmed fractions) does
+// not guarantee.
+const timestampFormat = "2006-01-02T15:04:05.000Z"
+
+// Timestamp is a time.Time encoded in XML as S3 does (timestampFormat).
+type Timestamp time.Time
+
+// MarshalXML implements xml.Marshaler.
+func (t Timestamp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(time.Time(t).UTC().Format(timestampFormat), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler, accepting any RFC 3339 time.
+func (t *Timestamp) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+
+	*t = Timestamp(parsed)
+
+	return nil
 }
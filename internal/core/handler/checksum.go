@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA-1 is one of the S3 checksum algorithms.
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// checksumAlgorithms maps the canonical x-amz-checksum-* header names to their
+// hash constructors. The header value is the base64 of the big-endian digest.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"X-Amz-Checksum-Crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	"X-Amz-Checksum-Crc32c": func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"X-Amz-Checksum-Sha1":   sha1.New,
+	"X-Amz-Checksum-Sha256": sha256.New,
+}
+
+// trailerChecksum hashes a request body as it streams to storage and, at EOF,
+// compares the digest with the checksum the client sent as an HTTP trailer.
+// A mismatch fails the final Read, so the backend discards the write instead
+// of committing it.
+type trailerChecksum struct {
+	r    io.Reader
+	req  *http.Request
+	name string
+	hash hash.Hash
+	err  error
+}
+
+// withTrailerChecksum wraps body when r declares an x-amz-checksum-* trailer;
+// otherwise it returns body unchanged and a nil checksum.
+func withTrailerChecksum(r *http.Request, body io.Reader) (io.Reader, *trailerChecksum) {
+	name := declaredChecksumTrailer(r)
+	if name == "" {
+		return body, nil
+	}
+
+	c := &trailerChecksum{
+		r:    body,
+		req:  r,
+		name: name,
+		hash: checksumAlgorithms[name](),
+	}
+
+	return c, c
+}
+
+// declaredChecksumTrailer returns the checksum trailer announced by the
+// request. net/http moves the Trailer header into r.Trailer's keys when it
+// parses a chunked body, so both are consulted.
+func declaredChecksumTrailer(r *http.Request) string {
+	names := make([]string, 0, len(r.Trailer))
+	for name := range r.Trailer {
+		names = append(names, name)
+	}
+
+	for _, v := range r.Header.Values("Trailer") {
+		names = append(names, strings.Split(v, ",")...)
+	}
+
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if _, ok := checksumAlgorithms[name]; ok {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (c *trailerChecksum) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+
+	if err == io.EOF {
+		if verr := c.verify(); verr != nil {
+			return n, verr
+		}
+	}
+
+	return n, err
+}
+
+// verify compares the digest with the trailer value. Trailers arrive only
+// after the last body byte, so the raw body is drained first in case the
+// payload framing (aws-chunked) ended before it. A declared but absent
+// trailer leaves nothing to check against.
+func (c *trailerChecksum) verify() error {
+	_, _ = io.Copy(io.Discard, c.req.Body)
+
+	want := c.req.Trailer.Get(c.name)
+	if want == "" {
+		return nil
+	}
+
+	if got := base64.StdEncoding.EncodeToString(c.hash.Sum(nil)); got != want {
+		c.err = errors.Errorf("%s trailer %q does not match computed %q", strings.ToLower(c.name), want, got)
+		return c.err
+	}
+
+	return nil
+}
+
+// mismatch returns the verification error, if the body failed it.
+func (c *trailerChecksum) mismatch() error {
+	if c == nil {
+		return nil
+	}
+
+	return c.err
+}
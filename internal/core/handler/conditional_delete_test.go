@@ -0,0 +1,54 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObject_IfMatch(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	put := func(key, body string) string {
+		rec := do(t, h, http.MethodPut, "/bucket-a/"+key, body, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return rec.Header().Get("ETag")
+	}
+
+	exists := func(key string) bool {
+		return do(t, h, http.MethodHead, "/bucket-a/"+key, "", nil).Code == http.StatusOK
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		etag := put("match", "v1")
+
+		rec := do(t, h, http.MethodDelete, "/bucket-a/match", "", map[string]string{"If-Match": etag})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.False(t, exists("match"))
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		stale := put("mismatch", "v1")
+		put("mismatch", "v2")
+
+		rec := do(t, h, http.MethodDelete, "/bucket-a/mismatch", "", map[string]string{"If-Match": stale})
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		require.Equal(t, "PreconditionFailed", errorCode(t, rec.Body.String()))
+		require.True(t, exists("mismatch"))
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		put("wildcard", "v1")
+
+		rec := do(t, h, http.MethodDelete, "/bucket-a/wildcard", "", map[string]string{"If-Match": "*"})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.False(t, exists("wildcard"))
+
+		// Only if it exists: gone now, so the condition fails.
+		rec = do(t, h, http.MethodDelete, "/bucket-a/wildcard", "", map[string]string{"If-Match": "*"})
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+}
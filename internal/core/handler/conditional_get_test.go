@@ -1,10 +1,17 @@
 package handler_test
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/mock"
 )
 
 func TestGetObject_Conditional(t *testing.T) {
@@ -28,5 +35,111 @@ func TestGetObject_Conditional(t *testing.T) {
 	t.Run("IfMatch_Mismatch", func(t *testing.T) {
 		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"If-Match": `"deadbeef"`})
 		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		require.Equal(t, "PreconditionFailed", errorCode(t, rec.Body.String()))
 	})
 }
+
+// seekCloser is a seekable object body, served through http.ServeContent.
+type seekCloser struct{ *strings.Reader }
+
+func (seekCloser) Close() error { return nil }
+
+func TestGetObject_ConditionalPrecedence(t *testing.T) {
+	const etag = `"5d41402abc4b2a76b9719d911017c592"`
+
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	at := modified.Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"None", nil, http.StatusOK},
+		{"IfUnmodifiedSince_After", map[string]string{"If-Unmodified-Since": after}, http.StatusOK},
+		{"IfUnmodifiedSince_Exact", map[string]string{"If-Unmodified-Since": at}, http.StatusOK},
+		{"IfUnmodifiedSince_Before", map[string]string{"If-Unmodified-Since": before}, http.StatusPreconditionFailed},
+		{"IfUnmodifiedSince_Invalid", map[string]string{"If-Unmodified-Since": "yesterday"}, http.StatusOK},
+		{"IfModifiedSince_Before", map[string]string{"If-Modified-Since": before}, http.StatusOK},
+		{"IfModifiedSince_Exact", map[string]string{"If-Modified-Since": at}, http.StatusNotModified},
+		{"IfModifiedSince_After", map[string]string{"If-Modified-Since": after}, http.StatusNotModified},
+		{"IfMatch_Star", map[string]string{"If-Match": "*"}, http.StatusOK},
+		{"IfMatch_List", map[string]string{"If-Match": `"other", ` + etag}, http.StatusOK},
+		{"IfNoneMatch_Other", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"IfNoneMatch_Weak", map[string]string{"If-None-Match": "W/" + etag}, http.StatusNotModified},
+
+		// If-Match, when present, replaces If-Unmodified-Since.
+		{"IfMatch_True_IfUnmodifiedSince_False", map[string]string{
+			"If-Match": etag, "If-Unmodified-Since": before,
+		}, http.StatusOK},
+		{"IfMatch_False_IfUnmodifiedSince_True", map[string]string{
+			"If-Match": `"other"`, "If-Unmodified-Since": after,
+		}, http.StatusPreconditionFailed},
+		// If-None-Match, when present, replaces If-Modified-Since.
+		{"IfNoneMatch_False_IfModifiedSince_True", map[string]string{
+			"If-None-Match": `"other"`, "If-Modified-Since": after,
+		}, http.StatusOK},
+		{"IfNoneMatch_True_IfModifiedSince_False", map[string]string{
+			"If-None-Match": etag, "If-Modified-Since": before,
+		}, http.StatusNotModified},
+		// Preconditions that fail (412) win over those that spare the body (304).
+		{"IfUnmodifiedSince_False_IfModifiedSince_False", map[string]string{
+			"If-Unmodified-Since": before, "If-Modified-Since": after,
+		}, http.StatusPreconditionFailed},
+		{"IfMatch_False_IfNoneMatch_True", map[string]string{
+			"If-Match": `"other"`, "If-None-Match": etag,
+		}, http.StatusPreconditionFailed},
+		{"IfUnmodifiedSince_True_IfNoneMatch_True", map[string]string{
+			"If-Unmodified-Since": after, "If-None-Match": etag,
+		}, http.StatusNotModified},
+		{"IfMatch_True_IfModifiedSince_False", map[string]string{
+			"If-Match": etag, "If-Modified-Since": after,
+		}, http.StatusNotModified},
+		{"AllPass", map[string]string{
+			"If-Match": etag, "If-Unmodified-Since": after,
+			"If-None-Match": `"other"`, "If-Modified-Since": before,
+		}, http.StatusOK},
+	} {
+		for _, body := range []struct {
+			name   string
+			reader func() io.ReadCloser
+		}{
+			{"Seekable", func() io.ReadCloser { return seekCloser{strings.NewReader("hello")} }},
+			{"NonSeekable", func() io.ReadCloser { return io.NopCloser(strings.NewReader("hello")) }},
+		} {
+			t.Run(tc.name+"/"+body.name, func(t *testing.T) {
+				h := newTestHandler(&mock.StorageMock{
+					GetObjectFunc: func(context.Context, string, string) (*fs.GetObjectResponse, error) {
+						return &fs.GetObjectResponse{
+							Reader:       body.reader(),
+							Size:         5,
+							LastModified: modified,
+							ETag:         strings.Trim(etag, `"`),
+						}, nil
+					},
+				})
+
+				for _, method := range []string{http.MethodGet, http.MethodHead} {
+					rec := do(t, h, method, "/bucket-a/obj", "", tc.headers)
+					require.Equal(t, tc.want, rec.Code, method)
+
+					switch tc.want {
+					case http.StatusOK:
+						if method == http.MethodGet {
+							require.Equal(t, "hello", rec.Body.String())
+						}
+					case http.StatusNotModified:
+						require.Empty(t, rec.Body.String())
+						require.Equal(t, etag, rec.Header().Get("ETag"))
+					case http.StatusPreconditionFailed:
+						if method == http.MethodGet {
+							require.Equal(t, "PreconditionFailed", errorCode(t, rec.Body.String()))
+						}
+					}
+				}
+			})
+		}
+	}
+}
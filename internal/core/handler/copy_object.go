@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
 )
 
 // CopyObjectResult is the XML response for a CopyObject operation.
@@ -25,11 +26,12 @@ type CopyObjectResult struct {
 // destination. Metadata follows x-amz-metadata-directive (COPY by default,
 // REPLACE takes it from the request headers), tags follow
 // x-amz-tagging-directive the same way. Conditional-copy headers
-// (x-amz-copy-source-if-*) are ignored.
+// (x-amz-copy-source-if-*) are ignored. The copy runs under the request
+// context, so a client that disconnects stops it; backends write through a
+// temp file, leaving the destination untouched.
 func (h *handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	destBucket, destKey, _ := strings.Cut(path, "/")
+	destBucket, destKey := splitPath(r)
 
 	srcBucket, srcKey, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
 	if !ok {
@@ -49,16 +51,30 @@ func (h *handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The source is read with the copy-source SSE-C key, the destination
+	// written with the request's own; both key MD5s are checked here.
+	srcCtx, ok := withCustomerKey(ctx, w, r, sseCustomerCopySourcePrefix)
+	if !ok {
+		return
+	}
+
+	dstCtx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
 	// Copying an object onto itself is only allowed when it changes something
-	// (metadata REPLACE), matching S3.
-	if srcBucket == destBucket && srcKey == destKey && metadataDirective != directiveReplace {
+	// (metadata REPLACE, or encryption: an SSE-C key for the result, which is
+	// how a key is rotated), matching S3.
+	if srcBucket == destBucket && srcKey == destKey &&
+		metadataDirective != directiveReplace && fs.CustomerKeyFromContext(dstCtx) == nil {
 		renderAPIError(ctx, w, r, s3err.InvalidRequest,
-			errors.New("copy to itself without metadata directive REPLACE"))
+			errors.New("copy to itself without changing metadata or encryption"))
 
 		return
 	}
 
-	src, err := h.service.GetObject(ctx, srcBucket, srcKey)
+	src, err := h.service.GetObject(srcCtx, srcBucket, srcKey)
 	if err != nil {
 		renderError(ctx, w, r, err)
 		return
@@ -68,7 +84,10 @@ func (h *handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 
 	metadata := src.Metadata
 	if metadataDirective == directiveReplace {
-		metadata = extractObjectMetadata(r.Header)
+		if metadata, err = extractObjectMetadata(r.Header); err != nil {
+			renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+			return
+		}
 	}
 
 	var tags []fs.Tag
@@ -93,7 +112,19 @@ func (h *handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 		ACL:      fs.ParseACL(r.Header.Get("X-Amz-Acl")),
 	}
 
-	resp, err := h.service.PutObject(ctx, put)
+	// With WithNoOverwriteRename a copy onto an existing object is stored
+	// under a free derived key. A copy onto itself rewrites the object in
+	// place (metadata or key rotation) and is left alone.
+	if srcBucket != destBucket || srcKey != destKey {
+		if put.Key, put.IfNoneMatch, err = h.renameOnConflict(ctx, w, destBucket, destKey, "", ""); err != nil {
+			renderError(ctx, w, r, err)
+			return
+		}
+
+		destKey = put.Key
+	}
+
+	resp, err := h.service.PutObject(dstCtx, put)
 	if err != nil {
 		renderError(ctx, w, r, err)
 		return
@@ -101,15 +132,18 @@ func (h *handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 
 	// Read back the destination for the response timestamp.
 	lastModified := time.Now().UTC()
-	if dst, err := h.service.GetObject(ctx, destBucket, destKey); err == nil {
+	if dst, err := h.service.GetObject(dstCtx, destBucket, destKey); err == nil {
 		lastModified = dst.LastModified
 		_ = dst.Reader.Close()
 	}
 
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
 	writeXML(ctx, w, r, CopyObjectResult{
 		LastModified: lastModified.UTC(),
 		ETag:         quoteETag(resp.ETag),
 	})
+
+	h.emit(w, notify.ObjectCreatedCopy, destBucket, destKey, src.Size, resp.ETag)
 }
 
 // Copy directives for metadata and tagging.
@@ -1,13 +1,22 @@
 package handler_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagefs"
 )
 
 func TestCopyObject(t *testing.T) {
@@ -32,3 +41,78 @@ func TestCopyObject(t *testing.T) {
 		"X-Amz-Copy-Source": "/bucket-a/absent",
 	}).Code)
 }
+
+// cancelingSource stands in for a client that disconnects mid-copy: the
+// reader of the copy source cancels the request once n bytes have been read.
+type cancelingSource struct {
+	fs.Storage
+
+	n      int
+	cancel context.CancelFunc
+}
+
+func (s cancelingSource) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	resp, err := s.Storage.GetObject(ctx, bucket, key)
+	if err == nil && key == "src" {
+		resp.Reader = &cancelAfterRead{ReadCloser: resp.Reader, n: s.n, cancel: s.cancel}
+	}
+
+	return resp, err
+}
+
+type cancelAfterRead struct {
+	io.ReadCloser
+
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfterRead) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+
+	c.n -= n
+	if c.n <= 0 {
+		c.cancel()
+	}
+
+	return n, err
+}
+
+// TestCopyObject_CanceledMidStream cancels a copy request partway through the
+// source: the copy must fail and leave no destination object, not even a
+// staged one, on filesystem storage.
+func TestCopyObject_CanceledMidStream(t *testing.T) {
+	root := t.TempDir()
+
+	storage, err := storagefs.New(root)
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateBucket(t.Context(), "bucket-a"))
+
+	body := bytes.Repeat([]byte("0123456789abcdef"), 1<<18) // 4 MiB
+	_, err = storage.PutObject(t.Context(), &fs.PutObjectRequest{
+		Bucket: "bucket-a", Key: "src", Reader: bytes.NewReader(body), Size: int64(len(body)),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	h := handler.New(service.New(cancelingSource{Storage: storage, n: 64 << 10, cancel: cancel}))
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodPut, "/bucket-a/dst", http.NoBody)
+	req.Header.Set("X-Amz-Copy-Source", "/bucket-a/src")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.ErrorIs(t, ctx.Err(), context.Canceled, "the copy must have reached the cancellation")
+	require.NotEqual(t, http.StatusOK, rec.Code)
+
+	// Nothing of the destination is left behind, staged or in place.
+	_, err = storage.GetObject(t.Context(), "bucket-a", "dst")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+	require.NoFileExists(t, filepath.Join(root, "bucket-a", "dst"))
+
+	staged, err := os.ReadDir(filepath.Join(root, ".tmp"))
+	require.NoError(t, err)
+	require.Empty(t, staged)
+}
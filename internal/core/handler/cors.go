@@ -26,7 +26,7 @@ func corsMiddleware(resolver CORSResolver, next http.Handler) http.Handler {
 			return
 		}
 
-		bucket, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		bucket, _ := splitPath(r)
 		rules := resolver.Rules(bucket)
 
 		if r.Method == http.MethodOptions {
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/go-faster/fs"
+)
+
+// countParam is the extension bucket subresource that answers with the number
+// of objects under prefix instead of listing them.
+const countParam = "count"
+
+// ObjectCount is the XML response of GET /{bucket}?count.
+type ObjectCount struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ObjectCount"`
+	Name    string   `xml:"Name"`
+	Prefix  string   `xml:"Prefix"`
+	Count   int64    `xml:"Count"`
+}
+
+// CountObjects implements GET /{bucket}?count[&prefix=P] (extension): the
+// number of objects under the prefix, with no delimiter folding, sorting or
+// per-object output.
+func (h *handler) CountObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+	prefix := h.listPrefix(r.URL.Query())
+
+	n, err := fs.CountObjects(ctx, h.service, bucket, prefix)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	writeXML(ctx, w, r, ObjectCount{Name: bucket, Prefix: prefix, Count: n})
+}
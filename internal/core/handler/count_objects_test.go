@@ -0,0 +1,55 @@
+package handler_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+)
+
+func TestCountObjects(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	for _, key := range []string{
+		"logs/2026/01/a.log",
+		"logs/2026/01/b.log",
+		"logs/2026/02/c.log",
+		"logs/readme.txt",
+		"images/cat.png",
+		"top.txt",
+	} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/"+key, "x", nil).Code)
+	}
+
+	for _, tt := range []struct {
+		prefix string
+		want   int64
+	}{
+		{"", 6},
+		{"logs/", 4},
+		{"logs/2026/", 3},
+		{"logs/2026/01/", 2},
+		{"images/", 1},
+		{"missing/", 0},
+	} {
+		rec := do(t, h, http.MethodGet, "/bucket-a?count&prefix="+tt.prefix, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var got handler.ObjectCount
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "bucket-a", got.Name)
+		require.Equal(t, tt.prefix, got.Prefix)
+		require.Equal(t, tt.want, got.Count, tt.prefix)
+
+		// The count agrees with a full listing.
+		require.Len(t, listBucket(t, h, "bucket-a", "?prefix="+tt.prefix).Contents, int(tt.want))
+	}
+
+	rec := do(t, h, http.MethodGet, "/bucket-b?count", "", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()))
+}
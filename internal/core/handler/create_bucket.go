@@ -1,20 +1,47 @@
 package handler
 
 import (
+	"cmp"
+	"encoding/xml"
+	"io"
 	"net/http"
-	"strings"
+
+	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
 )
 
-// CreateBucket implements PUT /{bucket}. Any LocationConstraint in the request
-// body is ignored (this server is single-region); on success it echoes the
-// bucket path in the Location header as S3 does. A canned x-amz-acl (e.g.
-// public-read) is recorded on the new bucket.
+// defaultRegion is the region an S3 bucket created without a
+// LocationConstraint lives in.
+const defaultRegion = "us-east-1"
+
+// CreateBucketConfiguration is the optional XML body of CreateBucket.
+type CreateBucketConfiguration struct {
+	XMLName            xml.Name `xml:"CreateBucketConfiguration"`
+	LocationConstraint string   `xml:"LocationConstraint"`
+}
+
+// CreateBucket implements PUT /{bucket}. A LocationConstraint in the request
+// body is recorded as the bucket's region (refused unless it is the server's
+// region with WithStrictRegion); on success it echoes the bucket path in the
+// Location header as S3 does. A canned x-amz-acl (e.g. public-read) is
+// recorded on the new bucket.
 func (h *handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	name, _, _ := strings.Cut(path, "/")
+	name, _ := splitPath(r)
+
+	var cfg CreateBucketConfiguration
+	if err := xml.NewDecoder(r.Body).Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		renderAPIError(ctx, w, r, s3err.MalformedXML, err)
+		return
+	}
+
+	if region := cmp.Or(cfg.LocationConstraint, defaultRegion); h.strictRegion && region != h.region {
+		renderAPIError(ctx, w, r, s3err.IllegalLocation,
+			errors.Errorf("location constraint %q, server region is %q", region, h.region))
+		return
+	}
 
 	if err := h.service.CreateBucket(ctx, name); err != nil {
 		renderError(ctx, w, r, err)
@@ -28,6 +55,13 @@ func (h *handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if cfg.LocationConstraint != "" {
+		if err := h.service.SetBucketRegion(ctx, name, cfg.LocationConstraint); err != nil {
+			renderError(ctx, w, r, err)
+			return
+		}
+	}
+
 	w.Header().Set("Location", "/"+name)
 	w.WriteHeader(http.StatusOK)
 }
@@ -2,13 +2,18 @@ package handler_test
 
 import (
 	"context"
+	"encoding/xml"
+	"net/http"
 	"testing"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
 	"github.com/go-faster/fs/internal/mock"
+	"github.com/go-faster/fs/storagemem"
 )
 
 func TestHandler_CreateBucket(t *testing.T) {
@@ -30,3 +35,64 @@ func TestHandler_CreateBucket(t *testing.T) {
 	err := newTestClient(t, svc).MakeBucket(ctx, expectedBucketName, minio.MakeBucketOptions{})
 	require.NoError(t, err)
 }
+
+func locationConstraintBody(region string) string {
+	return `<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">` +
+		`<LocationConstraint>` + region + `</LocationConstraint></CreateBucketConfiguration>`
+}
+
+// bucketLocation reads back GET /{bucket}?location.
+func bucketLocation(t *testing.T, h http.Handler, bucket string) string {
+	t.Helper()
+
+	rec := do(t, h, http.MethodGet, "/"+bucket+"?location", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var res handler.LocationConstraintResult
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &res))
+
+	return res.Value
+}
+
+func TestHandler_CreateBucket_LocationConstraint(t *testing.T) {
+	t.Parallel()
+
+	h := newStorageHandler(t)
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/plain", "", nil).Code)
+	require.Equal(t, http.StatusOK,
+		do(t, h, http.MethodPut, "/regional", locationConstraintBody("eu-west-1"), nil).Code)
+
+	// us-east-1, the default, is reported as an empty constraint.
+	require.Empty(t, bucketLocation(t, h, "plain"))
+	require.Equal(t, "eu-west-1", bucketLocation(t, h, "regional"))
+	// A missing bucket reports the server's region rather than failing the
+	// SDK's region probe.
+	require.Empty(t, bucketLocation(t, h, "missing"))
+
+	rec := do(t, h, http.MethodPut, "/broken", "<CreateBucketConfiguration>", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "MalformedXML", errorCode(t, rec.Body.String()))
+	require.Equal(t, http.StatusNotFound, do(t, h, http.MethodHead, "/broken", "", nil).Code)
+}
+
+func TestHandler_CreateBucket_StrictRegion(t *testing.T) {
+	t.Parallel()
+
+	h := handler.New(service.New(storagemem.New()),
+		handler.WithRegion("eu-central-1"), handler.WithStrictRegion())
+
+	require.Equal(t, http.StatusOK,
+		do(t, h, http.MethodPut, "/matching", locationConstraintBody("eu-central-1"), nil).Code)
+	require.Equal(t, "eu-central-1", bucketLocation(t, h, "matching"))
+
+	for name, body := range map[string]string{
+		"other":         locationConstraintBody("eu-west-1"),
+		"unconstrained": "",
+	} {
+		rec := do(t, h, http.MethodPut, "/"+name, body, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, name)
+		require.Equal(t, "IllegalLocationConstraintException", errorCode(t, rec.Body.String()), name)
+		require.Equal(t, http.StatusNotFound, do(t, h, http.MethodHead, "/"+name, "", nil).Code, name)
+	}
+}
@@ -2,13 +2,11 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 )
 
 func (h *handler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	name, _, _ := strings.Cut(path, "/")
+	name, _ := splitPath(r)
 
 	err := h.service.DeleteBucket(ctx, name)
 	if err != nil {
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// forceParam is the query parameter of the force bucket delete extension.
+const forceParam = "force"
+
+// ForceDeleteBucketResult is the XML document answering a force bucket
+// delete: what was removed along with the bucket.
+type ForceDeleteBucketResult struct {
+	XMLName xml.Name `xml:"ForceDeleteBucketResult"`
+	Bucket  string   `xml:"Bucket"`
+	Objects int      `xml:"Objects"`
+	Bytes   int64    `xml:"Bytes"`
+	Uploads int      `xml:"Uploads"`
+}
+
+// DeleteBucketForce implements the DELETE /{bucket}?force extension: the
+// bucket is deleted with all its objects and in-progress uploads
+// (fs.DeleteBucketRecursive), answered with a ForceDeleteBucketResult. A plain
+// DELETE /{bucket} still refuses a non-empty bucket with BucketNotEmpty. Each
+// removed object is reported to the event sink as ObjectRemoved:Delete, even
+// when the delete stops on an error. Like the pattern delete it needs an
+// authenticator and an Admin grant on "*".
+func (h *handler) DeleteBucketForce(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+
+	if !h.authenticated {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, errors.New("admin requests need authentication"))
+		return
+	}
+
+	removed, err := fs.DeleteBucketRecursiveFunc(ctx, h.service, bucket, func(key string) {
+		h.emit(w, notify.ObjectRemovedDelete, bucket, key, 0, "")
+	})
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	zctx.From(ctx).Warn("Bucket force-deleted",
+		zap.String("bucket", bucket),
+		zap.Int("objects", removed.Objects),
+		zap.Int64("bytes", removed.Bytes),
+	)
+
+	writeXML(ctx, w, r, ForceDeleteBucketResult{
+		Bucket:  removed.Bucket,
+		Objects: removed.Objects,
+		Bytes:   removed.Bytes,
+		Uploads: removed.Uploads,
+	})
+}
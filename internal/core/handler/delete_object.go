@@ -2,13 +2,14 @@ package handler
 
 import (
 	"net/http"
-	"strings"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/notify"
 )
 
 func (h *handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 	query := r.URL.Query()
 
 	// Check if this is an abort multipart upload request.
@@ -24,7 +25,12 @@ func (h *handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Regular delete object.
+	// Regular delete object. If-Match is evaluated by the storage layer under
+	// its write lock, as for a conditional PUT.
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		ctx = fs.WithDeleteCondition(ctx, &fs.DeleteCondition{IfMatch: ifMatch})
+	}
+
 	err := h.service.DeleteObject(ctx, bucket, key)
 	if err != nil {
 		renderError(ctx, w, r, err)
@@ -32,4 +38,6 @@ func (h *handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusNoContent)
+
+	h.emit(w, notify.ObjectRemovedDelete, bucket, key, 0, "")
 }
@@ -3,12 +3,24 @@ package handler
 import (
 	"encoding/xml"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// maxDeleteObjects is the most keys one DeleteObjects request may name, as on
+// S3.
+const maxDeleteObjects = 1000
+
+// dryRunParam is the query parameter of the DeleteObjects dry-run extension;
+// dryRunHeader marks a response of a dry run, in which nothing was deleted.
+const (
+	dryRunParam  = "dry-run"
+	dryRunHeader = "X-Fs-Dry-Run"
 )
 
 // DeleteObjectsRequest represents the XML request body for deleting multiple objects.
@@ -49,8 +61,7 @@ type DeleteError struct {
 }
 
 func (h *handler) HandleBucketPost(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, _, _ := strings.Cut(path, "/")
+	bucket, _ := splitPath(r)
 	query := r.URL.Query()
 
 	// Handle delete multiple objects operation.
@@ -59,18 +70,52 @@ func (h *handler) HandleBucketPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if query.Has(diffParam) {
+		h.DiffObjects(w, r)
+		return
+	}
+
+	if isFormUpload(r) {
+		h.PostObject(w, r, bucket)
+		return
+	}
+
 	// Unknown POST operation to bucket.
 	ctx := r.Context()
 	renderError(ctx, w, r, fs.ErrUnsupportedOperation)
 }
 
+// deleteObjects handles DeleteObjects. Only a request that cannot be acted on
+// at all (a body that is not a Delete document, or with no or too many keys)
+// fails as a whole, with 400 MalformedXML. Once the keys are known the answer
+// is 200: each key is reported under Deleted or Error, even when every one of
+// them failed, since clients read the per-key results rather than the status.
+//
+// With ?dry-run=true nothing is deleted: Deleted lists the keys that exist and
+// would be removed, Error the ones whose delete would be refused, and the
+// response carries X-Fs-Dry-Run: true.
 func (h *handler) deleteObjects(w http.ResponseWriter, r *http.Request, bucket string) {
 	ctx := r.Context()
 
+	var dryRun bool
+	if v := r.URL.Query().Get(dryRunParam); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.Errorf("invalid %s %q", dryRunParam, v))
+			return
+		}
+	}
+
 	// Parse the XML body.
 	var req DeleteObjectsRequest
 	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
-		renderError(ctx, w, r, err)
+		renderAPIError(ctx, w, r, s3err.MalformedXML, err)
+		return
+	}
+
+	if len(req.Objects) == 0 || len(req.Objects) > maxDeleteObjects {
+		renderAPIError(ctx, w, r, s3err.MalformedXML,
+			errors.Errorf("%d objects to delete, want 1 to %d", len(req.Objects), maxDeleteObjects))
 		return
 	}
 
@@ -80,9 +125,18 @@ func (h *handler) deleteObjects(w http.ResponseWriter, r *http.Request, bucket s
 
 	// Delete each object. Deleting a key that does not exist is a success in S3
 	// (the operation is idempotent); any other failure is reported per-object
-	// with its S3 error code.
+	// with its S3 error code. A dry run reports only the keys it would remove.
 	for _, obj := range req.Objects {
-		err := h.service.DeleteObject(ctx, bucket, obj.Key)
+		var err error
+		if dryRun {
+			err = h.previewDelete(r, bucket, obj.Key)
+			if errors.Is(err, fs.ErrObjectNotFound) {
+				continue
+			}
+		} else {
+			err = h.service.DeleteObject(ctx, bucket, obj.Key)
+		}
+
 		if err != nil && !errors.Is(err, fs.ErrObjectNotFound) {
 			api := s3err.FromError(err)
 			result.Errors = append(result.Errors, DeleteError{
@@ -94,12 +148,37 @@ func (h *handler) deleteObjects(w http.ResponseWriter, r *http.Request, bucket s
 			continue
 		}
 
+		if err == nil && !dryRun {
+			h.emit(w, notify.ObjectRemovedDelete, bucket, obj.Key, 0, "")
+		}
+
 		if !req.Quiet {
 			result.Deleted = append(result.Deleted, DeletedObject{Key: obj.Key})
 		}
 	}
 
+	if dryRun {
+		w.Header().Set(dryRunHeader, "true")
+	}
+
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(http.StatusOK)
 	_ = xml.NewEncoder(w).Encode(result)
 }
+
+// previewDelete reports what deleting key would do without deleting it: nil
+// when the object exists and would be removed, fs.ErrObjectNotFound when there
+// is nothing to remove, and fs.ErrAccessDenied when a legal hold protects it.
+// Prefix policies are not consulted; see COMPATIBILITY.md.
+func (h *handler) previewDelete(r *http.Request, bucket, key string) error {
+	held, err := h.service.GetObjectLegalHold(r.Context(), bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if held {
+		return errors.Wrapf(fs.ErrAccessDenied, "%q is under legal hold", key)
+	}
+
+	return nil
+}
@@ -2,6 +2,9 @@ package handler_test
 
 import (
 	"context"
+	"encoding/xml"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-faster/errors"
@@ -9,6 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/policy"
+	"github.com/go-faster/fs/storagemem"
 )
 
 func TestHandler_DeleteObjects_Success(t *testing.T) {
@@ -559,3 +566,131 @@ func TestHandler_DeleteObjects_EmptyKeys(t *testing.T) {
 
 	require.ElementsMatch(t, []string{"", "valid.txt"}, deletedKeys)
 }
+
+func TestHandler_DeleteObjects_PartialFailure(t *testing.T) {
+	const bucket = "bucket-a"
+
+	// Keys under locked/ cannot be deleted, like retention-locked objects.
+	registry := policy.NewRegistry()
+	registry.SetPrefixPolicy(bucket, "locked/", policy.PrefixPolicy{AppendOnly: true})
+
+	h := handler.New(service.New(storagemem.New(), service.WithPrefixPolicies(registry)))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	for _, key := range []string{"a.txt", "locked/b.txt", "c.txt", "locked/d.txt"} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", nil).Code)
+	}
+
+	rec := do(t, h, http.MethodPost, "/"+bucket+"?delete", `<Delete>
+		<Object><Key>a.txt</Key></Object>
+		<Object><Key>locked/b.txt</Key></Object>
+		<Object><Key>c.txt</Key></Object>
+		<Object><Key>locked/d.txt</Key></Object>
+		<Object><Key>missing.txt</Key></Object>
+	</Delete>`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result handler.DeleteObjectsResult
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+
+	var deleted []string
+	for _, d := range result.Deleted {
+		deleted = append(deleted, d.Key)
+	}
+
+	require.Equal(t, []string{"a.txt", "c.txt", "missing.txt"}, deleted)
+	require.Len(t, result.Errors, 2)
+
+	for i, key := range []string{"locked/b.txt", "locked/d.txt"} {
+		require.Equal(t, key, result.Errors[i].Key)
+		require.Equal(t, "AccessDenied", result.Errors[i].Code)
+		require.NotEmpty(t, result.Errors[i].Message)
+	}
+
+	// The refused keys are still there; the deleted ones are gone.
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/"+bucket+"/locked/b.txt", "", nil).Code)
+	require.Equal(t, http.StatusNotFound, do(t, h, http.MethodHead, "/"+bucket+"/a.txt", "", nil).Code)
+
+	// Every key failing is still a 200 with per-key errors.
+	rec = do(t, h, http.MethodPost, "/"+bucket+"?delete",
+		`<Delete><Object><Key>locked/b.txt</Key></Object></Delete>`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>AccessDenied</Code>")
+}
+
+func TestHandler_DeleteObjects_Malformed(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	tooMany := "<Delete>" + strings.Repeat("<Object><Key>k</Key></Object>", 1001) + "</Delete>"
+
+	for name, body := range map[string]string{
+		"NotXML":    "not xml",
+		"WrongRoot": "<Tagging></Tagging>",
+		"NoKeys":    "<Delete><Quiet>true</Quiet></Delete>",
+		"TooMany":   tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := do(t, h, http.MethodPost, "/"+bucket+"?delete", body, nil)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Equal(t, "MalformedXML", errorCode(t, rec.Body.String()))
+		})
+	}
+}
+
+func TestHandler_DeleteObjects_DryRun(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	for _, key := range []string{"a.txt", "b.txt", "held.txt"} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", nil).Code)
+	}
+
+	require.Equal(t, http.StatusOK,
+		do(t, h, http.MethodPut, "/"+bucket+"/held.txt?legal-hold", legalHoldBody("ON"), nil).Code)
+
+	body := `<Delete>
+		<Object><Key>a.txt</Key></Object>
+		<Object><Key>held.txt</Key></Object>
+		<Object><Key>missing.txt</Key></Object>
+		<Object><Key>b.txt</Key></Object>
+	</Delete>`
+
+	rec := do(t, h, http.MethodPost, "/"+bucket+"?delete&dry-run=true", body, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get("X-Fs-Dry-Run"))
+
+	var result handler.DeleteObjectsResult
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+
+	var deleted []string
+	for _, d := range result.Deleted {
+		deleted = append(deleted, d.Key)
+	}
+
+	// Only keys that exist would be removed; the held one would be refused.
+	require.Equal(t, []string{"a.txt", "b.txt"}, deleted)
+	require.Len(t, result.Errors, 1)
+	require.Equal(t, "held.txt", result.Errors[0].Key)
+	require.Equal(t, "AccessDenied", result.Errors[0].Code)
+
+	for _, key := range []string{"a.txt", "b.txt", "held.txt"} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/"+bucket+"/"+key, "", nil).Code, key)
+	}
+
+	// The real run removes what the dry run listed.
+	rec = do(t, h, http.MethodPost, "/"+bucket+"?delete", body, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("X-Fs-Dry-Run"))
+	require.Equal(t, http.StatusNotFound, do(t, h, http.MethodHead, "/"+bucket+"/a.txt", "", nil).Code)
+
+	t.Run("Invalid", func(t *testing.T) {
+		rec := do(t, h, http.MethodPost, "/"+bucket+"?delete&dry-run=maybe", body, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"path"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// patternParam is the query parameter of the pattern delete extension.
+const patternParam = "pattern"
+
+// DeleteByPatternResult is the XML document answering a pattern delete.
+type DeleteByPatternResult struct {
+	XMLName xml.Name `xml:"DeleteByPatternResult"`
+	Pattern string   `xml:"Pattern"`
+	Deleted int      `xml:"Deleted"`
+}
+
+// DeleteObjectsByPattern implements the DELETE /{bucket}?pattern=<glob>
+// extension: every object whose key matches the glob (path.Match) is deleted
+// (fs.DeleteObjectsByPattern), answered with a DeleteByPatternResult. Each
+// deleted object is reported to the event sink as ObjectRemoved:Delete, even
+// when the sweep stops on an error. Being a bulk delete it is an
+// administration request: it needs an authenticator and an Admin grant on "*".
+func (h *handler) DeleteObjectsByPattern(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+
+	if !h.authenticated {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, errors.New("admin requests need authentication"))
+		return
+	}
+
+	pattern := r.URL.Query().Get(patternParam)
+	if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.Errorf("invalid pattern %q", pattern))
+		return
+	}
+
+	deleted, err := fs.DeleteObjectsByPatternFunc(ctx, h.service, bucket, pattern, func(key string) {
+		h.emit(w, notify.ObjectRemovedDelete, bucket, key, 0, "")
+	})
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	zctx.From(ctx).Info("Deleted objects by pattern",
+		zap.String("bucket", bucket),
+		zap.String("pattern", pattern),
+		zap.Int("deleted", deleted),
+	)
+
+	writeXML(ctx, w, r, DeleteByPatternResult{Pattern: pattern, Deleted: deleted})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+const (
+	// diffParam selects the diff extension on a bucket POST.
+	diffParam = "diff"
+	// maxDiffBody caps a diff request body: about half a million entries
+	// of typical key length.
+	maxDiffBody = 64 << 20
+)
+
+// DiffRequest is the body of a diff: the keys a client holds, with the ETag
+// of its copy of each.
+type DiffRequest struct {
+	XMLName xml.Name     `xml:"Diff" json:"-"`
+	Objects []DiffObject `xml:"Object" json:"objects"`
+}
+
+// DiffObject is one key of a DiffRequest.
+type DiffObject struct {
+	Key  string `xml:"Key" json:"key"`
+	ETag string `xml:"ETag" json:"etag"`
+}
+
+// DiffResult answers a diff with the keys the client is missing (Added),
+// holds an outdated copy of (Changed), and holds but the bucket no longer
+// has (Deleted).
+type DiffResult struct {
+	XMLName xml.Name `xml:"DiffResult" json:"-"`
+	Added   []string `xml:"Added" json:"added"`
+	Changed []string `xml:"Changed" json:"changed"`
+	Deleted []string `xml:"Deleted" json:"deleted"`
+}
+
+// isDiff reports whether r is a diff, which only reads the bucket.
+func isDiff(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+
+	_, key := splitPath(r)
+
+	return key == "" && r.URL.Query().Has(diffParam)
+}
+
+// DiffObjects implements the POST /{bucket}?diff extension for sync tools:
+// given the client's key → ETag state it reports what changed on the server
+// (fs.DiffObjects), so the client transfers only that, without listing the
+// bucket itself. The body is a DiffRequest in JSON, or in XML when sent as
+// application/xml or text/xml; the DiffResult comes back in the same format.
+// It needs only read access to the bucket.
+func (h *handler) DiffObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	asXML := mediaType == "application/xml" || mediaType == "text/xml"
+
+	var (
+		req  DiffRequest
+		body = http.MaxBytesReader(w, r.Body, maxDiffBody)
+		err  error
+	)
+
+	if asXML {
+		err = xml.NewDecoder(body).Decode(&req)
+	} else {
+		err = json.NewDecoder(body).Decode(&req)
+	}
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &tooLarge):
+			renderAPIError(ctx, w, r, s3err.EntityTooLarge, err)
+		case asXML:
+			renderAPIError(ctx, w, r, s3err.MalformedXML, err)
+		case errors.Is(err, io.EOF):
+			renderAPIError(ctx, w, r, s3err.MissingRequestBody, err)
+		default:
+			renderAPIError(ctx, w, r, s3err.InvalidRequest, errors.Wrap(err, "decode diff request"))
+		}
+
+		return
+	}
+
+	state := make(map[string]string, len(req.Objects))
+	for _, o := range req.Objects {
+		state[o.Key] = o.ETag
+	}
+
+	added, changed, deleted, err := fs.DiffObjects(ctx, h.service, bucket, state)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	res := DiffResult{Added: added, Changed: changed, Deleted: deleted}
+
+	if asXML {
+		writeXML(ctx, w, r, res)
+		return
+	}
+
+	// Empty lists, not nulls, for clients that iterate without a check.
+	res.Added = nonNil(res.Added)
+	res.Changed = nonNil(res.Changed)
+	res.Deleted = nonNil(res.Deleted)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		zctx.From(ctx).Debug("Diff write failed", zap.Error(err))
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
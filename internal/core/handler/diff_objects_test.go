@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+)
+
+func TestDiffObjects(t *testing.T) {
+	t.Parallel()
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	put := func(key, body string) string {
+		rec := do(t, h, http.MethodPut, "/bucket-a/"+key, body, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return rec.Header().Get("ETag")
+	}
+
+	same := put("same.txt", "same")
+	stale := put("edited.txt", "v1")
+	put("edited.txt", "v2")
+	put("new.txt", "new")
+
+	want := handler.DiffResult{
+		Added:   []string{"new.txt"},
+		Changed: []string{"edited.txt"},
+		Deleted: []string{"gone.txt"},
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		body := `{"objects":[` +
+			// The ETag header's quoted form compares equal to the bare one.
+			`{"key":"same.txt","etag":` + jsonString(t, same) + `},` +
+			`{"key":"edited.txt","etag":` + jsonString(t, stale) + `},` +
+			`{"key":"gone.txt","etag":"0cc175b9c0f1b6a831c399e269772661"}]}`
+
+		rec := do(t, h, http.MethodPost, "/bucket-a?diff", body, map[string]string{"Content-Type": "application/json"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var got handler.DiffResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, want, got)
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		body := `<Diff>` +
+			`<Object><Key>same.txt</Key><ETag>` + strings.Trim(same, `"`) + `</ETag></Object>` +
+			`<Object><Key>edited.txt</Key><ETag>` + strings.Trim(stale, `"`) + `</ETag></Object>` +
+			`<Object><Key>gone.txt</Key><ETag>0cc175b9c0f1b6a831c399e269772661</ETag></Object>` +
+			`</Diff>`
+
+		rec := do(t, h, http.MethodPost, "/bucket-a?diff", body, map[string]string{"Content-Type": "application/xml"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var got handler.DiffResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &got))
+
+		got.XMLName = xml.Name{}
+		require.Equal(t, want, got)
+	})
+
+	t.Run("UpToDate", func(t *testing.T) {
+		body := `{"objects":[{"key":"same.txt","etag":` + jsonString(t, same) + `},` +
+			`{"key":"edited.txt","etag":` + jsonString(t, put("edited.txt", "v2")) + `},` +
+			`{"key":"new.txt","etag":` + jsonString(t, put("new.txt", "new")) + `}]}`
+
+		rec := do(t, h, http.MethodPost, "/bucket-a?diff", body, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.JSONEq(t, `{"added":[],"changed":[],"deleted":[]}`, rec.Body.String())
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		rec := do(t, h, http.MethodPost, "/bucket-a?diff", `{"objects":`, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidRequest", errorCode(t, rec.Body.String()))
+
+		rec = do(t, h, http.MethodPost, "/bucket-a?diff", "<Diff>", map[string]string{"Content-Type": "text/xml"})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "MalformedXML", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("NoSuchBucket", func(t *testing.T) {
+		rec := do(t, h, http.MethodPost, "/missing?diff", `{"objects":[]}`, nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()))
+	})
+}
+
+func jsonString(t *testing.T, s string) string {
+	t.Helper()
+
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	return string(b)
+}
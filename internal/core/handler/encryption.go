@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // SSE-C identifies keys by their MD5.
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// SSE-C header prefixes: the object's own key, and on a copy the key of the
+// source object. Each is followed by the customer-key header suffixes below.
+const (
+	sseCustomerPrefix           = "X-Amz-"
+	sseCustomerCopySourcePrefix = "X-Amz-Copy-Source-"
+
+	sseCustomerAlgorithmSuffix = "Server-Side-Encryption-Customer-Algorithm"
+	sseCustomerKeySuffix       = "Server-Side-Encryption-Customer-Key"
+	sseCustomerKeyMD5Suffix    = "Server-Side-Encryption-Customer-Key-Md5"
+)
+
+// sseCustomerAlgorithm is the only SSE-C algorithm S3 defines.
+const sseCustomerAlgorithm = "AES256"
+
+// parseCustomerKey reads the SSE-C headers under prefix. It returns nil when
+// none is present, and an error when they are incomplete, name another
+// algorithm, carry something other than a base64 256-bit key, or the key MD5
+// does not match the key.
+func parseCustomerKey(h http.Header, prefix string) (*fs.CustomerKey, error) {
+	algorithm := h.Get(prefix + sseCustomerAlgorithmSuffix)
+	encoded := h.Get(prefix + sseCustomerKeySuffix)
+	keyMD5 := h.Get(prefix + sseCustomerKeyMD5Suffix)
+
+	if algorithm == "" && encoded == "" && keyMD5 == "" {
+		return nil, nil
+	}
+
+	if algorithm != sseCustomerAlgorithm {
+		return nil, errors.Errorf("%s%s must be %s", prefix, sseCustomerAlgorithmSuffix, sseCustomerAlgorithm)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.Errorf("%s%s must be a base64-encoded 256-bit key", prefix, sseCustomerKeySuffix)
+	}
+
+	sum := md5.Sum(key) //nolint:gosec // SSE-C identifies keys by their MD5.
+	if subtle.ConstantTimeCompare([]byte(base64.StdEncoding.EncodeToString(sum[:])), []byte(keyMD5)) != 1 {
+		return nil, errors.Errorf("%s%s does not match the key", prefix, sseCustomerKeyMD5Suffix)
+	}
+
+	return &fs.CustomerKey{Key: key, KeyMD5: keyMD5}, nil
+}
+
+// withCustomerKey returns ctx carrying the request's SSE-C key under prefix,
+// if it sent one. Invalid headers are answered with InvalidArgument and ok is
+// false.
+func withCustomerKey(ctx context.Context, w http.ResponseWriter, r *http.Request, prefix string) (_ context.Context, ok bool) {
+	key, err := parseCustomerKey(r.Header, prefix)
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return ctx, false
+	}
+
+	if key == nil {
+		return ctx, true
+	}
+
+	return fs.WithCustomerKey(ctx, key), true
+}
+
+// setEncryptionHeaders reports how the backend encrypted the object at rest,
+// if it did: x-amz-server-side-encryption for a server-managed key, the
+// customer algorithm and key MD5 for SSE-C.
+func setEncryptionHeaders(h http.Header, sse, customerKeyMD5 string) {
+	if sse != "" {
+		h.Set("X-Amz-Server-Side-Encryption", sse)
+	}
+
+	if customerKeyMD5 != "" {
+		h.Set(sseCustomerPrefix+sseCustomerAlgorithmSuffix, sseCustomerAlgorithm)
+		h.Set(sseCustomerPrefix+sseCustomerKeyMD5Suffix, customerKeyMD5)
+	}
+}
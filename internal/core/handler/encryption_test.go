@@ -0,0 +1,146 @@
+package handler_test
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // SSE-C key MD5.
+	"encoding/base64"
+	"maps"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// sseCustomerHeaders returns SSE-C request headers for a key of b bytes,
+// prefixed "x-amz-" or "x-amz-copy-source-".
+func sseCustomerHeaders(prefix string, b byte) map[string]string {
+	key := bytes.Repeat([]byte{b}, 32)
+	sum := md5.Sum(key) //nolint:gosec // SSE-C key MD5.
+
+	return map[string]string{
+		prefix + "server-side-encryption-customer-algorithm": "AES256",
+		prefix + "server-side-encryption-customer-key":       base64.StdEncoding.EncodeToString(key),
+		prefix + "server-side-encryption-customer-key-MD5":   base64.StdEncoding.EncodeToString(sum[:]),
+	}
+}
+
+func TestServerSideEncryptionCustomerKey(t *testing.T) {
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	h := handler.New(service.New(storage))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	key := sseCustomerHeaders("x-amz-", 1)
+	keyMD5 := key["x-amz-server-side-encryption-customer-key-MD5"]
+
+	put := do(t, h, http.MethodPut, "/bucket-a/obj", "customer data", key)
+	require.Equal(t, http.StatusOK, put.Code, put.Body.String())
+	require.Equal(t, "AES256", put.Header().Get("x-amz-server-side-encryption-customer-algorithm"))
+	require.Equal(t, keyMD5, put.Header().Get("x-amz-server-side-encryption-customer-key-MD5"))
+
+	t.Run("CorrectKey", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			rec := do(t, h, method, "/bucket-a/obj", "", key)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, keyMD5, rec.Header().Get("x-amz-server-side-encryption-customer-key-MD5"))
+			require.Empty(t, rec.Header().Get("x-amz-server-side-encryption"))
+			require.Equal(t, "13", rec.Header().Get("Content-Length"))
+		}
+
+		require.Equal(t, "customer data", do(t, h, http.MethodGet, "/bucket-a/obj", "", key).Body.String())
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", sseCustomerHeaders("x-amz-", 2))
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Equal(t, "AccessDenied", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidRequest", errorCode(t, rec.Body.String()))
+
+		require.Equal(t, http.StatusBadRequest, do(t, h, http.MethodHead, "/bucket-a/obj", "", nil).Code)
+	})
+
+	t.Run("InvalidHeaders", func(t *testing.T) {
+		badMD5 := maps.Clone(key)
+		badMD5["x-amz-server-side-encryption-customer-key-MD5"] = sseCustomerHeaders("x-amz-", 2)["x-amz-server-side-encryption-customer-key-MD5"]
+
+		badAlgorithm := maps.Clone(key)
+		badAlgorithm["x-amz-server-side-encryption-customer-algorithm"] = "aws:kms"
+
+		for _, headers := range []map[string]string{badMD5, badAlgorithm, {"x-amz-server-side-encryption-customer-algorithm": "AES256"}} {
+			rec := do(t, h, http.MethodPut, "/bucket-a/other", "data", headers)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+		}
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		headers := sseCustomerHeaders("x-amz-copy-source-", 1)
+		headers["x-amz-copy-source"] = "/bucket-a/obj"
+
+		rec := do(t, h, http.MethodPut, "/bucket-a/copy", "", headers)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Empty(t, rec.Header().Get("x-amz-server-side-encryption-customer-key-MD5"))
+		require.Equal(t, "customer data", do(t, h, http.MethodGet, "/bucket-a/copy", "", nil).Body.String())
+
+		delete(headers, "x-amz-copy-source-server-side-encryption-customer-key")
+		require.Equal(t, http.StatusBadRequest, do(t, h, http.MethodPut, "/bucket-a/copy", "", headers).Code)
+	})
+
+	t.Run("RotateKey", func(t *testing.T) {
+		oldKey, newKey := sseCustomerHeaders("x-amz-", 3), sseCustomerHeaders("x-amz-", 4)
+
+		put := do(t, h, http.MethodPut, "/bucket-a/rotate", "rotated data", oldKey)
+		require.Equal(t, http.StatusOK, put.Code, put.Body.String())
+
+		// Decrypt with the old key, re-encrypt in place with the new one.
+		headers := sseCustomerHeaders("x-amz-copy-source-", 3)
+		maps.Copy(headers, newKey)
+		headers["x-amz-copy-source"] = "/bucket-a/rotate"
+
+		rec := do(t, h, http.MethodPut, "/bucket-a/rotate", "", headers)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, newKey["x-amz-server-side-encryption-customer-key-MD5"],
+			rec.Header().Get("x-amz-server-side-encryption-customer-key-MD5"))
+
+		get := do(t, h, http.MethodGet, "/bucket-a/rotate", "", newKey)
+		require.Equal(t, http.StatusOK, get.Code)
+		require.Equal(t, "rotated data", get.Body.String())
+
+		require.Equal(t, http.StatusForbidden, do(t, h, http.MethodGet, "/bucket-a/rotate", "", oldKey).Code)
+
+		// Both key MD5s are validated.
+		bad := maps.Clone(headers)
+		bad["x-amz-copy-source-server-side-encryption-customer-key-MD5"] = newKey["x-amz-server-side-encryption-customer-key-MD5"]
+		require.Equal(t, http.StatusBadRequest, do(t, h, http.MethodPut, "/bucket-a/rotate", "", bad).Code)
+
+		bad = maps.Clone(headers)
+		bad["x-amz-server-side-encryption-customer-key-MD5"] = oldKey["x-amz-server-side-encryption-customer-key-MD5"]
+		require.Equal(t, http.StatusBadRequest, do(t, h, http.MethodPut, "/bucket-a/rotate", "", bad).Code)
+
+		// Without a new key or REPLACE, a self-copy changes nothing.
+		same := sseCustomerHeaders("x-amz-copy-source-", 4)
+		same["x-amz-copy-source"] = "/bucket-a/rotate"
+		rec = do(t, h, http.MethodPut, "/bucket-a/rotate", "", same)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidRequest", errorCode(t, rec.Body.String()))
+	})
+}
+
+func TestServerSideEncryptionCustomerKey_Unsupported(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	rec := do(t, h, http.MethodPut, "/bucket-a/obj", "data", sseCustomerHeaders("x-amz-", 1))
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+	require.Equal(t, "NotImplemented", errorCode(t, rec.Body.String()))
+}
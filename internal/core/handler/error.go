@@ -10,15 +10,51 @@ import (
 	"github.com/go-faster/fs/internal/s3err"
 )
 
+// ErrorVerbosity is how much of an internal error an error response carries.
+type ErrorVerbosity int
+
+const (
+	// ErrorsPublic answers with the S3 code and its generic message only, so
+	// nothing about the server (file paths, wrapped causes) reaches clients.
+	ErrorsPublic ErrorVerbosity = iota
+	// ErrorsDebug appends the internal error to the message. For development.
+	ErrorsDebug
+)
+
+type errorDetailKey struct{}
+
+// withErrorDetail marks every request's context so that renderError and
+// renderAPIError include the internal error in the response.
+func withErrorDetail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), errorDetailKey{}, true)))
+	})
+}
+
+// errorDetail returns the message of err when ctx asks for error detail.
+func errorDetail(ctx context.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if on, _ := ctx.Value(errorDetailKey{}).(bool); !on {
+		return ""
+	}
+
+	return err.Error()
+}
+
 // renderError logs err and writes the corresponding S3 XML error response,
 // mapping the fs.Err* sentinels to their S3 codes.
 func renderError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	api := s3err.FromError(err)
+
 	zctx.From(ctx).Error("Request failed",
-		zap.String("code", s3err.FromError(err).Code),
+		zap.String("code", api.Code),
 		zap.Error(err),
 	)
 
-	s3err.Write(w, r, err)
+	s3err.WriteAPIDetail(w, r, api, errorDetail(ctx, err))
 }
 
 // renderAPIError logs and writes a specific S3 error (used where the handler
@@ -29,5 +65,5 @@ func renderAPIError(ctx context.Context, w http.ResponseWriter, r *http.Request,
 		zap.Error(err),
 	)
 
-	s3err.WriteAPI(w, r, api)
+	s3err.WriteAPIDetail(w, r, api, errorDetail(ctx, err))
 }
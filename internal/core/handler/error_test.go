@@ -0,0 +1,54 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+func TestErrorVerbosity(t *testing.T) {
+	const path = "/srv/fs/data/bucket/key"
+
+	svc := baseMock()
+	svc.GetObjectFunc = func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+		return nil, errors.Wrap(errors.New("open "+path+": permission denied"), "get object")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		opts    []handler.Option
+		visible bool
+	}{
+		{name: "Default"},
+		{name: "Public", opts: []handler.Option{handler.WithErrorVerbosity(handler.ErrorsPublic)}},
+		{name: "Debug", opts: []handler.Option{handler.WithErrorVerbosity(handler.ErrorsDebug)}, visible: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.New(svc, tt.opts...)
+
+			rec := do(t, h, http.MethodGet, "/bucket/key", "", nil)
+			require.Equal(t, http.StatusInternalServerError, rec.Code)
+			require.Equal(t, "InternalError", errorCode(t, rec.Body.String()))
+			require.Contains(t, rec.Body.String(), s3err.InternalError.Message)
+
+			if tt.visible {
+				require.Contains(t, rec.Body.String(), path)
+			} else {
+				require.NotContains(t, rec.Body.String(), path)
+			}
+		})
+	}
+
+	// Errors the handler raises itself carry their detail the same way.
+	h := handler.New(svc, handler.WithErrorVerbosity(handler.ErrorsDebug))
+	rec := do(t, h, http.MethodPut, "/bucket/key", "x", map[string]string{"Content-Range": "bytes x-y/*"})
+	require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	require.Contains(t, rec.Body.String(), `invalid Content-Range &#34;bytes x-y/*&#34;`)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-faster/fs/notify"
+)
+
+// emit reports a successful object change to the event sink, if one is
+// configured. The record carries the response's request id so consumers can
+// correlate it with access logs. An unknown size (-1, a PUT without a length)
+// is left out like a removal's.
+func (h *handler) emit(w http.ResponseWriter, name notify.Name, bucket, key string, size int64, etag string) {
+	if h.events == nil {
+		return
+	}
+
+	rec := notify.NewRecord(name, bucket, key, max(size, 0), etag)
+	rec.ResponseElements = map[string]string{
+		"x-amz-request-id": w.Header().Get("x-amz-request-id"),
+	}
+
+	h.events(notify.Event{Records: []notify.Record{rec}})
+}
@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/notify"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// eventRecorder is a notify.Sink collecting every record it receives.
+type eventRecorder struct {
+	mu      sync.Mutex
+	records []notify.Record
+}
+
+func (e *eventRecorder) sink(ev notify.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.records = append(e.records, ev.Records...)
+}
+
+func (e *eventRecorder) names() []notify.Name {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]notify.Name, len(e.records))
+	for i, r := range e.records {
+		names[i] = r.EventName
+	}
+
+	return names
+}
+
+func TestEventSink(t *testing.T) {
+	const bucket = "bucket-a"
+
+	var events eventRecorder
+
+	h := handler.New(service.New(storagemem.New()), handler.WithEventSink(events.sink))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	rec := do(t, h, http.MethodPut, "/"+bucket+"/dir/obj", "hello", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.Len(t, events.records, 1)
+	created := events.records[0]
+	require.Equal(t, notify.ObjectCreatedPut, created.EventName)
+	require.Equal(t, "aws:s3", created.EventSource)
+	require.Equal(t, "2.1", created.EventVersion)
+	require.Equal(t, bucket, created.S3.Bucket.Name)
+	require.Equal(t, "arn:aws:s3:::"+bucket, created.S3.Bucket.ARN)
+	require.Equal(t, "dir/obj", created.S3.Object.Key)
+	require.EqualValues(t, 5, created.S3.Object.Size)
+	require.Equal(t, "5d41402abc4b2a76b9719d911017c592", created.S3.Object.ETag)
+	require.Equal(t, rec.Header().Get("x-amz-request-id"), created.ResponseElements["x-amz-request-id"])
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/copy", "", map[string]string{
+		"X-Amz-Copy-Source": "/" + bucket + "/dir/obj",
+	}).Code)
+	require.Equal(t, http.StatusNoContent, do(t, h, http.MethodDelete, "/"+bucket+"/dir/obj", "", nil).Code)
+
+	// Failed requests are not reported.
+	require.Equal(t, http.StatusNotFound, do(t, h, http.MethodPut, "/missing-bucket/obj", "x", nil).Code)
+
+	require.Equal(t, []notify.Name{
+		notify.ObjectCreatedPut,
+		notify.ObjectCreatedCopy,
+		notify.ObjectRemovedDelete,
+	}, events.names())
+	require.EqualValues(t, 5, events.records[1].S3.Object.Size)
+	require.Less(t, events.records[0].S3.Object.Sequencer, events.records[2].S3.Object.Sequencer)
+}
+
+func TestEventSink_Multiple(t *testing.T) {
+	const bucket = "bucket-a"
+
+	var first, second eventRecorder
+
+	h := handler.New(service.New(storagemem.New()),
+		handler.WithEventSink(first.sink),
+		handler.WithEventSink(second.sink),
+	)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/obj", "x", nil).Code)
+	require.Equal(t, http.StatusNoContent, do(t, h, http.MethodDelete, "/"+bucket+"/obj", "", nil).Code)
+
+	want := []notify.Name{notify.ObjectCreatedPut, notify.ObjectRemovedDelete}
+	require.Equal(t, want, first.names())
+	require.Equal(t, want, second.names())
+}
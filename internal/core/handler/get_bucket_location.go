@@ -1,22 +1,42 @@
 package handler
 
 import (
+	"cmp"
 	"encoding/xml"
 	"net/http"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
 )
 
 // LocationConstraintResult is the XML response for GetBucketLocation. An empty
-// constraint denotes the default region (us-east-1), which is what this
-// single-region server always reports.
+// constraint denotes the default region (us-east-1).
 type LocationConstraintResult struct {
 	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
 	Value   string   `xml:",chardata"`
 }
 
-// GetBucketLocation implements GET /{bucket}?location, always reporting the
-// default region. S3 SDKs (e.g. minio-go) call this to cache a bucket's region
-// before other operations, so it stays cheap and unconditional rather than
-// verifying the bucket exists.
+// GetBucketLocation implements GET /{bucket}?location, reporting the region
+// recorded when the bucket was created, or the server's region (WithRegion)
+// for a bucket created without one. S3 SDKs (e.g. minio-go) call this to
+// cache a bucket's region before other operations, so a bucket that does not
+// exist is not an error here: it reports the server's region, and the
+// operation that follows fails with NoSuchBucket.
 func (h *handler) GetBucketLocation(w http.ResponseWriter, r *http.Request) {
-	writeXML(r.Context(), w, r, LocationConstraintResult{})
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+
+	region, err := h.service.BucketRegion(ctx, bucket)
+	if err != nil && !errors.Is(err, fs.ErrBucketNotFound) {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	region = cmp.Or(region, h.region)
+	if region == defaultRegion {
+		region = ""
+	}
+
+	writeXML(ctx, w, r, LocationConstraintResult{Value: region})
 }
@@ -1,26 +1,120 @@
 package handler
 
 import (
+	"context"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
 
 	"github.com/go-faster/fs"
 )
 
 func (h *handler) GetObject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
 
 	resp, err := h.service.GetObject(ctx, bucket, key)
+
+	resp, err = h.preferGzip(ctx, w, r, bucket, key, resp, err)
 	if err != nil {
+		if errors.Is(err, fs.ErrObjectNotFound) && h.serveNotFoundKey(ctx, w, r, bucket, key) {
+			return
+		}
+
 		renderError(ctx, w, r, err)
 		return
 	}
 
-	serveObject(w, r, key, resp)
+	serveObject(w, r, key, resp, h.defaultContentType)
+}
+
+// integrityReader records an fs.ErrIntegrity returned mid-body by a backend
+// that verifies while streaming (err), and any other read failure (readErr)
+// for logServeError. Seek is forwarded when the backend reader supports it.
+type integrityReader struct {
+	io.Reader
+	err     error
+	readErr error
+}
+
+func (ir *integrityReader) Read(p []byte) (int, error) {
+	n, err := ir.Reader.Read(p)
+
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+	case errors.Is(err, fs.ErrIntegrity):
+		ir.err = err
+	default:
+		ir.readErr = err
+	}
+
+	return n, err
+}
+
+type integrityReadSeeker struct {
+	*integrityReader
+	io.Seeker
+}
+
+// abortIfCorrupt ends the response without completing it when the body failed
+// integrity verification. The status and part of the body are already sent,
+// so aborting (net/http resets the connection) is the only way to keep the
+// client from taking a short body as the whole object.
+func abortIfCorrupt(ctx context.Context, ir *integrityReader) {
+	if ir.err == nil {
+		return
+	}
+
+	zctx.From(ctx).Error("Corrupt object detected while serving", zap.Error(ir.err))
+	panic(http.ErrAbortHandler)
+}
+
+// logServeError logs why an object body was cut short, given the error the
+// copy ended with. Clients abort downloads all the time, so one that went
+// away (a broken pipe, a reset connection, the request context canceled) is
+// logged at debug level only; a read from storage or a write that failed
+// otherwise is a warning. Integrity failures are left to abortIfCorrupt.
+func logServeError(ctx context.Context, ir *integrityReader, err error) {
+	if ir.err != nil {
+		return
+	}
+
+	msg := "Response write failed while serving object"
+	if ir.readErr != nil {
+		err, msg = ir.readErr, "Object read failed while serving"
+	}
+
+	if err == nil {
+		return
+	}
+
+	lg := zctx.From(ctx)
+	if clientGone(ctx, err) {
+		lg.Debug("Client disconnected during download", zap.Error(err))
+		return
+	}
+
+	lg.Warn(msg, zap.Error(err))
+}
+
+// clientGone reports whether err, from serving a response, means the client
+// closed the connection rather than that something failed.
+func clientGone(ctx context.Context, err error) bool {
+	return ctx.Err() != nil ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET)
 }
 
 // quoteETag returns the ETag as a quoted string, as required by S3/HTTP.
@@ -35,41 +129,115 @@ func quoteETag(etag string) string {
 // serveObject writes an object response, delegating to http.ServeContent when the
 // reader is seekable so that Range requests (206 + Content-Range) and conditional
 // headers (If-Range, If-Modified-Since, If-Match, If-None-Match) are handled. It is
-// safe for HEAD requests. The reader is always closed.
-func serveObject(w http.ResponseWriter, r *http.Request, key string, resp *fs.GetObjectResponse) {
+// safe for HEAD requests, and GET and HEAD share every header via
+// setObjectHeaders. A ?partNumber request is served as the range of that part
+// (see partRequest). An object stored without a Content-Type is served as
+// defaultType. The reader is always closed.
+func serveObject(w http.ResponseWriter, r *http.Request, key string, resp *fs.GetObjectResponse, defaultType string) {
 	defer func() { _ = resp.Reader.Close() }()
 
-	// Stored representation headers and x-amz-meta-* pairs; without a stored
-	// Content-Type the S3 default applies.
-	w.Header().Set("Content-Type", "application/octet-stream")
-	writeObjectMetadata(w.Header(), resp.Metadata)
+	r, ok := partRequest(w, r, resp)
+	if !ok {
+		return
+	}
+
+	r = dropMultiRange(r)
 
-	if resp.ETag != "" {
-		w.Header().Set("ETag", quoteETag(resp.ETag))
+	ir := &integrityReader{Reader: resp.Reader}
+
+	if resp.MetadataMissing {
+		zctx.From(r.Context()).Warn("Object metadata unavailable, serving defaults",
+			zap.String("key", key),
+		)
 	}
 
-	w.Header().Set("Accept-Ranges", "bytes")
+	setObjectHeaders(w.Header(), resp, defaultType)
+
+	ow := &objectWriter{ResponseWriter: w, r: r, encoding: w.Header().Get("Content-Encoding")}
+	w.Header().Del("Content-Encoding")
+
+	if s, ok := resp.Reader.(io.Seeker); ok {
+		// ServeContent handles Range, conditional requests, Content-Range and
+		// the 206/304/412/416 status codes, and writes no body for HEAD
+		// requests.
+		http.ServeContent(ow, r, key, resp.LastModified, integrityReadSeeker{ir, s})
+		logServeError(r.Context(), ir, ow.writeErr)
+		abortIfCorrupt(r.Context(), ir)
 
-	if rs, ok := resp.Reader.(io.ReadSeeker); ok {
-		// ServeContent handles Range, conditional requests, Content-Range,
-		// Last-Modified and the 206/304/412/416 status codes, and writes no body
-		// for HEAD requests.
-		http.ServeContent(w, r, key, resp.LastModified, rs)
 		return
 	}
 
-	// Fallback for non-seekable readers: full body, no range support.
-	if resp.Size > 0 {
-		w.Header().Set("Content-Length", strconv.FormatInt(resp.Size, 10))
-	}
+	// Fallback for non-seekable readers: full body, no range support, but
+	// the same preconditions.
+	switch checkPreconditions(r, w.Header().Get("ETag"), resp.LastModified) {
+	case http.StatusPreconditionFailed:
+		ow.WriteHeader(http.StatusPreconditionFailed)
+		return
+	case http.StatusNotModified:
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Length")
+		ow.WriteHeader(http.StatusNotModified)
 
-	if !resp.LastModified.IsZero() {
-		w.Header().Set("Last-Modified", resp.LastModified.UTC().Format(http.TimeFormat))
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	ow.WriteHeader(http.StatusOK)
 
 	if r.Method != http.MethodHead {
-		_, _ = io.Copy(w, resp.Reader)
+		_, err := io.Copy(ow, ir)
+		logServeError(r.Context(), ir, err)
+		abortIfCorrupt(r.Context(), ir)
+	}
+}
+
+// dropMultiRange removes a Range header asking for more than one range, so
+// the whole object is served with 200 as S3 does, rather than the
+// multipart/byteranges body http.ServeContent would build: S3 clients never
+// parse one, and a 206 without Content-Range would read as the whole object.
+func dropMultiRange(r *http.Request) *http.Request {
+	spec := r.Header.Get("Range")
+	if !strings.Contains(spec, ",") {
+		return r
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Del("Range")
+
+	return r
+}
+
+// checkPreconditions evaluates the conditional headers of a GET or HEAD
+// against the object's quoted etag and modification time exactly as
+// http.ServeContent does (RFC 9110 section 13.2.2), for readers it cannot
+// serve: If-Match, or If-Unmodified-Since only without it, fails with 412;
+// then If-None-Match, or If-Modified-Since only without it, yields 304. Dates
+// compare at the one-second resolution of HTTP dates, and an unparsable date
+// or zero modification time leaves its condition out. It returns 0 when the
+// object is to be served.
+func checkPreconditions(r *http.Request, etag string, modified time.Time) int {
+	modified = modified.Truncate(time.Second)
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !etagMatches(ifMatch, etag) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := headerTime(r, "If-Unmodified-Since"); ok && !modified.IsZero() && modified.After(since) {
+		return http.StatusPreconditionFailed
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag) {
+			return http.StatusNotModified
+		}
+	} else if since, ok := headerTime(r, "If-Modified-Since"); ok && !modified.IsZero() && !modified.After(since) {
+		return http.StatusNotModified
 	}
+
+	return 0
+}
+
+// headerTime parses an HTTP-date request header.
+func headerTime(r *http.Request, name string) (time.Time, bool) {
+	t, err := http.ParseTime(r.Header.Get(name))
+	return t, err == nil
 }
@@ -3,12 +3,21 @@ package handler_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/iotest"
 	"time"
 
+	"github.com/go-faster/sdk/zctx"
 	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/mock"
@@ -358,3 +367,157 @@ func TestGetObject_BucketNotFound_EmptyKey(t *testing.T) {
 
 	require.Equal(t, 404, w.Code, "Should return 404 for non-existent bucket")
 }
+
+// corruptReader serves its content but fails the read that would complete it,
+// like a backend verifying checksums while streaming.
+type corruptReader struct {
+	*bytes.Reader
+}
+
+func (r corruptReader) Read(p []byte) (int, error) {
+	if int64(len(p)) >= int64(r.Len()) {
+		return 0, fs.ErrIntegrity
+	}
+
+	return r.Reader.Read(p)
+}
+
+func (corruptReader) Close() error { return nil }
+
+func TestGetObject_IntegrityFailureAbortsResponse(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("x"), 64<<10)
+
+	svc := &mock.StorageMock{
+		GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+			return &fs.GetObjectResponse{
+				Reader:       corruptReader{bytes.NewReader(content)},
+				Size:         int64(len(content)),
+				LastModified: time.Now(),
+			}, nil
+		},
+	}
+
+	srv := httptest.NewServer(newTestHandler(svc))
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/test-bucket/rotted", http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	// The headers went out before the mismatch was found, but the body must
+	// not end cleanly.
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	data, err := io.ReadAll(resp.Body)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Less(t, len(data), len(content))
+}
+
+// zeroObject is an endless object body of zeros, seekable or not.
+type zeroObject struct{ off int64 }
+
+func (z *zeroObject) Read(p []byte) (int, error) {
+	clear(p)
+	z.off += int64(len(p))
+
+	return len(p), nil
+}
+
+func (z *zeroObject) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekStart {
+		// ServeContent measures the size by seeking to the end.
+		return 1 << 40, nil
+	}
+
+	z.off = offset
+
+	return offset, nil
+}
+
+func (*zeroObject) Close() error { return nil }
+
+func TestGetObject_ClientAbortLogsQuietly(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		reader func() io.ReadCloser
+	}{
+		{"Seekable", func() io.ReadCloser { return &zeroObject{} }},
+		{"NonSeekable", func() io.ReadCloser { return io.NopCloser(io.LimitReader(&zeroObject{}, 1<<40)) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			svc := &mock.StorageMock{
+				GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+					return &fs.GetObjectResponse{Reader: tc.reader(), Size: 1 << 40, LastModified: time.Now()}, nil
+				},
+			}
+
+			srv := httptest.NewUnstartedServer(newTestHandler(svc))
+			srv.Config.BaseContext = func(net.Listener) context.Context {
+				return zctx.Base(context.Background(), zap.New(core))
+			}
+			srv.Start()
+			t.Cleanup(srv.Close)
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/test-bucket/huge", http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := srv.Client().Do(req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// Take a little of the body, then hang up.
+			_, err = io.CopyN(io.Discard, resp.Body, 1<<20)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Eventually(t, func() bool {
+				return logs.FilterMessage("Client disconnected during download").Len() == 1
+			}, 10*time.Second, 10*time.Millisecond)
+
+			for _, e := range logs.All() {
+				require.Less(t, e.Level, zapcore.WarnLevel, e.Message)
+			}
+		})
+	}
+}
+
+func TestGetObject_ReadFailureWarns(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	svc := &mock.StorageMock{
+		GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+			return &fs.GetObjectResponse{
+				Reader: io.NopCloser(io.MultiReader(
+					bytes.NewReader(make([]byte, 1024)),
+					iotest.ErrReader(errors.New("disk on fire")),
+				)),
+				Size:         4096,
+				LastModified: time.Now(),
+			}, nil
+		},
+	}
+
+	ctx := zctx.Base(context.Background(), zap.New(core))
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/test-bucket/broken", http.NoBody)
+	rec := httptest.NewRecorder()
+	newTestHandler(svc).ServeHTTP(rec, req)
+
+	warnings := logs.FilterMessage("Object read failed while serving").All()
+	require.Len(t, warnings, 1)
+	require.Equal(t, zapcore.WarnLevel, warnings[0].Level)
+	require.Empty(t, logs.FilterMessage("Client disconnected during download").All())
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// gzipStaticSuffix names the precompressed variant of an object: key.gz
+// holds key gzip-compressed.
+const gzipStaticSuffix = ".gz"
+
+// preferGzip swaps a GET or HEAD of key for its precompressed variant when
+// WithGzipStatic is on, the client accepts gzip and key.gz exists. The
+// variant is served with Content-Encoding: gzip and the Content-Type of the
+// plain object (guessed from the key's extension when only the variant
+// exists); its ETag, length and byte ranges are its own. plain and plainErr
+// are the result of fetching key, returned as they are when the variant does
+// not apply; plain is closed when it is replaced.
+func (h *handler) preferGzip(
+	ctx context.Context, w http.ResponseWriter, r *http.Request, bucket, key string,
+	plain *fs.GetObjectResponse, plainErr error,
+) (*fs.GetObjectResponse, error) {
+	if !h.gzipStatic || strings.HasSuffix(key, gzipStaticSuffix) {
+		return plain, plainErr
+	}
+
+	// The response depends on Accept-Encoding whichever variant is served.
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) || (plainErr != nil && !errors.Is(plainErr, fs.ErrObjectNotFound)) {
+		return plain, plainErr
+	}
+
+	gz, err := h.service.GetObject(ctx, bucket, key+gzipStaticSuffix)
+	if err != nil {
+		return plain, plainErr
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if plain != nil {
+		contentType = plain.Metadata.ContentType
+		_ = plain.Reader.Close()
+	}
+
+	gz.Metadata.ContentType = contentType
+	gz.Metadata.ContentEncoding = "gzip"
+
+	return gz, nil
+}
+
+// acceptsGzip reports whether an Accept-Encoding value admits gzip: it lists
+// gzip (or *) without q=0.
+func acceptsGzip(header string) bool {
+	for spec := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(spec, ";")
+
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.String()
+}
+
+func TestGetObject_GzipStatic(t *testing.T) {
+	h := handler.New(service.New(storagemem.New()), handler.WithGzipStatic())
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	const script = "console.log('hello, world');\n"
+
+	compressed := gzipString(t, script)
+
+	put := func(key, body, contentType string) string {
+		rec := do(t, h, http.MethodPut, "/bucket-a/"+key, body, map[string]string{"Content-Type": contentType})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return rec.Header().Get("ETag")
+	}
+
+	plainETag := put("app.js", script, "text/javascript")
+	gzETag := put("app.js.gz", compressed, "application/gzip")
+	put("only.css.gz", gzipString(t, "body{}"), "application/gzip")
+
+	t.Run("AcceptsGzip", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/app.js", "", map[string]string{"Accept-Encoding": "gzip, deflate, br"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "text/javascript", rec.Header().Get("Content-Type"))
+		require.Equal(t, gzETag, rec.Header().Get("ETag"))
+		require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+		zr, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.Equal(t, script, string(got))
+	})
+
+	t.Run("Plain", func(t *testing.T) {
+		for _, accept := range []string{"", "identity", "gzip;q=0, br"} {
+			rec := do(t, h, http.MethodGet, "/bucket-a/app.js", "", map[string]string{"Accept-Encoding": accept})
+			require.Equal(t, http.StatusOK, rec.Code, accept)
+			require.Empty(t, rec.Header().Get("Content-Encoding"), accept)
+			require.Equal(t, plainETag, rec.Header().Get("ETag"), accept)
+			require.Equal(t, script, rec.Body.String(), accept)
+			require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"), accept)
+		}
+	})
+
+	t.Run("RangeOfVariant", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/app.js", "", map[string]string{
+			"Accept-Encoding": "gzip",
+			"Range":           "bytes=0-9",
+		})
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+		require.Equal(t, compressed[:10], rec.Body.String())
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("Head", func(t *testing.T) {
+		rec := do(t, h, http.MethodHead, "/bucket-a/app.js", "", map[string]string{"Accept-Encoding": "gzip"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, gzETag, rec.Header().Get("ETag"))
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("OnlyVariant", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/only.css", "", map[string]string{"Accept-Encoding": "gzip"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		require.Contains(t, rec.Header().Get("Content-Type"), "text/css")
+
+		rec = do(t, h, http.MethodGet, "/bucket-a/only.css", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("VariantRequestedDirectly", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/app.js.gz", "", map[string]string{"Accept-Encoding": "gzip"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+}
+
+func TestGetObject_GzipStaticOff(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/app.js", "plain", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/app.js.gz", gzipString(t, "plain"), nil).Code)
+
+	rec := do(t, h, http.MethodGet, "/bucket-a/app.js", "", map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, "plain", rec.Body.String())
+	require.Empty(t, rec.Header().Get("Vary"))
+}
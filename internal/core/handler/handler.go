@@ -4,25 +4,94 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+	"net/netip"
 	"strings"
+	"time"
 
+	"github.com/go-faster/errors"
 	"github.com/go-faster/sdk/zctx"
 	"go.uber.org/zap"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/ingest"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/internal/sigv4"
+	"github.com/go-faster/fs/notify"
 )
 
 type handler struct {
-	service fs.Storage
+	service     fs.Storage
+	owner       Owner
+	maxListKeys int
+	// normalizePrefix trims a leading slash from listing prefixes.
+	normalizePrefix bool
+	events          notify.Sink
+	// uploads bounds concurrent object PUTs; nil means unlimited.
+	uploads *semaphore.Weighted
+	// maintenance, when on, refuses writes; authenticated gates the admin
+	// requests that toggle it.
+	maintenance   MaintenanceSwitch
+	authenticated bool
+	// reset enables the ?all admin endpoint that deletes every bucket.
+	reset bool
+	// gzipStatic serves key.gz in place of key to clients accepting gzip.
+	gzipStatic bool
+	// noOverwriteRename stores PUTs to existing keys under derived keys.
+	noOverwriteRename bool
+	// listingGzip compresses listing responses for clients accepting gzip.
+	listingGzip bool
+	// authenticator and formVerifier authenticate form uploads (POST object),
+	// which carry their credentials in the body; nil without WithAuthenticator.
+	authenticator Authenticator
+	formVerifier  *sigv4.Verifier
+	// authorizer vets form uploads, which authorizeMiddleware leaves to
+	// PostObject; nil without WithAuthorizer.
+	authorizer Authorizer
+	notFound   NotFoundResolver
+	// ingest serves PUTs with x-fs-source-url; nil without WithURLIngest.
+	ingest *ingest.Ingester
+	// downloads tracks ?download-id GETs; nil without
+	// WithResumableDownloads.
+	downloads *downloadSessions
+	// defaultContentType is served for objects stored without a type.
+	defaultContentType string
+	// region is the server's region, reported for buckets created without
+	// a LocationConstraint; with strictRegion, CreateBucket refuses any
+	// other constraint.
+	region       string
+	strictRegion bool
 }
 
 // Option configures the handler built by New.
 type Option func(*options)
 
 type options struct {
-	authenticator Authenticator
-	cors          CORSResolver
+	authenticator      Authenticator
+	authorizer         Authorizer
+	cors               CORSResolver
+	owner              Owner
+	rateLimit          *rateLimit
+	maxListKeys        int
+	normalizePrefix    bool
+	events             notify.Sink
+	maxUploads         int
+	inflight           inflightLimits
+	maintenance        MaintenanceSwitch
+	notFound           NotFoundResolver
+	reset              bool
+	gzipStatic         bool
+	listingGzip        bool
+	noOverwriteRename  bool
+	tracer             Tracer
+	ingest             []ingest.Option
+	ingestEnabled      bool
+	downloadTTL        time.Duration
+	errorVerbosity     ErrorVerbosity
+	defaultContentType string
+	region             string
+	strictRegion       bool
 }
 
 // WithAuthenticator enables SigV4 authentication and grant-based authorization
@@ -31,31 +100,299 @@ func WithAuthenticator(a Authenticator) Option {
 	return func(o *options) { o.authenticator = a }
 }
 
+// WithAuthorizer consults a after authentication for every request, denying
+// it with AccessDenied when a returns an error, so embedders can enforce
+// their own policy (bucket ownership, read-only principals) on top of, or
+// without, WithAuthenticator's grants. Without an authenticator every
+// request is anonymous: a sees an empty principal.
+func WithAuthorizer(a Authorizer) Option {
+	return func(o *options) { o.authorizer = a }
+}
+
 // WithCORS enables per-bucket CORS: OPTIONS preflight handling and CORS
 // response headers on cross-origin requests, resolved through c.
 func WithCORS(c CORSResolver) Option {
 	return func(o *options) { o.cors = c }
 }
 
+// WithOwner sets the owner identity reported in listings (the Owner element of
+// ListBuckets and ListObjects results). Without it,
+// DefaultOwnerID/DefaultOwnerDisplayName are used.
+func WithOwner(id, displayName string) Option {
+	return func(o *options) { o.owner = Owner{ID: id, DisplayName: displayName} }
+}
+
+// WithMaxListKeys sets the largest page ListObjects returns, in place of the
+// S3 cap of 1000 keys; requests still get 1000 unless they ask for more with
+// max-keys. n <= 0 removes the cap. Listings are streamed, so a large page
+// costs the server no more memory than the object list it is cut from.
+func WithMaxListKeys(n int) Option {
+	return func(o *options) { o.maxListKeys = n }
+}
+
+// WithPrefixNormalization trims a single leading slash from the prefix of
+// listing requests before matching, so "/dir/" lists the keys under "dir/" —
+// a prefix copied from a URL path otherwise matches nothing. Off by default:
+// S3 matches prefixes byte for byte, and a key that really begins with "/"
+// cannot be listed by prefix while this is on. The echoed Prefix is the
+// trimmed value.
+func WithPrefixNormalization() Option {
+	return func(o *options) { o.normalizePrefix = true }
+}
+
+// WithEventSink reports object changes to sink as S3 event notifications:
+// ObjectCreated for PUT, copy and multipart completion, ObjectRemoved for
+// deletes. Events are sent only after the change succeeded, synchronously, so
+// sink must not block; wrap slow destinations in a notify.Queue. Given more
+// than once, every sink receives every event, in the order given.
+func WithEventSink(sink notify.Sink) Option {
+	return func(o *options) {
+		prev := o.events
+		if prev == nil {
+			o.events = sink
+			return
+		}
+
+		o.events = func(e notify.Event) {
+			prev(e)
+			sink(e)
+		}
+	}
+}
+
+// WithMaxConcurrentUploads lets at most n object uploads (PutObject,
+// UploadPart and server-side copies) run at once; further ones are refused
+// with 503 SlowDown and Retry-After instead of waiting. Reads are not
+// counted. n <= 0 (the default) means no limit.
+func WithMaxConcurrentUploads(n int) Option {
+	return func(o *options) { o.maxUploads = n }
+}
+
+// WithMaxInFlight lets at most n requests be served at once; further ones are
+// refused with 503 SlowDown and Retry-After instead of waiting, before any
+// other work is done for them. n <= 0 (the default) means no limit.
+func WithMaxInFlight(n int) Option {
+	return func(o *options) { o.inflight.total = n }
+}
+
+// WithMaxInFlightReads is WithMaxInFlight for reads (GET, HEAD and OPTIONS)
+// alone, so a flood of downloads cannot starve writes; it applies within the
+// WithMaxInFlight limit, if any.
+func WithMaxInFlightReads(n int) Option {
+	return func(o *options) { o.inflight.reads = n }
+}
+
+// WithMaxInFlightWrites is WithMaxInFlight for every method other than those
+// WithMaxInFlightReads counts.
+func WithMaxInFlightWrites(n int) Option {
+	return func(o *options) { o.inflight.writes = n }
+}
+
+// WithMaintenance makes the handler refuse mutating requests with 503
+// ServiceUnavailable and Retry-After while m is enabled; reads keep working.
+// With WithAuthenticator, m can also be toggled over HTTP by an Admin key:
+// PUT /?maintenance turns it on, DELETE /?maintenance off, GET reports it.
+func WithMaintenance(m MaintenanceSwitch) Option {
+	return func(o *options) { o.maintenance = m }
+}
+
+// WithNotFoundKeys makes a GET or HEAD of a missing key serve the bucket's
+// fallback object resolved through n (an SPA entry point with 200, or an
+// error page with 404) instead of NoSuchKey.
+func WithNotFoundKeys(n NotFoundResolver) Option {
+	return func(o *options) { o.notFound = n }
+}
+
+// WithReset enables the admin endpoint that wipes the store, for test and
+// development servers: DELETE /?all deletes every bucket with its objects and
+// uploads, GET /?all reports what it would delete. Like ?maintenance it also
+// needs WithAuthenticator and an Admin key. Never enable it in production.
+func WithReset() Option {
+	return func(o *options) { o.reset = true }
+}
+
+// WithGzipStatic serves precompressed objects like nginx's gzip_static: a GET
+// or HEAD of key from a client accepting gzip is answered with the object
+// key.gz, if it exists, under Content-Encoding: gzip and key's Content-Type.
+// Responses for keys not ending in .gz then carry Vary: Accept-Encoding.
+func WithGzipStatic() Option {
+	return func(o *options) { o.gzipStatic = true }
+}
+
+// WithListingCompression gzip-compresses listing responses (ListBuckets,
+// ListObjects V1/V2, ListObjectVersions, ListMultipartUploads, ListParts) for
+// clients sending Accept-Encoding: gzip, as they are streamed; large listings
+// shrink by an order of magnitude. Object bodies are never compressed. Off by
+// default so responses match S3 byte for byte; when on, listing responses carry
+// Vary: Accept-Encoding.
+func WithListingCompression() Option {
+	return func(o *options) { o.listingGzip = true }
+}
+
+// WithNoOverwriteRename makes a write to an existing key store the new object
+// under a derived key instead of replacing it, the way a file manager names a
+// copy: "report.pdf" becomes "report (1).pdf", then "report (2).pdf". It
+// covers every write: PUT, CopyObject, POST uploads, URL ingest and
+// CompleteMultipartUpload. The key used is returned, URL-encoded, in the
+// X-Fs-Key response header. Writes that carry If-Match or If-None-Match keep
+// their S3 semantics. For ingestion pipelines that must never overwrite.
+func WithNoOverwriteRename() Option {
+	return func(o *options) { o.noOverwriteRename = true }
+}
+
+// WithTracer wraps every request in a span started through t and named after
+// its S3 operation ("GetObject", "PutObject", "ListObjectsV2", ...). The span
+// covers the whole request, authentication and throttling included, and its
+// context is the one the request is served with. Without it no spans are
+// started.
+func WithTracer(t Tracer) Option {
+	return func(o *options) { o.tracer = t }
+}
+
+// WithURLIngest enables the extension PUT that stores an object fetched by
+// the server: a PUT of a key with an x-fs-source-url header and no body
+// ingests that URL through an ingest.Ingester configured by opts (host
+// allow/deny lists, size limit). Without it such PUTs get NotImplemented.
+func WithURLIngest(opts ...ingest.Option) Option {
+	return func(o *options) {
+		o.ingestEnabled = true
+		o.ingest = append(o.ingest, opts...)
+	}
+}
+
+// WithResumableDownloads enables GET /{bucket}/{key}?download-id, which
+// resumes a download the client lost from the byte count it reports having
+// received. A session not resumed within ttl is forgotten.
+func WithResumableDownloads(ttl time.Duration) Option {
+	return func(o *options) { o.downloadTTL = ttl }
+}
+
+// WithErrorVerbosity sets how much of an internal error reaches clients:
+// ErrorsPublic (the default) sends only the S3 code and its generic message,
+// ErrorsDebug appends the error itself, file paths included.
+func WithErrorVerbosity(v ErrorVerbosity) Option {
+	return func(o *options) { o.errorVerbosity = v }
+}
+
+// WithDefaultContentType sets the Content-Type GET and HEAD report for objects
+// stored without one, in place of the S3 default application/octet-stream
+// (which browsers download rather than display). A type given on upload is
+// never overridden, and since the fallback is applied when serving, changing
+// it affects objects already stored. An empty contentType is ignored.
+func WithDefaultContentType(contentType string) Option {
+	return func(o *options) {
+		if contentType != "" {
+			o.defaultContentType = contentType
+		}
+	}
+}
+
+// WithRegion sets the region the server reports as its own (default
+// us-east-1): GetBucketLocation answers it for buckets created without a
+// LocationConstraint. It does not change how requests are signed.
+func WithRegion(region string) Option {
+	return func(o *options) { o.region = region }
+}
+
+// WithStrictRegion makes CreateBucket refuse, with
+// IllegalLocationConstraintException, a LocationConstraint other than the
+// server's region, as a regional S3 endpoint does. A request without one
+// asks for us-east-1. By default any constraint is accepted and recorded.
+func WithStrictRegion() Option {
+	return func(o *options) { o.strictRegion = true }
+}
+
+// WithRateLimit throttles each client IP to perIP requests per second with the
+// given burst; excess requests get 503 SlowDown with a Retry-After header. The
+// client IP is the TCP peer unless it is a trusted proxy (WithTrustedProxies).
+func WithRateLimit(perIP rate.Limit, burst int) Option {
+	return func(o *options) {
+		if o.rateLimit == nil {
+			o.rateLimit = &rateLimit{}
+		}
+
+		o.rateLimit.perIP, o.rateLimit.burst = perIP, burst
+	}
+}
+
+// WithTrustedProxies lists the proxy networks whose X-Forwarded-For header is
+// believed when attributing requests to a client IP for rate limiting.
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return func(o *options) {
+		if o.rateLimit == nil {
+			o.rateLimit = &rateLimit{perIP: rate.Inf}
+		}
+
+		o.rateLimit.trusted = append(o.rateLimit.trusted, prefixes...)
+	}
+}
+
 // New returns the S3-compatible http.Handler for a storage service. Every
 // response carries an x-amz-request-id header; request routing is delegated to
 // route. Options enable authentication and CORS.
 //
-// Middleware order (outermost first): request-id → CORS → auth → router, so
-// error responses carry a request id, CORS preflight is answered before auth,
-// and only authenticated (or public-read) requests reach the router.
+// Middleware order (outermost first): request-id → error detail → tracing →
+// in-flight → path validation → rate limit → CORS → auth → authorizer →
+// router, so error responses carry a request id (and, with ErrorsDebug, the
+// cause), a span covers everything the request goes through, requests over
+// the in-flight limits are shed before their path is even looked at, every
+// later stage sees a path that follows the routing spec (see path.go),
+// throttling applies before any other work, CORS preflight is answered before
+// auth, and only authenticated (or public-read) requests that the authorizer
+// allows reach the router. Error detail, tracing, in-flight limits, rate
+// limits, CORS, auth and the authorizer are each present only when their
+// option is set.
 func New(s fs.Storage, opts ...Option) http.Handler {
-	var o options
+	o := options{
+		owner:              Owner{ID: DefaultOwnerID, DisplayName: DefaultOwnerDisplayName},
+		maxListKeys:        defaultMaxKeys,
+		defaultContentType: "application/octet-stream",
+		region:             defaultRegion,
+	}
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	h := handler{service: s}
+	h := handler{
+		service:            s,
+		owner:              o.owner,
+		maxListKeys:        o.maxListKeys,
+		normalizePrefix:    o.normalizePrefix,
+		events:             o.events,
+		uploads:            newSlots(o.maxUploads),
+		maintenance:        o.maintenance,
+		authenticated:      o.authenticator != nil,
+		authenticator:      o.authenticator,
+		authorizer:         o.authorizer,
+		notFound:           o.notFound,
+		reset:              o.reset,
+		gzipStatic:         o.gzipStatic,
+		listingGzip:        o.listingGzip,
+		noOverwriteRename:  o.noOverwriteRename,
+		defaultContentType: o.defaultContentType,
+		region:             o.region,
+		strictRegion:       o.strictRegion,
+	}
+
+	if o.authenticator != nil {
+		h.formVerifier = sigv4.NewVerifier(o.authenticator.Secret)
+	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", h.route)
+	if o.ingestEnabled {
+		h.ingest = ingest.New(s, o.ingest...)
+	}
+
+	if o.downloadTTL > 0 {
+		h.downloads = newDownloadSessions(o.downloadTTL)
+	}
+
+	// Route directly rather than through http.ServeMux, which cleans paths
+	// and would redirect keys such as "a//b" to a different key.
+	var inner http.Handler = http.HandlerFunc(h.route)
+	if o.authorizer != nil {
+		inner = authorizeMiddleware(o.authorizer, inner)
+	}
 
-	var inner http.Handler = mux
 	if o.authenticator != nil {
 		inner = authMiddleware(o.authenticator, s, inner)
 	}
@@ -64,6 +401,23 @@ func New(s fs.Storage, opts ...Option) http.Handler {
 		inner = corsMiddleware(o.cors, inner)
 	}
 
+	if o.rateLimit != nil && o.rateLimit.perIP != rate.Inf {
+		inner = rateLimitMiddleware(newIPLimiters(*o.rateLimit, maxRateLimitedClients), inner)
+	}
+
+	inner = withValidPath(inner)
+	if o.inflight != (inflightLimits{}) {
+		inner = inflightMiddleware(o.inflight, inner)
+	}
+
+	if o.tracer != nil {
+		inner = withTracing(o.tracer, inner)
+	}
+
+	if o.errorVerbosity == ErrorsDebug {
+		inner = withErrorDetail(inner)
+	}
+
 	return withRequestID(inner)
 }
 
@@ -99,21 +453,39 @@ func (h *handler) route(w http.ResponseWriter, r *http.Request) {
 		zap.String("query", r.URL.RawQuery),
 	)
 
-	path := strings.TrimPrefix(r.URL.Path, "/")
+	if h.refuseInMaintenance(w, r) {
+		return
+	}
 
-	// Root path: only ListBuckets.
-	if path == "" {
+	switch adminSubresource(r) {
+	case adminMaintenance:
+		h.Maintenance(w, r)
+		return
+	case adminAll:
+		h.Reset(w, r)
+		return
+	case adminObjects:
+		h.ListAllObjects(w, r)
+		return
+	}
+
+	_, _, kind, _ := parsePath(r.URL.EscapedPath())
+
+	// Root path: only ListBuckets. Anything else must not fall through to the
+	// bucket handlers with an empty bucket name.
+	if kind == pathService {
 		if r.Method == http.MethodGet {
 			h.ListBuckets(w, r)
 			return
 		}
 
+		w.Header().Set("Allow", http.MethodGet)
 		s3err.WriteAPI(w, r, s3err.MethodNotAllowed)
 
 		return
 	}
 
-	if _, key, _ := strings.Cut(path, "/"); key == "" {
+	if kind == pathBucket {
 		h.routeBucket(w, r)
 		return
 	}
@@ -134,6 +506,10 @@ func (h *handler) routeBucket(w http.ResponseWriter, r *http.Request) {
 			h.ListObjectVersions(w, r)
 		case q.Has("uploads"):
 			h.ListMultipartUploads(w, r)
+		case q.Has("manifest"):
+			h.GetBucketManifest(w, r)
+		case q.Has(countParam):
+			h.CountObjects(w, r)
 		case hasUnsupportedBucketSubresource(q):
 			s3err.WriteAPI(w, r, s3err.NotImplemented)
 		case q.Get("list-type") == "2":
@@ -151,6 +527,15 @@ func (h *handler) routeBucket(w http.ResponseWriter, r *http.Request) {
 	case http.MethodHead:
 		h.HeadBucket(w, r)
 	case http.MethodDelete:
+		switch {
+		case q.Has(patternParam):
+			h.DeleteObjectsByPattern(w, r)
+			return
+		case q.Has(forceParam):
+			h.DeleteBucketForce(w, r)
+			return
+		}
+
 		if hasUnsupportedBucketSubresource(q) {
 			s3err.WriteAPI(w, r, s3err.NotImplemented)
 			return
@@ -158,7 +543,7 @@ func (h *handler) routeBucket(w http.ResponseWriter, r *http.Request) {
 
 		h.DeleteBucket(w, r)
 	case http.MethodPost:
-		// POST to a bucket initiates DeleteObjects (?delete).
+		// POST to a bucket is DeleteObjects (?delete) or a form upload.
 		h.HandleBucketPost(w, r)
 	default:
 		s3err.WriteAPI(w, r, s3err.MethodNotAllowed)
@@ -169,6 +554,11 @@ func (h *handler) routeBucket(w http.ResponseWriter, r *http.Request) {
 func (h *handler) routeObject(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && !versionIDSupported(q) {
+		renderAPIError(r.Context(), w, r, s3err.InvalidArgument, errors.New("invalid version id specified"))
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		switch {
@@ -176,16 +566,24 @@ func (h *handler) routeObject(w http.ResponseWriter, r *http.Request) {
 			h.ListParts(w, r)
 		case q.Has("tagging"):
 			h.GetObjectTagging(w, r)
+		case q.Has("legal-hold"):
+			h.GetObjectLegalHold(w, r)
+		case q.Has("meta"):
+			h.GetObjectMeta(w, r)
+		case q.Has(downloadIDParam):
+			h.ResumableDownload(w, r)
 		default:
 			h.GetObject(w, r)
 		}
 	case http.MethodPut:
-		if q.Has("tagging") {
+		switch {
+		case q.Has("tagging"):
 			h.PutObjectTagging(w, r)
-			return
+		case q.Has("legal-hold"):
+			h.PutObjectLegalHold(w, r)
+		default:
+			h.PutObject(w, r)
 		}
-
-		h.PutObject(w, r)
 	case http.MethodHead:
 		h.HeadObject(w, r)
 	case http.MethodDelete:
@@ -30,7 +30,9 @@ func baseMock() *mock.StorageMock {
 func NewClient(t testing.TB, srv *TestServer) *minio.Client {
 	t.Helper()
 
-	client, err := minio.New(srv.Endpoint, &minio.Options{})
+	// A known region spares each call a GetBucketLocation probe, which mocks
+	// would otherwise have to answer.
+	client, err := minio.New(srv.Endpoint, &minio.Options{Region: "us-east-1"})
 	require.NoError(t, err)
 
 	return client
@@ -83,3 +85,35 @@ func newTestRequest(t testing.TB, method, path string) *http.Request {
 func newTestResponseRecorder() *httptest.ResponseRecorder {
 	return httptest.NewRecorder()
 }
+
+func TestHandler_ServiceRootRejectsNonGet(t *testing.T) {
+	t.Parallel()
+
+	svc := baseMock()
+	h := handler.New(svc)
+
+	// S3 serves only ListBuckets at the root; nothing may reach the bucket
+	// handlers with an empty bucket name.
+	for _, method := range []string{http.MethodPut, http.MethodDelete, http.MethodPost, http.MethodHead, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/", http.NoBody))
+
+			require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+			require.Equal(t, http.MethodGet, rec.Header().Get("Allow"))
+
+			if method != http.MethodHead {
+				require.Contains(t, rec.Body.String(), "<Code>MethodNotAllowed</Code>")
+			}
+		})
+	}
+
+	require.Empty(t, svc.CreateBucketCalls())
+	require.Empty(t, svc.DeleteBucketCalls())
+	require.Empty(t, svc.PutObjectCalls())
+
+	// GET / still lists buckets.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -2,15 +2,13 @@ package handler
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/go-faster/fs"
 )
 
 func (h *handler) HeadBucket(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, _, _ := strings.Cut(path, "/")
+	bucket, _ := splitPath(r)
 
 	exists, err := h.service.BucketExists(ctx, bucket)
 	if err != nil {
@@ -2,21 +2,34 @@ package handler
 
 import (
 	"net/http"
-	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
 )
 
 func (h *handler) HeadObject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
 
 	resp, err := h.service.GetObject(ctx, bucket, key)
+
+	resp, err = h.preferGzip(ctx, w, r, bucket, key, resp, err)
 	if err != nil {
+		if errors.Is(err, fs.ErrObjectNotFound) && h.serveNotFoundKey(ctx, w, r, bucket, key) {
+			return
+		}
+
 		renderError(ctx, w, r, err)
 		return
 	}
 
-	// serveObject is HEAD-safe: it sets headers (Content-Type, ETag, Content-Length,
-	// Last-Modified) and honors conditional requests without writing a body.
-	serveObject(w, r, key, resp)
+	// serveObject is HEAD-safe: it sets the same headers as GET via
+	// setObjectHeaders and honors conditional requests without writing a body.
+	serveObject(w, r, key, resp, h.defaultContentType)
 }
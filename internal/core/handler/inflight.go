@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// inflightRetryAfter is the Retry-After hint, in seconds, sent when a request
+// is shed for want of an in-flight slot.
+const inflightRetryAfter = "1"
+
+// inflightLimits are the in-flight request caps set by WithMaxInFlight and
+// its read and write variants; a zero field means no limit of that kind.
+type inflightLimits struct {
+	total, reads, writes int
+}
+
+// inflightMiddleware sheds requests beyond the in-flight limits with 503
+// SlowDown and Retry-After. A request holds one slot of the total and one of
+// its kind (reads: GET, HEAD and OPTIONS; writes: everything else) until it
+// is served; it never waits for one, so a burst costs the server nothing but
+// the refusals.
+func inflightMiddleware(l inflightLimits, next http.Handler) http.Handler {
+	total := newSlots(l.total)
+	reads := newSlots(l.reads)
+	writes := newSlots(l.writes)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind := reads
+		if isMutating(r) {
+			kind = writes
+		}
+
+		if !tryAcquire(total) {
+			shed(w, r)
+			return
+		}
+		defer release(total)
+
+		if !tryAcquire(kind) {
+			shed(w, r)
+			return
+		}
+		defer release(kind)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tryAcquire takes a slot of s; a nil s is unlimited.
+func tryAcquire(s *semaphore.Weighted) bool {
+	return s == nil || s.TryAcquire(1)
+}
+
+func release(s *semaphore.Weighted) {
+	if s != nil {
+		s.Release(1)
+	}
+}
+
+func shed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", inflightRetryAfter)
+	s3err.WriteAPI(w, r, s3err.SlowDown)
+}
@@ -0,0 +1,108 @@
+package handler_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagefs"
+)
+
+// holdUploads starts n PUTs whose bodies are still arriving, so each holds its
+// in-flight slot until the returned func finishes them.
+func holdUploads(t *testing.T, h http.Handler, n int) (finish func()) {
+	t.Helper()
+
+	var (
+		wg      sync.WaitGroup
+		writers []*io.PipeWriter
+	)
+
+	for i := range n {
+		pr, pw := io.Pipe()
+		writers = append(writers, pw)
+
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/bucket-a/slow-%d", i), pr)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+
+		// The write returns once the backend is reading the body.
+		_, err := pw.Write([]byte("x"))
+		require.NoError(t, err)
+	}
+
+	return func() {
+		for _, pw := range writers {
+			require.NoError(t, pw.Close())
+		}
+
+		wg.Wait()
+	}
+}
+
+func requireShed(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>SlowDown</Code>")
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestMaxInFlight(t *testing.T) {
+	newHandler := func(t *testing.T, opts ...handler.Option) http.Handler {
+		// storagefs streams bodies without holding a lock, so the uploads
+		// really run side by side.
+		store, err := storagefs.New(t.TempDir())
+		require.NoError(t, err)
+
+		h := handler.New(service.New(store), opts...)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/existing", "x", nil).Code)
+
+		return h
+	}
+
+	t.Run("Total", func(t *testing.T) {
+		h := newHandler(t, handler.WithMaxInFlight(2))
+
+		finish := holdUploads(t, h, 2)
+
+		// Any request beyond the cap is shed, reads included.
+		requireShed(t, do(t, h, http.MethodGet, "/bucket-a/existing", "", nil))
+		requireShed(t, do(t, h, http.MethodPut, "/bucket-a/one-too-many", "x", nil))
+
+		finish()
+
+		// Finished requests give their slots back.
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodGet, "/bucket-a/existing", "", nil).Code)
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		h := newHandler(t, handler.WithMaxInFlight(10), handler.WithMaxInFlightWrites(2))
+
+		finish := holdUploads(t, h, 2)
+
+		requireShed(t, do(t, h, http.MethodPut, "/bucket-a/one-too-many", "x", nil))
+		requireShed(t, do(t, h, http.MethodDelete, "/bucket-a/existing", "", nil))
+
+		// Reads have slots of their own.
+		rec := do(t, h, http.MethodGet, "/bucket-a/existing", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "x", rec.Body.String())
+
+		finish()
+
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/one-too-many", "x", nil).Code)
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/xml"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// ListAllObjectsResult is the XML document served by the ?objects admin
+// endpoint: the objects under Prefix in every bucket that has any.
+type ListAllObjectsResult struct {
+	XMLName xml.Name               `xml:"ListAllObjectsResult"`
+	Prefix  string                 `xml:"Prefix"`
+	Buckets []ListAllObjectsBucket `xml:"Bucket"`
+}
+
+// ListAllObjectsBucket is one bucket of a ListAllObjectsResult.
+type ListAllObjectsBucket struct {
+	Name     string       `xml:"Name"`
+	Contents []ObjectInfo `xml:"Contents"`
+}
+
+// ListAllObjects serves the GET /?objects&prefix=<prefix> admin endpoint: the
+// objects under prefix across all buckets (fs.ListAllObjects), buckets sorted
+// by name. It needs an authenticator (the caller must hold an Admin grant on
+// "*").
+func (h *handler) ListAllObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !h.authenticated {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, errors.New("admin requests need authentication"))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		s3err.WriteAPI(w, r, s3err.MethodNotAllowed)
+
+		return
+	}
+
+	prefix := h.listPrefix(r.URL.Query())
+
+	all, err := fs.ListAllObjects(ctx, h.service, prefix)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	result := ListAllObjectsResult{Prefix: prefix}
+
+	for _, name := range slices.Sorted(maps.Keys(all)) {
+		b := ListAllObjectsBucket{Name: name}
+		for _, obj := range all[name] {
+			b.Contents = append(b.Contents, ObjectInfo{
+				Key:          obj.Key,
+				LastModified: obj.LastModified,
+				ETag:         quoteETag(obj.ETag),
+				Size:         obj.Size,
+			})
+		}
+
+		result.Buckets = append(result.Buckets, b)
+	}
+
+	writeXML(ctx, w, r, result)
+}
@@ -13,8 +13,27 @@ type ObjectInfo struct {
 	ETag         string    `xml:"ETag,omitempty"`
 	Size         int64     `xml:"Size"`
 	StorageClass string    `xml:"StorageClass,omitempty"`
+	// Owner is always set in V1 listings and only with fetch-owner=true in V2.
+	Owner *Owner `xml:"Owner,omitempty"`
+	// ContentType and UserMetadata are only set with fetch-metadata=true.
+	ContentType  string        `xml:"ContentType,omitempty"`
+	UserMetadata *UserMetadata `xml:"UserMetadata,omitempty"`
 }
 
+// Owner is the XML representation of a resource owner.
+type Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+// Default owner identity reported when WithOwner is not set. The ID has the
+// shape of an S3 canonical user ID (64 hex characters), which some clients
+// validate.
+const (
+	DefaultOwnerID          = "75aa57f09aa0c8caeab4f8c24e99d10f8e7faeebf76c078efc7c6caef54b4b3a"
+	DefaultOwnerDisplayName = "fs"
+)
+
 // CommonPrefix is a grouped key prefix produced by delimiter-based listing.
 type CommonPrefix struct {
 	Prefix string `xml:"Prefix"`
@@ -53,13 +72,18 @@ type BucketsWrapper struct {
 
 // Bucket represents an S3 bucket.
 
-// ListAllMyBucketsResult is the XML response for listing buckets.
+// ListAllMyBucketsResult is the XML response for listing buckets. S3 always
+// includes the Owner, and some clients refuse a result without one.
 type ListAllMyBucketsResult struct {
 	XMLName xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+	Owner   Owner          `xml:"Owner"`
 	Buckets BucketsWrapper `xml:"Buckets"`
 }
 
 func (h *handler) ListBuckets(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
 
 	buckets, err := h.service.ListBuckets(ctx)
@@ -70,10 +94,11 @@ func (h *handler) ListBuckets(w http.ResponseWriter, r *http.Request) {
 
 	bucketInfos := make([]BucketInfo, len(buckets))
 	for i, bucket := range buckets {
-		bucketInfos[i] = BucketInfo(bucket)
+		bucketInfos[i] = BucketInfo{Name: bucket.Name, CreationDate: Timestamp(bucket.CreationDate)}
 	}
 
 	response := ListAllMyBucketsResult{
+		Owner: h.owner,
 		Buckets: BucketsWrapper{
 			Buckets: bucketInfos,
 		},
@@ -2,11 +2,15 @@ package handler_test
 
 import (
 	"context"
+	"encoding/xml"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/handler"
 	"github.com/go-faster/fs/internal/mock"
 )
 
@@ -28,6 +32,42 @@ func TestHandler_ListBuckets(t *testing.T) {
 	require.Equal(t, "bucket2", buckets[1].Name)
 }
 
+func TestHandler_ListBuckets_Owner(t *testing.T) {
+	created := time.Date(2024, 3, 9, 10, 4, 5, 120_000_000, time.FixedZone("CET", 3600))
+	svc := &mock.StorageMock{
+		ListBucketsFunc: func(ctx context.Context) ([]fs.Bucket, error) {
+			return []fs.Bucket{{Name: "bucket1", CreationDate: created}}, nil
+		},
+	}
+
+	for _, tt := range []struct {
+		name  string
+		opts  []handler.Option
+		owner handler.Owner
+	}{
+		{"Default", nil, handler.Owner{ID: handler.DefaultOwnerID, DisplayName: handler.DefaultOwnerDisplayName}},
+		{"Configured", []handler.Option{handler.WithOwner("abc123", "alice")}, handler.Owner{ID: "abc123", DisplayName: "alice"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, handler.New(svc, tt.opts...), http.MethodGet, "/", "", nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var result struct {
+				Owner   handler.Owner `xml:"Owner"`
+				Buckets []struct {
+					Name         string `xml:"Name"`
+					CreationDate string `xml:"CreationDate"`
+				} `xml:"Buckets>Bucket"`
+			}
+			require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+
+			require.Equal(t, tt.owner, result.Owner)
+			require.Len(t, result.Buckets, 1)
+			require.Equal(t, "2024-03-09T09:04:05.120Z", result.Buckets[0].CreationDate)
+		})
+	}
+}
+
 func BenchmarkHandler_ListBuckets(b *testing.B) {
 	b.ReportAllocs()
 
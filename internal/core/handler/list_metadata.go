@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/go-faster/fs"
+)
+
+// fetchMetadataParam is the extension listing parameter that inlines each
+// object's Content-Type and x-amz-meta-* pairs into its Contents entry, so a
+// file browser need not HEAD every object it lists.
+const fetchMetadataParam = "fetch-metadata"
+
+// maxMetadataListKeys caps the page of a fetch-metadata listing: each listed
+// object costs an open of the object (and, for encrypted objects, of its
+// decryption) on top of the walk.
+const maxMetadataListKeys = defaultMaxKeys
+
+// UserMetadata is the XML representation of an object's x-amz-meta-* pairs
+// in a fetch-metadata listing, sorted by name.
+type UserMetadata struct {
+	Items []MetadataItem `xml:"Items"`
+}
+
+// MetadataItem is one x-amz-meta-* pair, named without the prefix.
+type MetadataItem struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+func newUserMetadata(m map[string]string) *UserMetadata {
+	if len(m) == 0 {
+		return nil
+	}
+
+	u := &UserMetadata{Items: make([]MetadataItem, 0, len(m))}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		u.Items = append(u.Items, MetadataItem{Key: k, Value: m[k]})
+	}
+
+	return u
+}
+
+// withListMetadata makes p load the metadata of every object it lists,
+// clamping its page to maxMetadataListKeys. An object whose metadata cannot
+// be read (deleted since the walk, or SSE-C encrypted) is listed without it.
+func (h *handler) withListMetadata(ctx context.Context, p *listQuery) {
+	p.maxKeys = min(p.maxKeys, maxMetadataListKeys)
+	p.metadata = func(key string) (fs.ObjectMetadata, bool) {
+		resp, err := h.service.GetObject(ctx, p.bucket, key)
+		if err != nil {
+			return fs.ObjectMetadata{}, false
+		}
+
+		_ = resp.Reader.Close()
+
+		return resp.Metadata, true
+	}
+}
@@ -44,12 +44,14 @@ type UploadXML struct {
 // in-progress multipart uploads ordered by key then upload ID, with
 // prefix/delimiter grouping and key-marker/upload-id-marker pagination.
 func (h *handler) ListMultipartUploads(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, _, _ := strings.Cut(path, "/")
+	bucket, _ := splitPath(r)
 
 	q := r.URL.Query()
-	prefix := q.Get("prefix")
+	prefix := h.listPrefix(q)
 	delimiter := q.Get("delimiter")
 	keyMarker := q.Get("key-marker")
 	uploadIDMarker := q.Get("upload-id-marker")
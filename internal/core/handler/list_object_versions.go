@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -50,17 +49,29 @@ type ListVersionsResult struct {
 // buckets.
 const unversionedVersionID = "null"
 
+// versionIDSupported reports whether a GET/HEAD versionId selector can be
+// served: only the "null" version exists in an unversioned store, and it is
+// the current object. Any other id names a version that was never kept, and
+// ignoring the selector would serve the wrong data.
+func versionIDSupported(q url.Values) bool {
+	values, ok := q["versionId"]
+
+	return !ok || (len(values) == 1 && values[0] == unversionedVersionID)
+}
+
 // ListObjectVersions implements GET /{bucket}?versions. On an unversioned store
 // it lists current objects as single "null" versions. It exists chiefly so S3
 // clients and tooling that enumerate objects for deletion via
 // list_object_versions (rather than list_objects) work correctly.
 func (h *handler) ListObjectVersions(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, _, _ := strings.Cut(path, "/")
+	bucket, _ := splitPath(r)
 
 	q := r.URL.Query()
-	prefix := q.Get("prefix")
+	prefix := h.listPrefix(q)
 	delimiter := q.Get("delimiter")
 	encodeURL := q.Get("encoding-type") == encodingTypeURL
 	keyMarker := q.Get("key-marker")
@@ -72,3 +72,39 @@ func TestListObjectVersions_BucketNotFound(t *testing.T) {
 
 	require.Equal(t, http.StatusNotFound, rec.Code)
 }
+
+func TestGetObject_VersionID(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/obj", "data", nil).Code)
+
+	t.Run("NullIsCurrent", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/"+bucket+"/obj?versionId=null", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "data", rec.Body.String())
+
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/"+bucket+"/obj?versionId=null", "", nil).Code)
+	})
+
+	t.Run("UnknownRejected", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/"+bucket+"/obj?versionId=3HL4kqtJlcpXroDTDmJ", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+
+		rec = do(t, h, http.MethodHead, "/"+bucket+"/obj?versionId=3HL4kqtJlcpXroDTDmJ", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("DeletedHasNoMarker", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do(t, h, http.MethodDelete, "/"+bucket+"/obj", "", nil).Code)
+
+		// Without versioning a delete leaves no marker: the key is simply gone.
+		rec := do(t, h, http.MethodGet, "/"+bucket+"/obj", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchKey", errorCode(t, rec.Body.String()))
+		require.Empty(t, rec.Header().Get("x-amz-delete-marker"))
+	})
+}
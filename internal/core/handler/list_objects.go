@@ -5,9 +5,12 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"net/http"
+	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-faster/errors"
 
@@ -31,12 +34,13 @@ type listEntry struct {
 }
 
 // listPage is the delimiter-and-pagination walk shared by ListObjects V1/V2.
+// entries is the window of the keyspace returned on this page; it is rendered
+// into Contents and CommonPrefixes while the response is written.
 type listPage struct {
-	contents       []ObjectInfo
-	commonPrefixes []CommonPrefix
-	count          int
-	truncated      bool
-	nextCursor     string
+	entries    []listEntry
+	count      int
+	truncated  bool
+	nextCursor string
 }
 
 // listQuery holds the parameters common to both listing versions.
@@ -46,6 +50,18 @@ type listQuery struct {
 	delimiter string
 	encodeURL bool
 	maxKeys   int
+	// owner, when set, is reported on every Contents entry.
+	owner *Owner
+	// modifiedSince, when set, keeps only objects modified after it.
+	modifiedSince time.Time
+	// contains, when set, keeps only keys containing it.
+	contains string
+	// order is the key order of the listing; cursors are exclusive bounds
+	// in that direction.
+	order fs.Order
+	// metadata, when set, loads each listed object's metadata
+	// (fetch-metadata=true).
+	metadata func(key string) (fs.ObjectMetadata, bool)
 }
 
 // maybeEncode URL-encodes s when encoding-type=url was requested.
@@ -58,10 +74,10 @@ func (p *listQuery) maybeEncode(s string) string {
 }
 
 // parseListQuery parses the shared listing parameters, rejecting invalid
-// max-keys and unknown encoding-type values.
-func parseListQuery(r *http.Request) (*listQuery, error) {
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, _, _ := strings.Cut(path, "/")
+// max-keys and unknown encoding-type values. max-keys is clamped to the
+// handler's maxListKeys; a non-positive limit leaves it unbounded.
+func (h *handler) parseListQuery(r *http.Request) (*listQuery, error) {
+	bucket, _ := splitPath(r)
 	q := r.URL.Query()
 
 	encodeURL, err := parseEncodingType(q)
@@ -69,7 +85,12 @@ func parseListQuery(r *http.Request) (*listQuery, error) {
 		return nil, err
 	}
 
+	limit := h.maxListKeys
+
 	maxKeys := defaultMaxKeys
+	if limit > 0 && limit < maxKeys {
+		maxKeys = limit
+	}
 
 	if v := q.Get("max-keys"); v != "" {
 		n, err := strconv.Atoi(v)
@@ -77,19 +98,93 @@ func parseListQuery(r *http.Request) (*listQuery, error) {
 			return nil, errors.Errorf("invalid max-keys %q", v)
 		}
 
-		// Values above 1000 are clamped, not rejected.
-		if n < maxKeys {
-			maxKeys = n
+		// Values above the limit are clamped, not rejected.
+		maxKeys = n
+		if limit > 0 && n > limit {
+			maxKeys = limit
 		}
 	}
 
-	return &listQuery{
-		bucket:    bucket,
-		prefix:    q.Get("prefix"),
-		delimiter: q.Get("delimiter"),
-		encodeURL: encodeURL,
-		maxKeys:   maxKeys,
-	}, nil
+	modifiedSince, err := parseModifiedSince(q.Get(modifiedSinceParam))
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := parseOrder(q.Get(orderParam))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &listQuery{
+		bucket:        bucket,
+		prefix:        h.listPrefix(q),
+		delimiter:     q.Get("delimiter"),
+		encodeURL:     encodeURL,
+		maxKeys:       maxKeys,
+		modifiedSince: modifiedSince,
+		contains:      q.Get(containsParam),
+		order:         order,
+	}
+
+	if q.Get(fetchMetadataParam) == "true" {
+		h.withListMetadata(r.Context(), p)
+	}
+
+	return p, nil
+}
+
+// containsParam is the extension listing parameter that keeps only keys
+// containing a substring anywhere, not just at the start.
+const containsParam = "contains"
+
+// orderParam is the extension listing parameter that selects the key order:
+// "asc" (the default, as S3) or "desc".
+const orderParam = "order"
+
+// parseOrder parses the orderParam value. An absent parameter is ascending.
+func parseOrder(v string) (fs.Order, error) {
+	switch v {
+	case "", fs.Ascending.String():
+		return fs.Ascending, nil
+	case fs.Descending.String():
+		return fs.Descending, nil
+	default:
+		return fs.Ascending, errors.Errorf("invalid %s %q", orderParam, v)
+	}
+}
+
+// modifiedSinceParam is the extension listing parameter that keeps only
+// objects modified after a time, for incremental sync.
+const modifiedSinceParam = "modified-since"
+
+// parseModifiedSince parses the modifiedSinceParam value, an RFC 3339 time or
+// an HTTP date. An absent parameter yields the zero time (no filter).
+func parseModifiedSince(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s %q", modifiedSinceParam, v)
+	}
+
+	return t, nil
+}
+
+// listPrefix returns the prefix parameter of a listing, minus one leading
+// slash when prefix normalization is enabled.
+func (h *handler) listPrefix(q url.Values) string {
+	prefix := q.Get("prefix")
+	if h.normalizePrefix {
+		prefix = strings.TrimPrefix(prefix, "/")
+	}
+
+	return prefix
 }
 
 // parseEncodingType validates the encoding-type parameter: absent (false) or
@@ -108,15 +203,27 @@ func parseEncodingType(q map[string][]string) (bool, error) {
 }
 
 // walkList pages through the delimiter-folded keyspace after the exclusive
-// cursor, encoding output fields as requested. Common prefixes count toward
-// maxKeys, exactly like on S3.
+// cursor, encoding output fields as requested. Objects not modified after
+// modifiedSince or whose key lacks contains (when set) are left out before
+// folding, so common prefixes only name prefixes with a match under them.
+// Common prefixes count toward maxKeys, exactly like on S3. In descending
+// order the keyspace is walked from the end and the cursor bounds it from
+// above: the page holds the entries sorting before it.
 func (h *handler) walkList(ctx context.Context, p *listQuery, cursor string) (*listPage, error) {
-	objects, err := h.service.ListObjects(ctx, p.bucket, p.prefix)
+	objects, err := fs.ListObjectsModifiedSince(ctx, h.service, p.bucket, p.prefix, p.modifiedSince)
 	if err != nil {
 		return nil, err
 	}
 
+	if p.contains != "" {
+		objects = slices.DeleteFunc(objects, func(o fs.Object) bool { return !strings.Contains(o.Key, p.contains) })
+	}
+
 	entries := buildListEntries(objects, p.prefix, p.delimiter)
+	if p.order == fs.Descending {
+		slices.Reverse(entries)
+	}
+
 	page := &listPage{}
 
 	// S3 answers max-keys=0 with an empty, non-truncated result.
@@ -124,44 +231,42 @@ func (h *handler) walkList(ctx context.Context, p *listQuery, cursor string) (*l
 		return page, nil
 	}
 
-	for _, e := range entries {
-		if cursor != "" && e.key <= cursor {
-			continue
-		}
+	start := 0
+	if cursor != "" {
+		start = sort.Search(len(entries), func(i int) bool {
+			if p.order == fs.Descending {
+				return entries[i].key < cursor
+			}
 
-		if page.count >= p.maxKeys {
-			page.truncated = true
-			break
-		}
+			return entries[i].key > cursor
+		})
+	}
 
-		if e.isPrefix {
-			page.commonPrefixes = append(page.commonPrefixes, CommonPrefix{Prefix: p.maybeEncode(e.key)})
-		} else {
-			page.contents = append(page.contents, ObjectInfo{
-				Key:          p.maybeEncode(e.obj.Key),
-				LastModified: e.obj.LastModified,
-				ETag:         quoteETag(e.obj.ETag),
-				Size:         e.obj.Size,
-			})
-		}
+	end := len(entries)
+	if end-start > p.maxKeys {
+		end = start + p.maxKeys
+		page.truncated = true
+	}
+
+	page.entries = entries[start:end]
+	page.count = len(page.entries)
 
-		page.nextCursor = e.key
-		page.count++
+	if page.count > 0 {
+		page.nextCursor = page.entries[page.count-1].key
 	}
 
 	return page, nil
 }
 
-// baseListResult fills the response fields shared by V1 and V2.
+// baseListResult fills the response fields shared by V1 and V2. Contents and
+// CommonPrefixes are left empty; writeListResult streams them from page.
 func baseListResult(p *listQuery, page *listPage) ListBucketResult {
 	resp := ListBucketResult{
-		Name:           p.bucket,
-		Prefix:         p.maybeEncode(p.prefix),
-		Delimiter:      p.maybeEncode(p.delimiter),
-		MaxKeys:        p.maxKeys,
-		IsTruncated:    page.truncated,
-		Contents:       page.contents,
-		CommonPrefixes: page.commonPrefixes,
+		Name:        p.bucket,
+		Prefix:      p.maybeEncode(p.prefix),
+		Delimiter:   p.maybeEncode(p.delimiter),
+		MaxKeys:     p.maxKeys,
+		IsTruncated: page.truncated,
 	}
 
 	if p.encodeURL {
@@ -173,14 +278,20 @@ func baseListResult(p *listQuery, page *listPage) ListBucketResult {
 
 // ListObjectsV1 handles GET on a bucket without list-type=2.
 func (h *handler) ListObjectsV1(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
 
-	p, err := parseListQuery(r)
+	p, err := h.parseListQuery(r)
 	if err != nil {
 		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
 		return
 	}
 
+	// V1 always reports the owner.
+	p.owner = &h.owner
+
 	marker := r.URL.Query().Get("marker")
 
 	page, err := h.walkList(ctx, p, marker)
@@ -198,14 +309,17 @@ func (h *handler) ListObjectsV1(w http.ResponseWriter, r *http.Request) {
 		resp.NextMarker = p.maybeEncode(page.nextCursor)
 	}
 
-	writeXML(ctx, w, r, resp)
+	writeListResult(ctx, w, r, p, &resp, page)
 }
 
 // ListObjectsV2 handles GET on a bucket with list-type=2.
 func (h *handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
 
-	p, err := parseListQuery(r)
+	p, err := h.parseListQuery(r)
 	if err != nil {
 		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
 		return
@@ -219,6 +333,11 @@ func (h *handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 		cursor = decodeContinuationToken(token)
 	}
 
+	// V2 omits the owner unless fetch-owner=true.
+	if q.Get("fetch-owner") == "true" {
+		p.owner = &h.owner
+	}
+
 	page, err := h.walkList(ctx, p, cursor)
 	if err != nil {
 		renderError(ctx, w, r, err)
@@ -234,7 +353,7 @@ func (h *handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 		resp.NextContinuationToken = encodeContinuationToken(page.nextCursor)
 	}
 
-	writeXML(ctx, w, r, resp)
+	writeListResult(ctx, w, r, p, &resp, page)
 }
 
 // buildListEntries folds objects into the ordered listing keyspace, rolling keys
@@ -2,12 +2,16 @@ package handler_test
 
 import (
 	"encoding/xml"
+	"fmt"
 	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
 )
 
 // listBucket issues a bucket GET with the given query and decodes the result.
@@ -158,6 +162,22 @@ func TestListObjectsV2_Semantics(t *testing.T) {
 		require.Equal(t, "b", result.StartAfter)
 	})
 
+	t.Run("StartAfterIsExclusive", func(t *testing.T) {
+		// A bound between stored keys starts at the next key; a bound equal
+		// to the last key leaves nothing.
+		result := listBucket(t, h, bucket, "?list-type=2&start-after=a0")
+		require.Len(t, result.Contents, 3)
+		require.Equal(t, "b", result.Contents[0].Key)
+
+		result = listBucket(t, h, bucket, "?list-type=2&start-after=dir%2Fx")
+		require.Len(t, result.Contents, 1)
+		require.Equal(t, "dir/y", result.Contents[0].Key)
+
+		result = listBucket(t, h, bucket, "?list-type=2&start-after=dir%2Fy")
+		require.Empty(t, result.Contents)
+		require.False(t, result.IsTruncated)
+	})
+
 	t.Run("ContinuationTokenFlow", func(t *testing.T) {
 		page := listBucket(t, h, bucket, "?list-type=2&max-keys=2")
 		require.True(t, page.IsTruncated)
@@ -215,3 +235,380 @@ func TestListObjects_CommonPrefixesCountTowardMaxKeys(t *testing.T) {
 	require.NotNil(t, page.KeyCount)
 	require.Equal(t, 2, *page.KeyCount)
 }
+
+func TestListObjects_WithMaxListKeys(t *testing.T) {
+	const (
+		bucket  = "bucket-a"
+		objects = 1200
+	)
+
+	h := handler.New(service.New(storagemem.New()), handler.WithMaxListKeys(5000))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	for i := range objects {
+		key := fmt.Sprintf("/%s/key-%05d", bucket, i)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, key, "x", nil).Code)
+	}
+
+	t.Run("DefaultPageUnchanged", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2")
+		require.Equal(t, 1000, result.MaxKeys)
+		require.Len(t, result.Contents, 1000)
+		require.True(t, result.IsTruncated)
+	})
+
+	t.Run("RaisedCap", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2&max-keys=100000")
+		require.Equal(t, 5000, result.MaxKeys)
+		require.Len(t, result.Contents, objects)
+		require.False(t, result.IsTruncated)
+		require.Equal(t, "key-01199", result.Contents[objects-1].Key)
+	})
+
+	t.Run("Unbounded", func(t *testing.T) {
+		h := handler.New(service.New(storagemem.New()), handler.WithMaxListKeys(0))
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/a", "x", nil).Code)
+
+		result := listBucket(t, h, bucket, "?max-keys=100000")
+		require.Equal(t, 100000, result.MaxKeys)
+		require.Len(t, result.Contents, 1)
+	})
+}
+
+func TestListObjects_PrefixNormalization(t *testing.T) {
+	const bucket = "bucket-a"
+
+	setup := func(t *testing.T, opts ...handler.Option) http.Handler {
+		t.Helper()
+
+		h := handler.New(service.New(storagemem.New()), opts...)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+		for _, key := range []string{"dir/a", "dir/b", "other"} {
+			require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", nil).Code)
+		}
+
+		return h
+	}
+
+	t.Run("OffByDefault", func(t *testing.T) {
+		result := listBucket(t, setup(t), bucket, "?list-type=2&prefix=%2Fdir%2F")
+		require.Equal(t, "/dir/", result.Prefix)
+		require.Empty(t, result.Contents)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		h := setup(t, handler.WithPrefixNormalization())
+
+		for _, query := range []string{"?list-type=2&prefix=%2Fdir%2F", "?prefix=%2Fdir%2F"} {
+			result := listBucket(t, h, bucket, query)
+			require.Equal(t, "dir/", result.Prefix)
+			require.Len(t, result.Contents, 2, query)
+			require.Equal(t, "dir/a", result.Contents[0].Key)
+		}
+
+		// Only one slash is trimmed.
+		result := listBucket(t, h, bucket, "?list-type=2&prefix=%2F%2Fdir%2F")
+		require.Empty(t, result.Contents)
+	})
+}
+
+func TestListObjects_IfNoneMatch(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/a", "x", nil).Code)
+
+	for _, query := range []string{"", "?list-type=2"} {
+		rec := do(t, h, http.MethodGet, "/"+bucket+query, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		// Unchanged: 304 with no body.
+		rec = do(t, h, http.MethodGet, "/"+bucket+query, "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, rec.Code, query)
+		require.Empty(t, rec.Body.String())
+		require.Equal(t, etag, rec.Header().Get("ETag"))
+
+		// A stale tag gets the full listing.
+		rec = do(t, h, http.MethodGet, "/"+bucket+query, "", map[string]string{"If-None-Match": `"stale"`})
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec := do(t, h, http.MethodGet, "/"+bucket, "", nil)
+	etag := rec.Header().Get("ETag")
+
+	// The same objects rendered differently are a different listing.
+	for _, query := range []string{"?list-type=2", "?encoding-type=url", "?list-type=2&fetch-owner=true"} {
+		rec = do(t, h, http.MethodGet, "/"+bucket+query, "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, rec.Code, query)
+		require.NotEqual(t, etag, rec.Header().Get("ETag"), query)
+	}
+
+	// Overwriting a key changes the listing even within the same second.
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/a", "y", nil).Code)
+
+	rec = do(t, h, http.MethodGet, "/"+bucket, "", map[string]string{"If-None-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestListObjects_ModifiedSince(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	for key, at := range map[string]string{
+		"a/old":    "2026-01-01T00:00:00Z",
+		"a/new-1":  "2026-01-03T00:00:00Z",
+		"a/new-2":  "2026-01-04T00:00:00Z",
+		"b/old":    "2026-01-01T00:00:00Z",
+		"c/new":    "2026-01-05T00:00:00Z",
+		"top-new":  "2026-01-05T00:00:00Z",
+		"top-same": "2026-01-02T00:00:00Z",
+	} {
+		rec := do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", map[string]string{"X-Fs-Last-Modified": at})
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	keys := func(result handler.ListBucketResult) []string {
+		var out []string
+		for _, o := range result.Contents {
+			out = append(out, o.Key)
+		}
+
+		return out
+	}
+
+	const since = "&modified-since=2026-01-02T00:00:00Z"
+
+	t.Run("Filter", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2"+since)
+		require.Equal(t, []string{"a/new-1", "a/new-2", "c/new", "top-new"}, keys(result))
+
+		// An HTTP date works too, and V1 filters the same way.
+		result = listBucket(t, h, bucket, "?modified-since=Fri,%2002%20Jan%202026%2000:00:00%20GMT")
+		require.Equal(t, []string{"a/new-1", "a/new-2", "c/new", "top-new"}, keys(result))
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2&prefix=a/"+since)
+		require.Equal(t, []string{"a/new-1", "a/new-2"}, keys(result))
+	})
+
+	t.Run("Delimiter", func(t *testing.T) {
+		// b/ has nothing new, so it is not a common prefix.
+		result := listBucket(t, h, bucket, "?list-type=2&delimiter=/"+since)
+		require.Equal(t, []string{"top-new"}, keys(result))
+		require.Len(t, result.CommonPrefixes, 2)
+		require.Equal(t, "a/", result.CommonPrefixes[0].Prefix)
+		require.Equal(t, "c/", result.CommonPrefixes[1].Prefix)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		var all []string
+
+		query := "?list-type=2&max-keys=3" + since
+		for {
+			result := listBucket(t, h, bucket, query)
+			all = append(all, keys(result)...)
+
+			if !result.IsTruncated {
+				break
+			}
+
+			query = "?list-type=2&max-keys=3&continuation-token=" + result.NextContinuationToken + since
+		}
+
+		require.Equal(t, []string{"a/new-1", "a/new-2", "c/new", "top-new"}, all)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/"+bucket+"?list-type=2&modified-since=yesterday", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	})
+}
+
+func TestListObjects_Contains(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	for _, key := range []string{
+		"2026/01/01/app.log",
+		"2026/01/01/app.json",
+		"2026/01/02/db.log",
+		"2026/02/01/app.log",
+		"archive/old.tar",
+		"top.log",
+	} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", nil).Code)
+	}
+
+	keys := func(result handler.ListBucketResult) []string {
+		var out []string
+		for _, o := range result.Contents {
+			out = append(out, o.Key)
+		}
+
+		return out
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		want := []string{"2026/01/01/app.log", "2026/01/02/db.log", "2026/02/01/app.log", "top.log"}
+
+		require.Equal(t, want, keys(listBucket(t, h, bucket, "?list-type=2&contains=.log")))
+		require.Equal(t, want, keys(listBucket(t, h, bucket, "?contains=.log")))
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2&prefix=2026/01/&contains=app")
+		require.Equal(t, []string{"2026/01/01/app.json", "2026/01/01/app.log"}, keys(result))
+	})
+
+	t.Run("Delimiter", func(t *testing.T) {
+		// archive/ holds no match, so it is not a common prefix.
+		result := listBucket(t, h, bucket, "?list-type=2&delimiter=/&contains=.log")
+		require.Equal(t, []string{"top.log"}, keys(result))
+		require.Len(t, result.CommonPrefixes, 1)
+		require.Equal(t, "2026/", result.CommonPrefixes[0].Prefix)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		var all []string
+
+		query := "?list-type=2&max-keys=1&contains=app"
+		for {
+			result := listBucket(t, h, bucket, query)
+			all = append(all, keys(result)...)
+
+			if !result.IsTruncated {
+				break
+			}
+
+			query = "?list-type=2&max-keys=1&contains=app&continuation-token=" + result.NextContinuationToken
+		}
+
+		require.Equal(t, []string{"2026/01/01/app.json", "2026/01/01/app.log", "2026/02/01/app.log"}, all)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		require.Empty(t, listBucket(t, h, bucket, "?list-type=2&contains=missing").Contents)
+	})
+}
+
+func TestListObjects_Order(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	ascending := []string{
+		"2026-01-01.log",
+		"2026-01-02.log",
+		"2026-02-01.log",
+		"2026-03-01.log",
+		"2026-03-02.log",
+	}
+	for _, key := range ascending {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/"+key, "x", nil).Code)
+	}
+
+	descending := slices.Clone(ascending)
+	slices.Reverse(descending)
+
+	keys := func(result handler.ListBucketResult) []string {
+		var out []string
+		for _, o := range result.Contents {
+			out = append(out, o.Key)
+		}
+
+		return out
+	}
+
+	// pageV2 collects a V2 listing two keys at a time, returning every page.
+	pageV2 := func(t *testing.T, query string) [][]string {
+		t.Helper()
+
+		var pages [][]string
+
+		next := query
+		for {
+			result := listBucket(t, h, bucket, "?list-type=2&max-keys=2"+next)
+			pages = append(pages, keys(result))
+
+			if !result.IsTruncated {
+				return pages
+			}
+
+			next = query + "&continuation-token=" + result.NextContinuationToken
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		require.Equal(t, ascending, keys(listBucket(t, h, bucket, "?list-type=2")))
+		require.Equal(t, ascending, keys(listBucket(t, h, bucket, "?list-type=2&order=asc")))
+	})
+
+	t.Run("Desc", func(t *testing.T) {
+		require.Equal(t, descending, keys(listBucket(t, h, bucket, "?list-type=2&order=desc")))
+		require.Equal(t, descending, keys(listBucket(t, h, bucket, "?order=desc")))
+	})
+
+	t.Run("PaginationAsc", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"2026-01-01.log", "2026-01-02.log"},
+			{"2026-02-01.log", "2026-03-01.log"},
+			{"2026-03-02.log"},
+		}, pageV2(t, "&order=asc"))
+	})
+
+	t.Run("PaginationDesc", func(t *testing.T) {
+		require.Equal(t, [][]string{
+			{"2026-03-02.log", "2026-03-01.log"},
+			{"2026-02-01.log", "2026-01-02.log"},
+			{"2026-01-01.log"},
+		}, pageV2(t, "&order=desc"))
+	})
+
+	t.Run("StartAfterDesc", func(t *testing.T) {
+		// The bound is exclusive in the listing's direction: keys before it.
+		result := listBucket(t, h, bucket, "?list-type=2&order=desc&start-after=2026-02-01.log")
+		require.Equal(t, []string{"2026-01-02.log", "2026-01-01.log"}, keys(result))
+
+		// A cursor that is not a key still splits the keyspace.
+		result = listBucket(t, h, bucket, "?list-type=2&order=desc&start-after=2026-02")
+		require.Equal(t, []string{"2026-01-02.log", "2026-01-01.log"}, keys(result))
+	})
+
+	t.Run("MarkerV1Desc", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?order=desc&max-keys=3")
+		require.True(t, result.IsTruncated)
+		require.Equal(t, descending[:3], keys(result))
+
+		result = listBucket(t, h, bucket, "?order=desc&max-keys=3&marker="+descending[2])
+		require.False(t, result.IsTruncated)
+		require.Equal(t, descending[3:], keys(result))
+	})
+
+	t.Run("DelimiterDesc", func(t *testing.T) {
+		result := listBucket(t, h, bucket, "?list-type=2&order=desc&prefix=2026-&delimiter=-")
+		require.Empty(t, result.Contents)
+		require.Len(t, result.CommonPrefixes, 3)
+		require.Equal(t, "2026-03-", result.CommonPrefixes[0].Prefix)
+		require.Equal(t, "2026-01-", result.CommonPrefixes[2].Prefix)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/"+bucket+"?list-type=2&order=newest", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	})
+}
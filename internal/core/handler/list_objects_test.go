@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,7 +14,10 @@ import (
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
 	"github.com/go-faster/fs/internal/mock"
+	"github.com/go-faster/fs/storagefs"
+	"github.com/go-faster/fs/storagemem"
 )
 
 func TestListObjects(t *testing.T) {
@@ -206,3 +210,131 @@ func TestListObjectsV2_Pagination(t *testing.T) {
 	require.Equal(t, []string{"k0", "k1", "k2", "k3", "k4"}, seen)
 	require.Equal(t, 3, pages) // 2 + 2 + 1
 }
+
+func TestListObjects_Owner(t *testing.T) {
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(t.Context(), "bucket"))
+
+	_, err := store.PutObject(t.Context(), &fs.PutObjectRequest{
+		Bucket: "bucket", Key: "k", Reader: strings.NewReader("x"), Size: 1,
+	})
+	require.NoError(t, err)
+
+	list := func(t *testing.T, h http.Handler, target string) handler.ListBucketResult {
+		t.Helper()
+
+		rec := do(t, h, http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var res handler.ListBucketResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.Contents, 1)
+
+		return res
+	}
+
+	h := handler.New(service.New(store), handler.WithOwner("owner-id", "Owner Name"))
+	want := &handler.Owner{ID: "owner-id", DisplayName: "Owner Name"}
+
+	t.Run("V1", func(t *testing.T) {
+		require.Equal(t, want, list(t, h, "/bucket").Contents[0].Owner)
+	})
+
+	t.Run("V2FetchOwner", func(t *testing.T) {
+		require.Equal(t, want, list(t, h, "/bucket?list-type=2&fetch-owner=true").Contents[0].Owner)
+	})
+
+	t.Run("V2WithoutFetchOwner", func(t *testing.T) {
+		require.Nil(t, list(t, h, "/bucket?list-type=2").Contents[0].Owner)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		got := list(t, handler.New(service.New(store)), "/bucket")
+		require.Equal(t, handler.DefaultOwnerID, got.Contents[0].Owner.ID)
+	})
+}
+
+func TestListObjects_MissingVsEmptyBucket(t *testing.T) {
+	for name, newStore := range map[string]func(t *testing.T) fs.Storage{
+		"Memory": func(*testing.T) fs.Storage { return storagemem.New() },
+		"Filesystem": func(t *testing.T) fs.Storage {
+			s, err := storagefs.New(t.TempDir())
+			require.NoError(t, err)
+
+			return s
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := handler.New(service.New(newStore(t)))
+			require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+			for _, query := range []string{"", "?list-type=2", "?list-type=2&prefix=dir/"} {
+				rec := do(t, h, http.MethodGet, "/bucket-a"+query, "", nil)
+				require.Equal(t, http.StatusOK, rec.Code, query)
+
+				var result handler.ListBucketResult
+				require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+				require.Equal(t, "bucket-a", result.Name)
+				require.Empty(t, result.Contents)
+
+				rec = do(t, h, http.MethodGet, "/bucket-b"+query, "", nil)
+				require.Equal(t, http.StatusNotFound, rec.Code, query)
+				require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()), query)
+			}
+		})
+	}
+}
+
+func TestListObjects_FetchMetadata(t *testing.T) {
+	h := handler.New(service.New(storagemem.New()), handler.WithMaxListKeys(0))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket", "", nil).Code)
+
+	for key, contentType := range map[string]string{
+		"a.txt":  "text/plain",
+		"b.json": "application/json",
+		"c.png":  "image/png",
+	} {
+		rec := do(t, h, http.MethodPut, "/bucket/"+key, "x", map[string]string{
+			"Content-Type":      contentType,
+			"x-amz-meta-origin": "upload of " + key,
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	list := func(t *testing.T, target string) handler.ListBucketResult {
+		t.Helper()
+
+		rec := do(t, h, http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var res handler.ListBucketResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &res))
+
+		return res
+	}
+
+	for _, target := range []string{"/bucket?fetch-metadata=true", "/bucket?list-type=2&fetch-metadata=true"} {
+		res := list(t, target)
+		require.Len(t, res.Contents, 3)
+
+		var types []string
+		for _, c := range res.Contents {
+			types = append(types, c.ContentType)
+			require.Equal(t, &handler.UserMetadata{Items: []handler.MetadataItem{
+				{Key: "origin", Value: "upload of " + c.Key},
+			}}, c.UserMetadata)
+		}
+
+		require.Equal(t, []string{"text/plain", "application/json", "image/png"}, types, target)
+	}
+
+	// Plain listings stay as S3 sends them.
+	for _, c := range list(t, "/bucket?list-type=2").Contents {
+		require.Empty(t, c.ContentType)
+		require.Nil(t, c.UserMetadata)
+	}
+
+	// The page is capped whatever max-keys asks for.
+	res := list(t, "/bucket?list-type=2&fetch-metadata=true&max-keys=5000")
+	require.Equal(t, 1000, res.MaxKeys)
+}
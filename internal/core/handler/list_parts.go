@@ -4,7 +4,6 @@ import (
 	"encoding/xml"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-faster/errors"
@@ -38,9 +37,11 @@ type PartXML struct {
 // ListParts handles GET on an object with ?uploadId, returning the parts
 // uploaded so far, paginated by part-number-marker/max-parts.
 func (h *handler) ListParts(w http.ResponseWriter, r *http.Request) {
+	w, done := h.compressListing(w, r)
+	defer done()
+
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 	q := r.URL.Query()
 	uploadID := q.Get("uploadId")
 
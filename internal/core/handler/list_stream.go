@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+)
+
+// s3Namespace is the XML namespace of S3 response documents.
+const s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// listFlushEvery is how many listing elements are encoded between flushes to
+// the response writer.
+const listFlushEvery = 100
+
+// writeListResult writes a listing response whose Contents and CommonPrefixes
+// are produced from page as they are encoded, instead of being collected into
+// resp first. Memory stays flat however large the page is, and the client
+// receives the head of the document before the tail is rendered.
+//
+// As an extension (S3 sends no ETag on listings) the response carries an ETag
+// derived from the page, and an If-None-Match that still matches it gets a
+// bodiless 304, so pollers of a quiet bucket skip the XML.
+func writeListResult(ctx context.Context, w http.ResponseWriter, r *http.Request, p *listQuery, resp *ListBucketResult, page *listPage) {
+	etag := listETag(r.URL.Query(), page)
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+
+	contents := func(yield func(ObjectInfo) bool) {
+		for _, e := range page.entries {
+			if e.isPrefix {
+				continue
+			}
+
+			info := ObjectInfo{
+				Key:          p.maybeEncode(e.obj.Key),
+				LastModified: e.obj.LastModified,
+				ETag:         quoteETag(e.obj.ETag),
+				Size:         e.obj.Size,
+				Owner:        p.owner,
+			}
+
+			if p.metadata != nil {
+				if m, ok := p.metadata(e.obj.Key); ok {
+					info.ContentType = m.ContentType
+					info.UserMetadata = newUserMetadata(m.UserMetadata)
+				}
+			}
+
+			if !yield(info) {
+				return
+			}
+		}
+	}
+
+	prefixes := func(yield func(CommonPrefix) bool) {
+		for _, e := range page.entries {
+			if e.isPrefix && !yield(CommonPrefix{Prefix: p.maybeEncode(e.key)}) {
+				return
+			}
+		}
+	}
+
+	// The status line is out; a failure now can only be a broken connection.
+	if err := encodeListResult(w, resp, contents, prefixes); err != nil {
+		zctx.From(ctx).Debug("Listing write failed", zap.Error(err))
+	}
+}
+
+// listETagParams are the query parameters that change how a page is
+// rendered, and so are part of its ETag: the same objects listed as V1 and
+// V2, or with and without URL encoding, are different documents.
+var listETagParams = []string{"list-type", "encoding-type", "fetch-owner", fetchMetadataParam}
+
+// listETag hashes what the page lists: the rendering parameters from q, each
+// key with its modification time and ETag (so a same-second overwrite still
+// changes it), in listing order, plus where the page ends.
+func listETag(q url.Values, page *listPage) string {
+	h := sha256.New()
+
+	for _, name := range listETagParams {
+		_, _ = fmt.Fprintf(h, "q\x00%s\x00%s\x00", name, q.Get(name))
+	}
+
+	for _, e := range page.entries {
+		if e.isPrefix {
+			_, _ = fmt.Fprintf(h, "p\x00%s\x00", e.key)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(h, "o\x00%s\x00%d\x00%s\x00%d\x00",
+			e.obj.Key, e.obj.LastModified.UnixNano(), e.obj.ETag, e.obj.Size)
+	}
+
+	_, _ = fmt.Fprintf(h, "t\x00%t\x00%s", page.truncated, page.nextCursor)
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match value names etag (or is "*").
+// Weak and strong forms compare equal, as If-None-Match specifies.
+func etagMatches(header, etag string) bool {
+	for tok := range strings.SplitSeq(header, ",") {
+		tok = strings.TrimPrefix(strings.TrimSpace(tok), "W/")
+		if tok == "*" || tok == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeListResult writes resp's scalar fields followed by the given Contents
+// and CommonPrefixes, byte-for-byte as xml.Marshal would render resp with
+// those slices filled in. resp.Contents and resp.CommonPrefixes are ignored.
+func encodeListResult(w io.Writer, resp *ListBucketResult, contents iter.Seq[ObjectInfo], prefixes iter.Seq[CommonPrefix]) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	root := xml.StartElement{Name: xml.Name{Space: s3Namespace, Local: "ListBucketResult"}}
+
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+
+	var keyCount string
+	if resp.KeyCount != nil {
+		keyCount = strconv.Itoa(*resp.KeyCount)
+	}
+
+	// Field order and omitempty mirror the ListBucketResult struct tags.
+	for _, f := range []struct {
+		name, value string
+		omitEmpty   bool
+	}{
+		{"Name", resp.Name, false},
+		{"Prefix", resp.Prefix, false},
+		{"ContinuationToken", resp.ContinuationToken, true},
+		{"NextContinuationToken", resp.NextContinuationToken, true},
+		{"StartAfter", resp.StartAfter, true},
+		{"KeyCount", keyCount, true},
+		{"Marker", resp.Marker, true},
+		{"NextMarker", resp.NextMarker, true},
+		{"MaxKeys", strconv.Itoa(resp.MaxKeys), false},
+		{"Delimiter", resp.Delimiter, true},
+		{"EncodingType", resp.EncodingType, true},
+		{"IsTruncated", strconv.FormatBool(resp.IsTruncated), false},
+	} {
+		if f.omitEmpty && f.value == "" {
+			continue
+		}
+
+		if err := enc.EncodeElement(f.value, xml.StartElement{Name: xml.Name{Local: f.name}}); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	flush := func() error {
+		n++
+		if n%listFlushEvery == 0 {
+			return enc.Flush()
+		}
+
+		return nil
+	}
+
+	for c := range contents {
+		if err := enc.EncodeElement(c, xml.StartElement{Name: xml.Name{Local: "Contents"}}); err != nil {
+			return err
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	for cp := range prefixes {
+		if err := enc.EncodeElement(cp, xml.StartElement{Name: xml.Name{Local: "CommonPrefixes"}}); err != nil {
+			return err
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
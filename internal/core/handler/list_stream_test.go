@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeListResultMatchesMarshal(t *testing.T) {
+	keyCount := 3
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name string
+		resp ListBucketResult
+	}{
+		{"Empty", ListBucketResult{Name: "bucket", MaxKeys: 1000}},
+		{"V1", ListBucketResult{
+			Name:        "bucket",
+			Prefix:      "dir/",
+			Marker:      "dir/a",
+			NextMarker:  "dir/z/",
+			MaxKeys:     2,
+			Delimiter:   "/",
+			IsTruncated: true,
+			Contents: []ObjectInfo{
+				{Key: "dir/b<&>", LastModified: modified, ETag: `"abc"`, Size: 5, Owner: &Owner{ID: "id", DisplayName: "name"}},
+			},
+			CommonPrefixes: []CommonPrefix{{Prefix: "dir/z/"}},
+		}},
+		{"V2", ListBucketResult{
+			Name:                  "bucket",
+			ContinuationToken:     "YQ==",
+			NextContinuationToken: "Yw==",
+			StartAfter:            "a",
+			KeyCount:              &keyCount,
+			MaxKeys:               3,
+			EncodingType:          encodingTypeURL,
+			Contents: []ObjectInfo{
+				{Key: "b", LastModified: modified, ETag: `"1"`, Size: 1},
+				{Key: "c%20d", LastModified: modified, ETag: `"2"`, Size: 2},
+			},
+			CommonPrefixes: []CommonPrefix{{Prefix: "e/"}},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := xml.Marshal(tc.resp)
+			require.NoError(t, err)
+
+			var got bytes.Buffer
+			require.NoError(t, encodeListResult(&got, &tc.resp,
+				slices.Values(tc.resp.Contents), slices.Values(tc.resp.CommonPrefixes)))
+			require.Equal(t, xml.Header+string(want), got.String())
+		})
+	}
+}
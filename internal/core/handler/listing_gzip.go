@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"compress/gzip"
+	"net/http"
+)
+
+// compressListing returns the writer a listing response should be written to:
+// with WithListingCompression and a client accepting gzip, one that
+// gzip-compresses a 200 body as it is streamed, under Content-Encoding: gzip.
+// Error and 304 responses pass through unchanged. The returned func finishes
+// the gzip stream and must be called once the response is written.
+func (h *handler) compressListing(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if !h.listingGzip {
+		return w, func() {}
+	}
+
+	// The response depends on Accept-Encoding whether or not it is compressed.
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		return w, func() {}
+	}
+
+	gw := &gzipListingWriter{ResponseWriter: w}
+
+	return gw, gw.close
+}
+
+// gzipListingWriter compresses the body of a 200 response. The decision is
+// made when the status is written, so errors rendered before any listing
+// output stay plain XML.
+type gzipListingWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipListingWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.wroteHeader = true
+
+	if code == http.StatusOK {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipListingWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.gz.Write(p)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *gzipListingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *gzipListingWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
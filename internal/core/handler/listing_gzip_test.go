@@ -0,0 +1,88 @@
+package handler_test
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestListObjects_Compression(t *testing.T) {
+	h := handler.New(service.New(storagemem.New()), handler.WithListingCompression())
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	const keys = 200
+	for i := range keys {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, fmt.Sprintf("/bucket-a/logs/2026/10/17/entry-%04d.json", i), "{}", nil).Code)
+	}
+
+	gzipped := map[string]string{"Accept-Encoding": "gzip"}
+
+	t.Run("Gzip", func(t *testing.T) {
+		plain := do(t, h, http.MethodGet, "/bucket-a?list-type=2", "", nil)
+		require.Equal(t, http.StatusOK, plain.Code)
+		require.Empty(t, plain.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", plain.Header().Get("Vary"))
+
+		rec := do(t, h, http.MethodGet, "/bucket-a?list-type=2", "", gzipped)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		require.Less(t, rec.Body.Len(), plain.Body.Len()/4)
+
+		zr, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.Equal(t, plain.Body.String(), string(body))
+
+		var result handler.ListBucketResult
+		require.NoError(t, xml.Unmarshal(body, &result))
+		require.Len(t, result.Contents, keys)
+	})
+
+	t.Run("OtherListings", func(t *testing.T) {
+		for _, target := range []string{"/", "/bucket-a", "/bucket-a?versions", "/bucket-a?uploads"} {
+			rec := do(t, h, http.MethodGet, target, "", gzipped)
+			require.Equal(t, http.StatusOK, rec.Code, target)
+			require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"), target)
+
+			zr, err := gzip.NewReader(rec.Body)
+			require.NoError(t, err, target)
+			_, err = io.ReadAll(zr)
+			require.NoError(t, err, target)
+		}
+	})
+
+	t.Run("ErrorsUncompressed", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/missing?list-type=2", "", gzipped)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("ObjectsUncompressed", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/logs/2026/10/17/entry-0000.json", "", gzipped)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "{}", rec.Body.String())
+	})
+}
+
+func TestListObjects_CompressionOff(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	rec := do(t, h, http.MethodGet, "/bucket-a?list-type=2", "", map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Empty(t, rec.Header().Get("Vary"))
+}
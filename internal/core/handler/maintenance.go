@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// MaintenanceSwitch is a runtime maintenance-mode flag. It is satisfied by
+// *server.Maintenance.
+type MaintenanceSwitch interface {
+	Enabled() bool
+	SetEnabled(on bool)
+}
+
+// maintenanceRetryAfter is the Retry-After hint, in seconds, on writes refused
+// during maintenance. Its length is up to the operator; this only paces
+// retrying clients.
+const maintenanceRetryAfter = "30"
+
+// MaintenanceStatus is the XML document served by the ?maintenance admin
+// endpoint.
+type MaintenanceStatus struct {
+	XMLName xml.Name `xml:"MaintenanceStatus"`
+	Enabled bool     `xml:"Enabled"`
+}
+
+// Admin subresources of the root path.
+const (
+	adminMaintenance = "maintenance"
+	adminAll         = "all"
+	adminObjects     = "objects"
+)
+
+// adminSubresource returns the admin subresource r names, or "" when r is not
+// a server administration request (a root-path request naming one).
+func adminSubresource(r *http.Request) string {
+	if strings.TrimPrefix(r.URL.Path, "/") != "" {
+		return ""
+	}
+
+	q := r.URL.Query()
+	for _, name := range []string{adminMaintenance, adminAll, adminObjects} {
+		if q.Has(name) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// isAdminRequest reports whether r is a server administration request.
+func isAdminRequest(r *http.Request) bool {
+	return adminSubresource(r) != ""
+}
+
+// isBulkDelete reports whether r is one of the bucket DELETE extensions that
+// remove objects wholesale (?pattern, ?force). They are scoped as
+// administration requests although they address a bucket.
+func isBulkDelete(r *http.Request) bool {
+	if r.Method != http.MethodDelete {
+		return false
+	}
+
+	if _, key := splitPath(r); key != "" {
+		return false
+	}
+
+	q := r.URL.Query()
+
+	return q.Has(patternParam) || q.Has(forceParam)
+}
+
+// isMutating reports whether r changes stored state: any method but GET,
+// HEAD and OPTIONS, save a diff, which only reads although it is a POST.
+func isMutating(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return !isDiff(r)
+	}
+}
+
+// refuseInMaintenance answers a write with 503 ServiceUnavailable while
+// maintenance mode is on and reports whether it did. Reads, and the admin
+// requests that turn maintenance off again, pass.
+func (h *handler) refuseInMaintenance(w http.ResponseWriter, r *http.Request) bool {
+	if h.maintenance == nil || !h.maintenance.Enabled() || !isMutating(r) || adminSubresource(r) == adminMaintenance {
+		return false
+	}
+
+	w.Header().Set("Retry-After", maintenanceRetryAfter)
+	s3err.WriteAPI(w, r, s3err.ServiceUnavailable)
+
+	return true
+}
+
+// Maintenance serves the ?maintenance admin endpoint: GET reports the mode,
+// PUT turns it on and DELETE turns it off. It needs WithMaintenance and an
+// authenticator (the caller must hold an Admin grant on "*"); without one
+// anybody could toggle it, so it is refused.
+func (h *handler) Maintenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.maintenance == nil {
+		s3err.WriteAPI(w, r, s3err.NotImplemented)
+		return
+	}
+
+	if !h.authenticated {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, errors.New("admin requests need authentication"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodDelete:
+		on := r.Method == http.MethodPut
+		h.maintenance.SetEnabled(on)
+
+		zctx.From(ctx).Info("Maintenance mode changed", zap.Bool("enabled", on))
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		s3err.WriteAPI(w, r, s3err.MethodNotAllowed)
+
+		return
+	}
+
+	writeXML(ctx, w, r, MaintenanceStatus{Enabled: h.maintenance.Enabled()})
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	m := new(server.Maintenance)
+	h := handler.New(service.New(storagemem.New()), handler.WithMaintenance(m))
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/before", "x", nil).Code)
+
+	m.SetEnabled(true)
+
+	rec := do(t, h, http.MethodPut, "/bucket-a/during", "x", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>ServiceUnavailable</Code>")
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	for _, r := range []struct{ method, target string }{
+		{http.MethodDelete, "/bucket-a/before"},
+		{http.MethodPost, "/bucket-a/during?uploads"},
+		{http.MethodPut, "/bucket-b"},
+	} {
+		require.Equal(t, http.StatusServiceUnavailable, do(t, h, r.method, r.target, "", nil).Code, r)
+	}
+
+	// Reads keep working.
+	rec = do(t, h, http.MethodGet, "/bucket-a/before", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "x", rec.Body.String())
+	require.Len(t, listBucket(t, h, "bucket-a", "?list-type=2").Contents, 1)
+
+	m.SetEnabled(false)
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/during", "x", nil).Code)
+}
+
+func TestMaintenanceEndpoint_RequiresAuthentication(t *testing.T) {
+	m := new(server.Maintenance)
+	h := handler.New(service.New(storagemem.New()), handler.WithMaintenance(m))
+
+	rec := do(t, h, http.MethodPut, "/?maintenance", "", nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.False(t, m.Enabled())
+
+	// Without WithMaintenance there is nothing to toggle.
+	rec = do(t, newStorageHandler(t), http.MethodPut, "/?maintenance", "", nil)
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+func TestResetEndpoint_Gated(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	// Off unless enabled with WithReset.
+	rec := do(t, h, http.MethodDelete, "/?all", "", nil)
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+
+	// Enabled, but admin requests still need authentication.
+	h = handler.New(service.New(storagemem.New()), handler.WithReset())
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	rec = do(t, h, http.MethodDelete, "/?all", "", nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/bucket-a", "", nil).Code)
+}
+
+func TestDeleteByPatternEndpoint_RequiresAuthentication(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/logs/a", "x", nil).Code)
+
+	rec := do(t, h, http.MethodDelete, "/bucket-a?pattern=logs/*", "", nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/bucket-a/logs/a", "", nil).Code)
+}
+
+func TestDeleteBucketForceEndpoint_RequiresAuthentication(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/key", "x", nil).Code)
+
+	require.Equal(t, http.StatusForbidden, do(t, h, http.MethodDelete, "/bucket-a?force", "", nil).Code)
+	require.Equal(t, http.StatusConflict, do(t, h, http.MethodDelete, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodHead, "/bucket-a/key", "", nil).Code)
+}
+
+func TestListAllObjectsEndpoint_RequiresAuthentication(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/logs/a", "x", nil).Code)
+
+	rec := do(t, h, http.MethodGet, "/?objects&prefix=logs/", "", nil)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.NotContains(t, rec.Body.String(), "logs/a")
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// manifestContentTypes are the response types of the ?manifest formats.
+var manifestContentTypes = map[string]string{
+	fs.InventoryCSV:  "text/csv; charset=utf-8",
+	fs.InventoryJSON: "application/json",
+}
+
+// GetBucketManifest implements the GET /{bucket}?manifest extension: the
+// bucket's full inventory (fs.GenerateInventory) streamed as one download,
+// CSV by default or JSON with format=json. It needs the same read access as a
+// listing.
+func (h *handler) GetBucketManifest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, _ := splitPath(r)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = fs.InventoryCSV
+	}
+
+	if !fs.ValidInventoryFormat(format) {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.Errorf("unknown manifest format %q", format))
+		return
+	}
+
+	mw := &manifestWriter{w: w, bucket: bucket, format: format}
+	if err := fs.GenerateInventory(ctx, h.service, bucket, format, mw); err != nil {
+		if !mw.started {
+			renderError(ctx, w, r, err)
+			return
+		}
+
+		// The status is out; all that is left is to cut the body short.
+		zctx.From(ctx).Error("Manifest interrupted", zap.String("bucket", bucket), zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+
+	if !mw.started {
+		mw.start()
+	}
+}
+
+// manifestWriter sends the response headers on the first write, so a
+// listing error can still be answered with an S3 error instead.
+type manifestWriter struct {
+	w       http.ResponseWriter
+	bucket  string
+	format  string
+	started bool
+}
+
+func (m *manifestWriter) start() {
+	m.started = true
+
+	h := m.w.Header()
+	h.Set("Content-Type", manifestContentTypes[m.format])
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", m.bucket+"-manifest."+m.format))
+	m.w.WriteHeader(http.StatusOK)
+}
+
+func (m *manifestWriter) Write(p []byte) (int, error) {
+	if !m.started {
+		m.start()
+	}
+
+	return m.w.Write(p)
+}
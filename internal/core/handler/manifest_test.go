@@ -0,0 +1,59 @@
+package handler_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestGetBucketManifest(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/dir/one", "1", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/two", "22", nil).Code)
+
+	t.Run("CSV", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a?manifest", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		require.Contains(t, rec.Header().Get("Content-Disposition"), `filename="bucket-a-manifest.csv"`)
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, "Key", records[0][0])
+		slices.SortFunc(records[1:], func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+		require.Equal(t, []string{"dir/one", "1"}, records[1][:2])
+		require.Equal(t, []string{"two", "2"}, records[2][:2])
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a?manifest&format=json", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var entries []fs.InventoryEntry
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+		require.Len(t, entries, 2)
+		slices.SortFunc(entries, func(a, b fs.InventoryEntry) int { return strings.Compare(a.Key, b.Key) })
+		require.Equal(t, "two", entries[1].Key)
+		require.Equal(t, strings.Trim(do(t, h, http.MethodHead, "/bucket-a/two", "", nil).Header().Get("ETag"), `"`), entries[1].ETag)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a?manifest&format=xml", "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+
+		rec = do(t, h, http.MethodGet, "/missing?manifest", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()))
+	})
+}
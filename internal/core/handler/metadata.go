@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"mime"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/go-faster/errors"
 
@@ -14,9 +16,37 @@ import (
 // userMetadataPrefix is the header prefix for user-defined object metadata.
 const userMetadataPrefix = "X-Amz-Meta-"
 
+// metadataWordDecoder decodes RFC 2047 encoded-words in x-amz-meta-* values.
+var metadataWordDecoder = new(mime.WordDecoder)
+
+// decodeMetadataValue returns the text of an x-amz-meta-* request header.
+// SDKs send non-ASCII values as RFC 2047 encoded-words ("=?UTF-8?B?...?="),
+// which are decoded so the stored value is the text itself; raw UTF-8 is kept
+// as is. A value that is not valid UTF-8 either way is refused.
+func decodeMetadataValue(v string) (string, error) {
+	decoded, err := metadataWordDecoder.DecodeHeader(v)
+	if err != nil {
+		return "", errors.Wrap(err, "decode metadata value")
+	}
+
+	if !utf8.ValidString(decoded) {
+		return "", errors.Errorf("metadata value %q is not valid UTF-8", v)
+	}
+
+	return decoded, nil
+}
+
+// encodeMetadataValue renders a stored metadata value for a response header:
+// printable ASCII as is, anything else (non-ASCII, control characters) as
+// RFC 2047 encoded-words, as S3 does, so no value can break the header block.
+func encodeMetadataValue(v string) string {
+	return mime.BEncoding.Encode("UTF-8", v)
+}
+
 // extractObjectMetadata collects the representation headers and x-amz-meta-*
-// pairs from a request into the domain metadata type.
-func extractObjectMetadata(header http.Header) fs.ObjectMetadata {
+// pairs from a request into the domain metadata type, decoding encoded
+// metadata values (see decodeMetadataValue).
+func extractObjectMetadata(header http.Header) (fs.ObjectMetadata, error) {
 	meta := fs.ObjectMetadata{
 		ContentType:        header.Get("Content-Type"),
 		CacheControl:       header.Get("Cache-Control"),
@@ -34,14 +64,19 @@ func extractObjectMetadata(header http.Header) fs.ObjectMetadata {
 			continue
 		}
 
+		value, err := decodeMetadataValue(values[0])
+		if err != nil {
+			return fs.ObjectMetadata{}, errors.Wrapf(err, "%s%s", userMetadataPrefix, key)
+		}
+
 		if meta.UserMetadata == nil {
 			meta.UserMetadata = make(map[string]string)
 		}
 
-		meta.UserMetadata[key] = values[0]
+		meta.UserMetadata[key] = value
 	}
 
-	return meta
+	return meta, nil
 }
 
 // cleanContentEncoding drops the transport-only "aws-chunked" token from a
@@ -95,7 +130,7 @@ func writeObjectMetadata(h http.Header, meta fs.ObjectMetadata) {
 		// Assign directly to keep the all-lowercase header name AWS emits
 		// (h.Set would canonicalize x-amz-meta-color to X-Amz-Meta-Color, and
 		// SDKs surface the key casing verbatim).
-		h[strings.ToLower(userMetadataPrefix)+k] = []string{meta.UserMetadata[k]}
+		h[strings.ToLower(userMetadataPrefix)+k] = []string{encodeMetadataValue(meta.UserMetadata[k])}
 	}
 }
 
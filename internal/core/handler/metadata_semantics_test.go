@@ -1,7 +1,9 @@
 package handler_test
 
 import (
+	"encoding/base64"
 	"encoding/xml"
+	"mime"
 	"net/http"
 	"strings"
 	"testing"
@@ -50,6 +52,58 @@ func TestMetadata_HeaderRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMetadata_NonASCIIRoundTrip(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	const (
+		city  = "Zürich – 東京"
+		notes = "line one\nline two"
+	)
+
+	put := do(t, h, http.MethodPut, "/"+bucket+"/doc.txt", "content", map[string]string{
+		// As SDKs send it: an RFC 2047 encoded-word.
+		"X-Amz-Meta-City": mime.BEncoding.Encode("UTF-8", city),
+		// Raw UTF-8 is taken as is.
+		"X-Amz-Meta-Raw":   city,
+		"X-Amz-Meta-Notes": mime.QEncoding.Encode("UTF-8", notes),
+		"X-Amz-Meta-Plain": "ascii only",
+	})
+	require.Equal(t, http.StatusOK, put.Code, put.Body.String())
+
+	var dec mime.WordDecoder
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		rec := do(t, h, method, "/"+bucket+"/doc.txt", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		for key, want := range map[string]string{"city": city, "raw": city, "notes": notes} {
+			v := metaHeader(rec.Header(), key)
+			require.True(t, strings.HasPrefix(v, "=?UTF-8?b?"), "%s %s: %q", method, key, v)
+			require.NotContains(t, v, "\n")
+
+			got, err := dec.DecodeHeader(v)
+			require.NoError(t, err)
+			require.Equal(t, want, got, method)
+		}
+
+		require.Equal(t, "ascii only", metaHeader(rec.Header(), "plain"), method)
+	}
+
+	// The stored value is the text, not its encoding.
+	rec := do(t, h, http.MethodGet, "/"+bucket+"/doc.txt?meta", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"city": "Zürich – 東京"`)
+
+	rec = do(t, h, http.MethodPut, "/"+bucket+"/bad", "x", map[string]string{
+		"X-Amz-Meta-Bad": "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe}) + "?=",
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+}
+
 func TestMetadata_DefaultContentType(t *testing.T) {
 	const bucket = "bucket-a"
 
@@ -107,6 +161,35 @@ func TestMetadata_MultipartRoundTrip(t *testing.T) {
 	require.Equal(t, "clip", metaHeader(get.Header(), "title"))
 }
 
+func TestMetadata_SizeLimit(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	// "color" + value: 5 + 2043 = 2048 bytes, exactly the S3 limit.
+	atLimit := map[string]string{"X-Amz-Meta-Color": strings.Repeat("x", 2043)}
+	overLimit := map[string]string{"X-Amz-Meta-Color": strings.Repeat("x", 2044)}
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/at", "x", atLimit).Code)
+
+	rec := do(t, h, http.MethodPut, "/"+bucket+"/over", "x", overLimit)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>MetadataTooLarge</Code>")
+
+	rec = do(t, h, http.MethodPost, "/"+bucket+"/over?uploads", "", overLimit)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>MetadataTooLarge</Code>")
+
+	rec = do(t, h, http.MethodPut, "/"+bucket+"/over", "", map[string]string{
+		"X-Amz-Copy-Source":        "/" + bucket + "/at",
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"X-Amz-Meta-Color":         overLimit["X-Amz-Meta-Color"],
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>MetadataTooLarge</Code>")
+}
+
 func TestCopyObject_MetadataDirectives(t *testing.T) {
 	const bucket = "bucket-a"
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
 )
 
 // CompleteMultipartUploadResult represents the response for completing multipart upload.
@@ -68,10 +69,25 @@ func (h *handler) completeMultipartUpload(w http.ResponseWriter, r *http.Request
 	}
 
 	req := &fs.CompleteMultipartUploadRequest{
-		Bucket:   bucket,
-		Key:      key,
-		UploadID: uploadID,
-		Parts:    parts,
+		Bucket:      bucket,
+		Key:         key,
+		UploadID:    uploadID,
+		Parts:       parts,
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+		IfMatch:     r.Header.Get("If-Match"),
+	}
+
+	// The upload stays under key; with WithNoOverwriteRename the assembled
+	// object may be stored under a free derived key instead.
+	finalKey, ifNoneMatch, err := h.renameOnConflict(ctx, w, bucket, key, req.IfNoneMatch, req.IfMatch)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	req.IfNoneMatch = ifNoneMatch
+	if finalKey != key {
+		req.StoreAs = finalKey
 	}
 
 	resp, err := h.service.CompleteMultipartUpload(ctx, req)
@@ -89,6 +105,18 @@ func (h *handler) completeMultipartUpload(w http.ResponseWriter, r *http.Request
 	}
 
 	w.Header().Set("Content-Type", "application/xml")
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, "")
 	w.WriteHeader(http.StatusOK)
 	_ = xml.NewEncoder(w).Encode(result)
+
+	if h.events != nil {
+		// The assembled size is only known to storage; read it back.
+		var size int64
+		if obj, err := h.service.GetObject(ctx, bucket, finalKey); err == nil {
+			size = obj.Size
+			_ = obj.Reader.Close()
+		}
+
+		h.emit(w, notify.ObjectCreatedCompleteMultipartUpload, bucket, finalKey, size, resp.ETag)
+	}
 }
@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/xml"
 	"net/http"
-	"strings"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
@@ -20,8 +19,7 @@ type InitiateMultipartUploadResult struct {
 
 func (h *handler) HandleObjectPost(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 	query := r.URL.Query()
 
 	// Check if this is multipart upload initiation.
@@ -49,10 +47,21 @@ func (h *handler) initiateMultipartUpload(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	metadata, err := extractObjectMetadata(r.Header)
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	}
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
 	upload, err := h.service.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{
 		Bucket:   bucket,
 		Key:      key,
-		Metadata: extractObjectMetadata(r.Header),
+		Metadata: metadata,
 		Tags:     tags,
 		ACL:      fs.ParseACL(r.Header.Get("X-Amz-Acl")),
 	})
@@ -3,7 +3,6 @@ package handler
 import (
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
@@ -11,8 +10,7 @@ import (
 
 func (h *handler) UploadPart(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 	query := r.URL.Query()
 
 	uploadID := query.Get("uploadId")
@@ -24,8 +22,8 @@ func (h *handler) UploadPart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Handle AWS chunked encoding.
-	reader := getBodyReader(r)
+	// Handle AWS chunked encoding and checksum trailers.
+	reader, checksum := withTrailerChecksum(r, getBodyReader(r))
 
 	req := &fs.UploadPartRequest{
 		Bucket:     bucket,
@@ -36,9 +34,20 @@ func (h *handler) UploadPart(w http.ResponseWriter, r *http.Request) {
 		Size:       r.ContentLength,
 	}
 
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
 	part, err := h.service.UploadPart(ctx, req)
 	if err != nil {
+		if cerr := checksum.mismatch(); cerr != nil {
+			renderAPIError(ctx, w, r, s3err.BadDigest, cerr)
+			return
+		}
+
 		renderError(ctx, w, r, err)
+
 		return
 	}
 
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+)
+
+// NotFoundResolver returns the fallback object a bucket serves in place of a
+// missing key, as fallback.Registry does.
+type NotFoundResolver interface {
+	NotFoundKey(bucket string) (key string, status int, ok bool)
+}
+
+// serveNotFoundKey answers a GET or HEAD whose key is missing with the
+// bucket's fallback object, if it has one and the object exists. A 200
+// fallback is served as the object itself (ranges, conditionals); any other
+// status sends its whole body with that status. It returns false, having
+// written nothing, when there is no fallback to serve.
+func (h *handler) serveNotFoundKey(ctx context.Context, w http.ResponseWriter, r *http.Request, bucket, key string) bool {
+	if h.notFound == nil {
+		return false
+	}
+
+	fallbackKey, status, ok := h.notFound.NotFoundKey(bucket)
+	if !ok || fallbackKey == key {
+		return false
+	}
+
+	resp, err := h.service.GetObject(ctx, bucket, fallbackKey)
+	if err != nil {
+		if !errors.Is(err, fs.ErrObjectNotFound) {
+			zctx.From(ctx).Warn("Not-found fallback unavailable",
+				zap.String("bucket", bucket),
+				zap.String("key", fallbackKey),
+				zap.Error(err),
+			)
+		}
+
+		return false
+	}
+
+	if status == http.StatusOK {
+		serveObject(w, r, fallbackKey, resp, h.defaultContentType)
+		return true
+	}
+
+	defer func() { _ = resp.Reader.Close() }()
+
+	writeObjectMetadata(w.Header(), resp.Metadata)
+	w.Header().Set("Content-Length", strconv.FormatInt(resp.Size, 10))
+	w.WriteHeader(status)
+
+	if r.Method != http.MethodHead {
+		ir := &integrityReader{Reader: resp.Reader}
+		_, err := io.Copy(w, ir)
+		logServeError(ctx, ir, err)
+		abortIfCorrupt(ctx, ir)
+	}
+
+	return true
+}
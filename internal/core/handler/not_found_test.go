@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/fallback"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestGetObject_NotFoundKey(t *testing.T) {
+	reg := fallback.NewRegistry()
+	h := handler.New(service.New(storagemem.New()), handler.WithNotFoundKeys(reg))
+
+	for _, bucket := range []string{"spa-app", "error-pages", "plain"} {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+	}
+
+	html := map[string]string{"Content-Type": "text/html"}
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/spa-app/index.html", "<app>", html).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/spa-app/main.js", "js", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/error-pages/404.html", "<not found>", html).Code)
+
+	reg.SetBucketNotFoundKey("spa-app", "index.html", http.StatusOK)
+	reg.SetBucketNotFoundKey("error-pages", "404.html", http.StatusNotFound)
+
+	t.Run("SPARewrite", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/spa-app/users/42/settings", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "<app>", rec.Body.String())
+		require.Equal(t, "text/html", rec.Header().Get("Content-Type"))
+
+		head := do(t, h, http.MethodHead, "/spa-app/users/42/settings", "", nil)
+		require.Equal(t, http.StatusOK, head.Code)
+		require.Empty(t, head.Body.String())
+	})
+
+	t.Run("ExistingKeyUnaffected", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/spa-app/main.js", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "js", rec.Body.String())
+	})
+
+	t.Run("ErrorPage", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/error-pages/missing.html", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "<not found>", rec.Body.String())
+		require.Equal(t, "text/html", rec.Header().Get("Content-Type"))
+
+		head := do(t, h, http.MethodHead, "/error-pages/missing.html", "", nil)
+		require.Equal(t, http.StatusNotFound, head.Code)
+		require.Empty(t, head.Body.String())
+	})
+
+	t.Run("NoFallback", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/plain/missing", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchKey", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("FallbackMissing", func(t *testing.T) {
+		reg.SetBucketNotFoundKey("plain", "index.html", http.StatusOK)
+		t.Cleanup(func() { reg.RemoveBucketNotFoundKey("plain") })
+
+		rec := do(t, h, http.MethodGet, "/plain/missing", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchKey", errorCode(t, rec.Body.String()))
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// setObjectHeaders sets the representation headers GET and HEAD share, so the
+// two never diverge: Content-Type (the stored one, else defaultType), the
+// other stored representation headers and x-amz-meta-* pairs, ETag,
+// Last-Modified, the x-amz-server-side-encryption headers, Accept-Ranges and
+// the full-object Content-Length.
+//
+// http.ServeContent later narrows Content-Length and adds Content-Range for a
+// satisfied Range, and drops the body headers on 304; objectWriter settles the
+// rest once the status is known.
+func setObjectHeaders(h http.Header, resp *fs.GetObjectResponse, defaultType string) {
+	h.Set("Content-Type", defaultType)
+	writeObjectMetadata(h, resp.Metadata)
+
+	if resp.ETag != "" {
+		h.Set("ETag", quoteETag(resp.ETag))
+	}
+
+	if !resp.LastModified.IsZero() {
+		h.Set("Last-Modified", resp.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	setEncryptionHeaders(h, resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
+	h.Set("Accept-Ranges", "bytes")
+
+	if resp.MetadataMissing {
+		// S3 counts the entries it could not return; how many were stored is
+		// unknown here, so any is reported as one.
+		h.Set("x-amz-missing-meta", "1")
+	}
+
+	if resp.Size >= 0 {
+		h.Set("Content-Length", strconv.FormatInt(resp.Size, 10))
+	}
+}
+
+// objectWriter finalizes the object headers when the status is written. A
+// stored Content-Encoding is held back until then: http.ServeContent only sets
+// Content-Length (and the ranged length) when no Content-Encoding is present,
+// so serving with it set would leave GET relying on chunking and HEAD
+// reporting no length. The stored encoding describes the object bytes, not a
+// transfer coding, so a byte range of it is still exact. On a non-2xx status
+// (412, 416) the full-object Content-Length no longer describes the body and
+// is dropped, and the plain-text error http.ServeContent writes is replaced by
+// the S3 XML error (PreconditionFailed, InvalidRange), without a body for
+// HEAD, so GET and HEAD answer ranges and conditions identically.
+type objectWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	encoding    string
+	wroteHeader bool
+	// discard drops the body ServeContent writes after an error status.
+	discard bool
+	// writeErr is the first body write that failed, which ServeContent
+	// does not report.
+	writeErr error
+}
+
+func (w *objectWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.wroteHeader = true
+
+	if code < http.StatusMultipleChoices {
+		if w.encoding != "" {
+			w.Header().Set("Content-Encoding", w.encoding)
+		}
+
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	w.Header().Del("Content-Length")
+
+	var api s3err.APIError
+
+	switch code {
+	case http.StatusPreconditionFailed:
+		api = s3err.PreconditionFailed
+	case http.StatusRequestedRangeNotSatisfiable:
+		api = s3err.InvalidRange
+	default:
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.discard = true
+	w.Header().Del("X-Content-Type-Options")
+	s3err.WriteAPI(w.ResponseWriter, w.r, api)
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.discard {
+		return len(p), nil
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	if err != nil && w.writeErr == nil {
+		w.writeErr = err
+	}
+
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *objectWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,182 @@
+package handler_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/internal/mock"
+	"github.com/go-faster/fs/storagefs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// objectHeaders returns the response headers minus the per-request ones.
+func objectHeaders(rec interface{ Header() http.Header }) http.Header {
+	h := rec.Header().Clone()
+	h.Del("x-amz-request-id")
+
+	return h
+}
+
+func TestHeadObject_HeadersMatchGet(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/obj", "0123456789", map[string]string{
+		"Content-Type":        "text/plain",
+		"Content-Encoding":    "gzip",
+		"Cache-Control":       "no-cache",
+		"x-amz-meta-color":    "blue",
+		"Content-Disposition": "attachment",
+	}).Code)
+
+	for _, tt := range []struct {
+		name    string
+		headers map[string]string
+		status  int
+		length  string
+		body    string
+	}{
+		{"Full", nil, http.StatusOK, "10", "0123456789"},
+		{"Range", map[string]string{"Range": "bytes=2-5"}, http.StatusPartialContent, "4", "2345"},
+		{"NotModified", map[string]string{"If-None-Match": "*"}, http.StatusNotModified, "", ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			get := do(t, h, http.MethodGet, "/bucket-a/obj", "", tt.headers)
+			head := do(t, h, http.MethodHead, "/bucket-a/obj", "", tt.headers)
+
+			require.Equal(t, tt.status, get.Code)
+			require.Equal(t, tt.status, head.Code)
+			require.Equal(t, objectHeaders(get), objectHeaders(head))
+			require.Equal(t, tt.length, head.Header().Get("Content-Length"))
+			require.Equal(t, tt.body, get.Body.String())
+			require.Empty(t, head.Body.String())
+
+			if tt.status != http.StatusNotModified {
+				require.Equal(t, "gzip", head.Header().Get("Content-Encoding"))
+			}
+		})
+	}
+}
+
+func TestHeadObject_HeadersMatchGet_NonSeekable(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := &mock.StorageMock{
+		GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+			return &fs.GetObjectResponse{
+				// io.NopCloser hides Seek, forcing the non-ServeContent path.
+				Reader:       io.NopCloser(strings.NewReader("")),
+				Size:         0,
+				LastModified: modified,
+				ETag:         "d41d8cd98f00b204e9800998ecf8427e",
+				Metadata:     fs.ObjectMetadata{ContentEncoding: "gzip"},
+			}, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	get := do(t, h, http.MethodGet, "/bucket-a/empty", "", nil)
+	head := do(t, h, http.MethodHead, "/bucket-a/empty", "", nil)
+
+	require.Equal(t, http.StatusOK, get.Code)
+	require.Equal(t, objectHeaders(get), objectHeaders(head))
+	require.Equal(t, "0", head.Header().Get("Content-Length"))
+	require.Equal(t, modified.Format(http.TimeFormat), head.Header().Get("Last-Modified"))
+}
+
+func TestObjectHeaders_ServerSideEncryption(t *testing.T) {
+	root := t.TempDir()
+	storage, err := storagefs.New(root, storagefs.WithEncryptionKey(make([]byte, 32)))
+	require.NoError(t, err)
+
+	h := handler.New(service.New(storage))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	put := do(t, h, http.MethodPut, "/bucket-a/obj", "top secret", nil)
+	require.Equal(t, http.StatusOK, put.Code)
+	require.Equal(t, "AES256", put.Header().Get("x-amz-server-side-encryption"))
+
+	raw, err := os.ReadFile(filepath.Join(root, "bucket-a", "obj"))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "top secret")
+
+	get := do(t, h, http.MethodGet, "/bucket-a/obj", "", nil)
+	require.Equal(t, http.StatusOK, get.Code)
+	require.Equal(t, "top secret", get.Body.String())
+	require.Equal(t, "AES256", get.Header().Get("x-amz-server-side-encryption"))
+
+	ranged := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=4-9"})
+	require.Equal(t, http.StatusPartialContent, ranged.Code)
+	require.Equal(t, "secret", ranged.Body.String())
+
+	head := do(t, h, http.MethodHead, "/bucket-a/obj", "", nil)
+	require.Equal(t, objectHeaders(get), objectHeaders(head))
+	require.Equal(t, "10", head.Header().Get("Content-Length"))
+}
+
+func TestObjectHeaders_MissingMeta(t *testing.T) {
+	missing := true
+	svc := &mock.StorageMock{
+		GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+			return &fs.GetObjectResponse{
+				Reader:          io.NopCloser(strings.NewReader("body")),
+				Size:            4,
+				ETag:            "841a2d689ad86bd1611447453c22c6fc",
+				MetadataMissing: missing,
+			}, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	get := do(t, h, http.MethodGet, "/bucket-a/obj", "", nil)
+	require.Equal(t, http.StatusOK, get.Code)
+	require.Equal(t, "body", get.Body.String())
+	require.Equal(t, "application/octet-stream", get.Header().Get("Content-Type"))
+	require.Equal(t, "1", get.Header().Get("x-amz-missing-meta"))
+
+	head := do(t, h, http.MethodHead, "/bucket-a/obj", "", nil)
+	require.Equal(t, objectHeaders(get), objectHeaders(head))
+
+	missing = false
+	require.Empty(t, do(t, h, http.MethodGet, "/bucket-a/obj", "", nil).Header().Get("x-amz-missing-meta"))
+}
+
+func TestObjectHeaders_DefaultContentType(t *testing.T) {
+	h := handler.New(service.New(storagemem.New()), handler.WithDefaultContentType("text/plain; charset=utf-8"))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/typeless", "plain words", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/typed", "{}",
+		map[string]string{"Content-Type": "application/json"}).Code)
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		rec := do(t, h, method, "/bucket-a/typeless", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"), method)
+
+		// An uploaded type always wins.
+		rec = do(t, h, method, "/bucket-a/typed", "", nil)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"), method)
+	}
+
+	get := do(t, h, http.MethodGet, "/bucket-a/typeless", "", nil)
+	require.Equal(t, "plain words", get.Body.String())
+
+	// Without the option, or with an empty one, the S3 default applies.
+	for _, opts := range [][]handler.Option{nil, {handler.WithDefaultContentType("")}} {
+		h = handler.New(service.New(storagemem.New()), opts...)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/typeless", "plain words", nil).Code)
+		require.Equal(t, "application/octet-stream",
+			do(t, h, http.MethodGet, "/bucket-a/typeless", "", nil).Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// Legal hold statuses.
+const (
+	legalHoldOn  = "ON"
+	legalHoldOff = "OFF"
+)
+
+// LegalHold is the XML document for an object's legal hold (both request and
+// response).
+type LegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
+
+// GetObjectLegalHold handles GET on an object with ?legal-hold. An object
+// that was never held reports OFF.
+func (h *handler) GetObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, key := splitPath(r)
+
+	on, err := h.service.GetObjectLegalHold(ctx, bucket, key)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	resp := LegalHold{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Status: legalHoldOff,
+	}
+	if on {
+		resp.Status = legalHoldOn
+	}
+
+	writeXML(ctx, w, r, resp)
+}
+
+// PutObjectLegalHold handles PUT on an object with ?legal-hold, placing (ON)
+// or lifting (OFF) the hold. While it is on, the object can be neither
+// overwritten nor deleted (AccessDenied).
+func (h *handler) PutObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, key := splitPath(r)
+
+	var doc LegalHold
+	if err := xml.NewDecoder(r.Body).Decode(&doc); err != nil {
+		renderAPIError(ctx, w, r, s3err.MalformedXML, err)
+		return
+	}
+
+	if doc.Status != legalHoldOn && doc.Status != legalHoldOff {
+		renderAPIError(ctx, w, r, s3err.MalformedXML, errors.Errorf("invalid legal hold status %q", doc.Status))
+		return
+	}
+
+	if err := h.service.PutObjectLegalHold(ctx, bucket, key, doc.Status == legalHoldOn); err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
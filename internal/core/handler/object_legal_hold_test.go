@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+)
+
+func legalHoldBody(status string) string {
+	return `<LegalHold xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>` + status + `</Status></LegalHold>`
+}
+
+func TestObjectLegalHold(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/report.pdf", "v1", nil).Code)
+
+	status := func(t *testing.T) string {
+		t.Helper()
+
+		rec := do(t, h, http.MethodGet, "/bucket-a/report.pdf?legal-hold", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var doc handler.LegalHold
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &doc))
+
+		return doc.Status
+	}
+
+	require.Equal(t, "OFF", status(t))
+
+	rec := do(t, h, http.MethodPut, "/bucket-a/report.pdf?legal-hold", legalHoldBody("ON"), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "ON", status(t))
+
+	t.Run("DeleteRefused", func(t *testing.T) {
+		rec := do(t, h, http.MethodDelete, "/bucket-a/report.pdf", "", nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Equal(t, "AccessDenied", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("OverwriteRefused", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket-a/report.pdf", "v2", nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Equal(t, "AccessDenied", errorCode(t, rec.Body.String()))
+
+		rec = do(t, h, http.MethodGet, "/bucket-a/report.pdf", "", nil)
+		require.Equal(t, "v1", rec.Body.String())
+	})
+
+	t.Run("CopyOntoRefused", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/other", "v3", nil).Code)
+
+		rec := do(t, h, http.MethodPut, "/bucket-a/report.pdf", "", map[string]string{
+			"X-Amz-Copy-Source": "/bucket-a/other",
+		})
+		require.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Lifted", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket-a/report.pdf?legal-hold", legalHoldBody("OFF"), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "OFF", status(t))
+
+		require.Equal(t, http.StatusNoContent, do(t, h, http.MethodDelete, "/bucket-a/report.pdf", "", nil).Code)
+		require.Equal(t, http.StatusNotFound, do(t, h, http.MethodGet, "/bucket-a/report.pdf", "", nil).Code)
+	})
+}
+
+func TestObjectLegalHold_Errors(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/key", "x", nil).Code)
+
+	for _, body := range []string{"not xml", legalHoldBody("on"), legalHoldBody("")} {
+		rec := do(t, h, http.MethodPut, "/bucket-a/key?legal-hold", body, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Equal(t, "MalformedXML", errorCode(t, rec.Body.String()), body)
+	}
+
+	rec := do(t, h, http.MethodGet, "/bucket-a/missing?legal-hold", "", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "NoSuchKey", errorCode(t, rec.Body.String()))
+
+	rec = do(t, h, http.MethodPut, "/bucket-a/missing?legal-hold", legalHoldBody("ON"), nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
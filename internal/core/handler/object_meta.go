@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+)
+
+// GetObjectMeta implements the GET /{bucket}/{key}?meta extension: the
+// object's metadata, tags and checksums (fs.DescribeObject) as one JSON
+// document, for operators and tooling. It needs the same access as a GET,
+// including the SSE-C key for an object encrypted with one.
+func (h *handler) GetObjectMeta(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, key := splitPath(r)
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
+	d, err := fs.DescribeObject(ctx, h.service, bucket, key)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(d); err != nil {
+		zctx.From(ctx).Debug("Object meta write failed", zap.Error(err))
+	}
+}
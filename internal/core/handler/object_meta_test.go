@@ -0,0 +1,52 @@
+package handler_test
+
+import (
+	"crypto/md5" //nolint:gosec // S3 ETag.
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObjectMeta(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	body := "described body"
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/dir/obj", body, map[string]string{
+		"Content-Type":     "text/plain",
+		"Cache-Control":    "no-cache",
+		"X-Amz-Meta-Color": "blue",
+		"X-Amz-Tagging":    "team=core&tier=gold",
+	}).Code)
+
+	rec := do(t, h, http.MethodGet, "/bucket-a/dir/obj?meta", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+	sum := md5.Sum([]byte(body)) //nolint:gosec // S3 ETag.
+	etag := hex.EncodeToString(sum[:])
+
+	require.Equal(t, "bucket-a", doc["bucket"])
+	require.Equal(t, "dir/obj", doc["key"])
+	require.EqualValues(t, len(body), doc["size"])
+	require.Equal(t, etag, doc["etag"])
+	require.NotEmpty(t, doc["last_modified"])
+	require.Equal(t, "text/plain", doc["content_type"])
+	require.Equal(t, "no-cache", doc["cache_control"])
+	require.Equal(t, map[string]any{"color": "blue"}, doc["user_metadata"])
+	require.Equal(t, map[string]any{"team": "core", "tier": "gold"}, doc["tags"])
+	require.Equal(t, "STANDARD", doc["storage_class"])
+	require.Equal(t, map[string]any{"md5": etag}, doc["checksums"])
+
+	t.Run("NotFound", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/missing?meta", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchKey", errorCode(t, rec.Body.String()))
+	})
+}
@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/xml"
 	"net/http"
-	"strings"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
@@ -30,8 +29,7 @@ type TagXML struct {
 // GetObjectTagging handles GET on an object with ?tagging.
 func (h *handler) GetObjectTagging(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 
 	tags, err := h.service.GetObjectTagging(ctx, bucket, key)
 	if err != nil {
@@ -54,8 +52,7 @@ func (h *handler) GetObjectTagging(w http.ResponseWriter, r *http.Request) {
 // PutObjectTagging handles PUT on an object with ?tagging.
 func (h *handler) PutObjectTagging(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 
 	var doc Tagging
 	if err := xml.NewDecoder(r.Body).Decode(&doc); err != nil {
@@ -79,8 +76,7 @@ func (h *handler) PutObjectTagging(w http.ResponseWriter, r *http.Request) {
 // DeleteObjectTagging handles DELETE on an object with ?tagging.
 func (h *handler) DeleteObjectTagging(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 
 	if err := h.service.DeleteObjectTagging(ctx, bucket, key); err != nil {
 		renderError(ctx, w, r, err)
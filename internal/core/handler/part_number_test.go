@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObject_PartNumber(t *testing.T) {
+	const bucket, key = "bucket-a", "parts.bin"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+
+	bodies := []string{minPartBody(), strings.Repeat("b", 5*1024*1024), "tail"}
+	uploadID := initiateUpload(t, h, bucket, key)
+
+	var parts [][2]string
+
+	for i, body := range bodies {
+		etag := putPart(t, h, bucket, key, uploadID, i+1, body)
+		parts = append(parts, [2]string{fmt.Sprint(i + 1), etag})
+	}
+
+	rec := do(t, h, http.MethodPost, "/"+bucket+"/"+key+"?uploadId="+uploadID, completeBody(parts...), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	t.Run("Reassemble", func(t *testing.T) {
+		var (
+			got    strings.Builder
+			offset int
+		)
+
+		for i, body := range bodies {
+			rec := do(t, h, http.MethodGet, fmt.Sprintf("/%s/%s?partNumber=%d", bucket, key, i+1), "", nil)
+			require.Equal(t, http.StatusPartialContent, rec.Code)
+			require.Equal(t, "3", rec.Header().Get("x-amz-mp-parts-count"))
+			require.Equal(t,
+				fmt.Sprintf("bytes %d-%d/%d", offset, offset+len(body)-1, len(bodies[0])+len(bodies[1])+len(bodies[2])),
+				rec.Header().Get("Content-Range"),
+			)
+			require.Equal(t, fmt.Sprint(len(body)), rec.Header().Get("Content-Length"))
+
+			got.WriteString(rec.Body.String())
+			offset += len(body)
+		}
+
+		require.Equal(t, strings.Join(bodies, ""), got.String())
+	})
+
+	t.Run("Head", func(t *testing.T) {
+		rec := do(t, h, http.MethodHead, "/"+bucket+"/"+key+"?partNumber=3", "", nil)
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+		require.Equal(t, "4", rec.Header().Get("Content-Length"))
+		require.Equal(t, "3", rec.Header().Get("x-amz-mp-parts-count"))
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/"+bucket+"/"+key+"?partNumber=4", "", nil)
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		require.Equal(t, "InvalidPartNumber", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, n := range []string{"0", "-1", "x", "10001"} {
+			rec := do(t, h, http.MethodGet, "/"+bucket+"/"+key+"?partNumber="+n, "", nil)
+			require.Equal(t, http.StatusBadRequest, rec.Code, n)
+			require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()), n)
+		}
+
+		rec := do(t, h, http.MethodGet, "/"+bucket+"/"+key+"?partNumber=1", "", map[string]string{"Range": "bytes=0-1"})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidRequest", errorCode(t, rec.Body.String()))
+	})
+}
+
+func TestGetObject_PartNumberSinglePart(t *testing.T) {
+	const bucket = "bucket-a"
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket, "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/"+bucket+"/plain.txt", "hello", nil).Code)
+
+	rec := do(t, h, http.MethodGet, "/"+bucket+"/plain.txt?partNumber=1", "", nil)
+	require.Equal(t, http.StatusPartialContent, rec.Code)
+	require.Equal(t, "hello", rec.Body.String())
+	require.Equal(t, "bytes 0-4/5", rec.Header().Get("Content-Range"))
+	require.Empty(t, rec.Header().Get("x-amz-mp-parts-count"))
+
+	rec = do(t, h, http.MethodGet, "/"+bucket+"/plain.txt?partNumber=2", "", nil)
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+	require.Equal(t, "InvalidPartNumber", errorCode(t, rec.Body.String()))
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// Request paths are path-style, /{bucket}/{key}, and are interpreted as
+// follows before any routing:
+//
+//   - Bucket and key are split on the escaped path as sent (URL.EscapedPath):
+//     the first literal "/" after the bucket ends it. Each part is then
+//     percent-decoded exactly once on its own, so an encoded slash can never
+//     move the boundary: "/bucket%2Fkey" names the bucket "bucket/key" (and is
+//     rejected), not the key "key".
+//   - In the key, "%2F" decodes to "/" like S3 does: keys are flat strings, so
+//     "a%2Fb" and "a/b" name the same key. "%20" is a space, "+" is a literal
+//     plus (never a space, unlike in a query string) and "%2541" names the key
+//     "%41", never "A".
+//   - "/" alone, or the empty path http.StripPrefix leaves for a handler
+//     mounted under a prefix, addresses the service (ListBuckets).
+//   - "/bucket" and "/bucket/" both address the bucket.
+//   - Everything after the "/" following the bucket is the key, byte for byte
+//     once decoded. Nothing is collapsed or cleaned: "a//b", "a/" and "a" are
+//     three distinct keys.
+//   - A path that does not start with "/", has an empty bucket ("//key"), a
+//     malformed escape, a "/" in the decoded bucket or a "." or ".." segment
+//     in the decoded key (encoded or not) is rejected with InvalidURI rather
+//     than being resolved, since resolving it would alias another bucket or
+//     key.
+//
+// The signature is computed over the same escaped path (see
+// sigv4.canonicalURI), so the request itself is never rewritten; handlers
+// re-derive bucket and key with splitPath.
+
+// pathKind is what a request path addresses.
+type pathKind int
+
+const (
+	pathService pathKind = iota
+	pathBucket
+	pathObject
+)
+
+func (k pathKind) String() string {
+	switch k {
+	case pathService:
+		return "service"
+	case pathBucket:
+		return "bucket"
+	default:
+		return "object"
+	}
+}
+
+// errInvalidPath marks a request path outside the routing spec.
+var errInvalidPath = errors.New("invalid request path")
+
+// parsePath splits an escaped request path into decoded bucket and key per
+// the spec above and reports what it addresses.
+func parsePath(escaped string) (bucket, key string, kind pathKind, err error) {
+	if escaped == "" {
+		return "", "", pathService, nil
+	}
+
+	if !strings.HasPrefix(escaped, "/") {
+		return "", "", 0, errors.Wrapf(errInvalidPath, "path %q must start with /", escaped)
+	}
+
+	rawBucket, rawKey, hasKey := strings.Cut(escaped[1:], "/")
+	if rawBucket == "" {
+		if !hasKey {
+			return "", "", pathService, nil
+		}
+
+		return "", "", 0, errors.Wrapf(errInvalidPath, "path %q has an empty bucket", escaped)
+	}
+
+	if bucket, err = url.PathUnescape(rawBucket); err != nil {
+		return "", "", 0, errors.Wrapf(errInvalidPath, "bucket %q: %v", rawBucket, err)
+	}
+
+	if key, err = url.PathUnescape(rawKey); err != nil {
+		return "", "", 0, errors.Wrapf(errInvalidPath, "key %q: %v", rawKey, err)
+	}
+
+	switch {
+	case bucket == "":
+		return "", "", 0, errors.Wrapf(errInvalidPath, "path %q has an empty bucket", escaped)
+	case strings.Contains(bucket, "/"):
+		return "", "", 0, errors.Wrapf(errInvalidPath, "bucket %q contains an encoded slash", bucket)
+	case isDotSegment(bucket):
+		return "", "", 0, errors.Wrapf(errInvalidPath, "bucket %q is a dot segment", bucket)
+	}
+
+	for segment := range strings.SplitSeq(key, "/") {
+		if isDotSegment(segment) {
+			return "", "", 0, errors.Wrapf(errInvalidPath, "key %q has a dot segment", key)
+		}
+	}
+
+	if key == "" {
+		return bucket, "", pathBucket, nil
+	}
+
+	return bucket, key, pathObject, nil
+}
+
+func isDotSegment(s string) bool { return s == "." || s == ".." }
+
+// splitPath returns the bucket and key addressed by r. The path has already
+// passed withValidPath, so it follows the spec.
+func splitPath(r *http.Request) (bucket, key string) {
+	bucket, key, _, _ = parsePath(r.URL.EscapedPath())
+	return bucket, key
+}
+
+// withValidPath rejects requests whose path is outside the routing spec
+// before any other middleware interprets it.
+func withValidPath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, _, err := parsePath(r.URL.EscapedPath()); err != nil {
+			renderAPIError(r.Context(), w, r, s3err.InvalidURI, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
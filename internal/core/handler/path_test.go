@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestParsePath(t *testing.T) {
+	get := func(t *testing.T, target string) (*http.Request, error) {
+		t.Helper()
+
+		u := &url.URL{} // "" is what http.StripPrefix leaves for "/"
+		if target != "" {
+			var err error
+			if u, err = url.ParseRequestURI(target); err != nil {
+				return nil, err
+			}
+		}
+		require.Equal(t, target, u.EscapedPath())
+
+		return &http.Request{Method: http.MethodGet, URL: u}, nil
+	}
+
+	for _, tt := range []struct {
+		target string // escaped path, as URL.EscapedPath returns it
+		bucket string
+		key    string
+		kind   pathKind
+		op     auth.Operation // of a GET to target
+		bad    bool
+	}{
+		{target: "/", kind: pathService, op: auth.OperationListBuckets},
+		{target: "", kind: pathService, op: auth.OperationListBuckets},
+		{target: "/bucket", bucket: "bucket", kind: pathBucket, op: auth.OperationListObjects},
+		{target: "/bucket/", bucket: "bucket", kind: pathBucket, op: auth.OperationListObjects},
+		{target: "/bucket/key", bucket: "bucket", key: "key", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/dir/key", bucket: "bucket", key: "dir/key", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/dir/", bucket: "bucket", key: "dir/", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket//key", bucket: "bucket", key: "/key", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a//b", bucket: "bucket", key: "a//b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a%2Fb", bucket: "bucket", key: "a/b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a%2F%2Fb", bucket: "bucket", key: "a//b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/buck%65t/key", bucket: "bucket", key: "key", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/%2541", bucket: "bucket", key: "%41", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a%20b", bucket: "bucket", key: "a b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a+b", bucket: "bucket", key: "a+b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/.hidden", bucket: "bucket", key: ".hidden", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/a..b", bucket: "bucket", key: "a..b", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket/...", bucket: "bucket", key: "...", kind: pathObject, op: auth.OperationGetObject},
+		{target: "/bucket%2Fkey", bad: true},
+		{target: "/bucket%2F", bad: true},
+		{target: "/bucket/a%ZZ", bad: true},
+		{target: "/bucket/a%2F..%2Fb", bad: true},
+		{target: "/%2E%2E/key", bad: true},
+		{target: "//key", bad: true},
+		{target: "/./key", bad: true},
+		{target: "/..", bad: true},
+		{target: "/bucket/.", bad: true},
+		{target: "/bucket/./key", bad: true},
+		{target: "/bucket/a/../b", bad: true},
+		{target: "/bucket/%2E%2E/b", bad: true},
+		{target: "/bucket/a/..", bad: true},
+		{target: "*", bad: true},
+	} {
+		t.Run(tt.target, func(t *testing.T) {
+			bucket, key, kind, err := parsePath(tt.target)
+			if tt.bad {
+				require.ErrorIs(t, err, errInvalidPath)
+				require.Empty(t, bucket)
+				require.Empty(t, key)
+
+				// A target net/http rejects never reaches routing at all.
+				if r, err := get(t, tt.target); err == nil {
+					require.Equal(t, auth.OperationUnknown, operationName(r))
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.bucket, bucket)
+			require.Equal(t, tt.key, key)
+			require.Equal(t, tt.kind, kind)
+
+			r, err := get(t, tt.target)
+			require.NoError(t, err)
+			require.Equal(t, tt.op, operationName(r))
+		})
+	}
+}
+
+func TestRouteKeepsKeysDistinct(t *testing.T) {
+	h := New(storagemem.New())
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a", "").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a/a//b", "double").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a/a/b", "single").Code)
+
+	// Not cleaned and redirected: each path reads back its own key.
+	got := serve(http.MethodGet, "/bucket-a/a//b", "")
+	require.Equal(t, http.StatusOK, got.Code)
+	require.Equal(t, "double", got.Body.String())
+	require.Equal(t, "single", serve(http.MethodGet, "/bucket-a/a%2Fb", "").Body.String())
+
+	rec := serve(http.MethodGet, "/bucket-a/a/../b", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>InvalidURI</Code>")
+}
+
+func TestRoutePercentEncodedKeys(t *testing.T) {
+	h := New(storagemem.New())
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a", "").Code)
+
+	// An encoded slash is part of the key, and the same key as a plain one.
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a/dir%2Fslash", "slash").Code)
+	require.Equal(t, "slash", serve(http.MethodGet, "/bucket-a/dir/slash", "").Body.String())
+
+	// It never moves the bucket boundary.
+	rec := serve(http.MethodGet, "/bucket-a%2Fdir/slash", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>InvalidURI</Code>")
+
+	// "%20" is a space; "+" is a plus, not a space.
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a/with%20space", "space").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/bucket-a/a+b", "plus").Code)
+	require.Equal(t, "space", serve(http.MethodGet, "/bucket-a/with%20space", "").Body.String())
+	require.Equal(t, "plus", serve(http.MethodGet, "/bucket-a/a%2Bb", "").Body.String())
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/bucket-a/a%20b", "").Code)
+
+	list := serve(http.MethodGet, "/bucket-a?list-type=2", "").Body.String()
+	for _, key := range []string{"<Key>dir/slash</Key>", "<Key>with space</Key>", "<Key>a+b</Key>"} {
+		require.Contains(t, list, key)
+	}
+}
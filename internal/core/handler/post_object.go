@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/auth"
+	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// maxPostFormFields bounds the total size of the fields preceding the file
+// in a POST object form, so a form cannot make the server buffer an
+// unbounded body before the upload starts.
+const maxPostFormFields = 1 << 20
+
+// PostResponse is the body returned for a form upload with
+// success_action_status 201.
+type PostResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// isFormUpload reports whether r is a POST object (browser form upload): a
+// multipart/form-data POST to a bucket that HandleBucketPost does not route
+// elsewhere (?delete, ?diff). Such a request carries its credentials in the
+// form, so authMiddleware lets it through unsigned; nothing else may pass
+// that way.
+func isFormUpload(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+
+	if _, key := splitPath(r); key != "" {
+		return false
+	}
+
+	query := r.URL.Query()
+	if query.Has("delete") || query.Has(diffParam) {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// postForm is a parsed POST object form: the fields preceding the file,
+// keyed by lowercase name, and the file part, positioned at its content.
+// Fields after the file are ignored, as S3 does.
+type postForm struct {
+	fields   map[string]string
+	file     *multipart.Part
+	filename string
+}
+
+func (f *postForm) field(name string) string { return f.fields[name] }
+
+// readPostForm reads the form of r up to and including the header of the
+// file part. form.file is nil when the form has no file.
+func readPostForm(r *http.Request) (*postForm, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	form := &postForm{fields: make(map[string]string)}
+	budget := int64(maxPostFormFields)
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return form, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "read form")
+		}
+
+		name := strings.ToLower(part.FormName())
+		if name == "file" {
+			form.file, form.filename = part, part.FileName()
+			return form, nil
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, budget+1))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read form field %q", name)
+		}
+
+		if budget -= int64(len(value)); budget < 0 {
+			return nil, errors.Errorf("form fields exceed %d bytes", maxPostFormFields)
+		}
+
+		if name != "" {
+			form.fields[name] = string(value)
+		}
+	}
+}
+
+// PostObject uploads the file of a browser form (POST object). The form's
+// key field names the object, with ${filename} standing for the uploaded
+// file's name; Content-Type, Cache-Control, Content-Disposition,
+// Content-Encoding, x-amz-meta-* and acl fields are stored as their header
+// counterparts. The response follows success_action_redirect (303 to it) or
+// success_action_status (200, 201 with a PostResponse, or the default 204).
+//
+// With an authenticator configured, a form not signed in its headers must
+// carry a SigV4-signed policy whose conditions it meets; an unsigned form is
+// treated as an anonymous write.
+func (h *handler) PostObject(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+
+	release, ok := h.acquireUpload(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+
+	form, err := readPostForm(r)
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.MalformedPOSTRequest, err)
+		return
+	}
+
+	if form.file == nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.New("POST requires exactly one file upload per request"))
+		return
+	}
+
+	if form.field("key") == "" {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.New("bucket POST must contain a field named 'key'"))
+		return
+	}
+
+	key := strings.ReplaceAll(form.field("key"), "${filename}", form.filename)
+
+	body := &postBody{r: form.file, maxLength: -1}
+	principal := principalFromContext(ctx)
+
+	if h.authenticator != nil && !hasSigV4Credentials(r) {
+		policy, accessKey, ok := h.authorizePost(w, r, bucket, key, form)
+		if !ok {
+			return
+		}
+
+		if policy != nil {
+			body.minLength, body.maxLength = policy.minLength, policy.maxLength
+		}
+
+		principal = accessKey
+	}
+
+	if h.authorizer != nil {
+		if err := h.authorizer(ctx, principal, auth.OperationPostObject, bucket, key); err != nil {
+			renderAPIError(ctx, w, r, s3err.AccessDenied, err)
+			return
+		}
+	}
+
+	header := make(http.Header)
+
+	for name, value := range form.fields {
+		switch {
+		case name == "content-type", name == "cache-control", name == "content-disposition", name == "content-encoding",
+			strings.HasPrefix(name, "x-amz-meta-"):
+			header.Set(name, value)
+		}
+	}
+
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", form.file.Header.Get("Content-Type"))
+	}
+
+	metadata, err := extractObjectMetadata(header)
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	}
+
+	// With WithNoOverwriteRename an upload onto an existing object is stored
+	// under a free derived key; the policy above was checked against key.
+	key, ifNoneMatch, err := h.renameOnConflict(ctx, w, bucket, key, "", "")
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	resp, err := h.service.PutObject(ctx, &fs.PutObjectRequest{
+		Reader:      body,
+		Bucket:      bucket,
+		Key:         key,
+		Size:        -1,
+		Metadata:    metadata,
+		ACL:         fs.ParseACL(form.field("acl")),
+		IfNoneMatch: ifNoneMatch,
+	})
+	if err != nil {
+		if body.err != nil {
+			renderAPIError(ctx, w, r, body.api, body.err)
+			return
+		}
+
+		renderError(ctx, w, r, err)
+
+		return
+	}
+
+	etag := quoteETag(resp.ETag)
+	location := (&url.URL{Scheme: requestScheme(r), Host: r.Host, Path: "/" + bucket + "/" + key}).String()
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Location", location)
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
+
+	h.emit(w, notify.ObjectCreatedPost, bucket, key, body.n, resp.ETag)
+
+	if redirect := form.field("success_action_redirect"); redirect != "" {
+		if target, err := url.Parse(redirect); err == nil && target.IsAbs() {
+			q := target.Query()
+			q.Set("bucket", bucket)
+			q.Set("key", key)
+			q.Set("etag", etag)
+			target.RawQuery = q.Encode()
+
+			w.Header().Set("Location", target.String())
+			w.WriteHeader(http.StatusSeeOther)
+
+			return
+		}
+	}
+
+	switch form.field("success_action_status") {
+	case "200":
+		w.WriteHeader(http.StatusOK)
+	case "201":
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusCreated)
+		_ = xml.NewEncoder(w).Encode(PostResponse{Location: location, Bucket: bucket, Key: key, ETag: etag})
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// authorizePost authenticates a form upload through its fields. A form with
+// a policy or signature must carry a valid SigV4 signature of the policy
+// from a key allowed to write to bucket, and meet the policy's conditions;
+// the policy is returned for its content-length-range, with the signing
+// access key. A form with neither is an anonymous write, allowed only as
+// anonymousAllowed allows it. On failure the error response is written and
+// ok is false.
+func (h *handler) authorizePost(
+	w http.ResponseWriter, r *http.Request, bucket, key string, form *postForm,
+) (policy *postPolicy, accessKey string, ok bool) {
+	ctx := r.Context()
+
+	if form.field("policy") == "" && form.field("x-amz-signature") == "" {
+		if anonymousAllowed(ctx, h.service, h.authenticator, bucket, key, auth.ActionWrite) {
+			return nil, "", true
+		}
+
+		s3err.WriteAPI(w, r, s3err.AccessDenied)
+
+		return nil, "", false
+	}
+
+	res, err := h.formVerifier.VerifyPostPolicy(form.field)
+	if err != nil {
+		writeAuthError(w, r, err)
+		return nil, "", false
+	}
+
+	if !h.authenticator.Allow(res.AccessKey, bucket, auth.ActionWrite) {
+		s3err.WriteAPI(w, r, s3err.AccessDenied)
+		return nil, "", false
+	}
+
+	policy, err = parsePostPolicy(form.field("policy"))
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidPolicyDocument, err)
+		return nil, "", false
+	}
+
+	if err := policy.check(form.fields, bucket, time.Now()); err != nil {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, err)
+		return nil, "", false
+	}
+
+	return policy, res.AccessKey, true
+}
+
+// postBody streams the file of a form upload, counting it and failing the
+// upload once it leaves the policy's content-length-range, so an object
+// outside the range is never stored.
+type postBody struct {
+	r                    io.Reader
+	n                    int64
+	minLength, maxLength int64 // maxLength -1: unbounded
+
+	api s3err.APIError
+	err error
+}
+
+func (b *postBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+
+	switch {
+	case b.maxLength >= 0 && b.n > b.maxLength:
+		b.api, b.err = s3err.EntityTooLarge, errors.Errorf("file exceeds the policy maximum of %d bytes", b.maxLength)
+		return n, b.err
+	case errors.Is(err, io.EOF) && b.n < b.minLength:
+		b.api, b.err = s3err.EntityTooSmall, errors.Errorf("file is below the policy minimum of %d bytes", b.minLength)
+		return n, b.err
+	}
+
+	return n, err
+}
+
+// requestScheme returns the scheme r arrived over.
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+)
+
+// postForm posts a multipart form with fields (in order) and a file part
+// named filename holding content to the bucket URL target.
+func postForm(t *testing.T, h http.Handler, target string, fields [][2]string, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	for _, f := range fields {
+		require.NoError(t, mw.WriteField(f[0], f[1]))
+	}
+
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestPostObject(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	t.Run("StoresUnderKey", func(t *testing.T) {
+		rec := postForm(t, h, "/bucket-a", [][2]string{
+			{"key", "uploads/photo.jpg"},
+			{"Content-Type", "image/jpeg"},
+			{"x-amz-meta-owner", "alice"},
+		}, "local.jpg", "jpeg bytes")
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		require.NotEmpty(t, rec.Header().Get("ETag"))
+
+		got := do(t, h, http.MethodGet, "/bucket-a/uploads/photo.jpg", "", nil)
+		require.Equal(t, http.StatusOK, got.Code)
+		require.Equal(t, "jpeg bytes", got.Body.String())
+		require.Equal(t, "image/jpeg", got.Header().Get("Content-Type"))
+		require.Equal(t, []string{"alice"}, got.Header()["x-amz-meta-owner"])
+		require.Equal(t, rec.Header().Get("ETag"), got.Header().Get("ETag"))
+	})
+
+	t.Run("FilenameVariable", func(t *testing.T) {
+		rec := postForm(t, h, "/bucket-a", [][2]string{{"key", "user/${filename}"}}, "notes.txt", "notes")
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		got := do(t, h, http.MethodGet, "/bucket-a/user/notes.txt", "", nil)
+		require.Equal(t, http.StatusOK, got.Code)
+		require.Equal(t, "notes", got.Body.String())
+	})
+
+	t.Run("Status201", func(t *testing.T) {
+		rec := postForm(t, h, "/bucket-a", [][2]string{
+			{"key", "created.txt"},
+			{"success_action_status", "201"},
+		}, "created.txt", "data")
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var resp handler.PostResponse
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Equal(t, "bucket-a", resp.Bucket)
+		require.Equal(t, "created.txt", resp.Key)
+		require.Equal(t, rec.Header().Get("ETag"), resp.ETag)
+		require.Equal(t, "http://example.com/bucket-a/created.txt", resp.Location)
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		rec := postForm(t, h, "/bucket-a", [][2]string{
+			{"key", "redirected.txt"},
+			{"success_action_redirect", "https://app.example.com/done?from=form"},
+		}, "redirected.txt", "data")
+		require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+
+		loc, err := url.Parse(rec.Header().Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, "app.example.com", loc.Host)
+		require.Equal(t, "form", loc.Query().Get("from"))
+		require.Equal(t, "bucket-a", loc.Query().Get("bucket"))
+		require.Equal(t, "redirected.txt", loc.Query().Get("key"))
+		require.Equal(t, rec.Header().Get("ETag"), loc.Query().Get("etag"))
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		rec := postForm(t, h, "/bucket-a", nil, "f.txt", "data")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("NoSuchBucket", func(t *testing.T) {
+		rec := postForm(t, h, "/missing-bucket", [][2]string{{"key", "k"}}, "f.txt", "data")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "NoSuchBucket", errorCode(t, rec.Body.String()))
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// errPolicyViolation marks a form that the signed policy does not allow:
+// expired, or with a field outside the policy's conditions. S3 answers it
+// with AccessDenied.
+var errPolicyViolation = errors.New("invalid according to policy")
+
+// postPolicy is a decoded POST object policy document.
+type postPolicy struct {
+	expiration time.Time
+	conditions []postCondition
+	// minLength and maxLength bound the file size when the policy has a
+	// content-length-range condition; maxLength is -1 otherwise.
+	minLength, maxLength int64
+}
+
+// postCondition is one field condition of a policy: the form field (lowercase,
+// without the "$") must equal value, or start with it for "starts-with".
+type postCondition struct {
+	field      string
+	value      string
+	startsWith bool
+}
+
+func (c postCondition) matches(v string) bool {
+	if c.startsWith {
+		return strings.HasPrefix(v, c.value)
+	}
+
+	return v == c.value
+}
+
+// parsePostPolicy decodes the base64 JSON policy document of a form upload.
+// Conditions come either as {"field": "value"} objects (exact match) or as
+// ["eq" | "starts-with", "$field", "value"] and
+// ["content-length-range", min, max] arrays.
+func parsePostPolicy(encoded string) (*postPolicy, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode policy")
+	}
+
+	var doc struct {
+		Expiration string            `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrap(err, "parse policy")
+	}
+
+	expiration, err := time.Parse(time.RFC3339, doc.Expiration)
+	if err != nil {
+		return nil, errors.Errorf("invalid policy expiration %q", doc.Expiration)
+	}
+
+	p := &postPolicy{expiration: expiration, maxLength: -1}
+
+	for _, rawCond := range doc.Conditions {
+		if err := p.addCondition(rawCond); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *postPolicy) addCondition(raw json.RawMessage) error {
+	var exact map[string]string
+	if err := json.Unmarshal(raw, &exact); err == nil {
+		if len(exact) != 1 {
+			return errors.Errorf("policy condition %s must name one field", raw)
+		}
+
+		for field, value := range exact {
+			p.conditions = append(p.conditions, postCondition{field: strings.ToLower(field), value: value})
+		}
+
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil || len(list) != 3 {
+		return errors.Errorf("malformed policy condition %s", raw)
+	}
+
+	var op string
+	if err := json.Unmarshal(list[0], &op); err != nil {
+		return errors.Errorf("malformed policy condition %s", raw)
+	}
+
+	if strings.EqualFold(op, "content-length-range") {
+		if json.Unmarshal(list[1], &p.minLength) != nil || json.Unmarshal(list[2], &p.maxLength) != nil ||
+			p.minLength < 0 || p.maxLength < p.minLength {
+			return errors.Errorf("invalid content-length-range %s", raw)
+		}
+
+		return nil
+	}
+
+	var field, value string
+	if json.Unmarshal(list[1], &field) != nil || json.Unmarshal(list[2], &value) != nil || !strings.HasPrefix(field, "$") {
+		return errors.Errorf("malformed policy condition %s", raw)
+	}
+
+	cond := postCondition{field: strings.ToLower(field[1:]), value: value}
+
+	switch strings.ToLower(op) {
+	case "eq":
+	case "starts-with":
+		cond.startsWith = true
+	default:
+		return errors.Errorf("unknown policy condition %q", op)
+	}
+
+	p.conditions = append(p.conditions, cond)
+
+	return nil
+}
+
+// check verifies the form against the policy at now: the policy must not have
+// expired, every condition must hold, and every field of the form must be
+// covered by a condition except the ones S3 exempts (the policy and signature
+// themselves, the file and x-ignore-* fields). The bucket is checked as if it
+// were a form field.
+func (p *postPolicy) check(fields map[string]string, bucket string, now time.Time) error {
+	if !now.Before(p.expiration) {
+		return errors.Wrap(errPolicyViolation, "policy expired")
+	}
+
+	lookup := func(name string) string {
+		if name == "bucket" {
+			return bucket
+		}
+
+		return fields[name]
+	}
+
+	covered := make(map[string]bool, len(p.conditions))
+
+	for _, c := range p.conditions {
+		if !c.matches(lookup(c.field)) {
+			return errors.Wrapf(errPolicyViolation, "condition failed: %s", c.field)
+		}
+
+		covered[c.field] = true
+	}
+
+	for name := range fields {
+		if covered[name] || exemptFromPolicy(name) {
+			continue
+		}
+
+		return errors.Wrapf(errPolicyViolation, "extra input field: %s", name)
+	}
+
+	return nil
+}
+
+// exemptFromPolicy reports whether a form field may be sent without a policy
+// condition covering it.
+func exemptFromPolicy(name string) bool {
+	switch name {
+	case "policy", "x-amz-signature", "file":
+		return true
+	default:
+		return strings.HasPrefix(name, "x-ignore-")
+	}
+}
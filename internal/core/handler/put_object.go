@@ -4,12 +4,38 @@ import (
 	"io"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
 )
 
+// lastModifiedHeader is the extension header a PUT uses to supply the object's
+// modification time, for migrations that must keep historical timestamps.
+const lastModifiedHeader = "X-Fs-Last-Modified"
+
+// parseLastModified parses the lastModifiedHeader value, accepting an HTTP
+// date or RFC 3339. An absent header yields the zero time (write time).
+func parseLastModified(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s %q", lastModifiedHeader, v)
+	}
+
+	return t, nil
+}
+
 // getBodyReader returns the appropriate reader for the request body,
 // handling AWS chunked encoding if necessary.
 func getBodyReader(r *http.Request) io.Reader {
@@ -37,10 +63,24 @@ func getDecodedContentLength(r *http.Request) int64 {
 	return size
 }
 
+// hasDeclaredLength reports whether the request says how long its body is:
+// a Content-Length header (possibly zero) or a chunked Transfer-Encoding,
+// which net/http reports as an unknown ContentLength. A request with neither
+// arrives with ContentLength 0, indistinguishable from an empty body by that
+// field alone, so the header itself is checked.
+func hasDeclaredLength(r *http.Request) bool {
+	return r.ContentLength != 0 || len(r.TransferEncoding) > 0 || r.Header.Get("Content-Length") != ""
+}
+
 func (h *handler) PutObject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
+
+	release, ok := h.acquireUpload(w, r)
+	if !ok {
+		return
+	}
+	defer release()
 
 	// Check if this is an upload part request (with x-amz-copy-source it is an
 	// UploadPartCopy).
@@ -62,16 +102,60 @@ func (h *handler) PutObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if source := r.Header.Get(sourceURLHeader); source != "" {
+		h.IngestObject(w, r, bucket, key, source)
+		return
+	}
+
+	if !hasDeclaredLength(r) {
+		renderAPIError(ctx, w, r, s3err.MissingContentLength,
+			errors.New("PUT without Content-Length or chunked Transfer-Encoding"))
+		return
+	}
+
+	// Content-Range on a PUT is an extension that patches part of an
+	// existing object.
+	if r.Header.Get("Content-Range") != "" {
+		h.PutObjectRange(w, r, bucket, key)
+		return
+	}
+
 	tags, err := parseTaggingHeader(r.Header.Get("X-Amz-Tagging"))
 	if err != nil {
 		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
 		return
 	}
 
-	// Handle AWS chunked encoding.
-	reader := getBodyReader(r)
+	lastModified, err := parseLastModified(r.Header.Get(lastModifiedHeader))
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	}
+
+	metadata, err := extractObjectMetadata(r.Header)
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	}
+
+	ctx, ok = withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
+	// Handle AWS chunked encoding; a checksum trailer is verified as the body
+	// streams through.
+	reader, checksum := withTrailerChecksum(r, getBodyReader(r))
 	size := getDecodedContentLength(r)
 
+	// A declared empty body creates an empty object: the storage is given a
+	// reader that yields nothing rather than whatever the connection holds,
+	// so the object is zero bytes with the empty-content ETag. A key ending
+	// in "/" is no different: a folder marker is just an empty object.
+	if r.ContentLength == 0 {
+		reader, size = http.NoBody, 0
+	}
+
 	// If-Match / If-None-Match are forwarded to the storage layer, which
 	// evaluates them atomically with the write so concurrent conditional PUTs
 	// resolve to a single winner. On failure it returns ErrPreconditionFailed,
@@ -81,19 +165,37 @@ func (h *handler) PutObject(w http.ResponseWriter, r *http.Request) {
 		Bucket:      bucket,
 		Key:         key,
 		Size:        size,
-		Metadata:    extractObjectMetadata(r.Header),
+		Metadata:    metadata,
 		Tags:        tags,
 		ACL:         fs.ParseACL(r.Header.Get("X-Amz-Acl")),
 		IfNoneMatch: r.Header.Get("If-None-Match"),
 		IfMatch:     r.Header.Get("If-Match"),
+
+		LastModified: lastModified,
+	}
+
+	// With WithNoOverwriteRename an unconditional PUT is stored under a free
+	// derived key rather than replacing an existing object.
+	if req.Key, req.IfNoneMatch, err = h.renameOnConflict(ctx, w, bucket, key, req.IfNoneMatch, req.IfMatch); err != nil {
+		renderError(ctx, w, r, err)
+		return
 	}
 
 	resp, err := h.service.PutObject(ctx, req)
 	if err != nil {
+		if cerr := checksum.mismatch(); cerr != nil {
+			renderAPIError(ctx, w, r, s3err.BadDigest, cerr)
+			return
+		}
+
 		renderError(ctx, w, r, err)
+
 		return
 	}
 
 	w.Header().Set("ETag", quoteETag(resp.ETag))
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
 	w.WriteHeader(http.StatusOK)
+
+	h.emit(w, notify.ObjectCreatedPut, bucket, req.Key, size, resp.ETag)
 }
@@ -2,11 +2,18 @@ package handler_test
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagefs"
 )
 
 // TestPutObject_IfNoneMatchConcurrentSingleWinner is the regression test for the
@@ -111,3 +118,58 @@ func TestPutObject_IfMatchConcurrentSingleWinner(t *testing.T) {
 	require.Equal(t, 1, winners, "exactly one CAS racer must win, got codes: %v", codes)
 	require.Equal(t, racers-1, codes[http.StatusPreconditionFailed], "losers must all get 412")
 }
+
+func TestPutObject_MaxConcurrentUploads(t *testing.T) {
+	const slots = 2
+
+	// storagefs streams bodies without holding a lock, so the uploads below
+	// really run side by side.
+	store, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	h := handler.New(service.New(store), handler.WithMaxConcurrentUploads(slots))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/existing", "x", nil).Code)
+
+	// Occupy every slot with an upload whose body is still arriving.
+	var (
+		wg      sync.WaitGroup
+		writers []*io.PipeWriter
+	)
+
+	for i := range slots {
+		pr, pw := io.Pipe()
+		writers = append(writers, pw)
+
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/bucket-a/slow-%d", i), pr)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+
+		// The write returns once the backend is reading the body, i.e. the
+		// upload holds its slot.
+		_, err := pw.Write([]byte("x"))
+		require.NoError(t, err)
+	}
+
+	rec := do(t, h, http.MethodPut, "/bucket-a/one-too-many", "x", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>SlowDown</Code>")
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Reads are not gated.
+	rec = do(t, h, http.MethodGet, "/bucket-a/existing", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "x", rec.Body.String())
+
+	for _, pw := range writers {
+		require.NoError(t, pw.Close())
+	}
+
+	wg.Wait()
+
+	// Finished uploads give their slots back.
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/one-too-many", "x", nil).Code)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// parseContentRange parses a PUT Content-Range of the form "bytes S-E/T",
+// where T is the complete length or "*", and returns the first offset and
+// the length of the range.
+func parseContentRange(v string) (offset, length int64, err error) {
+	spec, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range %q", v)
+	}
+
+	spec, _, ok = strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range %q", v)
+	}
+
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range %q", v)
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errors.Errorf("invalid Content-Range %q", v)
+	}
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, errors.Errorf("invalid Content-Range %q", v)
+	}
+
+	return start, end - start + 1, nil
+}
+
+// PutObjectRange handles a PUT carrying a Content-Range header, an extension
+// that overwrites part of an existing object in place (see
+// fs.PutObjectRange). The body must be exactly the range's length; a range
+// starting past the object's end is 416 InvalidRange.
+func (h *handler) PutObjectRange(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	ctx := r.Context()
+
+	offset, length, err := parseContentRange(r.Header.Get("Content-Range"))
+	if err != nil {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	}
+
+	if size := getDecodedContentLength(r); size != length {
+		renderAPIError(ctx, w, r, s3err.InvalidArgument,
+			errors.Errorf("body of %d bytes for a %d-byte Content-Range", size, length))
+		return
+	}
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
+	body := io.LimitReader(getBodyReader(r), length)
+
+	resp, err := fs.PutObjectRange(ctx, h.service, bucket, key, offset, body, length)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(resp.ETag))
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
+	w.WriteHeader(http.StatusOK)
+
+	h.emit(w, notify.ObjectCreatedPut, bucket, key, -1, resp.ETag)
+}
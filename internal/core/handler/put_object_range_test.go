@@ -0,0 +1,66 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_PutObjectRange(t *testing.T) {
+	h := newStorageHandler(t)
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket/file", "0123456789", nil).Code)
+
+	get := func() string {
+		t.Helper()
+
+		rec := do(t, h, http.MethodGet, "/bucket/file", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return rec.Body.String()
+	}
+
+	t.Run("InPlace", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/file", "ab", map[string]string{"Content-Range": "bytes 2-3/*"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, "01ab456789", get())
+
+		head := do(t, h, http.MethodHead, "/bucket/file", "", nil)
+		require.Equal(t, rec.Header().Get("ETag"), head.Header().Get("ETag"))
+		require.Equal(t, "10", head.Header().Get("Content-Length"))
+	})
+
+	t.Run("Extend", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/file", "XYZW", map[string]string{"Content-Range": "bytes 8-11/12"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, "01ab4567XYZW", get())
+
+		head := do(t, h, http.MethodHead, "/bucket/file", "", nil)
+		require.Equal(t, "12", head.Header().Get("Content-Length"))
+	})
+
+	t.Run("PastEnd", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/file", "x", map[string]string{"Content-Range": "bytes 20-20/*"})
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		require.Equal(t, "InvalidRange", errorCode(t, rec.Body.String()))
+		require.Equal(t, "01ab4567XYZW", get())
+	})
+
+	t.Run("LengthMismatch", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/file", "xyz", map[string]string{"Content-Range": "bytes 0-1/*"})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "InvalidArgument", errorCode(t, rec.Body.String()))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/file", "x", map[string]string{"Content-Range": "items 0-0/*"})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket/missing", "x", map[string]string{"Content-Range": "bytes 0-0/*"})
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
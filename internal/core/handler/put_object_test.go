@@ -3,8 +3,13 @@ package handler_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -52,6 +57,8 @@ func putRequest(t testing.TB, bucket, key, body string, headers map[string]strin
 	t.Helper()
 
 	req := httptest.NewRequest(http.MethodPut, "/"+bucket+"/"+key, strings.NewReader(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
@@ -130,3 +137,169 @@ func TestPutObject_ConditionalIfMatch(t *testing.T) {
 	require.Equal(t, http.StatusPreconditionFailed,
 		do(t, h, http.MethodPut, "/bucket-a/missing", "x", map[string]string{"If-Match": "*"}).Code)
 }
+
+func TestPutObject_LastModifiedHeader(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	const stamp = "Sat, 14 Mar 2015 09:26:53 GMT"
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/old", "v1", map[string]string{
+		"X-Fs-Last-Modified": stamp,
+	}).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/iso", "v1", map[string]string{
+		"X-Fs-Last-Modified": "2015-03-14T09:26:53Z",
+	}).Code)
+
+	for _, key := range []string{"old", "iso"} {
+		head := do(t, h, http.MethodHead, "/bucket-a/"+key, "", nil)
+		require.Equal(t, http.StatusOK, head.Code)
+		require.Equal(t, stamp, head.Header().Get("Last-Modified"))
+	}
+
+	list := do(t, h, http.MethodGet, "/bucket-a?list-type=2", "", nil)
+	require.Equal(t, http.StatusOK, list.Code)
+	require.Equal(t, 2, strings.Count(list.Body.String(), "<LastModified>2015-03-14T09:26:53Z</LastModified>"), list.Body.String())
+
+	// An unparseable timestamp is rejected rather than silently ignored.
+	rec := do(t, h, http.MethodPut, "/bucket-a/bad", "v1", map[string]string{"X-Fs-Last-Modified": "yesterday"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "InvalidArgument")
+}
+
+func TestPutObject_KeyTooLong(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/"+strings.Repeat("k", 1024), "x", nil).Code)
+
+	rec := do(t, h, http.MethodPut, "/bucket-a/"+strings.Repeat("k", 1025), "x", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>KeyTooLongError</Code>")
+}
+
+func TestPutObject_InvalidNames(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	rec := do(t, h, http.MethodPut, "/Bucket_A", "", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>InvalidBucketName</Code>")
+
+	rec = do(t, h, http.MethodPut, "/bucket-a/dir%5Cfile", "x", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>InvalidArgument</Code>")
+}
+
+func TestPutObject_TrailerChecksum(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	const body = "hello, trailer"
+
+	sum := crc32.Checksum([]byte(body), crc32.MakeTable(crc32.Castagnoli))
+	good := base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, sum))
+
+	// put sends body chunked (unknown length) with the checksum as a trailer.
+	put := func(t *testing.T, key, checksum string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPut, srv.URL+"/bucket-a/"+key,
+			strings.NewReader(body))
+		require.NoError(t, err)
+
+		req.ContentLength = -1
+		req.Trailer = http.Header{"X-Amz-Checksum-Crc32c": {checksum}}
+
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+
+		return resp
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		resp := put(t, "good", good)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		rec := do(t, h, http.MethodGet, "/bucket-a/good", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		resp := put(t, "bad", "AAAAAA==")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "<Code>BadDigest</Code>")
+
+		// The object was never committed.
+		require.Equal(t, http.StatusNotFound, do(t, h, http.MethodGet, "/bucket-a/bad", "", nil).Code)
+	})
+}
+
+func TestPutObject_Empty(t *testing.T) {
+	const emptyETag = `"d41d8cd98f00b204e9800998ecf8427e"`
+
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	// "photos" is an empty object, "photos/" a folder marker: two keys.
+	for _, key := range []string{"photos", "photos/"} {
+		req := putRequest(t, "bucket-a", key, "", nil)
+		require.Zero(t, req.ContentLength)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, emptyETag, rec.Header().Get("ETag"))
+
+		rec = do(t, h, http.MethodHead, "/bucket-a/"+key, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "0", rec.Header().Get("Content-Length"))
+		require.Equal(t, emptyETag, rec.Header().Get("ETag"))
+
+		rec = do(t, h, http.MethodGet, "/bucket-a/"+key, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Body.String())
+		require.Equal(t, emptyETag, rec.Header().Get("ETag"))
+	}
+
+	result := listBucket(t, h, "bucket-a", "")
+	require.Len(t, result.Contents, 2)
+
+	for i, key := range []string{"photos", "photos/"} {
+		require.Equal(t, key, result.Contents[i].Key)
+		require.Zero(t, result.Contents[i].Size)
+		require.Equal(t, emptyETag, result.Contents[i].ETag)
+	}
+}
+
+func TestPutObject_MissingContentLength(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	// Neither Content-Length nor chunked Transfer-Encoding.
+	req := httptest.NewRequest(http.MethodPut, "/bucket-a/key", nil)
+	require.Empty(t, req.Header.Get("Content-Length"))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusLengthRequired, rec.Code)
+	require.Equal(t, "MissingContentLength", errorCode(t, rec.Body.String()))
+	require.Equal(t, http.StatusNotFound, do(t, h, http.MethodHead, "/bucket-a/key", "", nil).Code)
+
+	// A chunked body declares no length up front but is accepted.
+	req = httptest.NewRequest(http.MethodPut, "/bucket-a/key", strings.NewReader("data"))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
@@ -34,6 +34,8 @@ func TestGetObject_Range(t *testing.T) {
 	t.Run("Unsatisfiable", func(t *testing.T) {
 		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=100-200"})
 		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		require.Equal(t, "InvalidRange", errorCode(t, rec.Body.String()))
+		require.Equal(t, "bytes */10", rec.Header().Get("Content-Range"))
 	})
 
 	t.Run("Full", func(t *testing.T) {
@@ -43,3 +45,103 @@ func TestGetObject_Range(t *testing.T) {
 		require.NotEmpty(t, rec.Header().Get("ETag"))
 	})
 }
+
+func TestHeadObject_Range(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/obj", "0123456789", nil).Code)
+
+	t.Run("Satisfiable", func(t *testing.T) {
+		rec := do(t, h, http.MethodHead, "/bucket-a/obj", "", map[string]string{"Range": "bytes=2-5"})
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+		require.Equal(t, "bytes 2-5/10", rec.Header().Get("Content-Range"))
+		require.Equal(t, "4", rec.Header().Get("Content-Length"))
+		require.Empty(t, rec.Body.String())
+
+		// Same headers as the matching GET.
+		get := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=2-5"})
+		require.Equal(t, get.Header().Get("Content-Range"), rec.Header().Get("Content-Range"))
+		require.Equal(t, get.Header().Get("Content-Length"), rec.Header().Get("Content-Length"))
+		require.Equal(t, get.Header().Get("ETag"), rec.Header().Get("ETag"))
+	})
+
+	t.Run("Unsatisfiable", func(t *testing.T) {
+		rec := do(t, h, http.MethodHead, "/bucket-a/obj", "", map[string]string{"Range": "bytes=100-200"})
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code)
+		require.Equal(t, "bytes */10", rec.Header().Get("Content-Range"))
+		require.Empty(t, rec.Body.String())
+		require.Empty(t, rec.Header().Get("Content-Type"))
+	})
+}
+
+func TestGetObject_IfRangeResume(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/obj", "0123456789", nil).Code)
+
+	// The interrupted download: the client got the validators and some bytes.
+	first := do(t, h, http.MethodGet, "/bucket-a/obj", "", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	etag := first.Header().Get("ETag")
+
+	t.Run("Unchanged", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=4-", "If-Range": etag})
+		require.Equal(t, http.StatusPartialContent, rec.Code)
+		require.Equal(t, "456789", rec.Body.String())
+		require.Equal(t, "bytes 4-9/10", rec.Header().Get("Content-Range"))
+	})
+
+	t.Run("WeakETag", func(t *testing.T) {
+		// If-Range needs a strong match; a weak validator gets the whole object.
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=4-", "If-Range": "W/" + etag})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "0123456789", rec.Body.String())
+	})
+
+	// The object changes before the client resumes.
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/obj", "abcdefghijklmnop", nil).Code)
+
+	t.Run("ChangedETag", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=4-", "If-Range": etag})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "abcdefghijklmnop", rec.Body.String())
+		require.Empty(t, rec.Header().Get("Content-Range"))
+		require.NotEqual(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("ChangedDate", func(t *testing.T) {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{
+			"Range":    "bytes=4-",
+			"If-Range": "Mon, 02 Jan 2006 15:04:05 GMT",
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "abcdefghijklmnop", rec.Body.String())
+	})
+}
+
+func TestGetObject_MultipleRanges(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/obj", "0123456789", nil).Code)
+
+	// Like S3, several ranges get the whole object rather than a
+	// multipart/byteranges body, for GET and HEAD alike.
+	for _, spec := range []string{"bytes=0-1,4-5", "bytes=0-1, 8-", "bytes=-2,0-0", "bytes=100-200,300-400"} {
+		rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": spec})
+		require.Equal(t, http.StatusOK, rec.Code, spec)
+		require.Equal(t, "0123456789", rec.Body.String(), spec)
+		require.Empty(t, rec.Header().Get("Content-Range"), spec)
+		require.Equal(t, "10", rec.Header().Get("Content-Length"), spec)
+		require.NotContains(t, rec.Header().Get("Content-Type"), "multipart/byteranges", spec)
+
+		head := do(t, h, http.MethodHead, "/bucket-a/obj", "", map[string]string{"Range": spec})
+		require.Equal(t, http.StatusOK, head.Code, spec)
+		require.Equal(t, "10", head.Header().Get("Content-Length"), spec)
+	}
+
+	// A single range is still honored.
+	rec := do(t, h, http.MethodGet, "/bucket-a/obj", "", map[string]string{"Range": "bytes=4-5"})
+	require.Equal(t, http.StatusPartialContent, rec.Code)
+	require.Equal(t, "45", rec.Body.String())
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"container/list"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// maxRateLimitedClients bounds the per-IP limiter table. When it is full the
+// least recently seen client's limiter is evicted; that client simply starts
+// over with a full bucket, which errs on the side of serving.
+const maxRateLimitedClients = 10_000
+
+// rateLimit configures per-client-IP request throttling.
+type rateLimit struct {
+	perIP   rate.Limit
+	burst   int
+	trusted []netip.Prefix
+}
+
+// ipLimiters is a bounded LRU of token-bucket limiters keyed by client IP.
+type ipLimiters struct {
+	cfg rateLimit
+	max int
+
+	mu      sync.Mutex
+	entries map[netip.Addr]*list.Element
+	lru     *list.List // front = most recently seen; values are *ipLimiter.
+}
+
+type ipLimiter struct {
+	addr    netip.Addr
+	limiter *rate.Limiter
+}
+
+func newIPLimiters(cfg rateLimit, maxEntries int) *ipLimiters {
+	return &ipLimiters{
+		cfg:     cfg,
+		max:     maxEntries,
+		entries: make(map[netip.Addr]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// get returns the limiter for addr, creating it (and evicting the least
+// recently seen entry if the table is full) when absent.
+func (l *ipLimiters) get(addr netip.Addr) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.entries[addr]; ok {
+		l.lru.MoveToFront(e)
+
+		return e.Value.(*ipLimiter).limiter
+	}
+
+	if l.lru.Len() >= l.max {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.entries, oldest.Value.(*ipLimiter).addr)
+	}
+
+	lim := rate.NewLimiter(l.cfg.perIP, l.cfg.burst)
+	l.entries[addr] = l.lru.PushFront(&ipLimiter{addr: addr, limiter: lim})
+
+	return lim
+}
+
+// len reports the number of tracked clients.
+func (l *ipLimiters) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Len()
+}
+
+// rateLimitMiddleware throttles each client IP to its token bucket, answering
+// excess requests with 503 SlowDown and a Retry-After hint. Requests whose
+// client address cannot be determined are not limited.
+func rateLimitMiddleware(limiters *ipLimiters, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := clientIP(r, limiters.cfg.trusted)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res := limiters.get(addr).Reserve()
+		if delay := res.Delay(); delay > 0 {
+			res.Cancel()
+
+			w.Header().Set("Retry-After", retryAfterSeconds(delay))
+			s3err.WriteAPI(w, r, s3err.SlowDown)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// retryAfterSeconds renders a delay as a Retry-After value: whole seconds,
+// rounded up, at least 1. An infinite delay (a zero-burst limiter) also
+// reports 1, since no wait would ever succeed.
+func retryAfterSeconds(d time.Duration) string {
+	if d == rate.InfDuration {
+		return "1"
+	}
+
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
+
+// clientIP resolves the address a request is attributed to. The TCP peer is
+// authoritative unless it is a trusted proxy, in which case X-Forwarded-For is
+// walked from the right, skipping further trusted hops, and the first
+// untrusted address wins — so a client cannot spoof its address by prepending
+// entries.
+func clientIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	peer = peer.Unmap()
+	if !isTrusted(peer, trusted) {
+		return peer, true
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		hop = hop.Unmap()
+		if !isTrusted(hop, trusted) {
+			return hop, true
+		}
+	}
+
+	return peer, true
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestRateLimitPerIP(t *testing.T) {
+	h := New(storagemem.New(), WithRateLimit(rate.Every(time.Hour), 2))
+
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	for range 2 {
+		require.Equal(t, http.StatusOK, get("192.0.2.1:1000").Code)
+	}
+
+	rec := get("192.0.2.1:1001")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>SlowDown</Code>")
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// Another client is unaffected.
+	require.Equal(t, http.StatusOK, get("192.0.2.2:1000").Code)
+}
+
+func TestRateLimitTrustedProxy(t *testing.T) {
+	h := New(storagemem.New(),
+		WithRateLimit(rate.Every(time.Hour), 1),
+		WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8")),
+	)
+
+	get := func(remote, xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = remote
+		req.Header.Set("X-Forwarded-For", xff)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// Two clients behind the same proxy get separate buckets.
+	require.Equal(t, http.StatusOK, get("10.0.0.1:80", "198.51.100.1"))
+	require.Equal(t, http.StatusOK, get("10.0.0.1:80", "198.51.100.2"))
+	require.Equal(t, http.StatusServiceUnavailable, get("10.0.0.1:80", "198.51.100.1"))
+
+	// A spoofed left-most entry does not escape the limit.
+	require.Equal(t, http.StatusServiceUnavailable, get("10.0.0.1:80", "203.0.113.9, 198.51.100.2"))
+
+	// An untrusted peer's header is ignored.
+	require.Equal(t, http.StatusOK, get("192.0.2.7:80", "198.51.100.3"))
+	require.Equal(t, http.StatusServiceUnavailable, get("192.0.2.7:80", "198.51.100.4"))
+}
+
+func TestIPLimitersBounded(t *testing.T) {
+	l := newIPLimiters(rateLimit{perIP: 1, burst: 1}, 3)
+
+	for i := range 10 {
+		l.get(netip.AddrFrom4([4]byte{192, 0, 2, byte(i)}))
+	}
+
+	require.Equal(t, 3, l.len())
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// finalKeyHeader reports, URL-encoded like encoding-type=url listings, the key
+// a write was stored under with WithNoOverwriteRename.
+const finalKeyHeader = "X-Fs-Key"
+
+// maxConflictRenames bounds how many derived keys a write tries before giving up.
+const maxConflictRenames = 10000
+
+// conflictKey returns the n-th derived key for key, numbered like a file
+// manager names a copy: "dir/report (2).pdf" for "dir/report.pdf". The number
+// goes before the extension of the last path segment; n == 0 is key itself.
+func conflictKey(key string, n int) string {
+	if n == 0 {
+		return key
+	}
+
+	dir, name := "", key
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		dir, name = key[:i+1], key[i+1:]
+	}
+
+	ext := path.Ext(name)
+	if ext == name {
+		// A dotfile such as ".env" has no extension to keep.
+		ext = ""
+	}
+
+	return dir + strings.TrimSuffix(name, ext) + " (" + strconv.Itoa(n) + ")" + ext
+}
+
+// freeKey returns the first of key, "key (1)", "key (2)", ... that names no
+// object in bucket. The caller must still write it with If-None-Match: * so a
+// concurrent upload that takes the same name first fails instead of being
+// overwritten.
+func (h *handler) freeKey(ctx context.Context, bucket, key string) (string, error) {
+	for n := range maxConflictRenames {
+		candidate := conflictKey(key, n)
+
+		_, err := h.service.ObjectACL(ctx, bucket, candidate)
+		switch {
+		case errors.Is(err, fs.ErrObjectNotFound):
+			return candidate, nil
+		case err != nil:
+			return "", err
+		}
+	}
+
+	return "", errors.Wrapf(fs.ErrPreconditionFailed, "no free key after %d renames of %q", maxConflictRenames, key)
+}
+
+// renameOnConflict applies WithNoOverwriteRename to a write of key with the
+// client's If-None-Match and If-Match headers. An unconditional write never
+// replaces an object: it gets the first free derived key, reported in
+// finalKeyHeader, and is made put-if-absent so a racing upload of that name
+// wins rather than being overwritten (this one then fails with 412).
+// Conditional writes and writes without the option keep key and ifNoneMatch.
+func (h *handler) renameOnConflict(
+	ctx context.Context, w http.ResponseWriter, bucket, key, ifNoneMatch, ifMatch string,
+) (string, string, error) {
+	if !h.noOverwriteRename || ifNoneMatch != "" || ifMatch != "" {
+		return key, ifNoneMatch, nil
+	}
+
+	key, err := h.freeKey(ctx, bucket, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	w.Header().Set(finalKeyHeader, s3EncodeKey(key))
+
+	return key, "*", nil
+}
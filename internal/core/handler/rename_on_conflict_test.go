@@ -0,0 +1,175 @@
+package handler_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/ingest"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestPutObject_NoOverwriteRename(t *testing.T) {
+	h := handler.New(service.New(storagemem.New()), handler.WithNoOverwriteRename())
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	put := func(t *testing.T, key, body string, headers map[string]string) string {
+		t.Helper()
+
+		rec := do(t, h, http.MethodPut, "/bucket-a/"+url.PathEscape(key), body, headers)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		got, err := url.PathUnescape(rec.Header().Get("X-Fs-Key"))
+		require.NoError(t, err)
+
+		return got
+	}
+
+	t.Run("ThreeUploads", func(t *testing.T) {
+		var keys []string
+		for _, body := range []string{"first", "second", "third"} {
+			keys = append(keys, put(t, "in/report.pdf", body, nil))
+		}
+
+		require.Equal(t, []string{"in/report.pdf", "in/report (1).pdf", "in/report (2).pdf"}, keys)
+
+		for i, body := range []string{"first", "second", "third"} {
+			rec := do(t, h, http.MethodGet, "/bucket-a/"+url.PathEscape(keys[i]), "", nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, body, rec.Body.String())
+		}
+
+		rec := do(t, h, http.MethodGet, "/bucket-a?list-type=2&prefix=in/", "", nil)
+
+		var result handler.ListBucketResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+		require.Len(t, result.Contents, 3)
+	})
+
+	t.Run("NoExtension", func(t *testing.T) {
+		require.Equal(t, "key", put(t, "key", "a", nil))
+		require.Equal(t, "key (1)", put(t, "key", "b", nil))
+		require.Equal(t, ".env", put(t, ".env", "a", nil))
+		require.Equal(t, ".env (1)", put(t, ".env", "b", nil))
+	})
+
+	t.Run("ConditionalKeepsSemantics", func(t *testing.T) {
+		require.Equal(t, "cond.txt", put(t, "cond.txt", "a", nil))
+
+		rec := do(t, h, http.MethodPut, "/bucket-a/cond.txt", "b", map[string]string{"If-None-Match": "*"})
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = do(t, h, http.MethodPut, "/bucket-a/cond.txt", "c", map[string]string{"If-Match": "*"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("X-Fs-Key"))
+		require.Equal(t, "c", do(t, h, http.MethodGet, "/bucket-a/cond.txt", "", nil).Body.String())
+	})
+}
+
+func TestNoOverwriteRename_WritePaths(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "fetched")
+	}))
+	t.Cleanup(source.Close)
+
+	u, err := url.Parse(source.URL)
+	require.NoError(t, err)
+
+	h := handler.New(service.New(storagemem.New()),
+		handler.WithNoOverwriteRename(),
+		handler.WithURLIngest(ingest.WithAllowedHosts(u.Hostname())),
+	)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	// setup stores "original" under key and returns the path of the key.
+	setup := func(t *testing.T, key string) string {
+		t.Helper()
+
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/"+key, "original", nil).Code)
+
+		return "/bucket-a/" + key
+	}
+
+	// check asserts the write was stored as want while key kept "original".
+	check := func(t *testing.T, rec *httptest.ResponseRecorder, key, want, body string) {
+		t.Helper()
+
+		got, err := url.PathUnescape(rec.Header().Get("X-Fs-Key"))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+
+		require.Equal(t, "original", do(t, h, http.MethodGet, "/bucket-a/"+key, "", nil).Body.String())
+		require.Equal(t, body, do(t, h, http.MethodGet, "/bucket-a/"+url.PathEscape(want), "", nil).Body.String())
+	}
+
+	t.Run("CopyObject", func(t *testing.T) {
+		target := setup(t, "copy.txt")
+		require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a/src.txt", "source", nil).Code)
+
+		rec := do(t, h, http.MethodPut, target, "", map[string]string{"X-Amz-Copy-Source": "/bucket-a/src.txt"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		check(t, rec, "copy.txt", "copy (1).txt", "source")
+	})
+
+	t.Run("PostObject", func(t *testing.T) {
+		setup(t, "post.txt")
+
+		rec := postForm(t, h, "/bucket-a", [][2]string{{"key", "post.txt"}}, "post.txt", "posted")
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		require.Contains(t, rec.Header().Get("Location"), "/bucket-a/post%20%281%29.txt")
+		check(t, rec, "post.txt", "post (1).txt", "posted")
+	})
+
+	t.Run("URLIngest", func(t *testing.T) {
+		target := setup(t, "ingest.txt")
+
+		rec := do(t, h, http.MethodPut, target, "", map[string]string{"X-Fs-Source-Url": source.URL})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		check(t, rec, "ingest.txt", "ingest (1).txt", "fetched")
+	})
+
+	t.Run("CompleteMultipartUpload", func(t *testing.T) {
+		target := setup(t, "mp.txt")
+		uploadID := initiateUpload(t, h, "bucket-a", "mp.txt")
+		etag := putPart(t, h, "bucket-a", "mp.txt", uploadID, 1, "assembled")
+
+		rec := do(t, h, http.MethodPost, target+"?uploadId="+uploadID, completeBody([2]string{"1", etag}), nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		check(t, rec, "mp.txt", "mp (1).txt", "assembled")
+
+		var result handler.CompleteMultipartUploadResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+		require.Equal(t, "mp (1).txt", result.Key)
+	})
+
+	t.Run("CompleteMultipartUploadIfNoneMatch", func(t *testing.T) {
+		target := setup(t, "mp-cond.txt")
+		uploadID := initiateUpload(t, h, "bucket-a", "mp-cond.txt")
+		etag := putPart(t, h, "bucket-a", "mp-cond.txt", uploadID, 1, "assembled")
+
+		rec := do(t, h, http.MethodPost, target+"?uploadId="+uploadID, completeBody([2]string{"1", etag}),
+			map[string]string{"If-None-Match": "*"})
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+		require.Equal(t, "original", do(t, h, http.MethodGet, target, "", nil).Body.String())
+	})
+}
+
+func TestPutObject_Overwrites(t *testing.T) {
+	h := newStorageHandler(t)
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	for _, body := range []string{"first", "second"} {
+		rec := do(t, h, http.MethodPut, "/bucket-a/key", body, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("X-Fs-Key"))
+	}
+
+	require.Equal(t, "second", do(t, h, http.MethodGet, "/bucket-a/key", "", nil).Body.String())
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/go-faster/errors"
+	"github.com/go-faster/sdk/zctx"
+	"go.uber.org/zap"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// ResetResult is the XML document served by the ?all admin endpoint: what a
+// reset deleted, or with DryRun would delete.
+type ResetResult struct {
+	XMLName xml.Name           `xml:"ResetResult"`
+	DryRun  bool               `xml:"DryRun"`
+	Buckets []ResetBucketEntry `xml:"Bucket"`
+}
+
+// ResetBucketEntry is one bucket of a ResetResult.
+type ResetBucketEntry struct {
+	Name    string `xml:"Name"`
+	Objects int    `xml:"Objects"`
+	Bytes   int64  `xml:"Bytes"`
+	Uploads int    `xml:"Uploads"`
+}
+
+// Reset serves the ?all admin endpoint: DELETE deletes every bucket with its
+// objects and in-progress uploads, GET is the dry run. Both answer with a
+// ResetResult. It needs WithReset and an authenticator (the caller must hold
+// an Admin grant on "*"), and is refused during maintenance. Unlike the other
+// deletes it reports no events to the sink: it tears down a whole test or
+// development store, where one removal per object would only flood it.
+func (h *handler) Reset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !h.reset {
+		s3err.WriteAPI(w, r, s3err.NotImplemented)
+		return
+	}
+
+	if !h.authenticated {
+		renderAPIError(ctx, w, r, s3err.AccessDenied, errors.New("admin requests need authentication"))
+		return
+	}
+
+	var dryRun bool
+
+	switch r.Method {
+	case http.MethodGet:
+		dryRun = true
+	case http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		s3err.WriteAPI(w, r, s3err.MethodNotAllowed)
+
+		return
+	}
+
+	report, err := fs.Reset(ctx, h.service, dryRun)
+	if err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	result := ResetResult{DryRun: dryRun}
+	for _, b := range report.Buckets {
+		result.Buckets = append(result.Buckets, ResetBucketEntry{
+			Name:    b.Bucket,
+			Objects: b.Objects,
+			Bytes:   b.Bytes,
+			Uploads: b.Uploads,
+		})
+	}
+
+	if !dryRun {
+		zctx.From(ctx).Warn("Store reset",
+			zap.Int("buckets", len(report.Buckets)),
+			zap.Int("objects", report.Objects()),
+		)
+	}
+
+	writeXML(ctx, w, r, result)
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+const (
+	// downloadIDParam starts (empty) or resumes (an ID) a resumable download.
+	downloadIDParam = "download-id"
+	// downloadIDHeader carries the download's ID; downloadOffsetHeader the
+	// object offset the body starts at, in a response, and the bytes the
+	// client received, in a resuming request.
+	downloadIDHeader     = "X-Fs-Download-Id"
+	downloadOffsetHeader = "X-Fs-Download-Offset"
+	// maxDownloadSessions bounds the sessions held at once; the one closest
+	// to expiring makes room for a new one.
+	maxDownloadSessions = 10000
+)
+
+// downloadSession is the progress of one resumable download.
+type downloadSession struct {
+	bucket, key string
+	etag        string
+	// written is how far into the object the server has written; a client
+	// may resume at any offset up to it.
+	written int64
+	expires time.Time
+}
+
+// downloadSessions tracks unfinished resumable downloads by ID. A session is
+// taken out of the table while a request streams it, so two requests cannot
+// resume the same download at once, and put back with its new progress if the
+// stream is cut short. Sessions idle for longer than ttl are dropped, and at
+// most max are held.
+type downloadSessions struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*downloadSession
+}
+
+func newDownloadSessions(ttl time.Duration) *downloadSessions {
+	return &downloadSessions{
+		ttl:      ttl,
+		max:      maxDownloadSessions,
+		now:      time.Now,
+		sessions: make(map[string]*downloadSession),
+	}
+}
+
+// take removes and returns the live session id, if any.
+func (d *downloadSessions) take(id string) (*downloadSession, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expireLocked()
+
+	s, ok := d.sessions[id]
+	delete(d.sessions, id)
+
+	return s, ok
+}
+
+// put records s under id for another ttl, evicting the session closest to
+// expiring if the table is full.
+func (d *downloadSessions) put(id string, s *downloadSession) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expireLocked()
+
+	if len(d.sessions) >= d.max {
+		var oldest string
+		for sid, cur := range d.sessions {
+			if oldest == "" || cur.expires.Before(d.sessions[oldest].expires) {
+				oldest = sid
+			}
+		}
+
+		delete(d.sessions, oldest)
+	}
+
+	s.expires = d.now().Add(d.ttl)
+	d.sessions[id] = s
+}
+
+// len reports the number of sessions held, expired or not.
+func (d *downloadSessions) len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.sessions)
+}
+
+func (d *downloadSessions) expireLocked() {
+	now := d.now()
+	for id, s := range d.sessions {
+		if now.After(s.expires) {
+			delete(d.sessions, id)
+		}
+	}
+}
+
+// newDownloadID returns a random 32-hex-character download ID.
+func newDownloadID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errors.Wrap(err, "generate download id")
+	}
+
+	return hex.EncodeToString(b[:]), nil
+}
+
+// skipTo advances r to offset, seeking when it can.
+func skipTo(r io.Reader, offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+
+	if s, ok := r.(io.Seeker); ok {
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			return errors.Wrap(err, "seek to offset")
+		}
+
+		return nil
+	}
+
+	if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		return errors.Wrap(err, "skip to offset")
+	}
+
+	return nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// ResumableDownload serves GET /{bucket}/{key}?download-id, an extension for
+// clients that cannot resume with Range requests. Without a value it starts a
+// download under a new ID, returned in X-Fs-Download-Id, and streams from
+// the first byte; with the ID of a download that was cut short, and the
+// number of bytes the client received in X-Fs-Download-Offset, it streams the
+// rest from there (echoed in the response's X-Fs-Download-Offset). Both
+// answer 200 with the remaining length as Content-Length. A finished download
+// forgets its ID; an unknown or expired one is InvalidArgument, as is a
+// missing offset or one past what the server wrote, and an object replaced
+// since the download began is PreconditionFailed.
+func (h *handler) ResumableDownload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucket, key := splitPath(r)
+
+	if h.downloads == nil {
+		renderAPIError(ctx, w, r, s3err.NotImplemented, errors.New("resumable downloads are not enabled"))
+		return
+	}
+
+	ctx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
+	id := r.URL.Query().Get(downloadIDParam)
+
+	var offset int64
+
+	session := &downloadSession{bucket: bucket, key: key}
+	if id != "" {
+		s, ok := h.downloads.take(id)
+		if !ok || s.bucket != bucket || s.key != key {
+			renderAPIError(ctx, w, r, s3err.InvalidArgument, errors.Errorf("unknown or expired %s %q", downloadIDParam, id))
+			return
+		}
+
+		// The client says where to resume: bytes in flight when the
+		// connection broke may never have reached it. It cannot have more
+		// than the server wrote.
+		received, err := strconv.ParseInt(r.Header.Get(downloadOffsetHeader), 10, 64)
+		if err != nil || received < 0 || received > s.written {
+			h.downloads.put(id, s)
+			renderAPIError(ctx, w, r, s3err.InvalidArgument,
+				errors.Errorf("%s must be the bytes received, at most %d", downloadOffsetHeader, s.written))
+
+			return
+		}
+
+		session, offset = s, received
+	} else {
+		var err error
+		if id, err = newDownloadID(); err != nil {
+			renderError(ctx, w, r, err)
+			return
+		}
+	}
+
+	resp, err := h.service.GetObject(ctx, bucket, key)
+	if err != nil {
+		if session.etag != "" {
+			// Resumable again once the object is back, until it expires.
+			h.downloads.put(id, session)
+		}
+
+		renderError(ctx, w, r, err)
+
+		return
+	}
+	defer func() { _ = resp.Reader.Close() }()
+
+	switch {
+	case session.etag == "":
+		session.etag = resp.ETag
+	case session.etag != resp.ETag:
+		renderAPIError(ctx, w, r, s3err.PreconditionFailed,
+			errors.Errorf("%s/%s changed since download %s began", bucket, key, id))
+		return
+	}
+
+	if err := skipTo(resp.Reader, offset); err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	setObjectHeaders(w.Header(), resp, h.defaultContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(resp.Size-offset, 10))
+	w.Header().Set(downloadIDHeader, id)
+	w.Header().Set(downloadOffsetHeader, strconv.FormatInt(offset, 10))
+	w.WriteHeader(http.StatusOK)
+
+	ir := &integrityReader{Reader: resp.Reader}
+	cw := &countingWriter{w: w}
+	_, err = io.Copy(cw, ir)
+	logServeError(ctx, ir, err)
+
+	session.written = offset + cw.n
+	if session.written < resp.Size && ir.err == nil {
+		h.downloads.put(id, session)
+	}
+
+	abortIfCorrupt(ctx, ir)
+}
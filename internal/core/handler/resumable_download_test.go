@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/storagemem"
+)
+
+// cutWriter is a ResponseWriter whose connection breaks after limit bytes.
+type cutWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	limit  int
+}
+
+func (c *cutWriter) Header() http.Header { return c.header }
+
+func (c *cutWriter) WriteHeader(code int) { c.code = code }
+
+func (c *cutWriter) Write(p []byte) (int, error) {
+	if room := c.limit - c.body.Len(); len(p) > room {
+		c.body.Write(p[:room])
+		return room, errors.New("connection reset")
+	}
+
+	return c.body.Write(p)
+}
+
+func TestResumableDownload(t *testing.T) {
+	h := New(storagemem.New(), WithResumableDownloads(time.Minute))
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	serveFrom := func(w http.ResponseWriter, method, target string, body []byte, offset string) {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+		if offset != "" {
+			req.Header.Set(downloadOffsetHeader, offset)
+		}
+
+		h.ServeHTTP(w, req)
+	}
+	serve := func(w http.ResponseWriter, method, target string, body []byte) {
+		serveFrom(w, method, target, body, "")
+	}
+
+	rec := httptest.NewRecorder()
+	serve(rec, http.MethodPut, "/bucket", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	serve(rec, http.MethodPut, "/bucket/file", content)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The first attempt breaks off 3000 bytes in.
+	first := &cutWriter{header: http.Header{}, limit: 3000}
+	serve(first, http.MethodGet, "/bucket/file?download-id", nil)
+	require.Equal(t, http.StatusOK, first.code)
+	require.Equal(t, "10000", first.header.Get("Content-Length"))
+	require.Equal(t, "0", first.header.Get(downloadOffsetHeader))
+
+	id := first.header.Get(downloadIDHeader)
+	require.NotEmpty(t, id)
+
+	// An offset past what the server wrote, or none, is refused and keeps
+	// the download resumable.
+	for _, offset := range []string{"", "3001", "-1", "x"} {
+		rec = httptest.NewRecorder()
+		serveFrom(rec, http.MethodGet, "/bucket/file?download-id="+id, nil, offset)
+		require.Equal(t, http.StatusBadRequest, rec.Code, offset)
+		require.Contains(t, rec.Body.String(), "<Code>InvalidArgument</Code>")
+	}
+
+	// The client lost the last 500 bytes in flight: it resumes from what it
+	// received, and the server sends those again.
+	received := first.body.Bytes()[:2500]
+
+	second := &cutWriter{header: http.Header{}, limit: 1000}
+	serveFrom(second, http.MethodGet, "/bucket/file?download-id="+id, nil, "2500")
+	require.Equal(t, http.StatusOK, second.code)
+	require.Equal(t, "2500", second.header.Get(downloadOffsetHeader))
+	require.Equal(t, "7500", second.header.Get("Content-Length"))
+	received = append(received, second.body.Bytes()...)
+
+	rec = httptest.NewRecorder()
+	serveFrom(rec, http.MethodGet, "/bucket/file?download-id="+id, nil, "3500")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "3500", rec.Header().Get(downloadOffsetHeader))
+	require.Equal(t, "6500", rec.Header().Get("Content-Length"))
+	require.Equal(t, content, append(received, rec.Body.Bytes()...))
+
+	// A finished download is forgotten.
+	rec = httptest.NewRecorder()
+	serveFrom(rec, http.MethodGet, "/bucket/file?download-id="+id, nil, "0")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>InvalidArgument</Code>")
+
+	t.Run("Replaced", func(t *testing.T) {
+		cut := &cutWriter{header: http.Header{}, limit: 10}
+		serve(cut, http.MethodGet, "/bucket/file?download-id", nil)
+		id := cut.header.Get(downloadIDHeader)
+
+		rec := httptest.NewRecorder()
+		serve(rec, http.MethodPut, "/bucket/file", []byte("other"))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = httptest.NewRecorder()
+		serveFrom(rec, http.MethodGet, "/bucket/file?download-id="+id, nil, "10")
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		cut := &cutWriter{header: http.Header{}, limit: 1}
+		serve(cut, http.MethodGet, "/bucket/file?download-id", nil)
+		id := cut.header.Get(downloadIDHeader)
+
+		rec := httptest.NewRecorder()
+		serveFrom(rec, http.MethodGet, "/bucket/other?download-id="+id, nil, "1")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestResumableDownload_Disabled(t *testing.T) {
+	h := New(storagemem.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/bucket/file?download-id", http.NoBody)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotImplemented, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>NotImplemented</Code>")
+}
+
+func TestDownloadSessionsExpire(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	d := newDownloadSessions(time.Minute)
+	d.now = func() time.Time { return now }
+
+	d.put("a", &downloadSession{bucket: "b", key: "k", written: 1})
+	d.put("b", &downloadSession{bucket: "b", key: "k", written: 2})
+
+	now = now.Add(30 * time.Second)
+	s, ok := d.take("a")
+	require.True(t, ok)
+	require.Equal(t, int64(1), s.written)
+	require.Equal(t, 1, d.len())
+
+	// A stale session is dropped on the next access.
+	now = now.Add(time.Minute)
+	_, ok = d.take("b")
+	require.False(t, ok)
+	require.Zero(t, d.len())
+}
+
+func TestDownloadSessionsCapped(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	d := newDownloadSessions(time.Minute)
+	d.max = 2
+	d.now = func() time.Time { return now }
+
+	for _, id := range []string{"a", "b", "c"} {
+		d.put(id, &downloadSession{bucket: "b", key: "k"})
+		now = now.Add(time.Second)
+	}
+
+	// The session closest to expiring made room.
+	require.Equal(t, 2, d.len())
+
+	_, ok := d.take("a")
+	require.False(t, ok)
+
+	_, ok = d.take("c")
+	require.True(t, ok)
+}
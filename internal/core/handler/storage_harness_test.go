@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -30,6 +31,11 @@ func do(t testing.TB, h http.Handler, method, target, body string, headers map[s
 	}
 
 	req := httptest.NewRequest(method, target, r)
+	if r != http.NoBody {
+		// As net/http's client does; a PUT without it is 411.
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-faster/fs/auth"
+)
+
+// Tracer starts a span around each request. StartSpan returns the context
+// the request is served with, so the span is the parent of anything the
+// backend traces, and a func that ends the span: with nil on success, or a
+// *ResponseError for a 4xx/5xx response. It is a bridge point for
+// OpenTelemetry or any other tracing system without this package importing
+// one.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, func(error))
+}
+
+// ResponseError is the error a span ends with when the request was answered
+// with an error status.
+type ResponseError struct {
+	StatusCode int
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// withTracing wraps each request in a span named after its S3 operation (see
+// operationName). A panicking handler, such as one aborting a response with
+// http.ErrAbortHandler, ends the span with the panic value before the panic
+// continues.
+func withTracing(t Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, end := t.StartSpan(r.Context(), string(operationName(r)))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			if p := recover(); p != nil {
+				if err, ok := p.(error); ok {
+					end(err)
+				} else {
+					end(fmt.Errorf("panic: %v", p))
+				}
+
+				panic(p)
+			}
+
+			if sw.status >= http.StatusBadRequest {
+				end(&ResponseError{StatusCode: sw.status})
+				return
+			}
+
+			end(nil)
+		}()
+
+		next.ServeHTTP(sw, r.WithContext(ctx))
+	})
+}
+
+// statusWriter records the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// operationName names the S3 operation a request performs, following the
+// dispatch in route, routeBucket, routeObject and the PUT and POST handlers.
+// Keep it in step with them when adding an operation.
+func operationName(r *http.Request) auth.Operation {
+	if r.Method == http.MethodOptions {
+		return auth.OperationPreflightRequest
+	}
+
+	switch adminSubresource(r) {
+	case adminMaintenance:
+		return auth.OperationMaintenance
+	case adminAll:
+		return auth.OperationReset
+	case adminObjects:
+		return auth.OperationListAllObjects
+	}
+
+	_, _, kind, err := parsePath(r.URL.EscapedPath())
+	if err != nil {
+		return auth.OperationUnknown
+	}
+
+	q := r.URL.Query()
+
+	switch kind {
+	case pathService:
+		return auth.OperationListBuckets
+	case pathBucket:
+		return bucketOperationName(r, q)
+	default:
+		return objectOperationName(r, q)
+	}
+}
+
+func bucketOperationName(r *http.Request, q url.Values) auth.Operation {
+	switch r.Method {
+	case http.MethodGet:
+		switch {
+		case q.Has("location"):
+			return auth.OperationGetBucketLocation
+		case q.Has("versions"):
+			return auth.OperationListObjectVersions
+		case q.Has("uploads"):
+			return auth.OperationListMultipartUploads
+		case q.Has("manifest"):
+			return auth.OperationGetBucketManifest
+		case q.Has(countParam):
+			return auth.OperationCountObjects
+		case q.Get("list-type") == "2":
+			return auth.OperationListObjectsV2
+		default:
+			return auth.OperationListObjects
+		}
+	case http.MethodPut:
+		return auth.OperationCreateBucket
+	case http.MethodHead:
+		return auth.OperationHeadBucket
+	case http.MethodDelete:
+		switch {
+		case q.Has(patternParam):
+			return auth.OperationDeleteObjectsByPattern
+		case q.Has(forceParam):
+			return auth.OperationDeleteBucketForce
+		default:
+			return auth.OperationDeleteBucket
+		}
+	case http.MethodPost:
+		if q.Has("delete") {
+			return auth.OperationDeleteObjects
+		}
+
+		if q.Has(diffParam) {
+			return auth.OperationDiffObjects
+		}
+
+		return auth.OperationPostObject
+	default:
+		return auth.OperationUnknown
+	}
+}
+
+func objectOperationName(r *http.Request, q url.Values) auth.Operation {
+	switch r.Method {
+	case http.MethodGet:
+		switch {
+		case q.Has("uploadId"):
+			return auth.OperationListParts
+		case q.Has("tagging"):
+			return auth.OperationGetObjectTagging
+		case q.Has("legal-hold"):
+			return auth.OperationGetObjectLegalHold
+		case q.Has("meta"):
+			return auth.OperationGetObjectMeta
+		default:
+			return auth.OperationGetObject
+		}
+	case http.MethodHead:
+		return auth.OperationHeadObject
+	case http.MethodPut:
+		copySource := r.Header.Get("X-Amz-Copy-Source") != ""
+		part := q.Get("uploadId") != "" && q.Get("partNumber") != ""
+
+		switch {
+		case q.Has("tagging"):
+			return auth.OperationPutObjectTagging
+		case q.Has("legal-hold"):
+			return auth.OperationPutObjectLegalHold
+		case part && copySource:
+			return auth.OperationUploadPartCopy
+		case part:
+			return auth.OperationUploadPart
+		case copySource:
+			return auth.OperationCopyObject
+		case r.Header.Get("Content-Range") != "":
+			return auth.OperationPutObjectRange
+		default:
+			return auth.OperationPutObject
+		}
+	case http.MethodDelete:
+		switch {
+		case q.Has("tagging"):
+			return auth.OperationDeleteObjectTagging
+		case q.Get("uploadId") != "":
+			return auth.OperationAbortMultipartUpload
+		default:
+			return auth.OperationDeleteObject
+		}
+	case http.MethodPost:
+		switch {
+		case q.Has("uploads"):
+			return auth.OperationCreateMultipartUpload
+		case q.Get("uploadId") != "":
+			return auth.OperationCompleteMultipartUpload
+		default:
+			return auth.OperationUnknown
+		}
+	default:
+		return auth.OperationUnknown
+	}
+}
@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	name  string
+	ended bool
+	err   error
+}
+
+// fakeTracer records every span it starts.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (f *fakeTracer) StartSpan(ctx context.Context, name string) (context.Context, func(error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	span := &fakeSpan{name: name}
+	f.spans = append(f.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), func(err error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		span.ended, span.err = true, err
+	}
+}
+
+func (f *fakeTracer) last(t *testing.T) *fakeSpan {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	require.NotEmpty(t, f.spans)
+
+	return f.spans[len(f.spans)-1]
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := handler.New(service.New(storagemem.New()), handler.WithTracer(tracer))
+
+	for _, tt := range []struct {
+		method, target string
+		headers        map[string]string
+		name           string
+		status         int
+	}{
+		{method: http.MethodGet, target: "/", name: "ListBuckets", status: http.StatusOK},
+		{method: http.MethodPut, target: "/bucket-a", name: "CreateBucket", status: http.StatusOK},
+		{method: http.MethodPut, target: "/bucket-a/key", name: "PutObject", status: http.StatusOK},
+		{method: http.MethodGet, target: "/bucket-a/key", name: "GetObject", status: http.StatusOK},
+		{method: http.MethodHead, target: "/bucket-a/key", name: "HeadObject", status: http.StatusOK},
+		{
+			method: http.MethodPut, target: "/bucket-a/copy", name: "CopyObject", status: http.StatusOK,
+			headers: map[string]string{"X-Amz-Copy-Source": "/bucket-a/key"},
+		},
+		{method: http.MethodGet, target: "/bucket-a?list-type=2", name: "ListObjectsV2", status: http.StatusOK},
+		{method: http.MethodGet, target: "/bucket-a", name: "ListObjects", status: http.StatusOK},
+		{method: http.MethodPost, target: "/bucket-a/mp?uploads", name: "CreateMultipartUpload", status: http.StatusOK},
+		{method: http.MethodGet, target: "/bucket-a/missing", name: "GetObject", status: http.StatusNotFound},
+		{method: http.MethodDelete, target: "/bucket-a/key", name: "DeleteObject", status: http.StatusNoContent},
+		{method: http.MethodDelete, target: "/bucket-a", name: "DeleteBucket", status: http.StatusConflict},
+	} {
+		rec := do(t, h, tt.method, tt.target, "", tt.headers)
+		require.Equal(t, tt.status, rec.Code, tt.target)
+
+		span := tracer.last(t)
+		require.Equal(t, tt.name, span.name, tt.target)
+		require.True(t, span.ended, tt.target)
+
+		if tt.status < http.StatusBadRequest {
+			require.NoError(t, span.err, tt.target)
+			continue
+		}
+
+		var respErr *handler.ResponseError
+		require.ErrorAs(t, span.err, &respErr, tt.target)
+		require.Equal(t, tt.status, respErr.StatusCode, tt.target)
+	}
+
+	require.Len(t, tracer.spans, 12)
+}
+
+func TestWithTracer_SpanContext(t *testing.T) {
+	tracer := &fakeTracer{}
+
+	var got *fakeSpan
+
+	h := handler.New(spanCapturingStorage{Storage: storagemem.New(), got: &got}, handler.WithTracer(tracer))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	require.NotNil(t, got)
+	require.Equal(t, "CreateBucket", got.name)
+	require.Same(t, tracer.last(t), got)
+}
+
+// spanCapturingStorage records the span found in the context of CreateBucket.
+type spanCapturingStorage struct {
+	*storagemem.Storage
+	got **fakeSpan
+}
+
+func (s spanCapturingStorage) CreateBucket(ctx context.Context, bucket string) error {
+	span, ok := ctx.Value(spanKey{}).(*fakeSpan)
+	if !ok {
+		return errors.New("no span in context")
+	}
+
+	*s.got = span
+
+	return s.Storage.CreateBucket(ctx, bucket)
+}
@@ -128,6 +128,9 @@ func TestHandler_SupportedOperations(t *testing.T) {
 					Key:      req.Key,
 				}, nil
 			}
+			svc.DeleteObjectFunc = func(ctx context.Context, bucket, key string) error {
+				return nil
+			}
 			svc.CompleteMultipartUploadFunc = func(ctx context.Context, req *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error) {
 				return &fs.CompleteMultipartUploadResponse{
 					Location: "/" + req.Bucket + "/" + req.Key,
@@ -149,8 +152,8 @@ func TestHandler_SupportedOperations(t *testing.T) {
 				// CompleteMultipartUpload requires an XML body with at least one part.
 				body = `<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>"etag1"</ETag></Part></CompleteMultipartUpload>`
 			} else if strings.Contains(tt.query, "delete") {
-				// DeleteObjects requires XML body.
-				body = `<Delete></Delete>`
+				// DeleteObjects requires an XML body with at least one key.
+				body = `<Delete><Object><Key>obj</Key></Object></Delete>`
 			}
 
 			req := httptest.NewRequest(tt.method, url, strings.NewReader(body))
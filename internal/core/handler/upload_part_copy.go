@@ -26,8 +26,7 @@ type CopyPartResult struct {
 // ETag is recomputed from the copied bytes by the storage layer.
 func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	path := strings.TrimPrefix(r.URL.Path, "/")
-	bucket, key, _ := strings.Cut(path, "/")
+	bucket, key := splitPath(r)
 	q := r.URL.Query()
 
 	partNumber, err := strconv.Atoi(q.Get("partNumber"))
@@ -42,7 +41,17 @@ func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	src, err := h.service.GetObject(ctx, srcBucket, srcKey)
+	srcCtx, ok := withCustomerKey(ctx, w, r, sseCustomerCopySourcePrefix)
+	if !ok {
+		return
+	}
+
+	dstCtx, ok := withCustomerKey(ctx, w, r, sseCustomerPrefix)
+	if !ok {
+		return
+	}
+
+	src, err := h.service.GetObject(srcCtx, srcBucket, srcKey)
 	if err != nil {
 		renderError(ctx, w, r, err)
 		return
@@ -75,7 +84,7 @@ func (h *handler) UploadPartCopy(w http.ResponseWriter, r *http.Request) {
 		reader = io.LimitReader(src.Reader, size)
 	}
 
-	part, err := h.service.UploadPart(ctx, &fs.UploadPartRequest{
+	part, err := h.service.UploadPart(dstCtx, &fs.UploadPartRequest{
 		Bucket:     bucket,
 		Key:        key,
 		UploadID:   q.Get("uploadId"),
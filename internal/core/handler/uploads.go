@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/go-faster/fs/internal/s3err"
+)
+
+// uploadRetryAfter is the Retry-After hint, in seconds, sent when every upload
+// slot is taken. Slots free up as uploads finish, which has no predictable
+// schedule, so the hint is just "soon".
+const uploadRetryAfter = "1"
+
+// acquireUpload takes an upload slot for the duration of a body-carrying
+// object PUT (PutObject, UploadPart, and the copies, which write as much). It
+// never queues: with no slot free it answers 503 SlowDown and returns false,
+// so a burst of uploads is pushed back to the clients instead of piling up
+// open files and goroutines. Without WithMaxConcurrentUploads it always
+// succeeds; release must be called after a true result.
+func (h *handler) acquireUpload(w http.ResponseWriter, r *http.Request) (release func(), ok bool) {
+	if h.uploads == nil {
+		return func() {}, true
+	}
+
+	if !h.uploads.TryAcquire(1) {
+		w.Header().Set("Retry-After", uploadRetryAfter)
+		s3err.WriteAPI(w, r, s3err.SlowDown)
+
+		return nil, false
+	}
+
+	return func() { h.uploads.Release(1) }, true
+}
+
+// newSlots returns a semaphore of n slots, for uploads or requests in flight;
+// nil (unlimited) when n <= 0.
+func newSlots(n int) *semaphore.Weighted {
+	if n <= 0 {
+		return nil
+	}
+
+	return semaphore.NewWeighted(int64(n))
+}
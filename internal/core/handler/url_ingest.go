@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/ingest"
+	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/notify"
+)
+
+// sourceURLHeader names the URL an ingest PUT fetches the object from.
+const sourceURLHeader = "X-Fs-Source-Url"
+
+// IngestObject stores the object at source (the x-fs-source-url header of a
+// PUT) as key: the server fetches it, so the request carries no body. The
+// source's Content-Type and related headers are kept and the ETag is
+// computed as for any PUT. A host the ingester may not fetch from gets
+// AccessDenied, a source over its size limit EntityTooLarge, and a source
+// that cannot be fetched InvalidArgument.
+func (h *handler) IngestObject(w http.ResponseWriter, r *http.Request, bucket, key, source string) {
+	ctx := r.Context()
+
+	if h.ingest == nil {
+		renderAPIError(ctx, w, r, s3err.NotImplemented, errors.New("URL ingest is not enabled"))
+		return
+	}
+
+	if r.ContentLength > 0 {
+		renderAPIError(ctx, w, r, s3err.InvalidRequest, errors.Errorf("%s requests must not have a body", sourceURLHeader))
+		return
+	}
+
+	put := &fs.PutObjectRequest{
+		Bucket:      bucket,
+		Key:         key,
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+		IfMatch:     r.Header.Get("If-Match"),
+	}
+
+	// With WithNoOverwriteRename an unconditional ingest is stored under a
+	// free derived key rather than replacing an existing object.
+	var err error
+	if put.Key, put.IfNoneMatch, err = h.renameOnConflict(ctx, w, bucket, key, put.IfNoneMatch, put.IfMatch); err != nil {
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	resp, err := h.ingest.Ingest(ctx, put, source)
+	switch {
+	case errors.Is(err, ingest.ErrTooLarge):
+		renderAPIError(ctx, w, r, s3err.EntityTooLarge, err)
+		return
+	case errors.Is(err, ingest.ErrSource):
+		renderAPIError(ctx, w, r, s3err.InvalidArgument, err)
+		return
+	case err != nil:
+		renderError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(resp.ETag))
+	setEncryptionHeaders(w.Header(), resp.ServerSideEncryption, resp.SSECustomerKeyMD5)
+	w.WriteHeader(http.StatusOK)
+
+	h.emit(w, notify.ObjectCreatedPut, bucket, put.Key, -1, resp.ETag)
+}
@@ -0,0 +1,58 @@
+package handler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/ingest"
+	"github.com/go-faster/fs/internal/core/handler"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestIngestObject(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	t.Cleanup(source.Close)
+
+	u, err := url.Parse(source.URL)
+	require.NoError(t, err)
+
+	h := handler.New(service.New(storagemem.New()), handler.WithURLIngest(ingest.WithAllowedHosts(u.Hostname())))
+	require.Equal(t, http.StatusOK, do(t, h, http.MethodPut, "/bucket-a", "", nil).Code)
+
+	t.Run("Fetches", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket-a/status.json", "", map[string]string{"X-Fs-Source-Url": source.URL + "/status"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NotEmpty(t, rec.Header().Get("ETag"))
+
+		got := do(t, h, http.MethodGet, "/bucket-a/status.json", "", nil)
+		require.Equal(t, http.StatusOK, got.Code)
+		require.Equal(t, `{"ok":true}`, got.Body.String())
+		require.Equal(t, "application/json", got.Header().Get("Content-Type"))
+		require.Equal(t, rec.Header().Get("ETag"), got.Header().Get("ETag"))
+	})
+
+	t.Run("HostNotAllowed", func(t *testing.T) {
+		rec := do(t, h, http.MethodPut, "/bucket-a/meta", "", map[string]string{"X-Fs-Source-Url": "http://169.254.169.254/latest/meta-data"})
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Equal(t, "AccessDenied", errorCode(t, rec.Body.String()))
+
+		require.Equal(t, http.StatusNotFound, do(t, h, http.MethodGet, "/bucket-a/meta", "", nil).Code)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		plain := newStorageHandler(t)
+		require.Equal(t, http.StatusOK, do(t, plain, http.MethodPut, "/bucket-a", "", nil).Code)
+
+		rec := do(t, plain, http.MethodPut, "/bucket-a/k", "", map[string]string{"X-Fs-Source-Url": source.URL})
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+	})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/go-faster/fs"
+)
+
+// DefaultsResolver returns the default tags and metadata of a bucket, as
+// defaults.Registry does.
+type DefaultsResolver interface {
+	BucketDefaults(bucket string) (tags, metadata map[string]string, ok bool)
+}
+
+// WithBucketDefaults merges the per-bucket default tags and metadata resolved
+// through r into every PutObject (copies included) and CreateMultipartUpload.
+// Tags and metadata names the request sets itself keep the request's values.
+// Limits apply to the merged result, so defaults count against the 10 tags
+// and the metadata size of each object.
+func WithBucketDefaults(r DefaultsResolver) Option {
+	return func(s *Service) { s.defaults = r }
+}
+
+// withDefaults returns meta and tags with the defaults of bucket merged in.
+// The arguments are not modified. s.defaults must be set.
+func (s Service) withDefaults(bucket string, meta fs.ObjectMetadata, tags []fs.Tag) (fs.ObjectMetadata, []fs.Tag) {
+	defTags, defMeta, ok := s.defaults.BucketDefaults(bucket)
+	if !ok {
+		return meta, tags
+	}
+
+	if len(defMeta) > 0 {
+		merged := maps.Clone(defMeta)
+		maps.Copy(merged, meta.UserMetadata)
+		meta.UserMetadata = merged
+	}
+
+	if len(defTags) > 0 {
+		set := make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			set[tag.Key] = struct{}{}
+		}
+
+		merged := slices.Clone(tags)
+
+		for _, key := range slices.Sorted(maps.Keys(defTags)) {
+			if _, ok := set[key]; !ok {
+				merged = append(merged, fs.Tag{Key: key, Value: defTags[key]})
+			}
+		}
+
+		tags = merged
+	}
+
+	return meta, tags
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/defaults"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestService_BucketDefaults(t *testing.T) {
+	ctx := t.Context()
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(ctx, "shared"))
+
+	registry := defaults.NewRegistry()
+	registry.SetBucketDefaults("shared",
+		map[string]string{"cost-center": "42", "env": "prod"},
+		map[string]string{"Team": "storage"},
+	)
+
+	svc := service.New(store, service.WithBucketDefaults(registry))
+
+	upload := func(key string, tags []fs.Tag, meta map[string]string) {
+		t.Helper()
+
+		_, err := svc.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket:   "shared",
+			Key:      key,
+			Reader:   strings.NewReader("data"),
+			Size:     4,
+			Tags:     tags,
+			Metadata: fs.ObjectMetadata{UserMetadata: meta},
+		})
+		require.NoError(t, err)
+	}
+
+	stored := func(key string) ([]fs.Tag, map[string]string) {
+		t.Helper()
+
+		tags, err := svc.GetObjectTagging(ctx, "shared", key)
+		require.NoError(t, err)
+
+		resp, err := svc.GetObject(ctx, "shared", key)
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+
+		return tags, resp.Metadata.UserMetadata
+	}
+
+	t.Run("Applied", func(t *testing.T) {
+		upload("plain", nil, nil)
+
+		tags, meta := stored("plain")
+		require.ElementsMatch(t, []fs.Tag{{Key: "cost-center", Value: "42"}, {Key: "env", Value: "prod"}}, tags)
+		require.Equal(t, map[string]string{"team": "storage"}, meta)
+	})
+
+	t.Run("ClientWins", func(t *testing.T) {
+		clientTags := []fs.Tag{{Key: "env", Value: "staging"}}
+		clientMeta := map[string]string{"team": "web", "owner": "alice"}
+		upload("override", clientTags, clientMeta)
+
+		tags, meta := stored("override")
+		require.ElementsMatch(t, []fs.Tag{{Key: "cost-center", Value: "42"}, {Key: "env", Value: "staging"}}, tags)
+		require.Equal(t, map[string]string{"team": "web", "owner": "alice"}, meta)
+
+		// The request itself is left alone.
+		require.Equal(t, []fs.Tag{{Key: "env", Value: "staging"}}, clientTags)
+		require.Equal(t, map[string]string{"team": "web", "owner": "alice"}, clientMeta)
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		upload, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "big"})
+		require.NoError(t, err)
+
+		part, err := svc.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket: "shared", Key: "big", UploadID: upload.UploadID, PartNumber: 1,
+			Reader: strings.NewReader("part"), Size: 4,
+		})
+		require.NoError(t, err)
+
+		_, err = svc.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+			Bucket: "shared", Key: "big", UploadID: upload.UploadID,
+			Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+		})
+		require.NoError(t, err)
+
+		tags, meta := stored("big")
+		require.Len(t, tags, 2)
+		require.Equal(t, "storage", meta["team"])
+	})
+
+	t.Run("OtherBucket", func(t *testing.T) {
+		require.NoError(t, store.CreateBucket(ctx, "plain"))
+
+		_, err := svc.PutObject(ctx, &fs.PutObjectRequest{Bucket: "plain", Key: "k", Reader: strings.NewReader("x"), Size: 1})
+		require.NoError(t, err)
+
+		tags, err := svc.GetObjectTagging(ctx, "plain", "k")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+	})
+}
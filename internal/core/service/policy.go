@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/policy"
+)
+
+// PolicyResolver returns the prefix policy governing an object key, as
+// policy.Registry does.
+type PolicyResolver interface {
+	PrefixPolicy(bucket, key string) (prefix string, policy policy.PrefixPolicy, ok bool)
+}
+
+// WithPrefixPolicies enforces the per-prefix policies resolved through r on
+// writes: read-only prefixes refuse them with fs.ErrAccessDenied, append-only
+// prefixes refuse overwrites and deletes with fs.ErrAccessDenied, quotas with
+// fs.ErrQuotaExceeded, and objects lacking required metadata are refused with
+// fs.ErrMissingMetadata.
+func WithPrefixPolicies(r PolicyResolver) Option {
+	return func(s *Service) { s.policies = r }
+}
+
+// prefixPolicy returns the policy governing key, if any.
+func (s Service) prefixPolicy(bucket, key string) (string, policy.PrefixPolicy, bool) {
+	if s.policies == nil {
+		return "", policy.PrefixPolicy{}, false
+	}
+
+	return s.policies.PrefixPolicy(bucket, key)
+}
+
+// checkWritable refuses any change to key under a read-only prefix.
+func (s Service) checkWritable(bucket, key string) error {
+	if prefix, pol, ok := s.prefixPolicy(bucket, key); ok && pol.ReadOnly {
+		return errors.Wrapf(fs.ErrAccessDenied, "prefix %q of bucket %q is read-only", prefix, bucket)
+	}
+
+	return nil
+}
+
+// appendOnly reports whether key lies under an append-only prefix, returning
+// the prefix.
+func (s Service) appendOnly(bucket, key string) (string, bool) {
+	prefix, pol, ok := s.prefixPolicy(bucket, key)
+	return prefix, ok && pol.AppendOnly
+}
+
+// appendOnlyError refuses a change to an existing object (or any delete) of
+// an append-only prefix.
+func appendOnlyError(bucket, key, prefix string) error {
+	return errors.Wrapf(fs.ErrAccessDenied, "%q in bucket %q: prefix %q is append-only", key, bucket, prefix)
+}
+
+// checkDeletable refuses deleting key under a read-only or append-only
+// prefix.
+func (s Service) checkDeletable(bucket, key string) error {
+	if err := s.checkWritable(bucket, key); err != nil {
+		return err
+	}
+
+	if prefix, ok := s.appendOnly(bucket, key); ok {
+		return appendOnlyError(bucket, key, prefix)
+	}
+
+	return nil
+}
+
+// checkBucketDeletable refuses deleting a bucket whose whole keyspace (the
+// empty prefix) is append-only. Append-only objects under narrower prefixes
+// keep the bucket from being emptied, so they need no check here.
+func (s Service) checkBucketDeletable(bucket string) error {
+	if _, pol, ok := s.prefixPolicy(bucket, ""); ok && pol.AppendOnly {
+		return errors.Wrapf(fs.ErrAccessDenied, "bucket %q is append-only", bucket)
+	}
+
+	return nil
+}
+
+// checkNotExists refuses creating key under an append-only prefix when an
+// object is already there. It is a separate step from the write, so two
+// racing creations of the same key can both pass it; PutObject avoids that
+// by making the write itself conditional (see appendOnlyUpload).
+func (s Service) checkNotExists(ctx context.Context, bucket, key string) error {
+	prefix, ok := s.appendOnly(bucket, key)
+	if !ok {
+		return nil
+	}
+
+	obj, err := s.storage.GetObject(ctx, bucket, key)
+	if errors.Is(err, fs.ErrObjectNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "check existing object")
+	}
+
+	_ = obj.Reader.Close()
+
+	return appendOnlyError(bucket, key, prefix)
+}
+
+// appendOnlyUpload makes a PutObject under an append-only prefix conditional
+// on the key being absent, so the backend refuses an overwrite atomically
+// with the write. own reports whether the caller already asked for
+// If-None-Match itself, in which case a failed condition stays a
+// precondition failure. A write meant to replace an object (If-Match) is
+// refused outright.
+func (s Service) appendOnlyUpload(req *fs.PutObjectRequest) (_ *fs.PutObjectRequest, own bool, err error) {
+	prefix, ok := s.appendOnly(req.Bucket, req.Key)
+	if !ok {
+		return req, false, nil
+	}
+
+	if req.IfMatch != "" {
+		return nil, false, appendOnlyError(req.Bucket, req.Key, prefix)
+	}
+
+	if strings.TrimSpace(req.IfNoneMatch) == "*" {
+		return req, true, nil
+	}
+
+	conditional := *req
+	conditional.IfNoneMatch = "*"
+
+	return &conditional, false, nil
+}
+
+// checkNewObject applies the checks that do not depend on the body to an
+// object about to be created at key: the prefix must be writable and meta
+// must carry the required metadata.
+func (s Service) checkNewObject(bucket, key string, meta fs.ObjectMetadata) error {
+	if err := s.checkWritable(bucket, key); err != nil {
+		return err
+	}
+
+	prefix, pol, ok := s.prefixPolicy(bucket, key)
+	if !ok {
+		return nil
+	}
+
+	for _, name := range pol.RequiredMetadata {
+		if meta.UserMetadata[name] == "" {
+			return errors.Wrapf(fs.ErrMissingMetadata, "x-amz-meta-%s is required under prefix %q", name, prefix)
+		}
+	}
+
+	return nil
+}
+
+// quotaRemaining returns how many bytes an object written at key may hold
+// under its prefix's quota: the quota less the objects already under the
+// prefix, not counting the one at key, which the write replaces. ok is false
+// when no quota applies.
+func (s Service) quotaRemaining(ctx context.Context, bucket, key string) (remaining int64, ok bool, err error) {
+	prefix, pol, found := s.prefixPolicy(bucket, key)
+	if !found || pol.MaxBytes <= 0 {
+		return 0, false, nil
+	}
+
+	objects, err := s.storage.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "measure prefix usage")
+	}
+
+	remaining = pol.MaxBytes
+	for _, obj := range objects {
+		if obj.Key != key {
+			remaining -= obj.Size
+		}
+	}
+
+	return remaining, true, nil
+}
+
+// quotaError reports an upload of size bytes (-1 when not known up front)
+// that does not fit in remaining.
+func quotaError(bucket, key string, size, remaining int64) error {
+	if size < 0 {
+		return errors.Wrapf(fs.ErrQuotaExceeded, "%q in bucket %q exceeds the %d bytes left in its prefix quota",
+			key, bucket, max(remaining, 0))
+	}
+
+	return errors.Wrapf(fs.ErrQuotaExceeded, "%d bytes for %q in bucket %q exceed the %d bytes left in its prefix quota",
+		size, key, bucket, max(remaining, 0))
+}
+
+// quotaReader fails an upload of unknown size once it reads past the bytes
+// its prefix quota has left.
+type quotaReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+
+	q.remaining -= int64(n)
+	if q.remaining < 0 {
+		return n, q.err
+	}
+
+	return n, err
+}
+
+// enforceUpload applies the prefix policy to a PutObject, returning the
+// request to send on: req itself, or a copy whose body stops at the quota
+// when the size is not known up front.
+func (s Service) enforceUpload(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectRequest, error) {
+	if err := s.checkNewObject(req.Bucket, req.Key, req.Metadata); err != nil {
+		return nil, err
+	}
+
+	remaining, ok, err := s.quotaRemaining(ctx, req.Bucket, req.Key)
+	if err != nil || !ok {
+		return req, err
+	}
+
+	if req.Size > remaining || remaining < 0 {
+		return nil, quotaError(req.Bucket, req.Key, req.Size, remaining)
+	}
+
+	if req.Size >= 0 {
+		return req, nil
+	}
+
+	limited := *req
+	limited.Reader = &quotaReader{
+		r:         req.Reader,
+		remaining: remaining,
+		err:       quotaError(req.Bucket, req.Key, -1, remaining),
+	}
+
+	return &limited, nil
+}
@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/policy"
+	"github.com/go-faster/fs/storagefs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func put(ctx context.Context, s fs.Storage, key, body string, size int64, meta map[string]string) error {
+	_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket:   "shared",
+		Key:      key,
+		Reader:   strings.NewReader(body),
+		Size:     size,
+		Metadata: fs.ObjectMetadata{UserMetadata: meta},
+	})
+
+	return err
+}
+
+func TestService_PrefixPolicies(t *testing.T) {
+	ctx := t.Context()
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(ctx, "shared"))
+	require.NoError(t, put(ctx, store, "archive/2023.log", "old", 3, nil))
+
+	registry := policy.NewRegistry()
+	registry.SetPrefixPolicy("shared", "archive/", policy.PrefixPolicy{ReadOnly: true})
+	registry.SetPrefixPolicy("shared", "archive/inbox/", policy.PrefixPolicy{})
+	registry.SetPrefixPolicy("shared", "tenant/", policy.PrefixPolicy{MaxBytes: 10})
+	registry.SetPrefixPolicy("shared", "tenant/tagged/", policy.PrefixPolicy{MaxBytes: 10, RequiredMetadata: []string{"Owner"}})
+
+	svc := service.New(store, service.WithPrefixPolicies(registry))
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		require.ErrorIs(t, put(ctx, svc, "archive/2024.log", "new", 3, nil), fs.ErrAccessDenied)
+		require.ErrorIs(t, svc.DeleteObject(ctx, "shared", "archive/2023.log"), fs.ErrAccessDenied)
+		require.ErrorIs(t, svc.PutObjectTagging(ctx, "shared", "archive/2023.log", []fs.Tag{{Key: "k", Value: "v"}}), fs.ErrAccessDenied)
+
+		_, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "archive/big"})
+		require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+		// Reads are unaffected.
+		resp, err := svc.GetObject(ctx, "shared", "archive/2023.log")
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+
+		// The more specific prefix wins.
+		require.NoError(t, put(ctx, svc, "archive/inbox/upload", "ok", 2, nil))
+	})
+
+	t.Run("Quota", func(t *testing.T) {
+		require.NoError(t, put(ctx, svc, "tenant/a", "123456", 6, nil))
+		require.ErrorIs(t, put(ctx, svc, "tenant/b", "123456", 6, nil), fs.ErrQuotaExceeded)
+
+		// Overwriting replaces the old size rather than adding to it.
+		require.NoError(t, put(ctx, svc, "tenant/a", "12345678", 8, nil))
+
+		// Without a declared size the body is cut off at the quota.
+		require.ErrorIs(t, put(ctx, svc, "tenant/c", "12345", -1, nil), fs.ErrQuotaExceeded)
+		require.NoError(t, put(ctx, svc, "tenant/c", "12", -1, nil))
+
+		_, err := store.GetObject(ctx, "shared", "tenant/b")
+		require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+		// The read-only prefix next door has no quota, and the quota no
+		// read-only restriction.
+		require.NoError(t, svc.DeleteObject(ctx, "shared", "tenant/c"))
+		require.NoError(t, put(ctx, svc, "archive/inbox/large", strings.Repeat("x", 64), 64, nil))
+	})
+
+	t.Run("QuotaMultipart", func(t *testing.T) {
+		upload, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "tenant/mp"})
+		require.NoError(t, err)
+
+		part, err := svc.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket: "shared", Key: "tenant/mp", UploadID: upload.UploadID, PartNumber: 1,
+			Reader: bytes.NewReader([]byte("12345")), Size: 5,
+		})
+		require.NoError(t, err)
+
+		_, err = svc.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+			Bucket: "shared", Key: "tenant/mp", UploadID: upload.UploadID,
+			Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+		})
+		require.ErrorIs(t, err, fs.ErrQuotaExceeded)
+	})
+
+	t.Run("RequiredMetadata", func(t *testing.T) {
+		require.ErrorIs(t, put(ctx, svc, "tenant/tagged/x", "x", 1, nil), fs.ErrMissingMetadata)
+		require.ErrorIs(t, put(ctx, svc, "tenant/tagged/x", "x", 1, map[string]string{"owner": ""}), fs.ErrMissingMetadata)
+		require.NoError(t, put(ctx, svc, "tenant/tagged/x", "x", 1, map[string]string{"owner": "team-a"}))
+
+		_, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "tenant/tagged/mp"})
+		require.ErrorIs(t, err, fs.ErrMissingMetadata)
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		registry.RemovePrefixPolicy("shared", "archive/")
+		require.NoError(t, svc.DeleteObject(ctx, "shared", "archive/2023.log"))
+	})
+}
+
+func TestService_AppendOnly(t *testing.T) {
+	ctx := t.Context()
+	store := storagemem.New()
+	require.NoError(t, store.CreateBucket(ctx, "shared"))
+
+	registry := policy.NewRegistry()
+	registry.SetPrefixPolicy("shared", "audit/", policy.PrefixPolicy{AppendOnly: true})
+
+	svc := service.New(store, service.WithPrefixPolicies(registry))
+
+	// The first write succeeds.
+	require.NoError(t, put(ctx, svc, "audit/2024-01-01.log", "entry", 5, nil))
+
+	t.Run("Overwrite", func(t *testing.T) {
+		require.ErrorIs(t, put(ctx, svc, "audit/2024-01-01.log", "forged", 6, nil), fs.ErrAccessDenied)
+
+		_, err := svc.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "shared", Key: "audit/2024-01-01.log", Reader: strings.NewReader("x"), Size: 1, IfMatch: "*",
+		})
+		require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+		// A client's own If-None-Match keeps its precondition semantics.
+		_, err = svc.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "shared", Key: "audit/2024-01-01.log", Reader: strings.NewReader("x"), Size: 1, IfNoneMatch: "*",
+		})
+		require.ErrorIs(t, err, fs.ErrPreconditionFailed)
+
+		_, err = svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "audit/2024-01-01.log"})
+		require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+		// An upload started before the key was written cannot complete over it.
+		upload, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "shared", Key: "audit/raced.log"})
+		require.NoError(t, err)
+
+		part, err := svc.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket: "shared", Key: "audit/raced.log", UploadID: upload.UploadID, PartNumber: 1,
+			Reader: strings.NewReader("part"), Size: 4,
+		})
+		require.NoError(t, err)
+		require.NoError(t, put(ctx, svc, "audit/raced.log", "first", 5, nil))
+
+		_, err = svc.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+			Bucket: "shared", Key: "audit/raced.log", UploadID: upload.UploadID,
+			Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+		})
+		require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+		resp, err := svc.GetObject(ctx, "shared", "audit/2024-01-01.log")
+		require.NoError(t, err)
+
+		var got bytes.Buffer
+		_, err = got.ReadFrom(resp.Reader)
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+		require.Equal(t, "entry", got.String())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.ErrorIs(t, svc.DeleteObject(ctx, "shared", "audit/2024-01-01.log"), fs.ErrAccessDenied)
+		require.ErrorIs(t, svc.DeleteObject(ctx, "shared", "audit/missing.log"), fs.ErrAccessDenied)
+
+		// Outside the prefix nothing changes.
+		require.NoError(t, put(ctx, svc, "scratch", "a", 1, nil))
+		require.NoError(t, put(ctx, svc, "scratch", "b", 1, nil))
+		require.NoError(t, svc.DeleteObject(ctx, "shared", "scratch"))
+	})
+
+	t.Run("DeleteBucket", func(t *testing.T) {
+		require.NoError(t, store.CreateBucket(ctx, "logs"))
+		registry.SetPrefixPolicy("logs", "", policy.PrefixPolicy{AppendOnly: true})
+		require.ErrorIs(t, svc.DeleteBucket(ctx, "logs"), fs.ErrAccessDenied)
+
+		registry.RemovePrefixPolicy("logs", "")
+		require.NoError(t, svc.DeleteBucket(ctx, "logs"))
+	})
+}
+
+func TestService_PutObjectRangePolicies(t *testing.T) {
+	ctx := t.Context()
+
+	// storagefs writes ranges in place, past PutObject and its policy checks.
+	store, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.CreateBucket(ctx, "shared"))
+
+	registry := policy.NewRegistry()
+	svc := service.New(store, service.WithPrefixPolicies(registry))
+
+	require.NoError(t, put(ctx, svc, "audit/log", "entry", 5, nil))
+	require.NoError(t, put(ctx, svc, "quota/blob", "1234", 4, nil))
+	registry.SetPrefixPolicy("shared", "audit/", policy.PrefixPolicy{AppendOnly: true})
+	registry.SetPrefixPolicy("shared", "quota/", policy.PrefixPolicy{MaxBytes: 6})
+
+	_, err = fs.PutObjectRange(ctx, svc, "shared", "audit/log", 0, strings.NewReader("E"), 1)
+	require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+	_, err = fs.PutObjectRange(ctx, svc, "shared", "quota/blob", 4, strings.NewReader("567"), 3)
+	require.ErrorIs(t, err, fs.ErrQuotaExceeded)
+
+	_, err = fs.PutObjectRange(ctx, svc, "shared", "quota/blob", 4, strings.NewReader("56"), 2)
+	require.NoError(t, err)
+
+	resp, err := svc.GetObject(ctx, "shared", "audit/log")
+	require.NoError(t, err)
+
+	var got bytes.Buffer
+	_, err = got.ReadFrom(resp.Reader)
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+	require.Equal(t, "entry", got.String())
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"io"
+	"iter"
 
 	"github.com/go-faster/errors"
 
@@ -9,14 +11,86 @@ import (
 	"github.com/go-faster/fs/internal/validate"
 )
 
-var _ fs.Storage = (*Service)(nil)
+var (
+	_ fs.Storage      = (*Service)(nil)
+	_ fs.ObjectWalker = (*Service)(nil)
+	_ fs.RangeWriter  = (*Service)(nil)
+)
 
-func New(storage fs.Storage) *Service {
-	return &Service{storage: storage}
+func New(storage fs.Storage, opts ...Option) *Service {
+	s := &Service{
+		storage:         storage,
+		maxKeyLength:    validate.MaxKeyLength,
+		maxMetadataSize: MaxMetadataSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 type Service struct {
-	storage fs.Storage
+	storage         fs.Storage
+	maxKeyLength    int
+	maxMetadataSize int
+	policies        PolicyResolver
+	defaults        DefaultsResolver
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithMaxKeyLength sets the length limit in bytes for keys of new objects
+// (default validate.MaxKeyLength, the S3 limit): PUT, multipart uploads and
+// everything built on them. Longer keys fail with fs.ErrKeyTooLong before
+// reaching the backend. Reads, deletes and the other requests on existing
+// objects accept any key up to the S3 limit.
+func WithMaxKeyLength(n int) Option {
+	return func(s *Service) { s.maxKeyLength = n }
+}
+
+// MaxMetadataSize is the S3 limit on user metadata, in bytes.
+const MaxMetadataSize = 2 << 10
+
+// WithMaxMetadataSize sets the user metadata size limit in bytes (default
+// MaxMetadataSize); n <= 0 removes it. Larger metadata fails with
+// fs.ErrMetadataTooLarge before reaching the backend.
+func WithMaxMetadataSize(n int) Option {
+	return func(s *Service) { s.maxMetadataSize = n }
+}
+
+// validateMetadata enforces the user metadata size limit. As on S3, the size
+// is the UTF-8 byte length of every x-amz-meta-* name (without the prefix)
+// and value; the representation headers (Content-Type etc.) don't count.
+func (s Service) validateMetadata(m fs.ObjectMetadata) error {
+	if s.maxMetadataSize <= 0 {
+		return nil
+	}
+
+	var size int
+	for k, v := range m.UserMetadata {
+		size += len(k) + len(v)
+	}
+
+	if size > s.maxMetadataSize {
+		return errors.Wrapf(fs.ErrMetadataTooLarge, "%d bytes of user metadata exceed the limit of %d", size, s.maxMetadataSize)
+	}
+
+	return nil
+}
+
+// validateNewKey validates the key of an object being created against the
+// configured length limit.
+func (s Service) validateNewKey(key string) error {
+	return validate.KeyWithMaxLength(key, s.maxKeyLength)
+}
+
+// validateKey validates the key of an existing object. It is held to the S3
+// limit, or the configured one if that is higher, so lowering the limit
+// leaves longer keys stored before it readable and deletable.
+func (s Service) validateKey(key string) error {
+	return validate.KeyWithMaxLength(key, max(s.maxKeyLength, validate.MaxKeyLength))
 }
 
 func (s Service) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.Object, error) {
@@ -31,20 +105,83 @@ func (s Service) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.O
 	return s.storage.ListObjects(ctx, bucket, prefix)
 }
 
+// WalkObjects is ListObjects streamed: it validates like ListObjects and
+// yields the storage's objects as fs.WalkObjects finds them, so the listing
+// helpers need not build the whole listing through the service.
+func (s Service) WalkObjects(ctx context.Context, bucket, prefix string) iter.Seq2[fs.Object, error] {
+	if err := validate.BucketName(bucket); err != nil {
+		return func(yield func(fs.Object, error) bool) { yield(fs.Object{}, errors.Wrap(err, "validate bucket name")) }
+	}
+
+	if err := validate.Prefix(prefix); err != nil {
+		return func(yield func(fs.Object, error) bool) { yield(fs.Object{}, errors.Wrap(err, "validate prefix")) }
+	}
+
+	return fs.WalkObjects(ctx, s.storage, bucket, prefix)
+}
+
 func (s Service) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
 	if err := validate.BucketName(req.Bucket); err != nil {
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(req.Key); err != nil {
+	if err := s.validateNewKey(req.Key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
+	if s.defaults != nil {
+		merged := *req
+		merged.Metadata, merged.Tags = s.withDefaults(req.Bucket, req.Metadata, req.Tags)
+		req = &merged
+	}
+
 	if err := validateTags(req.Tags); err != nil {
 		return nil, err
 	}
 
-	return s.storage.PutObject(ctx, req)
+	if err := s.validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+
+	req, err := s.enforceUpload(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	req, own, err := s.appendOnlyUpload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.storage.PutObject(ctx, req)
+	if err != nil && !own && errors.Is(err, fs.ErrPreconditionFailed) {
+		if prefix, ok := s.appendOnly(req.Bucket, req.Key); ok {
+			return nil, appendOnlyError(req.Bucket, req.Key, prefix)
+		}
+	}
+
+	return resp, err
+}
+
+// PutObjectRange validates like the requests on existing objects and lets
+// fs.PutObjectRange patch the object through the storage, in place where the
+// storage can. An object governed by a prefix policy is rewritten through
+// PutObject instead (fs.RewriteObjectRange), so that read-only, append-only
+// and quota rules apply to the new content.
+func (s Service) PutObjectRange(ctx context.Context, bucket, key string, offset int64, r io.Reader, size int64) (*fs.PutObjectResponse, error) {
+	if err := validate.BucketName(bucket); err != nil {
+		return nil, errors.Wrap(err, "validate bucket name")
+	}
+
+	if err := s.validateKey(key); err != nil {
+		return nil, errors.Wrap(err, "validate object key")
+	}
+
+	if _, _, ok := s.prefixPolicy(bucket, key); ok {
+		return fs.RewriteObjectRange(ctx, s, bucket, key, offset, r, size)
+	}
+
+	return fs.PutObjectRange(ctx, s.storage, bucket, key, offset, r, size)
 }
 
 // S3 object-tagging limits.
@@ -87,7 +224,7 @@ func (s Service) GetObjectTagging(ctx context.Context, bucket, key string) ([]fs
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
@@ -99,7 +236,7 @@ func (s Service) PutObjectTagging(ctx context.Context, bucket, key string, tags
 		return errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return errors.Wrap(err, "validate object key")
 	}
 
@@ -107,6 +244,10 @@ func (s Service) PutObjectTagging(ctx context.Context, bucket, key string, tags
 		return err
 	}
 
+	if err := s.checkWritable(bucket, key); err != nil {
+		return err
+	}
+
 	return s.storage.PutObjectTagging(ctx, bucket, key, tags)
 }
 
@@ -115,13 +256,43 @@ func (s Service) DeleteObjectTagging(ctx context.Context, bucket, key string) er
 		return errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return errors.Wrap(err, "validate object key")
 	}
 
+	if err := s.checkWritable(bucket, key); err != nil {
+		return err
+	}
+
 	return s.storage.DeleteObjectTagging(ctx, bucket, key)
 }
 
+// PutObjectLegalHold is allowed under read-only prefixes: a hold only
+// restricts the object further.
+func (s Service) PutObjectLegalHold(ctx context.Context, bucket, key string, on bool) error {
+	if err := validate.BucketName(bucket); err != nil {
+		return errors.Wrap(err, "validate bucket name")
+	}
+
+	if err := s.validateKey(key); err != nil {
+		return errors.Wrap(err, "validate object key")
+	}
+
+	return s.storage.PutObjectLegalHold(ctx, bucket, key, on)
+}
+
+func (s Service) GetObjectLegalHold(ctx context.Context, bucket, key string) (bool, error) {
+	if err := validate.BucketName(bucket); err != nil {
+		return false, errors.Wrap(err, "validate bucket name")
+	}
+
+	if err := s.validateKey(key); err != nil {
+		return false, errors.Wrap(err, "validate object key")
+	}
+
+	return s.storage.GetObjectLegalHold(ctx, bucket, key)
+}
+
 func (s Service) SetBucketACL(ctx context.Context, bucket string, acl fs.ACL) error {
 	if err := validate.BucketName(bucket); err != nil {
 		return errors.Wrap(err, "validate bucket name")
@@ -138,12 +309,28 @@ func (s Service) BucketACL(ctx context.Context, bucket string) (fs.ACL, error) {
 	return s.storage.BucketACL(ctx, bucket)
 }
 
+func (s Service) SetBucketRegion(ctx context.Context, bucket, region string) error {
+	if err := validate.BucketName(bucket); err != nil {
+		return errors.Wrap(err, "validate bucket name")
+	}
+
+	return s.storage.SetBucketRegion(ctx, bucket, region)
+}
+
+func (s Service) BucketRegion(ctx context.Context, bucket string) (string, error) {
+	if err := validate.BucketName(bucket); err != nil {
+		return "", errors.Wrap(err, "validate bucket name")
+	}
+
+	return s.storage.BucketRegion(ctx, bucket)
+}
+
 func (s Service) ObjectACL(ctx context.Context, bucket, key string) (fs.ACL, error) {
 	if err := validate.BucketName(bucket); err != nil {
 		return fs.ACLPrivate, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return fs.ACLPrivate, errors.Wrap(err, "validate object key")
 	}
 
@@ -167,6 +354,10 @@ func (s Service) DeleteBucket(ctx context.Context, bucket string) error {
 		return errors.Wrap(err, "validate bucket name")
 	}
 
+	if err := s.checkBucketDeletable(bucket); err != nil {
+		return err
+	}
+
 	return s.storage.DeleteBucket(ctx, bucket)
 }
 
@@ -183,10 +374,14 @@ func (s Service) DeleteObject(ctx context.Context, bucket, key string) error {
 		return errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return errors.Wrap(err, "validate object key")
 	}
 
+	if err := s.checkDeletable(bucket, key); err != nil {
+		return err
+	}
+
 	return s.storage.DeleteObject(ctx, bucket, key)
 }
 
@@ -195,7 +390,7 @@ func (s Service) GetObject(ctx context.Context, bucket, key string) (*fs.GetObje
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
@@ -207,14 +402,32 @@ func (s Service) CreateMultipartUpload(ctx context.Context, req *fs.CreateMultip
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(req.Key); err != nil {
+	if err := s.validateNewKey(req.Key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
+	if s.defaults != nil {
+		merged := *req
+		merged.Metadata, merged.Tags = s.withDefaults(req.Bucket, req.Metadata, req.Tags)
+		req = &merged
+	}
+
 	if err := validateTags(req.Tags); err != nil {
 		return nil, err
 	}
 
+	if err := s.validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNewObject(req.Bucket, req.Key, req.Metadata); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNotExists(ctx, req.Bucket, req.Key); err != nil {
+		return nil, err
+	}
+
 	return s.storage.CreateMultipartUpload(ctx, req)
 }
 
@@ -233,7 +446,7 @@ func (s Service) UploadPart(ctx context.Context, req *fs.UploadPartRequest) (*fs
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(req.Key); err != nil {
+	if err := s.validateKey(req.Key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
@@ -241,6 +454,11 @@ func (s Service) UploadPart(ctx context.Context, req *fs.UploadPartRequest) (*fs
 		return nil, errors.Wrapf(fs.ErrInvalidPartNumber, "part number %d", req.PartNumber)
 	}
 
+	// The policy may have changed since the upload was created.
+	if err := s.checkWritable(req.Bucket, req.Key); err != nil {
+		return nil, err
+	}
+
 	return s.storage.UploadPart(ctx, req)
 }
 
@@ -249,7 +467,7 @@ func (s Service) ListParts(ctx context.Context, bucket, key, uploadID string) ([
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
@@ -269,10 +487,20 @@ func (s Service) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteMu
 		return nil, errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(req.Key); err != nil {
+	if err := s.validateNewKey(req.Key); err != nil {
 		return nil, errors.Wrap(err, "validate object key")
 	}
 
+	// The object is stored under StoreAs when set; the policies apply there.
+	target := req.Key
+	if req.StoreAs != "" {
+		if err := s.validateNewKey(req.StoreAs); err != nil {
+			return nil, errors.Wrap(err, "validate object key")
+		}
+
+		target = req.StoreAs
+	}
+
 	if len(req.Parts) == 0 {
 		return nil, errors.Wrap(fs.ErrInvalidPart, "no parts specified")
 	}
@@ -312,6 +540,30 @@ func (s Service) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteMu
 		}
 	}
 
+	if err := s.checkWritable(req.Bucket, target); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNotExists(ctx, req.Bucket, target); err != nil {
+		return nil, err
+	}
+
+	remaining, ok, err := s.quotaRemaining(ctx, req.Bucket, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		var size int64
+		for _, part := range req.Parts {
+			size += byNumber[part.PartNumber].Size
+		}
+
+		if size > remaining {
+			return nil, quotaError(req.Bucket, target, size, remaining)
+		}
+	}
+
 	return s.storage.CompleteMultipartUpload(ctx, req)
 }
 
@@ -320,7 +572,7 @@ func (s Service) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID
 		return errors.Wrap(err, "validate bucket name")
 	}
 
-	if err := validate.Key(key); err != nil {
+	if err := s.validateKey(key); err != nil {
 		return errors.Wrap(err, "validate object key")
 	}
 
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -56,6 +57,7 @@ func TestService_CreateBucket(t *testing.T) {
 		err := svc.CreateBucket(ctx, "INVALID")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 }
 
@@ -84,6 +86,7 @@ func TestService_DeleteBucket(t *testing.T) {
 		err := svc.DeleteBucket(ctx, "INVALID")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 }
 
@@ -120,6 +123,7 @@ func TestService_ListObjects(t *testing.T) {
 		_, err := svc.ListObjects(ctx, "INVALID", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidPrefix", func(t *testing.T) {
@@ -131,6 +135,7 @@ func TestService_ListObjects(t *testing.T) {
 		_, err := svc.ListObjects(ctx, "valid-bucket", "invalid\x00prefix")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate prefix")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -167,6 +172,7 @@ func TestService_PutObject(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -181,6 +187,72 @@ func TestService_PutObject(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
+	})
+
+	t.Run("MaxKeyLength", func(t *testing.T) {
+		storage := &mock.StorageMock{
+			PutObjectFunc: func(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
+				return &fs.PutObjectResponse{ETag: "etag"}, nil
+			},
+			GetObjectFunc: func(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+				return &fs.GetObjectResponse{}, nil
+			},
+			DeleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+				return nil
+			},
+		}
+
+		svc := service.New(storage, service.WithMaxKeyLength(8))
+		ctx := t.Context()
+
+		_, err := svc.PutObject(ctx, &fs.PutObjectRequest{Bucket: "valid-bucket", Key: "12345678"})
+		require.NoError(t, err)
+
+		_, err = svc.PutObject(ctx, &fs.PutObjectRequest{Bucket: "valid-bucket", Key: "123456789"})
+		require.ErrorIs(t, err, fs.ErrKeyTooLong)
+		require.Len(t, storage.PutObjectCalls(), 1, "over-long key must not reach the backend")
+
+		_, err = svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "valid-bucket", Key: "123456789"})
+		require.ErrorIs(t, err, fs.ErrKeyTooLong)
+
+		// Objects stored before the limit was lowered stay reachable.
+		_, err = svc.GetObject(ctx, "valid-bucket", "123456789")
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteObject(ctx, "valid-bucket", "123456789"))
+
+		_, err = svc.GetObject(ctx, "valid-bucket", strings.Repeat("k", 1025))
+		require.ErrorIs(t, err, fs.ErrKeyTooLong)
+	})
+
+	t.Run("MaxMetadataSize", func(t *testing.T) {
+		storage := &mock.StorageMock{
+			PutObjectFunc: func(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
+				return &fs.PutObjectResponse{ETag: "etag"}, nil
+			},
+		}
+
+		put := func(svc *service.Service, meta map[string]string) error {
+			_, err := svc.PutObject(t.Context(), &fs.PutObjectRequest{
+				Bucket:   "valid-bucket",
+				Key:      "key",
+				Metadata: fs.ObjectMetadata{ContentType: strings.Repeat("t", 4096), UserMetadata: meta},
+			})
+
+			return err
+		}
+
+		// Names and values count, in UTF-8 bytes ("é" is two).
+		atLimit := map[string]string{"a": strings.Repeat("x", 1000), "bé": strings.Repeat("y", 1044)}
+		overLimit := map[string]string{"a": strings.Repeat("x", 1001), "bé": strings.Repeat("y", 1044)}
+
+		svc := service.New(storage)
+		require.NoError(t, put(svc, atLimit))
+		require.ErrorIs(t, put(svc, overLimit), fs.ErrMetadataTooLarge)
+		require.Len(t, storage.PutObjectCalls(), 1, "oversized metadata must not reach the backend")
+
+		require.NoError(t, put(service.New(storage, service.WithMaxMetadataSize(4096)), overLimit))
+		require.NoError(t, put(service.New(storage, service.WithMaxMetadataSize(0)), overLimit))
 	})
 }
 
@@ -211,6 +283,7 @@ func TestService_DeleteObject(t *testing.T) {
 		err := svc.DeleteObject(ctx, "INVALID", "valid-key.txt")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -222,6 +295,7 @@ func TestService_DeleteObject(t *testing.T) {
 		err := svc.DeleteObject(ctx, "valid-bucket", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -258,6 +332,7 @@ func TestService_GetObject(t *testing.T) {
 		_, err := svc.GetObject(ctx, "INVALID", "valid-key.txt")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -269,6 +344,7 @@ func TestService_GetObject(t *testing.T) {
 		_, err := svc.GetObject(ctx, "valid-bucket", "")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -313,6 +389,7 @@ func TestService_BucketExists(t *testing.T) {
 		_, err := svc.BucketExists(ctx, "INVALID")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 }
 
@@ -350,6 +427,7 @@ func TestService_CreateMultipartUpload(t *testing.T) {
 		_, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "INVALID", Key: "valid-key.txt"})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -361,6 +439,7 @@ func TestService_CreateMultipartUpload(t *testing.T) {
 		_, err := svc.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "valid-bucket", Key: ""})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -407,6 +486,7 @@ func TestService_UploadPart(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -422,6 +502,7 @@ func TestService_UploadPart(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -475,6 +556,7 @@ func TestService_CompleteMultipartUpload(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -490,6 +572,7 @@ func TestService_CompleteMultipartUpload(t *testing.T) {
 		})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
 
@@ -521,6 +604,7 @@ func TestService_AbortMultipartUpload(t *testing.T) {
 		err := svc.AbortMultipartUpload(ctx, "INVALID", "valid-key.txt", "upload-123")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate bucket name")
+		require.ErrorIs(t, err, fs.ErrInvalidBucketName)
 	})
 
 	t.Run("InvalidKey", func(t *testing.T) {
@@ -532,5 +616,6 @@ func TestService_AbortMultipartUpload(t *testing.T) {
 		err := svc.AbortMultipartUpload(ctx, "valid-bucket", "", "upload-123")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "validate object key")
+		require.ErrorIs(t, err, fs.ErrInvalidKey)
 	})
 }
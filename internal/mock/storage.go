@@ -29,6 +29,9 @@ var _ fs.Storage = &StorageMock{}
 //			BucketExistsFunc: func(ctx context.Context, bucket string) (bool, error) {
 //				panic("mock out the BucketExists method")
 //			},
+//			BucketRegionFunc: func(ctx context.Context, bucket string) (string, error) {
+//				panic("mock out the BucketRegion method")
+//			},
 //			CompleteMultipartUploadFunc: func(ctx context.Context, req *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error) {
 //				panic("mock out the CompleteMultipartUpload method")
 //			},
@@ -50,6 +53,9 @@ var _ fs.Storage = &StorageMock{}
 //			GetObjectFunc: func(ctx context.Context, bucket string, key string) (*fs.GetObjectResponse, error) {
 //				panic("mock out the GetObject method")
 //			},
+//			GetObjectLegalHoldFunc: func(ctx context.Context, bucket string, key string) (bool, error) {
+//				panic("mock out the GetObjectLegalHold method")
+//			},
 //			GetObjectTaggingFunc: func(ctx context.Context, bucket string, key string) ([]fs.Tag, error) {
 //				panic("mock out the GetObjectTagging method")
 //			},
@@ -71,12 +77,18 @@ var _ fs.Storage = &StorageMock{}
 //			PutObjectFunc: func(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
 //				panic("mock out the PutObject method")
 //			},
+//			PutObjectLegalHoldFunc: func(ctx context.Context, bucket string, key string, on bool) error {
+//				panic("mock out the PutObjectLegalHold method")
+//			},
 //			PutObjectTaggingFunc: func(ctx context.Context, bucket string, key string, tags []fs.Tag) error {
 //				panic("mock out the PutObjectTagging method")
 //			},
 //			SetBucketACLFunc: func(ctx context.Context, bucket string, acl fs.ACL) error {
 //				panic("mock out the SetBucketACL method")
 //			},
+//			SetBucketRegionFunc: func(ctx context.Context, bucket string, region string) error {
+//				panic("mock out the SetBucketRegion method")
+//			},
 //			UploadPartFunc: func(ctx context.Context, req *fs.UploadPartRequest) (*fs.Part, error) {
 //				panic("mock out the UploadPart method")
 //			},
@@ -96,6 +108,9 @@ type StorageMock struct {
 	// BucketExistsFunc mocks the BucketExists method.
 	BucketExistsFunc func(ctx context.Context, bucket string) (bool, error)
 
+	// BucketRegionFunc mocks the BucketRegion method.
+	BucketRegionFunc func(ctx context.Context, bucket string) (string, error)
+
 	// CompleteMultipartUploadFunc mocks the CompleteMultipartUpload method.
 	CompleteMultipartUploadFunc func(ctx context.Context, req *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error)
 
@@ -117,6 +132,9 @@ type StorageMock struct {
 	// GetObjectFunc mocks the GetObject method.
 	GetObjectFunc func(ctx context.Context, bucket string, key string) (*fs.GetObjectResponse, error)
 
+	// GetObjectLegalHoldFunc mocks the GetObjectLegalHold method.
+	GetObjectLegalHoldFunc func(ctx context.Context, bucket string, key string) (bool, error)
+
 	// GetObjectTaggingFunc mocks the GetObjectTagging method.
 	GetObjectTaggingFunc func(ctx context.Context, bucket string, key string) ([]fs.Tag, error)
 
@@ -138,12 +156,18 @@ type StorageMock struct {
 	// PutObjectFunc mocks the PutObject method.
 	PutObjectFunc func(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error)
 
+	// PutObjectLegalHoldFunc mocks the PutObjectLegalHold method.
+	PutObjectLegalHoldFunc func(ctx context.Context, bucket string, key string, on bool) error
+
 	// PutObjectTaggingFunc mocks the PutObjectTagging method.
 	PutObjectTaggingFunc func(ctx context.Context, bucket string, key string, tags []fs.Tag) error
 
 	// SetBucketACLFunc mocks the SetBucketACL method.
 	SetBucketACLFunc func(ctx context.Context, bucket string, acl fs.ACL) error
 
+	// SetBucketRegionFunc mocks the SetBucketRegion method.
+	SetBucketRegionFunc func(ctx context.Context, bucket string, region string) error
+
 	// UploadPartFunc mocks the UploadPart method.
 	UploadPartFunc func(ctx context.Context, req *fs.UploadPartRequest) (*fs.Part, error)
 
@@ -174,6 +198,13 @@ type StorageMock struct {
 			// Bucket is the bucket argument value.
 			Bucket string
 		}
+		// BucketRegion holds details about calls to the BucketRegion method.
+		BucketRegion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bucket is the bucket argument value.
+			Bucket string
+		}
 		// CompleteMultipartUpload holds details about calls to the CompleteMultipartUpload method.
 		CompleteMultipartUpload []struct {
 			// Ctx is the ctx argument value.
@@ -229,6 +260,15 @@ type StorageMock struct {
 			// Key is the key argument value.
 			Key string
 		}
+		// GetObjectLegalHold holds details about calls to the GetObjectLegalHold method.
+		GetObjectLegalHold []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bucket is the bucket argument value.
+			Bucket string
+			// Key is the key argument value.
+			Key string
+		}
 		// GetObjectTagging holds details about calls to the GetObjectTagging method.
 		GetObjectTagging []struct {
 			// Ctx is the ctx argument value.
@@ -286,6 +326,17 @@ type StorageMock struct {
 			// Req is the req argument value.
 			Req *fs.PutObjectRequest
 		}
+		// PutObjectLegalHold holds details about calls to the PutObjectLegalHold method.
+		PutObjectLegalHold []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bucket is the bucket argument value.
+			Bucket string
+			// Key is the key argument value.
+			Key string
+			// On is the on argument value.
+			On bool
+		}
 		// PutObjectTagging holds details about calls to the PutObjectTagging method.
 		PutObjectTagging []struct {
 			// Ctx is the ctx argument value.
@@ -306,6 +357,15 @@ type StorageMock struct {
 			// ACL is the acl argument value.
 			ACL fs.ACL
 		}
+		// SetBucketRegion holds details about calls to the SetBucketRegion method.
+		SetBucketRegion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Bucket is the bucket argument value.
+			Bucket string
+			// Region is the region argument value.
+			Region string
+		}
 		// UploadPart holds details about calls to the UploadPart method.
 		UploadPart []struct {
 			// Ctx is the ctx argument value.
@@ -317,6 +377,7 @@ type StorageMock struct {
 	lockAbortMultipartUpload    sync.RWMutex
 	lockBucketACL               sync.RWMutex
 	lockBucketExists            sync.RWMutex
+	lockBucketRegion            sync.RWMutex
 	lockCompleteMultipartUpload sync.RWMutex
 	lockCreateBucket            sync.RWMutex
 	lockCreateMultipartUpload   sync.RWMutex
@@ -324,6 +385,7 @@ type StorageMock struct {
 	lockDeleteObject            sync.RWMutex
 	lockDeleteObjectTagging     sync.RWMutex
 	lockGetObject               sync.RWMutex
+	lockGetObjectLegalHold      sync.RWMutex
 	lockGetObjectTagging        sync.RWMutex
 	lockListBuckets             sync.RWMutex
 	lockListMultipartUploads    sync.RWMutex
@@ -331,8 +393,10 @@ type StorageMock struct {
 	lockListParts               sync.RWMutex
 	lockObjectACL               sync.RWMutex
 	lockPutObject               sync.RWMutex
+	lockPutObjectLegalHold      sync.RWMutex
 	lockPutObjectTagging        sync.RWMutex
 	lockSetBucketACL            sync.RWMutex
+	lockSetBucketRegion         sync.RWMutex
 	lockUploadPart              sync.RWMutex
 }
 
@@ -452,6 +516,42 @@ func (mock *StorageMock) BucketExistsCalls() []struct {
 	return calls
 }
 
+// BucketRegion calls BucketRegionFunc.
+func (mock *StorageMock) BucketRegion(ctx context.Context, bucket string) (string, error) {
+	if mock.BucketRegionFunc == nil {
+		panic("StorageMock.BucketRegionFunc: method is nil but Storage.BucketRegion was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bucket string
+	}{
+		Ctx:    ctx,
+		Bucket: bucket,
+	}
+	mock.lockBucketRegion.Lock()
+	mock.calls.BucketRegion = append(mock.calls.BucketRegion, callInfo)
+	mock.lockBucketRegion.Unlock()
+	return mock.BucketRegionFunc(ctx, bucket)
+}
+
+// BucketRegionCalls gets all the calls that were made to BucketRegion.
+// Check the length with:
+//
+//	len(mockedStorage.BucketRegionCalls())
+func (mock *StorageMock) BucketRegionCalls() []struct {
+	Ctx    context.Context
+	Bucket string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bucket string
+	}
+	mock.lockBucketRegion.RLock()
+	calls = mock.calls.BucketRegion
+	mock.lockBucketRegion.RUnlock()
+	return calls
+}
+
 // CompleteMultipartUpload calls CompleteMultipartUploadFunc.
 func (mock *StorageMock) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error) {
 	if mock.CompleteMultipartUploadFunc == nil {
@@ -716,6 +816,46 @@ func (mock *StorageMock) GetObjectCalls() []struct {
 	return calls
 }
 
+// GetObjectLegalHold calls GetObjectLegalHoldFunc.
+func (mock *StorageMock) GetObjectLegalHold(ctx context.Context, bucket string, key string) (bool, error) {
+	if mock.GetObjectLegalHoldFunc == nil {
+		panic("StorageMock.GetObjectLegalHoldFunc: method is nil but Storage.GetObjectLegalHold was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bucket string
+		Key    string
+	}{
+		Ctx:    ctx,
+		Bucket: bucket,
+		Key:    key,
+	}
+	mock.lockGetObjectLegalHold.Lock()
+	mock.calls.GetObjectLegalHold = append(mock.calls.GetObjectLegalHold, callInfo)
+	mock.lockGetObjectLegalHold.Unlock()
+	return mock.GetObjectLegalHoldFunc(ctx, bucket, key)
+}
+
+// GetObjectLegalHoldCalls gets all the calls that were made to GetObjectLegalHold.
+// Check the length with:
+//
+//	len(mockedStorage.GetObjectLegalHoldCalls())
+func (mock *StorageMock) GetObjectLegalHoldCalls() []struct {
+	Ctx    context.Context
+	Bucket string
+	Key    string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bucket string
+		Key    string
+	}
+	mock.lockGetObjectLegalHold.RLock()
+	calls = mock.calls.GetObjectLegalHold
+	mock.lockGetObjectLegalHold.RUnlock()
+	return calls
+}
+
 // GetObjectTagging calls GetObjectTaggingFunc.
 func (mock *StorageMock) GetObjectTagging(ctx context.Context, bucket string, key string) ([]fs.Tag, error) {
 	if mock.GetObjectTaggingFunc == nil {
@@ -984,6 +1124,50 @@ func (mock *StorageMock) PutObjectCalls() []struct {
 	return calls
 }
 
+// PutObjectLegalHold calls PutObjectLegalHoldFunc.
+func (mock *StorageMock) PutObjectLegalHold(ctx context.Context, bucket string, key string, on bool) error {
+	if mock.PutObjectLegalHoldFunc == nil {
+		panic("StorageMock.PutObjectLegalHoldFunc: method is nil but Storage.PutObjectLegalHold was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bucket string
+		Key    string
+		On     bool
+	}{
+		Ctx:    ctx,
+		Bucket: bucket,
+		Key:    key,
+		On:     on,
+	}
+	mock.lockPutObjectLegalHold.Lock()
+	mock.calls.PutObjectLegalHold = append(mock.calls.PutObjectLegalHold, callInfo)
+	mock.lockPutObjectLegalHold.Unlock()
+	return mock.PutObjectLegalHoldFunc(ctx, bucket, key, on)
+}
+
+// PutObjectLegalHoldCalls gets all the calls that were made to PutObjectLegalHold.
+// Check the length with:
+//
+//	len(mockedStorage.PutObjectLegalHoldCalls())
+func (mock *StorageMock) PutObjectLegalHoldCalls() []struct {
+	Ctx    context.Context
+	Bucket string
+	Key    string
+	On     bool
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bucket string
+		Key    string
+		On     bool
+	}
+	mock.lockPutObjectLegalHold.RLock()
+	calls = mock.calls.PutObjectLegalHold
+	mock.lockPutObjectLegalHold.RUnlock()
+	return calls
+}
+
 // PutObjectTagging calls PutObjectTaggingFunc.
 func (mock *StorageMock) PutObjectTagging(ctx context.Context, bucket string, key string, tags []fs.Tag) error {
 	if mock.PutObjectTaggingFunc == nil {
@@ -1068,6 +1252,46 @@ func (mock *StorageMock) SetBucketACLCalls() []struct {
 	return calls
 }
 
+// SetBucketRegion calls SetBucketRegionFunc.
+func (mock *StorageMock) SetBucketRegion(ctx context.Context, bucket string, region string) error {
+	if mock.SetBucketRegionFunc == nil {
+		panic("StorageMock.SetBucketRegionFunc: method is nil but Storage.SetBucketRegion was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Bucket string
+		Region string
+	}{
+		Ctx:    ctx,
+		Bucket: bucket,
+		Region: region,
+	}
+	mock.lockSetBucketRegion.Lock()
+	mock.calls.SetBucketRegion = append(mock.calls.SetBucketRegion, callInfo)
+	mock.lockSetBucketRegion.Unlock()
+	return mock.SetBucketRegionFunc(ctx, bucket, region)
+}
+
+// SetBucketRegionCalls gets all the calls that were made to SetBucketRegion.
+// Check the length with:
+//
+//	len(mockedStorage.SetBucketRegionCalls())
+func (mock *StorageMock) SetBucketRegionCalls() []struct {
+	Ctx    context.Context
+	Bucket string
+	Region string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Bucket string
+		Region string
+	}
+	mock.lockSetBucketRegion.RLock()
+	calls = mock.calls.SetBucketRegion
+	mock.lockSetBucketRegion.RUnlock()
+	return calls
+}
+
 // UploadPart calls UploadPartFunc.
 func (mock *StorageMock) UploadPart(ctx context.Context, req *fs.UploadPartRequest) (*fs.Part, error) {
 	if mock.UploadPartFunc == nil {
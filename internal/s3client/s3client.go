@@ -0,0 +1,58 @@
+// Package s3client builds the minio clients fs uses to reach other S3
+// endpoints: mirror upstreams, replication peers and the self-test target.
+package s3client
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// New returns a client for the S3 endpoint at rawURL ("https://s3.example.com";
+// https when the scheme is omitted), addressed path-style, and the endpoint
+// as scheme://host. Requests are signed with accessKey and secretKey, or
+// anonymous when both are empty.
+//
+// The client tries each request once: every caller has its own answer to a
+// failure (a mirror falls back to its cache, the replicator backs off on its
+// own budget, the self-test reports it), and minio's backoff would only delay
+// it.
+func New(rawURL, accessKey, secretKey string) (*minio.Client, string, error) {
+	endpoint := rawURL
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "parse endpoint")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", errors.Errorf("endpoint scheme %q is not http or https", u.Scheme)
+	}
+
+	if u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return nil, "", errors.Errorf("endpoint %q must be a bare scheme://host[:port]", rawURL)
+	}
+
+	creds := credentials.NewStaticV4(accessKey, secretKey, "")
+	if accessKey == "" && secretKey == "" {
+		creds = credentials.New(&credentials.Static{})
+	}
+
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        creds,
+		Secure:       u.Scheme == "https",
+		BucketLookup: minio.BucketLookupPath,
+		MaxRetries:   1,
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "create client")
+	}
+
+	return client, u.Scheme + "://" + u.Host, nil
+}
@@ -0,0 +1,38 @@
+package s3client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	for _, tt := range []struct {
+		rawURL   string
+		endpoint string
+		secure   bool
+	}{
+		{rawURL: "s3.example.com", endpoint: "https://s3.example.com", secure: true},
+		{rawURL: "http://localhost:9000", endpoint: "http://localhost:9000"},
+		{rawURL: "https://s3.example.com/", endpoint: "https://s3.example.com", secure: true},
+	} {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			client, endpoint, err := New(tt.rawURL, "key", "secret")
+			require.NoError(t, err)
+			require.Equal(t, tt.endpoint, endpoint)
+			require.Equal(t, tt.secure, client.EndpointURL().Scheme == "https")
+		})
+	}
+
+	for _, rawURL := range []string{
+		"ftp://s3.example.com",
+		"https://",
+		"https://s3.example.com/bucket",
+		"http://[::1",
+	} {
+		t.Run(rawURL, func(t *testing.T) {
+			_, _, err := New(rawURL, "", "")
+			require.Error(t, err)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagefs"
 )
 
 // APIError describes an S3 error: a stable wire code, its HTTP status, and a
@@ -33,20 +34,26 @@ var (
 	BucketAlreadyOwnedByYou = APIError{"BucketAlreadyOwnedByYou", http.StatusConflict, "The bucket you tried to create already exists and you own it."}
 	BucketNotEmpty          = APIError{"BucketNotEmpty", http.StatusConflict, "The bucket you tried to delete is not empty."}
 	InvalidBucketName       = APIError{"InvalidBucketName", http.StatusBadRequest, "The specified bucket is not valid."}
+	IllegalLocation         = APIError{"IllegalLocationConstraintException", http.StatusBadRequest, "The location constraint is incompatible with the region this request was sent to."}
 	InvalidArgument         = APIError{"InvalidArgument", http.StatusBadRequest, "Invalid Argument."}
 	InvalidRequest          = APIError{"InvalidRequest", http.StatusBadRequest, "Invalid Request."}
+	InvalidURI              = APIError{"InvalidURI", http.StatusBadRequest, "Couldn't parse the specified URI."}
 	MalformedXML            = APIError{"MalformedXML", http.StatusBadRequest, "The XML you provided was not well-formed or did not validate against our published schema."}
 	MissingContentLength    = APIError{"MissingContentLength", http.StatusLengthRequired, "You must provide the Content-Length HTTP header."}
 	InvalidPart             = APIError{"InvalidPart", http.StatusBadRequest, "One or more of the specified parts could not be found."}
 	InvalidPartOrder        = APIError{"InvalidPartOrder", http.StatusBadRequest, "The list of parts was not in ascending order. Parts must be ordered by part number."}
-	InvalidPartNumber       = APIError{"InvalidPartNumber", http.StatusBadRequest, "The requested partnumber is not satisfiable."}
+	InvalidPartNumber       = APIError{"InvalidPartNumber", http.StatusRequestedRangeNotSatisfiable, "The requested partnumber is not satisfiable."}
 	EntityTooSmall          = APIError{"EntityTooSmall", http.StatusBadRequest, "Your proposed upload is smaller than the minimum allowed object size."}
 	EntityTooLarge          = APIError{"EntityTooLarge", http.StatusBadRequest, "Your proposed upload exceeds the maximum allowed object size."}
 	InvalidRange            = APIError{"InvalidRange", http.StatusRequestedRangeNotSatisfiable, "The requested range is not satisfiable."}
+	KeyTooLong              = APIError{"KeyTooLongError", http.StatusBadRequest, "Your key is too long."}
+	BadDigest               = APIError{"BadDigest", http.StatusBadRequest, "The checksum you specified did not match the calculated checksum."}
+	MetadataTooLarge        = APIError{"MetadataTooLarge", http.StatusBadRequest, "Your metadata headers exceed the maximum allowed metadata size."}
 	InvalidTag              = APIError{"InvalidTag", http.StatusBadRequest, "The tag provided was not a valid tag."}
 	PreconditionFailed      = APIError{"PreconditionFailed", http.StatusPreconditionFailed, "At least one of the preconditions you specified did not hold."}
 	NotModified             = APIError{"NotModified", http.StatusNotModified, ""}
 	AccessDenied            = APIError{"AccessDenied", http.StatusForbidden, "Access Denied."}
+	QuotaExceeded           = APIError{"QuotaExceeded", http.StatusForbidden, "The upload exceeds the size quota for this key prefix."}
 	SignatureDoesNotMatch   = APIError{"SignatureDoesNotMatch", http.StatusForbidden, "The request signature we calculated does not match the signature you provided."}
 	InvalidAccessKeyID      = APIError{"InvalidAccessKeyId", http.StatusForbidden, "The AWS access key Id you provided does not exist in our records."}
 	RequestTimeTooSkewed    = APIError{"RequestTimeTooSkewed", http.StatusForbidden, "The difference between the request time and the current time is too large."}
@@ -55,7 +62,11 @@ var (
 	ExpiredPresignedRequest = APIError{"AccessDenied", http.StatusForbidden, "Request has expired."}
 	MethodNotAllowed        = APIError{"MethodNotAllowed", http.StatusMethodNotAllowed, "The specified method is not allowed against this resource."}
 	NotImplemented          = APIError{"NotImplemented", http.StatusNotImplemented, "A header or operation you provided implies functionality that is not implemented."}
+	MalformedPOSTRequest    = APIError{"MalformedPOSTRequest", http.StatusBadRequest, "The body of your POST request is not well-formed multipart/form-data."}
+	InvalidPolicyDocument   = APIError{"InvalidPolicyDocument", http.StatusBadRequest, "The content of the form does not meet the conditions specified in the policy document."}
 	MissingRequestBody      = APIError{"MissingRequestBodyError", http.StatusBadRequest, "Request body is empty."}
+	ServiceUnavailable      = APIError{"ServiceUnavailable", http.StatusServiceUnavailable, "The server is in maintenance mode; writes are temporarily unavailable."}
+	SlowDown                = APIError{"SlowDown", http.StatusServiceUnavailable, "Please reduce your request rate."}
 	InternalError           = APIError{"InternalError", http.StatusInternalServerError, "We encountered an internal error. Please try again."}
 )
 
@@ -84,8 +95,14 @@ func FromError(err error) APIError {
 		return BucketAlreadyOwnedByYou
 	case errors.Is(err, fs.ErrBucketNotEmpty):
 		return BucketNotEmpty
+	case errors.Is(err, storagefs.ErrNotBucket):
+		// Only CreateBucket gets here: elsewhere the error also matches
+		// fs.ErrBucketNotFound. The name is taken, just not by a bucket.
+		return BucketAlreadyExists
 	case errors.Is(err, fs.ErrInvalidBucketName):
 		return InvalidBucketName
+	case errors.Is(err, fs.ErrInvalidKey):
+		return InvalidArgument
 	case errors.Is(err, fs.ErrPreconditionFailed):
 		return PreconditionFailed
 	case errors.Is(err, fs.ErrInvalidPart):
@@ -100,6 +117,23 @@ func FromError(err error) APIError {
 		return EntityTooSmall
 	case errors.Is(err, fs.ErrInvalidTag):
 		return InvalidTag
+	case errors.Is(err, fs.ErrKeyTooLong):
+		return KeyTooLong
+	case errors.Is(err, fs.ErrMetadataTooLarge):
+		return MetadataTooLarge
+	case errors.Is(err, fs.ErrCustomerKeyMismatch):
+		return AccessDenied
+	case errors.Is(err, fs.ErrEncryptionParameters):
+		return InvalidRequest
+	case errors.Is(err, fs.ErrAccessDenied):
+		return AccessDenied
+	case errors.Is(err, fs.ErrQuotaExceeded):
+		// Not an AWS code; Ceph RGW answers quota refusals with it.
+		return QuotaExceeded
+	case errors.Is(err, fs.ErrMissingMetadata), errors.Is(err, fs.ErrPrefixNotFound):
+		return InvalidRequest
+	case errors.Is(err, fs.ErrInvalidRange):
+		return InvalidRange
 	case errors.Is(err, fs.ErrIntegrity):
 		// Server-side corruption: the object is damaged, so surface a 500
 		// rather than serve bad bytes.
@@ -121,6 +155,16 @@ func Write(w http.ResponseWriter, r *http.Request, err error) {
 // still emits the status code. The x-amz-request-id header, if already set
 // (e.g. by middleware), is echoed into the <RequestId> element.
 func WriteAPI(w http.ResponseWriter, r *http.Request, api APIError) {
+	WriteAPIDetail(w, r, api, "")
+}
+
+// WriteAPIDetail is WriteAPI with detail, when not empty, appended to the
+// message: the internal error behind the response, for development servers.
+func WriteAPIDetail(w http.ResponseWriter, r *http.Request, api APIError, detail string) {
+	if detail != "" {
+		api.Message += " (" + detail + ")"
+	}
+
 	header := w.Header()
 	requestID := header.Get("x-amz-request-id")
 
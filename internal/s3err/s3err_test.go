@@ -2,6 +2,7 @@ package s3err_test
 
 import (
 	"encoding/xml"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/internal/s3err"
+	"github.com/go-faster/fs/storagefs"
 )
 
 func TestFromError(t *testing.T) {
@@ -23,9 +25,20 @@ func TestFromError(t *testing.T) {
 		{fs.ErrUploadNotFound, "NoSuchUpload"},
 		{fs.ErrBucketAlreadyExists, "BucketAlreadyOwnedByYou"},
 		{fs.ErrBucketNotEmpty, "BucketNotEmpty"},
+		{storagefs.ErrNotBucket, "BucketAlreadyExists"},
+		{fmt.Errorf("%w: %w", storagefs.ErrNotBucket, fs.ErrBucketNotFound), "NoSuchBucket"},
 		{fs.ErrInvalidBucketName, "InvalidBucketName"},
+		{fs.ErrInvalidKey, "InvalidArgument"},
+		{fs.ErrNoSuchBucket, "NoSuchBucket"},
+		{fs.ErrNoSuchKey, "NoSuchKey"},
 		{fs.ErrPreconditionFailed, "PreconditionFailed"},
 		{fs.ErrUnsupportedOperation, "NotImplemented"},
+		{fs.ErrCustomerKeyMismatch, "AccessDenied"},
+		{fs.ErrEncryptionParameters, "InvalidRequest"},
+		{fs.ErrAccessDenied, "AccessDenied"},
+		{fs.ErrQuotaExceeded, "QuotaExceeded"},
+		{fs.ErrMissingMetadata, "InvalidRequest"},
+		{fs.ErrPrefixNotFound, "InvalidRequest"},
 		{errors.Wrap(fs.ErrObjectNotFound, "wrapped"), "NoSuchKey"},
 		{errors.New("something else"), "InternalError"},
 		{nil, "InternalError"},
@@ -81,3 +94,15 @@ func TestWriteAPI_ExplicitCode(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, rec.Code)
 	require.Contains(t, rec.Body.String(), "<Code>MalformedXML</Code>")
 }
+
+func TestWriteAPIDetail(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/bucket/key", http.NoBody)
+
+	s3err.WriteAPIDetail(rec, req, s3err.InternalError, "open /data/bucket/key: permission denied")
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Contains(t, rec.Body.String(),
+		"<Message>We encountered an internal error. Please try again. (open /data/bucket/key: permission denied)</Message>")
+	require.Equal(t, "We encountered an internal error. Please try again.", s3err.InternalError.Message)
+}
@@ -0,0 +1,43 @@
+package sigv4
+
+import (
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// VerifyPostPolicy authenticates a browser form upload (POST object). Such a
+// request carries its credentials as form fields rather than headers: the
+// client signs the base64 policy document itself with the SigV4 signing key,
+// so the signature covers the policy and nothing else. field returns a form
+// field by lowercase name ("" when absent).
+//
+// Only the signature is checked here. The policy's expiration and conditions
+// bind the rest of the form and are the caller's to enforce.
+func (v *Verifier) VerifyPostPolicy(field func(name string) string) (*Result, error) {
+	policy, providedSig := field("policy"), field("x-amz-signature")
+	if policy == "" || providedSig == "" {
+		return nil, errors.Wrap(ErrMissingSignature, "post policy")
+	}
+
+	if !strings.EqualFold(field("x-amz-algorithm"), algorithm) {
+		return nil, errors.Wrap(ErrMalformedSignature, "unsupported algorithm")
+	}
+
+	cred, err := parseCredential(field("x-amz-credential"))
+	if err != nil {
+		return nil, err
+	}
+
+	secret, ok := v.lookup(cred.accessKey)
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownAccessKey, "access key %q", cred.accessKey)
+	}
+
+	expected := hexHMAC(deriveSigningKey(secret, cred), policy)
+	if !constantTimeEqual(expected, providedSig) {
+		return nil, ErrSignatureMismatch
+	}
+
+	return &Result{AccessKey: cred.accessKey}, nil
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
 )
 
 func TestBucketName(t *testing.T) {
@@ -445,7 +447,7 @@ func TestBucketName(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			err := BucketName(tt.bucket)
 			if tt.wantError {
-				require.Error(t, err, "BucketName(%q) expected error, got nil", tt.bucket)
+				require.ErrorIs(t, err, fs.ErrInvalidBucketName, "BucketName(%q) expected error, got nil", tt.bucket)
 			} else {
 				require.NoError(t, err, "BucketName(%q) unexpected error", tt.bucket)
 			}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
 )
 
 func TestKey(t *testing.T) {
@@ -379,7 +381,7 @@ func TestKeySecurityFocused(t *testing.T) {
 
 		for _, attack := range attacks {
 			err := Key(attack)
-			require.Error(t, err, "should reject path traversal: %q", attack)
+			require.ErrorIs(t, err, fs.ErrInvalidKey, "should reject path traversal: %q", attack)
 		}
 	})
 
@@ -394,7 +396,7 @@ func TestKeySecurityFocused(t *testing.T) {
 
 		for _, attack := range attacks {
 			err := Key(attack)
-			require.Error(t, err, "should reject backslashes: %q", attack)
+			require.ErrorIs(t, err, fs.ErrInvalidKey, "should reject backslashes: %q", attack)
 		}
 	})
 
@@ -408,7 +410,7 @@ func TestKeySecurityFocused(t *testing.T) {
 
 		for _, attack := range attacks {
 			err := Key(attack)
-			require.Error(t, err, "should reject null bytes: %q", attack)
+			require.ErrorIs(t, err, fs.ErrInvalidKey, "should reject null bytes: %q", attack)
 		}
 	})
 
@@ -426,7 +428,7 @@ func TestKeySecurityFocused(t *testing.T) {
 
 		for _, attack := range attacks {
 			err := Key(attack)
-			require.Error(t, err, "should reject control characters: %q", attack)
+			require.ErrorIs(t, err, fs.ErrInvalidKey, "should reject control characters: %q", attack)
 		}
 	})
 
@@ -440,7 +442,7 @@ func TestKeySecurityFocused(t *testing.T) {
 
 		for _, attack := range attacks {
 			err := Key(attack)
-			require.Error(t, err, "should reject relative path references: %q", attack)
+			require.ErrorIs(t, err, fs.ErrInvalidKey, "should reject relative path references: %q", attack)
 		}
 	})
 }
@@ -486,7 +488,7 @@ func TestKeyAWSCompliance(t *testing.T) {
 	t.Run("length boundaries", func(t *testing.T) {
 		// Test at boundary
 		require.NoError(t, Key(strings.Repeat("a", 1024)), "1024 bytes should be at max")
-		require.Error(t, Key(strings.Repeat("a", 1025)), "1025 bytes should exceed max")
+		require.ErrorIs(t, Key(strings.Repeat("a", 1025)), fs.ErrKeyTooLong, "1025 bytes should exceed max")
 
 		// Test with multi-byte UTF-8
 		// Each emoji is 4 bytes
@@ -501,7 +503,16 @@ func TestKeyAWSCompliance(t *testing.T) {
 		// 257 emojis = 1028 bytes (over limit)
 		overEmoji := strings.Repeat(emoji, 257)
 		require.Equal(t, 1028, len(overEmoji))
-		require.Error(t, Key(overEmoji), "1028 bytes of emoji should be invalid")
+		require.ErrorIs(t, Key(overEmoji), fs.ErrKeyTooLong, "1028 bytes of emoji should be invalid")
+	})
+
+	t.Run("custom limit", func(t *testing.T) {
+		require.NoError(t, KeyWithMaxLength(strings.Repeat("a", 64), 64))
+		require.ErrorIs(t, KeyWithMaxLength(strings.Repeat("a", 65), 64), fs.ErrKeyTooLong)
+
+		// Character rules still apply under any limit.
+		require.ErrorIs(t, KeyWithMaxLength("a/../b", 64), fs.ErrInvalidKey)
+		require.NotErrorIs(t, KeyWithMaxLength("a/../b", 64), fs.ErrKeyTooLong)
 	})
 }
 
@@ -5,6 +5,8 @@ import (
 	"unicode/utf8"
 
 	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
 )
 
 // Prefix validates S3 object prefix for listing operations.
@@ -20,52 +22,52 @@ func Prefix(prefix string) error {
 
 	// Check length (same limit as keys: 1024 bytes)
 	if len(prefix) > 1024 {
-		return errors.New("prefix length cannot exceed 1024 bytes")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix length cannot exceed 1024 bytes")
 	}
 
 	// Validate UTF-8 encoding
 	if !utf8.ValidString(prefix) {
-		return errors.New("prefix must be valid UTF-8")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix must be valid UTF-8")
 	}
 
 	// Security: Prevent path traversal attacks
 	if strings.Contains(prefix, "..") {
-		return errors.New("prefix cannot contain '..'")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain '..'")
 	}
 
 	// Security: Prevent Windows absolute paths
 	if len(prefix) >= 2 && prefix[1] == ':' {
-		return errors.New("prefix cannot be a Windows absolute path")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot be a Windows absolute path")
 	}
 
 	// Security: Prevent backslashes (Windows-style paths)
 	if strings.Contains(prefix, "\\") {
-		return errors.New("prefix cannot contain backslashes")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain backslashes")
 	}
 
 	// Security: Prevent relative path references
 	if strings.HasPrefix(prefix, "./") || strings.HasPrefix(prefix, "../") {
-		return errors.New("prefix cannot start with './' or '../'")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot start with './' or '../'")
 	}
 
 	// Security: Prevent /./ patterns
 	if strings.Contains(prefix, "/./") {
-		return errors.New("prefix cannot contain '/./'")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain '/./'")
 	}
 
 	// Check for null bytes (security issue)
 	if strings.Contains(prefix, "\x00") {
-		return errors.New("prefix cannot contain null bytes")
+		return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain null bytes")
 	}
 
 	// Check for control characters
 	for _, ch := range prefix {
 		if ch < 32 && ch != '\t' {
-			return errors.New("prefix cannot contain control characters")
+			return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain control characters")
 		}
 
 		if ch == 127 {
-			return errors.New("prefix cannot contain DEL character")
+			return errors.Wrap(fs.ErrInvalidKey, "prefix cannot contain DEL character")
 		}
 	}
 
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
 )
 
 func TestPrefix(t *testing.T) {
@@ -312,7 +314,7 @@ func TestPrefix(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			err := Prefix(tt.prefix)
 			if tt.wantError {
-				require.Error(t, err, "Prefix(%q) expected error, got nil", tt.prefix)
+				require.ErrorIs(t, err, fs.ErrInvalidKey, "Prefix(%q) expected error, got nil", tt.prefix)
 			} else {
 				require.NoError(t, err, "Prefix(%q) unexpected error", tt.prefix)
 			}
@@ -0,0 +1,181 @@
+package fs
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// Inventory formats accepted by GenerateInventory.
+const (
+	// InventoryCSV is a CSV manifest with a header row:
+	// Key,Size,ETag,LastModified,StorageClass.
+	InventoryCSV = "csv"
+	// InventoryJSON is a JSON array of InventoryEntry objects.
+	InventoryJSON = "json"
+)
+
+// inventoryColumns is the CSV header row, in InventoryEntry field order.
+var inventoryColumns = []string{"Key", "Size", "ETag", "LastModified", "StorageClass"}
+
+// InventoryEntry is one object of an inventory manifest.
+type InventoryEntry struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	// ETag is unquoted, as stored.
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+	// StorageClass is always STANDARD, the only class served.
+	StorageClass string `json:"storage_class"`
+}
+
+// ValidInventoryFormat reports whether format is one GenerateInventory
+// writes.
+func ValidInventoryFormat(format string) bool {
+	return format == InventoryCSV || format == InventoryJSON
+}
+
+// GenerateInventory writes a manifest of every object in bucket to w, in walk
+// order (see WalkObjects), in format (InventoryCSV or InventoryJSON): a
+// snapshot to reconcile against another store without paging through
+// listings. Entries are encoded one at a time as the walk finds them, so
+// neither the listing nor the manifest is ever held in memory. Nothing is
+// written when the walk fails before its first object; a later failure cuts
+// the manifest short and is returned.
+func GenerateInventory(ctx context.Context, s Storage, bucket, format string, w io.Writer) error {
+	if !ValidInventoryFormat(format) {
+		return errors.Errorf("unknown inventory format %q", format)
+	}
+
+	next, stop := iter.Pull2(WalkObjects(ctx, s, bucket, ""))
+	defer stop()
+
+	// Pull the first object before writing, so that a missing bucket is an
+	// error with an empty w.
+	first, err, ok := next()
+	if ok && err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+
+	var walkErr error
+
+	entries := func(yield func(InventoryEntry) bool) {
+		for o, err, ok := first, err, ok; ok; o, err, ok = next() {
+			if err != nil {
+				walkErr = err
+				return
+			}
+
+			if !yield(InventoryEntry{
+				Key:          o.Key,
+				Size:         o.Size,
+				ETag:         strings.Trim(o.ETag, `"`),
+				LastModified: o.LastModified.UTC(),
+				StorageClass: "STANDARD",
+			}) {
+				return
+			}
+		}
+	}
+
+	write := writeInventoryJSON
+	if format == InventoryCSV {
+		write = writeInventoryCSV
+	}
+
+	if err := write(ctx, w, entries); err != nil {
+		return err
+	}
+
+	if walkErr != nil {
+		return errors.Wrap(walkErr, "list objects")
+	}
+
+	return nil
+}
+
+func writeInventoryCSV(ctx context.Context, w io.Writer, entries func(func(InventoryEntry) bool)) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(inventoryColumns); err != nil {
+		return errors.Wrap(err, "write header")
+	}
+
+	var err error
+
+	entries(func(e InventoryEntry) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		err = cw.Write([]string{
+			e.Key,
+			strconv.FormatInt(e.Size, 10),
+			e.ETag,
+			e.LastModified.Format(time.RFC3339),
+			e.StorageClass,
+		})
+
+		return err == nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "write entry")
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return errors.Wrap(err, "flush")
+	}
+
+	return nil
+}
+
+func writeInventoryJSON(ctx context.Context, w io.Writer, entries func(func(InventoryEntry) bool)) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	_, _ = bw.WriteString("[")
+
+	var (
+		err   error
+		first = true
+	)
+
+	entries(func(e InventoryEntry) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		if !first {
+			_, _ = bw.WriteString(",")
+		}
+
+		first = false
+
+		// Encode ends each entry with a newline: one entry per line.
+		err = enc.Encode(e)
+
+		return err == nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "write entry")
+	}
+
+	_, _ = bw.WriteString("]\n")
+
+	if err := bw.Flush(); err != nil {
+		return errors.Wrap(err, "flush")
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package fs_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func newInventoryStore(t *testing.T) fs.Storage {
+	t.Helper()
+
+	ctx := t.Context()
+	s := storagemem.New()
+	require.NoError(t, s.CreateBucket(ctx, "bucket"))
+
+	for key, body := range map[string]string{
+		"b/two":    "22",
+		"a, comma": "1",
+		"c":        "333",
+	} {
+		_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "bucket", Key: key, Reader: strings.NewReader(body), Size: int64(len(body)),
+		})
+		require.NoError(t, err)
+	}
+
+	return s
+}
+
+func TestGenerateInventory_CSV(t *testing.T) {
+	s := newInventoryStore(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, fs.GenerateInventory(t.Context(), s, "bucket", fs.InventoryCSV, &buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, []string{"Key", "Size", "ETag", "LastModified", "StorageClass"}, records[0])
+
+	// Entries come in walk order.
+	rows := records[1:]
+	slices.SortFunc(rows, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+
+	var keys []string
+
+	for _, rec := range rows {
+		keys = append(keys, rec[0])
+
+		require.Regexp(t, `^[0-9a-f]{32}$`, rec[2])
+		_, err := time.Parse(time.RFC3339, rec[3])
+		require.NoError(t, err)
+		require.Equal(t, "STANDARD", rec[4])
+	}
+
+	require.Equal(t, []string{"a, comma", "b/two", "c"}, keys)
+	require.Equal(t, []string{"1", "2", "3"}, []string{rows[0][1], rows[1][1], rows[2][1]})
+}
+
+func TestGenerateInventory_JSON(t *testing.T) {
+	s := newInventoryStore(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, fs.GenerateInventory(t.Context(), s, "bucket", fs.InventoryJSON, &buf))
+
+	var raw []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+	require.Len(t, raw, 3)
+
+	for _, e := range raw {
+		require.ElementsMatch(t, []string{"key", "size", "etag", "last_modified", "storage_class"}, mapKeys(e))
+	}
+
+	var entries []fs.InventoryEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	slices.SortFunc(entries, func(a, b fs.InventoryEntry) int { return strings.Compare(a.Key, b.Key) })
+	require.Equal(t, "a, comma", entries[0].Key)
+	require.Equal(t, int64(2), entries[1].Size)
+	require.Equal(t, "c", entries[2].Key)
+	require.Equal(t, "STANDARD", entries[2].StorageClass)
+	require.False(t, entries[2].LastModified.IsZero())
+}
+
+func TestGenerateInventory_Errors(t *testing.T) {
+	s := newInventoryStore(t)
+
+	var buf bytes.Buffer
+	require.Error(t, fs.GenerateInventory(t.Context(), s, "bucket", "xml", &buf))
+	require.ErrorIs(t, fs.GenerateInventory(t.Context(), s, "missing", fs.InventoryCSV, &buf), fs.ErrBucketNotFound)
+	require.Zero(t, buf.Len())
+
+	// An empty bucket is a header (or an empty array) only.
+	require.NoError(t, s.CreateBucket(t.Context(), "empty"))
+	require.NoError(t, fs.GenerateInventory(t.Context(), s, "empty", fs.InventoryJSON, &buf))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func mapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
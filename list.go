@@ -0,0 +1,301 @@
+package fs
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+)
+
+// ListObjectsRange returns the objects of bucket whose keys lie strictly
+// between startAfter and endBefore, sorted by key. An empty bound leaves that
+// side open. Both bounds are exclusive, like S3's start-after.
+//
+// Ranges are handy for splitting a bucket across workers. Keys never contain
+// NUL, so no key sorts between b and b+"\x00": the ranges (a, b+"\x00") and
+// (b, c) partition the keys between a and c exactly, with b in the first.
+func ListObjectsRange(ctx context.Context, s Storage, bucket, startAfter, endBefore string) ([]Object, error) {
+	// Every key between the bounds shares their common prefix, so only that
+	// part of the bucket needs listing.
+	var prefix string
+	if startAfter != "" && endBefore != "" {
+		prefix = commonPrefix(startAfter, endBefore)
+	}
+
+	objects, err := s.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	objects = slices.DeleteFunc(objects, func(o Object) bool {
+		return o.Key <= startAfter || (endBefore != "" && o.Key >= endBefore)
+	})
+	slices.SortFunc(objects, func(a, b Object) int { return strings.Compare(a.Key, b.Key) })
+
+	return objects, nil
+}
+
+// ListObjectsModifiedSince returns the objects of bucket under prefix whose
+// LastModified is strictly after since, sorted by key: what an incremental
+// sync agent needs to fetch after a run that started at since. A zero since
+// returns every object.
+func ListObjectsModifiedSince(ctx context.Context, s Storage, bucket, prefix string, since time.Time) ([]Object, error) {
+	objects, err := s.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if !since.IsZero() {
+		objects = slices.DeleteFunc(objects, func(o Object) bool { return !o.LastModified.After(since) })
+	}
+
+	slices.SortFunc(objects, func(a, b Object) int { return strings.Compare(a.Key, b.Key) })
+
+	return objects, nil
+}
+
+// FilterObjects yields the objects of bucket under prefix whose key match
+// reports true, in no particular order: a search by substring, suffix or
+// regular expression anywhere in the key, where a prefix listing alone cannot
+// narrow it. match sees each key once, as the walk (see WalkObjects) finds it.
+// Pass the longest literal prefix the search allows, since only that part of
+// the bucket is walked.
+func FilterObjects(ctx context.Context, s Storage, bucket, prefix string, match func(key string) bool) iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		for o, err := range WalkObjects(ctx, s, bucket, prefix) {
+			if err != nil {
+				yield(Object{}, err)
+				return
+			}
+
+			if match(o.Key) && !yield(o, nil) {
+				return
+			}
+		}
+	}
+}
+
+// CountObjects returns the number of objects of bucket under prefix: an
+// existence or cardinality check that needs no sorting and hands nothing but
+// a number to the caller. Objects are counted as the walk finds them, so no
+// listing is held in memory when s is an ObjectWalker.
+func CountObjects(ctx context.Context, s Storage, bucket, prefix string) (int64, error) {
+	var n int64
+
+	for _, err := range WalkObjects(ctx, s, bucket, prefix) {
+		if err != nil {
+			return 0, err
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// WalkObjects yields the objects of bucket under prefix in no particular
+// order: streamed from the storage's walk when s is an ObjectWalker, and from
+// its ListObjects otherwise. The first error ends the sequence.
+func WalkObjects(ctx context.Context, s Storage, bucket, prefix string) iter.Seq2[Object, error] {
+	if w, ok := s.(ObjectWalker); ok {
+		return w.WalkObjects(ctx, bucket, prefix)
+	}
+
+	return func(yield func(Object, error) bool) {
+		objects, err := s.ListObjects(ctx, bucket, prefix)
+		if err != nil {
+			yield(Object{}, err)
+			return
+		}
+
+		for _, o := range objects {
+			if !yield(o, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Order is the key order of a listing.
+type Order int
+
+const (
+	// Ascending lists keys in lexical byte order, as S3 does. The default.
+	Ascending Order = iota
+	// Descending lists keys in reverse lexical order, e.g. newest first when
+	// keys start with a date.
+	Descending
+)
+
+// String returns "asc" or "desc", the spelling of the order listing
+// parameter.
+func (o Order) String() string {
+	if o == Descending {
+		return "desc"
+	}
+
+	return "asc"
+}
+
+// ListObjectsOrdered returns the objects of bucket under prefix sorted by key
+// in order.
+func ListObjectsOrdered(ctx context.Context, s Storage, bucket, prefix string, order Order) ([]Object, error) {
+	objects, err := s.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(objects, func(a, b Object) int { return strings.Compare(a.Key, b.Key) })
+
+	if order == Descending {
+		slices.Reverse(objects)
+	}
+
+	return objects, nil
+}
+
+// ListAllObjects returns the objects under prefix in every bucket of s, keyed
+// by bucket name and sorted by key: a search across the whole store. Buckets
+// without a match are left out, as is one deleted while the search runs.
+func ListAllObjects(ctx context.Context, s Storage, prefix string) (map[string][]Object, error) {
+	buckets, err := s.ListBuckets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list buckets")
+	}
+
+	result := make(map[string][]Object)
+
+	for _, b := range buckets {
+		objects, err := s.ListObjects(ctx, b.Name, prefix)
+		if errors.Is(err, ErrBucketNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "list bucket %q", b.Name)
+		}
+
+		if len(objects) == 0 {
+			continue
+		}
+
+		slices.SortFunc(objects, func(a, b Object) int { return strings.Compare(a.Key, b.Key) })
+		result[b.Name] = objects
+	}
+
+	return result, nil
+}
+
+// userMetadataPrefix marks a ListObjectsByMetadata condition on user metadata
+// rather than on a tag.
+const userMetadataPrefix = "x-amz-meta-"
+
+// ListObjectsByMetadata yields the objects of bucket under prefix, in no
+// particular order, whose stored metadata and tags satisfy every condition of
+// match. A condition named x-amz-meta-<name> compares the user metadata
+// <name> (case insensitively, as stored); any other name compares the tag of
+// that key. Values must be equal; a missing one never matches. An empty match
+// yields every object.
+//
+// Each candidate costs a metadata or tagging read on top of the listing, so
+// the results are streamed from the walk (see WalkObjects) as they are found,
+// and the walk stops with the context's error once ctx is done. An object
+// deleted during the walk is skipped, as is one whose metadata cannot be read
+// without its SSE-C key (ErrEncryptionParameters).
+func ListObjectsByMetadata(ctx context.Context, s Storage, bucket, prefix string, match map[string]string) iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		for o, err := range WalkObjects(ctx, s, bucket, prefix) {
+			if err != nil {
+				yield(Object{}, err)
+				return
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(Object{}, err)
+				return
+			}
+
+			ok, err := matchesMetadata(ctx, s, bucket, o.Key, match)
+			if errors.Is(err, ErrObjectNotFound) || errors.Is(err, ErrEncryptionParameters) {
+				continue
+			}
+
+			if err != nil {
+				yield(Object{}, errors.Wrapf(err, "%q", o.Key))
+				return
+			}
+
+			if ok && !yield(o, nil) {
+				return
+			}
+		}
+	}
+}
+
+// matchesMetadata reports whether bucket/key satisfies match, reading only
+// what the conditions refer to.
+func matchesMetadata(ctx context.Context, s Storage, bucket, key string, match map[string]string) (bool, error) {
+	var (
+		meta, tags             map[string]string
+		metaLoaded, tagsLoaded bool
+	)
+
+	for name, want := range match {
+		if strings.HasPrefix(strings.ToLower(name), userMetadataPrefix) {
+			if !metaLoaded {
+				obj, err := s.GetObject(ctx, bucket, key)
+				if err != nil {
+					return false, err
+				}
+
+				_ = obj.Reader.Close()
+
+				meta, metaLoaded = obj.Metadata.UserMetadata, true
+			}
+
+			got, ok := meta[strings.ToLower(name[len(userMetadataPrefix):])]
+			if !ok || got != want {
+				return false, nil
+			}
+
+			continue
+		}
+
+		if !tagsLoaded {
+			set, err := s.GetObjectTagging(ctx, bucket, key)
+			if err != nil {
+				return false, err
+			}
+
+			tags, tagsLoaded = make(map[string]string, len(set)), true
+			for _, t := range set {
+				tags[t.Key] = t.Value
+			}
+		}
+
+		if got, ok := tags[name]; !ok || got != want {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// commonPrefix returns the longest common prefix of a and b, cut back to a
+// rune boundary so it is itself a valid prefix.
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	for n > 0 && !utf8.ValidString(a[:n]) {
+		n--
+	}
+
+	return a[:n]
+}
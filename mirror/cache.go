@@ -0,0 +1,90 @@
+package mirror
+
+import (
+	"container/list"
+	"sync"
+)
+
+// entry is one cached object in the LRU list.
+type entry struct {
+	bucket string
+	key    string
+	size   int64
+}
+
+// lru tracks the cached objects in least- to most-recently-used order and
+// their total size. It only does the bookkeeping: the caller deletes the
+// victims it returns.
+type lru struct {
+	mu      sync.Mutex
+	max     int64 // 0 means unlimited
+	total   int64
+	order   *list.List // of *entry, front is most recently used
+	entries map[string]*list.Element
+}
+
+func newLRU(maxSize int64) *lru {
+	return &lru{
+		max:     maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func cacheKey(bucket, key string) string { return bucket + "/" + key }
+
+// touch marks an object as just used. Objects the cache does not know (e.g.
+// written to the root by hand) are left alone.
+func (c *lru) touch(bucket, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[cacheKey(bucket, key)]; ok {
+		c.order.MoveToFront(el)
+	}
+}
+
+// add records an object as the most recently used one, replacing any previous
+// size, and returns the least recently used objects that must go to bring the
+// total back under the limit. An object larger than the limit evicts
+// everything, itself included: it has already been served.
+func (c *lru) add(bucket, key string, size int64) []entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := cacheKey(bucket, key)
+	if el, ok := c.entries[id]; ok {
+		c.total -= el.Value.(*entry).size
+		c.order.Remove(el)
+	}
+
+	c.entries[id] = c.order.PushFront(&entry{bucket: bucket, key: key, size: size})
+	c.total += size
+
+	if c.max <= 0 {
+		return nil
+	}
+
+	var victims []entry
+
+	for c.total > c.max {
+		el := c.order.Back()
+		e := el.Value.(*entry)
+
+		c.order.Remove(el)
+		delete(c.entries, cacheKey(e.bucket, e.key))
+		c.total -= e.size
+
+		victims = append(victims, *e)
+	}
+
+	return victims
+}
+
+// size returns the total size of the cached objects.
+func (c *lru) size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.total
+}
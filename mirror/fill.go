@@ -0,0 +1,174 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// errAbandoned aborts a fill whose reader was closed before the end of the
+// body.
+var errAbandoned = errors.New("read abandoned before the end of the object")
+
+// fill is the body of an object being pulled: it reads from the upstream and
+// copies every byte into a local PutObject running on the other end of a pipe.
+// Failing to cache never fails the read; the object is just not kept.
+type fill struct {
+	m      *Mirror
+	ctx    context.Context
+	bucket string
+	key    string
+	etag   string
+	size   int64
+
+	src  io.ReadCloser
+	pw   *io.PipeWriter
+	put  chan error // the local PutObject's outcome
+	n    int64      // bytes copied so far
+	pos  int64      // offset of the next read in the body
+	done bool       // the fill was finished or abandoned
+}
+
+func newFill(ctx context.Context, m *Mirror, bucket, key string, resp *fs.GetObjectResponse) *fill {
+	pr, pw := io.Pipe()
+	f := &fill{
+		m:      m,
+		ctx:    ctx,
+		bucket: bucket,
+		key:    key,
+		etag:   resp.ETag,
+		size:   resp.Size,
+		src:    resp.Reader,
+		pw:     pw,
+		put:    make(chan error, 1),
+	}
+
+	req := &fs.PutObjectRequest{
+		Reader:       pr,
+		Bucket:       bucket,
+		Key:          key,
+		Size:         resp.Size,
+		Metadata:     resp.Metadata,
+		LastModified: resp.LastModified,
+	}
+
+	go func() {
+		put, err := m.local.PutObject(ctx, req)
+		if err == nil && !sameETag(f.etag, put.ETag) {
+			err = errors.Wrapf(fs.ErrIntegrity, "local ETag %q, upstream %q", put.ETag, f.etag)
+		}
+
+		// Unblock the reader side if the write stopped early.
+		pr.CloseWithError(errAbandoned)
+		f.put <- err
+	}()
+
+	return f
+}
+
+// sameETag compares the stored ETag with the upstream one. A multipart ETag
+// ("<md5>-<parts>") cannot be reproduced by a single PUT, so it is not
+// checked.
+func sameETag(local, upstream string) bool {
+	upstream = strings.Trim(upstream, `"`)
+	if upstream == "" || strings.Contains(upstream, "-") {
+		return true
+	}
+
+	return local == upstream
+}
+
+func (f *fill) Read(p []byte) (int, error) {
+	if !f.done && f.pos != f.n {
+		// Seeked away: the bytes in between never reach the local copy.
+		f.abort(errAbandoned)
+	}
+
+	n, err := f.src.Read(p)
+	f.pos += int64(n)
+
+	if n > 0 && !f.done {
+		f.n += int64(n)
+		// A write error means the local PutObject gave up; keep serving.
+		_, _ = f.pw.Write(p[:n])
+	}
+
+	if err != nil && !f.done {
+		if errors.Is(err, io.EOF) {
+			f.finish()
+		} else {
+			f.abort(err)
+		}
+	}
+
+	return n, err
+}
+
+// finish completes the local copy once the whole body was read and records
+// it, or removes it when it does not match the upstream.
+func (f *fill) finish() {
+	f.done = true
+
+	var err error
+	if f.n != f.size {
+		err = errors.Errorf("read %d bytes, upstream size %d", f.n, f.size)
+		f.pw.CloseWithError(err)
+	} else {
+		_ = f.pw.Close()
+	}
+
+	if putErr := <-f.put; err == nil {
+		err = putErr
+	}
+
+	if err != nil {
+		// Only an ETag mismatch leaves a (wrong) object behind.
+		if errors.Is(err, fs.ErrIntegrity) {
+			_ = f.m.local.DeleteObject(f.ctx, f.bucket, f.key)
+		}
+
+		return
+	}
+
+	f.m.evict(f.ctx, f.m.cache.add(f.bucket, f.key, f.size))
+}
+
+// abort discards the local copy.
+func (f *fill) abort(err error) {
+	f.done = true
+	f.pw.CloseWithError(err)
+	<-f.put
+}
+
+// seekableFill is a fill over a body that can seek, such as a minio object,
+// which serves a seek with a ranged request. It keeps a Range GET on a cold
+// miss a ranged response: the range is read from the upstream and, unless it
+// starts at 0 and runs to the end, nothing is cached.
+type seekableFill struct {
+	*fill
+}
+
+func (f seekableFill) Seek(offset int64, whence int) (int64, error) {
+	pos, err := f.src.(io.Seeker).Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+
+	f.pos = pos
+
+	return pos, nil
+}
+
+// Close stops the fill if the body was not read to the end, so a partially
+// read object is never cached.
+func (f *fill) Close() error {
+	if !f.done {
+		f.abort(errAbandoned)
+	}
+
+	return f.src.Close()
+}
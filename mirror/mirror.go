@@ -0,0 +1,374 @@
+// Package mirror serves a read-only, pull-through copy of a remote S3.
+//
+// A Mirror is an fs.Storage that answers reads from a local store and fills
+// it from an Upstream on a miss: the object is streamed to the caller and
+// written to the local store as it goes (one upstream read serves both), so
+// the next request for it is served from disk. Listings come from the upstream,
+// which stays authoritative, and fall back to the local copy when it is
+// unreachable. Writes of any kind are refused with fs.ErrUnsupportedOperation.
+//
+// WithMaxCacheSize bounds the local copy: once the cached bodies exceed it,
+// the least recently read objects are deleted from the local store.
+package mirror
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// Upstream is the remote a Mirror pulls from. Every fs.Storage satisfies it;
+// NewS3Upstream talks to any S3 endpoint.
+type Upstream interface {
+	ListBuckets(ctx context.Context) ([]fs.Bucket, error)
+	BucketExists(ctx context.Context, bucket string) (bool, error)
+	ListObjects(ctx context.Context, bucket, prefix string) ([]fs.Object, error)
+	GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error)
+}
+
+// Option configures a Mirror.
+type Option func(*Mirror)
+
+// WithMaxCacheSize bounds the total size of the cached object bodies, in
+// bytes. Zero (the default) keeps everything ever pulled.
+func WithMaxCacheSize(size int64) Option {
+	return func(m *Mirror) { m.maxSize = size }
+}
+
+// Mirror is a read-only fs.Storage that caches an Upstream in a local store.
+type Mirror struct {
+	local    fs.Storage
+	upstream Upstream
+	maxSize  int64
+	cache    *lru
+}
+
+var _ fs.Storage = (*Mirror)(nil)
+
+// errReadOnly is returned by every write.
+var errReadOnly = errors.Wrap(fs.ErrUnsupportedOperation, "read-only mirror")
+
+// New returns a Mirror of upstream cached in local. Objects already in local
+// (from an earlier run) are served and accounted for against the cache size,
+// oldest modification first in the eviction order, since their read times were
+// not kept.
+func New(ctx context.Context, local fs.Storage, upstream Upstream, opts ...Option) (*Mirror, error) {
+	m := &Mirror{local: local, upstream: upstream}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.maxSize < 0 {
+		return nil, errors.Errorf("max cache size %d is negative", m.maxSize)
+	}
+
+	m.cache = newLRU(m.maxSize)
+
+	if err := m.load(ctx); err != nil {
+		return nil, errors.Wrap(err, "load cache")
+	}
+
+	return m, nil
+}
+
+// load accounts for the objects already in the local store.
+func (m *Mirror) load(ctx context.Context) error {
+	buckets, err := m.local.ListBuckets(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list buckets")
+	}
+
+	var cached []entry
+
+	modified := make(map[string]int64)
+
+	for _, b := range buckets {
+		objects, err := m.local.ListObjects(ctx, b.Name, "")
+		if err != nil {
+			return errors.Wrapf(err, "list %q", b.Name)
+		}
+
+		for _, obj := range objects {
+			cached = append(cached, entry{bucket: b.Name, key: obj.Key, size: obj.Size})
+			modified[cacheKey(b.Name, obj.Key)] = obj.LastModified.UnixNano()
+		}
+	}
+
+	slices.SortStableFunc(cached, func(a, b entry) int {
+		ma, mb := modified[cacheKey(a.bucket, a.key)], modified[cacheKey(b.bucket, b.key)]
+		switch {
+		case ma < mb:
+			return -1
+		case ma > mb:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, e := range cached {
+		m.evict(ctx, m.cache.add(e.bucket, e.key, e.size))
+	}
+
+	return nil
+}
+
+// CacheSize returns the total size of the object bodies cached locally.
+func (m *Mirror) CacheSize() int64 { return m.cache.size() }
+
+// evict deletes victims from the local store. It is best effort: an object
+// that survives is simply no longer accounted for, and a later pull of it
+// re-records it.
+func (m *Mirror) evict(ctx context.Context, victims []entry) {
+	for _, e := range victims {
+		_ = m.local.DeleteObject(ctx, e.bucket, e.key)
+	}
+}
+
+// isNotFound reports whether err is the upstream (or local store) saying the
+// bucket or object does not exist, as opposed to failing to answer.
+func isNotFound(err error) bool {
+	return errors.Is(err, fs.ErrBucketNotFound) || errors.Is(err, fs.ErrObjectNotFound)
+}
+
+// ListBuckets lists the upstream buckets, or the cached ones when the upstream
+// is unreachable.
+func (m *Mirror) ListBuckets(ctx context.Context) ([]fs.Bucket, error) {
+	buckets, err := m.upstream.ListBuckets(ctx)
+	if err != nil {
+		return m.local.ListBuckets(ctx)
+	}
+
+	return buckets, nil
+}
+
+// BucketExists asks the upstream, or the local store when the upstream is
+// unreachable.
+func (m *Mirror) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	ok, err := m.upstream.BucketExists(ctx, bucket)
+	if err != nil {
+		return m.local.BucketExists(ctx, bucket)
+	}
+
+	return ok, nil
+}
+
+// ListObjects lists the upstream bucket, or the cached objects when the
+// upstream is unreachable.
+func (m *Mirror) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.Object, error) {
+	objects, err := m.upstream.ListObjects(ctx, bucket, prefix)
+	if err != nil && !isNotFound(err) {
+		return m.local.ListObjects(ctx, bucket, prefix)
+	}
+
+	return objects, err
+}
+
+// GetObject serves the object from the local store, pulling it from the
+// upstream on a miss. A pulled object is cached as it is read: its body is
+// only kept once the caller has read it to the end and it matched the
+// upstream's size and (single-part) ETag.
+func (m *Mirror) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "customer-provided encryption keys")
+	}
+
+	resp, err := m.local.GetObject(ctx, bucket, key)
+	if err == nil {
+		m.cache.touch(bucket, key)
+		return resp, nil
+	}
+
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	return m.pull(ctx, bucket, key)
+}
+
+// pull fetches the object from the upstream and returns it wrapped in a
+// reader that writes it to the local store.
+func (m *Mirror) pull(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	resp, err := m.upstream.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "upstream")
+	}
+
+	if err := m.local.CreateBucket(ctx, bucket); err != nil && !errors.Is(err, fs.ErrBucketAlreadyExists) {
+		_ = resp.Reader.Close()
+		return nil, errors.Wrap(err, "create local bucket")
+	}
+
+	f := newFill(ctx, m, bucket, key, resp)
+	if _, ok := resp.Reader.(io.Seeker); ok {
+		resp.Reader = seekableFill{f}
+	} else {
+		resp.Reader = f
+	}
+
+	return resp, nil
+}
+
+// upstreamHas reports whether the upstream holds bucket/key, for the
+// read-only calls a Mirror answers without pulling the body.
+func (m *Mirror) upstreamHas(ctx context.Context, bucket, key string) error {
+	objects, err := m.upstream.ListObjects(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if obj.Key == key {
+			return nil
+		}
+	}
+
+	return fs.ErrObjectNotFound
+}
+
+// GetObjectTagging returns the cached object's tags. Tags are not pulled, so
+// an object not cached yet reports none.
+func (m *Mirror) GetObjectTagging(ctx context.Context, bucket, key string) ([]fs.Tag, error) {
+	tags, err := m.local.GetObjectTagging(ctx, bucket, key)
+	if err == nil || !isNotFound(err) {
+		return tags, err
+	}
+
+	if err := m.upstreamHas(ctx, bucket, key); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// GetObjectLegalHold reports the cached object's legal hold. Holds are not
+// pulled, so an object not cached yet reports none.
+func (m *Mirror) GetObjectLegalHold(ctx context.Context, bucket, key string) (bool, error) {
+	on, err := m.local.GetObjectLegalHold(ctx, bucket, key)
+	if err == nil || !isNotFound(err) {
+		return on, err
+	}
+
+	if err := m.upstreamHas(ctx, bucket, key); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// BucketACL reports every upstream bucket as private: ACLs are not mirrored.
+func (m *Mirror) BucketACL(ctx context.Context, bucket string) (fs.ACL, error) {
+	ok, err := m.BucketExists(ctx, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", fs.ErrBucketNotFound
+	}
+
+	return fs.ACLPrivate, nil
+}
+
+// BucketRegion reports no region for every upstream bucket: bucket
+// configuration is not mirrored.
+func (m *Mirror) BucketRegion(ctx context.Context, bucket string) (string, error) {
+	ok, err := m.BucketExists(ctx, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", fs.ErrBucketNotFound
+	}
+
+	return "", nil
+}
+
+// ObjectACL reports every upstream object as private: ACLs are not mirrored.
+func (m *Mirror) ObjectACL(ctx context.Context, bucket, key string) (fs.ACL, error) {
+	if _, err := m.local.ObjectACL(ctx, bucket, key); err == nil {
+		return fs.ACLPrivate, nil
+	}
+
+	if err := m.upstreamHas(ctx, bucket, key); err != nil {
+		return "", err
+	}
+
+	return fs.ACLPrivate, nil
+}
+
+// ListMultipartUploads reports none: a mirror never has uploads in progress.
+func (m *Mirror) ListMultipartUploads(ctx context.Context, bucket string) ([]fs.MultipartUpload, error) {
+	ok, err := m.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fs.ErrBucketNotFound
+	}
+
+	return nil, nil
+}
+
+// CreateBucket is refused: the mirror is read-only.
+func (m *Mirror) CreateBucket(context.Context, string) error { return errReadOnly }
+
+// DeleteBucket is refused: the mirror is read-only.
+func (m *Mirror) DeleteBucket(context.Context, string) error { return errReadOnly }
+
+// PutObject is refused: the mirror is read-only.
+func (m *Mirror) PutObject(context.Context, *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
+	return nil, errReadOnly
+}
+
+// DeleteObject is refused: the mirror is read-only.
+func (m *Mirror) DeleteObject(context.Context, string, string) error { return errReadOnly }
+
+// PutObjectTagging is refused: the mirror is read-only.
+func (m *Mirror) PutObjectTagging(context.Context, string, string, []fs.Tag) error {
+	return errReadOnly
+}
+
+// DeleteObjectTagging is refused: the mirror is read-only.
+func (m *Mirror) DeleteObjectTagging(context.Context, string, string) error { return errReadOnly }
+
+// PutObjectLegalHold is refused: the mirror is read-only.
+func (m *Mirror) PutObjectLegalHold(context.Context, string, string, bool) error { return errReadOnly }
+
+// SetBucketACL is refused: the mirror is read-only.
+func (m *Mirror) SetBucketACL(context.Context, string, fs.ACL) error { return errReadOnly }
+
+// SetBucketRegion is refused: the mirror is read-only.
+func (m *Mirror) SetBucketRegion(context.Context, string, string) error { return errReadOnly }
+
+// CreateMultipartUpload is refused: the mirror is read-only.
+func (m *Mirror) CreateMultipartUpload(context.Context, *fs.CreateMultipartUploadRequest) (*fs.MultipartUpload, error) {
+	return nil, errReadOnly
+}
+
+// UploadPart is refused: the mirror is read-only.
+func (m *Mirror) UploadPart(context.Context, *fs.UploadPartRequest) (*fs.Part, error) {
+	return nil, errReadOnly
+}
+
+// ListParts reports no such upload: a mirror never has uploads in progress.
+func (m *Mirror) ListParts(context.Context, string, string, string) ([]fs.Part, error) {
+	return nil, fs.ErrUploadNotFound
+}
+
+// CompleteMultipartUpload is refused: the mirror is read-only.
+func (m *Mirror) CompleteMultipartUpload(context.Context, *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error) {
+	return nil, errReadOnly
+}
+
+// AbortMultipartUpload reports no such upload: a mirror never has uploads in
+// progress.
+func (m *Mirror) AbortMultipartUpload(context.Context, string, string, string) error {
+	return fs.ErrUploadNotFound
+}
@@ -0,0 +1,240 @@
+package mirror_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/fstest"
+	"github.com/go-faster/fs/mirror"
+	"github.com/go-faster/fs/storagefs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// setup starts an upstream S3 server holding objects and returns it with its
+// backing store (to change or inspect it behind the server's back), the local
+// store and a Mirror of the upstream cached in it.
+func setup(t *testing.T, objects map[string][]byte, opts ...mirror.Option) (*httptest.Server, fs.Storage, fs.Storage, *mirror.Mirror) {
+	t.Helper()
+
+	remote := storagemem.New()
+	upstreamSrv, _ := fstest.NewTestServer(t, objects, fstest.WithStorage(remote))
+
+	upstream, err := mirror.NewS3Upstream(upstreamSrv.URL, "", "")
+	require.NoError(t, err)
+
+	local, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	m, err := mirror.New(t.Context(), local, upstream, opts...)
+	require.NoError(t, err)
+
+	return upstreamSrv, remote, local, m
+}
+
+func get(t *testing.T, url string) (int, []byte) {
+	t.Helper()
+
+	resp, err := http.Get(url) //nolint:gosec,noctx // test server.
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, body
+}
+
+func TestMirror_PullThrough(t *testing.T) {
+	body := bytes.Repeat([]byte("cat "), 10_000)
+	_, remote, local, m := setup(t, map[string][]byte{"photos/cat.jpg": body})
+
+	modified := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := remote.PutObject(t.Context(), &fs.PutObjectRequest{
+		Bucket: "photos", Key: "dog.jpg", Reader: bytes.NewReader(body), Size: int64(len(body)),
+		Metadata:     fs.ObjectMetadata{ContentType: "image/jpeg", UserMetadata: map[string]string{"breed": "corgi"}},
+		LastModified: modified,
+	})
+	require.NoError(t, err)
+
+	front, _ := fstest.NewTestServer(t, nil, fstest.WithStorage(m))
+
+	t.Run("FirstGetPulls", func(t *testing.T) {
+		_, err := local.GetObject(t.Context(), "photos", "cat.jpg")
+		require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+		status, got := get(t, front.URL+"/photos/cat.jpg")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, body, got)
+
+		cached, err := local.GetObject(t.Context(), "photos", "cat.jpg")
+		require.NoError(t, err)
+		require.NoError(t, cached.Reader.Close())
+		require.Equal(t, int64(len(body)), m.CacheSize())
+	})
+
+	t.Run("SecondGetServesLocally", func(t *testing.T) {
+		require.NoError(t, remote.DeleteObject(t.Context(), "photos", "cat.jpg"))
+
+		status, got := get(t, front.URL+"/photos/cat.jpg")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, body, got)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		status, _ := get(t, front.URL+"/photos/dog.jpg")
+		require.Equal(t, http.StatusOK, status)
+
+		cached, err := local.GetObject(t.Context(), "photos", "dog.jpg")
+		require.NoError(t, err)
+		require.NoError(t, cached.Reader.Close())
+		require.Equal(t, "image/jpeg", cached.Metadata.ContentType)
+		require.Equal(t, "corgi", cached.Metadata.UserMetadata["breed"])
+		require.True(t, modified.Equal(cached.LastModified))
+	})
+
+	t.Run("ColdRange", func(t *testing.T) {
+		_, err := remote.PutObject(t.Context(), &fs.PutObjectRequest{
+			Bucket: "photos", Key: "fox.jpg", Reader: bytes.NewReader(body), Size: int64(len(body)),
+		})
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, front.URL+"/photos/fox.jpg", http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=100-199")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		require.Equal(t, body[100:200], got)
+
+		// Only part of the object went by: nothing is cached.
+		_, err = local.GetObject(t.Context(), "photos", "fox.jpg")
+		require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+		status, got := get(t, front.URL+"/photos/fox.jpg")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, body, got)
+
+		cached, err := local.GetObject(t.Context(), "photos", "fox.jpg")
+		require.NoError(t, err)
+		require.NoError(t, cached.Reader.Close())
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		status, _ := get(t, front.URL+"/photos/bird.jpg")
+		require.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPut, front.URL+"/photos/new.jpg", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	})
+}
+
+func TestMirror_Eviction(t *testing.T) {
+	_, _, local, m := setup(t, map[string][]byte{
+		"bkt/one":   []byte("111111"),
+		"bkt/two":   []byte("222222"),
+		"bkt/three": []byte("333333"),
+	}, mirror.WithMaxCacheSize(12))
+
+	read := func(key string) {
+		t.Helper()
+
+		resp, err := m.GetObject(t.Context(), "bkt", key)
+		require.NoError(t, err)
+
+		_, err = io.ReadAll(resp.Reader)
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+	}
+
+	cached := func(key string) bool {
+		t.Helper()
+
+		resp, err := local.GetObject(t.Context(), "bkt", key)
+		if err != nil {
+			require.ErrorIs(t, err, fs.ErrObjectNotFound)
+			return false
+		}
+
+		require.NoError(t, resp.Reader.Close())
+
+		return true
+	}
+
+	read("one")
+	read("two")
+	read("one") // "two" is now the least recently used.
+	read("three")
+
+	require.True(t, cached("one"))
+	require.False(t, cached("two"))
+	require.True(t, cached("three"))
+	require.Equal(t, int64(12), m.CacheSize())
+
+	t.Run("Restart", func(t *testing.T) {
+		upstream := storagemem.New()
+
+		restarted, err := mirror.New(t.Context(), local, upstream, mirror.WithMaxCacheSize(12))
+		require.NoError(t, err)
+		require.Equal(t, int64(12), restarted.CacheSize())
+	})
+}
+
+func TestMirror_AbandonedRead(t *testing.T) {
+	_, _, local, m := setup(t, map[string][]byte{"bkt/obj": bytes.Repeat([]byte("x"), 1<<20)})
+
+	resp, err := m.GetObject(t.Context(), "bkt", "obj")
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(resp.Reader, make([]byte, 1024))
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	_, err = local.GetObject(t.Context(), "bkt", "obj")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+	require.Zero(t, m.CacheSize())
+}
+
+func TestMirror_UpstreamDown(t *testing.T) {
+	upstreamSrv, _, _, m := setup(t, map[string][]byte{"bkt/obj": []byte("cached")})
+
+	resp, err := m.GetObject(t.Context(), "bkt", "obj")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(resp.Reader)
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	upstreamSrv.Close()
+
+	objects, err := m.ListObjects(t.Context(), "bkt", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	resp, err = m.GetObject(t.Context(), "bkt", "obj")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(resp.Reader)
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+	require.Equal(t, "cached", string(data))
+}
@@ -0,0 +1,142 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3client"
+)
+
+// S3Upstream is an Upstream reached over the S3 API.
+type S3Upstream struct {
+	client *minio.Client
+}
+
+var _ Upstream = (*S3Upstream)(nil)
+
+// NewS3Upstream returns an Upstream for the S3 endpoint at rawURL
+// ("https://s3.example.com"; https when the scheme is omitted), addressed
+// path-style. Requests are signed with accessKey and secretKey, or anonymous
+// when both are empty.
+func NewS3Upstream(rawURL, accessKey, secretKey string) (*S3Upstream, error) {
+	client, _, err := s3client.New(rawURL, accessKey, secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Upstream{client: client}, nil
+}
+
+// upstreamError maps S3 error codes onto the fs sentinels, so a miss upstream
+// is a miss for the mirror's clients too.
+func upstreamError(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchBucket":
+		return errors.Wrap(fs.ErrBucketNotFound, err.Error())
+	case "NoSuchKey":
+		return errors.Wrap(fs.ErrObjectNotFound, err.Error())
+	default:
+		return err
+	}
+}
+
+// ListBuckets implements Upstream.
+func (u *S3Upstream) ListBuckets(ctx context.Context) ([]fs.Bucket, error) {
+	infos, err := u.client.ListBuckets(ctx)
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+
+	buckets := make([]fs.Bucket, 0, len(infos))
+	for _, info := range infos {
+		buckets = append(buckets, fs.Bucket{Name: info.Name, CreationDate: info.CreationDate})
+	}
+
+	return buckets, nil
+}
+
+// BucketExists implements Upstream.
+func (u *S3Upstream) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	ok, err := u.client.BucketExists(ctx, bucket)
+	if err != nil {
+		return false, upstreamError(err)
+	}
+
+	return ok, nil
+}
+
+// ListObjects implements Upstream.
+func (u *S3Upstream) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.Object, error) {
+	var objects []fs.Object
+
+	for info := range u.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, upstreamError(info.Err)
+		}
+
+		objects = append(objects, fs.Object{
+			Key:          info.Key,
+			Size:         info.Size,
+			LastModified: info.LastModified,
+			ETag:         strings.Trim(info.ETag, `"`),
+		})
+	}
+
+	return objects, nil
+}
+
+// GetObject implements Upstream. The body is streamed: nothing is read until
+// the caller reads it.
+func (u *S3Upstream) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
+	obj, err := u.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+
+	info, err := obj.Stat()
+	if err != nil {
+		_ = obj.Close()
+		return nil, upstreamError(err)
+	}
+
+	return &fs.GetObjectResponse{
+		Reader:       obj,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		ETag:         strings.Trim(info.ETag, `"`),
+		Metadata:     objectMetadata(info.Metadata),
+	}, nil
+}
+
+// objectMetadata extracts the representation headers and x-amz-meta-* pairs
+// from an upstream response.
+func objectMetadata(h http.Header) fs.ObjectMetadata {
+	meta := fs.ObjectMetadata{
+		ContentType:        h.Get("Content-Type"),
+		CacheControl:       h.Get("Cache-Control"),
+		ContentDisposition: h.Get("Content-Disposition"),
+		ContentEncoding:    h.Get("Content-Encoding"),
+	}
+
+	const prefix = "x-amz-meta-"
+
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, prefix) || len(values) == 0 {
+			continue
+		}
+
+		if meta.UserMetadata == nil {
+			meta.UserMetadata = make(map[string]string)
+		}
+
+		meta.UserMetadata[strings.TrimPrefix(lower, prefix)] = values[0]
+	}
+
+	return meta
+}
@@ -0,0 +1,106 @@
+// Package notify delivers S3 event notifications for object changes. Events
+// use the JSON document shape of Amazon S3 event notifications (a Records
+// array of eventVersion 2.1 records), so consumers written for S3 can parse
+// them unchanged.
+//
+// The server hands each event to a synchronous Sink on the request path after
+// the change succeeded. Sinks must not block; Queue adapts a slow or failing
+// destination (such as Webhook) into a Sink with bounded buffering and retried
+// delivery.
+package notify
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Name is an S3 event type, as carried in a record's eventName.
+type Name string
+
+// Event types emitted by the server.
+const (
+	ObjectCreatedPut                     Name = "ObjectCreated:Put"
+	ObjectCreatedPost                    Name = "ObjectCreated:Post"
+	ObjectCreatedCopy                    Name = "ObjectCreated:Copy"
+	ObjectCreatedCompleteMultipartUpload Name = "ObjectCreated:CompleteMultipartUpload"
+	ObjectRemovedDelete                  Name = "ObjectRemoved:Delete"
+)
+
+// Event is an S3 event notification document.
+type Event struct {
+	Records []Record `json:"Records"`
+}
+
+// Record describes a single object change.
+type Record struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AWSRegion         string            `json:"awsRegion"`
+	EventTime         time.Time         `json:"eventTime"`
+	EventName         Name              `json:"eventName"`
+	RequestParameters map[string]string `json:"requestParameters,omitempty"`
+	ResponseElements  map[string]string `json:"responseElements,omitempty"`
+	S3                S3Entity          `json:"s3"`
+}
+
+// S3Entity identifies the bucket and object a record is about.
+type S3Entity struct {
+	SchemaVersion string       `json:"s3SchemaVersion"`
+	Bucket        BucketEntity `json:"bucket"`
+	Object        ObjectEntity `json:"object"`
+}
+
+// BucketEntity is the bucket of a record.
+type BucketEntity struct {
+	Name string `json:"name"`
+	ARN  string `json:"arn"`
+}
+
+// ObjectEntity is the object of a record. Size and ETag are omitted for
+// removals.
+type ObjectEntity struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// Region is reported as awsRegion; the server is single-region.
+const Region = "us-east-1"
+
+// sequence orders records emitted by this process. S3 only guarantees that
+// sequencers of the same key compare in event order, which this satisfies.
+var sequence atomic.Uint64
+
+// NewRecord returns a record of the given type for bucket/key, stamped with the
+// current time and the next sequencer. ETag is unquoted hex, as in S3 events.
+func NewRecord(name Name, bucket, key string, size int64, etag string) Record {
+	return Record{
+		EventVersion: "2.1",
+		EventSource:  "aws:s3",
+		AWSRegion:    Region,
+		EventTime:    time.Now().UTC(),
+		EventName:    name,
+		S3: S3Entity{
+			SchemaVersion: "1.0",
+			Bucket:        BucketEntity{Name: bucket, ARN: "arn:aws:s3:::" + bucket},
+			Object: ObjectEntity{
+				Key:       key,
+				Size:      size,
+				ETag:      etag,
+				Sequencer: sequencer(sequence.Add(1)),
+			},
+		},
+	}
+}
+
+// sequencer renders n as the fixed-width uppercase hex S3 uses, so sequencers
+// compare correctly as strings.
+func sequencer(n uint64) string {
+	return fmt.Sprintf("%016X", n)
+}
+
+// Sink receives events on the request path, after the change is durable. It
+// must return quickly; see Queue.
+type Sink func(Event)
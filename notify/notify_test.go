@@ -0,0 +1,122 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/notify"
+)
+
+func TestQueueRetriesUntilDelivered(t *testing.T) {
+	var (
+		attempts atomic.Int32
+		mu       sync.Mutex
+		got      []notify.Event
+	)
+
+	q := notify.NewQueue(func(_ context.Context, e notify.Event) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("unavailable")
+		}
+
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+
+		return nil
+	}, 4)
+
+	q.Send(notify.Event{Records: []notify.Record{notify.NewRecord(notify.ObjectCreatedPut, "b", "k", 1, "e")}})
+	require.NoError(t, q.Close(t.Context()))
+
+	require.EqualValues(t, 3, attempts.Load())
+	require.Len(t, got, 1)
+	require.Equal(t, "k", got[0].Records[0].S3.Object.Key)
+}
+
+func TestQueueDropsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+
+	q := notify.NewQueue(func(context.Context, notify.Event) error {
+		<-release
+		return nil
+	}, 1)
+
+	// One event is held by the worker, one fills the buffer; the rest drop.
+	for range 10 {
+		q.Send(notify.Event{})
+	}
+
+	require.Positive(t, q.Dropped())
+	close(release)
+	require.NoError(t, q.Close(t.Context()))
+}
+
+func TestQueueCloseAbandonsRetries(t *testing.T) {
+	q := notify.NewQueue(func(context.Context, notify.Event) error {
+		return errors.New("down")
+	}, 1)
+	q.Send(notify.Event{})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, q.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestQueueSendAfterClose(t *testing.T) {
+	q := notify.NewQueue(func(context.Context, notify.Event) error { return nil }, 1)
+	require.NoError(t, q.Close(t.Context()))
+
+	require.NotPanics(t, func() { q.Send(notify.Event{}) })
+	require.EqualValues(t, 1, q.Dropped())
+}
+
+func TestWebhook(t *testing.T) {
+	var (
+		calls atomic.Int32
+		body  []byte
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	deliver := notify.Webhook(srv.URL, srv.Client())
+	e := notify.Event{Records: []notify.Record{notify.NewRecord(notify.ObjectRemovedDelete, "b", "dir/k", 0, "")}}
+
+	require.Error(t, deliver(t.Context(), e))
+	require.NoError(t, deliver(t.Context(), e))
+
+	var doc map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+
+	rec := doc["Records"][0]
+	require.Equal(t, "ObjectRemoved:Delete", rec["eventName"])
+	require.Equal(t, "aws:s3", rec["eventSource"])
+
+	obj := rec["s3"].(map[string]any)["object"].(map[string]any)
+	require.Equal(t, "dir/k", obj["key"])
+	require.NotContains(t, obj, "size")
+	require.Len(t, obj["sequencer"], 16)
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeliverFunc sends one event to its destination, returning an error if it
+// should be retried.
+type DeliverFunc func(ctx context.Context, e Event) error
+
+// Queue decouples delivery from the request path: Send enqueues without
+// blocking, and a single worker delivers events in order, retrying each with
+// capped exponential backoff until it succeeds. Delivery is at-least-once for
+// every event the queue accepts; a retried event may arrive more than once if
+// the destination failed after processing it.
+//
+// The buffer is bounded. When it is full, Send drops the event and counts it
+// in Dropped rather than stall the request. Events sent after Close are
+// dropped and counted the same way.
+type Queue struct {
+	deliver DeliverFunc
+	events  chan Event
+	dropped atomic.Int64
+
+	// mu guards closed, so Send never races Close into a closed channel.
+	mu     sync.Mutex
+	closed bool
+
+	stop context.CancelFunc
+	ctx  context.Context //nolint:containedctx // Cancels in-flight retries on Close.
+	done chan struct{}
+
+	// minBackoff and maxBackoff bound the delay between delivery attempts.
+	minBackoff, maxBackoff time.Duration
+}
+
+// Backoff bounds used by NewQueue.
+const (
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// NewQueue starts a queue buffering up to size events for deliver. Close it to
+// stop the worker.
+func NewQueue(deliver DeliverFunc, size int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &Queue{
+		deliver:    deliver,
+		events:     make(chan Event, size),
+		stop:       cancel,
+		ctx:        ctx,
+		done:       make(chan struct{}),
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+
+	go q.run()
+
+	return q
+}
+
+// Send enqueues e, dropping it if the buffer is full or the queue is closed.
+// It has the Sink signature, so q.Send can be passed wherever a Sink is
+// expected.
+func (q *Queue) Send(e Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		q.dropped.Add(1)
+		return
+	}
+
+	select {
+	case q.events <- e:
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events discarded because the buffer was full
+// or the queue was closed.
+func (q *Queue) Dropped() int64 { return q.dropped.Load() }
+
+// Close stops accepting events and waits for the buffered ones to be
+// delivered. If ctx ends first, pending retries are abandoned and ctx's error
+// is returned.
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.events)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		q.stop()
+		<-q.done
+
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+	defer q.stop()
+
+	for e := range q.events {
+		q.deliverWithRetry(e)
+	}
+}
+
+// deliverWithRetry attempts e until it is delivered or the queue is stopped.
+func (q *Queue) deliverWithRetry(e Event) {
+	backoff := q.minBackoff
+
+	for {
+		if q.ctx.Err() != nil {
+			return
+		}
+
+		if err := q.deliver(q.ctx, e); err == nil {
+			return
+		}
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, q.maxBackoff)
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+// DefaultWebhookTimeout bounds each POST made by a Webhook given a nil client.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// Webhook returns a DeliverFunc that POSTs each event as JSON to url. Any
+// non-2xx response is an error, so a Queue retries it. A nil client means one
+// with DefaultWebhookTimeout, so a webhook that never answers cannot stall the
+// queue.
+func Webhook(url string, client *http.Client) DeliverFunc {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+
+	return func(ctx context.Context, e Event) error {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return errors.Wrap(err, "marshal event")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return errors.Wrap(err, "create request")
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "post event")
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.Errorf("webhook returned %s", resp.Status)
+		}
+
+		return nil
+	}
+}
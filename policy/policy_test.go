@@ -0,0 +1,47 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/policy"
+)
+
+func TestRegistry_PrefixPolicy(t *testing.T) {
+	var r policy.Registry
+
+	_, _, ok := r.PrefixPolicy("b", "k")
+	require.False(t, ok)
+
+	r.SetPrefixPolicy("b", "", policy.PrefixPolicy{MaxBytes: 1})
+	r.SetPrefixPolicy("b", "logs/", policy.PrefixPolicy{ReadOnly: true})
+	r.SetPrefixPolicy("b", "logs/app/", policy.PrefixPolicy{MaxBytes: 3, RequiredMetadata: []string{"Team"}})
+	r.SetPrefixPolicy("other", "logs/", policy.PrefixPolicy{MaxBytes: 4})
+
+	for _, tt := range []struct {
+		bucket, key string
+		prefix      string
+		want        policy.PrefixPolicy
+		ok          bool
+	}{
+		{"b", "readme", "", policy.PrefixPolicy{MaxBytes: 1}, true},
+		{"b", "logs/sys", "logs/", policy.PrefixPolicy{ReadOnly: true}, true},
+		{"b", "logs/app/1", "logs/app/", policy.PrefixPolicy{MaxBytes: 3, RequiredMetadata: []string{"team"}}, true},
+		{"b", "logs", "", policy.PrefixPolicy{MaxBytes: 1}, true},
+		{"other", "logs/app/1", "logs/", policy.PrefixPolicy{MaxBytes: 4}, true},
+		{"other", "data", "", policy.PrefixPolicy{}, false},
+		{"none", "logs/x", "", policy.PrefixPolicy{}, false},
+	} {
+		prefix, got, ok := r.PrefixPolicy(tt.bucket, tt.key)
+		require.Equal(t, tt.ok, ok, "%s/%s", tt.bucket, tt.key)
+		require.Equal(t, tt.prefix, prefix, "%s/%s", tt.bucket, tt.key)
+		require.Equal(t, tt.want, got, "%s/%s", tt.bucket, tt.key)
+	}
+
+	r.RemovePrefixPolicy("b", "logs/app/")
+
+	prefix, _, ok := r.PrefixPolicy("b", "logs/app/1")
+	require.True(t, ok)
+	require.Equal(t, "logs/", prefix)
+}
@@ -1,6 +1,9 @@
 package fs
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 // PreconditionFailed reports whether the request's If-None-Match / If-Match
 // conditions fail against the current object state, where exists reports whether
@@ -19,8 +22,55 @@ import "strings"
 //   - If-Match: *               fail if the object does not exist.
 //   - If-Match: "<etag>"        fail if it is missing or the ETag differs.
 func (r *PutObjectRequest) PreconditionFailed(exists bool, currentETag string) bool {
-	ifNoneMatch := strings.TrimSpace(r.IfNoneMatch)
-	ifMatch := strings.TrimSpace(r.IfMatch)
+	return preconditionFailed(r.IfNoneMatch, r.IfMatch, exists, currentETag)
+}
+
+// PreconditionFailed is PutObjectRequest.PreconditionFailed for the object a
+// multipart upload completes, under the same locking rule.
+func (r *CompleteMultipartUploadRequest) PreconditionFailed(exists bool, currentETag string) bool {
+	return preconditionFailed(r.IfNoneMatch, r.IfMatch, exists, currentETag)
+}
+
+// DeleteCondition is the If-Match condition of a conditional DeleteObject:
+// the object is deleted only while its ETag matches, or with "*" only if it
+// exists, so a client never deletes a version it has not seen.
+type DeleteCondition struct {
+	// IfMatch is "*" or a comma-separated list of entity tags.
+	IfMatch string
+}
+
+type deleteConditionContextKey struct{}
+
+// WithDeleteCondition returns a context carrying cond for the DeleteObject
+// made with it. Like WithCustomerKey, it travels with the context because the
+// Storage interface has no per-call options. Backends evaluate it with
+// PreconditionFailed under the same lock as the delete.
+func WithDeleteCondition(ctx context.Context, cond *DeleteCondition) context.Context {
+	return context.WithValue(ctx, deleteConditionContextKey{}, cond)
+}
+
+// DeleteConditionFromContext returns the condition attached by
+// WithDeleteCondition, or nil.
+func DeleteConditionFromContext(ctx context.Context) *DeleteCondition {
+	cond, _ := ctx.Value(deleteConditionContextKey{}).(*DeleteCondition)
+	return cond
+}
+
+// PreconditionFailed reports whether the delete must be rejected with
+// ErrPreconditionFailed, with the same If-Match semantics as
+// PutObjectRequest.PreconditionFailed: a missing object fails any condition.
+// A nil condition never fails.
+func (c *DeleteCondition) PreconditionFailed(exists bool, currentETag string) bool {
+	if c == nil {
+		return false
+	}
+
+	return preconditionFailed("", c.IfMatch, exists, currentETag)
+}
+
+func preconditionFailed(ifNoneMatch, ifMatch string, exists bool, currentETag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	ifMatch = strings.TrimSpace(ifMatch)
 
 	if ifNoneMatch == "" && ifMatch == "" {
 		return false
@@ -0,0 +1,124 @@
+package fs
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// PutObjectRange overwrites the bytes of bucket/key from offset on with the
+// size bytes of r, extending the object when they run past its end, and
+// returns the new ETag. offset may be the object's size, which appends; a
+// larger one is ErrInvalidRange, since there is nothing to fill the gap
+// with. As for PutObjectRequest, size is -1 when the length is not known,
+// which not every backend accepts. Metadata, tags and ACL are kept; the ETag
+// and size are those of the new content.
+//
+// A RangeWriter writes the range itself, typically in place; any other
+// storage gets RewriteObjectRange.
+func PutObjectRange(ctx context.Context, s Storage, bucket, key string, offset int64, r io.Reader, size int64) (*PutObjectResponse, error) {
+	if rw, ok := s.(RangeWriter); ok {
+		return rw.PutObjectRange(ctx, bucket, key, offset, r, size)
+	}
+
+	return RewriteObjectRange(ctx, s, bucket, key, offset, r, size)
+}
+
+// RewriteObjectRange is PutObjectRange for any storage: the object is
+// rewritten as a whole through PutObject, streaming the old body around the
+// new bytes, so it changes atomically and gets a fresh ETag and size. The
+// write is conditional on the ETag that was read: an object replaced
+// meanwhile fails the call with ErrPreconditionFailed instead of losing
+// either write.
+func RewriteObjectRange(ctx context.Context, s Storage, bucket, key string, offset int64, r io.Reader, size int64) (*PutObjectResponse, error) {
+	obj, err := s.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = obj.Reader.Close() }()
+
+	if offset < 0 || offset > obj.Size {
+		return nil, errors.Wrapf(ErrInvalidRange, "offset %d of a %d-byte object", offset, obj.Size)
+	}
+
+	total := int64(-1)
+	if size >= 0 {
+		total = max(obj.Size, offset+size)
+	}
+
+	tags, err := s.GetObjectTagging(ctx, bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get tags")
+	}
+
+	acl, err := s.ObjectACL(ctx, bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get acl")
+	}
+
+	return s.PutObject(ctx, &PutObjectRequest{
+		Reader: &overlayReader{
+			base:  obj.Reader,
+			patch: r,
+			head:  offset,
+		},
+		Bucket:   bucket,
+		Key:      key,
+		Size:     total,
+		Metadata: obj.Metadata,
+		Tags:     tags,
+		ACL:      acl,
+		IfMatch:  `"` + obj.ETag + `"`,
+	})
+}
+
+// overlayReader reads base with patch laid over it from offset head: the
+// first head bytes of base, then patch while as many bytes of base are
+// skipped, then whatever of base is left.
+type overlayReader struct {
+	base  io.Reader
+	patch io.Reader
+	head  int64
+
+	patched bool // patch is exhausted
+}
+
+func (o *overlayReader) Read(p []byte) (int, error) {
+	if o.head > 0 {
+		if int64(len(p)) > o.head {
+			p = p[:o.head]
+		}
+
+		n, err := o.base.Read(p)
+		o.head -= int64(n)
+
+		if errors.Is(err, io.EOF) && o.head > 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+
+		return n, err
+	}
+
+	if !o.patched {
+		n, err := o.patch.Read(p)
+		if n > 0 {
+			// The overwritten bytes; base may end before they do.
+			if _, serr := io.CopyN(io.Discard, o.base, int64(n)); serr != nil && !errors.Is(serr, io.EOF) {
+				return n, serr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			o.patched, err = true, nil
+		}
+
+		return n, err
+	}
+
+	return o.base.Read(p)
+}
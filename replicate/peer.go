@@ -0,0 +1,62 @@
+package replicate
+
+import (
+	"context"
+
+	"github.com/go-faster/errors"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/internal/s3client"
+)
+
+// peer is a replication target and the operations queued for it.
+type peer struct {
+	endpoint string
+	client   *minio.Client
+	ops      chan op
+}
+
+func newPeer(t Target, buffer int) (*peer, error) {
+	client, endpoint, err := s3client.New(t.Endpoint, t.AccessKey, t.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &peer{
+		endpoint: endpoint,
+		client:   client,
+		ops:      make(chan op, buffer),
+	}, nil
+}
+
+// put uploads obj to bucket/key with its representation headers and user
+// metadata.
+func (p *peer) put(ctx context.Context, bucket, key string, obj *fs.GetObjectResponse) error {
+	meta := obj.Metadata
+
+	_, err := p.client.PutObject(ctx, bucket, key, obj.Reader, obj.Size, minio.PutObjectOptions{
+		ContentType:        meta.ContentType,
+		CacheControl:       meta.CacheControl,
+		ContentDisposition: meta.ContentDisposition,
+		ContentEncoding:    meta.ContentEncoding,
+		UserMetadata:       meta.UserMetadata,
+	})
+	if err != nil {
+		return errors.Wrap(err, "put")
+	}
+
+	return nil
+}
+
+// delete removes bucket/key. A key the peer does not have is not an error: a
+// creation skipped because the object was already gone is followed by its
+// removal.
+func (p *peer) delete(ctx context.Context, bucket, key string) error {
+	err := p.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		return errors.Wrap(err, "delete")
+	}
+
+	return nil
+}
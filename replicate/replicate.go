@@ -0,0 +1,368 @@
+// Package replicate forwards object writes to peer S3 endpoints, for a simple
+// disaster-recovery standby.
+//
+// A Replicator is a notify.Sink: installed with server.WithReplication, it is
+// told about every object creation and removal after it succeeded and replays
+// it on each peer in the background. A creation (PUT, POST, copy or multipart
+// completion) is replayed by reading the object back from the local store and
+// PUTting it with its metadata, a removal by DELETE. The client's request
+// never waits for a peer and never fails because of one.
+//
+// Every peer has its own bounded queue and worker, so a slow or unreachable
+// peer only delays itself, and operations reach it in the order they
+// happened. A failed operation is retried with capped exponential backoff up
+// to WithMaxAttempts times. One that still fails, or that finds the peer's
+// queue full, is given up on: it is written to the dead-letter log
+// (WithDeadLetter) and counted in Stats. Buckets are not replicated; they
+// must already exist on every peer.
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/notify"
+)
+
+// Defaults used by New.
+const (
+	DefaultBuffer      = 1024
+	DefaultMaxAttempts = 5
+	DefaultMinBackoff  = 100 * time.Millisecond
+	DefaultMaxBackoff  = 30 * time.Second
+)
+
+// Target is a peer S3 endpoint writes are replicated to.
+type Target struct {
+	// Endpoint is the peer's base URL ("https://dr.example.com"; https when
+	// the scheme is omitted), addressed path-style.
+	Endpoint string
+	// AccessKey and SecretKey sign the requests; with both empty they are
+	// sent anonymously.
+	AccessKey string
+	SecretKey string
+}
+
+// Op is the kind of a replicated operation.
+type Op string
+
+// Replicated operations.
+const (
+	OpPut    Op = "put"
+	OpDelete Op = "delete"
+)
+
+// DeadLetter is an operation given up on, one JSON line of the dead-letter
+// log. Attempts is 0 when the peer's queue was full.
+type DeadLetter struct {
+	Time     time.Time `json:"time"`
+	Endpoint string    `json:"endpoint"`
+	Op       Op        `json:"op"`
+	Bucket   string    `json:"bucket"`
+	Key      string    `json:"key"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+}
+
+// Stats counts replicated operations over all peers.
+type Stats struct {
+	// Replicated operations reached their peer.
+	Replicated int64
+	// Failed operations ran out of attempts.
+	Failed int64
+	// Dropped operations found their peer's queue full.
+	Dropped int64
+}
+
+// Option configures a Replicator.
+type Option func(*Replicator)
+
+// WithBuffer sets how many operations may wait for each peer (default
+// DefaultBuffer). Operations beyond it are dropped to the dead-letter log.
+func WithBuffer(n int) Option {
+	return func(r *Replicator) { r.buffer = n }
+}
+
+// WithMaxAttempts sets how many times an operation is tried before it is
+// given up on (default DefaultMaxAttempts).
+func WithMaxAttempts(n int) Option {
+	return func(r *Replicator) { r.maxAttempts = n }
+}
+
+// WithBackoff bounds the delay between attempts; it doubles from minBackoff
+// up to maxBackoff (defaults DefaultMinBackoff and DefaultMaxBackoff).
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(r *Replicator) { r.minBackoff, r.maxBackoff = minBackoff, maxBackoff }
+}
+
+// WithDeadLetter writes every operation given up on to w as a JSON line (see
+// DeadLetter). Without it they are only counted.
+func WithDeadLetter(w io.Writer) Option {
+	return func(r *Replicator) { r.deadLetter = w }
+}
+
+// Replicator replays object writes on peer S3 endpoints. Create it with New
+// and Close it after the server has stopped.
+type Replicator struct {
+	local fs.Storage
+	peers []*peer
+
+	buffer                 int
+	maxAttempts            int
+	minBackoff, maxBackoff time.Duration
+
+	deadMu     sync.Mutex
+	deadLetter io.Writer
+
+	replicated atomic.Int64
+	failed     atomic.Int64
+	dropped    atomic.Int64
+
+	// closeMu guards closed: Send holds it shared while queueing, Close
+	// exclusively to set it, so no operation is queued once done is closed.
+	closeMu sync.RWMutex
+	closed  bool
+	done    chan struct{}
+
+	stop context.CancelFunc
+	ctx  context.Context //nolint:containedctx // Cancels in-flight retries on Close.
+	wg   sync.WaitGroup
+}
+
+// op is one operation waiting for a peer.
+type op struct {
+	kind   Op
+	bucket string
+	key    string
+}
+
+// New starts replicating the writes of local to targets. local is the store
+// the server writes to: creations are read back from it.
+func New(local fs.Storage, targets []Target, opts ...Option) (*Replicator, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no replication targets")
+	}
+
+	r := &Replicator{
+		local:       local,
+		done:        make(chan struct{}),
+		buffer:      DefaultBuffer,
+		maxAttempts: DefaultMaxAttempts,
+		minBackoff:  DefaultMinBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.maxAttempts = max(r.maxAttempts, 1)
+
+	for _, t := range targets {
+		p, err := newPeer(t, r.buffer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "target %q", t.Endpoint)
+		}
+
+		r.peers = append(r.peers, p)
+	}
+
+	r.ctx, r.stop = context.WithCancel(context.Background())
+
+	for _, p := range r.peers {
+		r.wg.Add(1)
+
+		go func() {
+			defer r.wg.Done()
+			r.run(p)
+		}()
+	}
+
+	return r, nil
+}
+
+// Send queues the operations of e for every peer without blocking. It has the
+// notify.Sink signature. Operations sent after Close are dropped: they are
+// counted in Stats but not written to the dead-letter log, which the caller
+// may have closed.
+func (r *Replicator) Send(e notify.Event) {
+	r.closeMu.RLock()
+	defer r.closeMu.RUnlock()
+
+	for _, rec := range e.Records {
+		o := op{bucket: rec.S3.Bucket.Name, key: rec.S3.Object.Key}
+
+		switch {
+		case strings.HasPrefix(string(rec.EventName), "ObjectCreated:"):
+			o.kind = OpPut
+		case strings.HasPrefix(string(rec.EventName), "ObjectRemoved:"):
+			o.kind = OpDelete
+		default:
+			continue
+		}
+
+		if r.closed {
+			r.dropped.Add(int64(len(r.peers)))
+			continue
+		}
+
+		for _, p := range r.peers {
+			select {
+			case p.ops <- o:
+			default:
+				r.dropped.Add(1)
+				r.dead(p, o, 0, errors.New("replication queue full"))
+			}
+		}
+	}
+}
+
+// Stats returns the operation counts so far.
+func (r *Replicator) Stats() Stats {
+	return Stats{
+		Replicated: r.replicated.Load(),
+		Failed:     r.failed.Load(),
+		Dropped:    r.dropped.Load(),
+	}
+}
+
+// Close stops accepting operations and waits for the queued ones to be
+// replicated. If ctx ends first, pending retries are abandoned and ctx's error
+// is returned.
+func (r *Replicator) Close(ctx context.Context) error {
+	r.closeMu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+	r.closeMu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.stop()
+		return nil
+	case <-ctx.Done():
+		r.stop()
+		<-done
+
+		return ctx.Err()
+	}
+}
+
+// run replays the operations queued for p, in order, until Close; the queue
+// is then drained and run returns. The queue itself is never closed, so a
+// racing Send cannot panic on it.
+func (r *Replicator) run(p *peer) {
+	for {
+		select {
+		case o := <-p.ops:
+			r.apply(p, o)
+		case <-r.done:
+			for {
+				select {
+				case o := <-p.ops:
+					r.apply(p, o)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// apply replicates o on p and records the outcome.
+func (r *Replicator) apply(p *peer, o op) {
+	attempts, err := r.replicateWithRetry(p, o)
+	if err != nil {
+		r.failed.Add(1)
+		r.dead(p, o, attempts, err)
+
+		return
+	}
+
+	r.replicated.Add(1)
+}
+
+// replicateWithRetry tries o on p until it succeeds, runs out of attempts or
+// the replicator is stopped, returning the attempts made and the last error.
+func (r *Replicator) replicateWithRetry(p *peer, o op) (int, error) {
+	backoff := r.minBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := r.replicate(r.ctx, p, o)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if attempt >= r.maxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return attempt, err
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, r.maxBackoff)
+	}
+}
+
+// replicate applies o to p once. A creation reads the object's current state,
+// so an object deleted since is skipped: its removal follows in the queue.
+func (r *Replicator) replicate(ctx context.Context, p *peer, o op) error {
+	if o.kind == OpDelete {
+		return p.delete(ctx, o.bucket, o.key)
+	}
+
+	obj, err := r.local.GetObject(ctx, o.bucket, o.key)
+	if errors.Is(err, fs.ErrObjectNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "read local object")
+	}
+	defer func() { _ = obj.Reader.Close() }()
+
+	return p.put(ctx, o.bucket, o.key, obj)
+}
+
+// dead records an operation given up on.
+func (r *Replicator) dead(p *peer, o op, attempts int, err error) {
+	if r.deadLetter == nil {
+		return
+	}
+
+	line, mErr := json.Marshal(DeadLetter{
+		Time:     time.Now().UTC(),
+		Endpoint: p.endpoint,
+		Op:       o.kind,
+		Bucket:   o.bucket,
+		Key:      o.key,
+		Attempts: attempts,
+		Error:    err.Error(),
+	})
+	if mErr != nil {
+		return
+	}
+
+	r.deadMu.Lock()
+	defer r.deadMu.Unlock()
+
+	_, _ = r.deadLetter.Write(append(line, '\n'))
+}
@@ -0,0 +1,230 @@
+package replicate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/notify"
+	"github.com/go-faster/fs/replicate"
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+const bucket = "data"
+
+// newStore returns a storagemem store holding the test bucket.
+func newStore(t *testing.T) fs.Storage {
+	t.Helper()
+
+	s := storagemem.New()
+	require.NoError(t, s.CreateBucket(t.Context(), bucket))
+
+	return s
+}
+
+// serve serves store over HTTP and returns its base URL.
+func serve(t *testing.T, store fs.Storage, opts ...server.HandlerOption) string {
+	t.Helper()
+
+	srv := httptest.NewServer(server.NewHandler(store, opts...))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func newClient(t *testing.T, rawURL string) *minio.Client {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	client, err := minio.New(u.Host, &minio.Options{})
+	require.NoError(t, err)
+
+	return client
+}
+
+// syncBuffer is an io.Writer safe to read while the replicator writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestReplication(t *testing.T) {
+	ctx := t.Context()
+
+	replica := newStore(t)
+	replicaURL := serve(t, replica)
+
+	primary := newStore(t)
+
+	var dead syncBuffer
+
+	r, err := replicate.New(primary, []replicate.Target{{Endpoint: replicaURL}},
+		replicate.WithBackoff(time.Millisecond, 10*time.Millisecond),
+		replicate.WithDeadLetter(&dead))
+	require.NoError(t, err)
+
+	client := newClient(t, serve(t, primary, server.WithReplication(r)))
+
+	const body = "replicated body"
+
+	_, err = client.PutObject(ctx, bucket, "dir/a.txt", strings.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"origin": "primary"},
+	})
+	require.NoError(t, err)
+
+	_, err = client.PutObject(ctx, bucket, "b.txt", strings.NewReader("gone soon"), 9, minio.PutObjectOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.RemoveObject(ctx, bucket, "b.txt", minio.RemoveObjectOptions{}))
+
+	require.NoError(t, r.Close(ctx))
+	require.Empty(t, dead.String())
+	require.Equal(t, replicate.Stats{Replicated: 3}, r.Stats())
+
+	obj, err := replica.GetObject(ctx, bucket, "dir/a.txt")
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(obj.Reader)
+	require.NoError(t, obj.Reader.Close())
+	require.NoError(t, err)
+	require.Equal(t, body, string(got))
+	require.Equal(t, "text/plain", obj.Metadata.ContentType)
+	require.Equal(t, "primary", obj.Metadata.UserMetadata["origin"])
+
+	_, err = replica.GetObject(ctx, bucket, "b.txt")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
+
+func TestReplicationDeadLetter(t *testing.T) {
+	ctx := t.Context()
+
+	// A peer that is gone: every attempt fails to connect.
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	var dead syncBuffer
+
+	primary := newStore(t)
+
+	r, err := replicate.New(primary, []replicate.Target{{Endpoint: down.URL}},
+		replicate.WithMaxAttempts(3),
+		replicate.WithBackoff(time.Millisecond, time.Millisecond),
+		replicate.WithDeadLetter(&dead))
+	require.NoError(t, err)
+
+	client := newClient(t, serve(t, primary, server.WithReplication(r)))
+
+	// The client's write succeeds regardless of the peer.
+	_, err = client.PutObject(ctx, bucket, "key", strings.NewReader("x"), 1, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Close(ctx))
+	require.Equal(t, replicate.Stats{Failed: 1}, r.Stats())
+
+	var entry replicate.DeadLetter
+	require.NoError(t, json.Unmarshal([]byte(dead.String()), &entry))
+	require.Equal(t, down.URL, entry.Endpoint)
+	require.Equal(t, replicate.OpPut, entry.Op)
+	require.Equal(t, bucket, entry.Bucket)
+	require.Equal(t, "key", entry.Key)
+	require.Equal(t, 3, entry.Attempts)
+	require.NotEmpty(t, entry.Error)
+}
+
+func TestReplicationCloseAbandonsRetries(t *testing.T) {
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	primary := newStore(t)
+
+	r, err := replicate.New(primary, []replicate.Target{{Endpoint: down.URL}},
+		replicate.WithMaxAttempts(1000),
+		replicate.WithBackoff(time.Hour, time.Hour))
+	require.NoError(t, err)
+
+	client := newClient(t, serve(t, primary, server.WithReplication(r)))
+
+	_, err = client.PutObject(t.Context(), bucket, "key", strings.NewReader("x"), 1, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, r.Close(ctx), context.DeadlineExceeded)
+	require.Equal(t, int64(1), r.Stats().Failed)
+}
+
+func TestReplicationSendAfterClose(t *testing.T) {
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	r, err := replicate.New(newStore(t), []replicate.Target{{Endpoint: down.URL}}, replicate.WithMaxAttempts(1))
+	require.NoError(t, err)
+
+	send := func() {
+		r.Send(notify.Event{Records: []notify.Record{{
+			EventName: notify.ObjectRemovedDelete,
+			S3: notify.S3Entity{
+				Bucket: notify.BucketEntity{Name: bucket},
+				Object: notify.ObjectEntity{Key: "key"},
+			},
+		}}})
+	}
+
+	// Senders racing Close must neither panic nor block it.
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				send()
+			}
+		})
+	}
+
+	require.NoError(t, r.Close(t.Context()))
+	wg.Wait()
+
+	send()
+
+	stats := r.Stats()
+	require.Equal(t, int64(8*100+1), stats.Failed+stats.Dropped)
+}
+
+func TestNewInvalidTarget(t *testing.T) {
+	_, err := replicate.New(storagemem.New(), nil)
+	require.Error(t, err)
+
+	_, err = replicate.New(storagemem.New(), []replicate.Target{{Endpoint: "ftp://peer"}})
+	require.Error(t, err)
+
+	_, err = replicate.New(storagemem.New(), []replicate.Target{{Endpoint: "http://peer/prefix"}})
+	require.Error(t, err)
+}
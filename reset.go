@@ -0,0 +1,136 @@
+package fs
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// ResetReport lists what Reset removed, or in a dry run would remove, bucket
+// by bucket in name order.
+type ResetReport struct {
+	Buckets []BucketReset
+}
+
+// BucketReset is what Reset removed from one bucket, besides the bucket
+// itself.
+type BucketReset struct {
+	Bucket  string
+	Objects int
+	Bytes   int64
+	Uploads int // in-progress multipart uploads aborted
+}
+
+// Objects returns the number of objects across all buckets.
+func (r *ResetReport) Objects() (n int) {
+	for _, b := range r.Buckets {
+		n += b.Objects
+	}
+
+	return n
+}
+
+// Reset deletes every bucket of s with all its objects and in-progress
+// multipart uploads, leaving s empty; meant for tearing down test and
+// development environments. With dryRun it only reports what it would
+// delete. On error the report covers the buckets already handled, the failed
+// one last.
+func Reset(ctx context.Context, s Storage, dryRun bool) (*ResetReport, error) {
+	buckets, err := s.ListBuckets(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list buckets")
+	}
+
+	slices.SortFunc(buckets, func(a, b Bucket) int { return strings.Compare(a.Name, b.Name) })
+
+	report := &ResetReport{Buckets: make([]BucketReset, 0, len(buckets))}
+
+	for _, b := range buckets {
+		report.Buckets = append(report.Buckets, BucketReset{Bucket: b.Name})
+		if err := resetBucket(ctx, s, &report.Buckets[len(report.Buckets)-1], resetOptions{dryRun: dryRun}); err != nil {
+			return report, errors.Wrapf(err, "reset bucket %q", b.Name)
+		}
+	}
+
+	return report, nil
+}
+
+// DeleteBucketRecursive deletes bucket together with its objects and
+// in-progress multipart uploads, and reports what it removed. Storage's
+// DeleteBucket, by contrast, refuses a bucket that still holds objects
+// (ErrBucketNotEmpty); use this only where deleting the data is the intent. An
+// object written to the bucket while it is being emptied makes the final
+// delete fail with ErrBucketNotEmpty. A missing bucket is ErrBucketNotFound.
+// On error the report covers what was already removed.
+func DeleteBucketRecursive(ctx context.Context, s Storage, bucket string) (*BucketReset, error) {
+	return DeleteBucketRecursiveFunc(ctx, s, bucket, nil)
+}
+
+// DeleteBucketRecursiveFunc is DeleteBucketRecursive calling deleted, when not
+// nil, with the key of each object as soon as it is deleted.
+func DeleteBucketRecursiveFunc(ctx context.Context, s Storage, bucket string, deleted func(key string)) (*BucketReset, error) {
+	r := &BucketReset{Bucket: bucket}
+	if err := resetBucket(ctx, s, r, resetOptions{deleted: deleted}); err != nil {
+		return r, errors.Wrapf(err, "delete bucket %q", bucket)
+	}
+
+	return r, nil
+}
+
+// resetOptions tune resetBucket.
+type resetOptions struct {
+	// dryRun only counts what would be removed.
+	dryRun bool
+	// deleted, when not nil, is called with each deleted object's key.
+	deleted func(key string)
+}
+
+// resetBucket empties and deletes the bucket of r, counting into r.
+func resetBucket(ctx context.Context, s Storage, r *BucketReset, opts resetOptions) error {
+	uploads, err := s.ListMultipartUploads(ctx, r.Bucket)
+	if err != nil {
+		return errors.Wrap(err, "list multipart uploads")
+	}
+
+	for _, u := range uploads {
+		if !opts.dryRun {
+			if err := s.AbortMultipartUpload(ctx, r.Bucket, u.Key, u.UploadID); err != nil && !errors.Is(err, ErrUploadNotFound) {
+				return errors.Wrapf(err, "abort upload of %q", u.Key)
+			}
+		}
+
+		r.Uploads++
+	}
+
+	objects, err := s.ListObjects(ctx, r.Bucket, "")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+
+	for _, o := range objects {
+		if !opts.dryRun {
+			if err := s.DeleteObject(ctx, r.Bucket, o.Key); err != nil && !errors.Is(err, ErrObjectNotFound) {
+				return errors.Wrapf(err, "delete %q", o.Key)
+			}
+
+			if opts.deleted != nil {
+				opts.deleted(o.Key)
+			}
+		}
+
+		r.Objects++
+		r.Bytes += o.Size
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+
+	if err := s.DeleteBucket(ctx, r.Bucket); err != nil && !errors.Is(err, ErrBucketNotFound) {
+		return errors.Wrap(err, "delete bucket")
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package fs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestReset(t *testing.T) {
+	ctx := t.Context()
+	s := storagemem.New()
+
+	for _, bucket := range []string{"bucket-b", "bucket-a", "empty"} {
+		require.NoError(t, s.CreateBucket(ctx, bucket))
+	}
+
+	for bucket, keys := range map[string][]string{
+		"bucket-a": {"one", "dir/two"},
+		"bucket-b": {"three"},
+	} {
+		for _, key := range keys {
+			_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: bucket, Key: key, Reader: strings.NewReader("12345"), Size: 5,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	_, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "bucket-b", Key: "big"})
+	require.NoError(t, err)
+
+	want := []fs.BucketReset{
+		{Bucket: "bucket-a", Objects: 2, Bytes: 10},
+		{Bucket: "bucket-b", Objects: 1, Bytes: 5, Uploads: 1},
+		{Bucket: "empty"},
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		report, err := fs.Reset(ctx, s, true)
+		require.NoError(t, err)
+		require.Equal(t, want, report.Buckets)
+		require.Equal(t, 3, report.Objects())
+
+		buckets, err := s.ListBuckets(ctx)
+		require.NoError(t, err)
+		require.Len(t, buckets, 3)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		report, err := fs.Reset(ctx, s, false)
+		require.NoError(t, err)
+		require.Equal(t, want, report.Buckets)
+
+		buckets, err := s.ListBuckets(ctx)
+		require.NoError(t, err)
+		require.Empty(t, buckets)
+
+		report, err = fs.Reset(ctx, s, false)
+		require.NoError(t, err)
+		require.Empty(t, report.Buckets)
+	})
+}
+
+func TestDeleteBucketRecursive(t *testing.T) {
+	ctx := t.Context()
+	s := storagemem.New()
+
+	for _, bucket := range []string{"doomed", "kept"} {
+		require.NoError(t, s.CreateBucket(ctx, bucket))
+
+		for _, key := range []string{"one", "dir/two", "dir/sub/three"} {
+			_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: bucket, Key: key, Reader: strings.NewReader("12345"), Size: 5,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	_, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "doomed", Key: "big"})
+	require.NoError(t, err)
+
+	// The plain delete still refuses a bucket with objects.
+	require.ErrorIs(t, s.DeleteBucket(ctx, "doomed"), fs.ErrBucketNotEmpty)
+
+	var keys []string
+
+	removed, err := fs.DeleteBucketRecursiveFunc(ctx, s, "doomed", func(key string) { keys = append(keys, key) })
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"one", "dir/two", "dir/sub/three"}, keys)
+	require.Equal(t, &fs.BucketReset{Bucket: "doomed", Objects: 3, Bytes: 15, Uploads: 1}, removed)
+
+	exists, err := s.BucketExists(ctx, "doomed")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	objects, err := s.ListObjects(ctx, "kept", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 3, "other buckets untouched")
+
+	_, err = fs.DeleteBucketRecursive(ctx, s, "doomed")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
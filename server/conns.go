@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnStats counts the server's client connections by state, as reported by
+// http.Server.ConnState.
+type ConnStats struct {
+	// New, Active and Idle are the open connections currently in each state:
+	// accepted with no request read yet, serving a request, and kept alive
+	// between requests.
+	New    int64
+	Active int64
+	Idle   int64
+	// Accepted and Closed count connections since the server was created.
+	// Hijacked connections count as closed.
+	Accepted int64
+	Closed   int64
+}
+
+// Open returns the connections currently open.
+func (c ConnStats) Open() int64 { return c.New + c.Active + c.Idle }
+
+// connTracker keeps ConnStats up to date from ConnState callbacks.
+type connTracker struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+	stats  ConnStats
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{states: make(map[net.Conn]http.ConnState)}
+}
+
+// track is the http.Server.ConnState hook.
+func (t *connTracker) track(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if prev, ok := t.states[conn]; ok {
+		*t.counter(prev)--
+	}
+
+	switch state {
+	case http.StateNew:
+		t.stats.Accepted++
+	case http.StateClosed, http.StateHijacked:
+		t.stats.Closed++
+		delete(t.states, conn)
+
+		return
+	}
+
+	t.states[conn] = state
+	*t.counter(state)++
+}
+
+// counter returns the gauge of an open state.
+func (t *connTracker) counter(state http.ConnState) *int64 {
+	switch state {
+	case http.StateActive:
+		return &t.stats.Active
+	case http.StateIdle:
+		return &t.stats.Idle
+	default:
+		return &t.stats.New
+	}
+}
+
+func (t *connTracker) snapshot() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stats
+}
@@ -0,0 +1,116 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// serve runs srv on a loopback listener until the test ends and returns its
+// base URL.
+func serve(t *testing.T, srv *server.Server) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, returnsWithin(t, done))
+	})
+
+	return "http://" + ln.Addr().String()
+}
+
+func get(t *testing.T, client *http.Client, url string, header http.Header) int {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	// Read to the end, or the client will not reuse the connection.
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	return resp.StatusCode
+}
+
+func TestServer_ConnStats(t *testing.T) {
+	srv, err := server.New(server.Config{Storage: storagemem.New()})
+	require.NoError(t, err)
+
+	url := serve(t, srv)
+	client := &http.Client{Transport: &http.Transport{}}
+
+	require.Equal(t, http.StatusOK, get(t, client, url+"/health", nil))
+
+	// The request is done; the kept-alive connection waits for the next.
+	require.Eventually(t, func() bool {
+		return srv.ConnStats() == server.ConnStats{Idle: 1, Accepted: 1}
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Reused, not a new connection.
+	require.Equal(t, http.StatusOK, get(t, client, url+"/health", nil))
+	require.Eventually(t, func() bool {
+		return srv.ConnStats() == server.ConnStats{Idle: 1, Accepted: 1}
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// The client may not have pooled the connection yet: keep closing idle
+	// ones until the server sees it go.
+	require.Eventually(t, func() bool {
+		client.CloseIdleConnections()
+		return srv.ConnStats() == server.ConnStats{Accepted: 1, Closed: 1}
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Zero(t, srv.ConnStats().Open())
+}
+
+func TestServer_DisableKeepAlives(t *testing.T) {
+	srv, err := server.New(server.Config{Storage: storagemem.New(), DisableKeepAlives: true})
+	require.NoError(t, err)
+
+	url := serve(t, srv)
+	client := &http.Client{Transport: &http.Transport{}}
+
+	for range 2 {
+		require.Equal(t, http.StatusOK, get(t, client, url+"/health", nil))
+	}
+
+	// Each request had a connection of its own, closed after it.
+	require.Eventually(t, func() bool {
+		return srv.ConnStats() == server.ConnStats{Accepted: 2, Closed: 2}
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestServer_MaxHeaderBytes(t *testing.T) {
+	srv, err := server.New(server.Config{Storage: storagemem.New(), MaxHeaderBytes: 1 << 10})
+	require.NoError(t, err)
+
+	url := serve(t, srv)
+	client := &http.Client{Transport: &http.Transport{}}
+	big := http.Header{"X-Padding": {strings.Repeat("x", 64<<10)}}
+
+	require.Equal(t, http.StatusOK, get(t, client, url+"/health", nil))
+	require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, get(t, client, url+"/health", big))
+}
@@ -0,0 +1,20 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+func TestWithMaintenance_Nil(t *testing.T) {
+	h := server.NewHandler(storagemem.New(), server.WithMaintenance(nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/bucket-a", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
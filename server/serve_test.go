@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs/server"
+	"github.com/go-faster/fs/storagemem"
+)
+
+// returnsWithin waits for done, failing the test if it takes longer than a
+// generous bound: the point is that it returns at all.
+func returnsWithin(t *testing.T, done <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not return")
+		return nil
+	}
+}
+
+func TestServer_ListenAndServe_AddressInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = taken.Close() }()
+
+	srv, err := server.New(server.Config{Storage: storagemem.New(), Addr: taken.Addr().String()})
+	require.NoError(t, err)
+
+	// Nothing cancels this context: a bind failure alone must end the call.
+	done := make(chan error, 1)
+	go func() { done <- srv.ListenAndServe(context.Background()) }()
+
+	err = returnsWithin(t, done)
+	require.ErrorContains(t, err, "listen")
+}
+
+func TestServer_Serve_ExternalShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := server.New(server.Config{Storage: storagemem.New()})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(context.Background(), ln) }()
+
+	// Wait until the server accepts connections, then stop it without
+	// canceling the serving context.
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			return false
+		}
+
+		_ = conn.Close()
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, srv.Shutdown(t.Context()))
+	require.NoError(t, returnsWithin(t, done))
+}
+
+func TestServer_Serve_MaxConnections(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := server.New(server.Config{Storage: storagemem.New(), MaxConnections: 1})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	get := func(conn net.Conn) error {
+		if _, err := conn.Write([]byte("GET /health HTTP/1.1\r\nHost: x\r\n\r\n")); err != nil {
+			return err
+		}
+
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	first, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, get(first))
+
+	// The second connection is not served while the first stays open.
+	second, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	defer func() { _ = second.Close() }()
+
+	served := make(chan error, 1)
+
+	go func() { served <- get(second) }()
+
+	select {
+	case err := <-served:
+		t.Fatalf("second connection served over the limit: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Close())
+	require.NoError(t, returnsWithin(t, served))
+
+	cancel()
+	require.NoError(t, returnsWithin(t, done))
+}
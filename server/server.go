@@ -16,17 +16,26 @@ import (
 	"crypto/tls"
 	"net"
 	"net/http"
+	"net/netip"
 	"sync/atomic"
 	"time"
 
 	"github.com/go-faster/errors"
+	"golang.org/x/net/netutil"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 
 	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/auth"
 	"github.com/go-faster/fs/cors"
+	"github.com/go-faster/fs/defaults"
+	"github.com/go-faster/fs/fallback"
+	"github.com/go-faster/fs/ingest"
 	"github.com/go-faster/fs/internal/core/handler"
 	"github.com/go-faster/fs/internal/core/service"
+	"github.com/go-faster/fs/notify"
+	"github.com/go-faster/fs/policy"
+	"github.com/go-faster/fs/replicate"
 )
 
 // Default server configuration values.
@@ -43,7 +52,8 @@ const (
 type HandlerOption func(*handlerOptions)
 
 type handlerOptions struct {
-	opts []handler.Option
+	opts    []handler.Option
+	service []service.Option
 }
 
 // WithAuth enables SigV4 authentication and grant-based authorization on the
@@ -54,6 +64,21 @@ func WithAuth(store *auth.Store) HandlerOption {
 	}
 }
 
+// Authorizer decides whether principal (the access key, empty when
+// anonymous) may perform op on bucket and key; an error denies the request
+// with AccessDenied.
+type Authorizer = handler.Authorizer
+
+// WithAuthorizer consults a after authentication for every request, so an
+// embedder can enforce its own policy (per-user buckets, read-only
+// principals) without the server shipping a policy engine. It composes with
+// WithAuth: both must allow a request.
+func WithAuthorizer(a Authorizer) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithAuthorizer(a))
+	}
+}
+
 // WithCORS enables per-bucket CORS (OPTIONS preflight + response headers).
 func WithCORS(cfg cors.Config) HandlerOption {
 	return func(o *handlerOptions) {
@@ -61,6 +86,293 @@ func WithCORS(cfg cors.Config) HandlerOption {
 	}
 }
 
+// WithOwner sets the owner identity (canonical ID and display name) reported
+// in bucket listings. Without it a fixed default owner is reported.
+func WithOwner(id, displayName string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithOwner(id, displayName))
+	}
+}
+
+// WithRateLimit throttles each client IP to perIP requests per second with the
+// given burst, answering excess requests with 503 SlowDown and Retry-After.
+// The limiter table is bounded; the least recently seen clients are evicted.
+func WithRateLimit(perIP rate.Limit, burst int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithRateLimit(perIP, burst))
+	}
+}
+
+// WithTrustedProxies lists proxy networks whose X-Forwarded-For header is used
+// to attribute requests to client IPs for WithRateLimit.
+func WithTrustedProxies(prefixes ...netip.Prefix) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithTrustedProxies(prefixes...))
+	}
+}
+
+// WithMaxKeyLength sets the length limit in bytes for keys of new objects
+// (default 1024, the S3 limit). Writes with longer keys are rejected with
+// KeyTooLongError; existing objects stay readable up to the S3 limit.
+func WithMaxKeyLength(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.service = append(o.service, service.WithMaxKeyLength(n))
+	}
+}
+
+// WithPrefixPolicies enforces the per-prefix policies in r (read-only
+// prefixes, size quotas, required metadata) on object writes. Policies set on
+// r later apply from the next request.
+func WithPrefixPolicies(r *policy.Registry) HandlerOption {
+	return func(o *handlerOptions) {
+		o.service = append(o.service, service.WithPrefixPolicies(r))
+	}
+}
+
+// WithBucketDefaults merges the per-bucket default tags and metadata in r
+// into every uploaded object (see defaults.Registry.SetBucketDefaults);
+// values the upload sets itself win. Defaults set on r later apply from the
+// next upload.
+func WithBucketDefaults(r *defaults.Registry) HandlerOption {
+	return func(o *handlerOptions) {
+		o.service = append(o.service, service.WithBucketDefaults(r))
+	}
+}
+
+// WithNotFoundKeys serves the per-bucket fallback objects in r in place of
+// missing keys (see fallback.Registry.SetBucketNotFoundKey). Fallbacks set on
+// r later apply from the next request.
+func WithNotFoundKeys(r *fallback.Registry) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithNotFoundKeys(r))
+	}
+}
+
+// Maintenance is a maintenance-mode switch for WithMaintenance. The zero value
+// is off; it is safe for concurrent use.
+type Maintenance struct {
+	on atomic.Bool
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Maintenance) Enabled() bool { return m.on.Load() }
+
+// SetEnabled turns maintenance mode on or off.
+func (m *Maintenance) SetEnabled(on bool) { m.on.Store(on) }
+
+// Toggle flips maintenance mode and returns the new state.
+func (m *Maintenance) Toggle() bool {
+	for {
+		was := m.on.Load()
+		if m.on.CompareAndSwap(was, !was) {
+			return !was
+		}
+	}
+}
+
+// WithMaintenance refuses writes (PUT, POST, DELETE) with 503
+// ServiceUnavailable and Retry-After while m is enabled, keeping reads and
+// listings available, e.g. during a backup. Flip m at runtime with
+// SetEnabled; with WithAuth an Admin key on "*" can also PUT or DELETE
+// /?maintenance. A nil m is ignored.
+func WithMaintenance(m *Maintenance) HandlerOption {
+	return func(o *handlerOptions) {
+		// A nil *Maintenance would become a non-nil handler.MaintenanceSwitch.
+		if m == nil {
+			return
+		}
+
+		o.opts = append(o.opts, handler.WithMaintenance(m))
+	}
+}
+
+// WithReset enables the admin endpoint that wipes the store, for test and
+// development servers: with WithAuth an Admin key on "*" can DELETE /?all to
+// delete every bucket with its objects and uploads, or GET /?all for a dry
+// run; both answer with an XML summary. Never enable it in production.
+func WithReset() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithReset())
+	}
+}
+
+// WithGzipStatic serves key.gz, when it exists, in place of key to clients
+// sending Accept-Encoding: gzip, with Content-Encoding: gzip and the plain
+// object's Content-Type, like nginx's gzip_static.
+func WithGzipStatic() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithGzipStatic())
+	}
+}
+
+// WithResumableDownloads enables GET /{bucket}/{key}?download-id, a
+// resumable download for clients without Range support: a repeat of an
+// interrupted download with its ID and the bytes received (in
+// X-Fs-Download-Offset) sends the rest. Sessions not resumed within ttl are
+// dropped.
+func WithResumableDownloads(ttl time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithResumableDownloads(ttl))
+	}
+}
+
+// ErrorVerbosity is how much of an internal error an error response carries.
+type ErrorVerbosity = handler.ErrorVerbosity
+
+const (
+	// ErrorsPublic sends only the S3 code and its generic message (default).
+	ErrorsPublic = handler.ErrorsPublic
+	// ErrorsDebug appends the internal error, file paths included.
+	ErrorsDebug = handler.ErrorsDebug
+)
+
+// WithErrorVerbosity sets how much of an internal error reaches clients. Keep
+// the ErrorsPublic default in production: ErrorsDebug exposes server paths.
+func WithErrorVerbosity(v ErrorVerbosity) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithErrorVerbosity(v))
+	}
+}
+
+// WithDefaultContentType sets the Content-Type served for objects uploaded
+// without one, in place of application/octet-stream. An empty contentType is
+// ignored.
+func WithDefaultContentType(contentType string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithDefaultContentType(contentType))
+	}
+}
+
+// WithRegion sets the region reported by GetBucketLocation for buckets
+// created without a LocationConstraint (default us-east-1).
+func WithRegion(region string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithRegion(region))
+	}
+}
+
+// WithStrictRegion refuses bucket creation with a LocationConstraint other
+// than the server's region (IllegalLocationConstraintException).
+func WithStrictRegion() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithStrictRegion())
+	}
+}
+
+// WithListingCompression gzip-compresses listing responses for clients
+// sending Accept-Encoding: gzip. Object bodies are not compressed.
+func WithListingCompression() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithListingCompression())
+	}
+}
+
+// WithNoOverwriteRename stores any write to an existing key (PUT, copy, POST,
+// URL ingest, multipart completion) under a derived key ("key (1)",
+// "key (2)", ...) instead of overwriting it, reporting the key used in the
+// X-Fs-Key response header.
+func WithNoOverwriteRename() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithNoOverwriteRename())
+	}
+}
+
+// Tracer starts a span around each request and returns the context to serve
+// it with and a func ending the span: with nil on success, or a
+// *ResponseError for a 4xx/5xx response. Implement it to bridge to
+// OpenTelemetry or another tracing system.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, func(error))
+}
+
+// ResponseError is the error a Tracer span ends with when the request was
+// answered with an error status.
+type ResponseError = handler.ResponseError
+
+// WithTracer wraps every request in a span from t named after its S3
+// operation ("GetObject", "PutObject", ...). Without it no spans are started.
+func WithTracer(t Tracer) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithTracer(t))
+	}
+}
+
+// WithURLIngest lets a PUT with an x-fs-source-url header and no body store
+// the object the server fetches from that URL, under the host lists and size
+// limit set by opts (see the ingest package). Only public hosts are fetched
+// unless ingest.WithAllowedHosts names others.
+func WithURLIngest(opts ...ingest.Option) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithURLIngest(opts...))
+	}
+}
+
+// WithMaxMetadataSize sets the limit on x-amz-meta-* user metadata per object,
+// counted as the bytes of names and values (default 2 KB, the S3 limit);
+// n <= 0 removes it. Larger metadata is rejected with MetadataTooLarge.
+func WithMaxMetadataSize(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.service = append(o.service, service.WithMaxMetadataSize(n))
+	}
+}
+
+// WithMaxInFlight caps the requests served at once at total, and reads (GET,
+// HEAD, OPTIONS) and writes (every other method) separately at reads and
+// writes; requests beyond a cap get 503 SlowDown with Retry-After. A value
+// <= 0 means no limit of that kind.
+func WithMaxInFlight(total, reads, writes int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts,
+			handler.WithMaxInFlight(total),
+			handler.WithMaxInFlightReads(reads),
+			handler.WithMaxInFlightWrites(writes),
+		)
+	}
+}
+
+// WithMaxConcurrentUploads caps the object uploads (PUT, UploadPart, copies)
+// in flight at n; the rest get 503 SlowDown with Retry-After. GETs are not
+// affected. n <= 0 means no limit.
+func WithMaxConcurrentUploads(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithMaxConcurrentUploads(n))
+	}
+}
+
+// WithMaxListKeys lets ListObjects return up to n keys per page when the client
+// asks for that many with max-keys; n <= 0 removes the cap. The default is the
+// S3 limit of 1000.
+func WithMaxListKeys(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithMaxListKeys(n))
+	}
+}
+
+// WithPrefixNormalization trims one leading slash from listing prefixes, for
+// clients that send "/dir/" copied from a URL. Off by default (exact S3
+// prefix matching).
+func WithPrefixNormalization() HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithPrefixNormalization())
+	}
+}
+
+// WithEventSink reports successful object creations and removals to sink as
+// S3 event notifications. The sink runs on the request path; use a
+// notify.Queue (e.g. around notify.Webhook) for asynchronous delivery.
+func WithEventSink(sink notify.Sink) HandlerOption {
+	return func(o *handlerOptions) {
+		o.opts = append(o.opts, handler.WithEventSink(sink))
+	}
+}
+
+// WithReplication replays object creations and removals on the peers of r,
+// in the background (see package replicate). It composes with WithEventSink.
+// Close r after the server has stopped so queued operations drain.
+func WithReplication(r *replicate.Replicator) HandlerOption {
+	return WithEventSink(r.Send)
+}
+
 // NewHandler returns the S3-compatible http.Handler for a storage backend,
 // wiring the validation layer and the request router. Mount it into your own
 // http.Server or mux to embed the S3 API. Options enable authentication and
@@ -71,7 +383,7 @@ func NewHandler(store fs.Storage, opts ...HandlerOption) http.Handler {
 		opt(&o)
 	}
 
-	return handler.New(service.New(store), o.opts...)
+	return handler.New(service.New(store, o.service...), o.opts...)
 }
 
 // Config configures a Server.
@@ -88,6 +400,20 @@ type Config struct {
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
 
+	// MaxHeaderBytes limits the size of request headers. Zero means
+	// http.DefaultMaxHeaderBytes (1 MB).
+	MaxHeaderBytes int
+
+	// DisableKeepAlives closes every connection after one request. Keep-alives
+	// are on by default; turning them off trades connection reuse for a
+	// connection per request.
+	DisableKeepAlives bool
+
+	// MaxConnections caps the client connections open at once in
+	// ListenAndServe / Serve: further ones wait in the listen backlog until
+	// one closes. Zero means no limit.
+	MaxConnections int
+
 	// HealthPath is the path serving a plaintext "OK" liveness check. Defaults to
 	// DefaultHealthPath ("/health"). Set to "-" to disable the health endpoint.
 	HealthPath string
@@ -118,6 +444,11 @@ type Config struct {
 	// TLS, if set, serves HTTPS with hot-reloadable certificates.
 	TLS *TLSConfig
 
+	// HandlerOptions are applied to the S3 handler after the options derived
+	// from Auth and CORS, for handler behavior without a dedicated Config field
+	// (e.g. WithOwner).
+	HandlerOptions []HandlerOption
+
 	// WrapHandler, if set, wraps the composed handler (health endpoint + S3
 	// router) before it is served. This is the injection point for
 	// observability or middleware, e.g. otelhttp.NewHandler or request logging.
@@ -164,6 +495,7 @@ type Server struct {
 	handler http.Handler
 	http    *http.Server
 	certs   *certReloader
+	conns   *connTracker
 }
 
 // certReloader loads a TLS keypair from disk and caches it behind an atomic
@@ -209,14 +541,20 @@ func New(cfg Config) (*Server, error) {
 
 	cfg.setDefaults()
 
-	s := &Server{cfg: cfg}
+	s := &Server{cfg: cfg, conns: newConnTracker()}
 	s.handler = s.buildHandler()
 	s.http = &http.Server{
-		Addr:         cfg.Addr,
-		Handler:      s.handler,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		IdleTimeout:  cfg.IdleTimeout,
+		Addr:           cfg.Addr,
+		Handler:        s.handler,
+		ReadTimeout:    cfg.ReadTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		IdleTimeout:    cfg.IdleTimeout,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+		ConnState:      s.conns.track,
+	}
+
+	if cfg.DisableKeepAlives {
+		s.http.SetKeepAlivesEnabled(false)
 	}
 
 	if cfg.TLS != nil {
@@ -245,6 +583,8 @@ func (s *Server) buildHandler() http.Handler {
 		opts = append(opts, WithCORS(s.cfg.CORS))
 	}
 
+	opts = append(opts, s.cfg.HandlerOptions...)
+
 	mux := http.NewServeMux()
 	mux.Handle("/", NewHandler(s.cfg.Storage, opts...))
 
@@ -292,8 +632,8 @@ func (s *Server) Handler() http.Handler {
 
 // HTTPServer returns the underlying *http.Server, allowing callers to set
 // advanced fields (ConnContext, BaseContext, ErrorLog, TLSConfig, ...) before
-// calling ListenAndServe or Serve. The Handler, Addr and timeout fields are
-// managed by New and should not be replaced.
+// calling ListenAndServe or Serve. The Handler, Addr, timeout and ConnState
+// fields are managed by New and should not be replaced.
 func (s *Server) HTTPServer() *http.Server {
 	return s.http
 }
@@ -333,14 +673,28 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 }
 
 // Serve pre-creates configured buckets and serves on ln until ctx is canceled,
-// then performs a graceful shutdown. It returns nil on a clean shutdown.
+// then performs a graceful shutdown. It returns nil on a clean shutdown, which
+// includes Shutdown being called directly.
 func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
 	if err := s.ensureBuckets(ctx); err != nil {
+		_ = ln.Close()
 		return err
 	}
 
+	if s.cfg.MaxConnections > 0 {
+		ln = netutil.LimitListener(ln, s.cfg.MaxConnections)
+	}
+
+	// Serving can end without an error and without ctx being canceled (an
+	// explicit Shutdown returns ErrServerClosed). Cancel on any exit so the
+	// shutdown goroutine never waits for a ctx that will not end.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	g, gCtx := errgroup.WithContext(ctx)
 	g.Go(func() error {
+		defer cancel()
+
 		serve := s.http.Serve
 		if s.certs != nil {
 			// Certificates come from the reloader's GetCertificate callback.
@@ -364,6 +718,12 @@ func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
 	return g.Wait()
 }
 
+// ConnStats returns the current connection counts, for metrics or a debug
+// endpoint.
+func (s *Server) ConnStats() ConnStats {
+	return s.conns.snapshot()
+}
+
 // Shutdown gracefully shuts down the underlying HTTP server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	return s.http.Shutdown(ctx)
@@ -2,6 +2,8 @@ package fs
 
 import (
 	"context"
+	"io"
+	"iter"
 )
 
 // Storage defines the interface for S3-compatible storage operations.
@@ -25,6 +27,14 @@ type Storage interface {
 	// DeleteObjectTagging removes the object's tag set.
 	DeleteObjectTagging(ctx context.Context, bucket, key string) error
 
+	// PutObjectLegalHold places (on) or lifts the object's legal hold. While
+	// it is on, overwriting or deleting the object fails with
+	// ErrAccessDenied; tags and ACL may still change.
+	PutObjectLegalHold(ctx context.Context, bucket, key string, on bool) error
+	// GetObjectLegalHold reports whether the object is under legal hold (off
+	// by default).
+	GetObjectLegalHold(ctx context.Context, bucket, key string) (bool, error)
+
 	// SetBucketACL records the bucket's canned ACL.
 	SetBucketACL(ctx context.Context, bucket string, acl ACL) error
 	// BucketACL returns the bucket's canned ACL (ACLPrivate default);
@@ -34,6 +44,13 @@ type Storage interface {
 	// ErrBucketNotFound/ErrObjectNotFound when absent.
 	ObjectACL(ctx context.Context, bucket, key string) (ACL, error)
 
+	// SetBucketRegion records the region the bucket was created in (its
+	// LocationConstraint).
+	SetBucketRegion(ctx context.Context, bucket, region string) error
+	// BucketRegion returns the region recorded by SetBucketRegion, or "" when
+	// none was; ErrBucketNotFound when the bucket is absent.
+	BucketRegion(ctx context.Context, bucket string) (string, error)
+
 	CreateMultipartUpload(ctx context.Context, req *CreateMultipartUploadRequest) (*MultipartUpload, error)
 	UploadPart(ctx context.Context, req *UploadPartRequest) (*Part, error)
 	// ListParts returns the parts uploaded so far for an in-progress multipart
@@ -45,3 +62,21 @@ type Storage interface {
 	CompleteMultipartUpload(ctx context.Context, req *CompleteMultipartUploadRequest) (*CompleteMultipartUploadResponse, error)
 	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
 }
+
+// ObjectWalker is implemented by a Storage that can stream a listing:
+// WalkObjects yields the objects of bucket under prefix as its walk finds
+// them, in no particular order, without building the whole listing. A missing
+// bucket is ErrBucketNotFound. The listing helpers of this package use it
+// when the storage has it (see WalkObjects).
+type ObjectWalker interface {
+	WalkObjects(ctx context.Context, bucket, prefix string) iter.Seq2[Object, error]
+}
+
+// RangeWriter is implemented by a Storage that can overwrite part of an
+// object without rewriting the rest of it. PutObjectRange has the contract of
+// the package function of the same name, which uses it when the storage has
+// it; a RangeWriter that cannot patch a given object falls back on
+// RewriteObjectRange.
+type RangeWriter interface {
+	PutObjectRange(ctx context.Context, bucket, key string, offset int64, r io.Reader, size int64) (*PutObjectResponse, error)
+}
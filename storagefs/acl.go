@@ -20,6 +20,7 @@ const bucketMetaFile = "bucket.json"
 type bucketMeta struct {
 	Version int    `json:"version"`
 	ACL     fs.ACL `json:"acl,omitempty"`
+	Region  string `json:"region,omitempty"`
 }
 
 func (s *Storage) bucketMetaPath(bucket string) string {
@@ -60,7 +61,7 @@ func (s *Storage) writeBucketMeta(bucket string, m bucketMeta) error {
 }
 
 func (s *Storage) bucketExists(bucket string) bool {
-	_, err := os.Stat(filepath.Join(s.root, bucket))
+	_, err := s.bucketDir(bucket)
 
 	return err == nil
 }
@@ -87,6 +88,28 @@ func (s *Storage) BucketACL(_ context.Context, bucket string) (fs.ACL, error) {
 	return normalizeACL(s.readBucketMeta(bucket).ACL), nil
 }
 
+func (s *Storage) SetBucketRegion(_ context.Context, bucket, region string) error {
+	if !s.bucketExists(bucket) {
+		return fs.ErrBucketNotFound
+	}
+
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+
+	m := s.readBucketMeta(bucket)
+	m.Region = region
+
+	return s.writeBucketMeta(bucket, m)
+}
+
+func (s *Storage) BucketRegion(_ context.Context, bucket string) (string, error) {
+	if !s.bucketExists(bucket) {
+		return "", fs.ErrBucketNotFound
+	}
+
+	return s.readBucketMeta(bucket).Region, nil
+}
+
 func (s *Storage) ObjectACL(_ context.Context, bucket, key string) (fs.ACL, error) {
 	if err := s.statObject(bucket, key); err != nil {
 		return fs.ACLPrivate, err
@@ -2,26 +2,53 @@ package storagefs
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/go-faster/errors"
+
 	"github.com/go-faster/fs"
 )
 
-func (s *Storage) BucketExists(_ context.Context, bucket string) (bool, error) {
+// ErrNotBucket reports a bucket name taken under the root by something other
+// than a directory, such as a regular file created out of band. Only
+// directories directly under the root are buckets: such a name is left out of
+// ListBuckets, cannot be created, and every other operation on it fails with
+// an error matching both ErrNotBucket and fs.ErrBucketNotFound.
+var ErrNotBucket = errors.New("not a bucket directory")
+
+// bucketDir returns the directory of bucket, or fs.ErrBucketNotFound when
+// there is none.
+func (s *Storage) bucketDir(bucket string) (string, error) {
 	bucketPath := filepath.Join(s.root, bucket)
 
 	info, err := os.Stat(bucketPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
+	if os.IsNotExist(err) {
+		return "", fs.ErrBucketNotFound
+	}
 
-		return false, err
+	if err != nil {
+		return "", errors.Wrap(err, "stat bucket")
 	}
 
 	if !info.IsDir() {
-		return false, fs.ErrBucketNotFound
+		return "", fmt.Errorf("bucket %q: %w: %w", bucket, ErrNotBucket, fs.ErrBucketNotFound)
+	}
+
+	return bucketPath, nil
+}
+
+// BucketExists reports whether bucket is a directory under the root. A file
+// of that name is reported as an error matching ErrNotBucket.
+func (s *Storage) BucketExists(_ context.Context, bucket string) (bool, error) {
+	_, err := s.bucketDir(bucket)
+	if errors.Is(err, fs.ErrBucketNotFound) && !errors.Is(err, ErrNotBucket) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -1,6 +1,7 @@
 package storagefs_test
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/go-faster/fs"
 	"github.com/go-faster/fs/storagefs"
 )
 
@@ -29,3 +31,60 @@ func TestStorage_BucketExists_File(t *testing.T) {
 	require.Error(t, err)
 	require.False(t, exists)
 }
+
+// TestStorage_FileAtBucketPath plants a regular file where a bucket would be
+// and checks that it is never treated as one, nor touched.
+func TestStorage_FileAtBucketPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	storage, err := storagefs.New(root)
+	require.NoError(t, err)
+
+	filePath := filepath.Join(root, "taken")
+	require.NoError(t, os.WriteFile(filePath, []byte("content"), 0o600))
+
+	buckets, err := storage.ListBuckets(ctx)
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+
+	exists, err := storage.BucketExists(ctx, "taken")
+	require.ErrorIs(t, err, storagefs.ErrNotBucket)
+	require.False(t, exists)
+
+	err = storage.CreateBucket(ctx, "taken")
+	require.ErrorIs(t, err, storagefs.ErrNotBucket)
+	require.NotErrorIs(t, err, fs.ErrBucketAlreadyExists)
+
+	notBucket := func(t *testing.T, err error) {
+		t.Helper()
+		require.ErrorIs(t, err, storagefs.ErrNotBucket)
+		require.ErrorIs(t, err, fs.ErrBucketNotFound)
+	}
+
+	_, err = storage.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "taken",
+		Key:    "obj",
+		Reader: bytes.NewReader([]byte("data")),
+		Size:   4,
+	})
+	notBucket(t, err)
+
+	_, err = storage.GetObject(ctx, "taken", "obj")
+	notBucket(t, err)
+
+	_, err = storage.ListObjects(ctx, "taken", "")
+	notBucket(t, err)
+
+	notBucket(t, storage.DeleteObject(ctx, "taken", "obj"))
+
+	_, err = storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "taken", Key: "obj"})
+	notBucket(t, err)
+
+	notBucket(t, storage.DeleteBucket(ctx, "taken"))
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(data))
+}
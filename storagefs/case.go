@@ -0,0 +1,64 @@
+package storagefs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithCaseSensitiveKeys stores keys so that no two of them share a file even
+// on a case-insensitive (or Unicode-normalizing) filesystem, such as the
+// macOS and Windows defaults, where "File.txt" and "file.txt" would otherwise
+// overwrite each other. Under KeyPassthrough or KeyPercent it selects
+// KeyPercent with uppercase ASCII letters and all non-ASCII bytes
+// percent-escaped as well, so file names hold only lowercase letters and
+// still mirror the key structure; KeyHashed needs no change. Like the key
+// encoding, it is not recorded in the data directory: open a root with the
+// setting it was written with.
+func WithCaseSensitiveKeys() Option {
+	return func(s *Storage) { s.caseSensitiveKeys = true }
+}
+
+// CaseInsensitive reports whether New found the root's filesystem to fold
+// case, so that, without WithCaseSensitiveKeys or KeyHashed, keys differing
+// only in case share one object.
+func (s *Storage) CaseInsensitive() bool { return s.caseInsensitive }
+
+// KeysFoldCase reports whether keys differing only in case collide on this
+// storage: the filesystem folds case and the key encoding does not escape it.
+func (s *Storage) KeysFoldCase() bool {
+	return s.caseInsensitive && !s.caseSensitiveKeys && s.keyEncoding != KeyHashed
+}
+
+// detectCaseInsensitive reports whether dir's filesystem folds case, by
+// creating a file with an uppercase name and looking it up in lowercase. A
+// failed probe reports false.
+func detectCaseInsensitive(dir string) bool {
+	f, err := os.CreateTemp(dir, "CASE-PROBE-")
+	if err != nil {
+		return false
+	}
+
+	name := f.Name()
+	_ = f.Close()
+
+	defer func() { _ = os.Remove(name) }()
+
+	upper, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+
+	lower, err := os.Stat(filepath.Join(dir, strings.ToLower(filepath.Base(name))))
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(upper, lower)
+}
+
+// escapesCase reports whether encodeSegment escapes c because file names
+// holding it may compare equal to others on case-folding filesystems.
+func escapesCase(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,90 @@
+package storagefs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseSensitiveKeys(t *testing.T) {
+	keys := append([]string{
+		"A", "a",
+		"Dir/File.txt", "dir/file.txt", "DIR/FILE.TXT",
+		"café", "CAFÉ", "café",
+	}, adversarialKeys...)
+
+	for _, encoding := range []KeyEncoding{KeyPassthrough, KeyPercent, KeyHashed} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			ctx := t.Context()
+			root := t.TempDir()
+
+			s, err := New(root, WithKeyEncoding(encoding), WithCaseSensitiveKeys())
+			require.NoError(t, err)
+			require.False(t, s.KeysFoldCase())
+			require.NoError(t, s.CreateBucket(ctx, "b"))
+
+			for _, key := range keys {
+				putContent(t, s, "b", key, []byte("body of "+key))
+			}
+
+			for _, key := range keys {
+				require.Equal(t, []byte("body of "+key), readContent(t, s, "b", key), "key %q", key)
+			}
+
+			objects, err := s.ListObjects(ctx, "b", "")
+			require.NoError(t, err)
+			require.Len(t, objects, len(keys))
+
+			// No two object files would meet on a filesystem that folds case.
+			folded := make(map[string]string)
+
+			require.NoError(t, filepath.WalkDir(filepath.Join(root, "b"), func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+
+				lower := strings.ToLower(path)
+				require.NotContains(t, folded, lower, "%s and %s", path, folded[lower])
+				folded[lower] = path
+
+				return nil
+			}))
+		})
+	}
+}
+
+func TestCaseSensitiveKeysPaths(t *testing.T) {
+	s, err := New(t.TempDir(), WithCaseSensitiveKeys())
+	require.NoError(t, err)
+	require.Equal(t, KeyPercent, s.keyEncoding)
+
+	for key, want := range map[string]string{
+		"plain/file.txt": filepath.Join("plain", "file.txt"),
+		"Dir/File.txt":   filepath.Join("%44ir", "%46ile.txt"),
+		"CON":            "%43%4F%4E",
+		"caf\u00e9":      "caf%C3%A9",
+	} {
+		require.Equal(t, want, s.keyPath(key), "key %q", key)
+
+		got, ok := s.pathKey(filepath.Join("root", want), want)
+		require.True(t, ok)
+		require.Equal(t, key, got)
+	}
+}
+
+func TestDetectCaseInsensitive(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "X"), nil, 0o600))
+
+	_, err := os.Stat(filepath.Join(dir, "x"))
+	require.Equal(t, err == nil, detectCaseInsensitive(dir))
+
+	// The probe leaves nothing behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -20,3 +20,77 @@ func TestStorageConformance(t *testing.T) {
 		return storage
 	})
 }
+
+func TestStorageConformanceDedup(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t testing.TB) fs.Storage {
+		storage, err := storagefs.New(t.TempDir(), storagefs.WithDedup())
+		require.NoError(t, err)
+
+		return storage
+	})
+}
+
+func TestStorageConformanceKeyEncoding(t *testing.T) {
+	t.Parallel()
+
+	for _, e := range []storagefs.KeyEncoding{storagefs.KeyPercent, storagefs.KeyHashed} {
+		t.Run(e.String(), func(t *testing.T) {
+			t.Parallel()
+
+			storagetest.Run(t, func(t testing.TB) fs.Storage {
+				storage, err := storagefs.New(t.TempDir(), storagefs.WithKeyEncoding(e))
+				require.NoError(t, err)
+
+				return storage
+			})
+		})
+	}
+}
+
+func TestStorageConformanceXattr(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t testing.TB) fs.Storage {
+		storage, err := storagefs.New(t.TempDir(), storagefs.WithMetadataStore(storagefs.MetadataXattr))
+		require.NoError(t, err)
+
+		return storage
+	})
+}
+
+func TestStorageConformanceEncrypted(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 32)
+
+	storagetest.Run(t, func(t testing.TB) fs.Storage {
+		storage, err := storagefs.New(t.TempDir(), storagefs.WithEncryptionKey(key))
+		require.NoError(t, err)
+
+		return storage
+	})
+}
+
+func TestStorageConformanceExistenceFilter(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t testing.TB) fs.Storage {
+		storage, err := storagefs.New(t.TempDir(), storagefs.WithExistenceFilter(0))
+		require.NoError(t, err)
+
+		return storage
+	})
+}
+
+func TestStorageConformanceReadAhead(t *testing.T) {
+	t.Parallel()
+
+	storagetest.Run(t, func(t testing.TB) fs.Storage {
+		storage, err := storagefs.New(t.TempDir(), storagefs.WithReadAhead(), storagefs.WithVerifyOnRead())
+		require.NoError(t, err)
+
+		return storage
+	})
+}
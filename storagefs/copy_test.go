@@ -0,0 +1,77 @@
+package storagefs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// cancelAfter reads from r and cancels once n bytes have been delivered,
+// standing in for a client that goes away mid-copy.
+type cancelAfter struct {
+	r      io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+
+	c.n -= n
+	if c.n <= 0 {
+		c.cancel()
+	}
+
+	return n, err
+}
+
+// TestCopyCanceledMidStream copies a large object the way the handler's
+// CopyObject does (GetObject piped into PutObject) and cancels partway
+// through: the copy must stop early and leave nothing under the destination.
+func TestCopyCanceledMidStream(t *testing.T) {
+	root := t.TempDir()
+
+	s, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	body := bytes.Repeat([]byte("0123456789abcdef"), 1<<18) // 4 MiB
+	putContent(t, s, "b", "src", body)
+
+	src, err := s.GetObject(t.Context(), "b", "src")
+	require.NoError(t, err)
+
+	defer func() { _ = src.Reader.Close() }()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	reader := &cancelAfter{r: src.Reader, n: 64 << 10, cancel: cancel}
+
+	_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "dst", Reader: reader, Size: src.Size,
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The copy stopped at the next chunk instead of draining the source.
+	rest, err := io.ReadAll(src.Reader)
+	require.NoError(t, err)
+	require.NotEmpty(t, rest)
+
+	_, err = s.GetObject(t.Context(), "b", "dst")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+	require.NoFileExists(t, filepath.Join(root, "b", "dst"))
+
+	staged, err := os.ReadDir(filepath.Join(root, stagingSubdir))
+	require.NoError(t, err)
+	require.Empty(t, staged)
+
+	require.Equal(t, body, readContent(t, s, "b", "src"))
+}
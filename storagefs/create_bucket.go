@@ -17,11 +17,22 @@ func (s *Storage) CreateBucket(ctx context.Context, bucket string) error {
 	bucketPath := filepath.Join(s.root, bucket)
 	if err := os.Mkdir(bucketPath, defaultDirPermissions); err != nil {
 		if os.IsExist(err) {
+			if info, serr := os.Stat(bucketPath); serr == nil && !info.IsDir() {
+				return errors.Wrapf(ErrNotBucket, "bucket %q: a file of that name is in the way", bucket)
+			}
+
 			return errors.Wrapf(fs.ErrBucketAlreadyExists, "bucket %q", bucket)
 		}
 
 		return fmt.Errorf("failed to create bucket: %w", err)
 	}
 
+	// A root cached for an earlier directory of this name is stale.
+	s.forgetBucketRoot(bucket)
+
+	if s.existence != nil {
+		s.existence.addBucket(bucket)
+	}
+
 	return nil
 }
@@ -0,0 +1,150 @@
+package storagefs
+
+import (
+	"encoding/hex"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+)
+
+// casSubdir is the content-addressed store used by WithDedup, laid out as
+// .cas/<sha256[:2]>/<sha256>. Each object whose body lives here is a hard link
+// to its entry, so the entry's link count minus one is the number of objects
+// referencing it. Like the other root-level dot-dirs it is never a bucket.
+const casSubdir = ".cas"
+
+// WithDedup stores object bodies once per distinct content. Each PUT hashes
+// the body with SHA-256 while streaming it; the first copy of a content is
+// moved into the content store and every object with that content becomes a
+// hard link to it, so identical objects share disk space. The body is freed
+// when the last object referencing it is deleted or overwritten.
+//
+// Dedup is transparent to GET, HEAD and listings: since linked objects share
+// one inode (and so one mtime), each object's LastModified is kept in its
+// sidecar instead. The root must be on a filesystem that supports hard links.
+// Bodies sealed with an SSE-C key are stored per object, outside the content
+// store: the same plaintext under two keys is two different ciphertexts.
+func WithDedup() Option {
+	return func(s *Storage) { s.dedup = true }
+}
+
+// contentPath returns the content-store location for a hex SHA-256 digest.
+func (s *Storage) contentPath(digest string) string {
+	return filepath.Join(s.root, casSubdir, digest[:2], digest)
+}
+
+// placeObject moves the finished body at tmp to objectPath. When digest is
+// non-empty the body is interned in the content store first and objectPath
+// becomes another link to the shared entry; tmp is consumed either way.
+func (s *Storage) placeObject(tmp, objectPath, digest string) error {
+	if digest == "" {
+		if err := os.Rename(tmp, objectPath); err != nil {
+			_ = os.Remove(tmp)
+			return errors.Wrap(err, "rename object")
+		}
+
+		return nil
+	}
+
+	content := s.contentPath(digest)
+
+	// Installing (or finding) the entry and linking to it must not interleave
+	// with a release of the same content, or the entry could be removed between
+	// the existence check and the link.
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	switch _, err := os.Stat(content); {
+	case err == nil:
+		// Already stored: this copy of the body is redundant.
+		_ = os.Remove(tmp)
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(content), defaultDirPermissions); err != nil {
+			_ = os.Remove(tmp)
+			return errors.Wrap(err, "create content directory")
+		}
+
+		if err := os.Rename(tmp, content); err != nil {
+			_ = os.Remove(tmp)
+			return errors.Wrap(err, "store content")
+		}
+
+		if err := s.syncDir(filepath.Dir(content)); err != nil {
+			return err
+		}
+	default:
+		_ = os.Remove(tmp)
+		return errors.Wrap(err, "stat content")
+	}
+
+	// A link cannot replace an existing file, so link under a staging name and
+	// rename that over the key: an overwrite stays atomic.
+	link, err := s.newObjectTemp()
+	if err != nil {
+		return err
+	}
+
+	_ = link.Close()
+	_ = os.Remove(link.Name())
+
+	if err := os.Link(content, link.Name()); err != nil {
+		return errors.Wrap(err, "link content")
+	}
+
+	if err := os.Rename(link.Name(), objectPath); err != nil {
+		_ = os.Remove(link.Name())
+		return errors.Wrap(err, "rename object")
+	}
+
+	return nil
+}
+
+// releaseContent drops the content-store entry for digest once no object links
+// to it any more. An empty digest (a body outside the store) is a no-op.
+func (s *Storage) releaseContent(digest string) {
+	if digest == "" {
+		return
+	}
+
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	s.releaseUnlinked(s.contentPath(digest))
+}
+
+// releaseUnlinked removes the content-store entry at path if it is its own
+// only link. The caller must hold dedupMu.
+func (s *Storage) releaseUnlinked(path string) {
+	if n, err := linkCount(path); err == nil && n <= 1 {
+		_ = os.Remove(path)
+		_ = os.Remove(filepath.Dir(path)) // Only succeeds once the shard is empty.
+	}
+}
+
+// removeOrphanContent sweeps content-store entries no object links to, left by
+// a crash between storing a body and linking the key, or between deleting a
+// key and releasing its body. It runs regardless of WithDedup, so a store
+// opened without dedup still reclaims bodies its deduplicated objects held.
+func (s *Storage) removeOrphanContent() {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	_ = filepath.WalkDir(filepath.Join(s.root, casSubdir), func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Best-effort sweep; a missing .cas is normal.
+		}
+
+		if !d.IsDir() {
+			s.releaseUnlinked(path)
+		}
+
+		return nil
+	})
+}
+
+// contentDigest renders a SHA-256 sum as the content-store key.
+func contentDigest(sum []byte) string {
+	return hex.EncodeToString(sum)
+}
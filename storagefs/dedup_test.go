@@ -0,0 +1,186 @@
+package storagefs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// contentEntries lists the files in the content store.
+func contentEntries(t *testing.T, root string) []string {
+	t.Helper()
+
+	entries, err := filepath.Glob(filepath.Join(root, casSubdir, "*", "*"))
+	require.NoError(t, err)
+
+	return entries
+}
+
+func TestDedupSharesIdenticalBodies(t *testing.T) {
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root, WithDedup())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	body := []byte("the same bytes twice")
+	putContent(t, s, "b", "one", body)
+	putContent(t, s, "b", "nested/two", body)
+	putContent(t, s, "b", "other", []byte("different"))
+
+	one, err := os.Stat(filepath.Join(root, "b", "one"))
+	require.NoError(t, err)
+	two, err := os.Stat(filepath.Join(root, "b", "nested", "two"))
+	require.NoError(t, err)
+	other, err := os.Stat(filepath.Join(root, "b", "other"))
+	require.NoError(t, err)
+
+	require.True(t, os.SameFile(one, two), "identical objects must share one body")
+	require.False(t, os.SameFile(one, other))
+	require.Len(t, contentEntries(t, root), 2)
+
+	// Deleting one reference keeps the shared body for the other.
+	require.NoError(t, s.DeleteObject(ctx, "b", "one"))
+	require.Equal(t, body, readContent(t, s, "b", "nested/two"))
+	require.Len(t, contentEntries(t, root), 2)
+
+	// The last reference frees it.
+	require.NoError(t, s.DeleteObject(ctx, "b", "nested/two"))
+	require.Len(t, contentEntries(t, root), 1)
+
+	// Overwriting releases the replaced body too.
+	putContent(t, s, "b", "other", []byte("replaced"))
+	require.Len(t, contentEntries(t, root), 1)
+	require.Equal(t, []byte("replaced"), readContent(t, s, "b", "other"))
+}
+
+func TestDedupSkipsCustomerKeys(t *testing.T) {
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root, WithDedup())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	body := []byte("the same bytes under two keys")
+
+	for key, ck := range map[string]*fs.CustomerKey{"one": testCustomerKey(1), "two": testCustomerKey(2)} {
+		_, err := s.PutObject(fs.WithCustomerKey(ctx, ck), &fs.PutObjectRequest{
+			Bucket: "b", Key: key, Reader: bytes.NewReader(body), Size: int64(len(body)),
+		})
+		require.NoError(t, err)
+	}
+
+	require.Empty(t, contentEntries(t, root), "SSE-C bodies stay out of the content store")
+
+	for key, ck := range map[string]*fs.CustomerKey{"one": testCustomerKey(1), "two": testCustomerKey(2)} {
+		resp, err := s.GetObject(fs.WithCustomerKey(ctx, ck), "b", key)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(resp.Reader)
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+		require.Equal(t, body, data, key)
+	}
+
+	require.NoError(t, s.DeleteObject(ctx, "b", "one"))
+	require.NoError(t, s.DeleteObject(ctx, "b", "two"))
+}
+
+func TestDedupKeepsPerObjectLastModified(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := New(t.TempDir(), WithDedup())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	putContent(t, s, "b", "new", []byte("x"))
+
+	first, err := s.GetObject(ctx, "b", "new")
+	require.NoError(t, err)
+	require.NoError(t, first.Reader.Close())
+
+	// A second link to the same body must not move the first object's time.
+	old := first.LastModified.Add(-24 * time.Hour)
+	_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "old", Reader: bytes.NewReader([]byte("x")), Size: 1, LastModified: old,
+	})
+	require.NoError(t, err)
+
+	objects, err := s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	for _, o := range objects {
+		switch o.Key {
+		case "new":
+			require.True(t, first.LastModified.Equal(o.LastModified))
+		case "old":
+			require.True(t, old.Equal(o.LastModified))
+		}
+	}
+}
+
+func TestDedupConcurrentIdenticalPuts(t *testing.T) {
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root, WithDedup())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	body := bytes.Repeat([]byte("race"), 1<<12)
+
+	const writers = 16
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Go(func() {
+			putContent(t, s, "b", "k"+string(rune('a'+i)), body)
+		})
+	}
+	wg.Wait()
+
+	require.Len(t, contentEntries(t, root), 1)
+
+	n, err := linkCount(contentEntries(t, root)[0])
+	require.NoError(t, err)
+	require.EqualValues(t, writers+1, n)
+
+	for i := range writers {
+		require.NoError(t, s.DeleteObject(ctx, "b", "k"+string(rune('a'+i))))
+	}
+
+	require.Empty(t, contentEntries(t, root))
+}
+
+func TestNewRemovesOrphanContent(t *testing.T) {
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root, WithDedup())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "kept", []byte("kept"))
+
+	// A body stored by a writer that died before linking its key.
+	orphan := s.contentPath("00" + string(bytes.Repeat([]byte("f"), 62)))
+	require.NoError(t, os.MkdirAll(filepath.Dir(orphan), defaultDirPermissions))
+	require.NoError(t, os.WriteFile(orphan, []byte("orphan"), 0o600))
+
+	s, err = New(root)
+	require.NoError(t, err)
+
+	require.NoFileExists(t, orphan)
+	require.Len(t, contentEntries(t, root), 1)
+	require.Equal(t, []byte("kept"), readContent(t, s, "b", "kept"))
+}
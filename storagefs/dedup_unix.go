@@ -0,0 +1,15 @@
+//go:build unix
+
+package storagefs
+
+import "golang.org/x/sys/unix"
+
+// linkCount returns the number of hard links to the file at path.
+func linkCount(path string) (uint64, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return 0, err
+	}
+
+	return uint64(st.Nlink), nil //nolint:unconvert // Nlink's width varies by platform.
+}
@@ -3,7 +3,6 @@ package storagefs
 import (
 	"context"
 	"os"
-	"path/filepath"
 
 	"github.com/go-faster/errors"
 
@@ -14,9 +13,23 @@ import (
 //
 // NB: bucket is already sanitized.
 func (s *Storage) DeleteBucket(ctx context.Context, bucket string) error {
-	bucketPath := filepath.Join(s.root, bucket)
+	// Never remove a file that merely has the bucket's name.
+	bucketPath, err := s.bucketDir(bucket)
+	if err != nil {
+		return err
+	}
+
+	// The filter stops vouching for the bucket first, so a GET racing the
+	// removal goes to disk and sees NoSuchBucket rather than NoSuchKey.
+	if s.existence != nil {
+		s.existence.removeBucket(bucket)
+	}
 
 	if err := os.Remove(bucketPath); err != nil {
+		if s.existence != nil && !os.IsNotExist(err) {
+			s.existence.addBucket(bucket)
+		}
+
 		if os.IsNotExist(err) {
 			return fs.ErrBucketNotFound
 		}
@@ -30,6 +43,7 @@ func (s *Storage) DeleteBucket(ctx context.Context, bucket string) error {
 		return errors.Wrap(err, "delete bucket")
 	}
 
+	s.forgetBucketRoot(bucket)
 	s.deleteBucketMeta(bucket)
 
 	return nil
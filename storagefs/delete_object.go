@@ -14,12 +14,40 @@ import (
 //
 // NB: bucket and key are already sanitized.
 func (s *Storage) DeleteObject(ctx context.Context, bucket, key string) error {
-	bucketPath := filepath.Join(s.root, bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return fs.ErrBucketNotFound
+	bucketPath, err := s.bucketDir(bucket)
+	if err != nil {
+		return err
 	}
 
-	objectPath := filepath.Join(bucketPath, toOSPath(key))
+	objectPath := filepath.Join(bucketPath, s.keyPath(key))
+
+	// A delete holds putMu like a PUT, so its condition and legal-hold checks
+	// and the removal are atomic against writers to the key.
+	defer s.lockListings(bucket)()
+
+	s.putMu.Lock()
+	defer s.putMu.Unlock()
+
+	if cond := fs.DeleteConditionFromContext(ctx); cond != nil {
+		exists, etag, err := s.currentObjectState(bucket, key, objectPath)
+		if err != nil {
+			return err
+		}
+
+		if cond.PreconditionFailed(exists, etag) {
+			return fs.ErrPreconditionFailed
+		}
+	}
+
+	// Note the content-store entry before the sidecar goes away.
+	sc, err := s.readSidecar(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if sc != nil && sc.LegalHold {
+		return legalHoldError(bucket, key)
+	}
 
 	if err := os.Remove(objectPath); err != nil {
 		if os.IsNotExist(err) {
@@ -29,12 +57,26 @@ func (s *Storage) DeleteObject(ctx context.Context, bucket, key string) error {
 		return errors.Wrap(err, "delete object")
 	}
 
+	// A file without a sidecar may have been placed behind the storage's
+	// back and never added; removing it would take counts from other keys.
+	if s.existence != nil && sc != nil {
+		s.existence.remove(bucket, s.existenceKey(key))
+	}
+
+	s.removeKeyIndex(objectPath)
 	s.deleteSidecar(bucket, key)
 
+	if sc != nil {
+		s.releaseContent(sc.Content)
+	}
+
 	// Prune the now-empty parent directories left behind by a nested key, up
 	// to (but not including) the bucket root, so a bucket whose objects have
-	// all been deleted becomes genuinely empty and can be removed.
-	pruneEmptyDirs(filepath.Dir(objectPath), bucketPath)
+	// all been deleted becomes genuinely empty and can be removed. Strict
+	// prefixes are operator-made and stay.
+	if !s.strictPrefixes {
+		pruneEmptyDirs(filepath.Dir(objectPath), bucketPath)
+	}
 
 	return nil
 }
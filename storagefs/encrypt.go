@@ -0,0 +1,548 @@
+package storagefs
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// ServerSideEncryptionAES256 is the x-amz-server-side-encryption value
+// reported for objects encrypted with WithEncryptionKey.
+const ServerSideEncryptionAES256 = "AES256"
+
+// WithEncryptionKey encrypts object bodies at rest with AES-256-GCM under key,
+// which must be 32 bytes (New rejects other lengths). Encryption is
+// transparent: PutObject and multipart uploads store ciphertext, GetObject
+// decrypts, and reads report x-amz-server-side-encryption: AES256.
+//
+// The ETag stays the MD5 of the plaintext (the "-N" composite for multipart),
+// as S3 reports for SSE-S3, so clients checking it against Content-MD5 keep
+// working; the sidecar checksum covers the ciphertext, so Scrub and
+// WithVerifyOnRead check what is on disk without the key. Each object records
+// its nonce and the ID of the key that sealed it in its metadata document, so
+// losing that document makes the object unreadable: the body starts with a
+// marker, and GetObject refuses one without metadata (fs.ErrIntegrity) rather
+// than serve its ciphertext. Objects stored before the key was set stay
+// plaintext and readable.
+//
+// A body is linked once per content under WithDedup, which per-object nonces
+// rule out, so the two cannot be combined.
+func WithEncryptionKey(key []byte) Option {
+	return func(s *Storage) { s.encryptionKey = bytes.Clone(key) }
+}
+
+// Encrypted reports whether new objects are encrypted at rest.
+func (s *Storage) Encrypted() bool { return s.sealer != nil }
+
+// encryptionAlgorithm names the on-disk format recorded in the sidecar:
+// sealedMagic, then AES-256-GCM over fixed-size segments (see encryptWriter).
+const encryptionAlgorithm = "AES256-GCM-SEG64K"
+
+// sealedMagic starts every sealed object body, so a body whose metadata is
+// lost is still recognized as ciphertext. The sealed stream follows it.
+var sealedMagic = []byte("\x00FSSEAL\x01")
+
+// sealedOnDisk reports whether the object file f starts with sealedMagic.
+func sealedOnDisk(f io.ReaderAt) bool {
+	head := make([]byte, len(sealedMagic))
+	n, _ := f.ReadAt(head, 0)
+
+	return n == len(head) && bytes.Equal(head, sealedMagic)
+}
+
+// writeSealedMagic starts a sealed object body on w.
+func writeSealedMagic(w io.Writer) error {
+	if _, err := w.Write(sealedMagic); err != nil {
+		return errors.Wrap(err, "write encryption marker")
+	}
+
+	return nil
+}
+
+const (
+	// segmentSize is the plaintext length of every segment but the last.
+	segmentSize = 64 << 10
+	// sealedSegmentSize is a full segment on disk: ciphertext plus GCM tag.
+	sealedSegmentSize = segmentSize + gcmTagSize
+	gcmTagSize        = 16
+	gcmNonceSize      = 12
+)
+
+// encryptionInfo is the per-object record of how a body was encrypted.
+// KeyID names the server key rather than assuming the current one, so a
+// keyring can later decrypt objects sealed under retired keys. A body sealed
+// with an SSE-C key records only the key's MD5 in CustomerKeyMD5; the key
+// itself comes with every read.
+type encryptionInfo struct {
+	Algorithm      string `json:"algorithm"`
+	KeyID          string `json:"key_id,omitempty"`
+	CustomerKeyMD5 string `json:"customer_key_md5,omitempty"`
+	Nonce          []byte `json:"nonce"`
+}
+
+// sealer is a loaded encryption key.
+type sealer struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) != 32 {
+		return nil, errors.Errorf("encryption key must be 32 bytes (AES-256), got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "encryption key")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "encryption key")
+	}
+
+	// The ID is a truncated hash: enough to tell keys apart, useless for
+	// recovering one.
+	sum := sha256.Sum256(key)
+
+	return &sealer{id: hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+// newInfo draws a fresh random nonce for one body.
+func (k *sealer) newInfo() (*encryptionInfo, error) {
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generate nonce")
+	}
+
+	return &encryptionInfo{Algorithm: encryptionAlgorithm, KeyID: k.id, Nonce: nonce}, nil
+}
+
+// sseFor returns the x-amz-server-side-encryption value for a body described
+// by info, which is nil for plaintext. SSE-C bodies report theirs through the
+// customer-key headers instead.
+func sseFor(info *encryptionInfo) string {
+	if info == nil || info.CustomerKeyMD5 != "" {
+		return ""
+	}
+
+	return ServerSideEncryptionAES256
+}
+
+// customerKeyMD5 returns the SSE-C key MD5 to report for a body described by
+// info, or "".
+func customerKeyMD5(info *encryptionInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	return info.CustomerKeyMD5
+}
+
+// sealerFor picks the key a new body is sealed with: the SSE-C key carried by
+// ctx, else the server key. Both results are nil for a plaintext body.
+func (s *Storage) sealerFor(ctx context.Context) (*sealer, *encryptionInfo, error) {
+	k := s.sealer
+
+	if ck := fs.CustomerKeyFromContext(ctx); ck != nil {
+		var err error
+		if k, err = newSealer(ck.Key); err != nil {
+			return nil, nil, errors.Wrap(fs.ErrEncryptionParameters, err.Error())
+		}
+
+		info, err := k.newInfo()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		info.KeyID, info.CustomerKeyMD5 = "", ck.KeyMD5
+
+		return k, info, nil
+	}
+
+	if k == nil {
+		return nil, nil, nil
+	}
+
+	info, err := k.newInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return k, info, nil
+}
+
+// checkCustomerKey rejects an SSE-C key supplied for a body that was not
+// sealed with one.
+func checkCustomerKey(ctx context.Context, info *encryptionInfo) error {
+	if fs.CustomerKeyFromContext(ctx) != nil && customerKeyMD5(info) == "" {
+		return errors.Wrap(fs.ErrEncryptionParameters, "object is not encrypted with a customer-provided key")
+	}
+
+	return nil
+}
+
+// keyFor returns the key that opens a body described by info: the SSE-C key
+// carried by ctx, which must match the recorded MD5, or the server key.
+func (s *Storage) keyFor(ctx context.Context, info *encryptionInfo) (*sealer, error) {
+	if info.Algorithm != encryptionAlgorithm {
+		return nil, errors.Errorf("unsupported encryption algorithm %q", info.Algorithm)
+	}
+
+	if len(info.Nonce) != gcmNonceSize {
+		return nil, errors.Errorf("invalid encryption nonce length %d", len(info.Nonce))
+	}
+
+	if info.CustomerKeyMD5 != "" {
+		ck := fs.CustomerKeyFromContext(ctx)
+		if ck == nil {
+			return nil, errors.Wrap(fs.ErrEncryptionParameters, "object is encrypted with a customer-provided key")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(ck.KeyMD5), []byte(info.CustomerKeyMD5)) != 1 {
+			return nil, fs.ErrCustomerKeyMismatch
+		}
+
+		k, err := newSealer(ck.Key)
+		if err != nil {
+			return nil, errors.Wrap(fs.ErrEncryptionParameters, err.Error())
+		}
+
+		return k, nil
+	}
+
+	if err := checkCustomerKey(ctx, info); err != nil {
+		return nil, err
+	}
+
+	if s.sealer == nil || s.sealer.id != info.KeyID {
+		return nil, errors.Errorf("object is encrypted with key %s, which is not configured", info.KeyID)
+	}
+
+	return s.sealer, nil
+}
+
+// segmentNonce derives segment i's nonce from the body's base nonce by XORing
+// the index into its last 8 bytes, so no two segments share one.
+func segmentNonce(dst, base []byte, i int64) []byte {
+	dst = append(dst[:0], base...)
+
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(i)) //nolint:gosec // Segment indexes are non-negative.
+
+	for j := range idx {
+		dst[4+j] ^= idx[j]
+	}
+
+	return dst
+}
+
+// segmentAAD marks the final segment, so dropping whole trailing segments
+// fails authentication instead of silently truncating the body.
+func segmentAAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+
+	return []byte{0}
+}
+
+// plaintextSize returns the body length of a sealed stream of sealed bytes.
+// Every stream holds at least one segment, so an empty body is one bare tag.
+func plaintextSize(sealed int64) int64 {
+	segments := (sealed + sealedSegmentSize - 1) / sealedSegmentSize
+	if segments == 0 {
+		return 0
+	}
+
+	return sealed - segments*gcmTagSize
+}
+
+// encryptWriter seals what is written to it as a stream of segments: each
+// segmentSize bytes of plaintext become one GCM box under a per-segment nonce,
+// and Close seals the (possibly short, possibly empty) final one. A sealed
+// stream can be opened at any segment, which keeps ranged GETs cheap.
+type encryptWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	base  []byte
+	nonce []byte
+	buf   []byte
+	out   []byte
+	seq   int64
+}
+
+func newEncryptWriter(w io.Writer, k *sealer, info *encryptionInfo) *encryptWriter {
+	return &encryptWriter{
+		w:    w,
+		aead: k.aead,
+		base: info.Nonce,
+		buf:  make([]byte, 0, segmentSize),
+		out:  make([]byte, 0, sealedSegmentSize),
+	}
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		// A full segment is sealed only once more data arrives: until then
+		// it may be the last one.
+		if len(e.buf) == segmentSize {
+			if err := e.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+
+		c := copy(e.buf[len(e.buf):segmentSize], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+	}
+
+	return n, nil
+}
+
+// Close seals the final segment. It does not close the underlying writer.
+func (e *encryptWriter) Close() error { return e.seal(true) }
+
+func (e *encryptWriter) seal(last bool) error {
+	e.nonce = segmentNonce(e.nonce, e.base, e.seq)
+	e.out = e.aead.Seal(e.out[:0], e.nonce, e.buf, segmentAAD(last))
+	e.buf = e.buf[:0]
+	e.seq++
+
+	if _, err := e.w.Write(e.out); err != nil {
+		return errors.Wrap(err, "write encrypted segment")
+	}
+
+	return nil
+}
+
+// decryptReader serves the plaintext of a sealed stream stored in f from
+// offset start, opening one segment at a time. It seeks, so http.ServeContent
+// can answer ranges without decrypting what precedes them. A segment that
+// fails authentication fails the read with fs.ErrIntegrity.
+type decryptReader struct {
+	f      *os.File
+	aead   cipher.AEAD
+	base   []byte
+	start  int64
+	sealed int64
+	size   int64
+
+	pos   int64 // plaintext offset
+	seg   int64 // index of the segment held in plain, or -1
+	plain []byte
+	box   []byte
+	nonce []byte
+	err   error // sticky authentication failure
+
+	// onCorrupt, if set, runs on the first authentication failure.
+	onCorrupt func()
+	// onClose, if set, runs after f is closed, with whether a failure was seen.
+	onClose func(corrupt bool)
+}
+
+func newDecryptReader(f *os.File, start int64, k *sealer, nonce []byte) (*decryptReader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "stat object")
+	}
+
+	sealed := info.Size() - start
+
+	return &decryptReader{
+		f:      f,
+		aead:   k.aead,
+		base:   nonce,
+		start:  start,
+		sealed: sealed,
+		size:   plaintextSize(sealed),
+		seg:    -1,
+	}, nil
+}
+
+// Size is the plaintext length.
+func (d *decryptReader) Size() int64 { return d.size }
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	if d.pos >= d.size {
+		// An empty body is still one sealed segment; authenticate it so a
+		// zeroed or truncated file is not served as empty.
+		if d.size == 0 && d.seg != 0 {
+			if err := d.load(0); err != nil {
+				return 0, err
+			}
+		}
+
+		return 0, io.EOF
+	}
+
+	idx := d.pos / segmentSize
+	if idx != d.seg {
+		if err := d.load(idx); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain[d.pos-idx*segmentSize:])
+	d.pos += int64(n)
+
+	return n, nil
+}
+
+func (d *decryptReader) load(idx int64) error {
+	off := idx * sealedSegmentSize
+	length := min(int64(sealedSegmentSize), d.sealed-off)
+
+	if length < gcmTagSize {
+		return d.fail(errors.Wrapf(fs.ErrIntegrity, "encrypted segment %d truncated", idx))
+	}
+
+	if cap(d.box) < int(length) {
+		d.box = make([]byte, sealedSegmentSize)
+	}
+
+	box := d.box[:length]
+	if _, err := d.f.ReadAt(box, d.start+off); err != nil {
+		return errors.Wrapf(err, "read encrypted segment %d", idx)
+	}
+
+	last := off+length == d.sealed
+	d.nonce = segmentNonce(d.nonce, d.base, idx)
+
+	plain, err := d.aead.Open(d.plain[:0], d.nonce, box, segmentAAD(last))
+	if err != nil {
+		return d.fail(errors.Wrapf(fs.ErrIntegrity, "encrypted segment %d failed authentication", idx))
+	}
+
+	d.plain = plain
+	d.seg = idx
+
+	return nil
+}
+
+func (d *decryptReader) fail(err error) error {
+	d.err = err
+	d.seg = -1
+
+	if d.onCorrupt != nil {
+		d.onCorrupt()
+	}
+
+	return err
+}
+
+func (d *decryptReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = d.pos + offset
+	case io.SeekEnd:
+		pos = d.size + offset
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+
+	if pos < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+
+	d.pos = pos
+
+	return pos, nil
+}
+
+func (d *decryptReader) Close() error {
+	err := d.f.Close()
+
+	if d.onClose != nil {
+		d.onClose(d.err != nil)
+	}
+
+	return err
+}
+
+var _ io.ReadSeekCloser = (*decryptReader)(nil)
+
+// openEncrypted wraps an open object file whose sidecar records enc in a
+// decrypting reader. Authentication failures count as corrupt reads and, under
+// WithReadQuarantine, move the object aside once the reader is closed (if its
+// on-disk checksum confirms the damage).
+func (s *Storage) openEncrypted(ctx context.Context, f *os.File, bucket, key string, enc *encryptionInfo) (*decryptReader, error) {
+	k, err := s.keyFor(ctx, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := newDecryptReader(f, int64(len(sealedMagic)), k, enc.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	d.onCorrupt = func() { s.corruptReads.Add(1) }
+	d.onClose = func(corrupt bool) {
+		if !corrupt || !s.readQuarantine {
+			return
+		}
+
+		path := filepath.Join(s.root, bucket, s.keyPath(key))
+		if errors.Is(s.verifyContent(bucket, key, path), fs.ErrIntegrity) {
+			_ = s.quarantineObject(bucket, key)
+		}
+	}
+
+	return d, nil
+}
+
+// Encrypted multipart parts are staged as the part's base nonce followed by
+// its sealed stream, so each part file is self-describing until Complete
+// re-seals the assembled body under the object's own nonce.
+
+// newPartWriter starts an encrypted part file by writing its nonce header.
+func (s *Storage) newPartWriter(w io.Writer) (*encryptWriter, error) {
+	info, err := s.sealer.newInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(info.Nonce); err != nil {
+		return nil, errors.Wrap(err, "write part nonce")
+	}
+
+	return newEncryptWriter(w, s.sealer, info), nil
+}
+
+// openPart returns the plaintext reader of a part file sealed under keyID.
+func (s *Storage) openPart(f *os.File, keyID string) (*decryptReader, error) {
+	if s.sealer == nil || s.sealer.id != keyID {
+		return nil, errors.Errorf("upload is encrypted with key %s, which is not configured", keyID)
+	}
+
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := f.ReadAt(nonce, 0); err != nil {
+		return nil, errors.Wrap(fs.ErrIntegrity, "read part nonce")
+	}
+
+	return newDecryptReader(f, gcmNonceSize, s.sealer, nonce)
+}
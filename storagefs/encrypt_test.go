@@ -0,0 +1,367 @@
+package storagefs
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // Expected ETags.
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func testEncryptionKey(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+// encryptedBody returns a recognizable body spanning several segments with a
+// short final one.
+func encryptedBody() []byte {
+	return bytes.Repeat([]byte("plaintext-marker "), (3*segmentSize)/17+100)
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data) //nolint:gosec // S3 ETag.
+	return hex.EncodeToString(sum[:])
+}
+
+func TestEncryption(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root, WithEncryptionKey(testEncryptionKey(1)))
+	require.NoError(t, err)
+	require.True(t, s.Encrypted())
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	body := encryptedBody()
+	put, err := s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "secret.txt", Reader: bytes.NewReader(body), Size: int64(len(body)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, md5Hex(body), put.ETag)
+	require.Equal(t, ServerSideEncryptionAES256, put.ServerSideEncryption)
+
+	t.Run("CiphertextOnDisk", func(t *testing.T) {
+		raw, err := os.ReadFile(filepath.Join(root, "b", "secret.txt"))
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "plaintext-marker")
+		require.Equal(t, sealedMagic, raw[:len(sealedMagic)])
+		require.Equal(t, int64(len(body)), plaintextSize(int64(len(raw)-len(sealedMagic))))
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := s.GetObject(ctx, "b", "secret.txt")
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Reader.Close() }()
+
+		require.Equal(t, int64(len(body)), resp.Size)
+		require.Equal(t, md5Hex(body), resp.ETag)
+		require.Equal(t, ServerSideEncryptionAES256, resp.ServerSideEncryption)
+
+		data, err := io.ReadAll(resp.Reader)
+		require.NoError(t, err)
+		require.Equal(t, body, data)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, err := s.GetObject(ctx, "b", "secret.txt")
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Reader.Close() }()
+
+		// Straddle the boundary between the first two segments.
+		off := int64(segmentSize - 10)
+		_, err = resp.Reader.(io.Seeker).Seek(off, io.SeekStart)
+		require.NoError(t, err)
+
+		buf := make([]byte, 30)
+		_, err = io.ReadFull(resp.Reader, buf)
+		require.NoError(t, err)
+		require.Equal(t, body[off:off+30], buf)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		objects, err := s.ListObjects(ctx, "b", "")
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		require.Equal(t, int64(len(body)), objects[0].Size)
+		require.Equal(t, md5Hex(body), objects[0].ETag)
+	})
+
+	t.Run("Scrub", func(t *testing.T) {
+		report, err := s.Scrub(ctx, ScrubOptions{})
+		require.NoError(t, err)
+		require.True(t, report.Healthy())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		putContent(t, s, "b", "empty", nil)
+		require.Empty(t, readContent(t, s, "b", "empty"))
+	})
+
+	t.Run("MissingSidecar", func(t *testing.T) {
+		putContent(t, s, "b", "orphan", body)
+		require.NoError(t, os.Remove(s.sidecarPath("b", "orphan")))
+
+		// Without the sidecar there is no nonce to decrypt with; the
+		// ciphertext must not be served as the object.
+		_, err := s.GetObject(ctx, "b", "orphan")
+		require.ErrorIs(t, err, fs.ErrIntegrity)
+	})
+}
+
+func TestEncryption_Tampered(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		tamper func(data []byte) []byte
+		// resized tampering no longer matches the recorded file size, which
+		// GetObject checks before serving anything.
+		resized bool
+	}{
+		{"FlippedByte", func(data []byte) []byte { data[len(data)/2] ^= 0xFF; return data }, false},
+		{"DroppedSegment", func(data []byte) []byte { return data[:sealedSegmentSize] }, true},
+		{"Appended", func(data []byte) []byte { return append(data, 0) }, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			s, err := New(root, WithEncryptionKey(testEncryptionKey(1)))
+			require.NoError(t, err)
+			require.NoError(t, s.CreateBucket(t.Context(), "b"))
+			putContent(t, s, "b", "obj", encryptedBody())
+
+			path := filepath.Join(root, "b", "obj")
+			data, err := os.ReadFile(path) //nolint:gosec // test path.
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, tt.tamper(data), 0o600))
+
+			resp, err := s.GetObject(t.Context(), "b", "obj")
+			if tt.resized {
+				require.ErrorIs(t, err, fs.ErrIntegrity)
+				require.EqualValues(t, 1, s.CorruptReads())
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			_, err = io.ReadAll(resp.Reader)
+			require.ErrorIs(t, err, fs.ErrIntegrity)
+			require.NoError(t, resp.Reader.Close())
+			require.EqualValues(t, 1, s.CorruptReads())
+		})
+	}
+}
+
+func TestEncryption_Keys(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root, WithEncryptionKey(testEncryptionKey(1)))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+	putContent(t, s, "b", "obj", []byte("sealed"))
+
+	t.Run("Other", func(t *testing.T) {
+		other, err := New(root, WithEncryptionKey(testEncryptionKey(2)))
+		require.NoError(t, err)
+
+		_, err = other.GetObject(t.Context(), "b", "obj")
+		require.ErrorContains(t, err, "not configured")
+	})
+
+	t.Run("None", func(t *testing.T) {
+		plain, err := New(root)
+		require.NoError(t, err)
+
+		_, err = plain.GetObject(t.Context(), "b", "obj")
+		require.ErrorContains(t, err, "not configured")
+
+		// Objects written without a key stay readable once one is set.
+		putContent(t, plain, "b", "plain", []byte("clear"))
+		require.Equal(t, "clear", string(readContent(t, s, "b", "plain")))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := New(t.TempDir(), WithEncryptionKey([]byte("short")))
+		require.ErrorContains(t, err, "32 bytes")
+
+		_, err = New(t.TempDir(), WithEncryptionKey(testEncryptionKey(1)), WithDedup())
+		require.Error(t, err)
+	})
+}
+
+func TestEncryption_Multipart(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root, WithEncryptionKey(testEncryptionKey(1)))
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	upload, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "mp"})
+	require.NoError(t, err)
+
+	parts := [][]byte{encryptedBody(), []byte("plaintext-marker tail")}
+
+	var completed []fs.CompletedPart
+
+	for i, data := range parts {
+		part, err := s.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket: "b", Key: "mp", UploadID: upload.UploadID, PartNumber: i + 1,
+			Reader: bytes.NewReader(data), Size: int64(len(data)),
+		})
+		require.NoError(t, err)
+		require.Equal(t, md5Hex(data), part.ETag)
+
+		raw, err := os.ReadFile(filepath.Join(s.multipart.uploadPath(upload.UploadID), strconv.Itoa(part.PartNumber)))
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "plaintext-marker")
+
+		completed = append(completed, fs.CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	listed, err := s.ListParts(ctx, "b", "mp", upload.UploadID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	for i, p := range listed {
+		require.Equal(t, md5Hex(parts[i]), p.ETag)
+		require.Equal(t, int64(len(parts[i])), p.Size)
+	}
+
+	resp, err := s.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: "b", Key: "mp", UploadID: upload.UploadID, Parts: completed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ServerSideEncryptionAES256, resp.ServerSideEncryption)
+
+	raw, err := os.ReadFile(filepath.Join(root, "b", "mp"))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "plaintext-marker")
+
+	require.Equal(t, bytes.Join(parts, nil), readContent(t, s, "b", "mp"))
+}
+
+func testCustomerKey(b byte) *fs.CustomerKey {
+	key := testEncryptionKey(b)
+	sum := md5.Sum(key) //nolint:gosec // SSE-C key MD5.
+
+	return &fs.CustomerKey{Key: key, KeyMD5: base64.StdEncoding.EncodeToString(sum[:])}
+}
+
+func TestEncryption_CustomerKey(t *testing.T) {
+	root := t.TempDir()
+	// The server key must not be used for SSE-C objects, nor get in the way.
+	s, err := New(root, WithEncryptionKey(testEncryptionKey(1)))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	ck := testCustomerKey(7)
+	ctx := fs.WithCustomerKey(t.Context(), ck)
+	body := []byte("plaintext-marker for the customer")
+
+	put, err := s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "obj", Reader: bytes.NewReader(body), Size: int64(len(body)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ck.KeyMD5, put.SSECustomerKeyMD5)
+	require.Empty(t, put.ServerSideEncryption)
+	require.NotEqual(t, md5Hex(body), put.ETag, "SSE-C ETag must not be the plaintext MD5")
+
+	raw, err := os.ReadFile(filepath.Join(root, "b", "obj"))
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "plaintext-marker")
+
+	// Only the key's MD5 is persisted.
+	meta, err := os.ReadFile(s.sidecarPath("b", "obj"))
+	require.NoError(t, err)
+	require.Contains(t, string(meta), ck.KeyMD5)
+	require.NotContains(t, string(meta), base64.StdEncoding.EncodeToString(ck.Key))
+	require.NotContains(t, string(meta), hex.EncodeToString(ck.Key))
+
+	t.Run("CorrectKey", func(t *testing.T) {
+		resp, err := s.GetObject(ctx, "b", "obj")
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Reader.Close() }()
+
+		require.Equal(t, ck.KeyMD5, resp.SSECustomerKeyMD5)
+		require.Equal(t, put.ETag, resp.ETag)
+		require.Equal(t, int64(len(body)), resp.Size)
+
+		data, err := io.ReadAll(resp.Reader)
+		require.NoError(t, err)
+		require.Equal(t, body, data)
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		_, err := s.GetObject(fs.WithCustomerKey(t.Context(), testCustomerKey(8)), "b", "obj")
+		require.ErrorIs(t, err, fs.ErrCustomerKeyMismatch)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		_, err := s.GetObject(t.Context(), "b", "obj")
+		require.ErrorIs(t, err, fs.ErrEncryptionParameters)
+	})
+
+	t.Run("KeyForOtherObject", func(t *testing.T) {
+		putContent(t, s, "b", "server-encrypted", []byte("x"))
+		_, err := s.GetObject(ctx, "b", "server-encrypted")
+		require.ErrorIs(t, err, fs.ErrEncryptionParameters)
+
+		plain, err := New(root)
+		require.NoError(t, err)
+		putContent(t, plain, "b", "plain", []byte("x"))
+
+		_, err = plain.GetObject(ctx, "b", "plain")
+		require.ErrorIs(t, err, fs.ErrEncryptionParameters)
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		_, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "mp"})
+		require.ErrorIs(t, err, fs.ErrUnsupportedOperation)
+	})
+
+	t.Run("ListObjectsByMetadata", func(t *testing.T) {
+		_, err := s.PutObject(t.Context(), &fs.PutObjectRequest{
+			Bucket: "b", Key: "tagged", Reader: bytes.NewReader(nil),
+			Metadata: fs.ObjectMetadata{UserMetadata: map[string]string{"env": "prod"}},
+		})
+		require.NoError(t, err)
+
+		// The SSE-C object cannot be read without its key and is skipped.
+		var keys []string
+		for o, err := range fs.ListObjectsByMetadata(t.Context(), s, "b", "", map[string]string{"x-amz-meta-env": "prod"}) {
+			require.NoError(t, err)
+
+			keys = append(keys, o.Key)
+		}
+
+		require.Equal(t, []string{"tagged"}, keys)
+	})
+}
+
+func TestEncryption_CustomerKeyCorruptSidecar(t *testing.T) {
+	// No server key: the SSE-C body is the only sealed thing in the store.
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	ctx := fs.WithCustomerKey(t.Context(), testCustomerKey(7))
+	body := []byte("plaintext-marker for the customer")
+	_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "obj", Reader: bytes.NewReader(body), Size: int64(len(body)),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(s.sidecarPath("b", "obj"), []byte("{not json"), 0o600))
+
+	for _, ctx := range []context.Context{ctx, t.Context()} {
+		_, err := s.GetObject(ctx, "b", "obj")
+		require.ErrorIs(t, err, fs.ErrIntegrity)
+	}
+}
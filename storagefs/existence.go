@@ -0,0 +1,220 @@
+package storagefs
+
+import (
+	"context"
+	"hash/maphash"
+	iofs "io/fs"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-faster/errors"
+)
+
+// defaultFilterKeys sizes the existence filter when WithExistenceFilter is
+// given no expected key count.
+const defaultFilterKeys = 1 << 20
+
+// WithExistenceFilter keeps an in-memory filter of the keys that exist, so a
+// GET of a key that definitely does not exist is answered without touching
+// the disk. New fills it by walking every bucket; writes add to it and
+// deletes remove from it. It never reports a key it was told about as
+// missing, only (about 1% of the time, at up to expectedKeys keys) a
+// missing key as possibly present, which then costs the usual lookup.
+//
+// The filter learns only of writes made through this Storage: a file placed
+// in a bucket directory by hand stays invisible to GET until the next start.
+// Deleting a file without metadata (one placed by hand, before or after the
+// start) leaves its key counted, as the filter cannot tell whether it was
+// ever added; it costs a lookup until the next start.
+// It takes about ten bytes per expected key; expectedKeys <= 0 sizes it for a
+// million.
+func WithExistenceFilter(expectedKeys int) Option {
+	return func(s *Storage) {
+		if expectedKeys <= 0 {
+			expectedKeys = defaultFilterKeys
+		}
+
+		s.existence = newExistenceFilter(expectedKeys, 0.01)
+	}
+}
+
+// existenceFilter is a counting Bloom filter over bucket/file pairs (see
+// existenceKey), plus the exact set of buckets. Counters saturate instead of wrapping, and a
+// saturated counter is never decremented, so removals cannot turn a present
+// key into a false negative.
+type existenceFilter struct {
+	seed   maphash.Seed
+	hashes int
+
+	mu       sync.RWMutex
+	counters []uint8
+	buckets  map[string]struct{}
+}
+
+// newExistenceFilter sizes a filter for n keys at false-positive rate p.
+func newExistenceFilter(n int, p float64) *existenceFilter {
+	m := int(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := max(1, int(math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &existenceFilter{
+		seed:     maphash.MakeSeed(),
+		hashes:   k,
+		counters: make([]uint8, m),
+		buckets:  make(map[string]struct{}),
+	}
+}
+
+// positions calls fn with the counter index of each hash of bucket/key,
+// derived from one 64-bit hash by double hashing.
+func (f *existenceFilter) positions(bucket, key string, fn func(i int)) {
+	var h maphash.Hash
+
+	h.SetSeed(f.seed)
+	_, _ = h.WriteString(bucket)
+	_ = h.WriteByte(0)
+	_, _ = h.WriteString(key)
+
+	sum := h.Sum64()
+	h1, h2 := uint32(sum), uint32(sum>>32)|1
+	m := uint64(len(f.counters))
+
+	for i := range f.hashes {
+		fn(int((uint64(h1) + uint64(i)*uint64(h2)) % m))
+	}
+}
+
+// add records bucket/key as present.
+func (f *existenceFilter) add(bucket, key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.positions(bucket, key, func(i int) {
+		if f.counters[i] < math.MaxUint8 {
+			f.counters[i]++
+		}
+	})
+}
+
+// remove forgets one add of bucket/key. Call it only for a key that was
+// added, or counters shared with other keys would drop below their count:
+// DeleteObject calls it only for objects with a sidecar, which the filter
+// always knows (see learnKey).
+func (f *existenceFilter) remove(bucket, key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.positions(bucket, key, func(i int) {
+		if c := f.counters[i]; c > 0 && c < math.MaxUint8 {
+			f.counters[i]--
+		}
+	})
+}
+
+// mayExist reports whether bucket/key may exist: false only for a key never
+// added (or added and removed as often) in a bucket the filter knows.
+func (f *existenceFilter) mayExist(bucket, key string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if _, ok := f.buckets[bucket]; !ok {
+		// Only the disk can tell a missing bucket from a missing key.
+		return true
+	}
+
+	present := true
+
+	f.positions(bucket, key, func(i int) {
+		if f.counters[i] == 0 {
+			present = false
+		}
+	})
+
+	return present
+}
+
+// existenceKey returns what the existence filter records for key: its file
+// path below the bucket, as existencePath normalizes it. Keys that alias on
+// disk ("a//b" and "a/b", or differing only in case where KeysFoldCase) open
+// the same file, so they must be one entry, or a GET of one spelling would be
+// refused for an object stored under the other.
+func (s *Storage) existenceKey(key string) string {
+	return s.existencePath(s.keyPath(key))
+}
+
+// existencePath normalizes a file path below a bucket directory the way the
+// filesystem resolves it: cleaned, and lowercased where keys fold case.
+func (s *Storage) existencePath(rel string) string {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if s.KeysFoldCase() {
+		rel = strings.ToLower(rel)
+	}
+
+	return rel
+}
+
+// learnKey adds bucket/key to the existence filter, if there is one, before a
+// sidecar is created for an object written behind the storage's back, so that
+// every object with a sidecar is one the filter was told about.
+func (s *Storage) learnKey(bucket, key string) {
+	if s.existence != nil {
+		s.existence.add(bucket, s.existenceKey(key))
+	}
+}
+
+func (f *existenceFilter) addBucket(bucket string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.buckets[bucket] = struct{}{}
+}
+
+func (f *existenceFilter) removeBucket(bucket string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.buckets, bucket)
+}
+
+// fillExistenceFilter adds every bucket and every file in it to the filter.
+// Anything a GET could open counts, symlinks included, so the filter errs
+// towards presence.
+func (s *Storage) fillExistenceFilter(ctx context.Context) error {
+	buckets, err := s.ListBuckets(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list buckets")
+	}
+
+	for _, b := range buckets {
+		bucketPath := filepath.Join(s.root, b.Name)
+
+		err := filepath.WalkDir(bucketPath, func(path string, d iofs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(bucketPath, path)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := s.pathKey(path, relPath); ok {
+				s.existence.add(b.Name, s.existencePath(relPath))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "walk bucket %q", b.Name)
+		}
+
+		s.existence.addBucket(b.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package storagefs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestExistenceFilter_NoFalseNegatives(t *testing.T) {
+	t.Parallel()
+
+	f := newExistenceFilter(100, 0.01)
+	f.addBucket("b")
+
+	// Well past the sized capacity, so counters are shared and some saturate.
+	const n = 5000
+
+	for i := range n {
+		f.add("b", fmt.Sprintf("key-%d", i))
+	}
+
+	// Remove every other key, some more than once added.
+	for i := 0; i < n; i += 2 {
+		f.add("b", fmt.Sprintf("key-%d", i))
+		f.remove("b", fmt.Sprintf("key-%d", i))
+		f.remove("b", fmt.Sprintf("key-%d", i))
+	}
+
+	for i := 1; i < n; i += 2 {
+		require.True(t, f.mayExist("b", fmt.Sprintf("key-%d", i)), "key-%d", i)
+	}
+
+	// A bucket the filter does not know is left to the disk.
+	require.True(t, f.mayExist("other", "key-0"))
+}
+
+func TestExistenceFilter_FalsePositiveRate(t *testing.T) {
+	t.Parallel()
+
+	const n = 10_000
+
+	f := newExistenceFilter(n, 0.01)
+	f.addBucket("b")
+
+	for i := range n {
+		f.add("b", fmt.Sprintf("present-%d", i))
+	}
+
+	var positives int
+
+	for i := range n {
+		if f.mayExist("b", fmt.Sprintf("absent-%d", i)) {
+			positives++
+		}
+	}
+
+	require.Less(t, positives, n*3/100)
+}
+
+func TestWithExistenceFilter(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	for i := range 100 {
+		putContent(t, s, "b", fmt.Sprintf("dir/old-%d", i), []byte("x"))
+	}
+
+	// Objects already on disk are found after a restart with the filter.
+	s, err = New(root, WithExistenceFilter(1000))
+	require.NoError(t, err)
+
+	for i := range 100 {
+		resp, err := s.GetObject(ctx, "b", fmt.Sprintf("dir/old-%d", i))
+		require.NoError(t, err)
+		require.NoError(t, resp.Reader.Close())
+	}
+
+	// So are objects written and completed through it.
+	putContent(t, s, "b", "new", []byte("y"))
+
+	resp, err := s.GetObject(ctx, "b", "new")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	upload, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "multi"})
+	require.NoError(t, err)
+
+	part, err := s.UploadPart(ctx, &fs.UploadPartRequest{
+		Bucket: "b", Key: "multi", UploadID: upload.UploadID, PartNumber: 1,
+		Reader: bytes.NewReader([]byte("z")), Size: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: "b", Key: "multi", UploadID: upload.UploadID,
+		Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+	})
+	require.NoError(t, err)
+
+	resp, err = s.GetObject(ctx, "b", "multi")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	// A deleted object is gone; writing it again brings it back.
+	require.NoError(t, s.DeleteObject(ctx, "b", "new"))
+
+	_, err = s.GetObject(ctx, "b", "new")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+	putContent(t, s, "b", "new", []byte("y"))
+
+	resp, err = s.GetObject(ctx, "b", "new")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	// A miss is answered from memory: a file planted behind the storage's
+	// back is not seen until the next start.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b", "planted"), []byte("p"), 0o600))
+
+	_, err = s.GetObject(ctx, "b", "planted")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+	// Missing buckets are still told apart from missing keys.
+	_, err = s.GetObject(ctx, "nope", "key")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+	require.NoError(t, s.CreateBucket(ctx, "c"))
+
+	_, err = s.GetObject(ctx, "c", "key")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+	require.NoError(t, s.DeleteBucket(ctx, "c"))
+
+	_, err = s.GetObject(ctx, "c", "key")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
+
+func TestWithExistenceFilter_OutOfBandDelete(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	ctx := t.Context()
+
+	// A filter for one key has about ten counters and seven hashes, so the
+	// planted key shares counters with the live one whatever the seed.
+	s, err := New(root, WithExistenceFilter(1))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "live", []byte("x"))
+
+	// The filter never saw the planted key; deleting it must not take the
+	// live key's counts with it.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b", "planted"), []byte("p"), 0o600))
+	require.NoError(t, s.DeleteObject(ctx, "b", "planted"))
+
+	resp, err := s.GetObject(ctx, "b", "live")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+
+	// Tagging a planted file gives it metadata, and with it a place in the
+	// filter, so its delete may count down.
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b", "tagged"), []byte("p"), 0o600))
+	require.NoError(t, s.PutObjectTagging(ctx, "b", "tagged", []fs.Tag{{Key: "k", Value: "v"}}))
+	require.NoError(t, s.DeleteObject(ctx, "b", "tagged"))
+
+	resp, err = s.GetObject(ctx, "b", "live")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+}
+
+func TestWithExistenceFilter_AliasedKeys(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root, WithExistenceFilter(100))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	// "a//b" is stored in the file a/b, which the walk after a restart
+	// finds under that name.
+	putContent(t, s, "b", "a//b", []byte("x"))
+
+	s, err = New(root, WithExistenceFilter(100))
+	require.NoError(t, err)
+
+	for _, key := range []string{"a//b", "a/b"} {
+		resp, err := s.GetObject(ctx, "b", key)
+		require.NoError(t, err, key)
+		require.NoError(t, resp.Reader.Close())
+	}
+}
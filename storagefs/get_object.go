@@ -2,7 +2,7 @@ package storagefs
 
 import (
 	"context"
-	"os"
+	"io"
 	"path/filepath"
 
 	"github.com/go-faster/errors"
@@ -11,28 +11,17 @@ import (
 )
 
 func (s *Storage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObjectResponse, error) {
-	objectPath := filepath.Join(s.root, bucket, toOSPath(key))
-
-	// Check if bucket exists
-	bucketPath := filepath.Join(s.root, bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return nil, fs.ErrBucketNotFound
-	}
-
-	// #nosec G304 -- objectPath is constructed from validated bucket and key.
-	f, err := os.Open(objectPath)
-	if os.IsNotExist(err) {
+	if s.existence != nil && !s.existence.mayExist(bucket, s.existenceKey(key)) {
 		return nil, fs.ErrObjectNotFound
 	}
 
-	if err != nil {
-		return nil, errors.Wrap(err, "open object")
-	}
+	objectPath := filepath.Join(s.root, bucket, s.keyPath(key))
 
-	info, err := f.Stat()
+	// Open first and fstat the descriptor: the size and mtime describe exactly
+	// the file being served even if the key is replaced concurrently.
+	f, info, err := s.openObject(bucket, key)
 	if err != nil {
-		_ = f.Close()
-		return nil, errors.Wrap(err, "stat object")
+		return nil, err
 	}
 
 	// Verify-on-read: recompute and check the checksum before serving so corrupt
@@ -51,9 +40,29 @@ func (s *Storage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObj
 	}
 
 	// The sidecar carries the stored ETag and metadata; files without one
-	// (pre-sidecar data directories) fall back to recompute-on-read.
-	sc, err := s.readSidecar(bucket, key)
+	// (pre-sidecar data directories) fall back to recompute-on-read. So does
+	// an object whose sidecar is corrupt or unreadable, flagged as such: the
+	// body is intact and worth serving. Unless bodies may be encrypted, whose
+	// keys only the sidecar records.
+	sc, err := s.loadSidecar(bucket, key)
 	if err != nil {
+		if s.sealer != nil {
+			_ = f.Close()
+			return nil, errors.Wrapf(fs.ErrIntegrity, "%s/%s: metadata unavailable: %v", bucket, key, err)
+		}
+
+		sc = nil
+		resp.MetadataMissing = true
+	}
+
+	// Without metadata an encrypted body cannot be opened, and its
+	// ciphertext must not pass for the object.
+	if sc == nil && sealedOnDisk(f) {
+		_ = f.Close()
+		return nil, errors.Wrapf(fs.ErrIntegrity, "%s/%s: encrypted body without metadata", bucket, key)
+	}
+
+	if err := s.checkStoredSize(bucket, key, sc, f, info); err != nil {
 		_ = f.Close()
 		return nil, err
 	}
@@ -61,6 +70,37 @@ func (s *Storage) GetObject(ctx context.Context, bucket, key string) (*fs.GetObj
 	if sc != nil {
 		resp.ETag = sc.ETag
 		resp.Metadata = sc.metadata()
+		resp.LastModified = sc.lastModified(info)
+		resp.PartSizes = sc.Parts
+	}
+
+	expected, ok := sc.contentChecksum()
+
+	var body io.ReadSeekCloser = f
+	if s.readAhead {
+		body = &readAheadReader{f: f}
+		resp.Reader = body
+	}
+
+	switch {
+	case sc != nil && sc.Encryption != nil:
+		// GCM authenticates every segment as it is read, which subsumes
+		// streaming verification of the checksum.
+		d, err := s.openEncrypted(ctx, f, bucket, key, sc.Encryption)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		resp.Reader = d
+		resp.Size = d.Size()
+		resp.ServerSideEncryption = sseFor(sc.Encryption)
+		resp.SSECustomerKeyMD5 = customerKeyMD5(sc.Encryption)
+	case fs.CustomerKeyFromContext(ctx) != nil:
+		_ = f.Close()
+		return nil, checkCustomerKey(ctx, nil)
+	case ok && s.verifyStream:
+		resp.Reader = s.newVerifyingReader(body, bucket, key, expected, info.Size())
 	}
 
 	if resp.ETag == "" {
@@ -2,24 +2,111 @@ package storagefs
 
 import (
 	"context"
+	iofs "io/fs"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
 )
 
-// ListObjects lists all objects in bucket by prefix.
+// WithConsistentListings makes each ListObjects a point-in-time view: the walk
+// holds its bucket's listing lock shared, and every write, delete and
+// multipart completion takes it exclusively to land its change, so none lands
+// mid-walk and a listing never mixes states (an object added late in the walk
+// next to one already deleted). Listings run concurrently with each other and
+// with writes to other buckets. Uploads still stream while a listing runs;
+// only their final rename waits for it, so a listing of a large bucket delays
+// writes to that bucket for as long as it takes.
+func WithConsistentListings() Option {
+	return func(s *Storage) { s.consistentListings = true }
+}
+
+// listLock returns bucket's listing lock, creating it on first use.
+func (s *Storage) listLock(bucket string) *sync.RWMutex {
+	s.listLocksMu.Lock()
+	defer s.listLocksMu.Unlock()
+
+	if s.listLocks == nil {
+		s.listLocks = make(map[string]*sync.RWMutex)
+	}
+
+	l, ok := s.listLocks[bucket]
+	if !ok {
+		l = new(sync.RWMutex)
+		s.listLocks[bucket] = l
+	}
+
+	return l
+}
+
+// lockListings holds bucket's listing lock exclusively while a write lands
+// its change, returning the unlock; a no-op without WithConsistentListings.
+// It is taken before putMu, so a write waiting for a walk does not hold up
+// writes to other buckets.
+func (s *Storage) lockListings(bucket string) func() {
+	if !s.consistentListings {
+		return func() {}
+	}
+
+	l := s.listLock(bucket)
+	l.Lock()
+
+	return l.Unlock
+}
+
+// ListObjects lists all objects in bucket by prefix. A missing bucket is
+// fs.ErrBucketNotFound, checked before the walk, while an existing empty one
+// lists nothing.
 //
 // NB: bucket and prefix are already sanitized.
 func (s *Storage) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.Object, error) {
-	bucketPath := filepath.Join(s.root, bucket)
-
 	var objects []fs.Object
 
-	err := filepath.Walk(bucketPath, func(path string, info os.FileInfo, err error) error {
+	for o, err := range s.WalkObjects(ctx, bucket, prefix) {
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, o)
+	}
+
+	return objects, nil
+}
+
+// WalkObjects yields the objects of bucket under prefix in walk order, as
+// ListObjects lists them but without building the listing. With
+// WithConsistentListings the bucket's listing lock is held until the sequence
+// ends, so the caller must not write to the bucket while it ranges.
+//
+// NB: bucket and prefix are already sanitized.
+func (s *Storage) WalkObjects(ctx context.Context, bucket, prefix string) iter.Seq2[fs.Object, error] {
+	return func(yield func(fs.Object, error) bool) {
+		if err := s.walkObjects(ctx, bucket, prefix, yield); err != nil {
+			yield(fs.Object{}, err)
+		}
+	}
+}
+
+// walkObjects walks bucket, handing each object under prefix to yield until
+// it returns false.
+func (s *Storage) walkObjects(ctx context.Context, bucket, prefix string, yield func(fs.Object, error) bool) error {
+	if s.consistentListings {
+		l := s.listLock(bucket)
+		l.RLock()
+		defer l.RUnlock()
+	}
+
+	bucketPath, err := s.bucketDir(bucket)
+	if err != nil {
+		return err
+	}
+
+	err = filepath.WalkDir(bucketPath, func(path string, d iofs.DirEntry, err error) error {
 		select {
 		case <-ctx.Done():
 			// Stop walking on context done.
@@ -28,14 +115,28 @@ func (s *Storage) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.
 		}
 
 		if os.IsNotExist(err) {
-			return fs.ErrBucketNotFound
+			if path == bucketPath {
+				// Deleted since the check above.
+				return fs.ErrBucketNotFound
+			}
+
+			// Removed since its directory was read (a concurrent delete
+			// pruning empty directories): nothing left to list there.
+			return nil
 		}
 
 		if err != nil {
 			return errors.Wrap(err, "walk objects")
 		}
 
-		if info.IsDir() {
+		if d.IsDir() {
+			return nil
+		}
+
+		// Only regular files are objects. WalkDir never descends into a
+		// symlinked directory, and a symlinked file is skipped here, so a
+		// planted link cannot surface content from outside the bucket.
+		if !d.Type().IsRegular() {
 			return nil
 		}
 
@@ -44,28 +145,41 @@ func (s *Storage) ListObjects(ctx context.Context, bucket, prefix string) ([]fs.
 			return errors.Wrap(err, "determine relative path")
 		}
 
-		// Convert to forward slashes for S3 compatibility.
-		key := filepath.ToSlash(relPath)
+		key, ok := s.pathKey(path, relPath)
+		if !ok {
+			return nil
+		}
 
 		if prefix == "" || strings.HasPrefix(key, prefix) {
-			etag, err := s.objectETag(bucket, key, path, info)
+			info, err := d.Info()
+			if os.IsNotExist(err) {
+				return nil // removed since the directory was read
+			}
+
+			if err != nil {
+				return errors.Wrap(err, "stat object")
+			}
+
+			st, err := s.objectStat(bucket, key, path, info)
 			if err != nil {
 				return errors.Wrap(err, "etag")
 			}
 
-			objects = append(objects, fs.Object{
+			if !yield(fs.Object{
 				Key:          key,
-				Size:         info.Size(),
-				LastModified: info.ModTime(),
-				ETag:         etag,
-			})
+				Size:         st.size,
+				LastModified: st.modified,
+				ETag:         st.etag,
+			}, nil) {
+				return filepath.SkipAll
+			}
 		}
 
 		return nil
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "list objects")
+		return errors.Wrap(err, "list objects")
 	}
 
-	return objects, nil
+	return nil
 }
@@ -0,0 +1,127 @@
+package storagefs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestWithConsistentListings(t *testing.T) {
+	t.Parallel()
+
+	const window = 64
+
+	s, err := New(t.TempDir(), WithConsistentListings())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	// Objects are spread over directories, so a walk reads them at
+	// different times.
+	key := func(i int) string { return fmt.Sprintf("d%d/%06d", i%8, i) }
+
+	for i := range window {
+		putContent(t, s, "b", key(i), []byte("x"))
+	}
+
+	// The writer slides the window forward: add the next object, then drop
+	// the oldest. At any one moment the bucket holds a contiguous run of
+	// window or window+1 objects.
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var (
+		wg       sync.WaitGroup
+		writeErr error
+	)
+
+	wg.Go(func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			if _, err := s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: "b", Key: key(window + i), Reader: bytes.NewReader([]byte("x")), Size: 1,
+			}); err != nil {
+				writeErr = err
+				return
+			}
+
+			if err := s.DeleteObject(ctx, "b", key(i)); err != nil {
+				writeErr = err
+				return
+			}
+		}
+	})
+
+	for range 50 {
+		objects, err := s.ListObjects(t.Context(), "b", "")
+		require.NoError(t, err)
+
+		idx := make([]int, 0, len(objects))
+		for _, o := range objects {
+			n, err := strconv.Atoi(o.Key[strings.IndexByte(o.Key, '/')+1:])
+			require.NoError(t, err)
+
+			idx = append(idx, n)
+		}
+
+		slices.Sort(idx)
+
+		require.Contains(t, []int{window, window + 1}, len(idx), "listing %v", idx)
+		require.Equal(t, idx[0]+len(idx)-1, idx[len(idx)-1], "listing %v is not one point in time", idx)
+	}
+
+	cancel()
+	wg.Wait()
+
+	if writeErr != nil {
+		require.ErrorIs(t, writeErr, context.Canceled)
+	}
+}
+
+func TestWithConsistentListings_PerBucket(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(t.TempDir(), WithConsistentListings())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "a"))
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	// A walk of bucket a is in progress.
+	walk := s.listLock("a")
+	walk.RLock()
+
+	// Other listings of a, and writes to other buckets, go ahead.
+	_, err = s.ListObjects(t.Context(), "a", "")
+	require.NoError(t, err)
+	putContent(t, s, "b", "key", []byte("x"))
+
+	// A write to a waits for the walk.
+	var putErr error
+
+	landed := make(chan struct{})
+
+	go func() {
+		defer close(landed)
+
+		_, putErr = s.PutObject(t.Context(), &fs.PutObjectRequest{
+			Bucket: "a", Key: "key", Reader: bytes.NewReader([]byte("x")), Size: 1,
+		})
+	}()
+
+	select {
+	case <-landed:
+		t.Fatal("write to a landed during its listing")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	walk.RUnlock()
+	<-landed
+	require.NoError(t, putErr)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/go-faster/errors"
 
@@ -40,6 +41,44 @@ type sidecar struct {
 	// and verify-on-read for bit-rot detection. Distinct from ETag, which for a
 	// multipart object is the "-N" composite, not a content hash.
 	Checksum string `json:"checksum,omitempty"`
+	// Content is the hex SHA-256 of the body when it is a link into the
+	// content store (WithDedup); releasing the object releases this entry.
+	Content string `json:"content,omitempty"`
+	// Modified overrides the file mtime as the object's LastModified. It is
+	// recorded for content-store links, whose inode (and mtime) is shared.
+	Modified time.Time `json:"modified,omitzero"`
+	// Encryption describes how the body is sealed (WithEncryptionKey); nil
+	// for plaintext. Checksum then covers the ciphertext, ETag the plaintext.
+	Encryption *encryptionInfo `json:"encryption,omitempty"`
+	// Parts are the plaintext sizes of the parts a multipart object was
+	// completed from, in order; empty for a single PUT.
+	Parts []int64 `json:"parts,omitempty"`
+	// LegalHold refuses overwrites and deletes of the object while set.
+	LegalHold bool `json:"legal_hold,omitempty"`
+	// Stored is the size of the object file as written (the ciphertext for
+	// an encrypted body), checked against the file on every GetObject; zero
+	// for objects written before it was recorded.
+	Stored int64 `json:"stored,omitempty"`
+}
+
+// size returns the object's length: the file size, or for an encrypted body
+// the plaintext size it seals. sc may be nil.
+func (sc *sidecar) size(info os.FileInfo) int64 {
+	if sc != nil && sc.Encryption != nil {
+		return plaintextSize(info.Size() - int64(len(sealedMagic)))
+	}
+
+	return info.Size()
+}
+
+// lastModified returns the object's LastModified: the recorded time when
+// present, otherwise the file's mtime. sc may be nil.
+func (sc *sidecar) lastModified(info os.FileInfo) time.Time {
+	if sc != nil && !sc.Modified.IsZero() {
+		return sc.Modified
+	}
+
+	return info.ModTime()
 }
 
 // metadata converts the sidecar's header fields to the domain type.
@@ -79,8 +118,32 @@ func (s *Storage) sidecarPath(bucket, key string) string {
 	return filepath.Join(s.root, metaDir, bucket, hex.EncodeToString(sum[:])+".json")
 }
 
-// readSidecar loads an object's sidecar. A missing sidecar returns (nil, nil).
+// readSidecar loads an object's metadata document: from its extended
+// attribute under MetadataXattr, otherwise (or when there is none) from the
+// sidecar file. Missing metadata returns (nil, nil).
 func (s *Storage) readSidecar(bucket, key string) (*sidecar, error) {
+	sc, err := s.loadSidecar(bucket, key)
+	if errors.Is(err, errCorruptSidecar) {
+		// A corrupt sidecar must not make the object unreadable; treat it as
+		// absent (defaults + ETag recompute).
+		return nil, nil
+	}
+
+	return sc, err
+}
+
+// errCorruptSidecar reports a metadata document that does not decode.
+var errCorruptSidecar = errors.New("corrupt metadata record")
+
+// loadSidecar is readSidecar reporting a corrupt document as
+// errCorruptSidecar instead of as absent, for GetObject to flag.
+func (s *Storage) loadSidecar(bucket, key string) (*sidecar, error) {
+	if s.metaStore == MetadataXattr {
+		if sc, ok := s.readMetaXattr(bucket, key); ok {
+			return sc, nil
+		}
+	}
+
 	data, err := os.ReadFile(s.sidecarPath(bucket, key)) //nolint:gosec // Path is derived from a hash of the key.
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -92,29 +155,55 @@ func (s *Storage) readSidecar(bucket, key string) (*sidecar, error) {
 
 	var sc sidecar
 	if err := json.Unmarshal(data, &sc); err != nil {
-		// A corrupt sidecar must not make the object unreadable; treat it as
-		// absent (defaults + ETag recompute).
-		return nil, nil //nolint:nilerr // Deliberate: degrade to sidecar-less behavior.
+		return nil, errors.Wrapf(errCorruptSidecar, "decode sidecar: %v", err)
 	}
 
 	return &sc, nil
 }
 
-// writeSidecar persists an object's sidecar atomically (temp file + rename),
-// with fsync per the storage's sync policy.
+// writeSidecar persists an object's metadata document, with fsync per the
+// storage's sync policy. Under MetadataXattr it goes into the object's
+// extended attribute (one setxattr, so atomic); otherwise, or when the
+// attribute cannot be set, into the sidecar file (temp file + rename).
 func (s *Storage) writeSidecar(bucket string, sc *sidecar) error {
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return errors.Wrap(err, "marshal sidecar")
+	}
+
+	if s.metaStore == MetadataXattr {
+		if s.writeMetaXattr(bucket, sc.Key, data) == nil {
+			// A sidecar from before the switch, or from an earlier fallback,
+			// is stale now.
+			s.deleteSidecar(bucket, sc.Key)
+			return nil
+		}
+
+		// Too large for the filesystem's attribute limit, or not a regular
+		// file: keep a sidecar, and drop any older attribute, which reads
+		// would otherwise prefer.
+		_ = removeXattr(s.objectFilePath(bucket, sc.Key), xattrName)
+	}
+
 	path := s.sidecarPath(bucket, sc.Key)
 
 	if err := os.MkdirAll(filepath.Dir(path), defaultDirPermissions); err != nil {
 		return errors.Wrap(err, "create sidecar directory")
 	}
 
-	data, err := json.Marshal(sc)
-	if err != nil {
-		return errors.Wrap(err, "marshal sidecar")
+	return s.atomicWrite(path, data)
+}
+
+// commitSidecar completes the metadata of an object just renamed into place:
+// with the document staged in its attribute (stageMetaXattr) only a stale
+// sidecar is left to drop, otherwise the document is written now.
+func (s *Storage) commitSidecar(bucket string, sc *sidecar, staged bool) error {
+	if staged {
+		s.deleteSidecar(bucket, sc.Key)
+		return nil
 	}
 
-	return s.atomicWrite(path, data)
+	return s.writeSidecar(bucket, sc)
 }
 
 // deleteSidecar removes an object's sidecar; a missing sidecar is fine.
@@ -130,9 +219,36 @@ func (s *Storage) deleteBucketMeta(bucket string) {
 // objectETag resolves an object's ETag, preferring the sidecar's stored value
 // and falling back to (cached) recompute-on-read for sidecar-less files.
 func (s *Storage) objectETag(bucket, key, path string, info os.FileInfo) (string, error) {
-	if sc, err := s.readSidecar(bucket, key); err == nil && sc != nil && sc.ETag != "" {
-		return sc.ETag, nil
+	st, err := s.objectStat(bucket, key, path, info)
+
+	return st.etag, err
+}
+
+// objectAttrs is what listings report for an object.
+type objectAttrs struct {
+	etag     string
+	modified time.Time
+	size     int64
+}
+
+// objectStat resolves an object's ETag, LastModified and size from one sidecar
+// read, falling back to recompute-on-read and the file mtime and size.
+func (s *Storage) objectStat(bucket, key, path string, info os.FileInfo) (objectAttrs, error) {
+	sc, err := s.readSidecar(bucket, key)
+	if err != nil {
+		sc = nil
+	}
+
+	st := objectAttrs{size: sc.size(info), modified: sc.lastModified(info)}
+
+	if sc != nil && sc.ETag != "" {
+		st.etag = sc.ETag
+		return st, nil
+	}
+
+	if st.etag, err = s.etagFor(path, info); err != nil {
+		return objectAttrs{}, err
 	}
 
-	return s.etagFor(path, info)
+	return st, nil
 }
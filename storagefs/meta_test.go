@@ -89,6 +89,83 @@ func TestCorruptSidecarTolerated(t *testing.T) {
 	require.Equal(t, content, data)
 	// Metadata is lost but the ETag falls back to recompute.
 	require.Equal(t, fmt.Sprintf("%x", md5.Sum(content)), obj.ETag) //nolint:gosec // MD5 is required for S3 ETag compatibility.
+	require.True(t, obj.MetadataMissing)
+	require.Empty(t, obj.Metadata.ContentType)
+}
+
+// TestUnavailableSidecarServed covers the other ways an object can lose its
+// metadata record: the body is still served with defaults, flagged only when
+// a record exists but cannot be read.
+func TestUnavailableSidecarServed(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("content")
+
+	for _, tt := range []struct {
+		name    string
+		damage  func(path string) error
+		missing bool
+	}{
+		{name: "Deleted", damage: os.Remove},
+		{
+			name: "Unreadable", missing: true,
+			damage: func(path string) error {
+				// Reading a directory fails, as an I/O error would.
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+
+				return os.Mkdir(path, 0o700)
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(t.TempDir())
+			require.NoError(t, err)
+
+			ctx := t.Context()
+			require.NoError(t, s.CreateBucket(ctx, "bucket-a"))
+
+			_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: "bucket-a", Key: "obj.txt", Reader: bytes.NewReader(content), Size: int64(len(content)),
+				Metadata: fs.ObjectMetadata{ContentType: "text/plain"},
+			})
+			require.NoError(t, err)
+			require.NoError(t, tt.damage(s.sidecarPath("bucket-a", "obj.txt")))
+
+			obj, err := s.GetObject(ctx, "bucket-a", "obj.txt")
+			require.NoError(t, err)
+
+			defer func() { _ = obj.Reader.Close() }()
+
+			data, err := io.ReadAll(obj.Reader)
+			require.NoError(t, err)
+			require.Equal(t, content, data)
+			require.EqualValues(t, len(content), obj.Size)
+			require.Empty(t, obj.Metadata.ContentType)
+			require.Equal(t, tt.missing, obj.MetadataMissing)
+		})
+	}
+}
+
+// TestUnavailableSidecarEncrypted guards that an encrypting store never
+// serves a body it cannot tell is plaintext.
+func TestUnavailableSidecarEncrypted(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(t.TempDir(), WithEncryptionKey(testEncryptionKey(1)))
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "bucket-a"))
+
+	putContent(t, s, "bucket-a", "obj.txt", []byte("secret"))
+	require.NoError(t, os.WriteFile(s.sidecarPath("bucket-a", "obj.txt"), []byte("{not json"), 0o600))
+
+	_, err = s.GetObject(ctx, "bucket-a", "obj.txt")
+	require.ErrorIs(t, err, fs.ErrIntegrity)
 }
 
 // TestMetaDirHiddenFromBuckets guards that the sidecar tree never shows up as
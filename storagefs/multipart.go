@@ -3,13 +3,16 @@ package storagefs
 import (
 	"context"
 	"crypto/md5" //nolint:gosec // MD5 is required for S3 ETag compatibility.
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,17 +38,82 @@ type multipartMetadata struct {
 	Metadata fs.ObjectMetadata `json:"metadata,omitzero"`
 	Tags     []fs.Tag          `json:"tags,omitempty"`
 	ACL      fs.ACL            `json:"acl,omitempty"`
+	// EncryptionKeyID, when set, is the key the parts are sealed under (see
+	// WithEncryptionKey); uploads started without one stay plaintext.
+	EncryptionKeyID string `json:"encryption_key_id,omitempty"`
 }
 
 // multipartManager manages multipart uploads with disk-based persistence.
 type multipartManager struct {
 	mu   sync.RWMutex
 	root string
+	// locks holds a lock per upload in use: UploadPart holds one shared for
+	// as long as a part is being written, CompleteMultipartUpload and
+	// AbortMultipartUpload hold it exclusively, so an upload is never
+	// assembled or removed around a part still in flight. Parts of the same
+	// upload write in parallel and other uploads never wait. Entries are
+	// reference counted and dropped once no caller holds or waits on them.
+	locksMu sync.Mutex
+	locks   map[string]*uploadLock
+}
+
+// uploadLock is the lock of one upload and the number of callers using it.
+type uploadLock struct {
+	sync.RWMutex
+	refs int
+}
+
+// lockUpload locks uploadID exclusively and returns its unlock. Take it
+// before mu.
+func (m *multipartManager) lockUpload(uploadID string) (unlock func()) {
+	l := m.acquireUpload(uploadID)
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+		m.releaseUpload(uploadID, l)
+	}
+}
+
+// rlockUpload locks uploadID shared and returns its unlock. Take it before
+// mu.
+func (m *multipartManager) rlockUpload(uploadID string) (unlock func()) {
+	l := m.acquireUpload(uploadID)
+	l.RLock()
+
+	return func() {
+		l.RUnlock()
+		m.releaseUpload(uploadID, l)
+	}
+}
+
+func (m *multipartManager) acquireUpload(uploadID string) *uploadLock {
+	m.locksMu.Lock()
+	defer m.locksMu.Unlock()
+
+	l, ok := m.locks[uploadID]
+	if !ok {
+		l = &uploadLock{}
+		m.locks[uploadID] = l
+	}
+	l.refs++
+
+	return l
+}
+
+func (m *multipartManager) releaseUpload(uploadID string, l *uploadLock) {
+	m.locksMu.Lock()
+	defer m.locksMu.Unlock()
+
+	if l.refs--; l.refs == 0 {
+		delete(m.locks, uploadID)
+	}
 }
 
 func newMultipartManager(root string) *multipartManager {
 	return &multipartManager{
-		root: root,
+		root:  root,
+		locks: make(map[string]*uploadLock),
 	}
 }
 
@@ -107,11 +175,27 @@ func (m *multipartManager) deleteUpload(uploadID string) error {
 	return os.RemoveAll(uploadPath)
 }
 
-func (s *Storage) CreateMultipartUpload(_ context.Context, req *fs.CreateMultipartUploadRequest) (*fs.MultipartUpload, error) {
+func (s *Storage) CreateMultipartUpload(ctx context.Context, req *fs.CreateMultipartUploadRequest) (*fs.MultipartUpload, error) {
 	// Verify bucket exists.
-	bucketPath := filepath.Join(s.root, req.Bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return nil, fs.ErrBucketNotFound
+	bucketPath, err := s.bucketDir(req.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail at initiation rather than after every part has been uploaded.
+	if err := s.checkKeySegments(req.Key); err != nil {
+		return nil, err
+	}
+
+	if s.strictPrefixes {
+		objectPath := filepath.Join(bucketPath, s.keyPath(req.Key))
+		if err := checkPrefixExists(bucketPath, filepath.Dir(objectPath)); err != nil {
+			return nil, err
+		}
+	}
+
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "multipart uploads with customer-provided encryption keys")
 	}
 
 	uploadID := uuid.New().String()
@@ -131,6 +215,10 @@ func (s *Storage) CreateMultipartUpload(_ context.Context, req *fs.CreateMultipa
 		ACL:       req.ACL,
 	}
 
+	if s.sealer != nil {
+		meta.EncryptionKeyID = s.sealer.id
+	}
+
 	s.multipart.mu.Lock()
 	defer s.multipart.mu.Unlock()
 
@@ -147,38 +235,88 @@ func (s *Storage) CreateMultipartUpload(_ context.Context, req *fs.CreateMultipa
 	}, nil
 }
 
-func (s *Storage) UploadPart(_ context.Context, req *fs.UploadPartRequest) (*fs.Part, error) {
+func (s *Storage) UploadPart(ctx context.Context, req *fs.UploadPartRequest) (*fs.Part, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "multipart uploads with customer-provided encryption keys")
+	}
+
+	// Completion and abort wait for this part; a part started after either
+	// finds the upload gone.
+	unlock := s.multipart.rlockUpload(req.UploadID)
+	defer unlock()
+
 	s.multipart.mu.RLock()
-	_, err := s.multipart.loadMetadata(req.UploadID)
+	meta, err := s.multipart.loadMetadata(req.UploadID)
 	s.multipart.mu.RUnlock()
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Write part to file.
+	if meta.EncryptionKeyID != "" && (s.sealer == nil || s.sealer.id != meta.EncryptionKeyID) {
+		return nil, errors.Errorf("upload is encrypted with key %s, which is not configured", meta.EncryptionKeyID)
+	}
+
+	// Stream the part to its own upload temp and rename it into the upload
+	// directory under its number, so parallel parts never share a file and a
+	// re-upload of the same number replaces it atomically.
 	partPath := filepath.Join(s.multipart.uploadPath(req.UploadID), strconv.Itoa(req.PartNumber))
 
-	f, err := os.Create(partPath) //nolint:gosec // Path is constructed internally from validated uploadID and partNumber.
+	f, err := s.newUploadTemp()
 	if err != nil {
 		return nil, errors.Wrap(err, "create part file")
 	}
 
+	fail := func(err error) (*fs.Part, error) {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return nil, err
+	}
+
+	// The part ETag is the MD5 of what the client sent, encrypted or not.
+	var (
+		dst io.Writer = f
+		enc *encryptWriter
+	)
+
+	if meta.EncryptionKeyID != "" {
+		if enc, err = s.newPartWriter(f); err != nil {
+			return fail(err)
+		}
+
+		dst = enc
+	}
+
 	hash := md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
-	writer := io.MultiWriter(f, hash)
+	writer := io.MultiWriter(dst, hash)
 
-	size, err := io.Copy(writer, req.Reader)
+	size, err := io.Copy(writer, contextReader{ctx: ctx, r: req.Reader})
 	if err != nil {
-		_ = f.Close()
-		_ = os.Remove(partPath)
+		return fail(errors.Wrap(err, "write part"))
+	}
 
-		return nil, errors.Wrap(err, "write part")
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			return fail(err)
+		}
 	}
 
 	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
 		return nil, errors.Wrap(err, "close part file")
 	}
 
+	tmpName, err := s.settleUpload(f.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmpName, partPath); err != nil {
+		_ = os.Remove(tmpName)
+		return nil, errors.Wrap(err, "rename part file")
+	}
+
 	etag := hex.EncodeToString(hash.Sum(nil))
 
 	part := &fs.Part{
@@ -226,7 +364,7 @@ func (s *Storage) ListParts(_ context.Context, bucket, key, uploadID string) ([]
 			continue
 		}
 
-		part, err := statPart(filepath.Join(uploadPath, entry.Name()), partNumber)
+		part, err := s.statPart(filepath.Join(uploadPath, entry.Name()), partNumber, meta.EncryptionKeyID)
 		if err != nil {
 			return nil, err
 		}
@@ -241,8 +379,9 @@ func (s *Storage) ListParts(_ context.Context, bucket, key, uploadID string) ([]
 	return parts, nil
 }
 
-// statPart reads a stored part file's size, modification time, and MD5 ETag.
-func statPart(path string, partNumber int) (fs.Part, error) {
+// statPart reads a stored part file's size, modification time, and MD5 ETag;
+// for a part sealed under keyID, those of its plaintext.
+func (s *Storage) statPart(path string, partNumber int, keyID string) (fs.Part, error) {
 	f, err := os.Open(path) //nolint:gosec // Path is constructed internally from validated uploadID and partNumber.
 	if err != nil {
 		return fs.Part{}, errors.Wrapf(err, "open part %d", partNumber)
@@ -254,23 +393,36 @@ func statPart(path string, partNumber int) (fs.Part, error) {
 		return fs.Part{}, errors.Wrapf(err, "stat part %d", partNumber)
 	}
 
+	var (
+		r    io.Reader = f
+		size           = info.Size()
+	)
+
+	if keyID != "" {
+		d, err := s.openPart(f, keyID)
+		if err != nil {
+			return fs.Part{}, errors.Wrapf(err, "open part %d", partNumber)
+		}
+
+		r, size = d, d.Size()
+	}
+
 	hash := md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
-	if _, err := io.Copy(hash, f); err != nil {
+	if _, err := io.Copy(hash, r); err != nil {
 		return fs.Part{}, errors.Wrapf(err, "hash part %d", partNumber)
 	}
 
 	return fs.Part{
 		PartNumber:   partNumber,
 		ETag:         hex.EncodeToString(hash.Sum(nil)),
-		Size:         info.Size(),
+		Size:         size,
 		LastModified: info.ModTime(),
 	}, nil
 }
 
 func (s *Storage) ListMultipartUploads(_ context.Context, bucket string) ([]fs.MultipartUpload, error) {
-	bucketPath := filepath.Join(s.root, bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return nil, fs.ErrBucketNotFound
+	if _, err := s.bucketDir(bucket); err != nil {
+		return nil, err
 	}
 
 	s.multipart.mu.RLock()
@@ -323,6 +475,9 @@ func (s *Storage) ListMultipartUploads(_ context.Context, bucket string) ([]fs.M
 }
 
 func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMultipartUploadRequest) (*fs.CompleteMultipartUploadResponse, error) {
+	unlock := s.multipart.lockUpload(req.UploadID)
+	defer unlock()
+
 	s.multipart.mu.Lock()
 	defer s.multipart.mu.Unlock()
 
@@ -331,6 +486,14 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 		return nil, err
 	}
 
+	if req.StoreAs != "" {
+		if err := s.checkKeySegments(req.StoreAs); err != nil {
+			return nil, err
+		}
+
+		meta.Key = req.StoreAs
+	}
+
 	// Sort parts by part number.
 	parts := make([]fs.CompletedPart, len(req.Parts))
 	copy(parts, req.Parts)
@@ -339,12 +502,16 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 	})
 
 	// Create the final object path.
-	objectPath := filepath.Join(s.root, meta.Bucket, toOSPath(meta.Key))
+	objectPath := filepath.Join(s.root, meta.Bucket, s.keyPath(meta.Key))
 
 	// Ensure parent directory exists.
 	objectDir := filepath.Dir(objectPath)
-	if err := os.MkdirAll(objectDir, 0750); err != nil {
-		return nil, errors.Wrap(err, "create object directory")
+	if err := s.ensureObjectDir(filepath.Join(s.root, meta.Bucket), objectPath); err != nil {
+		return nil, err
+	}
+
+	if err := s.writeKeyIndex(objectPath, meta.Key); err != nil {
+		return nil, err
 	}
 
 	// Assemble into a staging temp file, then rename into place so a partially
@@ -362,23 +529,81 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 	}
 
 	// Concatenate all parts. hash accumulates the S3 multipart ETag (over the
-	// per-part MD5s); contentHash is the MD5 of the full assembled content, used
-	// for bit-rot detection.
+	// per-part MD5s); contentHash is the MD5 of the full assembled content as
+	// stored, used for bit-rot detection; with dedup, content is its SHA-256
+	// content-store key. An encrypted upload's parts are opened and the whole
+	// body sealed again under a nonce of its own.
+	var content hash.Hash
+	if s.dedup {
+		content = sha256.New()
+	}
+
 	hash := md5.New()        //nolint:gosec // MD5 is required for S3 ETag compatibility.
 	contentHash := md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
+	w := io.MultiWriter(finalFile, contentHash)
+
+	if content != nil {
+		w = io.MultiWriter(w, content)
+	}
+
+	var (
+		enc     *encryptWriter
+		encInfo *encryptionInfo
+	)
+
+	if meta.EncryptionKeyID != "" {
+		if s.sealer == nil || s.sealer.id != meta.EncryptionKeyID {
+			cleanup()
+			return nil, errors.Errorf("upload is encrypted with key %s, which is not configured", meta.EncryptionKeyID)
+		}
+
+		if encInfo, err = s.sealer.newInfo(); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		if err := writeSealedMagic(w); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		enc = newEncryptWriter(w, s.sealer, encInfo)
+		w = enc
+	}
 
 	uploadPath := s.multipart.uploadPath(req.UploadID)
+	partSizes := make([]int64, 0, len(parts))
+
 	for _, part := range parts {
 		partPath := filepath.Join(uploadPath, strconv.Itoa(part.PartNumber))
 
 		partFile, err := os.Open(partPath) //nolint:gosec // Path is constructed internally from validated uploadID and partNumber.
 		if err != nil {
 			cleanup()
+
+			if os.IsNotExist(err) {
+				return nil, errors.Wrapf(fs.ErrInvalidPart, "part %d", part.PartNumber)
+			}
+
 			return nil, errors.Wrapf(err, "open part %d", part.PartNumber)
 		}
 
+		var src io.Reader = partFile
+		if meta.EncryptionKeyID != "" {
+			d, err := s.openPart(partFile, meta.EncryptionKeyID)
+			if err != nil {
+				_ = partFile.Close()
+
+				cleanup()
+
+				return nil, errors.Wrapf(err, "open part %d", part.PartNumber)
+			}
+
+			src = d
+		}
+
 		partHash := md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
-		_, err = io.Copy(io.MultiWriter(finalFile, partHash, contentHash), partFile)
+		n, err := io.Copy(io.MultiWriter(w, partHash), src)
 		_ = partFile.Close()
 
 		if err != nil {
@@ -386,7 +611,23 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 			return nil, errors.Wrapf(err, "copy part %d", part.PartNumber)
 		}
 
-		_, _ = hash.Write(partHash.Sum(nil))
+		// The part must still be the one the client listed: a re-upload of
+		// the same number since then would otherwise slip into the object.
+		sum := partHash.Sum(nil)
+		if hex.EncodeToString(sum) != strings.Trim(part.ETag, `"`) {
+			cleanup()
+			return nil, errors.Wrapf(fs.ErrInvalidPart, "part %d does not match its ETag", part.PartNumber)
+		}
+
+		_, _ = hash.Write(sum)
+		partSizes = append(partSizes, n)
+	}
+
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			cleanup()
+			return nil, err
+		}
 	}
 
 	if err := s.syncFile(finalFile); err != nil {
@@ -394,14 +635,75 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 		return nil, err
 	}
 
+	stored, err := finalFile.Stat()
+	if err != nil {
+		cleanup()
+		return nil, errors.Wrap(err, "stat final file")
+	}
+
 	if err := finalFile.Close(); err != nil {
 		_ = os.Remove(tmpName)
 		return nil, errors.Wrap(err, "close final file")
 	}
 
-	if err := os.Rename(tmpName, objectPath); err != nil {
+	etag := hex.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(parts))
+	checksum := hex.EncodeToString(contentHash.Sum(nil))
+
+	// Persist the multipart ETag, content checksum and the metadata captured at
+	// initiation.
+	sc := newSidecar(meta.Key, etag, checksum, meta.Metadata, meta.Tags, meta.ACL)
+	if content != nil {
+		sc.Content = contentDigest(content.Sum(nil))
+		sc.Modified = time.Now()
+	}
+
+	sc.Encryption = encInfo
+	sc.Parts = partSizes
+	sc.Stored = stored.Size()
+
+	staged, err := s.stageMetaXattr(tmpName, sc)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return nil, err
+	}
+
+	// Finalize under putMu, as PutObject does, so a legal hold placed
+	// meanwhile is seen.
+	defer s.lockListings(meta.Bucket)()
+
+	s.putMu.Lock()
+	defer s.putMu.Unlock()
+
+	if req.IfNoneMatch != "" || req.IfMatch != "" {
+		exists, currentETag, err := s.currentObjectState(meta.Bucket, meta.Key, objectPath)
+		if err != nil {
+			_ = os.Remove(tmpName)
+			return nil, err
+		}
+
+		if req.PreconditionFailed(exists, currentETag) {
+			_ = os.Remove(tmpName)
+			return nil, fs.ErrPreconditionFailed
+		}
+	}
+
+	prev, err := s.readSidecar(meta.Bucket, meta.Key)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return nil, err
+	}
+
+	if prev != nil && prev.LegalHold {
 		_ = os.Remove(tmpName)
-		return nil, errors.Wrap(err, "rename final object")
+		return nil, legalHoldError(meta.Bucket, meta.Key)
+	}
+
+	if s.existence != nil {
+		s.existence.add(meta.Bucket, s.existenceKey(meta.Key))
+	}
+
+	if err := s.placeObject(tmpName, objectPath, sc.Content); err != nil {
+		return nil, errors.Wrap(err, "place final object")
 	}
 
 	if err := s.syncDir(objectDir); err != nil {
@@ -413,24 +715,27 @@ func (s *Storage) CompleteMultipartUpload(_ context.Context, req *fs.CompleteMul
 		return nil, errors.Wrap(err, "cleanup upload")
 	}
 
-	etag := hex.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(parts))
-	checksum := hex.EncodeToString(contentHash.Sum(nil))
-
-	// Persist the multipart ETag, content checksum and the metadata captured at
-	// initiation.
-	if err := s.writeSidecar(meta.Bucket, newSidecar(meta.Key, etag, checksum, meta.Metadata, meta.Tags, meta.ACL)); err != nil {
+	if err := s.commitSidecar(meta.Bucket, sc, staged); err != nil {
 		return nil, err
 	}
 
+	if prev != nil {
+		s.releaseContent(prev.Content)
+	}
+
 	return &fs.CompleteMultipartUploadResponse{
-		Location: "/" + meta.Bucket + "/" + meta.Key,
-		Bucket:   meta.Bucket,
-		Key:      meta.Key,
-		ETag:     etag,
+		Location:             "/" + meta.Bucket + "/" + meta.Key,
+		Bucket:               meta.Bucket,
+		Key:                  meta.Key,
+		ETag:                 etag,
+		ServerSideEncryption: sseFor(encInfo),
 	}, nil
 }
 
 func (s *Storage) AbortMultipartUpload(_ context.Context, _, _, uploadID string) error {
+	unlock := s.multipart.lockUpload(uploadID)
+	defer unlock()
+
 	s.multipart.mu.Lock()
 	defer s.multipart.mu.Unlock()
 
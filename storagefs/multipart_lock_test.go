@@ -0,0 +1,43 @@
+package storagefs
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartManager_UploadLocks(t *testing.T) {
+	t.Parallel()
+
+	m := newMultipartManager(t.TempDir())
+
+	unlock := m.lockUpload("held")
+
+	// No other upload waits on the one held, however many there are.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := range 1024 {
+			m.lockUpload(strconv.Itoa(i))()
+			m.rlockUpload(strconv.Itoa(i))()
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("an unrelated upload waited on the held lock")
+	}
+
+	// Shared holders of one upload do not wait on each other.
+	runlock := m.rlockUpload("shared")
+	m.rlockUpload("shared")()
+	runlock()
+
+	unlock()
+	require.Empty(t, m.locks)
+}
@@ -3,9 +3,13 @@ package storagefs_test
 import (
 	"bytes"
 	"context"
+	"crypto/md5" //nolint:gosec // S3 part ETag.
+	"encoding/hex"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -85,6 +89,53 @@ func TestStorage_MultipartUpload_MetadataPersistence(t *testing.T) {
 	require.Contains(t, string(data), upload.UploadID)
 }
 
+func TestStorage_MultipartUpload_ListingsSurviveRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	storage, err := storagefs.New(root)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.CreateBucket(ctx, "test-bucket"))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "test-bucket", Key: "dir/test-key"})
+	require.NoError(t, err)
+
+	part, err := storage.UploadPart(ctx, &fs.UploadPartRequest{
+		Bucket:     "test-bucket",
+		Key:        "dir/test-key",
+		UploadID:   upload.UploadID,
+		PartNumber: 1,
+		Reader:     bytes.NewReader([]byte("part one")),
+		Size:       8,
+	})
+	require.NoError(t, err)
+
+	// Both listings are read from the staging area, so a fresh Storage over
+	// the same root sees the interrupted upload and can resume or abort it.
+	reopened, err := storagefs.New(root)
+	require.NoError(t, err)
+
+	uploads, err := reopened.ListMultipartUploads(ctx, "test-bucket")
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	require.Equal(t, upload.UploadID, uploads[0].UploadID)
+	require.Equal(t, "dir/test-key", uploads[0].Key)
+
+	parts, err := reopened.ListParts(ctx, "test-bucket", "dir/test-key", upload.UploadID)
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	require.Equal(t, part.ETag, parts[0].ETag)
+	require.Equal(t, int64(8), parts[0].Size)
+
+	require.NoError(t, reopened.AbortMultipartUpload(ctx, "test-bucket", "dir/test-key", upload.UploadID))
+
+	uploads, err = reopened.ListMultipartUploads(ctx, "test-bucket")
+	require.NoError(t, err)
+	require.Empty(t, uploads)
+}
+
 func TestStorage_MultipartUpload_NestedKey(t *testing.T) {
 	t.Parallel()
 
@@ -351,3 +402,73 @@ func TestStorage_MultipartUpload_OverwritePart(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, string(part1DataNew), string(buf[:n]))
 }
+
+func TestStorage_MultipartUpload_CompleteWaitsForParts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage, err := storagefs.New(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, storage.CreateBucket(ctx, "test-bucket"))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "test-bucket", Key: "file.txt"})
+	require.NoError(t, err)
+
+	partData := []byte("streamed part")
+	sum := md5.Sum(partData) //nolint:gosec // S3 part ETag.
+	etag := hex.EncodeToString(sum[:])
+
+	// A part still streaming holds the upload open.
+	pr, pw := io.Pipe()
+	uploaded := make(chan error, 1)
+
+	go func() {
+		_, err := storage.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket:     "test-bucket",
+			Key:        "file.txt",
+			UploadID:   upload.UploadID,
+			PartNumber: 1,
+			Reader:     pr,
+			Size:       int64(len(partData)),
+		})
+		uploaded <- err
+	}()
+
+	_, err = pw.Write(partData[:4])
+	require.NoError(t, err)
+
+	completed := make(chan error, 1)
+
+	go func() {
+		_, err := storage.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+			Bucket:   "test-bucket",
+			Key:      "file.txt",
+			UploadID: upload.UploadID,
+			Parts:    []fs.CompletedPart{{PartNumber: 1, ETag: etag}},
+		})
+		completed <- err
+	}()
+
+	select {
+	case err := <-completed:
+		t.Fatalf("complete returned during part upload: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = pw.Write(partData[4:])
+	require.NoError(t, err)
+	require.NoError(t, pw.Close())
+
+	require.NoError(t, <-uploaded)
+	require.NoError(t, <-completed)
+
+	obj, err := storage.GetObject(ctx, "test-bucket", "file.txt")
+	require.NoError(t, err)
+
+	defer obj.Reader.Close()
+
+	got, err := io.ReadAll(obj.Reader)
+	require.NoError(t, err)
+	require.Equal(t, partData, got)
+}
@@ -1,10 +1,290 @@
 package storagefs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// KeyEncoding selects how object keys map to file paths under their bucket
+// directory.
+type KeyEncoding int
+
+const (
+	// KeyPassthrough stores a key at the path it spells: "a/b.txt" is the file
+	// b.txt in directory a, so the data directory stays browsable. Keys that
+	// are not a valid path everywhere (Windows reserved names, trailing dots
+	// or spaces, characters such as ':' or '?') do not port between
+	// filesystems, and keys differing only in repeated or trailing slashes
+	// ("a//b" and "a/b") share one file. The default.
+	KeyPassthrough KeyEncoding = iota
+	// KeyPercent keeps the directory structure but percent-encodes, segment
+	// by segment, every byte some filesystem rejects or mangles, as well as
+	// empty and "." segments and Windows reserved names. Everyday keys keep
+	// their plain paths. A key still cannot name both an object and the
+	// directory of longer keys ("a" and "a/b"), and a case-insensitive
+	// filesystem still folds "A" and "a" together.
+	KeyPercent
+	// KeyHashed stores each object under the SHA-256 of its key, fanned out
+	// over 256 directories, with an index file next to it holding the key.
+	// Every key is stored faithfully, at the cost of an opaque data
+	// directory and one extra small read per object when listing. It cannot
+	// be combined with WithStrictPrefixes, which relies on key directories.
+	KeyHashed
 )
 
+// ParseKeyEncoding maps a config string to a KeyEncoding, defaulting an empty
+// value to KeyPassthrough.
+func ParseKeyEncoding(s string) (KeyEncoding, error) {
+	switch s {
+	case "passthrough", "":
+		return KeyPassthrough, nil
+	case "percent":
+		return KeyPercent, nil
+	case "hashed":
+		return KeyHashed, nil
+	default:
+		return KeyPassthrough, errors.Errorf("invalid key encoding %q (want passthrough, percent or hashed)", s)
+	}
+}
+
+func (e KeyEncoding) String() string {
+	switch e {
+	case KeyPercent:
+		return "percent"
+	case KeyHashed:
+		return "hashed"
+	default:
+		return "passthrough"
+	}
+}
+
+// WithKeyEncoding selects how keys map to file paths (default
+// KeyPassthrough). The encoding is not recorded in the data directory and
+// existing objects are not migrated: open a root with the encoding it was
+// written with.
+func WithKeyEncoding(e KeyEncoding) Option {
+	return func(s *Storage) { s.keyEncoding = e }
+}
+
+// keyIndexSuffix names the KeyHashed index file holding an object's key,
+// beside the object file.
+const keyIndexSuffix = ".key"
+
+// keyPath returns the path of key's object file relative to its bucket
+// directory, in native form and cleaned, so a key with a trailing slash
+// ("dir/") reads the same file its write created ("dir").
+func (s *Storage) keyPath(key string) string {
+	switch s.keyEncoding {
+	case KeyPercent:
+		segments := strings.Split(key, "/")
+		for i, segment := range segments {
+			segments[i] = encodeSegment(segment, s.caseSensitiveKeys)
+		}
+
+		return filepath.Join(segments...)
+	case KeyHashed:
+		sum := sha256.Sum256([]byte(key))
+		name := hex.EncodeToString(sum[:])
+
+		return filepath.Join(name[:2], name)
+	default:
+		return filepath.Clean(toOSPath(key))
+	}
+}
+
+// pathKey is the inverse of keyPath: it returns the key stored in the object
+// file at path, rel below its bucket directory. ok is false for a file that
+// is not an object under the encoding (a KeyHashed index, or a name that does
+// not decode).
+func (s *Storage) pathKey(path, rel string) (key string, ok bool) {
+	switch s.keyEncoding {
+	case KeyPercent:
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+		for i, segment := range segments {
+			decoded, ok := decodeSegment(segment)
+			if !ok {
+				return "", false
+			}
+
+			segments[i] = decoded
+		}
+
+		return strings.Join(segments, "/"), true
+	case KeyHashed:
+		if strings.HasSuffix(path, keyIndexSuffix) {
+			return "", false
+		}
+
+		// The index is trusted only when it hashes back to the file name, so
+		// a torn or stray index never surfaces a wrong key.
+		b, err := os.ReadFile(path + keyIndexSuffix) //nolint:gosec // Path is under the bucket directory.
+		if err != nil {
+			return "", false
+		}
+
+		key := string(b)
+		if s.keyPath(key) != rel {
+			return "", false
+		}
+
+		return key, true
+	default:
+		return filepath.ToSlash(rel), true
+	}
+}
+
+// writeKeyIndex records key beside its object file at objectPath under
+// KeyHashed, so listings can name the object. It runs before the object is
+// renamed into place, so a visible object always has its index; an index
+// left by a failed write is ignored, as listings start from object files.
+func (s *Storage) writeKeyIndex(objectPath, key string) error {
+	if s.keyEncoding != KeyHashed {
+		return nil
+	}
+
+	path := objectPath + keyIndexSuffix
+	if b, err := os.ReadFile(path); err == nil && string(b) == key { //nolint:gosec // Path is under the bucket directory.
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640) //nolint:gosec // Path is under the bucket directory.
+	if err != nil {
+		return errors.Wrap(err, "create key index")
+	}
+
+	if _, err := f.WriteString(key); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "write key index")
+	}
+
+	if err := s.syncFile(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "close key index")
+	}
+
+	return nil
+}
+
+// removeKeyIndex removes the KeyHashed index of the object file at
+// objectPath, once the object is gone.
+func (s *Storage) removeKeyIndex(objectPath string) {
+	if s.keyEncoding == KeyHashed {
+		_ = os.Remove(objectPath + keyIndexSuffix)
+	}
+}
+
+// encodeSegment percent-encodes one key segment for KeyPercent: '%' itself,
+// control bytes and the characters Windows forbids in names, a trailing dot
+// or space (Windows strips them), the first byte of a Windows reserved name,
+// and whole "." and ".." segments. An empty segment becomes a lone "%", which
+// no encoded name otherwise is. With foldSafe, uppercase ASCII letters and
+// non-ASCII bytes are escaped too (see WithCaseSensitiveKeys); escapes use
+// uppercase hex, so distinct names never differ only in case.
+func encodeSegment(segment string, foldSafe bool) string {
+	switch segment {
+	case "":
+		return "%"
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+
+		last := i == len(segment)-1
+		if c < 0x20 || c == 0x7f || strings.IndexByte(`"%*:<>?\|`, c) >= 0 ||
+			(last && (c == '.' || c == ' ')) ||
+			(i == 0 && windowsReserved(segment)) || (foldSafe && escapesCase(c)) {
+			const hexDigits = "0123456789ABCDEF"
+
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xF])
+
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// decodeSegment reverses encodeSegment.
+func decodeSegment(name string) (string, bool) {
+	if name == "%" {
+		return "", true
+	}
+
+	segment, err := url.PathUnescape(name)
+	if err != nil {
+		return "", false
+	}
+
+	return segment, true
+}
+
+// windowsReserved reports whether segment is a device name Windows refuses as
+// a file name, with or without an extension ("CON", "nul.txt", "COM1").
+func windowsReserved(segment string) bool {
+	base, _, _ := strings.Cut(segment, ".")
+	base = strings.ToUpper(strings.TrimRight(base, " "))
+
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '0' && base[3] <= '9'
+	}
+
+	return false
+}
+
+// maxPathSegment is the longest file name common filesystems accept (NAME_MAX
+// on Linux and macOS, the NTFS component limit). Each "/"-separated key
+// segment becomes one path component.
+const maxPathSegment = 255
+
+// checkKeySegments rejects keys with a segment the filesystem cannot store,
+// so the caller gets fs.ErrKeyTooLong rather than ENAMETOOLONG from deep inside
+// a write. Segments are measured as encoded; KeyHashed names are always short.
+func (s *Storage) checkKeySegments(key string) error {
+	if s.keyEncoding == KeyHashed {
+		return nil
+	}
+
+	for segment := range strings.SplitSeq(key, "/") {
+		if s.keyEncoding == KeyPercent {
+			segment = encodeSegment(segment, s.caseSensitiveKeys)
+		}
+
+		if len(segment) > maxPathSegment {
+			return errors.Wrapf(fs.ErrKeyTooLong, "key segment of %d bytes exceeds the %d-byte file name limit",
+				len(segment), maxPathSegment)
+		}
+	}
+
+	return nil
+}
+
 // toOSPath converts an S3-style key (using forward slashes) to a native OS path.
 // On Windows, this converts "path/to/file.txt" to "path\to\file.txt".
 // On Unix, forward slashes are already the separator, so it returns the key as-is.
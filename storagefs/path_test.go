@@ -1,10 +1,14 @@
 package storagefs
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
 )
 
 func TestToOSPath(t *testing.T) {
@@ -52,3 +56,178 @@ func TestToOSPath(t *testing.T) {
 		})
 	}
 }
+
+func TestPutObjectKeySegmentLimit(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	// A segment at the file name limit is stored.
+	atLimit := "dir/" + strings.Repeat("a", maxPathSegment)
+	putContent(t, s, "b", atLimit, []byte("x"))
+	require.Equal(t, []byte("x"), readContent(t, s, "b", atLimit))
+
+	// One byte more fails cleanly, before touching the filesystem.
+	for _, key := range []string{
+		strings.Repeat("a", maxPathSegment+1),
+		strings.Repeat("a", maxPathSegment+1) + "/leaf",
+	} {
+		_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: "b", Key: key, Reader: strings.NewReader("x"), Size: 1,
+		})
+		require.ErrorIs(t, err, fs.ErrKeyTooLong)
+
+		_, err = s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: key})
+		require.ErrorIs(t, err, fs.ErrKeyTooLong)
+	}
+
+	objects, err := s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+}
+
+// adversarialKeys are valid S3 keys that do not map one-to-one onto paths on
+// every filesystem.
+var adversarialKeys = []string{
+	"CON",
+	"dir/nul.txt",
+	"dir/com1",
+	"trailing-dot.",
+	"trailing-space ",
+	"colon:and?star*",
+	`quote"pipe|lt<gt>`,
+	"percent%41literal",
+	"tab\tnewline\n",
+	"double//slash",
+	"/leading-slash",
+	"unicode/ключ/日本.txt",
+	"dotfile/.hidden",
+	"ends-with-dot/.",
+}
+
+func TestKeyEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		encoding KeyEncoding
+		keys     []string
+	}{
+		// Passthrough holds what the local filesystem can spell, as before.
+		{KeyPassthrough, []string{"plain.txt", "dir/nested/file", "unicode/ключ/日本.txt"}},
+		{KeyPercent, adversarialKeys},
+		// Only hashed keys can also name an object and the directory of
+		// longer keys, or differ by a trailing slash.
+		{KeyHashed, append([]string{"a", "a/b", "a/", "a/b/"}, adversarialKeys...)},
+	} {
+		t.Run(tc.encoding.String(), func(t *testing.T) {
+			ctx := t.Context()
+
+			s, err := New(t.TempDir(), WithKeyEncoding(tc.encoding))
+			require.NoError(t, err)
+			require.NoError(t, s.CreateBucket(ctx, "b"))
+
+			for _, key := range tc.keys {
+				putContent(t, s, "b", key, []byte("body of "+key))
+			}
+
+			for _, key := range tc.keys {
+				require.Equal(t, []byte("body of "+key), readContent(t, s, "b", key), "key %q", key)
+			}
+
+			objects, err := s.ListObjects(ctx, "b", "")
+			require.NoError(t, err)
+
+			var listed []string
+			for _, o := range objects {
+				listed = append(listed, o.Key)
+			}
+
+			require.ElementsMatch(t, tc.keys, listed)
+
+			// Deleting every key leaves nothing behind to list.
+			for _, key := range tc.keys {
+				require.NoError(t, s.DeleteObject(ctx, "b", key), "key %q", key)
+			}
+
+			objects, err = s.ListObjects(ctx, "b", "")
+			require.NoError(t, err)
+			require.Empty(t, objects)
+			require.NoError(t, s.DeleteBucket(ctx, "b"))
+		})
+	}
+}
+
+func TestKeyEncodingPercentPaths(t *testing.T) {
+	s, err := New(t.TempDir(), WithKeyEncoding(KeyPercent))
+	require.NoError(t, err)
+
+	for key, want := range map[string]string{
+		"plain/file.txt":  filepath.Join("plain", "file.txt"),
+		"CON":             "%43ON",
+		"dir/nul.txt":     filepath.Join("dir", "%6Eul.txt"),
+		"trailing-dot.":   "trailing-dot%2E",
+		"a:b?":            "a%3Ab%3F",
+		"100%":            "100%25",
+		"double//slash":   filepath.Join("double", "%", "slash"),
+		"dir/":            filepath.Join("dir", "%"),
+		"console/comfy":   filepath.Join("console", "comfy"),
+		"unicode/日本.txt":  filepath.Join("unicode", "日本.txt"),
+		"ends-with-dot/.": filepath.Join("ends-with-dot", "%2E"),
+	} {
+		require.Equal(t, want, s.keyPath(key), "key %q", key)
+
+		got, ok := s.pathKey(filepath.Join("root", want), want)
+		require.True(t, ok)
+		require.Equal(t, key, got)
+	}
+
+	// An encoded segment must fit the file name limit too.
+	require.ErrorIs(t, s.checkKeySegments(strings.Repeat("?", 100)), fs.ErrKeyTooLong)
+}
+
+func TestKeyEncodingHashedIgnoresStrayFiles(t *testing.T) {
+	ctx := t.Context()
+	root := t.TempDir()
+
+	s, err := New(root, WithKeyEncoding(KeyHashed))
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	putContent(t, s, "b", "kept", []byte("x"))
+
+	// An object file whose index names another key, and one without an
+	// index, are not listed.
+	path := filepath.Join(root, "b", s.keyPath("other"))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte("y"), 0o600))
+
+	objects, err := s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	require.NoError(t, os.WriteFile(path+keyIndexSuffix, []byte("not other"), 0o600))
+
+	objects, err = s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.Equal(t, "kept", objects[0].Key)
+}
+
+func TestParseKeyEncoding(t *testing.T) {
+	for in, want := range map[string]KeyEncoding{
+		"":            KeyPassthrough,
+		"passthrough": KeyPassthrough,
+		"percent":     KeyPercent,
+		"hashed":      KeyHashed,
+	} {
+		got, err := ParseKeyEncoding(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := ParseKeyEncoding("base64")
+	require.Error(t, err)
+
+	_, err = New(t.TempDir(), WithKeyEncoding(KeyHashed), WithStrictPrefixes())
+	require.Error(t, err)
+}
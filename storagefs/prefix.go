@@ -0,0 +1,62 @@
+package storagefs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// WithStrictPrefixes stops writes from creating directories: a PUT, or a
+// multipart upload, whose key lies under a prefix with no directory in the
+// bucket yet fails with fs.ErrPrefixNotFound instead, so a mistyped key cannot
+// grow stray structure. Prefixes are created out of band (mkdir under the
+// bucket directory), and DeleteObject leaves emptied directories in place.
+//
+// The default creates prefixes implicitly, as S3 has no directories at all.
+func WithStrictPrefixes() Option {
+	return func(s *Storage) { s.strictPrefixes = true }
+}
+
+// ensureObjectDir makes sure the directory for an object at objectPath
+// exists, creating it unless prefixes are strict.
+func (s *Storage) ensureObjectDir(bucketPath, objectPath string) error {
+	dir := filepath.Dir(objectPath)
+	if err := checkObjectDir(bucketPath, dir); err != nil {
+		return err
+	}
+
+	if !s.strictPrefixes {
+		if err := os.MkdirAll(dir, defaultDirPermissions); err != nil {
+			return errors.Wrap(err, "create object directory")
+		}
+
+		return nil
+	}
+
+	return checkPrefixExists(bucketPath, dir)
+}
+
+// checkPrefixExists fails with fs.ErrPrefixNotFound unless dir, an object's
+// directory under bucketPath, is an existing directory.
+func checkPrefixExists(bucketPath, dir string) error {
+	// A file where a directory of the prefix should be is ENOTDIR.
+	info, err := os.Stat(dir)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR) {
+		return errors.Wrap(err, "stat object directory")
+	}
+
+	prefix, relErr := filepath.Rel(bucketPath, dir)
+	if relErr != nil {
+		prefix = dir
+	}
+
+	return errors.Wrapf(fs.ErrPrefixNotFound, "%q", filepath.ToSlash(prefix)+"/")
+}
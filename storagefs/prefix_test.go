@@ -0,0 +1,66 @@
+package storagefs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestStrictPrefixes(t *testing.T) {
+	put := func(t *testing.T, s *Storage, key string) error {
+		t.Helper()
+
+		_, err := s.PutObject(t.Context(), &fs.PutObjectRequest{
+			Bucket: "b", Key: key, Reader: strings.NewReader("data"), Size: 4,
+		})
+
+		return err
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		root := t.TempDir()
+		s, err := New(root)
+		require.NoError(t, err)
+		require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+		require.NoError(t, put(t, s, "reports/2024/jan uary/x"))
+		require.DirExists(t, filepath.Join(root, "b", "reports", "2024", "jan uary"))
+
+		// Deleting the only object prunes the directories it created.
+		require.NoError(t, s.DeleteObject(t.Context(), "b", "reports/2024/jan uary/x"))
+		require.NoDirExists(t, filepath.Join(root, "b", "reports"))
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		ctx := t.Context()
+		root := t.TempDir()
+		s, err := New(root, WithStrictPrefixes())
+		require.NoError(t, err)
+		require.NoError(t, s.CreateBucket(ctx, "b"))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "b", "reports", "2024", "january"), 0o750))
+
+		// Keys at the bucket root and under existing prefixes are fine.
+		require.NoError(t, put(t, s, "top"))
+		require.NoError(t, put(t, s, "reports/2024/january/x"))
+
+		err = put(t, s, "reports/2024/jan uary/x")
+		require.ErrorIs(t, err, fs.ErrPrefixNotFound)
+		require.ErrorContains(t, err, `"reports/2024/jan uary/"`)
+		require.NoDirExists(t, filepath.Join(root, "b", "reports", "2024", "jan uary"))
+
+		// A file where a directory would have to be is not a prefix either.
+		require.ErrorIs(t, put(t, s, "top/x"), fs.ErrPrefixNotFound)
+
+		_, err = s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "missing/x"})
+		require.ErrorIs(t, err, fs.ErrPrefixNotFound)
+
+		// Prefixes outlive their last object.
+		require.NoError(t, s.DeleteObject(ctx, "b", "reports/2024/january/x"))
+		require.DirExists(t, filepath.Join(root, "b", "reports", "2024", "january"))
+	})
+}
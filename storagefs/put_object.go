@@ -3,11 +3,14 @@ package storagefs
 import (
 	"context"
 	"crypto/md5" //nolint:gosec // MD5 is required for S3 ETag compatibility.
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/go-faster/errors"
 
@@ -15,20 +18,28 @@ import (
 )
 
 func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
-	bucketPath := filepath.Join(s.root, req.Bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return nil, fs.ErrBucketNotFound
+	bucketPath, err := s.bucketDir(req.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkKeySegments(req.Key); err != nil {
+		return nil, err
 	}
 
-	objectPath := filepath.Join(bucketPath, toOSPath(req.Key))
-	if err := os.MkdirAll(filepath.Dir(objectPath), defaultDirPermissions); err != nil {
-		return nil, errors.Wrap(err, "create object directory")
+	objectPath := filepath.Join(bucketPath, s.keyPath(req.Key))
+	if err := s.ensureObjectDir(bucketPath, objectPath); err != nil {
+		return nil, err
+	}
+
+	if err := s.writeKeyIndex(objectPath, req.Key); err != nil {
+		return nil, err
 	}
 
 	// Stream to a staging temp file while hashing, then rename into place so a
 	// partially written object is never visible in the bucket; the sidecar is
 	// written after the object (sidecar-less files stay readable).
-	tmp, err := s.newObjectTemp()
+	tmp, err := s.newUploadTemp()
 	if err != nil {
 		return nil, err
 	}
@@ -38,47 +49,169 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 		_ = os.Remove(tmp.Name())
 	}
 
+	// With encryption the file holds ciphertext: the ETag is still over what
+	// the client sent, the checksum over what is stored.
+	k, encInfo, err := s.sealerFor(ctx)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// With dedup the body is also hashed for its content-store key. An SSE-C
+	// body is sealed with its own key and nonce, so equal plaintexts never
+	// share a body: it stays out of the content store.
+	var content hash.Hash
+	if s.dedup && encInfo == nil {
+		content = sha256.New()
+	}
+
+	var (
+		dst      io.Writer = tmp
+		enc      *encryptWriter
+		checksum hash.Hash
+	)
+
+	if k != nil {
+		checksum = md5.New() //nolint:gosec // MD5 is the stored object checksum.
+		sealed := io.MultiWriter(tmp, checksum)
+
+		if err := writeSealedMagic(sealed); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		enc = newEncryptWriter(sealed, k, encInfo)
+		dst = enc
+	}
+
 	hash := md5.New() //nolint:gosec // MD5 is required for S3 ETag compatibility.
+	w := io.MultiWriter(dst, hash)
 
-	if _, err := io.Copy(io.MultiWriter(tmp, hash), req.Reader); err != nil {
+	if content != nil {
+		w = io.MultiWriter(w, content)
+	}
+
+	// The body may be a multi-GB server-side copy; stop as soon as the caller
+	// gives up rather than finishing a write nobody will see.
+	if _, err := io.Copy(w, contextReader{ctx: ctx, r: req.Reader}); err != nil {
 		cleanup()
 		return nil, fmt.Errorf("failed to write object: %w", err)
 	}
 
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
 	// Flush object data to stable storage before it becomes visible (per policy).
 	if err := s.syncFile(tmp); err != nil {
 		cleanup()
 		return nil, err
 	}
 
+	stored, err := tmp.Stat()
+	if err != nil {
+		cleanup()
+		return nil, errors.Wrap(err, "stat object")
+	}
+
 	if err := tmp.Close(); err != nil {
 		_ = os.Remove(tmp.Name())
 		return nil, errors.Wrap(err, "close object")
 	}
 
+	// From here on the body is tmpName, on the root's filesystem.
+	tmpName, err := s.settleUpload(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// The file mtime is the object's LastModified; stamp a supplied one
+	// before the rename so the object never appears with the write time. A
+	// content-store link shares its mtime, so it records the time instead.
+	if !req.LastModified.IsZero() && content == nil {
+		if err := os.Chtimes(tmpName, req.LastModified, req.LastModified); err != nil {
+			_ = os.Remove(tmpName)
+			return nil, errors.Wrap(err, "set modification time")
+		}
+	}
+
 	etag := hex.EncodeToString(hash.Sum(nil))
+	sum := etag
+
+	if checksum != nil {
+		sum = hex.EncodeToString(checksum.Sum(nil))
+	}
+
+	// As on S3, an SSE-C object's ETag is not the MD5 of its plaintext, which
+	// would let anyone confirm a guess of the content without the key.
+	if customerKeyMD5(encInfo) != "" {
+		etag = sum
+	}
+
+	sc := newSidecar(req.Key, etag, sum, req.Metadata, req.Tags, req.ACL)
+	sc.Encryption = encInfo
+	sc.Stored = stored.Size()
+
+	if content != nil {
+		sc.Content = contentDigest(content.Sum(nil))
+		sc.Modified = req.LastModified
+
+		if sc.Modified.IsZero() {
+			sc.Modified = time.Now()
+		}
+	}
+
+	staged, err := s.stageMetaXattr(tmpName, sc)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return nil, err
+	}
 
 	// Finalize under putMu so the conditional-write check and the rename are
 	// atomic against other writers to this key (the body is already on disk).
+	defer s.lockListings(req.Bucket)()
+
 	s.putMu.Lock()
 	defer s.putMu.Unlock()
 
 	if req.IfNoneMatch != "" || req.IfMatch != "" {
 		exists, currentETag, err := s.currentObjectState(req.Bucket, req.Key, objectPath)
 		if err != nil {
-			_ = os.Remove(tmp.Name())
+			_ = os.Remove(tmpName)
 			return nil, err
 		}
 
 		if req.PreconditionFailed(exists, currentETag) {
-			_ = os.Remove(tmp.Name())
+			_ = os.Remove(tmpName)
 			return nil, fs.ErrPreconditionFailed
 		}
 	}
 
-	if err := os.Rename(tmp.Name(), objectPath); err != nil {
-		_ = os.Remove(tmp.Name())
-		return nil, errors.Wrap(err, "rename object")
+	// The body being replaced, if it lives in the content store, loses a
+	// reference once the new object is in place.
+	prev, err := s.readSidecar(req.Bucket, req.Key)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return nil, err
+	}
+
+	if prev != nil && prev.LegalHold {
+		_ = os.Remove(tmpName)
+		return nil, legalHoldError(req.Bucket, req.Key)
+	}
+
+	// The filter learns of the key before it is visible, so a GET never
+	// misses it. An overwrite counts it again, which only costs the filter
+	// precision.
+	if s.existence != nil {
+		s.existence.add(req.Bucket, s.existenceKey(req.Key))
+	}
+
+	if err := s.placeObject(tmpName, objectPath, sc.Content); err != nil {
+		return nil, err
 	}
 
 	// Persist the rename (per policy) so the object is durably visible.
@@ -86,11 +219,19 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 		return nil, err
 	}
 
-	if err := s.writeSidecar(req.Bucket, newSidecar(req.Key, etag, etag, req.Metadata, req.Tags, req.ACL)); err != nil {
+	if err := s.commitSidecar(req.Bucket, sc, staged); err != nil {
 		return nil, err
 	}
 
-	return &fs.PutObjectResponse{ETag: etag}, nil
+	if prev != nil {
+		s.releaseContent(prev.Content)
+	}
+
+	return &fs.PutObjectResponse{
+		ETag:                 etag,
+		ServerSideEncryption: sseFor(encInfo),
+		SSECustomerKeyMD5:    customerKeyMD5(encInfo),
+	}, nil
 }
 
 // currentObjectState reports whether the object at path exists and its ETag,
@@ -112,3 +253,18 @@ func (s *Storage) currentObjectState(bucket, key, path string) (exists bool, eta
 
 	return true, etag, nil
 }
+
+// contextReader fails reads once ctx is done, so a copy loop driven by it ends
+// at the next chunk after cancellation.
+type contextReader struct {
+	ctx context.Context //nolint:containedctx // Scoped to a single copy.
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p)
+}
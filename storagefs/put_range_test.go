@@ -0,0 +1,49 @@
+package storagefs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestPutObjectRange_InPlace(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	putContent(t, s, "b", "obj", []byte("0123456789"))
+
+	before, err := os.Stat(filepath.Join(root, "b", "obj"))
+	require.NoError(t, err)
+
+	resp, err := fs.PutObjectRange(t.Context(), s, "b", "obj", 8, strings.NewReader("XYZ"), 3)
+	require.NoError(t, err)
+
+	// The range went into the same file rather than a replacement.
+	after, err := os.Stat(filepath.Join(root, "b", "obj"))
+	require.NoError(t, err)
+	require.True(t, os.SameFile(before, after))
+	require.Equal(t, []byte("01234567XYZ"), readContent(t, s, "b", "obj"))
+
+	sc, err := s.readSidecar("b", "obj")
+	require.NoError(t, err)
+	require.Equal(t, resp.ETag, sc.ETag)
+	require.Equal(t, resp.ETag, sc.Checksum)
+	require.Equal(t, int64(11), sc.Stored)
+
+	// A short body leaves the object alone.
+	_, err = fs.PutObjectRange(t.Context(), s, "b", "obj", 0, strings.NewReader("ab"), 3)
+	require.Error(t, err)
+	require.Equal(t, []byte("01234567XYZ"), readContent(t, s, "b", "obj"))
+
+	require.NoError(t, s.PutObjectLegalHold(t.Context(), "b", "obj", true))
+
+	_, err = fs.PutObjectRange(t.Context(), s, "b", "obj", 0, strings.NewReader("a"), 1)
+	require.ErrorIs(t, err, fs.ErrAccessDenied)
+}
@@ -0,0 +1,53 @@
+package storagefs
+
+import (
+	"io"
+	"os"
+)
+
+// WithReadAhead hints the OS to read ahead aggressively when GetObject's body
+// is read from the start, as a full-object download is; on Linux that is
+// posix_fadvise(POSIX_FADV_SEQUENTIAL), elsewhere nothing. The hint is given
+// on the first read, so a range request that seeks into the object first is
+// served without it. Encrypted bodies are read segment by segment and never
+// hinted.
+func WithReadAhead() Option {
+	return func(s *Storage) { s.readAhead = true }
+}
+
+// readAheadReader gives an object file the sequential read hint once its first
+// read turns out to start at offset zero.
+type readAheadReader struct {
+	f       *os.File
+	pos     int64
+	started bool
+}
+
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+
+		if r.pos == 0 {
+			// Advisory only: a failure leaves the kernel defaults in place.
+			_ = adviseSequential(r.f)
+		}
+	}
+
+	n, err := r.f.Read(p)
+	r.pos += int64(n)
+
+	return n, err
+}
+
+func (r *readAheadReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.f.Seek(offset, whence)
+	if err == nil {
+		r.pos = pos
+	}
+
+	return pos, err
+}
+
+func (r *readAheadReader) Close() error { return r.f.Close() }
+
+var _ io.ReadSeekCloser = (*readAheadReader)(nil)
@@ -0,0 +1,15 @@
+//go:build linux
+
+package storagefs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// adviseSequential declares that f will be read sequentially, which lets the
+// kernel double its read-ahead window for it.
+func adviseSequential(f *os.File) error {
+	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package storagefs
+
+import "os"
+
+func adviseSequential(*os.File) error { return nil }
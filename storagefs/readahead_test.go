@@ -0,0 +1,54 @@
+package storagefs
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAheadReader(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(t.TempDir(), WithReadAhead())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+
+	content := []byte("0123456789abcdef")
+	putContent(t, s, "b", "obj", content)
+
+	t.Run("Full", func(t *testing.T) {
+		resp, err := s.GetObject(t.Context(), "b", "obj")
+		require.NoError(t, err)
+		defer func() { _ = resp.Reader.Close() }()
+
+		r, ok := resp.Reader.(*readAheadReader)
+		require.True(t, ok)
+
+		// A size probe before the body, as http.ServeContent does.
+		rs := resp.Reader.(io.ReadSeeker)
+		_, err = rs.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		_, err = rs.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(rs)
+		require.NoError(t, err)
+		require.Equal(t, content, got)
+		require.True(t, r.started)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, err := s.GetObject(t.Context(), "b", "obj")
+		require.NoError(t, err)
+		defer func() { _ = resp.Reader.Close() }()
+
+		rs := resp.Reader.(io.ReadSeeker)
+		_, err = rs.Seek(10, io.SeekStart)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(rs)
+		require.NoError(t, err)
+		require.Equal(t, content[10:], got)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-faster/errors"
 
@@ -37,6 +38,8 @@ type ScrubReport struct {
 	Unverifiable int
 	// Quarantined is the number of corrupt objects moved aside.
 	Quarantined int
+	// Bytes is the object content read, by size as listed.
+	Bytes int64
 }
 
 // Healthy reports whether the pass found no corruption.
@@ -48,6 +51,9 @@ type ScrubOptions struct {
 	// <root>/.quarantine so it is no longer served. When false, corruption is
 	// only reported.
 	Quarantine bool
+	// BytesPerSecond caps the pass's average read rate, so scrubbing cold
+	// data does not starve serving of disk bandwidth. Zero is unthrottled.
+	BytesPerSecond int64
 }
 
 // Scrub walks every object, recomputing its MD5 and comparing to the stored
@@ -60,6 +66,7 @@ func (s *Storage) Scrub(ctx context.Context, opts ScrubOptions) (*ScrubReport, e
 	}
 
 	report := &ScrubReport{}
+	start := time.Now()
 
 	for _, b := range buckets {
 		objects, err := s.ListObjects(ctx, b.Name, "")
@@ -73,12 +80,42 @@ func (s *Storage) Scrub(ctx context.Context, opts ScrubOptions) (*ScrubReport, e
 			}
 
 			s.scrubObject(b.Name, o.Key, opts, report)
+			report.Bytes += o.Size
+
+			if err := scrubPace(ctx, start, report.Bytes, opts.BytesPerSecond); err != nil {
+				return report, err
+			}
 		}
 	}
 
 	return report, nil
 }
 
+// scrubPace sleeps until reading n bytes since start keeps within rate bytes
+// per second, returning early with ctx's error if it is canceled.
+func scrubPace(ctx context.Context, start time.Time, n, rate int64) error {
+	if rate <= 0 {
+		return nil
+	}
+
+	due := start.Add(time.Duration(float64(n) / float64(rate) * float64(time.Second)))
+
+	wait := time.Until(due)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // scrubObject verifies one object and updates the report.
 func (s *Storage) scrubObject(bucket, key string, opts ScrubOptions, report *ScrubReport) {
 	report.Scanned++
@@ -89,7 +126,7 @@ func (s *Storage) scrubObject(bucket, key string, opts ScrubOptions, report *Scr
 		return
 	}
 
-	actual, err := fileMD5(filepath.Join(s.root, bucket, toOSPath(key)))
+	actual, err := fileMD5(filepath.Join(s.root, bucket, s.keyPath(key)))
 	if err != nil {
 		// A read error on the object path is itself a corruption signal.
 		report.Corrupt = append(report.Corrupt, ObjectRef{bucket, key})
@@ -114,7 +151,17 @@ func (s *Storage) scrubObject(bucket, key string, opts ScrubOptions, report *Scr
 // nothing to verify against.
 func (s *Storage) storedChecksum(bucket, key string) (string, bool) {
 	sc, err := s.readSidecar(bucket, key)
-	if err != nil || sc == nil {
+	if err != nil {
+		return "", false
+	}
+
+	return sc.contentChecksum()
+}
+
+// contentChecksum is storedChecksum for an already loaded sidecar (nil when the
+// object has none).
+func (sc *sidecar) contentChecksum() (string, bool) {
+	if sc == nil {
 		return "", false
 	}
 
@@ -164,16 +211,27 @@ func fileMD5(path string) (string, error) {
 // quarantineObject moves a corrupt object and its sidecar under
 // <root>/.quarantine/<bucket>/, mirroring the key path, so it stops serving.
 func (s *Storage) quarantineObject(bucket, key string) error {
-	dst := filepath.Join(s.root, quarantineSubdir, bucket, toOSPath(key))
+	dst := filepath.Join(s.root, quarantineSubdir, bucket, s.keyPath(key))
 	if err := os.MkdirAll(filepath.Dir(dst), defaultDirPermissions); err != nil {
 		return errors.Wrap(err, "create quarantine dir")
 	}
 
-	src := filepath.Join(s.root, bucket, toOSPath(key))
+	src := filepath.Join(s.root, bucket, s.keyPath(key))
 	if err := os.Rename(src, dst); err != nil {
 		return errors.Wrap(err, "quarantine object")
 	}
 
+	s.removeKeyIndex(src)
+
+	// A deduplicated body is corrupt for every object sharing it. Drop the
+	// content-store entry so new writes of that content store a fresh copy;
+	// the other links keep the inode alive until they are scrubbed too.
+	if sc, err := s.readSidecar(bucket, key); err == nil && sc != nil && sc.Content != "" {
+		s.dedupMu.Lock()
+		_ = os.Remove(s.contentPath(sc.Content))
+		s.dedupMu.Unlock()
+	}
+
 	// Best-effort: move the sidecar alongside (its absence is tolerated).
 	sidecarSrc := s.sidecarPath(bucket, key)
 	sidecarDst := filepath.Join(s.root, quarantineSubdir, "sidecars", bucket, filepath.Base(sidecarSrc))
@@ -182,7 +240,9 @@ func (s *Storage) quarantineObject(bucket, key string) error {
 		_ = os.Rename(sidecarSrc, sidecarDst)
 	}
 
-	pruneEmptyDirs(filepath.Dir(src), filepath.Join(s.root, bucket))
+	if !s.strictPrefixes {
+		pruneEmptyDirs(filepath.Dir(src), filepath.Join(s.root, bucket))
+	}
 
 	return nil
 }
@@ -2,9 +2,11 @@ package storagefs
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -182,3 +184,29 @@ func TestVerifyReads(t *testing.T) {
 	_, err = s.GetObject(ctx, "b", "bad.txt")
 	require.ErrorIs(t, err, fs.ErrIntegrity)
 }
+
+func TestScrub_Throttled(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "a", bytes.Repeat([]byte("a"), 1000))
+	putContent(t, s, "b", "b", bytes.Repeat([]byte("b"), 1000))
+
+	// 2000 bytes at 10000 bytes/s take at least 200ms.
+	start := time.Now()
+	report, err := s.Scrub(ctx, ScrubOptions{BytesPerSecond: 10000})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Equal(t, int64(2000), report.Bytes)
+	require.Equal(t, 2, report.OK)
+
+	// Canceling stops a throttled pass at the next pause.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = s.Scrub(cctx, ScrubOptions{BytesPerSecond: 1})
+	require.ErrorIs(t, err, context.Canceled)
+}
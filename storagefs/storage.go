@@ -2,20 +2,28 @@
 package storagefs
 
 import (
+	"context"
 	"crypto/md5" //nolint:gosec // MD5 is required for S3 ETag compatibility.
 	"encoding/hex"
 	"fmt"
 	"io"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-faster/errors"
 
 	"github.com/go-faster/fs"
 )
 
-var _ fs.Storage = (*Storage)(nil)
+var (
+	_ fs.Storage      = (*Storage)(nil)
+	_ fs.ObjectWalker = (*Storage)(nil)
+	_ fs.RangeWriter  = (*Storage)(nil)
+)
 
 // stagingSubdir holds in-progress object bodies before they are renamed into
 // place. Keeping it outside the bucket tree means a crash mid-write never leaves
@@ -23,6 +31,14 @@ var _ fs.Storage = (*Storage)(nil)
 // it is excluded from bucket listings.
 const stagingSubdir = ".tmp"
 
+// Temp file name prefixes. objectTempPrefix names object bodies in the staging
+// directory; atomicTempPrefix names atomicWrite temps beside sidecars and bucket
+// metadata. Neither is ever created inside a bucket directory.
+const (
+	objectTempPrefix = "obj-"
+	atomicTempPrefix = ".tmp-"
+)
+
 func New(root string, opts ...Option) (*Storage, error) {
 	if err := os.MkdirAll(root, 0750); err != nil {
 		return nil, fmt.Errorf("failed to create root directory: %w", err)
@@ -36,10 +52,56 @@ func New(root string, opts ...Option) (*Storage, error) {
 		opt(s)
 	}
 
+	if s.keyEncoding == KeyHashed && s.strictPrefixes {
+		return nil, errors.New("hashed key encoding cannot be combined with strict prefixes: keys have no directories")
+	}
+
+	if s.caseSensitiveKeys && s.keyEncoding == KeyPassthrough {
+		s.keyEncoding = KeyPercent
+	}
+
 	if err := os.MkdirAll(s.stagingDir(), defaultDirPermissions); err != nil {
 		return nil, fmt.Errorf("failed to create staging directory: %w", err)
 	}
 
+	s.caseInsensitive = detectCaseInsensitive(s.stagingDir())
+
+	if err := s.initTempDir(); err != nil {
+		return nil, err
+	}
+
+	if s.metaStore == MetadataXattr {
+		if s.dedup {
+			return nil, errors.New("xattr metadata cannot be combined with dedup: linked objects share one inode")
+		}
+
+		if !xattrSupported(s.stagingDir()) {
+			s.metaStore = MetadataSidecar
+		}
+	}
+
+	if s.encryptionKey != nil {
+		if s.dedup {
+			return nil, errors.New("encryption cannot be combined with dedup: bodies are sealed per object")
+		}
+
+		k, err := newSealer(s.encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		s.sealer = k
+	}
+
+	s.removeStaleTemps()
+	s.removeOrphanContent()
+
+	if s.existence != nil {
+		if err := s.fillExistenceFilter(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to fill existence filter: %w", err)
+		}
+	}
+
 	return s, nil
 }
 
@@ -53,18 +115,79 @@ type Storage struct {
 	// verifyReads makes GetObject verify the object checksum before serving.
 	verifyReads bool
 
+	// verifyStream checks the checksum as GetObject's reader is consumed
+	// instead (see WithVerifyOnRead); readQuarantine moves objects it finds
+	// corrupt aside, and corruptReads counts them.
+	verifyStream   bool
+	readQuarantine bool
+	corruptReads   atomic.Int64
+
+	// tempDir is where uploads are streamed (see WithTempDir); empty means
+	// the staging directory. tempCrossDevice is set by New when it is on
+	// another filesystem than the root.
+	tempDir         string
+	tempCrossDevice bool
+
+	// strictPrefixes refuses writes under missing directories (see
+	// WithStrictPrefixes).
+	strictPrefixes bool
+
+	// keyEncoding maps keys to file paths (see WithKeyEncoding).
+	keyEncoding KeyEncoding
+
+	// caseSensitiveKeys escapes case in file names (see
+	// WithCaseSensitiveKeys); caseInsensitive is set by New when the root's
+	// filesystem folds case.
+	caseSensitiveKeys bool
+	caseInsensitive   bool
+
+	// readAhead hints sequential reads of whole objects (see WithReadAhead).
+	readAhead bool
+
+	// consistentListings holds the bucket's listLocks entry across
+	// ListObjects walks (see WithConsistentListings).
+	consistentListings bool
+	listLocksMu        sync.Mutex
+	listLocks          map[string]*sync.RWMutex
+
+	// existence answers GETs of definitely missing keys from memory (see
+	// WithExistenceFilter); nil when off.
+	existence *existenceFilter
+
+	// dedup stores object bodies once per distinct content (see WithDedup).
+	dedup bool
+
+	// metaStore is where object metadata documents live (see
+	// WithMetadataStore); New downgrades it when xattrs are unsupported.
+	metaStore MetadataStore
+
+	// encryptionKey is the key given to WithEncryptionKey; New loads it into
+	// sealer, which encrypts new object bodies when set.
+	encryptionKey []byte
+	sealer        *sealer
+
 	etagMu    sync.Mutex
 	etagCache map[string]etagEntry
 
+	// roots caches an os.Root per bucket directory for reads (see
+	// bucketRoot).
+	rootsMu sync.Mutex
+	roots   map[string]*os.Root
+
 	// metaMu serializes sidecar read-modify-write cycles (tagging updates).
 	metaMu sync.Mutex
 
 	// putMu serializes the finalize step of PutObject (conditional-write
 	// evaluation, rename into place, and sidecar write) so concurrent
-	// conditional PUTs to the same key resolve to a single winner. The body is
-	// streamed to a temp file outside this lock, so only the fast rename step
-	// is serialized.
+	// conditional PUTs to the same key resolve to a single winner. Deletes,
+	// multipart completion and legal-hold changes take it too, so a hold is
+	// never bypassed. The body is streamed to a temp file outside this lock,
+	// so only the fast rename step is serialized.
 	putMu sync.Mutex
+
+	// dedupMu serializes content-store installs, links and releases, so a body
+	// is never freed while a concurrent write is linking to it.
+	dedupMu sync.Mutex
 }
 
 // etagEntry is a cached ETag valid as long as size and modtime are unchanged.
@@ -117,11 +240,43 @@ func (s *Storage) stagingDir() string {
 	return filepath.Join(s.root, stagingSubdir)
 }
 
+// removeStaleTemps deletes temp files orphaned by a previous process that died
+// mid-write: object bodies in the staging and upload directories and
+// atomicWrite temps (".tmp-*") next to sidecars and bucket metadata. All live
+// outside the bucket tree, so the sweep can never touch a user key. It is
+// best-effort: a file that cannot be removed is left for the next start.
+func (s *Storage) removeStaleTemps() {
+	for _, dir := range []string{s.stagingDir(), s.uploadDir()} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasPrefix(e.Name(), objectTempPrefix) {
+				_ = os.Remove(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	_ = filepath.WalkDir(filepath.Join(s.root, metaDir), func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Best-effort sweep; a missing .meta is normal.
+		}
+
+		if !d.IsDir() && strings.HasPrefix(d.Name(), atomicTempPrefix) {
+			_ = os.Remove(path)
+		}
+
+		return nil
+	})
+}
+
 // newObjectTemp creates a temp file in the staging directory for an object body
 // that will be renamed into its bucket. Staging and bucket dirs share the root
 // filesystem, so the rename is atomic.
 func (s *Storage) newObjectTemp() (*os.File, error) {
-	f, err := os.CreateTemp(s.stagingDir(), "obj-*")
+	f, err := os.CreateTemp(s.stagingDir(), objectTempPrefix+"*")
 	if err != nil {
 		return nil, errors.Wrap(err, "create temp object")
 	}
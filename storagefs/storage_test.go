@@ -0,0 +1,78 @@
+package storagefs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// TestNewRemovesStaleTemps seeds the temp files a crashed writer leaves behind
+// and checks that opening the root sweeps them while leaving real objects and
+// user keys that merely look similar untouched.
+func TestNewRemovesStaleTemps(t *testing.T) {
+	root := t.TempDir()
+	ctx := t.Context()
+
+	s, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "obj-1", []byte("user object"))
+	putContent(t, s, "b", ".tmp-user", []byte("user object"))
+
+	staleBody := filepath.Join(root, stagingSubdir, objectTempPrefix+"123456")
+	require.NoError(t, os.WriteFile(staleBody, []byte("partial"), 0o600))
+
+	staleSidecar := filepath.Join(root, metaDir, "b", atomicTempPrefix+"123456")
+	require.NoError(t, os.WriteFile(staleSidecar, []byte("{"), 0o600))
+
+	// An in-progress temp is never listed, even before the sweep.
+	objects, err := s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	s, err = New(root)
+	require.NoError(t, err)
+
+	require.NoFileExists(t, staleBody)
+	require.NoFileExists(t, staleSidecar)
+
+	objects, err = s.ListObjects(ctx, "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Equal(t, []byte("user object"), readContent(t, s, "b", "obj-1"))
+}
+
+// readContent reads an object's full body.
+func readContent(t *testing.T, s *Storage, bucket, key string) []byte {
+	t.Helper()
+
+	resp, err := s.GetObject(t.Context(), bucket, key)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Reader.Close() }()
+
+	data, err := io.ReadAll(resp.Reader)
+	require.NoError(t, err)
+
+	return data
+}
+
+// TestGetObjectPrefixDirectory checks that a key naming the directory created
+// for longer keys is reported missing rather than opened and served.
+func TestGetObjectPrefixDirectory(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+	putContent(t, s, "b", "dir/obj", []byte("x"))
+
+	_, err = s.GetObject(t.Context(), "b", "dir")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+	_, err = s.GetObject(t.Context(), "missing", "dir/obj")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
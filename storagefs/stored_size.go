@@ -0,0 +1,69 @@
+package storagefs
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is the stored object checksum.
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// checkStoredSize compares the size recorded when the object was written with
+// the open file f about to be served, so a body changed out of band (say,
+// truncated) is never sent under a Content-Length it does not match.
+//
+// On a mismatch the file is hashed: when it still matches the recorded
+// checksum only the record is stale, and it is refreshed. Otherwise the object
+// is corrupt: it counts as a corrupt read, fs.ErrIntegrity is returned (and,
+// with WithReadQuarantine, the object moved aside), which the handler logs and
+// answers with 500 InternalError.
+func (s *Storage) checkStoredSize(bucket, key string, sc *sidecar, f *os.File, info os.FileInfo) error {
+	if sc == nil || sc.Stored == 0 || sc.Stored == info.Size() {
+		return nil
+	}
+
+	if expected, ok := sc.contentChecksum(); ok {
+		h := md5.New() //nolint:gosec // MD5 is the stored object checksum.
+		if _, err := io.Copy(h, io.NewSectionReader(f, 0, info.Size())); err != nil {
+			return errors.Wrap(err, "hash object")
+		}
+
+		if hex.EncodeToString(h.Sum(nil)) == expected {
+			return s.refreshStoredSize(bucket, key, sc, info.Size())
+		}
+	}
+
+	s.corruptReads.Add(1)
+
+	if s.readQuarantine {
+		_ = s.quarantineObject(bucket, key)
+	}
+
+	return errors.Wrapf(fs.ErrIntegrity, "%s/%s: recorded size %d, file has %d",
+		bucket, strings.TrimPrefix(key, "/"), sc.Stored, info.Size())
+}
+
+// refreshStoredSize records size for an object whose content was verified,
+// unless the object was replaced since sc was read.
+func (s *Storage) refreshStoredSize(bucket, key string, sc *sidecar, size int64) error {
+	s.putMu.Lock()
+	defer s.putMu.Unlock()
+
+	current, err := s.readSidecar(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if current == nil || current.ETag != sc.ETag || current.Stored != sc.Stored {
+		return nil
+	}
+
+	current.Stored = size
+	sc.Stored = size
+
+	return s.writeSidecar(bucket, current)
+}
@@ -0,0 +1,98 @@
+package storagefs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestGetObject_StoredSizeMismatch(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	putContent(t, s, "b", "obj", content)
+
+	sc, err := s.readSidecar("b", "obj")
+	require.NoError(t, err)
+	require.EqualValues(t, len(content), sc.Stored)
+
+	// Truncated out of band: the recorded size no longer matches.
+	require.NoError(t, os.Truncate(filepath.Join(root, "b", "obj"), 10))
+
+	_, err = s.GetObject(ctx, "b", "obj")
+	require.ErrorIs(t, err, fs.ErrIntegrity)
+	require.EqualValues(t, 1, s.CorruptReads())
+}
+
+func TestGetObject_StoredSizeStaleRecord(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	content := []byte("intact content")
+	putContent(t, s, "b", "obj", content)
+
+	// The file still matches its checksum; only the recorded size is wrong.
+	sc, err := s.readSidecar("b", "obj")
+	require.NoError(t, err)
+
+	sc.Stored = 3
+	require.NoError(t, s.writeSidecar("b", sc))
+
+	resp, err := s.GetObject(ctx, "b", "obj")
+	require.NoError(t, err)
+	require.EqualValues(t, len(content), resp.Size)
+
+	got, err := io.ReadAll(resp.Reader)
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+	require.Equal(t, content, got)
+	require.Zero(t, s.CorruptReads())
+
+	sc, err = s.readSidecar("b", "obj")
+	require.NoError(t, err)
+	require.EqualValues(t, len(content), sc.Stored, "record refreshed")
+}
+
+func TestGetObject_StoredSizeMultipart(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	upload, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "mp"})
+	require.NoError(t, err)
+
+	part, err := s.UploadPart(ctx, &fs.UploadPartRequest{
+		Bucket: "b", Key: "mp", UploadID: upload.UploadID, PartNumber: 1,
+		Reader: bytes.NewReader([]byte("multipart body")), Size: 14,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: "b", Key: "mp", UploadID: upload.UploadID,
+		Parts: []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b", "mp"), []byte("multipart body, grown"), 0o600))
+
+	_, err = s.GetObject(ctx, "b", "mp")
+	require.ErrorIs(t, err, fs.ErrIntegrity)
+}
@@ -0,0 +1,141 @@
+package storagefs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// Symlinks: keys can never create one (every write is a regular file renamed
+// into place), so any symlink inside a bucket was planted in the data
+// directory. None is trusted to lead out of its bucket: listings skip them,
+// reads resolve through an os.Root confined to the bucket (a link can at most
+// alias another object of the same bucket), and writes refuse a key whose
+// directory resolves outside the bucket.
+
+// openObject opens the object file for bucket/key. The open resolves through
+// an os.Root on the bucket directory, so a symlink along the key cannot
+// escape the bucket; only regular files are returned.
+func (s *Storage) openObject(bucket, key string) (*os.File, os.FileInfo, error) {
+	return s.openObjectFile(bucket, key, os.O_RDONLY)
+}
+
+// openObjectFile is openObject with the given open flags, which must not
+// create the file.
+func (s *Storage) openObjectFile(bucket, key string, flag int) (*os.File, os.FileInfo, error) {
+	bucketPath, err := s.bucketDir(bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, err := s.bucketRoot(bucket, bucketPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := root.OpenFile(s.keyPath(key), flag, 0)
+	if err != nil {
+		// The escape error os.Root reports is not exported; tell it apart
+		// from real failures by resolving the path ourselves (off the hot path).
+		if os.IsNotExist(err) || !resolvesWithin(bucketPath, filepath.Join(bucketPath, s.keyPath(key))) {
+			return nil, nil, fs.ErrObjectNotFound
+		}
+
+		return nil, nil, errors.Wrap(err, "open object")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, errors.Wrap(err, "stat object")
+	}
+
+	// A key naming the directory of longer keys, or a device or socket, is
+	// not an object.
+	if !info.Mode().IsRegular() {
+		_ = f.Close()
+		return nil, nil, fs.ErrObjectNotFound
+	}
+
+	return f, info, nil
+}
+
+// bucketRoot returns the os.Root of bucket, at bucketPath, opened on first
+// use and kept for later reads, so a GET costs no extra open of the bucket
+// directory.
+func (s *Storage) bucketRoot(bucket, bucketPath string) (*os.Root, error) {
+	s.rootsMu.Lock()
+	defer s.rootsMu.Unlock()
+
+	if root, ok := s.roots[bucket]; ok {
+		return root, nil
+	}
+
+	root, err := os.OpenRoot(bucketPath)
+	if os.IsNotExist(err) {
+		return nil, fs.ErrBucketNotFound
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "open bucket")
+	}
+
+	if s.roots == nil {
+		s.roots = make(map[string]*os.Root)
+	}
+
+	s.roots[bucket] = root
+
+	return root, nil
+}
+
+// forgetBucketRoot drops the cached root of bucket, whose directory was
+// removed or created. The handle is not closed, since a concurrent read may
+// still hold it; it is released once unused.
+func (s *Storage) forgetBucketRoot(bucket string) {
+	s.rootsMu.Lock()
+	defer s.rootsMu.Unlock()
+
+	delete(s.roots, bucket)
+}
+
+// checkObjectDir rejects writing under dir when it, or its nearest existing
+// ancestor, resolves outside the bucket through a symlink. Call it before
+// creating the directory, so nothing is created outside either. Such a key
+// names no object of the bucket: the error is fs.ErrObjectNotFound.
+func checkObjectDir(bucketPath, dir string) error {
+	if !resolvesWithin(bucketPath, dir) {
+		return errors.Wrapf(fs.ErrObjectNotFound, "object directory %q resolves outside its bucket", dir)
+	}
+
+	return nil
+}
+
+// resolvesWithin reports whether path, with symlinks followed, stays under
+// base. A path that does not exist yet is judged by its nearest existing
+// ancestor.
+func resolvesWithin(base, path string) bool {
+	realBase, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return false
+	}
+
+	for {
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			rel, err := filepath.Rel(realBase, real)
+			return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path || !os.IsNotExist(err) {
+			return false
+		}
+
+		path = parent
+	}
+}
@@ -0,0 +1,74 @@
+package storagefs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// TestSymlinksNotFollowedOutOfBucket plants links from inside a bucket to a
+// file and a directory outside the storage root, and checks that neither is
+// listed, served, or written through.
+func TestSymlinksNotFollowedOutOfBucket(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	secret := filepath.Join(outside, "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("do not serve"), 0o600))
+
+	s, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, s.CreateBucket(t.Context(), "b"))
+	putContent(t, s, "b", "real", []byte("object"))
+
+	if err := os.Symlink(secret, filepath.Join(root, "b", "leak")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "b", "dirlink")))
+
+	objects, err := s.ListObjects(t.Context(), "b", "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.Equal(t, "real", objects[0].Key)
+
+	for _, key := range []string{"leak", "dirlink/secret"} {
+		_, err := s.GetObject(t.Context(), "b", key)
+		require.ErrorIs(t, err, fs.ErrObjectNotFound, key)
+	}
+
+	_, err = s.PutObject(t.Context(), &fs.PutObjectRequest{
+		Bucket: "b", Key: "dirlink/sub/planted", Reader: bytes.NewReader([]byte("x")), Size: 1,
+	})
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+	require.NoDirExists(t, filepath.Join(outside, "sub"))
+
+	require.Equal(t, []byte("object"), readContent(t, s, "b", "real"))
+}
+
+// TestBucketRootRecreated checks that reads through the cached bucket root
+// follow a bucket deleted and created again.
+func TestBucketRootRecreated(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "key", []byte("first"))
+	require.Equal(t, []byte("first"), readContent(t, s, "b", "key"))
+
+	require.NoError(t, s.DeleteObject(ctx, "b", "key"))
+	require.NoError(t, s.DeleteBucket(ctx, "b"))
+
+	_, err = s.GetObject(ctx, "b", "key")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "key", []byte("second"))
+	require.Equal(t, []byte("second"), readContent(t, s, "b", "key"))
+}
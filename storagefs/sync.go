@@ -51,6 +51,21 @@ func WithSyncPolicy(p SyncPolicy) Option {
 	return func(s *Storage) { s.sync = p }
 }
 
+// WithSyncWrites is WithSyncPolicy reduced to its two ends. With true,
+// PutObject and CompleteMultipartUpload fsync the object's data and, after the
+// rename, its directory before returning (SyncFileDir): an acknowledged write
+// survives a crash or power loss, at the cost of waiting for the disk on every
+// write. With false (the default) nothing is fsynced (SyncNone): writes run at
+// page-cache speed, and one acknowledged shortly before a power loss may be
+// lost, though never torn. SyncFile sits in between; see SyncPolicy.
+func WithSyncWrites(on bool) Option {
+	if on {
+		return WithSyncPolicy(SyncFileDir)
+	}
+
+	return WithSyncPolicy(SyncNone)
+}
+
 // WithVerifyReads makes GetObject recompute and check each object's checksum
 // before serving it, returning fs.ErrIntegrity on a mismatch so corrupt data is
 // never served. Off by default: it costs a full extra read per GET.
@@ -103,7 +118,7 @@ func (s *Storage) syncDir(dir string) error {
 func (s *Storage) atomicWrite(path string, data []byte) error {
 	dir := filepath.Dir(path)
 
-	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	tmp, err := os.CreateTemp(dir, atomicTempPrefix+"*")
 	if err != nil {
 		return errors.Wrap(err, "create temp file")
 	}
@@ -23,6 +23,14 @@ func TestParseSyncPolicy(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestWithSyncWrites(t *testing.T) {
+	for on, want := range map[bool]SyncPolicy{true: SyncFileDir, false: SyncNone} {
+		s, err := New(t.TempDir(), WithSyncWrites(on))
+		require.NoError(t, err)
+		require.Equal(t, want, s.sync)
+	}
+}
+
 // TestSyncPolicyRoundTrip verifies every policy produces a correct, readable
 // object (durability differences aren't observable without a real crash).
 func TestSyncPolicyRoundTrip(t *testing.T) {
@@ -10,12 +10,12 @@ import (
 
 // statObject verifies the bucket and object exist.
 func (s *Storage) statObject(bucket, key string) error {
-	bucketPath := filepath.Join(s.root, bucket)
-	if _, err := os.Stat(bucketPath); os.IsNotExist(err) {
-		return fs.ErrBucketNotFound
+	bucketPath, err := s.bucketDir(bucket)
+	if err != nil {
+		return err
 	}
 
-	info, err := os.Stat(filepath.Join(bucketPath, toOSPath(key)))
+	info, err := os.Stat(filepath.Join(bucketPath, s.keyPath(key)))
 	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
 		return fs.ErrObjectNotFound
 	}
@@ -60,6 +60,7 @@ func (s *Storage) updateTags(bucket, key string, tags []fs.Tag) error {
 	}
 
 	if sc == nil {
+		s.learnKey(bucket, key)
 		sc = newSidecar(key, "", "", fs.ObjectMetadata{}, nil, fs.ACLPrivate)
 	}
 
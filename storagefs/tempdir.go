@@ -0,0 +1,140 @@
+package storagefs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+)
+
+// WithTempDir streams in-progress uploads — PutObject bodies and multipart
+// parts — to dir instead of the staging directory under the root, for
+// deployments whose root is on slow or networked storage and want the upload
+// itself to land on fast local disk.
+//
+// A rename is only atomic within one filesystem. When dir is on another one
+// (see CrossDeviceTemp), each finished body is copied into the root's staging
+// directory and renamed into place from there, so objects still appear
+// atomically at the cost of one extra local copy per upload.
+//
+// The directory should be dedicated to this storage: New removes leftover
+// upload temps from it.
+func WithTempDir(dir string) Option {
+	return func(s *Storage) { s.tempDir = dir }
+}
+
+// CrossDeviceTemp reports whether the WithTempDir directory is on a different
+// filesystem than the root, so finished uploads are copied rather than renamed
+// out of it.
+func (s *Storage) CrossDeviceTemp() bool { return s.tempCrossDevice }
+
+// uploadDir returns the directory in-progress uploads are streamed to.
+func (s *Storage) uploadDir() string {
+	if s.tempDir != "" {
+		return s.tempDir
+	}
+
+	return s.stagingDir()
+}
+
+// initTempDir creates the WithTempDir directory and detects whether it shares
+// the root's filesystem.
+func (s *Storage) initTempDir() error {
+	if s.tempDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.tempDir, defaultDirPermissions); err != nil {
+		return errors.Wrap(err, "create temp directory")
+	}
+
+	same, err := sameFilesystem(s.tempDir, s.stagingDir())
+	if err != nil {
+		return err
+	}
+
+	s.tempCrossDevice = !same
+
+	return nil
+}
+
+// sameFilesystem reports whether a file in dir can be renamed into target,
+// which is what atomic placement needs. A probe rename answers that on every
+// platform, bind mounts and subvolumes included, where comparing device
+// numbers would not.
+func sameFilesystem(dir, target string) (bool, error) {
+	f, err := os.CreateTemp(dir, objectTempPrefix+"*")
+	if err != nil {
+		return false, errors.Wrap(err, "probe temp directory")
+	}
+
+	_ = f.Close()
+
+	moved := filepath.Join(target, filepath.Base(f.Name()))
+	if err := os.Rename(f.Name(), moved); err != nil {
+		_ = os.Remove(f.Name())
+		return false, nil //nolint:nilerr // A failed rename is the answer.
+	}
+
+	_ = os.Remove(moved)
+
+	return true, nil
+}
+
+// newUploadTemp creates a temp file for an upload body in the upload
+// directory. Pass the finished, closed file to settleUpload before renaming it
+// into the root.
+func (s *Storage) newUploadTemp() (*os.File, error) {
+	f, err := os.CreateTemp(s.uploadDir(), objectTempPrefix+"*")
+	if err != nil {
+		return nil, errors.Wrap(err, "create temp object")
+	}
+
+	return f, nil
+}
+
+// settleUpload returns the path of a finished upload temp that can be renamed
+// atomically into the root: tmp itself, or, when the upload directory is on
+// another filesystem, a copy in the staging directory (tmp is removed). On
+// error no temp is left behind.
+func (s *Storage) settleUpload(tmp string) (string, error) {
+	if !s.tempCrossDevice {
+		return tmp, nil
+	}
+
+	defer func() { _ = os.Remove(tmp) }()
+
+	src, err := os.Open(tmp) //nolint:gosec // Path is an internal temp file.
+	if err != nil {
+		return "", errors.Wrap(err, "open temp object")
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := s.newObjectTemp()
+	if err != nil {
+		return "", err
+	}
+
+	fail := func(err error) (string, error) {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+
+		return "", err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fail(errors.Wrap(err, "copy temp object"))
+	}
+
+	if err := s.syncFile(dst); err != nil {
+		return fail(err)
+	}
+
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return "", errors.Wrap(err, "close temp object")
+	}
+
+	return dst.Name(), nil
+}
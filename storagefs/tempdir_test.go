@@ -0,0 +1,121 @@
+package storagefs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// testTempDirUploads puts an object and a multipart object through s and
+// checks both read back and leave nothing behind in tempDir.
+func testTempDirUploads(t *testing.T, s *Storage, tempDir string) {
+	t.Helper()
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+
+	content := bytes.Repeat([]byte("staged"), 1000)
+	_, err := s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: "b", Key: "put", Reader: bytes.NewReader(content), Size: int64(len(content)),
+	})
+	require.NoError(t, err)
+
+	upload, err := s.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: "b", Key: "mp"})
+	require.NoError(t, err)
+
+	var parts []fs.CompletedPart
+
+	for i, body := range [][]byte{content, []byte("tail")} {
+		part, err := s.UploadPart(ctx, &fs.UploadPartRequest{
+			Bucket: "b", Key: "mp", UploadID: upload.UploadID, PartNumber: i + 1,
+			Reader: bytes.NewReader(body), Size: int64(len(body)),
+		})
+		require.NoError(t, err)
+
+		parts = append(parts, fs.CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	_, err = s.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: "b", Key: "mp", UploadID: upload.UploadID, Parts: parts,
+	})
+	require.NoError(t, err)
+
+	for key, want := range map[string][]byte{
+		"put": content,
+		"mp":  append(bytes.Clone(content), "tail"...),
+	} {
+		obj, err := s.GetObject(ctx, "b", key)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(obj.Reader)
+		require.NoError(t, err)
+		require.NoError(t, obj.Reader.Close())
+		require.Equal(t, want, got, key)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "upload temps must not outlive the upload")
+}
+
+func TestWithTempDir_SameFilesystem(t *testing.T) {
+	tempDir := t.TempDir()
+
+	s, err := New(t.TempDir(), WithTempDir(tempDir))
+	require.NoError(t, err)
+	require.False(t, s.CrossDeviceTemp())
+
+	testTempDirUploads(t, s, tempDir)
+}
+
+func TestWithTempDir_CrossFilesystem(t *testing.T) {
+	t.Run("Forced", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		s, err := New(t.TempDir(), WithTempDir(tempDir))
+		require.NoError(t, err)
+
+		// Take the copy path regardless of where the test directories live.
+		s.tempCrossDevice = true
+
+		testTempDirUploads(t, s, tempDir)
+	})
+
+	t.Run("Tmpfs", func(t *testing.T) {
+		if _, err := os.Stat("/dev/shm"); err != nil {
+			t.Skip("no /dev/shm")
+		}
+
+		tempDir, err := os.MkdirTemp("/dev/shm", "storagefs-test-")
+		if err != nil {
+			t.Skipf("/dev/shm not writable: %v", err)
+		}
+
+		t.Cleanup(func() { _ = os.RemoveAll(tempDir) })
+
+		s, err := New(t.TempDir(), WithTempDir(tempDir))
+		require.NoError(t, err)
+
+		if !s.CrossDeviceTemp() {
+			t.Skip("/dev/shm shares a filesystem with the test root")
+		}
+
+		testTempDirUploads(t, s, tempDir)
+	})
+}
+
+func TestWithTempDir_RemovesStaleTemps(t *testing.T) {
+	tempDir := t.TempDir()
+	stale := filepath.Join(tempDir, objectTempPrefix+"stale")
+	require.NoError(t, os.WriteFile(stale, []byte("partial"), 0o600))
+
+	_, err := New(t.TempDir(), WithTempDir(tempDir))
+	require.NoError(t, err)
+	require.NoFileExists(t, stale)
+}
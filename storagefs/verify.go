@@ -0,0 +1,124 @@
+package storagefs
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is the stored object checksum (S3 ETag).
+	"encoding/hex"
+	"hash"
+	"io"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+
+	"github.com/go-faster/fs"
+)
+
+// WithVerifyOnRead makes the GetObject reader hash the object as it is read
+// and fail with fs.ErrIntegrity when a full sequential read does not match the
+// stored checksum. Unlike WithVerifyReads it costs no extra read, but the
+// mismatch surfaces only at the end of the body: the final read is withheld so
+// the consumer never receives the complete corrupt object, and a server must
+// abort the response rather than finish it. Range reads are not verified.
+func WithVerifyOnRead() Option {
+	return func(s *Storage) { s.verifyStream = true }
+}
+
+// WithReadQuarantine moves an object that fails WithVerifyOnRead into
+// <root>/.quarantine, as Scrub does, so it is not served again.
+func WithReadQuarantine() Option {
+	return func(s *Storage) { s.readQuarantine = true }
+}
+
+// CorruptReads returns the number of reads that failed WithVerifyOnRead.
+func (s *Storage) CorruptReads() int64 { return s.corruptReads.Load() }
+
+// verifyingReader hashes an object file while it is read from the start. Seeks
+// are allowed (http.ServeContent probes the size), but a read that does not
+// continue the hashed prefix turns verification off for this reader.
+type verifyingReader struct {
+	f        io.ReadSeekCloser
+	s        *Storage
+	bucket   string
+	key      string
+	expected string
+	size     int64
+
+	hash    hash.Hash
+	pos     int64 // current file offset
+	hashed  int64 // length of the prefix hashed so far
+	partial bool  // a non-sequential read happened; nothing to verify
+	err     error // sticky verification failure
+}
+
+func (s *Storage) newVerifyingReader(f io.ReadSeekCloser, bucket, key, expected string, size int64) *verifyingReader {
+	return &verifyingReader{
+		f:        f,
+		s:        s,
+		bucket:   bucket,
+		key:      key,
+		expected: expected,
+		size:     size,
+		hash:     md5.New(), //nolint:gosec // MD5 is the stored object checksum (S3 ETag).
+	}
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+
+	if v.pos != v.hashed {
+		v.partial = true
+	}
+
+	n, err := v.f.Read(p)
+	v.pos += int64(n)
+
+	if v.partial {
+		return n, err
+	}
+
+	v.hash.Write(p[:n])
+	v.hashed = v.pos
+
+	// Check once the whole object has been read, without waiting for EOF:
+	// io.CopyN-style consumers stop at the size and never see it.
+	if v.hashed == v.size {
+		v.partial = true // verified; later reads pass through
+
+		if got := hex.EncodeToString(v.hash.Sum(nil)); got != v.expected {
+			v.err = errors.Wrapf(fs.ErrIntegrity, "%s/%s: stored %s, read %s", v.bucket, v.key, v.expected, got)
+			v.s.corruptReads.Add(1)
+
+			return 0, v.err
+		}
+	}
+
+	return n, err
+}
+
+func (v *verifyingReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := v.f.Seek(offset, whence)
+	if err == nil {
+		v.pos = pos
+	}
+
+	return pos, err
+}
+
+// Close closes the file and, for a corrupt object under WithReadQuarantine,
+// moves it aside. The object is verified again from disk first, so one that
+// was replaced while this reader held the old file is left alone.
+func (v *verifyingReader) Close() error {
+	err := v.f.Close()
+
+	if v.err != nil && v.s.readQuarantine {
+		path := filepath.Join(v.s.root, v.bucket, v.s.keyPath(v.key))
+		if errors.Is(v.s.verifyContent(v.bucket, v.key, path), fs.ErrIntegrity) {
+			_ = v.s.quarantineObject(v.bucket, v.key)
+		}
+	}
+
+	return err
+}
+
+var _ io.ReadSeekCloser = (*verifyingReader)(nil)
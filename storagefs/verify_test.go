@@ -0,0 +1,82 @@
+package storagefs
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+func TestVerifyOnRead(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root, WithVerifyOnRead())
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "good.txt", []byte("intact content"))
+	putContent(t, s, "b", "rotted.txt", []byte("this will rot"))
+
+	corrupt(t, root, "b", "rotted.txt")
+
+	t.Run("Clean", func(t *testing.T) {
+		require.Equal(t, "intact content", string(readContent(t, s, "b", "good.txt")))
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		resp, err := s.GetObject(ctx, "b", "rotted.txt")
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(resp.Reader)
+		require.ErrorIs(t, err, fs.ErrIntegrity)
+		require.NoError(t, resp.Reader.Close())
+
+		// The corrupt body is never handed over in full.
+		require.Less(t, len(data), len("this will rot"))
+		require.EqualValues(t, 1, s.CorruptReads())
+
+		// Without WithReadQuarantine the object stays in place.
+		require.NoError(t, s.statObject("b", "rotted.txt"))
+	})
+
+	t.Run("RangeNotVerified", func(t *testing.T) {
+		resp, err := s.GetObject(ctx, "b", "rotted.txt")
+		require.NoError(t, err)
+
+		defer func() { _ = resp.Reader.Close() }()
+
+		rs, ok := resp.Reader.(io.ReadSeeker)
+		require.True(t, ok)
+
+		_, err = rs.Seek(5, io.SeekStart)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(rs)
+		require.NoError(t, err)
+		require.Equal(t, "will rot", string(data))
+	})
+}
+
+func TestVerifyOnRead_Quarantine(t *testing.T) {
+	root := t.TempDir()
+	s, err := New(root, WithVerifyOnRead(), WithReadQuarantine())
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "b"))
+	putContent(t, s, "b", "rotted.txt", []byte("this will rot"))
+
+	corrupt(t, root, "b", "rotted.txt")
+
+	resp, err := s.GetObject(ctx, "b", "rotted.txt")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(resp.Reader)
+	require.ErrorIs(t, err, fs.ErrIntegrity)
+	require.NoError(t, resp.Reader.Close())
+
+	_, err = s.GetObject(ctx, "b", "rotted.txt")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
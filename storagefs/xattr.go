@@ -0,0 +1,176 @@
+package storagefs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/go-faster/errors"
+)
+
+// MetadataStore selects where storagefs keeps each object's metadata document
+// (ETag, checksum, representation headers, user metadata, tags, ACL).
+type MetadataStore int
+
+const (
+	// MetadataSidecar keeps it in a JSON file under <root>/.meta, named by a
+	// hash of the key. Works on every filesystem; the default.
+	MetadataSidecar MetadataStore = iota
+	// MetadataXattr keeps it in an extended attribute on the object file
+	// itself, saving an inode and a file per object. It needs user xattrs
+	// (Linux ext4, XFS, btrfs, tmpfs on 6.6+); see WithMetadataStore for the
+	// fallbacks.
+	MetadataXattr
+)
+
+// ParseMetadataStore maps a config string to a MetadataStore, defaulting an
+// empty value to MetadataSidecar.
+func ParseMetadataStore(s string) (MetadataStore, error) {
+	switch s {
+	case "sidecar", "":
+		return MetadataSidecar, nil
+	case "xattr":
+		return MetadataXattr, nil
+	default:
+		return MetadataSidecar, errors.Errorf("invalid metadata store %q (want sidecar or xattr)", s)
+	}
+}
+
+func (m MetadataStore) String() string {
+	if m == MetadataXattr {
+		return "xattr"
+	}
+
+	return "sidecar"
+}
+
+// WithMetadataStore selects where object metadata is kept (default
+// MetadataSidecar).
+//
+// MetadataXattr is probed when the Storage is created: if the root's
+// filesystem (or platform) has no user xattrs, sidecars are used instead, and
+// MetadataStore reports what is in effect. Individual objects still fall back
+// to a sidecar when their document does not fit the filesystem's xattr size
+// limit, or the object cannot carry one (a symlink planted in the data
+// directory); reads check the attribute first, then the sidecar, so a root
+// switched from sidecars keeps its existing metadata. Switching back from
+// xattrs does not migrate: attributes are not read in MetadataSidecar mode.
+// Linked objects share one inode and so one attribute set, so MetadataXattr
+// cannot be combined with WithDedup.
+func WithMetadataStore(m MetadataStore) Option {
+	return func(s *Storage) { s.metaStore = m }
+}
+
+// MetadataStore reports where object metadata is kept: the configured store,
+// or MetadataSidecar when MetadataXattr was asked for but is unsupported.
+func (s *Storage) MetadataStore() MetadataStore { return s.metaStore }
+
+// xattrName is the extended attribute holding the JSON metadata document. The
+// user namespace is the one unprivileged processes may write.
+const xattrName = "user.fs.meta"
+
+// xattrProbeName is set and removed on a staging file to detect support.
+const xattrProbeName = "user.fs.probe"
+
+// xattrSupported reports whether files in dir can carry user xattrs.
+func xattrSupported(dir string) bool {
+	f, err := os.CreateTemp(dir, objectTempPrefix+"*")
+	if err != nil {
+		return false
+	}
+
+	path := f.Name()
+	_ = f.Close()
+
+	defer func() { _ = os.Remove(path) }()
+
+	return setXattr(path, xattrProbeName, []byte("1")) == nil
+}
+
+// objectFilePath returns the on-disk path of an object's body.
+func (s *Storage) objectFilePath(bucket, key string) string {
+	return filepath.Join(s.root, bucket, s.keyPath(key))
+}
+
+// readMetaXattr loads an object's metadata from its extended attribute. ok is
+// false when there is none (or it is unreadable), so the caller falls back to
+// the sidecar.
+func (s *Storage) readMetaXattr(bucket, key string) (sc *sidecar, ok bool) {
+	data, err := getXattr(s.objectFilePath(bucket, key), xattrName)
+	if err != nil {
+		return nil, false
+	}
+
+	var doc sidecar
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+
+	return &doc, true
+}
+
+// stageMetaXattr stores a new object's metadata document in the extended
+// attribute of tmp, its body before the rename into place, fsyncing it per
+// the sync policy, so the object never appears without its metadata. It
+// reports false, leaving the document to writeSidecar after the rename, under
+// MetadataSidecar or when the attribute cannot be set.
+func (s *Storage) stageMetaXattr(tmp string, sc *sidecar) (bool, error) {
+	if s.metaStore != MetadataXattr {
+		return false, nil
+	}
+
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return false, errors.Wrap(err, "marshal sidecar")
+	}
+
+	if setXattr(tmp, xattrName, data) != nil {
+		return false, nil
+	}
+
+	if s.sync < SyncFile {
+		return true, nil
+	}
+
+	f, err := os.Open(tmp) //nolint:gosec // Staging file under root.
+	if err != nil {
+		return false, errors.Wrap(err, "open object")
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := s.syncFile(f); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// writeMetaXattr stores an object's metadata document in its extended
+// attribute, fsyncing the file per the sync policy. The object must already be
+// in place.
+func (s *Storage) writeMetaXattr(bucket, key string, data []byte) error {
+	bucketPath := filepath.Join(s.root, bucket)
+	path := s.objectFilePath(bucket, key)
+
+	// Attributes are set by path, so refuse a key whose directory was made to
+	// lead out of the bucket, as writes of the body do.
+	if err := checkObjectDir(bucketPath, filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	if err := setXattr(path, xattrName, data); err != nil {
+		return errors.Wrap(err, "set metadata xattr")
+	}
+
+	if s.sync < SyncFile {
+		return nil
+	}
+
+	f, err := os.Open(path) //nolint:gosec // Path built from a validated bucket/key under root.
+	if err != nil {
+		return errors.Wrap(err, "open object")
+	}
+	defer func() { _ = f.Close() }()
+
+	return s.syncFile(f)
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package storagefs
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// getXattr returns the value of the extended attribute name on path, without
+// following a final symlink.
+func getXattr(path, name string) ([]byte, error) {
+	for {
+		size, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size)
+
+		n, err := unix.Lgetxattr(path, name, buf)
+		if errors.Is(err, unix.ERANGE) {
+			// Grew between the two calls; size it again.
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return buf[:n], nil
+	}
+}
+
+// setXattr sets the extended attribute name on path, without following a
+// final symlink (Linux refuses user attributes on symlinks, so a planted link
+// is reported as an error rather than having its target tagged).
+func setXattr(path, name string, value []byte) error {
+	return unix.Lsetxattr(path, name, value, 0)
+}
+
+// removeXattr removes the extended attribute name from path; a missing one is
+// not an error.
+func removeXattr(path, name string) error {
+	if err := unix.Lremovexattr(path, name); err != nil && !errors.Is(err, unix.ENODATA) {
+		return err
+	}
+
+	return nil
+}
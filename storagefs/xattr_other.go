@@ -0,0 +1,15 @@
+//go:build !linux
+
+package storagefs
+
+import "github.com/go-faster/errors"
+
+// errNoXattr reports that this platform's extended attributes are not used;
+// WithMetadataStore(MetadataXattr) falls back to sidecars.
+var errNoXattr = errors.New("extended attributes are only supported on Linux")
+
+func getXattr(string, string) ([]byte, error) { return nil, errNoXattr }
+
+func setXattr(string, string, []byte) error { return errNoXattr }
+
+func removeXattr(string, string) error { return nil }
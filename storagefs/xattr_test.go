@@ -0,0 +1,206 @@
+package storagefs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/fs"
+)
+
+// TestMetadataXattr round-trips metadata on an ext4-backed temp dir and, when
+// present, tmpfs. Wherever user xattrs are unsupported the store must have
+// fallen back to sidecars with identical behavior.
+func TestMetadataXattr(t *testing.T) {
+	t.Parallel()
+
+	roots := map[string]string{"TempDir": t.TempDir()}
+	if runtime.GOOS == "linux" {
+		if dir, err := os.MkdirTemp("/dev/shm", "storagefs-"); err == nil {
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			roots["tmpfs"] = dir
+		}
+	}
+
+	for name, root := range roots {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := New(root, WithMetadataStore(MetadataXattr))
+			require.NoError(t, err)
+
+			ctx := t.Context()
+			require.NoError(t, s.CreateBucket(ctx, "bucket-a"))
+
+			meta := fs.ObjectMetadata{
+				ContentType:  "text/plain",
+				UserMetadata: map[string]string{"color": "blue"},
+			}
+			put, err := s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket:   "bucket-a",
+				Key:      "dir/obj.txt",
+				Reader:   strings.NewReader("hello"),
+				Size:     5,
+				Metadata: meta,
+				Tags:     []fs.Tag{{Key: "k", Value: "v"}},
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, s.PutObjectTagging(ctx, "bucket-a", "dir/obj.txt", []fs.Tag{{Key: "k2", Value: "v2"}}))
+
+			obj, err := s.GetObject(ctx, "bucket-a", "dir/obj.txt")
+			require.NoError(t, err)
+			_ = obj.Reader.Close()
+			require.Equal(t, put.ETag, obj.ETag)
+			require.Equal(t, meta, obj.Metadata)
+
+			tags, err := s.GetObjectTagging(ctx, "bucket-a", "dir/obj.txt")
+			require.NoError(t, err)
+			require.Equal(t, []fs.Tag{{Key: "k2", Value: "v2"}}, tags)
+
+			_, statErr := os.Stat(s.sidecarPath("bucket-a", "dir/obj.txt"))
+			_, xattrErr := getXattr(s.objectFilePath("bucket-a", "dir/obj.txt"), xattrName)
+
+			if s.MetadataStore() == MetadataXattr {
+				require.ErrorIs(t, statErr, os.ErrNotExist, "no sidecar file in xattr mode")
+				require.NoError(t, xattrErr)
+			} else {
+				t.Logf("%s: user xattrs unsupported, fell back to sidecars", root)
+				require.NoError(t, statErr)
+			}
+
+			require.NoError(t, s.DeleteObject(ctx, "bucket-a", "dir/obj.txt"))
+			_, err = s.GetObjectTagging(ctx, "bucket-a", "dir/obj.txt")
+			require.ErrorIs(t, err, fs.ErrObjectNotFound)
+		})
+	}
+}
+
+// TestMetadataXattr_OversizedFallsBack keeps a document over the kernel's 64
+// KiB attribute limit in a sidecar, and moves it back into the attribute once
+// it fits again.
+func TestMetadataXattr_OversizedFallsBack(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(t.TempDir(), WithMetadataStore(MetadataXattr))
+	require.NoError(t, err)
+
+	if s.MetadataStore() != MetadataXattr {
+		t.Skip("user xattrs unsupported here")
+	}
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "bucket-a"))
+
+	big := fs.ObjectMetadata{UserMetadata: map[string]string{"blob": strings.Repeat("x", 70<<10)}}
+	_, err = s.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket:   "bucket-a",
+		Key:      "obj",
+		Reader:   bytes.NewReader([]byte("body")),
+		Size:     4,
+		Metadata: big,
+	})
+	require.NoError(t, err)
+
+	_, err = os.Stat(s.sidecarPath("bucket-a", "obj"))
+	require.NoError(t, err, "oversized metadata is kept in a sidecar")
+
+	obj, err := s.GetObject(ctx, "bucket-a", "obj")
+	require.NoError(t, err)
+	body, err := io.ReadAll(obj.Reader)
+	require.NoError(t, err)
+	_ = obj.Reader.Close()
+	require.Equal(t, "body", string(body))
+	require.Equal(t, big, obj.Metadata)
+
+	// A later write that fits goes back to the attribute and retires the
+	// sidecar.
+	sc, err := s.readSidecar("bucket-a", "obj")
+	require.NoError(t, err)
+	sc.UserMetadata = nil
+	require.NoError(t, s.writeSidecar("bucket-a", sc))
+
+	_, err = os.Stat(s.sidecarPath("bucket-a", "obj"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = getXattr(s.objectFilePath("bucket-a", "obj"), xattrName)
+	require.NoError(t, err)
+}
+
+// TestMetadataXattr_VisibleWithMetadata checks that a new object never shows
+// up without its metadata: the attribute is set on the staging file, before
+// the rename that publishes the body.
+func TestMetadataXattr_VisibleWithMetadata(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(t.TempDir(), WithMetadataStore(MetadataXattr))
+	require.NoError(t, err)
+
+	if s.MetadataStore() != MetadataXattr {
+		t.Skip("user xattrs unsupported here")
+	}
+
+	ctx := t.Context()
+	require.NoError(t, s.CreateBucket(ctx, "bucket-a"))
+
+	const n = 200
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := range n {
+			_, _ = s.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: "bucket-a",
+				Key:    fmt.Sprintf("obj-%03d", i),
+				Reader: strings.NewReader("body"),
+				Size:   4,
+			})
+		}
+	}()
+
+	for i := 0; i < n; {
+		path := s.objectFilePath("bucket-a", fmt.Sprintf("obj-%03d", i))
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		_, err := getXattr(path, xattrName)
+		require.NoError(t, err, "object %d visible without its metadata", i)
+
+		i++
+	}
+
+	<-done
+}
+
+func TestMetadataXattr_Dedup(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(t.TempDir(), WithMetadataStore(MetadataXattr), WithDedup())
+	require.Error(t, err)
+}
+
+func TestParseMetadataStore(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]MetadataStore{"": MetadataSidecar, "sidecar": MetadataSidecar, "xattr": MetadataXattr} {
+		got, err := ParseMetadataStore(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+
+		back, err := ParseMetadataStore(want.String())
+		require.NoError(t, err)
+		require.Equal(t, want, back)
+	}
+
+	_, err := ParseMetadataStore("inode")
+	require.Error(t, err)
+}
@@ -36,6 +36,10 @@ type object struct {
 	metadata     fs.ObjectMetadata
 	tags         []fs.Tag
 	acl          fs.ACL
+	// partSizes are the part sizes of a multipart object, nil otherwise.
+	partSizes []int64
+	// legalHold refuses overwrites and deletes while set.
+	legalHold bool
 }
 
 type bucket struct {
@@ -43,6 +47,7 @@ type bucket struct {
 	creationDate time.Time
 	objects      map[string]*object
 	acl          fs.ACL
+	region       string
 }
 
 type uploadPart struct {
@@ -154,6 +159,10 @@ func (s *Storage) ListObjects(ctx context.Context, bucketName, prefix string) ([
 }
 
 func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.PutObjectResponse, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "customer-provided encryption keys")
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -176,19 +185,33 @@ func (s *Storage) PutObject(ctx context.Context, req *fs.PutObjectRequest) (*fs.
 		return nil, fs.ErrPreconditionFailed
 	}
 
+	if present && existing.legalHold {
+		return nil, legalHoldError(req.Bucket, req.Key)
+	}
+
 	// Read all data from the reader
 	data, err := io.ReadAll(req.Reader)
 	if err != nil {
 		return nil, errors.Wrap(err, "read data")
 	}
 
+	// A caller that gave up mid-body (e.g. a canceled copy) stores nothing.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Calculate ETag (MD5 hash).
 	hash := md5.Sum(data) //nolint:gosec // MD5 is required for S3 ETag compatibility.
 	etag := fmt.Sprintf("%x", hash)
 
+	lastModified := req.LastModified
+	if lastModified.IsZero() {
+		lastModified = time.Now()
+	}
+
 	b.objects[req.Key] = &object{
 		data:         data,
-		lastModified: time.Now(),
+		lastModified: lastModified,
 		etag:         etag,
 		metadata:     req.Metadata,
 		tags:         append([]fs.Tag(nil), req.Tags...),
@@ -253,6 +276,37 @@ func (s *Storage) DeleteObjectTagging(_ context.Context, bucketName, key string)
 	return nil
 }
 
+func (s *Storage) PutObjectLegalHold(_ context.Context, bucketName, key string, on bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, err := s.getObject(bucketName, key)
+	if err != nil {
+		return err
+	}
+
+	obj.legalHold = on
+
+	return nil
+}
+
+func (s *Storage) GetObjectLegalHold(_ context.Context, bucketName, key string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, err := s.getObject(bucketName, key)
+	if err != nil {
+		return false, err
+	}
+
+	return obj.legalHold, nil
+}
+
+// legalHoldError refuses a change to an object under legal hold.
+func legalHoldError(bucket, key string) error {
+	return errors.Wrapf(fs.ErrAccessDenied, "%q in bucket %q is under legal hold", key, bucket)
+}
+
 func (s *Storage) SetBucketACL(_ context.Context, bucketName string, acl fs.ACL) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -279,6 +333,32 @@ func (s *Storage) BucketACL(_ context.Context, bucketName string) (fs.ACL, error
 	return normalizeACL(b.acl), nil
 }
 
+func (s *Storage) SetBucketRegion(_ context.Context, bucketName, region string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return fs.ErrBucketNotFound
+	}
+
+	b.region = region
+
+	return nil
+}
+
+func (s *Storage) BucketRegion(_ context.Context, bucketName string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return "", fs.ErrBucketNotFound
+	}
+
+	return b.region, nil
+}
+
 func (s *Storage) ObjectACL(_ context.Context, bucketName, key string) (fs.ACL, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -301,6 +381,10 @@ func normalizeACL(a fs.ACL) fs.ACL {
 }
 
 func (s *Storage) GetObject(ctx context.Context, bucketName, key string) (*fs.GetObjectResponse, error) {
+	if fs.CustomerKeyFromContext(ctx) != nil {
+		return nil, errors.Wrap(fs.ErrUnsupportedOperation, "customer-provided encryption keys")
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -324,6 +408,7 @@ func (s *Storage) GetObject(ctx context.Context, bucketName, key string) (*fs.Ge
 		LastModified: obj.lastModified,
 		ETag:         obj.etag,
 		Metadata:     obj.metadata,
+		PartSizes:    append([]int64(nil), obj.partSizes...),
 	}, nil
 }
 
@@ -344,10 +429,26 @@ func (s *Storage) DeleteObject(ctx context.Context, bucketName, key string) erro
 		return fs.ErrBucketNotFound
 	}
 
-	if _, exists := b.objects[key]; !exists {
+	obj, exists := b.objects[key]
+	if cond := fs.DeleteConditionFromContext(ctx); cond != nil {
+		var etag string
+		if exists {
+			etag = obj.etag
+		}
+
+		if cond.PreconditionFailed(exists, etag) {
+			return fs.ErrPreconditionFailed
+		}
+	}
+
+	if !exists {
 		return fs.ErrObjectNotFound
 	}
 
+	if obj.legalHold {
+		return legalHoldError(bucketName, key)
+	}
+
 	delete(b.objects, key)
 
 	return nil
@@ -515,40 +616,66 @@ func (s *Storage) CompleteMultipartUpload(ctx context.Context, req *fs.CompleteM
 		return parts[i].PartNumber < parts[j].PartNumber
 	})
 
-	// Concatenate all parts
+	// Every listed part must exist as the client saw it.
 	var totalSize int64
 
 	for _, part := range parts {
-		if p, ok := upload.parts[part.PartNumber]; ok {
-			totalSize += int64(len(p.data))
+		p, ok := upload.parts[part.PartNumber]
+		if !ok || p.etag != strings.Trim(part.ETag, `"`) {
+			return nil, errors.Wrapf(fs.ErrInvalidPart, "part %d", part.PartNumber)
 		}
+
+		totalSize += int64(len(p.data))
 	}
 
+	// Concatenate all parts
 	data := make([]byte, 0, totalSize)
+	partSizes := make([]int64, 0, len(parts))
 
 	for _, part := range parts {
-		if p, ok := upload.parts[part.PartNumber]; ok {
-			data = append(data, p.data...)
-		}
+		p := upload.parts[part.PartNumber].data
+		data = append(data, p...)
+		partSizes = append(partSizes, int64(len(p)))
+	}
+
+	key := upload.key
+	if req.StoreAs != "" {
+		key = req.StoreAs
+	}
+
+	existing, present := b.objects[key]
+
+	var currentETag string
+	if present {
+		currentETag = existing.etag
+	}
+
+	if req.PreconditionFailed(present, currentETag) {
+		return nil, fs.ErrPreconditionFailed
+	}
+
+	if present && existing.legalHold {
+		return nil, legalHoldError(upload.bucket, key)
 	}
 
 	etag := multipartETag(parts, upload.parts)
 
-	b.objects[upload.key] = &object{
+	b.objects[key] = &object{
 		data:         data,
 		lastModified: time.Now(),
 		etag:         etag,
 		metadata:     upload.metadata,
 		tags:         upload.tags,
 		acl:          upload.acl,
+		partSizes:    partSizes,
 	}
 
 	delete(s.uploads, req.UploadID)
 
 	return &fs.CompleteMultipartUploadResponse{
-		Location: "/" + upload.bucket + "/" + upload.key,
+		Location: "/" + upload.bucket + "/" + key,
 		Bucket:   upload.bucket,
-		Key:      upload.key,
+		Key:      key,
 		ETag:     etag,
 	}, nil
 }
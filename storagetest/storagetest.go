@@ -14,14 +14,17 @@ package storagetest
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5" //nolint:gosec // MD5 is required for S3 ETag compatibility.
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -69,6 +72,9 @@ var suite = map[string]func(t *testing.T, storage fs.Storage){
 	"PutObject/NestedKey":                   testPutObjectNestedKey,
 	"PutObject/Overwrite":                   testPutObjectOverwrite,
 	"PutObject/BucketNotFound":              testPutObjectBucketNotFound,
+	"PutObject/LastModified":                testPutObjectLastModified,
+	"PutObject/Empty":                       testPutObjectEmpty,
+	"PutObject/TrailingSlash":               testPutObjectTrailingSlash,
 	"GetObject":                             testGetObject,
 	"GetObject/BucketNotFound":              testGetObjectBucketNotFound,
 	"GetObject/ObjectNotFound":              testGetObjectObjectNotFound,
@@ -78,6 +84,15 @@ var suite = map[string]func(t *testing.T, storage fs.Storage){
 	"ListObjects":                           testListObjects,
 	"ListObjects/WithPrefix":                testListObjectsWithPrefix,
 	"ListObjects/BucketNotFound":            testListObjectsBucketNotFound,
+	"ListObjectsRange":                      testListObjectsRange,
+	"ListObjectsModifiedSince":              testListObjectsModifiedSince,
+	"FilterObjects":                         testFilterObjects,
+	"ListObjectsOrdered":                    testListObjectsOrdered,
+	"CountObjects":                          testCountObjects,
+	"WalkObjects":                           testWalkObjects,
+	"ListAllObjects":                        testListAllObjects,
+	"ListObjectsByMetadata":                 testListObjectsByMetadata,
+	"PutObjectRange":                        testPutObjectRange,
 	"Multipart/Create":                      testMultipartCreate,
 	"Multipart/Create/BucketNotFound":       testMultipartCreateBucketNotFound,
 	"Multipart/UploadPart":                  testMultipartUploadPart,
@@ -86,7 +101,11 @@ var suite = map[string]func(t *testing.T, storage fs.Storage){
 	"Multipart/Complete/ETag":               testMultipartCompleteETag,
 	"Multipart/Complete/OutOfOrder":         testMultipartCompleteOutOfOrder,
 	"Multipart/Complete/NotFound":           testMultipartCompleteNotFound,
+	"Multipart/Complete/InvalidPart":        testMultipartCompleteInvalidPart,
+	"Multipart/Complete/StoreAs":            testMultipartCompleteStoreAs,
+	"Multipart/Complete/IfNoneMatch":        testMultipartCompleteIfNoneMatch,
 	"Multipart/Abort":                       testMultipartAbort,
+	"Multipart/ConcurrentParts":             testMultipartConcurrentParts,
 	"Multipart/Abort/NotFound":              testMultipartAbortNotFound,
 	"Multipart/ListParts":                   testMultipartListParts,
 	"Multipart/ListParts/Overwrite":         testMultipartListPartsOverwrite,
@@ -102,8 +121,11 @@ var suite = map[string]func(t *testing.T, storage fs.Storage){
 	"Tagging/RoundTrip":                     testTaggingRoundTrip,
 	"Tagging/PutObjectTags":                 testTaggingOnPut,
 	"Tagging/NotFound":                      testTaggingNotFound,
+	"LegalHold":                             testLegalHold,
+	"LegalHold/NotFound":                    testLegalHoldNotFound,
 	"Conditional/IfNoneMatch":               testConditionalIfNoneMatch,
 	"Conditional/IfMatch":                   testConditionalIfMatch,
+	"Conditional/DeleteIfMatch":             testConditionalDeleteIfMatch,
 	"Conditional/ConcurrentSingleWinner":    testConditionalConcurrentSingleWinner,
 	"Conditional/ConcurrentCASSingleWinner": testConditionalConcurrentCASSingleWinner,
 	"ACL/BucketRoundTrip":                   testACLBucketRoundTrip,
@@ -111,6 +133,8 @@ var suite = map[string]func(t *testing.T, storage fs.Storage){
 	"ACL/BucketNotFound":                    testACLBucketNotFound,
 	"ACL/ObjectFromPut":                     testACLObjectFromPut,
 	"ACL/ObjectDefaultPrivate":              testACLObjectDefaultPrivate,
+	"Region/BucketRoundTrip":                testRegionBucketRoundTrip,
+	"Region/BucketNotFound":                 testRegionBucketNotFound,
 }
 
 func putObject(t *testing.T, storage fs.Storage, key string, content []byte) {
@@ -252,6 +276,23 @@ func testPutObject(t *testing.T, storage fs.Storage) {
 	require.Equal(t, int64(len(content)), objects[0].Size)
 }
 
+// testPutObjectTrailingSlash covers a folder marker: a key ending in "/" reads
+// back and deletes like any other.
+func testPutObjectTrailingSlash(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	const key = "dir/"
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+	putObject(t, storage, key, []byte{})
+	require.Empty(t, readObject(t, storage, key))
+
+	require.NoError(t, storage.DeleteObject(ctx, testBucket, key))
+
+	_, err := storage.GetObject(ctx, testBucket, key)
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
+
 func testPutObjectNestedKey(t *testing.T, storage fs.Storage) {
 	ctx := t.Context()
 
@@ -295,6 +336,70 @@ func testPutObjectBucketNotFound(t *testing.T, storage fs.Storage) {
 	require.ErrorIs(t, err, fs.ErrBucketNotFound)
 }
 
+func testPutObjectLastModified(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	// Whole seconds: the coarsest mtime resolution a filesystem backend offers.
+	want := time.Date(2015, time.March, 14, 9, 26, 53, 0, time.UTC)
+
+	_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket:       testBucket,
+		Key:          "old.txt",
+		Reader:       strings.NewReader("x"),
+		Size:         1,
+		LastModified: want,
+	})
+	require.NoError(t, err)
+
+	resp, err := storage.GetObject(ctx, testBucket, "old.txt")
+	require.NoError(t, err)
+	require.NoError(t, resp.Reader.Close())
+	require.True(t, want.Equal(resp.LastModified), "get: got %v, want %v", resp.LastModified, want)
+
+	objects, err := storage.ListObjects(ctx, testBucket, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.True(t, want.Equal(objects[0].LastModified), "list: got %v, want %v", objects[0].LastModified, want)
+}
+
+// testPutObjectEmpty checks that a zero-length body stores a zero-byte object
+// with the MD5 of empty content as its ETag, reported as such by GET and
+// listings.
+func testPutObjectEmpty(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	const emptyETag = "d41d8cd98f00b204e9800998ecf8427e"
+
+	resp, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: testBucket,
+		Key:    "placeholder",
+		Reader: bytes.NewReader(nil),
+	})
+	require.NoError(t, err)
+	require.Equal(t, emptyETag, resp.ETag)
+
+	obj, err := storage.GetObject(ctx, testBucket, "placeholder")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(obj.Reader)
+	require.NoError(t, obj.Reader.Close())
+	require.NoError(t, err)
+	require.Empty(t, data)
+	require.Zero(t, obj.Size)
+	require.Equal(t, emptyETag, obj.ETag)
+
+	objects, err := storage.ListObjects(ctx, testBucket, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.Equal(t, "placeholder", objects[0].Key)
+	require.Zero(t, objects[0].Size)
+	require.Equal(t, emptyETag, objects[0].ETag)
+}
+
 func testGetObject(t *testing.T, storage fs.Storage) {
 	ctx := t.Context()
 	content := []byte("hello, world!")
@@ -414,6 +519,405 @@ func testListObjectsWithPrefix(t *testing.T, storage fs.Storage) {
 	require.Empty(t, result)
 }
 
+func testListObjectsRange(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	for _, key := range []string{"a", "b", "b0", "b1/x", "c", "c1/x", "d"} {
+		putObject(t, storage, key, []byte("x"))
+	}
+
+	keys := func(startAfter, endBefore string) []string {
+		objects, err := fs.ListObjectsRange(ctx, storage, testBucket, startAfter, endBefore)
+		require.NoError(t, err)
+
+		out := make([]string, len(objects))
+		for i, o := range objects {
+			out[i] = o.Key
+		}
+
+		return out
+	}
+
+	// Both bounds are exclusive, whether or not they name a stored key.
+	require.Equal(t, []string{"b0", "b1/x"}, keys("b", "c"))
+	require.Equal(t, []string{"b", "b0", "b1/x", "c"}, keys("a0", "c1"))
+	require.Equal(t, []string{"c1/x", "d"}, keys("c", ""))
+	require.Equal(t, []string{"a", "b", "b0"}, keys("", "b1"))
+	require.Empty(t, keys("b1/x", "c"))
+
+	// Split points with a NUL suffix partition the keyspace.
+	first := keys("", "b\x00")
+	second := keys("b", "")
+	require.Equal(t, []string{"a", "b"}, first)
+	require.Equal(t, []string{"a", "b", "b0", "b1/x", "c", "c1/x", "d"}, append(first, second...))
+
+	_, err := fs.ListObjectsRange(ctx, storage, "nonexistent", "a", "b")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
+
+func testListObjectsModifiedSince(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for key, at := range map[string]time.Time{
+		"logs/old":   base,
+		"logs/mid":   base.Add(time.Hour),
+		"logs/new":   base.Add(2 * time.Hour),
+		"other/new":  base.Add(2 * time.Hour),
+		"logs/newer": base.Add(3 * time.Hour),
+	} {
+		_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: testBucket, Key: key, Reader: strings.NewReader("x"), Size: 1, LastModified: at,
+		})
+		require.NoError(t, err)
+	}
+
+	keys := func(prefix string, since time.Time) []string {
+		objects, err := fs.ListObjectsModifiedSince(ctx, storage, testBucket, prefix, since)
+		require.NoError(t, err)
+
+		out := make([]string, len(objects))
+		for i, o := range objects {
+			out[i] = o.Key
+		}
+
+		return out
+	}
+
+	// Strictly after: an object modified exactly at since is not listed.
+	require.Equal(t, []string{"logs/new", "logs/newer"}, keys("logs/", base.Add(time.Hour)))
+	require.Equal(t, []string{"logs/new", "logs/newer", "other/new"}, keys("", base.Add(time.Hour)))
+	require.Len(t, keys("", time.Time{}), 5)
+	require.Empty(t, keys("", base.Add(3*time.Hour)))
+
+	_, err := fs.ListObjectsModifiedSince(ctx, storage, "nonexistent", "", base)
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
+
+func testFilterObjects(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	for _, key := range []string{
+		"logs/2026/01/app.log",
+		"logs/2026/01/app.json",
+		"logs/2026/02/db.log",
+		"data/export.log.gz",
+		"readme.txt",
+	} {
+		_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket: testBucket, Key: key, Reader: strings.NewReader("x"), Size: 1,
+		})
+		require.NoError(t, err)
+	}
+
+	keys := func(prefix string, match func(string) bool) []string {
+		var out []string
+
+		for o, err := range fs.FilterObjects(ctx, storage, testBucket, prefix, match) {
+			require.NoError(t, err)
+
+			out = append(out, o.Key)
+		}
+
+		slices.Sort(out)
+
+		return out
+	}
+
+	contains := func(sub string) func(string) bool {
+		return func(key string) bool { return strings.Contains(key, sub) }
+	}
+
+	require.Equal(t, []string{"data/export.log.gz", "logs/2026/01/app.log", "logs/2026/02/db.log"}, keys("", contains(".log")))
+	require.Equal(t, []string{"logs/2026/01/app.log", "logs/2026/02/db.log"}, keys("", func(key string) bool {
+		return strings.HasSuffix(key, ".log")
+	}))
+	require.Equal(t, []string{"logs/2026/01/app.json", "logs/2026/01/app.log"}, keys("logs/", contains("/01/")))
+	require.Empty(t, keys("data/", contains("app")))
+
+	var errs []error
+
+	for _, err := range fs.FilterObjects(ctx, storage, "nonexistent", "", contains("x")) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], fs.ErrBucketNotFound)
+}
+
+func testCountObjects(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	for _, key := range []string{"a/1", "a/2", "a/b/3", "c"} {
+		putObject(t, storage, key, []byte("x"))
+	}
+
+	for prefix, want := range map[string]int64{"": 4, "a/": 3, "a/b/": 1, "c": 1, "d": 0} {
+		n, err := fs.CountObjects(ctx, storage, testBucket, prefix)
+		require.NoError(t, err)
+		require.Equal(t, want, n, prefix)
+	}
+
+	_, err := fs.CountObjects(ctx, storage, "nonexistent", "")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
+
+func testWalkObjects(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	for _, key := range []string{"a/1", "a/2", "a/b/3", "c"} {
+		putObject(t, storage, key, []byte("x"))
+	}
+
+	var keys []string
+
+	for o, err := range fs.WalkObjects(ctx, storage, testBucket, "a/") {
+		require.NoError(t, err)
+
+		keys = append(keys, o.Key)
+	}
+
+	slices.Sort(keys)
+	require.Equal(t, []string{"a/1", "a/2", "a/b/3"}, keys)
+
+	// Stopping early ends the walk.
+	n := 0
+	for range fs.WalkObjects(ctx, storage, testBucket, "") {
+		n++
+		break
+	}
+
+	require.Equal(t, 1, n)
+
+	for _, err := range fs.WalkObjects(ctx, storage, "nonexistent", "") {
+		require.ErrorIs(t, err, fs.ErrBucketNotFound)
+	}
+}
+
+func testListAllObjects(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	files := map[string][]string{
+		"bucket-a": {"logs/2", "logs/1", "data/x"},
+		"bucket-b": {"logs/3"},
+		"bucket-c": {"data/y"},
+	}
+
+	for bucket, keys := range files {
+		require.NoError(t, storage.CreateBucket(ctx, bucket))
+
+		for _, key := range keys {
+			_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+				Bucket: bucket,
+				Key:    key,
+				Reader: bytes.NewReader([]byte(key)),
+				Size:   int64(len(key)),
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	keys := func(prefix string) map[string][]string {
+		all, err := fs.ListAllObjects(ctx, storage, prefix)
+		require.NoError(t, err)
+
+		out := make(map[string][]string, len(all))
+		for bucket, objects := range all {
+			for _, o := range objects {
+				out[bucket] = append(out[bucket], o.Key)
+			}
+		}
+
+		return out
+	}
+
+	require.Equal(t, map[string][]string{
+		"bucket-a": {"logs/1", "logs/2"},
+		"bucket-b": {"logs/3"},
+	}, keys("logs/"))
+	require.Equal(t, map[string][]string{
+		"bucket-a": {"data/x", "logs/1", "logs/2"},
+		"bucket-b": {"logs/3"},
+		"bucket-c": {"data/y"},
+	}, keys(""))
+	require.Empty(t, keys("missing/"))
+}
+
+func testListObjectsByMetadata(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	objects := []struct {
+		key  string
+		env  string
+		tags []fs.Tag
+	}{
+		{key: "a/prod-archived", env: "prod", tags: []fs.Tag{{Key: "archived", Value: "true"}}},
+		{key: "a/prod", env: "prod"},
+		{key: "a/dev-archived", env: "dev", tags: []fs.Tag{{Key: "archived", Value: "true"}}},
+		{key: "a/plain"},
+		{key: "b/prod", env: "prod"},
+	}
+
+	for _, o := range objects {
+		var meta fs.ObjectMetadata
+		if o.env != "" {
+			meta.UserMetadata = map[string]string{"env": o.env}
+		}
+
+		_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+			Bucket:   testBucket,
+			Key:      o.key,
+			Reader:   bytes.NewReader([]byte("x")),
+			Size:     1,
+			Metadata: meta,
+		})
+		require.NoError(t, err)
+
+		if o.tags != nil {
+			require.NoError(t, storage.PutObjectTagging(ctx, testBucket, o.key, o.tags))
+		}
+	}
+
+	keys := func(prefix string, match map[string]string) []string {
+		var out []string
+
+		for o, err := range fs.ListObjectsByMetadata(ctx, storage, testBucket, prefix, match) {
+			require.NoError(t, err)
+
+			out = append(out, o.Key)
+		}
+
+		slices.Sort(out)
+
+		return out
+	}
+
+	require.Equal(t, []string{"a/prod", "a/prod-archived"}, keys("a/", map[string]string{"x-amz-meta-env": "prod"}))
+	require.Equal(t, []string{"a/prod", "a/prod-archived", "b/prod"}, keys("", map[string]string{"X-Amz-Meta-Env": "prod"}))
+	require.Equal(t, []string{"a/dev-archived", "a/prod-archived"}, keys("", map[string]string{"archived": "true"}))
+	require.Equal(t, []string{"a/prod-archived"}, keys("", map[string]string{"x-amz-meta-env": "prod", "archived": "true"}))
+	require.Empty(t, keys("", map[string]string{"x-amz-meta-env": "staging"}))
+	require.Len(t, keys("a/", nil), 4)
+
+	// The walk stops at the first candidate once the context is done.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	var errs []error
+	for _, err := range fs.ListObjectsByMetadata(canceled, storage, testBucket, "", nil) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], context.Canceled)
+
+	errs = nil
+	for _, err := range fs.ListObjectsByMetadata(ctx, storage, "nonexistent", "", nil) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], fs.ErrBucketNotFound)
+}
+
+func testPutObjectRange(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	_, err := storage.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: testBucket,
+		Key:    "file",
+		Reader: bytes.NewReader([]byte("0123456789")),
+		Size:   10,
+		Metadata: fs.ObjectMetadata{
+			ContentType:  "text/plain",
+			UserMetadata: map[string]string{"owner": "alice"},
+		},
+		Tags: []fs.Tag{{Key: "env", Value: "prod"}},
+	})
+	require.NoError(t, err)
+
+	patch := func(offset int64, data string) (*fs.PutObjectResponse, error) {
+		return fs.PutObjectRange(ctx, storage, testBucket, "file", offset, strings.NewReader(data), int64(len(data)))
+	}
+
+	// In place.
+	resp, err := patch(2, "ab")
+	require.NoError(t, err)
+	require.Equal(t, []byte("01ab456789"), readObject(t, storage, "file"))
+	require.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("01ab456789"))), resp.ETag) //nolint:gosec // MD5 is required for S3 ETag compatibility.
+
+	// Past the end.
+	_, err = patch(8, "XYZW")
+	require.NoError(t, err)
+	require.Equal(t, []byte("01ab4567XYZW"), readObject(t, storage, "file"))
+
+	// At the end, an append.
+	resp, err = patch(12, "!")
+	require.NoError(t, err)
+	require.Equal(t, []byte("01ab4567XYZW!"), readObject(t, storage, "file"))
+
+	obj, err := storage.GetObject(ctx, testBucket, "file")
+	require.NoError(t, err)
+	require.NoError(t, obj.Reader.Close())
+	require.Equal(t, int64(13), obj.Size)
+	require.Equal(t, resp.ETag, obj.ETag)
+	require.Equal(t, "text/plain", obj.Metadata.ContentType)
+	require.Equal(t, map[string]string{"owner": "alice"}, obj.Metadata.UserMetadata)
+
+	tags, err := storage.GetObjectTagging(ctx, testBucket, "file")
+	require.NoError(t, err)
+	require.Equal(t, []fs.Tag{{Key: "env", Value: "prod"}}, tags)
+
+	_, err = patch(14, "x")
+	require.ErrorIs(t, err, fs.ErrInvalidRange)
+	require.Equal(t, []byte("01ab4567XYZW!"), readObject(t, storage, "file"))
+
+	_, err = fs.PutObjectRange(ctx, storage, testBucket, "missing", 0, strings.NewReader("x"), 1)
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
+
+func testListObjectsOrdered(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	for _, key := range []string{"2026-02-01.log", "2026-01-15.log", "2026-03-01.log", "readme.txt"} {
+		putObject(t, storage, key, []byte("x"))
+	}
+
+	keys := func(order fs.Order) []string {
+		objects, err := fs.ListObjectsOrdered(ctx, storage, testBucket, "2026-", order)
+		require.NoError(t, err)
+
+		out := make([]string, len(objects))
+		for i, o := range objects {
+			out[i] = o.Key
+		}
+
+		return out
+	}
+
+	require.Equal(t, []string{"2026-01-15.log", "2026-02-01.log", "2026-03-01.log"}, keys(fs.Ascending))
+	require.Equal(t, []string{"2026-03-01.log", "2026-02-01.log", "2026-01-15.log"}, keys(fs.Descending))
+
+	_, err := fs.ListObjectsOrdered(ctx, storage, "nonexistent", "", fs.Descending)
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}
+
 func testListObjectsBucketNotFound(t *testing.T, storage fs.Storage) {
 	_, err := storage.ListObjects(t.Context(), "nonexistent", "")
 	require.ErrorIs(t, err, fs.ErrBucketNotFound)
@@ -454,6 +958,71 @@ func uploadPart(t *testing.T, storage fs.Storage, uploadID string, partNumber in
 	return part
 }
 
+func testMultipartConcurrentParts(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: testBucket, Key: testKey})
+	require.NoError(t, err)
+
+	const parts = 32
+
+	bodies := make([][]byte, parts)
+	for i := range bodies {
+		bodies[i] = bytes.Repeat([]byte{byte('a' + i%26)}, 4096+i)
+	}
+
+	// Every part is uploaded twice at once, racing a re-upload of the same
+	// number; the bodies are identical so either may win.
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		etags = make(map[int]string)
+		errs  []error
+	)
+
+	for i := range parts * 2 {
+		wg.Go(func() {
+			n := i%parts + 1
+
+			part, err := storage.UploadPart(ctx, &fs.UploadPartRequest{
+				Bucket: testBucket, Key: testKey, UploadID: upload.UploadID, PartNumber: n,
+				Reader: bytes.NewReader(bodies[n-1]), Size: int64(len(bodies[n-1])),
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+
+			etags[n] = part.ETag
+		})
+	}
+
+	wg.Wait()
+	require.Empty(t, errs)
+
+	listed, err := storage.ListParts(ctx, testBucket, testKey, upload.UploadID)
+	require.NoError(t, err)
+	require.Len(t, listed, parts)
+
+	completed := make([]fs.CompletedPart, 0, parts)
+	for n := 1; n <= parts; n++ {
+		completed = append(completed, fs.CompletedPart{PartNumber: n, ETag: etags[n]})
+	}
+
+	_, err = storage.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket: testBucket, Key: testKey, UploadID: upload.UploadID, Parts: completed,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, bytes.Join(bodies, nil), readObject(t, storage, testKey))
+}
+
 func testMultipartUploadPart(t *testing.T, storage fs.Storage) {
 	ctx := t.Context()
 
@@ -509,6 +1078,69 @@ func testMultipartComplete(t *testing.T, storage fs.Storage) {
 
 	data := readObject(t, storage, testKey)
 	require.Equal(t, []byte("hello, world!"), data)
+
+	// Part sizes are kept so the object can be read back part by part.
+	obj, err := storage.GetObject(ctx, testBucket, testKey)
+	require.NoError(t, err)
+	require.NoError(t, obj.Reader.Close())
+	require.Equal(t, []int64{7, 6}, obj.PartSizes)
+}
+
+// testMultipartCompleteStoreAs covers completing an upload under another key:
+// the object is stored there and the upload's own key stays untouched.
+func testMultipartCompleteStoreAs(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+	putObject(t, storage, testKey, []byte("original"))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: testBucket, Key: testKey})
+	require.NoError(t, err)
+
+	part := uploadPart(t, storage, upload.UploadID, 1, []byte("assembled"))
+
+	resp, err := storage.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket:   testBucket,
+		Key:      testKey,
+		UploadID: upload.UploadID,
+		Parts:    []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+		StoreAs:  "renamed",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "renamed", resp.Key)
+
+	require.Equal(t, []byte("assembled"), readObject(t, storage, "renamed"))
+	require.Equal(t, []byte("original"), readObject(t, storage, testKey))
+}
+
+// testMultipartCompleteIfNoneMatch covers a put-if-absent completion: it fails
+// over an existing object, leaves it unchanged, and keeps the upload.
+func testMultipartCompleteIfNoneMatch(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+	putObject(t, storage, testKey, []byte("original"))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: testBucket, Key: testKey})
+	require.NoError(t, err)
+
+	part := uploadPart(t, storage, upload.UploadID, 1, []byte("assembled"))
+	req := &fs.CompleteMultipartUploadRequest{
+		Bucket:      testBucket,
+		Key:         testKey,
+		UploadID:    upload.UploadID,
+		Parts:       []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+		IfNoneMatch: "*",
+	}
+
+	_, err = storage.CompleteMultipartUpload(ctx, req)
+	require.ErrorIs(t, err, fs.ErrPreconditionFailed)
+	require.Equal(t, []byte("original"), readObject(t, storage, testKey))
+
+	req.IfNoneMatch, req.IfMatch = "", "*"
+	_, err = storage.CompleteMultipartUpload(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []byte("assembled"), readObject(t, storage, testKey))
 }
 
 func testMultipartCompleteETag(t *testing.T, storage fs.Storage) {
@@ -584,6 +1216,43 @@ func testMultipartCompleteOutOfOrder(t *testing.T, storage fs.Storage) {
 	require.Equal(t, []byte("hello, world!"), data)
 }
 
+// testMultipartCompleteInvalidPart checks that completion assembles exactly
+// the parts the client listed: a missing part, or one whose ETag no longer
+// matches because it was uploaded again, fails with ErrInvalidPart and
+// leaves the upload to be completed correctly.
+func testMultipartCompleteInvalidPart(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: testBucket, Key: testKey})
+	require.NoError(t, err)
+
+	part1 := uploadPart(t, storage, upload.UploadID, 1, []byte("hello, "))
+	stale := uploadPart(t, storage, upload.UploadID, 2, []byte("world!"))
+	part2 := uploadPart(t, storage, upload.UploadID, 2, []byte("there!"))
+
+	complete := func(parts ...fs.CompletedPart) error {
+		_, err := storage.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+			Bucket:   testBucket,
+			Key:      testKey,
+			UploadID: upload.UploadID,
+			Parts:    parts,
+		})
+
+		return err
+	}
+
+	err = complete(fs.CompletedPart{PartNumber: 1, ETag: part1.ETag}, fs.CompletedPart{PartNumber: 3, ETag: part1.ETag})
+	require.ErrorIs(t, err, fs.ErrInvalidPart)
+
+	err = complete(fs.CompletedPart{PartNumber: 1, ETag: part1.ETag}, fs.CompletedPart{PartNumber: 2, ETag: stale.ETag})
+	require.ErrorIs(t, err, fs.ErrInvalidPart)
+
+	require.NoError(t, complete(fs.CompletedPart{PartNumber: 1, ETag: part1.ETag}, fs.CompletedPart{PartNumber: 2, ETag: part2.ETag}))
+	require.Equal(t, []byte("hello, there!"), readObject(t, storage, testKey))
+}
+
 func testMultipartCompleteNotFound(t *testing.T, storage fs.Storage) {
 	ctx := t.Context()
 
@@ -945,6 +1614,73 @@ func testTaggingRoundTrip(t *testing.T, storage fs.Storage) {
 	require.Equal(t, []byte("content"), readObject(t, storage, "tagged.txt"))
 }
 
+// testLegalHold checks that a held object can be neither overwritten (by a PUT
+// or a multipart completion) nor deleted, that its tags may still change, and
+// that lifting the hold allows the delete again.
+func testLegalHold(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+	putObject(t, storage, testKey, []byte("evidence"))
+
+	on, err := storage.GetObjectLegalHold(ctx, testBucket, testKey)
+	require.NoError(t, err)
+	require.False(t, on)
+
+	require.NoError(t, storage.PutObjectLegalHold(ctx, testBucket, testKey, true))
+
+	on, err = storage.GetObjectLegalHold(ctx, testBucket, testKey)
+	require.NoError(t, err)
+	require.True(t, on)
+
+	require.ErrorIs(t, storage.DeleteObject(ctx, testBucket, testKey), fs.ErrAccessDenied)
+
+	_, err = storage.PutObject(ctx, &fs.PutObjectRequest{
+		Bucket: testBucket, Key: testKey, Reader: strings.NewReader("tampered"), Size: 8,
+	})
+	require.ErrorIs(t, err, fs.ErrAccessDenied)
+
+	upload, err := storage.CreateMultipartUpload(ctx, &fs.CreateMultipartUploadRequest{Bucket: testBucket, Key: testKey})
+	require.NoError(t, err)
+
+	part := uploadPart(t, storage, upload.UploadID, 1, []byte("tampered"))
+	_, err = storage.CompleteMultipartUpload(ctx, &fs.CompleteMultipartUploadRequest{
+		Bucket:   testBucket,
+		Key:      testKey,
+		UploadID: upload.UploadID,
+		Parts:    []fs.CompletedPart{{PartNumber: 1, ETag: part.ETag}},
+	})
+	require.ErrorIs(t, err, fs.ErrAccessDenied)
+	require.NoError(t, storage.AbortMultipartUpload(ctx, testBucket, testKey, upload.UploadID))
+
+	// Tags are not content: they may change under a hold, and keep it.
+	require.NoError(t, storage.PutObjectTagging(ctx, testBucket, testKey, []fs.Tag{{Key: tagEnv, Value: tagProd}}))
+
+	on, err = storage.GetObjectLegalHold(ctx, testBucket, testKey)
+	require.NoError(t, err)
+	require.True(t, on)
+	require.Equal(t, []byte("evidence"), readObject(t, storage, testKey))
+
+	require.NoError(t, storage.PutObjectLegalHold(ctx, testBucket, testKey, false))
+	require.NoError(t, storage.DeleteObject(ctx, testBucket, testKey))
+
+	_, err = storage.GetObject(ctx, testBucket, testKey)
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+}
+
+func testLegalHoldNotFound(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	_, err := storage.GetObjectLegalHold(ctx, "nonexistent", testKey)
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	_, err = storage.GetObjectLegalHold(ctx, testBucket, "missing")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+	require.ErrorIs(t, storage.PutObjectLegalHold(ctx, testBucket, "missing", true), fs.ErrObjectNotFound)
+}
+
 // testTaggingOnPut guards tags supplied at PutObject time.
 func testTaggingOnPut(t *testing.T, storage fs.Storage) {
 	ctx := t.Context()
@@ -1042,6 +1778,41 @@ func testConditionalIfMatch(t *testing.T, storage fs.Storage) {
 	require.Equal(t, []byte("v2"), readObject(t, storage, "obj"))
 }
 
+// testConditionalDeleteIfMatch covers DeleteObject under a DeleteCondition:
+// If-Match: "<etag>" deletes only that version, If-Match: * only an existing
+// object.
+func testConditionalDeleteIfMatch(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	deleteIfMatch := func(key, ifMatch string) error {
+		return storage.DeleteObject(fs.WithDeleteCondition(ctx, &fs.DeleteCondition{IfMatch: ifMatch}), testBucket, key)
+	}
+
+	v1, err := putConditional(t, storage, "obj", []byte("v1"), "", "")
+	require.NoError(t, err)
+
+	_, err = putConditional(t, storage, "obj", []byte("v2"), "", "")
+	require.NoError(t, err)
+
+	// The version the client saw is gone: nothing is deleted.
+	require.ErrorIs(t, deleteIfMatch("obj", v1.ETag), fs.ErrPreconditionFailed)
+	require.Equal(t, []byte("v2"), readObject(t, storage, "obj"))
+
+	v2, err := putConditional(t, storage, "obj", []byte("v2"), "", "")
+	require.NoError(t, err)
+	require.NoError(t, deleteIfMatch("obj", v2.ETag))
+
+	_, err = storage.GetObject(ctx, testBucket, "obj")
+	require.ErrorIs(t, err, fs.ErrObjectNotFound)
+
+	// If-Match: * deletes an existing object and fails on a missing one.
+	putObject(t, storage, "any", []byte("x"))
+	require.NoError(t, deleteIfMatch("any", "*"))
+	require.ErrorIs(t, deleteIfMatch("any", "*"), fs.ErrPreconditionFailed)
+}
+
 // testConditionalConcurrentSingleWinner is the race regression: N goroutines
 // race to create the same key with If-None-Match: *, and exactly one must win.
 // A check-then-act backend lets several observe "absent" and all succeed.
@@ -1211,3 +1982,36 @@ func testACLObjectDefaultPrivate(t *testing.T, storage fs.Storage) {
 	require.NoError(t, err)
 	require.Equal(t, fs.ACLPrivate, acl)
 }
+
+func testRegionBucketRoundTrip(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	require.NoError(t, storage.CreateBucket(ctx, testBucket))
+
+	region, err := storage.BucketRegion(ctx, testBucket)
+	require.NoError(t, err)
+	require.Empty(t, region)
+
+	require.NoError(t, storage.SetBucketRegion(ctx, testBucket, "eu-west-1"))
+
+	region, err = storage.BucketRegion(ctx, testBucket)
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", region)
+
+	// The region lives alongside the ACL without clobbering it.
+	require.NoError(t, storage.SetBucketACL(ctx, testBucket, fs.ACLPublicRead))
+
+	region, err = storage.BucketRegion(ctx, testBucket)
+	require.NoError(t, err)
+	require.Equal(t, "eu-west-1", region)
+}
+
+func testRegionBucketNotFound(t *testing.T, storage fs.Storage) {
+	ctx := t.Context()
+
+	_, err := storage.BucketRegion(ctx, "missing")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+
+	err = storage.SetBucketRegion(ctx, "missing", "eu-west-1")
+	require.ErrorIs(t, err, fs.ErrBucketNotFound)
+}